@@ -3,12 +3,18 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Server struct {
 	Port string
+	// TrustedProxies are the addresses or CIDRs of the proxies whose X-Forwarded-For is believed; with none the
+	// client IP is always the address of the connection
+	TrustedProxies []string
 }
 
 type Database struct {
@@ -17,16 +23,32 @@ type Database struct {
 	Username string
 	Password string
 	DbName   string
-	DbType   string // "mongodb" or "postgresql"
+	DbType   string // "mongodb", "postgresql", "sqlite" or "memory"
 	SSLMode  string
+	// MigrateOnStart applies pending SQL migrations at startup instead of refusing to serve
+	MigrateOnStart bool
 }
 
 type Config struct {
-	Server   Server
-	Database Database
-	MongoDB  MongoDBConfig
-	Postgres PostgresConfig
-	Env      string
+	Server        Server
+	Database      Database
+	MongoDB       MongoDBConfig
+	Postgres      PostgresConfig
+	SQLite        SQLiteConfig
+	Memory        MemoryConfig
+	Trash         TrashConfig
+	Invitations   InvitationConfig
+	Login         LoginConfig
+	Mfa           MfaConfig
+	Mail          MailConfig
+	PasswordReset PasswordResetConfig
+	Sms           SmsConfig
+	Verification  VerificationConfig
+	SigningKeys   SigningKeyConfig
+	ApiKeys       ApiKeyConfig
+	Oidc          OidcConfig
+	Passwords     PasswordConfig
+	Env           string
 }
 
 type MongoDBConfig struct {
@@ -36,6 +58,8 @@ type MongoDBConfig struct {
 	Username string
 	Password string
 	DbName   string
+	// AllowStandalone lets units of work run without transactions on a server that is not a replica set
+	AllowStandalone bool
 }
 
 type PostgresConfig struct {
@@ -48,6 +72,134 @@ type PostgresConfig struct {
 	TimeZone string
 }
 
+// SQLiteConfig configures the SQLite backend; Path may be ":memory:" for a throwaway database
+type SQLiteConfig struct {
+	Path string
+}
+
+// MemoryConfig configures the in-memory backend.
+// When SnapshotPath is set the store is loaded from it at startup and written back on shutdown.
+type MemoryConfig struct {
+	SnapshotPath string
+}
+
+// TrashConfig controls how long soft-deleted entities are kept before they are purged for good.
+// A PurgeInterval of 0 disables the background purge; expired entities are then only purged on request.
+type TrashConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// InvitationConfig controls the links admins hand out to invite users.
+// AcceptURL is the page of the client that posts the token of the link to the accept endpoint.
+type InvitationConfig struct {
+	TTL       time.Duration
+	AcceptURL string
+}
+
+// LoginConfig controls how failed logins are throttled. A username waits BaseDelay after its first failure,
+// twice as long after each further one up to MaxDelay, and is locked out for Lockout after MaxFailures failures
+// within Window. A client IP is locked out after MaxFailuresPerIP failures within Window, whatever the usernames.
+type LoginConfig struct {
+	MaxFailures      int
+	MaxFailuresPerIP int
+	Window           time.Duration
+	Lockout          time.Duration
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+}
+
+// MfaConfig controls two-factor authentication. Issuer names the account in authenticator apps and
+// ChallengeTTL is how long a login waits for its second factor.
+type MfaConfig struct {
+	Issuer       string
+	ChallengeTTL time.Duration
+}
+
+// MailConfig selects how mail is sent. Driver "smtp" delivers it through the SMTP server; "file" writes each
+// mail to OutboxDir instead, for development and tests.
+type MailConfig struct {
+	Driver       string
+	From         string
+	OutboxDir    string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// PasswordResetConfig controls the links mailed to users who forgot their password.
+// URL is the page of the client that posts the token of the link to the reset endpoint.
+type PasswordResetConfig struct {
+	TTL time.Duration
+	URL string
+}
+
+// SmsConfig selects how text messages are sent. Driver "webhook" posts each message as JSON to WebhookURL, the
+// endpoint of an SMS gateway, with WebhookToken as bearer token; "file" writes it to OutboxDir instead.
+type SmsConfig struct {
+	Driver       string
+	OutboxDir    string
+	WebhookURL   string
+	WebhookToken string
+}
+
+// VerificationConfig controls the codes users verify their email and phone with. A code expires after CodeTTL
+// and is discarded after MaxAttempts wrong guesses.
+type VerificationConfig struct {
+	CodeTTL     time.Duration
+	MaxAttempts int
+}
+
+// SigningKeyConfig controls the keys tokens are signed with. Algorithm is "RS256" or "EdDSA". Each key signs for
+// Rotation and is published PublishAhead before it starts, so that verifiers caching the JWKS document pick it up
+// in time; every instance reloads the keys every RefreshInterval. EncryptionKey is the base64 encoded 32 byte
+// key the private keys are encrypted with in the database.
+type SigningKeyConfig struct {
+	Algorithm       string
+	Rotation        time.Duration
+	PublishAhead    time.Duration
+	RefreshInterval time.Duration
+	EncryptionKey   string
+}
+
+// ApiKeyConfig controls the keys of machine clients. Keys expire after TTL unless issued with an expiry of their own,
+// and a rotated key keeps working for RotationGrace so that its client can switch over.
+type ApiKeyConfig struct {
+	TTL           time.Duration
+	RotationGrace time.Duration
+}
+
+// PasswordConfig selects the algorithm, argon2id or bcrypt, and the parameters new password hashes are made with.
+// Hashes made otherwise still verify, and are replaced on the next successful login. Argon2Memory is in KiB.
+type PasswordConfig struct {
+	Algorithm         string
+	Argon2Memory      int
+	Argon2Iterations  int
+	Argon2Parallelism int
+	BcryptCost        int
+}
+
+// OidcConfig lists the external OpenID Connect providers users may sign in with, named in OIDC_PROVIDERS.
+// A sign-in must return from the provider within LoginTTL.
+type OidcConfig struct {
+	Providers []OidcProviderConfig
+	LoginTTL  time.Duration
+}
+
+// OidcProviderConfig is a provider read from the OIDC_<NAME>_* variables. The provider sends the browser back to
+// RedirectURL, a page of the client that posts the state and code to the API. Users provisioned on their first
+// sign-in with the provider get Role.
+type OidcProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	Role         string
+}
+
 // LoadEnv loads the environment configuration based on the environment
 func LoadEnv(env string) error {
 	if env == "" {
@@ -95,6 +247,8 @@ func NewConfig() *Config {
 		}
 	}
 
+	dbConfig.MigrateOnStart, _ = strconv.ParseBool(getEnv("DB_MIGRATE_ON_START", "false"))
+
 	mongoConfig := MongoDBConfig{
 		URI:      getEnv("MONGODB_URI", ""),
 		Host:     getEnv("MONGO_HOST", getEnv("DB_HOST", "localhost")),
@@ -103,6 +257,7 @@ func NewConfig() *Config {
 		Password: getEnv("MONGO_PASSWORD", getEnv("DB_PASSWORD", "")),
 		DbName:   getEnv("MONGO_DB", getEnv("DB_NAME", "liongate")),
 	}
+	mongoConfig.AllowStandalone, _ = strconv.ParseBool(getEnv("MONGO_ALLOW_STANDALONE", "false"))
 
 	postgresConfig := PostgresConfig{
 		Host:     getEnv("POSTGRES_HOST", "localhost"),
@@ -117,12 +272,120 @@ func NewConfig() *Config {
 	return &Config{
 		Env: env,
 		Server: Server{
-			Port: getEnv("SERVER_PORT", "8080"),
+			Port:           getEnv("SERVER_PORT", "8080"),
+			TrustedProxies: getList("SERVER_TRUSTED_PROXIES"),
 		},
 		Database: dbConfig,
 		MongoDB:  mongoConfig,
 		Postgres: postgresConfig,
+		SQLite: SQLiteConfig{
+			Path: getEnv("SQLITE_PATH", "liongate.db"),
+		},
+		Memory: MemoryConfig{
+			SnapshotPath: getEnv("MEMORY_SNAPSHOT_PATH", ""),
+		},
+		Trash: TrashConfig{
+			Retention:     getDuration("TRASH_RETENTION", 30*24*time.Hour),
+			PurgeInterval: getDuration("TRASH_PURGE_INTERVAL", 0),
+		},
+		Invitations: InvitationConfig{
+			TTL:       getDuration("INVITATION_TTL", 72*time.Hour),
+			AcceptURL: getEnv("INVITATION_ACCEPT_URL", "http://localhost:3000/invitations/accept"),
+		},
+		Login: LoginConfig{
+			MaxFailures:      getInt("LOGIN_MAX_FAILURES", 5),
+			MaxFailuresPerIP: getInt("LOGIN_MAX_FAILURES_PER_IP", 50),
+			Window:           getDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			Lockout:          getDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			BaseDelay:        getDuration("LOGIN_BASE_DELAY", time.Second),
+			MaxDelay:         getDuration("LOGIN_MAX_DELAY", 30*time.Second),
+		},
+		Mfa: MfaConfig{
+			Issuer:       getEnv("MFA_ISSUER", "Liongate"),
+			ChallengeTTL: getDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "file"),
+			From:         getEnv("MAIL_FROM", "Liongate <no-reply@liongate.local>"),
+			OutboxDir:    getEnv("MAIL_OUTBOX_DIR", "outbox"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		},
+		PasswordReset: PasswordResetConfig{
+			TTL: getDuration("PASSWORD_RESET_TTL", time.Hour),
+			URL: getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		},
+		Sms: SmsConfig{
+			Driver:       getEnv("SMS_DRIVER", "file"),
+			OutboxDir:    getEnv("SMS_OUTBOX_DIR", "outbox"),
+			WebhookURL:   getEnv("SMS_WEBHOOK_URL", ""),
+			WebhookToken: getEnv("SMS_WEBHOOK_TOKEN", ""),
+		},
+		Verification: VerificationConfig{
+			CodeTTL:     getDuration("VERIFICATION_CODE_TTL", 15*time.Minute),
+			MaxAttempts: getInt("VERIFICATION_MAX_ATTEMPTS", 5),
+		},
+		SigningKeys: SigningKeyConfig{
+			Algorithm:       getEnv("JWT_SIGNING_ALGORITHM", "RS256"),
+			Rotation:        getDuration("JWT_KEY_ROTATION", 30*24*time.Hour),
+			PublishAhead:    getDuration("JWT_KEY_PUBLISH_AHEAD", time.Hour),
+			RefreshInterval: getDuration("JWT_KEY_REFRESH_INTERVAL", time.Minute),
+			EncryptionKey:   getEnv("JWT_KEY_ENCRYPTION_KEY", ""),
+		},
+		ApiKeys: ApiKeyConfig{
+			TTL:           getDuration("API_KEY_TTL", 90*24*time.Hour),
+			RotationGrace: getDuration("API_KEY_ROTATION_GRACE", 24*time.Hour),
+		},
+		Oidc: OidcConfig{
+			Providers: getOidcProviders(),
+			LoginTTL:  getDuration("OIDC_LOGIN_TTL", 10*time.Minute),
+		},
+		Passwords: PasswordConfig{
+			Algorithm:         getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+			Argon2Memory:      getInt("PASSWORD_ARGON2_MEMORY", 19*1024),
+			Argon2Iterations:  getInt("PASSWORD_ARGON2_ITERATIONS", 2),
+			Argon2Parallelism: getInt("PASSWORD_ARGON2_PARALLELISM", 1),
+			BcryptCost:        getInt("PASSWORD_BCRYPT_COST", 12),
+		},
+	}
+}
+
+// getOidcProviders reads the providers named in the comma separated OIDC_PROVIDERS, such as "google,line", from
+// their OIDC_<NAME>_* variables
+func getOidcProviders() []OidcProviderConfig {
+	redirectURL := getEnv("OIDC_REDIRECT_URL", "http://localhost:3000/auth/oidc/callback")
+
+	var providers []OidcProviderConfig
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OidcProviderConfig{
+			Name:         name,
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", redirectURL),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+			Role:         getEnv(prefix+"ROLE", "user"),
+		})
+	}
+	return providers
+}
+
+// getList retrieves the non-empty entries of a comma separated environment variable
+func getList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
 	}
+	return values
 }
 
 // getEnv retrieves an environment variable with a fallback value
@@ -133,6 +396,25 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getDuration retrieves a duration such as "720h" from an environment variable,
+// falling back when it is unset or not a valid non-negative duration
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
+// getInt retrieves a non-negative integer from an environment variable, falling back when it is unset or invalid
+func getInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
 // GetDatabaseConfig returns the appropriate database configuration
 func (c *Config) GetDatabaseConfig() any {
 	switch c.Database.DbType {
@@ -140,6 +422,10 @@ func (c *Config) GetDatabaseConfig() any {
 		return c.MongoDB
 	case "postgresql":
 		return c.Postgres
+	case "sqlite":
+		return c.SQLite
+	case "memory":
+		return c.Memory
 	default:
 		return nil
 	}
@@ -154,3 +440,13 @@ func (c *Config) IsMongoDB() bool {
 func (c *Config) IsPostgres() bool {
 	return c.Database.DbType == "postgresql"
 }
+
+// IsSQLite checks if SQLite is the selected database
+func (c *Config) IsSQLite() bool {
+	return c.Database.DbType == "sqlite"
+}
+
+// IsMemory checks if the in-memory backend is the selected database
+func (c *Config) IsMemory() bool {
+	return c.Database.DbType == "memory"
+}
@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
 	"github.com/khunmostz/be-liongate-go/app/core/port"
 )
@@ -11,41 +13,79 @@ import (
 type AnimalsController struct {
 	svc          port.AnimalsService
 	showRoundSvc port.ShowRoundsService
+	auth         *AuthMiddleware
 }
 
-func NewAnimalsController(svc port.AnimalsService, showRoundSvc port.ShowRoundsService) *AnimalsController {
+func NewAnimalsController(svc port.AnimalsService, showRoundSvc port.ShowRoundsService, auth *AuthMiddleware) *AnimalsController {
 	return &AnimalsController{
 		svc:          svc,
 		showRoundSvc: showRoundSvc,
+		auth:         auth,
 	}
 }
 
 func (ac *AnimalsController) RegisterRoutes(router *gin.Engine) {
 	animals := router.Group("/api/v1/animals")
-	animals.GET("/", ac.GetAnimals)
-	animals.POST("/", ac.CreateAnimal)
-	animals.GET("/:id", ac.GetAnimalById)
-	animals.PUT("/:id", ac.UpdateAnimal)
-	animals.DELETE("/:id", ac.DeleteAnimal)
-	animals.POST("/:id/perform-show/:roundId", ac.PerformShowRound)
+	animals.GET("/", ac.auth.RequireScope(domain.ScopeAnimalsRead), ac.GetAnimals)
+	animals.GET("/:id", ac.auth.RequireScope(domain.ScopeAnimalsRead), ac.GetAnimalById)
+
+	admin := animals.Group("", ac.auth.RequireAuth(), RequireRole(domain.RoleAdmin))
+	admin.POST("/", ac.CreateAnimal)
+	admin.PUT("/:id", ac.UpdateAnimal)
+	admin.DELETE("/:id", ac.DeleteAnimal)
+	admin.POST("/:id/perform-show/:roundId", ac.PerformShowRound)
 }
 
 // GetAnimals godoc
 // @Summary Get all animals
-// @Description Get a list of all animals
+// @Description Get a page of animals, optionally filtered by species and type
 // @Tags animals
 // @Accept json
 // @Produce json
-// @Success 200 {array} domain.Animals
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param species query string false "Filter by species"
+// @Param type query string false "Filter by type"
+// @Param created_from query string false "Only entities created at or after this RFC 3339 time"
+// @Param created_to query string false "Only entities created at or before this RFC 3339 time"
+// @Param updated_from query string false "Only entities last updated at or after this RFC 3339 time"
+// @Param updated_to query string false "Only entities last updated at or before this RFC 3339 time"
+// @Param created_by query string false "Only entities created by this user ID, or anonymous"
+// @Param updated_by query string false "Only entities last updated by this user ID, or anonymous"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (animal_id, name, species, type, show_duration, created_at, updated_at)"
+// @Success 200 {object} port.Page[dto.AnimalResponse]
+// @Failure 400 {object} domain.ProblemDetails "Invalid query parameters"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /animals [get]
 func (ac *AnimalsController) GetAnimals(c *gin.Context) {
-	animals, err := ac.svc.GetAnimals(c)
+	query, err := listQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	audit, err := auditQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filter := port.AnimalFilter{
+		Species: c.Query("species"),
+		Type:    c.Query("type"),
+		Audit:   audit,
+	}
+
+	animals, err := ac.svc.GetAnimals(c, filter, query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, animals)
+	c.JSON(http.StatusOK, dto.NewAnimalPage(animals))
 }
 
 // CreateAnimal godoc
@@ -54,23 +94,27 @@ func (ac *AnimalsController) GetAnimals(c *gin.Context) {
 // @Tags animals
 // @Accept json
 // @Produce json
-// @Param animal body domain.Animals true "Animal information"
-// @Success 201 {object} domain.Animals
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param animal body dto.AnimalRequest true "Animal information"
+// @Success 201 {object} dto.AnimalResponse
+// @Header 201 {string} ETag "Version of the animal"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Security BearerAuth
 // @Router /animals [post]
 func (ac *AnimalsController) CreateAnimal(c *gin.Context) {
-	var animal domain.Animals
-	if err := c.ShouldBindJSON(&animal); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.AnimalRequest
+	if !bindJSON(c, &req) {
 		return
 	}
-	result, err := ac.svc.CreateAnimal(c, &animal)
+	result, err := ac.svc.CreateAnimal(c, req.ToDomain())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusCreated, result)
+	setETag(c, result.Version)
+	c.JSON(http.StatusCreated, dto.NewAnimalResponse(*result))
 }
 
 // GetAnimalById godoc
@@ -80,24 +124,24 @@ func (ac *AnimalsController) CreateAnimal(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Animal ID"
-// @Success 200 {object} domain.Animals
-// @Failure 404 {object} map[string]interface{} "Animal not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Success 200 {object} dto.AnimalResponse
+// @Header 200 {string} ETag "Version of the animal, send it back in If-Match to update or delete it"
+// @Failure 404 {object} domain.ProblemDetails "Animal not found"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /animals/{id} [get]
 func (ac *AnimalsController) GetAnimalById(c *gin.Context) {
 	id := c.Param("id")
 	animal, err := ac.svc.GetAnimalById(c, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	if animal == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, animal)
+	setETag(c, animal.Version)
+	c.JSON(http.StatusOK, dto.NewAnimalResponse(*animal))
 }
 
 // UpdateAnimal godoc
@@ -107,58 +151,78 @@ func (ac *AnimalsController) GetAnimalById(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Animal ID"
-// @Param animal body domain.Animals true "Updated Animal information"
-// @Success 200 {object} domain.Animals
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
-// @Failure 404 {object} map[string]interface{} "Animal not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param If-Match header string true "ETag of the animal being updated, or * for any version"
+// @Param animal body dto.AnimalRequest true "Updated Animal information"
+// @Success 200 {object} dto.AnimalResponse
+// @Header 200 {string} ETag "New version of the animal"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 404 {object} domain.ProblemDetails "Animal not found"
+// @Failure 412 {object} domain.ProblemDetails "Animal was modified since it was read"
+// @Failure 428 {object} domain.ProblemDetails "If-Match header is missing"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Security BearerAuth
 // @Router /animals/{id} [put]
 func (ac *AnimalsController) UpdateAnimal(c *gin.Context) {
 	id := c.Param("id")
 
-	// Check if animal exists
-	_, err := ac.svc.GetAnimalById(c, id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
-	var updatedAnimal domain.Animals
-	if err := c.ShouldBindJSON(&updatedAnimal); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.AnimalRequest
+	if !bindJSON(c, &req) {
 		return
 	}
+	updatedAnimal := req.ToDomain()
+	updatedAnimal.Version = version
 
-	result, err := ac.svc.UpdateAnimal(c, id, &updatedAnimal)
+	result, err := ac.svc.UpdateAnimal(c, id, updatedAnimal)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	setETag(c, result.Version)
+	c.JSON(http.StatusOK, dto.NewAnimalResponse(*result))
 }
 
 // DeleteAnimal godoc
 // @Summary Delete an animal
-// @Description Delete an animal by its ID
+// @Description Move an animal to the trash by its ID; an admin can restore it until it is purged
 // @Tags animals
 // @Accept json
 // @Produce json
 // @Param id path string true "Animal ID"
-// @Success 200 {object} map[string]interface{} "Success message"
-// @Failure 404 {object} map[string]interface{} "Animal not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param If-Match header string true "ETag of the animal being deleted, or * for any version"
+// @Param cascade query bool false "Also delete the animal's show rounds and their bookings"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 404 {object} domain.ProblemDetails "Animal not found"
+// @Failure 409 {object} domain.ProblemDetails "Animal still has show rounds"
+// @Failure 412 {object} domain.ProblemDetails "Animal was modified since it was read"
+// @Failure 428 {object} domain.ProblemDetails "If-Match header is missing"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Security BearerAuth
 // @Router /animals/{id} [delete]
 func (ac *AnimalsController) DeleteAnimal(c *gin.Context) {
 	id := c.Param("id")
 
-	err := ac.svc.DeleteAnimal(c, id)
+	opts, ok := deleteOptions(c)
+	if !ok {
+		return
+	}
+
+	err := ac.svc.DeleteAnimal(c, id, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Animal deleted successfully"})
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Animal deleted successfully"})
 }
 
 // PerformShowRound godoc
@@ -169,10 +233,13 @@ func (ac *AnimalsController) DeleteAnimal(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Animal ID"
 // @Param roundId path string true "Show Round ID"
-// @Success 200 {object} domain.ShowRounds
-// @Failure 400 {object} map[string]interface{} "Bad request"
-// @Failure 404 {object} map[string]interface{} "Animal or show round not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Success 200 {object} dto.PerformanceResponse
+// @Failure 400 {object} domain.ProblemDetails "Bad request"
+// @Failure 404 {object} domain.ProblemDetails "Animal or show round not found"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Security BearerAuth
 // @Router /animals/{id}/perform-show/{roundId} [post]
 func (ac *AnimalsController) PerformShowRound(c *gin.Context) {
 	animalId := c.Param("id")
@@ -181,28 +248,28 @@ func (ac *AnimalsController) PerformShowRound(c *gin.Context) {
 	// Verify animal exists
 	animal, err := ac.svc.GetAnimalById(c, animalId)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
+		c.Error(err)
 		return
 	}
 
 	// Verify show round exists
 	showRound, err := ac.showRoundSvc.GetShowRoundById(c.Request.Context(), roundId)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Show round not found"})
+		c.Error(err)
 		return
 	}
 
 	// Verify this animal is assigned to this show round
 	if showRound.AnimalId != animalId {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "This animal is not assigned to this show round"})
+		c.Error(fmt.Errorf("%w: animal %q is not assigned to show round %q", domain.ErrValidation, animalId, roundId))
 		return
 	}
 
 	// Return the show round details with animal information
-	response := gin.H{
-		"show_round": showRound,
-		"animal":     animal,
-		"status":     "performing",
+	response := dto.PerformanceResponse{
+		ShowRound: dto.NewShowRoundResponse(*showRound),
+		Animal:    dto.NewAnimalResponse(*animal),
+		Status:    "performing",
 	}
 
 	c.JSON(http.StatusOK, response)
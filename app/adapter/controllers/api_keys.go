@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type ApiKeysController struct {
+	svc  port.ApiKeyService
+	auth *AuthMiddleware
+}
+
+func NewApiKeysController(svc port.ApiKeyService, auth *AuthMiddleware) *ApiKeysController {
+	return &ApiKeysController{
+		svc:  svc,
+		auth: auth,
+	}
+}
+
+func (ac *ApiKeysController) RegisterRoutes(router *gin.Engine) {
+	admin := router.Group("/api/v1/admin", ac.auth.RequireAuth(), RequireRole(domain.RoleAdmin))
+	{
+		admin.GET("/api-clients", ac.ListClients)
+		admin.POST("/api-clients", ac.CreateClient)
+		admin.GET("/api-clients/:id/keys", ac.ListKeys)
+		admin.POST("/api-clients/:id/keys", ac.IssueKey)
+		admin.POST("/api-keys/:id/rotate", ac.RotateKey)
+		admin.DELETE("/api-keys/:id", ac.RevokeKey)
+	}
+}
+
+// ListClients godoc
+// @Summary List API clients
+// @Description Get the machine clients that may be issued API keys, ordered by name
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.ApiClientResponse
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/api-clients [get]
+func (ac *ApiKeysController) ListClients(c *gin.Context) {
+	clients, err := ac.svc.ListClients(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewApiClientResponses(clients))
+}
+
+// CreateClient godoc
+// @Summary Register an API client
+// @Description Register a machine client, such as a box-office kiosk or a partner, to issue API keys to
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param client body dto.ApiClientRequest true "Name and description of the client"
+// @Success 201 {object} dto.ApiClientResponse
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 409 {object} domain.ProblemDetails "Name already taken"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/api-clients [post]
+func (ac *ApiKeysController) CreateClient(c *gin.Context) {
+	var req dto.ApiClientRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	client, err := ac.svc.CreateClient(c.Request.Context(), req.ToDomain())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.NewApiClientResponse(*client))
+}
+
+// ListKeys godoc
+// @Summary List the API keys of a client
+// @Description Get every key issued to a client, expired and revoked ones included, oldest first. The keys themselves cannot be read back.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Client ID"
+// @Success 200 {array} dto.ApiKeyResponse
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 404 {object} domain.ProblemDetails "Client not found"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/api-clients/{id}/keys [get]
+func (ac *ApiKeysController) ListKeys(c *gin.Context) {
+	keys, err := ac.svc.ListKeys(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewApiKeyResponses(keys))
+}
+
+// IssueKey godoc
+// @Summary Issue an API key
+// @Description Issue a key with the given scopes to a client. The key expires at expires_at, or after API_KEY_TTL, and is only returned in this response.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Client ID"
+// @Param key body dto.ApiKeyRequest true "Scopes and optional expiry of the key"
+// @Success 201 {object} dto.IssuedApiKeyResponse
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body, unknown scope or expiry in the past"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 404 {object} domain.ProblemDetails "Client not found"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/api-clients/{id}/keys [post]
+func (ac *ApiKeysController) IssueKey(c *gin.Context) {
+	var req dto.ApiKeyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	issued, err := ac.svc.IssueKey(c.Request.Context(), c.Param("id"), req.ToDomain())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.NewIssuedApiKeyResponse(*issued))
+}
+
+// RotateKey godoc
+// @Summary Rotate an API key
+// @Description Issue a successor with the scopes of a key, which is only returned in this response. The old key keeps working for API_KEY_ROTATION_GRACE so that the client can switch over.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Key ID"
+// @Success 201 {object} dto.IssuedApiKeyResponse
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 404 {object} domain.ProblemDetails "Key not found, expired or revoked"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/api-keys/{id}/rotate [post]
+func (ac *ApiKeysController) RotateKey(c *gin.Context) {
+	issued, err := ac.svc.RotateKey(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.NewIssuedApiKeyResponse(*issued))
+}
+
+// RevokeKey godoc
+// @Summary Revoke an API key
+// @Description Stop a key from working at once
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Key ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 404 {object} domain.ProblemDetails "Key not found or already revoked"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/api-keys/{id} [delete]
+func (ac *ApiKeysController) RevokeKey(c *gin.Context) {
+	if err := ac.svc.RevokeKey(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "API key revoked successfully"})
+}
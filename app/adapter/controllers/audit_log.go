@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type AuditLogController struct {
+	svc  port.AuditLogService
+	auth *AuthMiddleware
+}
+
+func NewAuditLogController(svc port.AuditLogService, auth *AuthMiddleware) *AuditLogController {
+	return &AuditLogController{
+		svc:  svc,
+		auth: auth,
+	}
+}
+
+func (ac *AuditLogController) RegisterRoutes(router *gin.Engine) {
+	auditLog := router.Group("/api/v1/admin/audit-log", ac.auth.RequireAuth(), RequireRole(domain.RoleAdmin))
+	auditLog.GET("", ac.ListAuditLog)
+}
+
+// ListAuditLog godoc
+// @Summary List audit log entries
+// @Description Get a page of the audit log of administrative and security events, most recent first, optionally filtered by actor, action, entity and time range
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param actor query string false "Only entries of this user ID, or anonymous"
+// @Param action query string false "Only entries of this action" Enums(login, login_failed, role_changed, stage_price_changed, booking_cancelled, booking_refunded, round_cancelled, invitation_created, invitation_accepted, admin_bootstrapped, login_locked, login_unlocked, mfa_enabled, mfa_disabled, mfa_reset, mfa_recovery_codes_renewed, mfa_requirement_changed, password_reset_requested, password_reset, password_changed, contact_verified, marketing_consent_changed, logout, api_client_created, api_key_issued, api_key_rotated, api_key_revoked, oidc_identity_linked)
+// @Param entity_type query string false "Only entries about this kind of entity" Enums(user, performance_stage, show_round, booking, invitation, login, role, api_client, api_key)
+// @Param entity_id query string false "Only entries about the entity with this ID"
+// @Param from query string false "Only entries that occurred at or after this RFC 3339 time"
+// @Param to query string false "Only entries that occurred at or before this RFC 3339 time"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (entry_id, occurred_at, actor, action, entity_type)"
+// @Success 200 {object} port.Page[dto.AuditLogEntryResponse]
+// @Failure 400 {object} domain.ProblemDetails "Invalid query parameters"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/audit-log [get]
+func (ac *AuditLogController) ListAuditLog(c *gin.Context) {
+	query, err := listQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	from, err := timeQuery(c, "from")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	to, err := timeQuery(c, "to")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filter := port.AuditLogFilter{
+		Actor:      c.Query("actor"),
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityId:   c.Query("entity_id"),
+		From:       from,
+		To:         to,
+	}
+
+	entries, err := ac.svc.ListAuditLog(c, filter, query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewAuditLogPage(entries))
+}
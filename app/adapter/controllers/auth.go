@@ -4,74 +4,145 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
 	"github.com/khunmostz/be-liongate-go/app/core/port"
 )
 
 type AuthController struct {
-	svc port.AuthService
+	svc           port.AuthService
+	passwordReset port.PasswordResetService
+	auth          *AuthMiddleware
 }
 
-func NewAuthController(svc port.AuthService) *AuthController {
-	return &AuthController{svc: svc}
+func NewAuthController(svc port.AuthService, passwordReset port.PasswordResetService, auth *AuthMiddleware) *AuthController {
+	return &AuthController{svc: svc, passwordReset: passwordReset, auth: auth}
 }
 
 func (ac *AuthController) RegisterRoutes(router *gin.Engine) {
 	router.POST("/api/v1/auth/login", ac.Login)
+	router.POST("/api/v1/auth/login/mfa", ac.VerifyMfa)
+	router.POST("/api/v1/auth/login/mfa/enroll", ac.EnrollMfa)
 	router.POST("/api/v1/auth/register", ac.Register)
 	router.POST("/api/v1/auth/refresh-token", ac.RefreshToken)
+	router.POST("/api/v1/auth/logout", ac.auth.RequireAuth(), ac.Logout)
+	router.POST("/api/v1/auth/forgot-password", ac.ForgotPassword)
+	router.POST("/api/v1/auth/reset-password", ac.ResetPassword)
 }
 
 // Login godoc
 // @Summary      User login
-// @Description  Authenticate user with username and password
+// @Description  Authenticate user with username and password. An unknown username and a wrong password fail alike.
+// @Description  After failed attempts the username is delayed, and after too many the username or client IP is locked out for a while.
+// @Description  When the user has two-factor authentication, or their role requires it, the response carries only an mfa challenge to complete at /auth/login/mfa.
 // @Tags         Authentication
 // @Accept       json
 // @Produce      json
-// @Param        request body domain.LoginRequest true "Login credentials"
-// @Success      200 {object} domain.AuthResponse "Successful login"
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param        request body dto.LoginRequest true "Login credentials"
+// @Success      200 {object} dto.AuthResponse "Successful login, or the mfa challenge of a login that needs a second factor"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 401 {object} domain.ProblemDetails "Invalid credentials"
+// @Failure 429 {object} domain.ProblemDetails "Too many failed attempts"
+// @Header 429 {integer} Retry-After "Seconds to wait before the next attempt"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
 // @Router       /auth/login [post]
 func (ac *AuthController) Login(c *gin.Context) {
-	var req domain.LoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.LoginRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	authResponse, err := ac.svc.Login(c.Request.Context(), &req)
+	authResponse, err := ac.svc.Login(c.Request.Context(), req.ToDomain())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, authResponse)
+	c.JSON(http.StatusOK, dto.NewAuthResponse(*authResponse))
+}
+
+// VerifyMfa godoc
+// @Summary      Complete a login with a second factor
+// @Description  Exchange the mfa_token of a login challenge and a code from the authenticator app, or an unused recovery code, for tokens.
+// @Description  When the challenge required enrollment, the code confirms it and the response also carries the recovery codes, which are not shown again.
+// @Description  Invalid codes count as failed logins and are throttled alike.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.MfaLoginRequest true "Challenge token and code"
+// @Success      200 {object} dto.AuthResponse "Successful login"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 401 {object} domain.ProblemDetails "Invalid or expired challenge, or invalid code"
+// @Failure 429 {object} domain.ProblemDetails "Too many failed attempts"
+// @Header 429 {integer} Retry-After "Seconds to wait before the next attempt"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router       /auth/login/mfa [post]
+func (ac *AuthController) VerifyMfa(c *gin.Context) {
+	var req dto.MfaLoginRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	authResponse, err := ac.svc.VerifyMfa(c.Request.Context(), req.ToDomain())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewAuthResponse(*authResponse))
+}
+
+// EnrollMfa godoc
+// @Summary      Enroll during login
+// @Description  Start the enrollment a login challenge with enrollment_required asks for. Add the secret to an authenticator app, then complete the login at /auth/login/mfa with its first code.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.MfaEnrollLoginRequest true "Challenge token"
+// @Success      200 {object} dto.MfaSetupResponse
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 401 {object} domain.ProblemDetails "Invalid or expired challenge"
+// @Failure 409 {object} domain.ProblemDetails "Two-factor authentication is already enabled"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router       /auth/login/mfa/enroll [post]
+func (ac *AuthController) EnrollMfa(c *gin.Context) {
+	var req dto.MfaEnrollLoginRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	setup, err := ac.svc.EnrollMfa(c.Request.Context(), req.MfaToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewMfaSetupResponse(*setup))
 }
 
 // Register godoc
 // @Summary      User registration
-// @Description  Register a new user account
+// @Description  Register a new user account; public registration always gets the user role, other roles are granted through invitations
 // @Tags         Authentication
 // @Accept       json
 // @Produce      json
-// @Param        request body domain.RegisterRequest true "Registration details"
-// @Success      200 {object} domain.AuthResponse "Successful registration"
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param        request body dto.RegisterRequest true "Registration details"
+// @Success      200 {object} dto.AuthResponse "Successful registration"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 409 {object} domain.ProblemDetails "Username already taken"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
 // @Router       /auth/register [post]
 func (ac *AuthController) Register(c *gin.Context) {
-	var req domain.RegisterRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.RegisterRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	authResponse, err := ac.svc.Register(c.Request.Context(), &req)
+	authResponse, err := ac.svc.Register(c.Request.Context(), req.ToDomain())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
 	}
-	c.JSON(http.StatusOK, authResponse)
+	c.JSON(http.StatusOK, dto.NewAuthResponse(*authResponse))
 }
 
 // RefreshToken godoc
@@ -80,22 +151,102 @@ func (ac *AuthController) Register(c *gin.Context) {
 // @Tags         Authentication
 // @Accept       json
 // @Produce      json
-// @Param        request body domain.RefreshTokenRequest true "Refresh token"
-// @Success      200 {object} domain.TokenPair "New token pair"
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param        request body dto.RefreshTokenRequest true "Refresh token"
+// @Success      200 {object} dto.TokenPairResponse "New token pair"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 401 {object} domain.ProblemDetails "Invalid or expired refresh token"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
 // @Router       /auth/refresh-token [post]
 func (ac *AuthController) RefreshToken(c *gin.Context) {
-	var req domain.RefreshTokenRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.RefreshTokenRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	tokenPair, err := ac.svc.RefreshToken(c.Request.Context(), &req)
+	tokenPair, err := ac.svc.RefreshToken(c.Request.Context(), req.ToDomain())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewTokenPairResponse(*tokenPair))
+}
+
+// Logout godoc
+// @Summary      Log out
+// @Description  Revoke the access token of the request, and the refresh token in the body when given, before they expire. Other sessions of the user stay signed in.
+// @Tags         Authentication
+// @Accept       json
+// @Security     BearerAuth
+// @Param        request body dto.LogoutRequest false "Refresh token to revoke as well"
+// @Produce      json
+// @Success      200 {object} dto.MessageResponse
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token, or a refresh token that is invalid or of another user"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router       /auth/logout [post]
+func (ac *AuthController) Logout(c *gin.Context) {
+	var req dto.LogoutRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+	claims, _ := currentClaims(c)
+
+	if err := ac.svc.Logout(c.Request.Context(), claims, req.ToDomain()); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Logged out"})
+}
+
+// ForgotPassword godoc
+// @Summary      Request a password reset link
+// @Description  Mail a link to reset the password to the email address of the user. It answers alike whether the username exists and has an address or not.
+// @Description  The link expires after a while and replaces the links sent before.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.ForgotPasswordRequest true "Username"
+// @Success      202 {object} dto.MessageResponse
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router       /auth/forgot-password [post]
+func (ac *AuthController) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := ac.passwordReset.ForgotPassword(c.Request.Context(), req.ToDomain()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.MessageResponse{Message: "If the account has an email address, a reset link has been sent to it"})
+}
+
+// ResetPassword godoc
+// @Summary      Reset the password
+// @Description  Set a new password with the token of a reset link. The token can be used once, and every session of the user is revoked, so they must log in again.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.ResetPasswordRequest true "Token of the reset link and new password"
+// @Success      200 {object} dto.MessageResponse
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body or password too weak"
+// @Failure 403 {object} domain.ProblemDetails "Unknown, expired or already used token"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router       /auth/reset-password [post]
+func (ac *AuthController) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := ac.passwordReset.ResetPassword(c.Request.Context(), req.ToDomain()); err != nil {
+		c.Error(err)
+		return
 	}
 
-	c.JSON(http.StatusOK, tokenPair)
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Password reset successfully"})
 }
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+func init() {
+	// Report invalid fields by their JSON names, which are the names clients know them by
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+// jsonFieldName returns the name a struct field has in JSON, or "" for fields that are not serialized
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	default:
+		return name
+	}
+}
+
+// bindJSON decodes the request body into obj and records a validation error listing the invalid fields
+// when it is malformed. The handler must stop when it returns false.
+func bindJSON(c *gin.Context, obj any) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		c.Error(bindingError(err))
+		return false
+	}
+	return true
+}
+
+// bindingError translates the errors of the JSON decoder and of the binding validator into domain validation errors
+func bindingError(err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]domain.FieldError, len(validationErrs))
+		for i, fieldErr := range validationErrs {
+			fields[i] = fieldError(fieldErr)
+		}
+		return &domain.ValidationError{Fields: fields}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		fieldErr := domain.NewFieldError(typeErr.Field, domain.RuleType, map[string]string{"type": jsonType(typeErr.Type)})
+		return &domain.ValidationError{Fields: []domain.FieldError{fieldErr}}
+	}
+
+	return fmt.Errorf("%w: malformed JSON body: %v", domain.ErrValidation, err)
+}
+
+// fieldError translates a failed binding rule into the domain rule it stands for
+func fieldError(fieldErr validator.FieldError) domain.FieldError {
+	// The namespace starts with the name of the request struct, which clients do not know
+	_, field, found := strings.Cut(fieldErr.Namespace(), ".")
+	if !found {
+		field = fieldErr.Field()
+	}
+
+	isString := fieldErr.Kind() == reflect.String
+	switch fieldErr.Tag() {
+	case "required":
+		return domain.NewFieldError(field, domain.RuleRequired, nil)
+	case "min", "gte":
+		if isString {
+			return domain.NewFieldError(field, domain.RuleMinLength, map[string]string{"min": fieldErr.Param()})
+		}
+		return domain.NewFieldError(field, domain.RuleMin, map[string]string{"min": fieldErr.Param()})
+	case "max", "lte":
+		if isString {
+			return domain.NewFieldError(field, domain.RuleMaxLength, map[string]string{"max": fieldErr.Param()})
+		}
+		return domain.NewFieldError(field, domain.RuleMax, map[string]string{"max": fieldErr.Param()})
+	case "oneof":
+		return domain.NewFieldError(field, domain.RuleOneOf, map[string]string{"values": strings.ReplaceAll(fieldErr.Param(), " ", ", ")})
+	default:
+		return domain.NewFieldError(field, domain.RuleInvalid, nil)
+	}
+}
+
+// requestLanguage picks the language of field error messages from the Accept-Language header,
+// preferring the supported language with the highest weight and falling back to English
+func requestLanguage(c *gin.Context) domain.Language {
+	language, weight := domain.LanguageEnglish, 0.0
+	for _, entry := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		switch candidate := domain.Language(primary); candidate {
+		case domain.LanguageEnglish, domain.LanguageThai:
+			if q > weight {
+				language, weight = candidate, q
+			}
+		}
+	}
+	return language
+}
+
+// jsonType names the JSON type a Go type is decoded from
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -4,57 +4,68 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
 	"github.com/khunmostz/be-liongate-go/app/core/port"
 )
 
 type BookingsController struct {
-	svc port.BookingsService
+	svc  port.BookingsService
+	auth *AuthMiddleware
 }
 
-func NewBookingsController(svc port.BookingsService) *BookingsController {
+func NewBookingsController(svc port.BookingsService, auth *AuthMiddleware) *BookingsController {
 	return &BookingsController{
-		svc: svc,
+		svc:  svc,
+		auth: auth,
 	}
 }
 
 func (bc *BookingsController) RegisterRoutes(router *gin.Engine) {
 	bookings := router.Group("/api/v1/bookings")
 	{
-		bookings.POST("", bc.CreateBooking)
-		bookings.GET("/:id", bc.GetBookingById)
-		bookings.GET("/user/:userId", bc.GetBookingsByUserId)
-		bookings.GET("/round/:roundId", bc.GetBookingsByRoundId)
-		bookings.PUT("/:id", bc.UpdateBooking)
-		bookings.DELETE("/:id", bc.DeleteBooking)
+		read := bc.auth.RequireScope(domain.ScopeBookingsRead)
+		bookings.GET("", read, bc.ListBookings)
+		bookings.POST("", bc.auth.RequireScope(domain.ScopeBookingsCreate), bc.CreateBooking)
+		bookings.GET("/:id", read, bc.GetBookingById)
+		bookings.GET("/user/:userId", read, bc.GetBookingsByUserId)
+		bookings.GET("/round/:roundId", read, bc.GetBookingsByRoundId)
+		bookings.PUT("/:id", bc.auth.RequireScope(domain.ScopeBookingsUpdate), bc.UpdateBooking)
+		bookings.DELETE("/:id", bc.auth.RequireScope(domain.ScopeBookingsDelete), bc.DeleteBooking)
 	}
 }
 
 // CreateBooking godoc
 // @Summary Create a new booking
-// @Description Create a new booking with the provided information
+// @Description Create a new booking; users book for themselves at the stage's price per seat, only admins and API clients set user_id and price
 // @Tags bookings
 // @Accept json
 // @Produce json
-// @Param booking body domain.Bookings true "Booking information"
-// @Success 201 {object} domain.Bookings
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param booking body dto.BookingRequest true "Booking information"
+// @Success 201 {object} dto.BookingResponse
+// @Header 201 {string} ETag "Version of the booking"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 422 {object} domain.ProblemDetails "Show round or user does not exist"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Failure 403 {object} domain.ProblemDetails "Booking of another user, a field only admins may set, or an API key without the scope"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /bookings [post]
 func (bc *BookingsController) CreateBooking(c *gin.Context) {
-	var booking domain.Bookings
-	if err := c.ShouldBindJSON(&booking); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.BookingRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	result, err := bc.svc.CreateBooking(c.Request.Context(), &booking)
+	result, err := bc.svc.CreateBooking(c.Request.Context(), req.ToDomain())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, result)
+	setETag(c, result.Version)
+	c.JSON(http.StatusCreated, dto.NewBookingResponse(*result))
 }
 
 // GetBookingById godoc
@@ -64,125 +75,221 @@ func (bc *BookingsController) CreateBooking(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Booking ID"
-// @Success 200 {object} domain.Bookings
-// @Failure 404 {object} map[string]interface{} "Booking not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Success 200 {object} dto.BookingResponse
+// @Header 200 {string} ETag "Version of the booking, send it back in If-Match to update or delete it"
+// @Failure 404 {object} domain.ProblemDetails "Booking not found"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Failure 403 {object} domain.ProblemDetails "Booking of another user, a field only admins may set, or an API key without the scope"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /bookings/{id} [get]
 func (bc *BookingsController) GetBookingById(c *gin.Context) {
 	id := c.Param("id")
 	booking, err := bc.svc.GetBookingById(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	if booking == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
-		return
-	}
+	setETag(c, booking.Version)
+	c.JSON(http.StatusOK, dto.NewBookingResponse(*booking))
+}
 
-	c.JSON(http.StatusOK, booking)
+// ListBookings godoc
+// @Summary List bookings
+// @Description Get a page of bookings, optionally filtered by user, show round and status; users only see their own
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param user_id query string false "Filter by user"
+// @Param round_id query string false "Filter by show round"
+// @Param status query string false "Filter by status (confirmed, cancelled, refunded)"
+// @Param created_from query string false "Only entities created at or after this RFC 3339 time"
+// @Param created_to query string false "Only entities created at or before this RFC 3339 time"
+// @Param updated_from query string false "Only entities last updated at or after this RFC 3339 time"
+// @Param updated_to query string false "Only entities last updated at or before this RFC 3339 time"
+// @Param created_by query string false "Only entities created by this user ID, or anonymous"
+// @Param updated_by query string false "Only entities last updated by this user ID, or anonymous"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (booking_id, user_id, round_id, seat_number, price, status, created_at, updated_at)"
+// @Success 200 {object} port.Page[dto.BookingResponse]
+// @Failure 400 {object} domain.ProblemDetails "Invalid query parameters"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Failure 403 {object} domain.ProblemDetails "Booking of another user, a field only admins may set, or an API key without the scope"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /bookings [get]
+func (bc *BookingsController) ListBookings(c *gin.Context) {
+	bc.listBookings(c, port.BookingFilter{
+		UserId:  c.Query("user_id"),
+		RoundId: c.Query("round_id"),
+	})
 }
 
 // GetBookingsByUserId godoc
 // @Summary Get bookings by user ID
-// @Description Get all bookings for a specific user
+// @Description Get a page of bookings for a specific user
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Param userId path string true "User ID"
-// @Success 200 {array} domain.Bookings
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param status query string false "Filter by status (confirmed, cancelled, refunded)"
+// @Param created_from query string false "Only entities created at or after this RFC 3339 time"
+// @Param created_to query string false "Only entities created at or before this RFC 3339 time"
+// @Param updated_from query string false "Only entities last updated at or after this RFC 3339 time"
+// @Param updated_to query string false "Only entities last updated at or before this RFC 3339 time"
+// @Param created_by query string false "Only entities created by this user ID, or anonymous"
+// @Param updated_by query string false "Only entities last updated by this user ID, or anonymous"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (booking_id, user_id, round_id, seat_number, price, status, created_at, updated_at)"
+// @Success 200 {object} port.Page[dto.BookingResponse]
+// @Failure 400 {object} domain.ProblemDetails "Invalid query parameters"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Failure 403 {object} domain.ProblemDetails "Booking of another user, a field only admins may set, or an API key without the scope"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /bookings/user/{userId} [get]
 func (bc *BookingsController) GetBookingsByUserId(c *gin.Context) {
-	userId := c.Param("userId")
-	bookings, err := bc.svc.GetBookingsByUserId(c.Request.Context(), userId)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, bookings)
+	bc.listBookings(c, port.BookingFilter{UserId: c.Param("userId")})
 }
 
 // GetBookingsByRoundId godoc
 // @Summary Get bookings by round ID
-// @Description Get all bookings for a specific show round
+// @Description Get a page of bookings for a specific show round; users only see their own
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Param roundId path string true "Round ID"
-// @Success 200 {array} domain.Bookings
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param status query string false "Filter by status (confirmed, cancelled, refunded)"
+// @Param created_from query string false "Only entities created at or after this RFC 3339 time"
+// @Param created_to query string false "Only entities created at or before this RFC 3339 time"
+// @Param updated_from query string false "Only entities last updated at or after this RFC 3339 time"
+// @Param updated_to query string false "Only entities last updated at or before this RFC 3339 time"
+// @Param created_by query string false "Only entities created by this user ID, or anonymous"
+// @Param updated_by query string false "Only entities last updated by this user ID, or anonymous"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (booking_id, user_id, round_id, seat_number, price, status, created_at, updated_at)"
+// @Success 200 {object} port.Page[dto.BookingResponse]
+// @Failure 400 {object} domain.ProblemDetails "Invalid query parameters"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /bookings/round/{roundId} [get]
 func (bc *BookingsController) GetBookingsByRoundId(c *gin.Context) {
-	roundId := c.Param("roundId")
-	bookings, err := bc.svc.GetBookingsByRoundId(c.Request.Context(), roundId)
+	bc.listBookings(c, port.BookingFilter{RoundId: c.Param("roundId")})
+}
+
+// listBookings serves one page of bookings matching the filter plus the shared status, audit and paging parameters
+func (bc *BookingsController) listBookings(c *gin.Context, filter port.BookingFilter) {
+	query, err := listQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
+	filter.Audit, err = auditQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	filter.Status = c.Query("status")
 
-	c.JSON(http.StatusOK, bookings)
+	bookings, err := bc.svc.ListBookings(c.Request.Context(), filter, query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBookingPage(bookings))
 }
 
 // UpdateBooking godoc
 // @Summary Update a booking
-// @Description Update a booking's information
+// @Description Update a booking's information; users may only change the seat, round or QR code of their own bookings and cancel them
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Param id path string true "Booking ID"
-// @Param booking body domain.Bookings true "Updated Booking information"
-// @Success 200 {object} domain.Bookings
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
-// @Failure 404 {object} map[string]interface{} "Booking not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param If-Match header string true "ETag of the booking being updated, or * for any version"
+// @Param booking body dto.BookingRequest true "Updated Booking information"
+// @Success 200 {object} dto.BookingResponse
+// @Header 200 {string} ETag "New version of the booking"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 404 {object} domain.ProblemDetails "Booking not found"
+// @Failure 412 {object} domain.ProblemDetails "Booking was modified since it was read"
+// @Failure 422 {object} domain.ProblemDetails "Show round or user does not exist"
+// @Failure 428 {object} domain.ProblemDetails "If-Match header is missing"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Failure 403 {object} domain.ProblemDetails "Booking of another user, a field only admins may set, or an API key without the scope"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /bookings/{id} [put]
 func (bc *BookingsController) UpdateBooking(c *gin.Context) {
 	id := c.Param("id")
 
-	// Check if booking exists
-	_, err := bc.svc.GetBookingById(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
-	var updatedBooking domain.Bookings
-	if err := c.ShouldBindJSON(&updatedBooking); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.BookingRequest
+	if !bindJSON(c, &req) {
 		return
 	}
+	updatedBooking := req.ToDomain()
+	updatedBooking.Version = version
 
-	result, err := bc.svc.UpdateBooking(c.Request.Context(), id, &updatedBooking)
+	result, err := bc.svc.UpdateBooking(c.Request.Context(), id, updatedBooking)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	setETag(c, result.Version)
+	c.JSON(http.StatusOK, dto.NewBookingResponse(*result))
 }
 
 // DeleteBooking godoc
 // @Summary Delete a booking
-// @Description Delete a booking by its ID
+// @Description Move a booking to the trash by its ID; an admin can restore it until it is purged
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Param id path string true "Booking ID"
-// @Success 200 {object} map[string]interface{} "Success message"
-// @Failure 404 {object} map[string]interface{} "Booking not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param If-Match header string true "ETag of the booking being deleted, or * for any version"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 404 {object} domain.ProblemDetails "Booking not found"
+// @Failure 412 {object} domain.ProblemDetails "Booking was modified since it was read"
+// @Failure 428 {object} domain.ProblemDetails "If-Match header is missing"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Failure 403 {object} domain.ProblemDetails "Booking of another user, a field only admins may set, or an API key without the scope"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /bookings/{id} [delete]
 func (bc *BookingsController) DeleteBooking(c *gin.Context) {
 	id := c.Param("id")
 
-	err := bc.svc.DeleteBooking(c.Request.Context(), id)
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	err := bc.svc.DeleteBooking(c.Request.Context(), id, port.DeleteOptions{Version: version})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Booking deleted successfully"})
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Booking deleted successfully"})
 }
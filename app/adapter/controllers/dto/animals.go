@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// AnimalRequest is the body of creating or updating an animal; fields left empty on update keep their value
+type AnimalRequest struct {
+	Name         string `json:"name" example:"Leo"`
+	Species      string `json:"species" example:"lion"`
+	Type         string `json:"type" example:"mammal"`
+	ShowDuration int    `json:"show_duration" example:"30"`
+}
+
+func (r AnimalRequest) ToDomain() *domain.Animals {
+	return &domain.Animals{
+		Name:         r.Name,
+		Species:      r.Species,
+		Type:         r.Type,
+		ShowDuration: r.ShowDuration,
+	}
+}
+
+type AnimalResponse struct {
+	Id           string `json:"animal_id"`
+	Name         string `json:"name" example:"Leo"`
+	Species      string `json:"species" example:"lion"`
+	Type         string `json:"type" example:"mammal"`
+	ShowDuration int    `json:"show_duration" example:"30"`
+	Metadata
+}
+
+func NewAnimalResponse(animal domain.Animals) AnimalResponse {
+	return AnimalResponse{
+		Id:           animal.Id,
+		Name:         animal.Name,
+		Species:      animal.Species,
+		Type:         animal.Type,
+		ShowDuration: animal.ShowDuration,
+		Metadata:     newMetadata(animal.Version, animal.Audit),
+	}
+}
+
+func NewAnimalPage(page *port.Page[domain.Animals]) *port.Page[AnimalResponse] {
+	return mapPage(page, NewAnimalResponse)
+}
+
+// PerformanceResponse is the body of an animal performing a show round
+type PerformanceResponse struct {
+	ShowRound ShowRoundResponse `json:"show_round"`
+	Animal    AnimalResponse    `json:"animal"`
+	Status    string            `json:"status" example:"performing"`
+}
@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// ApiClientRequest registers a machine client such as a box-office kiosk or a partner
+type ApiClientRequest struct {
+	Name        string `json:"name" binding:"required" example:"kiosk-north-gate"`
+	Description string `json:"description" example:"Box-office kiosk at the north gate"`
+}
+
+func (r ApiClientRequest) ToDomain() *domain.ApiClientRequest {
+	return &domain.ApiClientRequest{Name: r.Name, Description: r.Description}
+}
+
+type ApiClientResponse struct {
+	Id          string    `json:"client_id"`
+	Name        string    `json:"name" example:"kiosk-north-gate"`
+	Description string    `json:"description" example:"Box-office kiosk at the north gate"`
+	CreatedAt   time.Time `json:"created_at"`
+	CreatedBy   string    `json:"created_by"`
+}
+
+func NewApiClientResponse(client domain.ApiClient) ApiClientResponse {
+	return ApiClientResponse{
+		Id:          client.Id,
+		Name:        client.Name,
+		Description: client.Description,
+		CreatedAt:   client.CreatedAt,
+		CreatedBy:   client.CreatedBy,
+	}
+}
+
+func NewApiClientResponses(clients []domain.ApiClient) []ApiClientResponse {
+	return mapSlice(clients, NewApiClientResponse)
+}
+
+// ApiKeyRequest asks for a key with the given scopes. Without expires_at the key expires after API_KEY_TTL.
+type ApiKeyRequest struct {
+	Scopes    []string   `json:"scopes" binding:"required" example:"bookings:create,rounds:read"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (r ApiKeyRequest) ToDomain() *domain.ApiKeyRequest {
+	return &domain.ApiKeyRequest{Scopes: r.Scopes, ExpiresAt: r.ExpiresAt}
+}
+
+// ApiKeyResponse describes a key without the key itself; prefix holds its first characters to tell keys apart
+type ApiKeyResponse struct {
+	Id         string     `json:"key_id"`
+	ClientId   string     `json:"client_id"`
+	Prefix     string     `json:"prefix" example:"lg_Xk3v9Qa1"`
+	Scopes     []string   `json:"scopes" example:"bookings:create,rounds:read"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	CreatedBy  string     `json:"created_by"`
+}
+
+func NewApiKeyResponse(key domain.ApiKey) ApiKeyResponse {
+	return ApiKeyResponse{
+		Id:         key.Id,
+		ClientId:   key.ClientId,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+		CreatedBy:  key.CreatedBy,
+	}
+}
+
+func NewApiKeyResponses(keys []domain.ApiKey) []ApiKeyResponse {
+	return mapSlice(keys, NewApiKeyResponse)
+}
+
+// IssuedApiKeyResponse describes a newly issued key. The key is only ever returned here; it cannot be read back later.
+type IssuedApiKeyResponse struct {
+	ApiKeyResponse
+	Key string `json:"key" example:"lg_Xk3v9Qa1..."`
+}
+
+func NewIssuedApiKeyResponse(issued domain.IssuedApiKey) IssuedApiKeyResponse {
+	return IssuedApiKeyResponse{
+		ApiKeyResponse: NewApiKeyResponse(*issued.ApiKey),
+		Key:            issued.Key,
+	}
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type AuditLogEntryResponse struct {
+	Id         string         `json:"entry_id"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	Actor      string         `json:"actor"`
+	Action     string         `json:"action" example:"role_changed"`
+	EntityType string         `json:"entity_type" example:"user"`
+	EntityId   string         `json:"entity_id"`
+	Before     map[string]any `json:"before,omitempty"`
+	After      map[string]any `json:"after,omitempty"`
+	IP         string         `json:"ip" example:"203.0.113.7"`
+	RequestId  string         `json:"request_id"`
+}
+
+func NewAuditLogEntryResponse(entry domain.AuditLogEntry) AuditLogEntryResponse {
+	return AuditLogEntryResponse{
+		Id:         entry.Id,
+		OccurredAt: entry.OccurredAt,
+		Actor:      entry.Actor,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityId:   entry.EntityId,
+		Before:     entry.Before,
+		After:      entry.After,
+		IP:         entry.IP,
+		RequestId:  entry.RequestId,
+	}
+}
+
+func NewAuditLogPage(page *port.Page[domain.AuditLogEntry]) *port.Page[AuditLogEntryResponse] {
+	return mapPage(page, NewAuditLogEntryResponse)
+}
@@ -0,0 +1,114 @@
+package dto
+
+import (
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+type LoginRequest struct {
+	Username string `json:"username" binding:"required" example:"somchai"`
+	Password string `json:"password" binding:"required" example:"s3cret-pass"`
+}
+
+func (r LoginRequest) ToDomain() *domain.LoginRequest {
+	return &domain.LoginRequest{Username: r.Username, Password: r.Password}
+}
+
+// RegisterRequest is the body of public registration, which always creates a user with the user role.
+// The optional email is where password reset links are sent; it is never returned by the API.
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required" example:"somchai"`
+	Password string `json:"password" binding:"required" example:"s3cret-pass"`
+	Email    string `json:"email" example:"somchai@example.com"`
+}
+
+func (r RegisterRequest) ToDomain() *domain.RegisterRequest {
+	return &domain.RegisterRequest{Username: r.Username, Password: r.Password, Email: r.Email}
+}
+
+func (r RegisterRequest) ToUser() *domain.Users {
+	return &domain.Users{Username: r.Username, Password: r.Password, Email: r.Email}
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func (r RefreshTokenRequest) ToDomain() *domain.RefreshTokenRequest {
+	return &domain.RefreshTokenRequest{RefreshToken: r.RefreshToken}
+}
+
+// LogoutRequest optionally names the refresh token to revoke along with the access token of the request
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (r LogoutRequest) ToDomain() *domain.LogoutRequest {
+	return &domain.LogoutRequest{RefreshToken: r.RefreshToken}
+}
+
+// ForgotPasswordRequest asks for a password reset link to be mailed to the address of the user
+type ForgotPasswordRequest struct {
+	Username string `json:"username" binding:"required" example:"somchai"`
+}
+
+func (r ForgotPasswordRequest) ToDomain() *domain.ForgotPasswordRequest {
+	return &domain.ForgotPasswordRequest{Username: r.Username}
+}
+
+// ResetPasswordRequest sets a new password with the token of a reset link
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required" example:"n3w-s3cret-pass"`
+}
+
+func (r ResetPasswordRequest) ToDomain() *domain.ResetPasswordRequest {
+	return &domain.ResetPasswordRequest{Token: r.Token, Password: r.Password}
+}
+
+type TokenPairResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func NewTokenPairResponse(tokens domain.TokenPair) TokenPairResponse {
+	return TokenPairResponse{AccessToken: tokens.AccessToken, RefreshToken: tokens.RefreshToken}
+}
+
+// AuthResponse is the body of a login or registration: the user and the tokens issued to them.
+// A login that still needs a second factor only carries mfa; one that completes an enrollment also carries
+// the recovery codes, which are not shown again.
+type AuthResponse struct {
+	User          *UserResponse         `json:"user,omitempty"`
+	Tokens        *TokenPairResponse    `json:"tokens,omitempty"`
+	Mfa           *MfaChallengeResponse `json:"mfa,omitempty"`
+	RecoveryCodes []string              `json:"recovery_codes,omitempty"`
+}
+
+func NewAuthResponse(auth domain.AuthResponse) AuthResponse {
+	response := AuthResponse{RecoveryCodes: auth.RecoveryCodes}
+	if auth.User != nil {
+		user := NewUserResponse(*auth.User)
+		response.User = &user
+	}
+	if auth.Tokens != nil {
+		tokens := NewTokenPairResponse(*auth.Tokens)
+		response.Tokens = &tokens
+	}
+	if auth.Mfa != nil {
+		challenge := NewMfaChallengeResponse(*auth.Mfa)
+		response.Mfa = &challenge
+	}
+	return response
+}
+
+// JWKSResponse is the JWKS document: the public keys tokens are signed with, selected by their kid
+type JWKSResponse struct {
+	Keys []domain.JSONWebKey `json:"keys"`
+}
+
+func NewJWKSResponse(keys []domain.JSONWebKey) JWKSResponse {
+	if keys == nil {
+		keys = []domain.JSONWebKey{}
+	}
+	return JWKSResponse{Keys: keys}
+}
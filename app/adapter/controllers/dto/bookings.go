@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// BookingRequest is the body of creating or updating a booking; fields left empty on update keep their value.
+// UserId and Price are only taken from admins and API clients.
+type BookingRequest struct {
+	UserId     string  `json:"user_id"`
+	RoundId    string  `json:"round_id"`
+	SeatNumber int     `json:"seat_number" example:"12"`
+	Price      float64 `json:"price" example:"250"`
+	QrCode     string  `json:"qr_code"`
+	Status     string  `json:"status" example:"confirmed" enums:"confirmed,cancelled,refunded"`
+}
+
+func (r BookingRequest) ToDomain() *domain.Bookings {
+	return &domain.Bookings{
+		UserId:     r.UserId,
+		RoundId:    r.RoundId,
+		SeatNumber: r.SeatNumber,
+		Price:      r.Price,
+		QrCode:     r.QrCode,
+		Status:     r.Status,
+	}
+}
+
+type BookingResponse struct {
+	Id         string  `json:"booking_id"`
+	UserId     string  `json:"user_id"`
+	RoundId    string  `json:"round_id"`
+	SeatNumber int     `json:"seat_number" example:"12"`
+	Price      float64 `json:"price" example:"250"`
+	QrCode     string  `json:"qr_code"`
+	Status     string  `json:"status" example:"confirmed" enums:"confirmed,cancelled,refunded"`
+	Metadata
+}
+
+func NewBookingResponse(booking domain.Bookings) BookingResponse {
+	return BookingResponse{
+		Id:         booking.Id,
+		UserId:     booking.UserId,
+		RoundId:    booking.RoundId,
+		SeatNumber: booking.SeatNumber,
+		Price:      booking.Price,
+		QrCode:     booking.QrCode,
+		Status:     booking.Status,
+		Metadata:   newMetadata(booking.Version, booking.Audit),
+	}
+}
+
+func NewBookingPage(page *port.Page[domain.Bookings]) *port.Page[BookingResponse] {
+	return mapPage(page, NewBookingResponse)
+}
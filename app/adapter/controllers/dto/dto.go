@@ -0,0 +1,56 @@
+// Package dto holds the request and response bodies of the HTTP API and their mapping to and from domain entities.
+// Requests carry only the fields clients may set and responses only the fields clients may see, so that persistence
+// structs and wire formats can change independently and secrets such as password hashes never leave the API.
+package dto
+
+import (
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// Metadata is the version and the audit stamps every entity response carries
+type Metadata struct {
+	Version   int64     `json:"version" example:"1"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedBy string    `json:"created_by" example:"anonymous"`
+	UpdatedBy string    `json:"updated_by" example:"anonymous"`
+}
+
+func newMetadata(version int64, audit domain.Audit) Metadata {
+	return Metadata{
+		Version:   version,
+		CreatedAt: audit.CreatedAt,
+		UpdatedAt: audit.UpdatedAt,
+		CreatedBy: audit.CreatedBy,
+		UpdatedBy: audit.UpdatedBy,
+	}
+}
+
+// MessageResponse is the body of endpoints that only confirm an action
+type MessageResponse struct {
+	Message string `json:"message" example:"Animal deleted successfully"`
+}
+
+// mapSlice maps every element of items, keeping nil as nil
+func mapSlice[S, T any](items []S, mapItem func(S) T) []T {
+	if items == nil {
+		return nil
+	}
+	mapped := make([]T, len(items))
+	for i, item := range items {
+		mapped[i] = mapItem(item)
+	}
+	return mapped
+}
+
+// mapPage maps the items of a page and keeps its paging metadata
+func mapPage[S, T any](page *port.Page[S], mapItem func(S) T) *port.Page[T] {
+	return &port.Page[T]{
+		Items:      mapSlice(page.Items, mapItem),
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+	}
+}
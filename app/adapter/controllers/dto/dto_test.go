@@ -0,0 +1,157 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decode unmarshals body into a request the way the controllers bind it
+func decode[T any](t *testing.T, body string) T {
+	t.Helper()
+	var req T
+	require.NoError(t, json.Unmarshal([]byte(body), &req))
+	return req
+}
+
+// fields returns the JSON object v encodes to
+func fields(t *testing.T, v any) map[string]any {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	var object map[string]any
+	require.NoError(t, json.Unmarshal(data, &object))
+	return object
+}
+
+func testUser() domain.Users {
+	created := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	return domain.Users{
+		Id:       "user-1",
+		Username: "somchai",
+		Password: "$2a$10$hash",
+		Role:     domain.RoleUser,
+		Bookings: []domain.Bookings{{Id: "booking-1", UserId: "user-1", RoundId: "round-1", SeatNumber: 4, Version: 2}},
+		Version:  3,
+		Audit:    domain.Audit{CreatedAt: created, UpdatedAt: created.Add(time.Hour), CreatedBy: "anonymous", UpdatedBy: "admin-1"},
+	}
+}
+
+func TestUserResponseHidesPassword(t *testing.T) {
+	response := NewUserResponse(testUser())
+
+	assert.Equal(t, "user-1", response.Id)
+	assert.Equal(t, int64(3), response.Version)
+	assert.Equal(t, "admin-1", response.UpdatedBy)
+	require.Len(t, response.Bookings, 1)
+	assert.Equal(t, "booking-1", response.Bookings[0].Id)
+	assert.Equal(t, int64(2), response.Bookings[0].Version)
+
+	object := fields(t, response)
+	assert.NotContains(t, object, "password")
+	assert.Equal(t, "somchai", object["username"])
+	assert.Equal(t, float64(3), object["version"])
+	assert.Contains(t, object, "created_at")
+}
+
+func TestAuthResponseHidesPassword(t *testing.T) {
+	user := testUser()
+	response := NewAuthResponse(domain.AuthResponse{
+		User:   &user,
+		Tokens: &domain.TokenPair{AccessToken: "access", RefreshToken: "refresh"},
+	})
+
+	object := fields(t, response)
+	assert.NotContains(t, object["user"], "password")
+	assert.Equal(t, map[string]any{"access_token": "access", "refresh_token": "refresh"}, object["tokens"])
+}
+
+func TestRequestsIgnoreServerOwnedFields(t *testing.T) {
+	user := decode[UpdateUserRequest](t, `{"user_id":"forged","username":"ann","password":"secret","role":"user","version":9,"bookings":[{"booking_id":"b"}]}`).ToDomain()
+	assert.Equal(t, &domain.Users{Username: "ann", Password: "secret", Role: domain.RoleUser}, user)
+
+	animal := decode[AnimalRequest](t, `{"animal_id":"forged","name":"Leo","species":"lion","show_duration":30,"created_by":"someone"}`).ToDomain()
+	assert.Equal(t, &domain.Animals{Name: "Leo", Species: "lion", ShowDuration: 30}, animal)
+
+	stage := decode[StageRequest](t, `{"stage_id":"forged","room_number":"A1","seat_capacity":50,"price_per_seat":120}`).ToDomain()
+	assert.Equal(t, &domain.PerformanceStage{RoomNumber: "A1", SeatCapacity: 50, PricePerSeat: 120}, stage)
+
+	showRound := decode[ShowRoundRequest](t, `{"round_id":"forged","animal_id":"a","stage_id":"s","show_time":"2025-01-01T10:00:00Z","bookings":[{}]}`).ToDomain()
+	assert.Equal(t, &domain.ShowRounds{AnimalId: "a", StageId: "s", ShowTime: "2025-01-01T10:00:00Z"}, showRound)
+
+	booking := decode[BookingRequest](t, `{"booking_id":"forged","user_id":"u","round_id":"r","seat_number":4,"price":120,"status":"confirmed","version":5}`).ToDomain()
+	assert.Equal(t, &domain.Bookings{UserId: "u", RoundId: "r", SeatNumber: 4, Price: 120, Status: domain.BookingStatusConfirmed}, booking)
+}
+
+func TestResponsesMapEveryField(t *testing.T) {
+	audit := domain.Audit{CreatedBy: "admin-1", UpdatedBy: "admin-2"}
+
+	animal := NewAnimalResponse(domain.Animals{Id: "a", Name: "Leo", Species: "lion", Type: "mammal", ShowDuration: 30, Version: 2, Audit: audit})
+	assert.Equal(t, AnimalResponse{Id: "a", Name: "Leo", Species: "lion", Type: "mammal", ShowDuration: 30,
+		Metadata: Metadata{Version: 2, CreatedBy: "admin-1", UpdatedBy: "admin-2"}}, animal)
+
+	stage := NewStageResponse(domain.PerformanceStage{Id: "s", RoomNumber: "A1", SeatCapacity: 50, PricePerSeat: 120, Version: 1})
+	assert.Equal(t, StageResponse{Id: "s", RoomNumber: "A1", SeatCapacity: 50, PricePerSeat: 120, Metadata: Metadata{Version: 1}}, stage)
+
+	showRound := NewShowRoundResponse(domain.ShowRounds{Id: "r", AnimalId: "a", StageId: "s", ShowTime: "2025-01-01T10:00:00Z"})
+	assert.Equal(t, ShowRoundResponse{Id: "r", AnimalId: "a", StageId: "s", ShowTime: "2025-01-01T10:00:00Z"}, showRound)
+	assert.NotContains(t, fields(t, showRound), "bookings")
+
+	entry := NewAuditLogEntryResponse(domain.AuditLogEntry{Id: "e", Actor: "admin-1", Action: "role_changed", EntityType: "user",
+		EntityId: "u", Before: map[string]any{"role": "user"}, After: map[string]any{"role": "admin"}, IP: "203.0.113.7", RequestId: "req"})
+	assert.Equal(t, AuditLogEntryResponse{Id: "e", Actor: "admin-1", Action: "role_changed", EntityType: "user",
+		EntityId: "u", Before: map[string]any{"role": "user"}, After: map[string]any{"role": "admin"}, IP: "203.0.113.7", RequestId: "req"}, entry)
+}
+
+func TestProfile(t *testing.T) {
+	consent := true
+	user := testUser()
+	user.Email, user.VerifiedEmail = "somchai@example.com", "somchai@example.com"
+	user.Phone, user.VerifiedPhone = "+66812345678", "+66899999999"
+	user.MarketingConsent = &consent
+
+	object := fields(t, NewProfileResponse(user))
+	assert.NotContains(t, object, "password")
+	assert.NotContains(t, object, "verified_email")
+	assert.Equal(t, true, object["email_verified"])
+	assert.Equal(t, false, object["phone_verified"], "the verified phone is an older number")
+	assert.Equal(t, true, object["marketing_consent"])
+
+	update := decode[UpdateProfileRequest](t, `{"display_name":"Somchai","marketing_consent":false,"verified_email":"forged@example.com","role":"admin"}`).ToDomain()
+	withdrawn := false
+	assert.Equal(t, &domain.ProfileUpdate{DisplayName: "Somchai", MarketingConsent: &withdrawn}, update)
+
+	update = decode[UpdateProfileRequest](t, `{"language":"en"}`).ToDomain()
+	assert.Nil(t, update.MarketingConsent, "leaving marketing_consent out keeps it")
+}
+
+func TestPageKeepsPaging(t *testing.T) {
+	page := NewAnimalPage(&port.Page[domain.Animals]{
+		Items:      []domain.Animals{{Id: "a"}, {Id: "b"}},
+		Total:      7,
+		NextCursor: "next",
+	})
+
+	assert.Equal(t, int64(7), page.Total)
+	assert.Equal(t, "next", page.NextCursor)
+	assert.Equal(t, []AnimalResponse{{Id: "a"}, {Id: "b"}}, page.Items)
+}
+
+func TestTrashItemMapsEntity(t *testing.T) {
+	deletedAt := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	user := testUser()
+	user.DeletedAt = &deletedAt
+
+	response := NewTrashItemResponse(port.NewTrashItem(port.TrashUsers, &user))
+
+	assert.Equal(t, port.TrashUsers, response.Kind)
+	assert.Equal(t, "user-1", response.Id)
+	assert.Equal(t, deletedAt, response.DeletedAt)
+	assert.IsType(t, UserResponse{}, response.Entity)
+	assert.NotContains(t, fields(t, response)["entity"], "password")
+}
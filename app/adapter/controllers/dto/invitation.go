@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+type InvitationRequest struct {
+	Role string `json:"role" binding:"required" example:"admin" enums:"admin,user"`
+}
+
+func (r InvitationRequest) ToDomain() *domain.InvitationRequest {
+	return &domain.InvitationRequest{Role: r.Role}
+}
+
+// InvitationResponse describes a created invitation and the link to hand to the invitee.
+// The token is only ever returned here; it cannot be read back later.
+type InvitationResponse struct {
+	Id        string    `json:"invitation_id"`
+	Role      string    `json:"role" example:"admin"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Token     string    `json:"token"`
+	URL       string    `json:"url" example:"http://localhost:3000/invitations/accept?token=eyJhbGciOi..."`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by"`
+}
+
+func NewInvitationResponse(link domain.InvitationLink) InvitationResponse {
+	return InvitationResponse{
+		Id:        link.Invitation.Id,
+		Role:      link.Invitation.Role,
+		ExpiresAt: link.Invitation.ExpiresAt,
+		Token:     link.Token,
+		URL:       link.URL,
+		CreatedAt: link.Invitation.CreatedAt,
+		CreatedBy: link.Invitation.CreatedBy,
+	}
+}
+
+// AcceptInvitationRequest signs up with the token of an invitation link
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Username string `json:"username" binding:"required" example:"somchai"`
+	Password string `json:"password" binding:"required" example:"s3cret-pass"`
+}
+
+func (r AcceptInvitationRequest) ToDomain() *domain.AcceptInvitationRequest {
+	return &domain.AcceptInvitationRequest{Token: r.Token, Username: r.Username, Password: r.Password}
+}
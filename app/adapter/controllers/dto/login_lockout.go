@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// LoginLockoutResponse describes a username or client IP that is locked out after failed logins
+type LoginLockoutResponse struct {
+	Kind          string     `json:"kind" example:"username" enums:"username,ip"`
+	Value         string     `json:"value" example:"alice"`
+	Failures      int        `json:"failures" example:"5"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+}
+
+func NewLoginLockoutResponse(attempt domain.LoginAttempt) LoginLockoutResponse {
+	kind, value, _ := strings.Cut(attempt.Key, ":")
+	return LoginLockoutResponse{
+		Kind:          kind,
+		Value:         value,
+		Failures:      attempt.Failures,
+		LastFailureAt: attempt.LastFailureAt,
+		LockedUntil:   attempt.LockedUntil,
+	}
+}
+
+func NewLoginLockoutResponses(attempts []domain.LoginAttempt) []LoginLockoutResponse {
+	return mapSlice(attempts, NewLoginLockoutResponse)
+}
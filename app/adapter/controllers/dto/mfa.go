@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"encoding/base64"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// MfaLoginRequest completes a login with the token of its challenge and a code from the authenticator app,
+// or one of the recovery codes instead
+type MfaLoginRequest struct {
+	MfaToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code" example:"123456"`
+	RecoveryCode string `json:"recovery_code" example:"k7m2p-x9qrt"`
+}
+
+func (r MfaLoginRequest) ToDomain() *domain.MfaLoginRequest {
+	return &domain.MfaLoginRequest{Token: r.MfaToken, Code: r.Code, RecoveryCode: r.RecoveryCode}
+}
+
+// MfaEnrollLoginRequest starts the enrollment a login was held back for
+type MfaEnrollLoginRequest struct {
+	MfaToken string `json:"mfa_token" binding:"required"`
+}
+
+// MfaConfirmRequest confirms an enrollment with the first code of the authenticator app
+type MfaConfirmRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+// MfaCodeRequest proves possession of the second factor with a code or a recovery code
+type MfaCodeRequest struct {
+	Code         string `json:"code" example:"123456"`
+	RecoveryCode string `json:"recovery_code" example:"k7m2p-x9qrt"`
+}
+
+func (r MfaCodeRequest) ToDomain() *domain.MfaCodeRequest {
+	return &domain.MfaCodeRequest{Code: r.Code, RecoveryCode: r.RecoveryCode}
+}
+
+// MfaChallengeResponse is returned by a login that needs a second factor. With enrollment_required the user must
+// first enroll with the token, then complete the login with a code like any other.
+type MfaChallengeResponse struct {
+	MfaToken           string    `json:"mfa_token"`
+	ExpiresAt          time.Time `json:"expires_at"`
+	EnrollmentRequired bool      `json:"enrollment_required"`
+}
+
+func NewMfaChallengeResponse(challenge domain.MfaChallenge) MfaChallengeResponse {
+	return MfaChallengeResponse{
+		MfaToken:           challenge.Token,
+		ExpiresAt:          challenge.ExpiresAt,
+		EnrollmentRequired: challenge.EnrollmentRequired,
+	}
+}
+
+// MfaSetupResponse is what an authenticator app needs to enroll; qr_code is a PNG data URI of otpauth_uri
+type MfaSetupResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	OtpauthURI string `json:"otpauth_uri" example:"otpauth://totp/Liongate:somchai?issuer=Liongate&secret=JBSWY3DPEHPK3PXP"`
+	QRCode     string `json:"qr_code" example:"data:image/png;base64,iVBORw0KGgo="`
+}
+
+func NewMfaSetupResponse(setup domain.MfaSetup) MfaSetupResponse {
+	return MfaSetupResponse{
+		Secret:     setup.Secret,
+		OtpauthURI: setup.URI,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(setup.QRCode),
+	}
+}
+
+// RecoveryCodesResponse holds new recovery codes; they are stored hashed and cannot be shown again
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MfaRequirementRequest turns the requirement of a second factor for a role on or off
+type MfaRequirementRequest struct {
+	Required *bool `json:"required" binding:"required" example:"true"`
+}
+
+// MfaRequirementResponse is a role whose users must log in with a second factor
+type MfaRequirementResponse struct {
+	Role      string    `json:"role" example:"admin"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by"`
+}
+
+func NewMfaRequirementResponse(requirement domain.MfaRequirement) MfaRequirementResponse {
+	return MfaRequirementResponse{Role: requirement.Role, CreatedAt: requirement.CreatedAt, CreatedBy: requirement.CreatedBy}
+}
+
+func NewMfaRequirementResponses(requirements []domain.MfaRequirement) []MfaRequirementResponse {
+	return mapSlice(requirements, NewMfaRequirementResponse)
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// OidcAuthorizationResponse tells the client where to send the browser. The client keeps state and posts the code
+// to /auth/oidc/callback only when the provider returns the same state.
+type OidcAuthorizationResponse struct {
+	AuthorizationURL string    `json:"authorization_url" example:"https://accounts.google.com/o/oauth2/v2/auth?client_id=..."`
+	State            string    `json:"state" example:"Vg3x8Q6Jt5l2..."`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+func NewOidcAuthorizationResponse(authorization domain.OidcAuthorization) OidcAuthorizationResponse {
+	return OidcAuthorizationResponse{
+		AuthorizationURL: authorization.URL,
+		State:            authorization.State,
+		ExpiresAt:        authorization.ExpiresAt,
+	}
+}
+
+// OidcCallbackRequest carries the state and code the provider redirected the browser back with
+type OidcCallbackRequest struct {
+	State string `json:"state" binding:"required" example:"Vg3x8Q6Jt5l2..."`
+	Code  string `json:"code" binding:"required" example:"4/0AfJohXk..."`
+}
+
+func (r OidcCallbackRequest) ToDomain() *domain.OidcLoginRequest {
+	return &domain.OidcLoginRequest{State: r.State, Code: r.Code}
+}
+
+type OidcIdentityResponse struct {
+	Id        string    `json:"identity_id"`
+	Provider  string    `json:"provider" example:"google"`
+	Subject   string    `json:"subject" example:"110248495921238986420"`
+	Email     string    `json:"email,omitempty" example:"ann@example.com"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewOidcIdentityResponse(identity domain.OidcIdentity) OidcIdentityResponse {
+	return OidcIdentityResponse{
+		Id:        identity.Id,
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: identity.CreatedAt,
+	}
+}
+
+func NewOidcIdentityResponses(identities []domain.OidcIdentity) []OidcIdentityResponse {
+	return mapSlice(identities, NewOidcIdentityResponse)
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// StageRequest is the body of creating or updating a performance stage; fields left empty on update keep their value
+type StageRequest struct {
+	RoomNumber   string  `json:"room_number" example:"A101"`
+	SeatCapacity int     `json:"seat_capacity" example:"120"`
+	PricePerSeat float64 `json:"price_per_seat" example:"250"`
+}
+
+func (r StageRequest) ToDomain() *domain.PerformanceStage {
+	return &domain.PerformanceStage{
+		RoomNumber:   r.RoomNumber,
+		SeatCapacity: r.SeatCapacity,
+		PricePerSeat: r.PricePerSeat,
+	}
+}
+
+type StageResponse struct {
+	Id           string  `json:"stage_id"`
+	RoomNumber   string  `json:"room_number" example:"A101"`
+	SeatCapacity int     `json:"seat_capacity" example:"120"`
+	PricePerSeat float64 `json:"price_per_seat" example:"250"`
+	Metadata
+}
+
+func NewStageResponse(stage domain.PerformanceStage) StageResponse {
+	return StageResponse{
+		Id:           stage.Id,
+		RoomNumber:   stage.RoomNumber,
+		SeatCapacity: stage.SeatCapacity,
+		PricePerSeat: stage.PricePerSeat,
+		Metadata:     newMetadata(stage.Version, stage.Audit),
+	}
+}
+
+func NewStagePage(page *port.Page[domain.PerformanceStage]) *port.Page[StageResponse] {
+	return mapPage(page, NewStageResponse)
+}
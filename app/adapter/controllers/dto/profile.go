@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// UpdateProfileRequest is the body of updating one's own profile; fields left out keep their value.
+// A new email or phone must be verified again.
+type UpdateProfileRequest struct {
+	DisplayName      string `json:"display_name" example:"Somchai J."`
+	Email            string `json:"email" example:"somchai@example.com"`
+	Phone            string `json:"phone" example:"+66812345678"`
+	Language         string `json:"language" example:"th" enums:"en,th"`
+	MarketingConsent *bool  `json:"marketing_consent" example:"true"`
+}
+
+func (r UpdateProfileRequest) ToDomain() *domain.ProfileUpdate {
+	return &domain.ProfileUpdate{
+		DisplayName:      r.DisplayName,
+		Email:            r.Email,
+		Phone:            r.Phone,
+		Language:         r.Language,
+		MarketingConsent: r.MarketingConsent,
+	}
+}
+
+// ProfileResponse describes the caller's own user, contact details included
+type ProfileResponse struct {
+	Id               string `json:"user_id"`
+	Username         string `json:"username" example:"somchai"`
+	Role             string `json:"role" example:"user"`
+	DisplayName      string `json:"display_name" example:"Somchai J."`
+	Email            string `json:"email" example:"somchai@example.com"`
+	EmailVerified    bool   `json:"email_verified"`
+	Phone            string `json:"phone" example:"+66812345678"`
+	PhoneVerified    bool   `json:"phone_verified"`
+	Language         string `json:"language" example:"th"`
+	MarketingConsent bool   `json:"marketing_consent"`
+	Metadata
+}
+
+func NewProfileResponse(user domain.Users) ProfileResponse {
+	return ProfileResponse{
+		Id:               user.Id,
+		Username:         user.Username,
+		Role:             user.Role,
+		DisplayName:      user.DisplayName,
+		Email:            user.Email,
+		EmailVerified:    user.ContactVerified(domain.ContactEmail),
+		Phone:            user.Phone,
+		PhoneVerified:    user.ContactVerified(domain.ContactPhone),
+		Language:         user.Language,
+		MarketingConsent: user.ConsentsToMarketing(),
+		Metadata:         newMetadata(user.Version, user.Audit),
+	}
+}
+
+// VerifyContactRequest confirms an email or phone with the code sent to it
+type VerifyContactRequest struct {
+	Code string `json:"code" binding:"required" example:"042917"`
+}
+
+// VerificationSentResponse tells where a verification code was sent and until when it can be used
+type VerificationSentResponse struct {
+	Channel   string    `json:"channel" example:"phone"`
+	Target    string    `json:"target" example:"+66812345678"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewVerificationSentResponse(verification domain.ContactVerification) VerificationSentResponse {
+	return VerificationSentResponse{
+		Channel:   verification.Channel,
+		Target:    verification.Target,
+		ExpiresAt: verification.ExpiresAt,
+	}
+}
@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// ShowRoundRequest is the body of creating or updating a show round; fields left empty on update keep their value
+type ShowRoundRequest struct {
+	AnimalId string `json:"animal_id"`
+	StageId  string `json:"stage_id"`
+	ShowTime string `json:"show_time" example:"2025-01-01T10:00:00Z"`
+}
+
+func (r ShowRoundRequest) ToDomain() *domain.ShowRounds {
+	return &domain.ShowRounds{
+		AnimalId: r.AnimalId,
+		StageId:  r.StageId,
+		ShowTime: r.ShowTime,
+	}
+}
+
+type ShowRoundResponse struct {
+	Id       string            `json:"round_id"`
+	AnimalId string            `json:"animal_id"`
+	StageId  string            `json:"stage_id"`
+	ShowTime string            `json:"show_time" example:"2025-01-01T10:00:00Z"`
+	Bookings []BookingResponse `json:"bookings,omitempty"`
+	Metadata
+}
+
+func NewShowRoundResponse(showRound domain.ShowRounds) ShowRoundResponse {
+	return ShowRoundResponse{
+		Id:       showRound.Id,
+		AnimalId: showRound.AnimalId,
+		StageId:  showRound.StageId,
+		ShowTime: showRound.ShowTime,
+		Bookings: mapSlice(showRound.Bookings, NewBookingResponse),
+		Metadata: newMetadata(showRound.Version, showRound.Audit),
+	}
+}
+
+func NewShowRoundPage(page *port.Page[*domain.ShowRounds]) *port.Page[ShowRoundResponse] {
+	return mapPage(page, func(showRound *domain.ShowRounds) ShowRoundResponse {
+		return NewShowRoundResponse(*showRound)
+	})
+}
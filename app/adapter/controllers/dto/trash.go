@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// TrashItemResponse describes a soft-deleted entity. Entity holds the response of its kind, such as a UserResponse.
+type TrashItemResponse struct {
+	Kind      port.TrashKind `json:"kind" example:"animals"`
+	Id        string         `json:"id"`
+	DeletedAt time.Time      `json:"deleted_at"`
+	Entity    any            `json:"entity"`
+}
+
+func NewTrashItemResponse(item port.TrashItem) TrashItemResponse {
+	return TrashItemResponse{
+		Kind:      item.Kind,
+		Id:        item.Id,
+		DeletedAt: item.DeletedAt,
+		Entity:    newEntityResponse(item.Entity),
+	}
+}
+
+func NewTrashPage(page *port.Page[port.TrashItem]) *port.Page[TrashItemResponse] {
+	return mapPage(page, NewTrashItemResponse)
+}
+
+// newEntityResponse maps a deleted entity to its response. Kinds without a response are left out rather than
+// exposed as they are stored.
+func newEntityResponse(entity any) any {
+	switch entity := entity.(type) {
+	case *domain.Users:
+		return NewUserResponse(*entity)
+	case *domain.Animals:
+		return NewAnimalResponse(*entity)
+	case *domain.PerformanceStage:
+		return NewStageResponse(*entity)
+	case *domain.ShowRounds:
+		return NewShowRoundResponse(*entity)
+	case *domain.Bookings:
+		return NewBookingResponse(*entity)
+	default:
+		return nil
+	}
+}
+
+// PurgeResponse is the body of purging the expired entities of the trash
+type PurgeResponse struct {
+	Purged int `json:"purged" example:"3"`
+}
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// UpdateUserRequest is the body of updating a user; fields left empty keep their value. Only admins may change the role,
+// and users changing their own password must send the current one.
+type UpdateUserRequest struct {
+	Username        string `json:"username" example:"somchai"`
+	Password        string `json:"password" example:"s3cret-pass"`
+	CurrentPassword string `json:"current_password" example:"old-s3cret"`
+	Email           string `json:"email" example:"somchai@example.com"`
+	Role            string `json:"role" example:"user" enums:"admin,user"`
+}
+
+func (r UpdateUserRequest) ToDomain() *domain.Users {
+	return &domain.Users{
+		Username: r.Username,
+		Password: r.Password,
+		Email:    r.Email,
+		Role:     r.Role,
+	}
+}
+
+// UserResponse describes a user without their password hash
+type UserResponse struct {
+	Id          string            `json:"user_id"`
+	Username    string            `json:"username" example:"somchai"`
+	DisplayName string            `json:"display_name,omitempty" example:"Somchai J."`
+	Role        string            `json:"role" example:"user"`
+	Bookings    []BookingResponse `json:"bookings,omitempty"`
+	Metadata
+}
+
+func NewUserResponse(user domain.Users) UserResponse {
+	return UserResponse{
+		Id:          user.Id,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Role:        user.Role,
+		Bookings:    mapSlice(user.Bookings, NewBookingResponse),
+		Metadata:    newMetadata(user.Version, user.Audit),
+	}
+}
+
+func NewUserResponses(users []domain.Users) []UserResponse {
+	return mapSlice(users, NewUserResponse)
+}
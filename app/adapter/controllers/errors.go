@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// problemContentType is the media type of RFC 7807 error responses
+const problemContentType = "application/problem+json"
+
+// problemTypePrefix turns an error code into the problem type URI
+const problemTypePrefix = "urn:liongate:problem:"
+
+// errIfMatchRequired is recorded by conditional requests without an If-Match header. It is a rule of the HTTP API
+// rather than of the domain, so errorStatus maps it on its own.
+var errIfMatchRequired = domain.NewError(domain.KindValidation, "if_match_required", "If-Match header with the ETag of the entity is required")
+
+// errorStatus maps domain errors to a status by their kind: validation errors to 400, failed authentication to 401,
+// denied access to 403, unknown entities to 404, conflicts to 409 and throttled requests to 429. Dangling references
+// are answered with 422, stale versions with 412 and a missing If-Match with 428. Every other error is an internal one
+// and maps to 500.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, errIfMatchRequired):
+		return http.StatusPreconditionRequired
+	case errors.Is(err, domain.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, domain.ErrInvalidReference):
+		return http.StatusUnprocessableEntity
+	}
+
+	switch domain.KindOf(err) {
+	case domain.KindValidation:
+		return http.StatusBadRequest
+	case domain.KindUnauthorized:
+		return http.StatusUnauthorized
+	case domain.KindForbidden:
+		return http.StatusForbidden
+	case domain.KindNotFound:
+		return http.StatusNotFound
+	case domain.KindConflict:
+		return http.StatusConflict
+	case domain.KindTooManyRequests:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// problemDetails describes err as an RFC 7807 problem. The code and title come from the domain error err wraps
+// and the detail from err itself. The messages of field errors are in the language the client accepts. Internal
+// errors get a generic title and no detail, so that database and driver messages do not reach clients.
+func problemDetails(c *gin.Context, err error) domain.ProblemDetails {
+	problem := domain.ProblemDetails{
+		Status:    errorStatus(err),
+		Instance:  c.Request.URL.Path,
+		RequestId: port.RequestMetaFromContext(c.Request.Context()).RequestId,
+		Code:      "internal",
+		Title:     "internal server error",
+	}
+
+	if domainErr := domain.AsError(err); domainErr != nil && problem.Status != http.StatusInternalServerError {
+		problem.Code = domainErr.Code
+		problem.Title = domainErr.Message
+		if detail := err.Error(); detail != domainErr.Message {
+			problem.Detail = detail
+		}
+	}
+
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		language := requestLanguage(c)
+		problem.Errors = make([]domain.FieldError, len(validationErr.Fields))
+		for i, field := range validationErr.Fields {
+			problem.Errors[i] = field.Localize(language)
+		}
+		c.Header("Content-Language", string(language))
+	}
+
+	problem.Type = problemTypePrefix + problem.Code
+	return problem
+}
+
+// ErrorHandler answers every request whose handlers recorded an error with c.Error and wrote no response
+// with an application/problem+json body. Internal errors are logged with the id of the request.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+		problem := problemDetails(c, err)
+		if problem.Status == http.StatusInternalServerError {
+			log.Printf("%s %s (request %s): %v", c.Request.Method, c.Request.URL.Path, problem.RequestId, err)
+		}
+
+		var throttled *domain.LoginThrottledError
+		if errors.As(err, &throttled) {
+			c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(throttled.RetryAt)))
+		}
+
+		c.Header("Content-Type", problemContentType)
+		c.JSON(problem.Status, problem)
+	}
+}
+
+// retryAfterSeconds is the Retry-After value for a retry at the given time, rounded up to whole seconds
+func retryAfterSeconds(at time.Time) int {
+	return max(1, int(math.Ceil(time.Until(at).Seconds())))
+}
+
+// abortWithError stops the handler chain of a middleware and leaves err for ErrorHandler to answer
+func abortWithError(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
+
+// deleteOptions reads the required If-Match header and the optional ?cascade=true query parameter of delete endpoints.
+// Like ifMatchVersion it has already responded when ok is false.
+func deleteOptions(c *gin.Context) (opts port.DeleteOptions, ok bool) {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return opts, false
+	}
+	cascade, _ := strconv.ParseBool(c.Query("cascade"))
+	return port.DeleteOptions{Cascade: cascade, Version: version}, true
+}
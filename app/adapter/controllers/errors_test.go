@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failWith serves a request whose handler records err, with the headers given as name, value pairs
+func failWith(t *testing.T, err error, headers ...string) (*httptest.ResponseRecorder, domain.ProblemDetails) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestMeta(), ErrorHandler())
+	router.GET("/api/v1/things/42", func(c *gin.Context) {
+		c.Error(err)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/things/42", nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var problem domain.ProblemDetails
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem), w.Body.String())
+	return w, problem
+}
+
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"validation", fmt.Errorf("%w: name is required", domain.ErrValidation), http.StatusBadRequest, "validation_failed"},
+		{"invalid query", domain.ErrInvalidQuery, http.StatusBadRequest, "invalid_query"},
+		{"unauthorized", domain.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+		{"forbidden", domain.ErrForbidden, http.StatusForbidden, "forbidden"},
+		{"not found", fmt.Errorf("%w: animal %q", domain.ErrNotFound, "42"), http.StatusNotFound, "not_found"},
+		{"already exists", domain.ErrAlreadyExists, http.StatusConflict, "already_exists"},
+		{"reference in use", domain.ErrReferenceInUse, http.StatusConflict, "reference_in_use"},
+		{"invalid reference", domain.ErrInvalidReference, http.StatusUnprocessableEntity, "invalid_reference"},
+		{"version conflict", domain.ErrVersionConflict, http.StatusPreconditionFailed, "version_conflict"},
+		{"if-match required", errIfMatchRequired, http.StatusPreconditionRequired, "if_match_required"},
+		{"login throttled", &domain.LoginThrottledError{RetryAt: time.Now().Add(time.Minute)}, http.StatusTooManyRequests, "login_throttled"},
+		{"internal", errors.New("pq: connection refused"), http.StatusInternalServerError, "internal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, problem := failWith(t, tt.err, requestIdHeader, "req-1")
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, tt.code, problem.Code)
+			assert.Equal(t, problemTypePrefix+tt.code, problem.Type)
+			assert.Equal(t, "/api/v1/things/42", problem.Instance)
+			assert.Equal(t, "req-1", problem.RequestId)
+			assert.NotEmpty(t, problem.Title)
+		})
+	}
+
+	t.Run("the detail names the entity", func(t *testing.T) {
+		_, problem := failWith(t, fmt.Errorf("%w: animal %q", domain.ErrNotFound, "42"))
+
+		assert.Equal(t, "entity not found", problem.Title)
+		assert.Equal(t, `entity not found: animal "42"`, problem.Detail)
+	})
+
+	t.Run("a bare domain error has no detail", func(t *testing.T) {
+		_, problem := failWith(t, domain.ErrForbidden)
+
+		assert.Empty(t, problem.Detail)
+	})
+
+	t.Run("internal errors are not disclosed", func(t *testing.T) {
+		w, problem := failWith(t, fmt.Errorf("failed to save: %w", errors.New("pq: password authentication failed for user \"app\"")))
+
+		assert.Equal(t, "internal server error", problem.Title)
+		assert.Empty(t, problem.Detail)
+		assert.NotContains(t, w.Body.String(), "pq:")
+	})
+
+	t.Run("domain errors of an unknown kind are internal", func(t *testing.T) {
+		w, problem := failWith(t, domain.NewError(domain.KindInternal, "signing_failed", "token could not be signed"))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "internal", problem.Code)
+		assert.NotContains(t, w.Body.String(), "signed")
+	})
+
+	t.Run("throttled logins carry Retry-After", func(t *testing.T) {
+		w, _ := failWith(t, &domain.LoginThrottledError{RetryAt: time.Now().Add(90 * time.Second)})
+
+		retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+		require.NoError(t, err)
+		assert.InDelta(t, 90, retryAfter, 1)
+	})
+
+	t.Run("field errors are localized", func(t *testing.T) {
+		var v domain.Validation
+		v.Required("name", "")
+		v.Min("seat_number", 0, 1)
+		invalid := v.Err()
+
+		w, problem := failWith(t, invalid)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "validation_failed", problem.Code)
+		assert.Equal(t, "en", w.Header().Get("Content-Language"))
+		require.Len(t, problem.Errors, 2)
+		assert.Equal(t, domain.FieldError{Field: "name", Code: domain.RuleRequired, Message: "name is required"}, problem.Errors[0])
+		assert.Equal(t, "seat_number", problem.Errors[1].Field)
+		assert.Equal(t, "seat_number must be at least 1", problem.Errors[1].Message)
+
+		w, problem = failWith(t, invalid, "Accept-Language", "fr;q=1, th-TH;q=0.8, en;q=0.5")
+		assert.Equal(t, "th", w.Header().Get("Content-Language"))
+		require.Len(t, problem.Errors, 2)
+		assert.Equal(t, "กรุณาระบุ name", problem.Errors[0].Message)
+		assert.Equal(t, domain.RuleRequired, problem.Errors[0].Code, "codes do not depend on the language")
+	})
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// etag formats an entity version as a strong entity tag
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// setETag advertises the version of the returned entity so that clients can send it back in If-Match
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", etag(version))
+}
+
+// ifMatchVersion reads the version a PUT or DELETE is conditional on from the If-Match header; "*" matches any version and yields 0.
+// A missing header is recorded as a 428 error and a tag that cannot be one of ours as a version conflict; the handler must stop when ok is false.
+func ifMatchVersion(c *gin.Context) (version int64, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		c.Error(errIfMatchRequired)
+		return 0, false
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	// If-Match uses the strong comparison, so weak W/ tags never match
+	tag, quoted := strings.CutPrefix(header, `"`)
+	tag, closed := strings.CutSuffix(tag, `"`)
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if !quoted || !closed || err != nil || version < 1 {
+		c.Error(fmt.Errorf("%w: If-Match does not match the current version of the entity", domain.ErrVersionConflict))
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAnimalsService holds a single animal at version 3 and rejects writes conditional on any other version, like the
+// repositories do
+type stubAnimalsService struct {
+	port.AnimalsService
+}
+
+const stubAnimalVersion = 3
+
+func (s stubAnimalsService) UpdateAnimal(ctx context.Context, id string, animal *domain.Animals) (*domain.Animals, error) {
+	if animal.Version != 0 && animal.Version != stubAnimalVersion {
+		return nil, fmt.Errorf("%w: animal %s is at version %d", domain.ErrVersionConflict, id, stubAnimalVersion)
+	}
+	updated := *animal
+	updated.Id = id
+	updated.Version = stubAnimalVersion + 1
+	return &updated, nil
+}
+
+func (s stubAnimalsService) DeleteAnimal(ctx context.Context, id string, opts port.DeleteOptions) error {
+	if opts.Version != 0 && opts.Version != stubAnimalVersion {
+		return fmt.Errorf("%w: animal %s is at version %d", domain.ErrVersionConflict, id, stubAnimalVersion)
+	}
+	return nil
+}
+
+func TestIfMatch(t *testing.T) {
+	auth := newTestAuth()
+	router := newTestRouter(auth)
+	NewAnimalsController(stubAnimalsService{}, nil, auth).RegisterRoutes(router)
+
+	send := func(method string, ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/animals/a1", strings.NewReader(`{"name":"Leo","species":"lion","type":"mammal","show_duration":30}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer admin-token")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		status  int
+		code    string
+	}{
+		{"missing", "", http.StatusPreconditionRequired, "if_match_required"},
+		{"blank", "   ", http.StatusPreconditionRequired, "if_match_required"},
+		{"stale", `"2"`, http.StatusPreconditionFailed, "version_conflict"},
+		{"unquoted", "3", http.StatusPreconditionFailed, "version_conflict"},
+		{"weak", `W/"3"`, http.StatusPreconditionFailed, "version_conflict"},
+		{"not a number", `"abc"`, http.StatusPreconditionFailed, "version_conflict"},
+		{"unclosed", `"3`, http.StatusPreconditionFailed, "version_conflict"},
+		{"zero", `"0"`, http.StatusPreconditionFailed, "version_conflict"},
+		{"negative", `"-1"`, http.StatusPreconditionFailed, "version_conflict"},
+	}
+	for _, method := range []string{http.MethodPut, http.MethodDelete} {
+		for _, tt := range tests {
+			t.Run(method+" "+tt.name, func(t *testing.T) {
+				w := send(method, tt.ifMatch)
+
+				require.Equal(t, tt.status, w.Code, w.Body.String())
+				assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+				var problem domain.ProblemDetails
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+				assert.Equal(t, tt.code, problem.Code)
+			})
+		}
+
+		t.Run(method+" current", func(t *testing.T) {
+			w := send(method, `"3"`)
+
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		})
+
+		t.Run(method+" any version", func(t *testing.T) {
+			w := send(method, "*")
+
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		})
+	}
+
+	t.Run("an update returns the new version", func(t *testing.T) {
+		w := send(http.MethodPut, ` "3" `)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	})
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type InvitationsController struct {
+	svc  port.InvitationService
+	auth *AuthMiddleware
+}
+
+func NewInvitationsController(svc port.InvitationService, auth *AuthMiddleware) *InvitationsController {
+	return &InvitationsController{
+		svc:  svc,
+		auth: auth,
+	}
+}
+
+func (ic *InvitationsController) RegisterRoutes(router *gin.Engine) {
+	admin := router.Group("/api/v1/admin/invitations", ic.auth.RequireAuth(), RequireRole(domain.RoleAdmin))
+	admin.POST("", ic.CreateInvitation)
+
+	router.POST("/api/v1/auth/invitations/accept", ic.AcceptInvitation)
+}
+
+// CreateInvitation godoc
+// @Summary Invite a user
+// @Description Create a single-use invitation to sign up with the given role. The signed link expires after INVITATION_TTL and is only returned in this response.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param invitation body dto.InvitationRequest true "Role of the invited user"
+// @Success 201 {object} dto.InvitationResponse
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/invitations [post]
+func (ic *InvitationsController) CreateInvitation(c *gin.Context) {
+	var req dto.InvitationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	link, err := ic.svc.CreateInvitation(c.Request.Context(), req.ToDomain())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.NewInvitationResponse(*link))
+}
+
+// AcceptInvitation godoc
+// @Summary      Accept an invitation
+// @Description  Sign up with the token of an invitation link and log in. The user gets the role of the invitation, and the invitation cannot be used again.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.AcceptInvitationRequest true "Invitation token and the new user's credentials"
+// @Success      201 {object} dto.AuthResponse "Successful registration"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 403 {object} domain.ProblemDetails "Invitation is invalid, expired or already used"
+// @Failure 409 {object} domain.ProblemDetails "Username already taken"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router       /auth/invitations/accept [post]
+func (ic *InvitationsController) AcceptInvitation(c *gin.Context) {
+	var req dto.AcceptInvitationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	authResponse, err := ic.svc.AcceptInvitation(c.Request.Context(), req.ToDomain())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.NewAuthResponse(*authResponse))
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// jwksMaxAge is how long verifiers may cache the JWKS document. New keys are published well before they sign,
+// so it must stay below JWT_KEY_PUBLISH_AHEAD.
+const jwksMaxAge = 5 * time.Minute
+
+type JWKSController struct {
+	svc port.SigningKeyService
+}
+
+func NewJWKSController(svc port.SigningKeyService) *JWKSController {
+	return &JWKSController{svc: svc}
+}
+
+func (jc *JWKSController) RegisterRoutes(router *gin.Engine) {
+	router.GET("/.well-known/jwks.json", jc.GetJWKS)
+}
+
+// GetJWKS serves the public keys tokens are signed with as a JWKS document. A token names its key in the kid
+// header; keys appear before they start signing and stay until the tokens they signed have expired. The route
+// lives outside the API base path, where verifiers look for it, so it is left out of the swagger docs.
+func (jc *JWKSController) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(jwksMaxAge.Seconds())))
+	c.JSON(http.StatusOK, dto.NewJWKSResponse(jc.svc.PublicKeys()))
+}
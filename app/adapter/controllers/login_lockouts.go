@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type LoginLockoutsController struct {
+	svc  port.LoginLockoutService
+	auth *AuthMiddleware
+}
+
+func NewLoginLockoutsController(svc port.LoginLockoutService, auth *AuthMiddleware) *LoginLockoutsController {
+	return &LoginLockoutsController{
+		svc:  svc,
+		auth: auth,
+	}
+}
+
+func (lc *LoginLockoutsController) RegisterRoutes(router *gin.Engine) {
+	lockouts := router.Group("/api/v1/admin/login-lockouts", lc.auth.RequireAuth(), RequireRole(domain.RoleAdmin))
+	{
+		lockouts.GET("", lc.ListLockouts)
+		lockouts.DELETE("/:kind/:value", lc.Unlock)
+	}
+}
+
+// ListLockouts godoc
+// @Summary List login lockouts
+// @Description Get the usernames and client IPs that are locked out after too many failed logins
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.LoginLockoutResponse
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/login-lockouts [get]
+func (lc *LoginLockoutsController) ListLockouts(c *gin.Context) {
+	lockouts, err := lc.svc.ListLockouts(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewLoginLockoutResponses(lockouts))
+}
+
+// Unlock godoc
+// @Summary Unlock a username or client IP
+// @Description Clear the failed logins of a username or client IP, lifting its delay and lockout at once
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param kind path string true "What to unlock" Enums(username, ip)
+// @Param value path string true "The username or client IP"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} domain.ProblemDetails "Unknown kind"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 404 {object} domain.ProblemDetails "No failed logins are recorded for the username or IP"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/login-lockouts/{kind}/{value} [delete]
+func (lc *LoginLockoutsController) Unlock(c *gin.Context) {
+	if err := lc.svc.Unlock(c.Request.Context(), c.Param("kind"), c.Param("value")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Login unlocked successfully"})
+}
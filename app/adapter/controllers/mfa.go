@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type MfaController struct {
+	svc  port.MfaService
+	auth *AuthMiddleware
+}
+
+func NewMfaController(svc port.MfaService, auth *AuthMiddleware) *MfaController {
+	return &MfaController{
+		svc:  svc,
+		auth: auth,
+	}
+}
+
+func (mc *MfaController) RegisterRoutes(router *gin.Engine) {
+	mfa := router.Group("/api/v1/auth/mfa", mc.auth.RequireAuth())
+	{
+		mfa.POST("/enroll", mc.Enroll)
+		mfa.POST("/confirm", mc.Confirm)
+		mfa.POST("/recovery-codes", mc.RenewRecoveryCodes)
+		mfa.POST("/disable", mc.Disable)
+	}
+
+	admin := router.Group("/api/v1/admin", mc.auth.RequireAuth(), RequireRole(domain.RoleAdmin))
+	{
+		admin.GET("/mfa/requirements", mc.ListRequirements)
+		admin.PUT("/mfa/requirements/:role", mc.SetRequirement)
+		admin.DELETE("/users/:id/mfa", mc.Reset)
+	}
+}
+
+// Enroll godoc
+// @Summary Start enrolling a second factor
+// @Description Create a new TOTP secret for the caller. It takes effect once confirmed with a first code; enrolling again before that replaces it.
+// @Tags mfa
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.MfaSetupResponse
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 409 {object} domain.ProblemDetails "Two-factor authentication is already enabled"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /auth/mfa/enroll [post]
+func (mc *MfaController) Enroll(c *gin.Context) {
+	setup, err := mc.svc.Enroll(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewMfaSetupResponse(*setup))
+}
+
+// Confirm godoc
+// @Summary Confirm a second factor
+// @Description Enable two-factor authentication with the first code of the authenticator app and get the recovery codes, which are not shown again
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.MfaConfirmRequest true "Code from the authenticator app"
+// @Success 200 {object} dto.RecoveryCodesResponse
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token, or invalid code"
+// @Failure 409 {object} domain.ProblemDetails "Nothing to confirm, or already enabled"
+// @Failure 429 {object} domain.ProblemDetails "Too many failed attempts"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /auth/mfa/confirm [post]
+func (mc *MfaController) Confirm(c *gin.Context) {
+	var req dto.MfaConfirmRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	codes, err := mc.svc.Confirm(c.Request.Context(), req.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RenewRecoveryCodes godoc
+// @Summary Renew recovery codes
+// @Description Replace all recovery codes of the caller after checking a code or a recovery code
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.MfaCodeRequest true "Code or recovery code"
+// @Success 200 {object} dto.RecoveryCodesResponse
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token, or invalid code"
+// @Failure 409 {object} domain.ProblemDetails "Two-factor authentication is not enabled"
+// @Failure 429 {object} domain.ProblemDetails "Too many failed attempts"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /auth/mfa/recovery-codes [post]
+func (mc *MfaController) RenewRecoveryCodes(c *gin.Context) {
+	var req dto.MfaCodeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	codes, err := mc.svc.RenewRecoveryCodes(c.Request.Context(), req.ToDomain())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable godoc
+// @Summary Disable the second factor
+// @Description Turn off two-factor authentication for the caller after checking a code or a recovery code. Not allowed when the role of the caller requires it.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.MfaCodeRequest true "Code or recovery code"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token, or invalid code"
+// @Failure 403 {object} domain.ProblemDetails "The role of the caller requires two-factor authentication"
+// @Failure 409 {object} domain.ProblemDetails "Two-factor authentication is not enabled"
+// @Failure 429 {object} domain.ProblemDetails "Too many failed attempts"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /auth/mfa/disable [post]
+func (mc *MfaController) Disable(c *gin.Context) {
+	var req dto.MfaCodeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := mc.svc.Disable(c.Request.Context(), req.ToDomain()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Two-factor authentication disabled successfully"})
+}
+
+// ListRequirements godoc
+// @Summary List roles requiring MFA
+// @Description Get the roles whose users must log in with a second factor
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.MfaRequirementResponse
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/mfa/requirements [get]
+func (mc *MfaController) ListRequirements(c *gin.Context) {
+	requirements, err := mc.svc.ListRequirements(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewMfaRequirementResponses(requirements))
+}
+
+// SetRequirement godoc
+// @Summary Require MFA for a role
+// @Description Require a second factor from every user of a role, or stop requiring it. Users of the role without one must enroll at their next login.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param role path string true "Role" Enums(admin, user)
+// @Param request body dto.MfaRequirementRequest true "Whether the role requires MFA"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body or unknown role"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/mfa/requirements/{role} [put]
+func (mc *MfaController) SetRequirement(c *gin.Context) {
+	var req dto.MfaRequirementRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	requirement := &domain.MfaRequirementRequest{Role: c.Param("role"), Required: *req.Required}
+	if err := mc.svc.SetRequirement(c.Request.Context(), requirement); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "MFA requirement updated successfully"})
+}
+
+// Reset godoc
+// @Summary Reset the second factor of a user
+// @Description Remove the second factor of a user who lost their device and recovery codes; they enroll again afterwards
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 404 {object} domain.ProblemDetails "The user has no second factor"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/users/{id}/mfa [delete]
+func (mc *MfaController) Reset(c *gin.Context) {
+	if err := mc.svc.Reset(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Two-factor authentication reset successfully"})
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// requestIdHeader carries the id that ties a request to the audit log entries it caused
+const requestIdHeader = "X-Request-ID"
+
+// validRequestId accepts the request ids a client or proxy may pass on; anything else is replaced
+var validRequestId = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestMeta records the client IP and the id of the request in the request context, so that the audit log
+// can tell where a change came from. The X-Request-ID of the request is kept when it looks sane, otherwise a new
+// id is generated; either way it is sent back in the X-Request-ID response header.
+func RequestMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(requestIdHeader)
+		if !validRequestId.MatchString(requestId) {
+			requestId = uuid.New().String()
+		}
+		c.Header(requestIdHeader, requestId)
+
+		meta := port.RequestMeta{IP: c.ClientIP(), RequestId: requestId}
+		c.Request = c.Request.WithContext(port.WithRequestMeta(c.Request.Context(), meta))
+		c.Next()
+	}
+}
+
+// claimsKey is the gin context key under which RequireAuth stores the claims of the access token
+const claimsKey = "claims"
+
+// apiKeyHeader carries the API key of a machine client
+const apiKeyHeader = "X-API-Key"
+
+// AuthMiddleware guards routes with the access tokens issued by AuthController and the API keys of machine clients
+type AuthMiddleware struct {
+	svc     port.AuthService
+	apiKeys port.ApiKeyService
+}
+
+func NewAuthMiddleware(svc port.AuthService, apiKeys port.ApiKeyService) *AuthMiddleware {
+	return &AuthMiddleware{svc: svc, apiKeys: apiKeys}
+}
+
+// Identify attributes requests that carry an access token to its user, so that the repositories stamp their
+// changes with that user. Requests without an Authorization header go on as anonymous; an invalid token is
+// rejected with 401.
+func (m *AuthMiddleware) Identify() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" && !m.authenticate(c) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAuth rejects requests without a valid "Authorization: Bearer <access token>" header with 401
+// and makes the claims of the token available to the following handlers
+func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := currentClaims(c); !ok && !m.authenticate(c) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticate verifies the bearer token of the request, and that its session was not revoked, and records its claims and user as the actor
+// of the request context. It aborts the request with 401 and returns false when the token is missing or invalid.
+func (m *AuthMiddleware) authenticate(c *gin.Context) bool {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || strings.TrimSpace(token) == "" {
+		abortWithError(c, fmt.Errorf("%w: bearer access token is required", domain.ErrUnauthorized))
+		return false
+	}
+
+	claims, err := m.svc.Authenticate(c.Request.Context(), strings.TrimSpace(token))
+	if err != nil {
+		abortWithError(c, err)
+		return false
+	}
+
+	c.Set(claimsKey, claims)
+	c.Request = c.Request.WithContext(port.WithActor(c.Request.Context(), claims.UserID))
+	return true
+}
+
+// RequireScope lets a route be used with an access token, or by machine clients with an "X-API-Key" header whose
+// key carries scope; it records the client of the key as the actor and answers keys without the scope with 403.
+// Requests with neither are answered with 401. Routes without RequireScope ignore API keys, so a key only ever acts
+// on routes that name one of its scopes. Any signed in user passes, so the services decide what a user may touch.
+func (m *AuthMiddleware) RequireScope(scope string) gin.HandlerFunc {
+	return m.RequireRoleOrScope(scope)
+}
+
+// RequireRoleOrScope is RequireScope for routes that an access token only opens to users with one of roles,
+// answering the other users with 403. With no roles any user passes.
+func (m *AuthMiddleware) RequireRoleOrScope(scope string, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, ok := currentClaims(c); ok {
+			if len(roles) > 0 && !slices.Contains(roles, claims.Role) {
+				abortWithError(c, fmt.Errorf("%w: insufficient role", domain.ErrForbidden))
+				return
+			}
+			c.Next()
+			return
+		}
+		key := strings.TrimSpace(c.GetHeader(apiKeyHeader))
+		if key == "" {
+			abortWithError(c, fmt.Errorf("%w: bearer access token or API key is required", domain.ErrUnauthorized))
+			return
+		}
+
+		apiKey, err := m.apiKeys.Authenticate(c.Request.Context(), key)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if !apiKey.HasScope(scope) {
+			abortWithError(c, fmt.Errorf("%w: API key lacks the %s scope", domain.ErrForbidden, scope))
+			return
+		}
+
+		c.Request = c.Request.WithContext(port.WithActor(c.Request.Context(), domain.ApiClientActor(apiKey.ClientId)))
+		c.Next()
+	}
+}
+
+// RequireRole lets through only requests whose token carries one of roles and answers the others with 403.
+// It must run after RequireAuth.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := currentClaims(c)
+		if !ok {
+			abortWithError(c, fmt.Errorf("%w: bearer access token is required", domain.ErrUnauthorized))
+			return
+		}
+		if !slices.Contains(roles, claims.Role) {
+			abortWithError(c, fmt.Errorf("%w: insufficient role", domain.ErrForbidden))
+			return
+		}
+		c.Next()
+	}
+}
+
+// currentClaims returns the claims RequireAuth stored for the request
+func currentClaims(c *gin.Context) (*domain.JWTClaims, bool) {
+	value, ok := c.Get(claimsKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := value.(*domain.JWTClaims)
+	return claims, ok
+}
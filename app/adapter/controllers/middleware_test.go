@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAuthService accepts the access tokens listed in claims
+type stubAuthService struct {
+	port.AuthService
+	claims map[string]*domain.JWTClaims
+}
+
+func (s stubAuthService) Authenticate(ctx context.Context, accessToken string) (*domain.JWTClaims, error) {
+	if claims, ok := s.claims[accessToken]; ok {
+		return claims, nil
+	}
+	return nil, fmt.Errorf("%w: invalid access token", domain.ErrUnauthorized)
+}
+
+// stubApiKeyService accepts the API keys listed in keys
+type stubApiKeyService struct {
+	port.ApiKeyService
+	keys map[string]*domain.ApiKey
+}
+
+func (s stubApiKeyService) Authenticate(ctx context.Context, key string) (*domain.ApiKey, error) {
+	if apiKey, ok := s.keys[key]; ok {
+		return apiKey, nil
+	}
+	return nil, fmt.Errorf("%w: unknown API key", domain.ErrApiKeyInvalid)
+}
+
+// newTestAuth knows the tokens "user-token" and "admin-token", and the keys "rounds-read-key" and "rounds-write-key"
+func newTestAuth() *AuthMiddleware {
+	return NewAuthMiddleware(
+		stubAuthService{claims: map[string]*domain.JWTClaims{
+			"user-token":  {UserID: "alice", Role: domain.RoleUser, Type: "access"},
+			"admin-token": {UserID: "root", Role: domain.RoleAdmin, Type: "access"},
+		}},
+		stubApiKeyService{keys: map[string]*domain.ApiKey{
+			"rounds-read-key":  {Id: "k1", ClientId: "kiosk", Scopes: []string{domain.ScopeRoundsRead}},
+			"rounds-write-key": {Id: "k2", ClientId: "kiosk", Scopes: []string{domain.ScopeRoundsRead, domain.ScopeRoundsWrite}},
+		}},
+	)
+}
+
+// newTestRouter is set up like the router of the server, identifying the user of every request that has a token
+func newTestRouter(auth *AuthMiddleware) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestMeta(), ErrorHandler(), auth.Identify())
+	return router
+}
+
+// credentials are the Authorization and X-API-Key headers of a request; either may be empty
+type credentials struct {
+	token  string
+	apiKey string
+}
+
+func serve(router *gin.Engine, method string, path string, creds credentials) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if creds.token != "" {
+		req.Header.Set("Authorization", "Bearer "+creds.token)
+	}
+	if creds.apiKey != "" {
+		req.Header.Set(apiKeyHeader, creds.apiKey)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	auth := newTestAuth()
+	router := newTestRouter(auth)
+	actor := func(c *gin.Context) {
+		c.String(http.StatusOK, port.ActorFromContext(c.Request.Context()))
+	}
+	router.GET("/auth", auth.RequireAuth(), actor)
+	router.GET("/admin", auth.RequireAuth(), RequireRole(domain.RoleAdmin), actor)
+	router.GET("/admin-only", RequireRole(domain.RoleAdmin), actor)
+	router.GET("/scope", auth.RequireScope(domain.ScopeRoundsWrite), actor)
+	router.GET("/role-or-scope", auth.RequireRoleOrScope(domain.ScopeRoundsWrite, domain.RoleAdmin), actor)
+
+	tests := []struct {
+		name   string
+		path   string
+		creds  credentials
+		status int
+		actor  string
+	}{
+		{"auth without a token", "/auth", credentials{}, http.StatusUnauthorized, ""},
+		{"auth with an invalid token", "/auth", credentials{token: "forged"}, http.StatusUnauthorized, ""},
+		{"auth ignores API keys", "/auth", credentials{apiKey: "rounds-write-key"}, http.StatusUnauthorized, ""},
+		{"auth with a token", "/auth", credentials{token: "user-token"}, http.StatusOK, "alice"},
+		{"role of another user", "/admin", credentials{token: "user-token"}, http.StatusForbidden, ""},
+		{"role of an admin", "/admin", credentials{token: "admin-token"}, http.StatusOK, "root"},
+		{"role without a token", "/admin-only", credentials{}, http.StatusUnauthorized, ""},
+		{"role of a token identified by the router", "/admin-only", credentials{token: "admin-token"}, http.StatusOK, "root"},
+		{"scope without credentials", "/scope", credentials{}, http.StatusUnauthorized, ""},
+		{"scope with an unknown key", "/scope", credentials{apiKey: "forged"}, http.StatusUnauthorized, ""},
+		{"scope with a key lacking it", "/scope", credentials{apiKey: "rounds-read-key"}, http.StatusForbidden, ""},
+		{"scope with a key carrying it", "/scope", credentials{apiKey: "rounds-write-key"}, http.StatusOK, domain.ApiClientActor("kiosk")},
+		{"scope lets any user through", "/scope", credentials{token: "user-token"}, http.StatusOK, "alice"},
+		{"scope with an invalid token", "/scope", credentials{token: "forged", apiKey: "rounds-write-key"}, http.StatusUnauthorized, ""},
+		{"role or scope refuses other users", "/role-or-scope", credentials{token: "user-token"}, http.StatusForbidden, ""},
+		{"role or scope refuses users holding a key", "/role-or-scope", credentials{token: "user-token", apiKey: "rounds-write-key"}, http.StatusForbidden, ""},
+		{"role or scope lets admins through", "/role-or-scope", credentials{token: "admin-token"}, http.StatusOK, "root"},
+		{"role or scope with a key carrying it", "/role-or-scope", credentials{apiKey: "rounds-write-key"}, http.StatusOK, domain.ApiClientActor("kiosk")},
+		{"role or scope with a key lacking it", "/role-or-scope", credentials{apiKey: "rounds-read-key"}, http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, tt.path, tt.creds)
+
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			if tt.status == http.StatusOK {
+				assert.Equal(t, tt.actor, w.Body.String())
+			} else {
+				assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestAdminRouteGuards(t *testing.T) {
+	auth := newTestAuth()
+	router := newTestRouter(auth)
+	// The guards run before the handlers, which answer a DELETE without If-Match with 428 before calling their service
+	NewShowRoundsController(nil, auth).RegisterRoutes(router)
+	NewAnimalsController(nil, nil, auth).RegisterRoutes(router)
+	NewPerformanceStageController(nil, auth).RegisterRoutes(router)
+	NewApiKeysController(nil, auth).RegisterRoutes(router)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		creds  credentials
+		status int
+	}{
+		{"show round writes need credentials", http.MethodDelete, "/api/v1/show-rounds/r1", credentials{}, http.StatusUnauthorized},
+		{"show round writes are refused to users", http.MethodDelete, "/api/v1/show-rounds/r1", credentials{token: "user-token"}, http.StatusForbidden},
+		{"show round creation is refused to users", http.MethodPost, "/api/v1/show-rounds/", credentials{token: "user-token"}, http.StatusForbidden},
+		{"show round updates are refused to users", http.MethodPut, "/api/v1/show-rounds/r1", credentials{token: "user-token"}, http.StatusForbidden},
+		{"show round writes are open to admins", http.MethodDelete, "/api/v1/show-rounds/r1", credentials{token: "admin-token"}, http.StatusPreconditionRequired},
+		{"show round writes need the write scope", http.MethodDelete, "/api/v1/show-rounds/r1", credentials{apiKey: "rounds-read-key"}, http.StatusForbidden},
+		{"show round writes are open to keys with the write scope", http.MethodDelete, "/api/v1/show-rounds/r1", credentials{apiKey: "rounds-write-key"}, http.StatusPreconditionRequired},
+		{"animal writes are refused to users", http.MethodDelete, "/api/v1/animals/a1", credentials{token: "user-token"}, http.StatusForbidden},
+		{"animal writes ignore API keys", http.MethodDelete, "/api/v1/animals/a1", credentials{apiKey: "rounds-write-key"}, http.StatusUnauthorized},
+		{"animal writes are open to admins", http.MethodDelete, "/api/v1/animals/a1", credentials{token: "admin-token"}, http.StatusPreconditionRequired},
+		{"performing a show is refused to users", http.MethodPost, "/api/v1/animals/a1/perform-show/r1", credentials{token: "user-token"}, http.StatusForbidden},
+		{"stage writes are refused to users", http.MethodDelete, "/api/v1/stages/s1", credentials{token: "user-token"}, http.StatusForbidden},
+		{"stage creation is refused to users", http.MethodPost, "/api/v1/stages/", credentials{token: "user-token"}, http.StatusForbidden},
+		{"stage writes are open to admins", http.MethodDelete, "/api/v1/stages/s1", credentials{token: "admin-token"}, http.StatusPreconditionRequired},
+		{"admin routes are refused to users", http.MethodPost, "/api/v1/admin/api-clients", credentials{token: "user-token"}, http.StatusForbidden},
+		{"admin routes ignore API keys", http.MethodDelete, "/api/v1/admin/api-keys/k1", credentials{apiKey: "rounds-write-key"}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, tt.method, tt.path, tt.creds)
+
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type OidcController struct {
+	svc  port.OidcService
+	auth *AuthMiddleware
+}
+
+func NewOidcController(svc port.OidcService, auth *AuthMiddleware) *OidcController {
+	return &OidcController{svc: svc, auth: auth}
+}
+
+func (oc *OidcController) RegisterRoutes(router *gin.Engine) {
+	router.GET("/api/v1/auth/oidc/:provider/authorize", oc.Authorize)
+	router.POST("/api/v1/auth/oidc/callback", oc.Callback)
+	router.GET("/api/v1/me/identities", oc.auth.RequireAuth(), oc.ListIdentities)
+}
+
+// Authorize godoc
+// @Summary      Start a sign-in with an identity provider
+// @Description  Get the URL of the provider to send the browser to. The provider redirects back to the client with a state and a code, which the client posts to /auth/oidc/callback when the state is the one returned here.
+// @Description  With an access token, the identity is linked to the caller instead of signing in as it.
+// @Tags         Authentication
+// @Produce      json
+// @Security     BearerAuth
+// @Param        provider path string true "Name of the identity provider, such as google"
+// @Success      200 {object} dto.OidcAuthorizationResponse
+// @Failure 401 {object} domain.ProblemDetails "Invalid access token"
+// @Failure 404 {object} domain.ProblemDetails "Unknown identity provider"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router       /auth/oidc/{provider}/authorize [get]
+func (oc *OidcController) Authorize(c *gin.Context) {
+	authorization, err := oc.svc.Authorize(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewOidcAuthorizationResponse(*authorization))
+}
+
+// Callback godoc
+// @Summary      Complete a sign-in with an identity provider
+// @Description  Exchange the state and code the provider returned for tokens, like a password login. The first sign-in with an identity creates a user, unless the sign-in was started to link it.
+// @Description  When the user has two-factor authentication, or their role requires it, the response carries only an mfa challenge to complete at /auth/login/mfa.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.OidcCallbackRequest true "State and code from the provider"
+// @Success      200 {object} dto.AuthResponse "Successful login, or the mfa challenge of a login that needs a second factor"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 401 {object} domain.ProblemDetails "Unknown or expired state, or the provider rejected the code"
+// @Failure 409 {object} domain.ProblemDetails "Identity is linked to another user, or its email belongs to another user"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router       /auth/oidc/callback [post]
+func (oc *OidcController) Callback(c *gin.Context) {
+	var req dto.OidcCallbackRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	authResponse, err := oc.svc.Login(c.Request.Context(), req.ToDomain())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewAuthResponse(*authResponse))
+}
+
+// ListIdentities godoc
+// @Summary List linked identities
+// @Description Get the identities at external providers the caller can sign in with, oldest first
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.OidcIdentityResponse
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /me/identities [get]
+func (oc *OidcController) ListIdentities(c *gin.Context) {
+	identities, err := oc.svc.ListIdentities(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewOidcIdentityResponses(identities))
+}
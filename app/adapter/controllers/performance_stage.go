@@ -4,45 +4,90 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
 	"github.com/khunmostz/be-liongate-go/app/core/port"
 )
 
 type PerformanceStageController struct {
-	svc port.PerformanceStageService
+	svc  port.PerformanceStageService
+	auth *AuthMiddleware
 }
 
-func NewPerformanceStageController(svc port.PerformanceStageService) *PerformanceStageController {
+func NewPerformanceStageController(svc port.PerformanceStageService, auth *AuthMiddleware) *PerformanceStageController {
 	return &PerformanceStageController{
-		svc: svc,
+		svc:  svc,
+		auth: auth,
 	}
 }
 
 func (pc *PerformanceStageController) RegisterRoutes(router *gin.Engine) {
 	stages := router.Group("/api/v1/stages")
-	stages.GET("/", pc.GetStages)
-	stages.POST("/", pc.CreateStage)
-	stages.GET("/:id", pc.GetStageById)
-	stages.PUT("/:id", pc.UpdateStage)
-	stages.DELETE("/:id", pc.DeleteStage)
+	stages.GET("/", pc.auth.RequireScope(domain.ScopeStagesRead), pc.GetStages)
+	stages.GET("/:id", pc.auth.RequireScope(domain.ScopeStagesRead), pc.GetStageById)
+
+	admin := stages.Group("", pc.auth.RequireAuth(), RequireRole(domain.RoleAdmin))
+	admin.POST("/", pc.CreateStage)
+	admin.PUT("/:id", pc.UpdateStage)
+	admin.DELETE("/:id", pc.DeleteStage)
 }
 
 // GetStages godoc
 // @Summary Get all performance stages
-// @Description Get a list of all performance stages
+// @Description Get a page of performance stages, optionally filtered by room number and capacity
 // @Tags stages
 // @Accept json
 // @Produce json
-// @Success 200 {array} domain.PerformanceStage
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param room_number query string false "Filter by room number"
+// @Param min_seat_capacity query int false "Only stages with at least this many seats"
+// @Param created_from query string false "Only entities created at or after this RFC 3339 time"
+// @Param created_to query string false "Only entities created at or before this RFC 3339 time"
+// @Param updated_from query string false "Only entities last updated at or after this RFC 3339 time"
+// @Param updated_to query string false "Only entities last updated at or before this RFC 3339 time"
+// @Param created_by query string false "Only entities created by this user ID, or anonymous"
+// @Param updated_by query string false "Only entities last updated by this user ID, or anonymous"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (stage_id, room_number, seat_capacity, price_per_seat, created_at, updated_at)"
+// @Success 200 {object} port.Page[dto.StageResponse]
+// @Failure 400 {object} domain.ProblemDetails "Invalid query parameters"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /stages [get]
 func (pc *PerformanceStageController) GetStages(c *gin.Context) {
-	stages, err := pc.svc.GetStages(c)
+	query, err := listQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	minSeatCapacity, err := intQuery(c, "min_seat_capacity")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	audit, err := auditQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, stages)
+
+	filter := port.StageFilter{
+		RoomNumber:      c.Query("room_number"),
+		MinSeatCapacity: minSeatCapacity,
+		Audit:           audit,
+	}
+
+	stages, err := pc.svc.GetStages(c, filter, query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewStagePage(stages))
 }
 
 // CreateStage godoc
@@ -51,23 +96,27 @@ func (pc *PerformanceStageController) GetStages(c *gin.Context) {
 // @Tags stages
 // @Accept json
 // @Produce json
-// @Param stage body domain.PerformanceStage true "Performance Stage information"
-// @Success 201 {object} domain.PerformanceStage
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param stage body dto.StageRequest true "Performance Stage information"
+// @Success 201 {object} dto.StageResponse
+// @Header 201 {string} ETag "Version of the performance stage"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Security BearerAuth
 // @Router /stages [post]
 func (pc *PerformanceStageController) CreateStage(c *gin.Context) {
-	var stage domain.PerformanceStage
-	if err := c.ShouldBindJSON(&stage); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.StageRequest
+	if !bindJSON(c, &req) {
 		return
 	}
-	result, err := pc.svc.CreateStage(c, &stage)
+	result, err := pc.svc.CreateStage(c, req.ToDomain())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusCreated, result)
+	setETag(c, result.Version)
+	c.JSON(http.StatusCreated, dto.NewStageResponse(*result))
 }
 
 // GetStageById godoc
@@ -77,17 +126,23 @@ func (pc *PerformanceStageController) CreateStage(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Performance Stage ID"
-// @Success 200 {object} domain.PerformanceStage
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Success 200 {object} dto.StageResponse
+// @Header 200 {string} ETag "Version of the performance stage, send it back in If-Match to update or delete it"
+// @Failure 404 {object} domain.ProblemDetails "Performance stage not found"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /stages/{id} [get]
 func (pc *PerformanceStageController) GetStageById(c *gin.Context) {
 	id := c.Param("id")
 	stage, err := pc.svc.GetStageById(c, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, stage)
+	setETag(c, stage.Version)
+	c.JSON(http.StatusOK, dto.NewStageResponse(*stage))
 }
 
 // UpdateStage godoc
@@ -97,50 +152,71 @@ func (pc *PerformanceStageController) GetStageById(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Performance Stage ID"
-// @Param stage body domain.PerformanceStage true "Updated Performance Stage information"
-// @Success 200 {object} domain.PerformanceStage
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param If-Match header string true "ETag of the performance stage being updated, or * for any version"
+// @Param stage body dto.StageRequest true "Updated Performance Stage information"
+// @Success 200 {object} dto.StageResponse
+// @Header 200 {string} ETag "New version of the performance stage"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 404 {object} domain.ProblemDetails "Performance stage not found"
+// @Failure 412 {object} domain.ProblemDetails "Performance stage was modified since it was read"
+// @Failure 428 {object} domain.ProblemDetails "If-Match header is missing"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Security BearerAuth
 // @Router /stages/{id} [put]
 func (pc *PerformanceStageController) UpdateStage(c *gin.Context) {
 	id := c.Param("id")
-	// Check if stage exists
-	_, err := pc.svc.GetStageById(c, id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
-	var updatedStage domain.PerformanceStage
-	if err := c.ShouldBindJSON(&updatedStage); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.StageRequest
+	if !bindJSON(c, &req) {
 		return
 	}
+	updatedStage := req.ToDomain()
+	updatedStage.Version = version
 
-	result, err := pc.svc.UpdateStage(c, id, &updatedStage)
+	result, err := pc.svc.UpdateStage(c, id, updatedStage)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, result)
+	setETag(c, result.Version)
+	c.JSON(http.StatusOK, dto.NewStageResponse(*result))
 }
 
 // DeleteStage godoc
 // @Summary Delete a performance stage
-// @Description Delete a performance stage by its ID
+// @Description Move a performance stage to the trash by its ID; an admin can restore it until it is purged
 // @Tags stages
 // @Accept json
 // @Produce json
 // @Param id path string true "Performance Stage ID"
-// @Success 200 {object} map[string]interface{} "Success message"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param If-Match header string true "ETag of the performance stage being deleted, or * for any version"
+// @Param cascade query bool false "Also delete the stage's show rounds and their bookings"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 404 {object} domain.ProblemDetails "Performance stage not found"
+// @Failure 409 {object} domain.ProblemDetails "Stage still has show rounds"
+// @Failure 412 {object} domain.ProblemDetails "Performance stage was modified since it was read"
+// @Failure 428 {object} domain.ProblemDetails "If-Match header is missing"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Security BearerAuth
 // @Router /stages/{id} [delete]
 func (pc *PerformanceStageController) DeleteStage(c *gin.Context) {
 	id := c.Param("id")
-	err := pc.svc.DeleteStage(c, id)
+	opts, ok := deleteOptions(c)
+	if !ok {
+		return
+	}
+	err := pc.svc.DeleteStage(c, id, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Stage deleted successfully"})
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Stage deleted successfully"})
 }
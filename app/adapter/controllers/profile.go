@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type ProfileController struct {
+	svc  port.ProfileService
+	auth *AuthMiddleware
+}
+
+func NewProfileController(svc port.ProfileService, auth *AuthMiddleware) *ProfileController {
+	return &ProfileController{
+		svc:  svc,
+		auth: auth,
+	}
+}
+
+func (pc *ProfileController) RegisterRoutes(router *gin.Engine) {
+	me := router.Group("/api/v1/me", pc.auth.RequireAuth())
+	{
+		me.GET("", pc.GetProfile)
+		me.PUT("", pc.UpdateProfile)
+		me.POST("/verifications/:channel", pc.SendVerification)
+		me.POST("/verifications/:channel/confirm", pc.VerifyContact)
+	}
+}
+
+// GetProfile godoc
+// @Summary Get own profile
+// @Description Get the profile of the caller, with their contact details and whether they are verified
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ProfileResponse
+// @Header 200 {string} ETag "Version of the user"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /me [get]
+func (pc *ProfileController) GetProfile(c *gin.Context) {
+	user, err := pc.svc.GetProfile(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, dto.NewProfileResponse(*user))
+}
+
+// UpdateProfile godoc
+// @Summary Update own profile
+// @Description Change the display name, email, phone, preferred language or marketing consent of the caller. A new email or phone starts out unverified.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param If-Match header string true "ETag of the profile being updated, or * for any version"
+// @Param profile body dto.UpdateProfileRequest true "Fields to change"
+// @Success 200 {object} dto.ProfileResponse
+// @Header 200 {string} ETag "New version of the user"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 409 {object} domain.ProblemDetails "Email or phone already used by another user"
+// @Failure 412 {object} domain.ProblemDetails "Profile was modified since it was read"
+// @Failure 428 {object} domain.ProblemDetails "If-Match header is missing"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /me [put]
+func (pc *ProfileController) UpdateProfile(c *gin.Context) {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateProfileRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	update := req.ToDomain()
+	update.Version = version
+
+	user, err := pc.svc.UpdateProfile(c.Request.Context(), update)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, dto.NewProfileResponse(*user))
+}
+
+// SendVerification godoc
+// @Summary Send a verification code
+// @Description Send a code to the current email or phone of the caller, replacing the code sent before
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Param channel path string true "Address to verify" Enums(email, phone)
+// @Success 202 {object} dto.VerificationSentResponse
+// @Failure 400 {object} domain.ProblemDetails "Unknown channel, or no address to verify"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 409 {object} domain.ProblemDetails "Address is already verified"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /me/verifications/{channel} [post]
+func (pc *ProfileController) SendVerification(c *gin.Context) {
+	verification, err := pc.svc.SendVerification(c.Request.Context(), c.Param("channel"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.NewVerificationSentResponse(*verification))
+}
+
+// VerifyContact godoc
+// @Summary Verify an email or phone
+// @Description Confirm the current email or phone of the caller with the code sent to it
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param channel path string true "Address to verify" Enums(email, phone)
+// @Param request body dto.VerifyContactRequest true "Code that was sent"
+// @Success 200 {object} dto.ProfileResponse
+// @Header 200 {string} ETag "New version of the user"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Code is wrong, expired or used up, or the address changed since it was sent"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /me/verifications/{channel}/confirm [post]
+func (pc *ProfileController) VerifyContact(c *gin.Context) {
+	var req dto.VerifyContactRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := pc.svc.VerifyContact(c.Request.Context(), &domain.VerifyContactRequest{Channel: c.Param("channel"), Code: req.Code})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, dto.NewProfileResponse(*user))
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// listQuery reads the shared limit, offset, cursor and sort query parameters of list endpoints
+func listQuery(c *gin.Context) (port.ListQuery, error) {
+	limit, err := intQuery(c, "limit")
+	if err != nil {
+		return port.ListQuery{}, err
+	}
+	offset, err := intQuery(c, "offset")
+	if err != nil {
+		return port.ListQuery{}, err
+	}
+
+	return port.ListQuery{
+		Limit:  limit,
+		Offset: offset,
+		Cursor: c.Query("cursor"),
+		Sort:   port.ParseSort(c.Query("sort")),
+	}, nil
+}
+
+// intQuery reads an optional integer query parameter
+func intQuery(c *gin.Context, key string) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s must be an integer", domain.ErrInvalidQuery, key)
+	}
+	return value, nil
+}
+
+// timeQuery reads an optional RFC 3339 timestamp query parameter
+func timeQuery(c *gin.Context, key string) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be an RFC 3339 timestamp", domain.ErrInvalidQuery, key)
+	}
+	return &value, nil
+}
+
+// auditQuery reads the optional created_from, created_to, updated_from, updated_to, created_by and updated_by
+// query parameters that narrow list endpoints by the audit stamps of the entities
+func auditQuery(c *gin.Context) (port.AuditFilter, error) {
+	filter := port.AuditFilter{
+		CreatedBy: c.Query("created_by"),
+		UpdatedBy: c.Query("updated_by"),
+	}
+	for key, bound := range map[string]**time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+		"updated_from": &filter.UpdatedFrom,
+		"updated_to":   &filter.UpdatedTo,
+	} {
+		value, err := timeQuery(c, key)
+		if err != nil {
+			return port.AuditFilter{}, err
+		}
+		*bound = value
+	}
+	return filter, nil
+}
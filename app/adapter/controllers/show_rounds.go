@@ -4,48 +4,103 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
 	"github.com/khunmostz/be-liongate-go/app/core/port"
 )
 
 type ShowRoundsController struct {
-	svc port.ShowRoundsService
+	svc  port.ShowRoundsService
+	auth *AuthMiddleware
 }
 
-func NewShowRoundsController(svc port.ShowRoundsService) *ShowRoundsController {
+func NewShowRoundsController(svc port.ShowRoundsService, auth *AuthMiddleware) *ShowRoundsController {
 	return &ShowRoundsController{
-		svc: svc,
+		svc:  svc,
+		auth: auth,
 	}
 }
 
 func (src *ShowRoundsController) RegisterRoutes(router *gin.Engine) {
 	showRounds := router.Group("/api/v1/show-rounds")
 	{
-		showRounds.GET("/", src.GetAllShowRounds)
-		showRounds.POST("/", src.CreateShowRound)
-		showRounds.POST("/:id", src.GetShowRoundById)
-		showRounds.GET("/:id", src.GetShowRoundById)
-		showRounds.PUT("/:id", src.UpdateShowRound)
-		showRounds.DELETE("/:id", src.DeleteShowRound)
+		read, write := src.auth.RequireScope(domain.ScopeRoundsRead), src.auth.RequireRoleOrScope(domain.ScopeRoundsWrite, domain.RoleAdmin)
+		showRounds.GET("/", read, src.GetAllShowRounds)
+		showRounds.POST("/", write, src.CreateShowRound)
+		showRounds.POST("/:id", read, src.GetShowRoundById)
+		showRounds.GET("/:id", read, src.GetShowRoundById)
+		showRounds.PUT("/:id", write, src.UpdateShowRound)
+		showRounds.DELETE("/:id", write, src.DeleteShowRound)
 	}
 }
 
 // GetAllShowRounds godoc
 // @Summary Get all show rounds
-// @Description Get a list of all show rounds
+// @Description Get a page of show rounds, optionally filtered by date range, animal, species or stage
 // @Tags show-rounds
 // @Accept json
 // @Produce json
-// @Success 200 {array} domain.ShowRounds
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param from query string false "Only rounds starting at or after this RFC 3339 time"
+// @Param to query string false "Only rounds starting at or before this RFC 3339 time"
+// @Param animal_id query string false "Filter by animal"
+// @Param species query string false "Filter by the species of the round's animal"
+// @Param stage_id query string false "Filter by stage"
+// @Param created_from query string false "Only entities created at or after this RFC 3339 time"
+// @Param created_to query string false "Only entities created at or before this RFC 3339 time"
+// @Param updated_from query string false "Only entities last updated at or after this RFC 3339 time"
+// @Param updated_to query string false "Only entities last updated at or before this RFC 3339 time"
+// @Param created_by query string false "Only entities created by this user ID, or anonymous"
+// @Param updated_by query string false "Only entities last updated by this user ID, or anonymous"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (round_id, animal_id, stage_id, show_time, created_at, updated_at)"
+// @Success 200 {object} port.Page[dto.ShowRoundResponse]
+// @Failure 400 {object} domain.ProblemDetails "Invalid query parameters"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /show-rounds [get]
 func (src *ShowRoundsController) GetAllShowRounds(c *gin.Context) {
-	showRounds, err := src.svc.GetAllShowRounds(c)
+	query, err := listQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, showRounds)
+
+	from, err := timeQuery(c, "from")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	to, err := timeQuery(c, "to")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	audit, err := auditQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filter := port.ShowRoundFilter{
+		AnimalId: c.Query("animal_id"),
+		StageId:  c.Query("stage_id"),
+		Species:  c.Query("species"),
+		From:     from,
+		To:       to,
+		Audit:    audit,
+	}
+
+	showRounds, err := src.svc.GetAllShowRounds(c, filter, query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewShowRoundPage(showRounds))
 }
 
 // CreateShowRound godoc
@@ -54,24 +109,30 @@ func (src *ShowRoundsController) GetAllShowRounds(c *gin.Context) {
 // @Tags show-rounds
 // @Accept json
 // @Produce json
-// @Param showRound body domain.ShowRounds true "Show Round information"
-// @Success 201 {object} domain.ShowRounds
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param showRound body dto.ShowRoundRequest true "Show Round information"
+// @Success 201 {object} dto.ShowRoundResponse
+// @Header 201 {string} ETag "Version of the show round"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 422 {object} domain.ProblemDetails "Animal or stage does not exist"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Failure 403 {object} domain.ProblemDetails "Not an admin, or an API key without the rounds:write scope"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /show-rounds [post]
 func (src *ShowRoundsController) CreateShowRound(c *gin.Context) {
-	var showRound domain.ShowRounds
-	if err := c.ShouldBindJSON(&showRound); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.ShowRoundRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	result, err := src.svc.CreateShowRound(c, &showRound)
+	result, err := src.svc.CreateShowRound(c, req.ToDomain())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusCreated, result)
+	setETag(c, result.Version)
+	c.JSON(http.StatusCreated, dto.NewShowRoundResponse(*result))
 }
 
 // GetShowRoundById godoc
@@ -81,25 +142,25 @@ func (src *ShowRoundsController) CreateShowRound(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Show Round ID"
-// @Success 200 {object} domain.ShowRounds
-// @Failure 404 {object} map[string]interface{} "Show round not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Success 200 {object} dto.ShowRoundResponse
+// @Header 200 {string} ETag "Version of the show round, send it back in If-Match to update or delete it"
+// @Failure 404 {object} domain.ProblemDetails "Show round not found"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /show-rounds/{id} [get]
 // @Router /show-rounds/{id} [post]
 func (src *ShowRoundsController) GetShowRoundById(c *gin.Context) {
 	id := c.Param("id")
 	showRound, err := src.svc.GetShowRoundById(c, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	if showRound == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Show round not found"})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, showRound)
+	setETag(c, showRound.Version)
+	c.JSON(http.StatusOK, dto.NewShowRoundResponse(*showRound))
 }
 
 // UpdateShowRound godoc
@@ -109,56 +170,79 @@ func (src *ShowRoundsController) GetShowRoundById(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Show Round ID"
-// @Param showRound body domain.ShowRounds true "Updated Show Round information"
-// @Success 200 {object} domain.ShowRounds
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
-// @Failure 404 {object} map[string]interface{} "Show round not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param If-Match header string true "ETag of the show round being updated, or * for any version"
+// @Param showRound body dto.ShowRoundRequest true "Updated Show Round information"
+// @Success 200 {object} dto.ShowRoundResponse
+// @Header 200 {string} ETag "New version of the show round"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 404 {object} domain.ProblemDetails "Show round not found"
+// @Failure 412 {object} domain.ProblemDetails "Show round was modified since it was read"
+// @Failure 422 {object} domain.ProblemDetails "Animal or stage does not exist"
+// @Failure 428 {object} domain.ProblemDetails "If-Match header is missing"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Failure 403 {object} domain.ProblemDetails "Not an admin, or an API key without the rounds:write scope"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /show-rounds/{id} [put]
 func (src *ShowRoundsController) UpdateShowRound(c *gin.Context) {
 	id := c.Param("id")
 
-	// Check if show round exists
-	_, err := src.svc.GetShowRoundById(c, id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
-	var updatedShowRound domain.ShowRounds
-	if err := c.ShouldBindJSON(&updatedShowRound); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.ShowRoundRequest
+	if !bindJSON(c, &req) {
 		return
 	}
+	updatedShowRound := req.ToDomain()
+	updatedShowRound.Version = version
 
-	result, err := src.svc.UpdateShowRound(c, id, &updatedShowRound)
+	result, err := src.svc.UpdateShowRound(c, id, updatedShowRound)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	setETag(c, result.Version)
+	c.JSON(http.StatusOK, dto.NewShowRoundResponse(*result))
 }
 
 // DeleteShowRound godoc
 // @Summary Delete a show round
-// @Description Delete a show round by its ID
+// @Description Move a show round to the trash by its ID; an admin can restore it until it is purged
 // @Tags show-rounds
 // @Accept json
 // @Produce json
 // @Param id path string true "Show Round ID"
-// @Success 200 {object} map[string]interface{} "Success message"
-// @Failure 404 {object} map[string]interface{} "Show round not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param If-Match header string true "ETag of the show round being deleted, or * for any version"
+// @Param cascade query bool false "Also delete the show round's bookings"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 404 {object} domain.ProblemDetails "Show round not found"
+// @Failure 409 {object} domain.ProblemDetails "Show round still has bookings"
+// @Failure 412 {object} domain.ProblemDetails "Show round was modified since it was read"
+// @Failure 428 {object} domain.ProblemDetails "If-Match header is missing"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token or API key"
+// @Failure 403 {object} domain.ProblemDetails "Not an admin, or an API key without the rounds:write scope"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /show-rounds/{id} [delete]
 func (src *ShowRoundsController) DeleteShowRound(c *gin.Context) {
 	id := c.Param("id")
 
-	err := src.svc.DeleteShowRound(c, id)
+	opts, ok := deleteOptions(c)
+	if !ok {
+		return
+	}
+
+	err := src.svc.DeleteShowRound(c, id, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Show round deleted successfully"})
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Show round deleted successfully"})
 }
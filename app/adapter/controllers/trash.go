@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type TrashController struct {
+	svc  port.TrashService
+	auth *AuthMiddleware
+}
+
+func NewTrashController(svc port.TrashService, auth *AuthMiddleware) *TrashController {
+	return &TrashController{
+		svc:  svc,
+		auth: auth,
+	}
+}
+
+func (tc *TrashController) RegisterRoutes(router *gin.Engine) {
+	trash := router.Group("/api/v1/admin/trash", tc.auth.RequireAuth(), RequireRole(domain.RoleAdmin))
+	trash.POST("/purge", tc.PurgeExpired)
+	trash.GET("/:kind", tc.ListTrash)
+	trash.POST("/:kind/:id/restore", tc.Restore)
+	trash.DELETE("/:kind/:id", tc.Purge)
+}
+
+// ListTrash godoc
+// @Summary List deleted entities
+// @Description Get a page of the soft-deleted entities of one kind, most recently deleted first
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param kind path string true "Entity kind" Enums(users, animals, performance_stages, show_rounds, bookings)
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (id, deleted_at)"
+// @Success 200 {object} port.Page[dto.TrashItemResponse]
+// @Failure 400 {object} domain.ProblemDetails "Unknown kind or invalid query parameters"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/trash/{kind} [get]
+func (tc *TrashController) ListTrash(c *gin.Context) {
+	kind, err := port.ParseTrashKind(c.Param("kind"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	query, err := listQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	items, err := tc.svc.ListTrash(c, kind, query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTrashPage(items))
+}
+
+// Restore godoc
+// @Summary Restore a deleted entity
+// @Description Bring a soft-deleted entity back. The entities it references must be live, so restore a show round's animal and stage, or a booking's show round and user, first.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param kind path string true "Entity kind" Enums(users, animals, performance_stages, show_rounds, bookings)
+// @Param id path string true "Entity ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} domain.ProblemDetails "Unknown kind"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 404 {object} domain.ProblemDetails "Entity is not in the trash"
+// @Failure 409 {object} domain.ProblemDetails "The seat of the booking has been booked again"
+// @Failure 422 {object} domain.ProblemDetails "A referenced entity is deleted"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/trash/{kind}/{id}/restore [post]
+func (tc *TrashController) Restore(c *gin.Context) {
+	kind, err := port.ParseTrashKind(c.Param("kind"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := tc.svc.Restore(c, kind, c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Entity restored successfully"})
+}
+
+// Purge godoc
+// @Summary Permanently delete an entity
+// @Description Permanently remove a soft-deleted entity together with the deleted entities that reference it
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param kind path string true "Entity kind" Enums(users, animals, performance_stages, show_rounds, bookings)
+// @Param id path string true "Entity ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} domain.ProblemDetails "Unknown kind"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 404 {object} domain.ProblemDetails "Entity is not in the trash"
+// @Failure 409 {object} domain.ProblemDetails "Live entities still reference it"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/trash/{kind}/{id} [delete]
+func (tc *TrashController) Purge(c *gin.Context) {
+	kind, err := port.ParseTrashKind(c.Param("kind"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := tc.svc.Purge(c, kind, c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Entity purged successfully"})
+}
+
+// PurgeExpired godoc
+// @Summary Purge expired entities
+// @Description Permanently remove every entity deleted longer ago than the retention period (TRASH_RETENTION)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.PurgeResponse "Number of purged entities"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
+// @Router /admin/trash/purge [post]
+func (tc *TrashController) PurgeExpired(c *gin.Context) {
+	purged, err := tc.svc.PurgeExpired(c)
+	if err != nil {
+		c.Error(fmt.Errorf("purged %d entities before failing: %w", purged, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PurgeResponse{Purged: purged})
+}
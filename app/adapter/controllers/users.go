@@ -4,24 +4,27 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers/dto"
 	"github.com/khunmostz/be-liongate-go/app/core/port"
 )
 
 type UsersController struct {
-	svc port.UsersService
+	svc  port.UsersService
+	auth *AuthMiddleware
 }
 
-func NewUsersController(svc port.UsersService) *UsersController {
+func NewUsersController(svc port.UsersService, auth *AuthMiddleware) *UsersController {
 	return &UsersController{
-		svc: svc,
+		svc:  svc,
+		auth: auth,
 	}
 }
 
 func (uc *UsersController) RegisterRoutes(router *gin.Engine) {
-	users := router.Group("/api/v1/users")
+	router.POST("/api/v1/users/register", uc.Register)
+
+	users := router.Group("/api/v1/users", uc.auth.RequireAuth())
 	{
-		users.POST("/register", uc.Register)
 		users.GET("/:id", uc.GetUserById)
 		users.GET("/role/:role", uc.GetUsersByRole)
 		users.PUT("/:id", uc.UpdateUser)
@@ -31,136 +34,162 @@ func (uc *UsersController) RegisterRoutes(router *gin.Engine) {
 
 // Register godoc
 // @Summary Register a new user
-// @Description Register a new user with the provided information
+// @Description Register a new user with the provided information; the user always gets the user role
 // @Tags users
 // @Accept json
 // @Produce json
-// @Param user body domain.Users true "User information"
-// @Success 201 {object} domain.Users
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param user body dto.RegisterRequest true "User information"
+// @Success 201 {object} dto.UserResponse
+// @Header 201 {string} ETag "Version of the user"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body"
+// @Failure 409 {object} domain.ProblemDetails "Username already taken"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
 // @Router /users/register [post]
 func (uc *UsersController) Register(c *gin.Context) {
-	var user domain.Users
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.RegisterRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	result, err := uc.svc.Register(c.Request.Context(), &user)
+	result, err := uc.svc.Register(c.Request.Context(), req.ToUser())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, result)
+	setETag(c, result.Version)
+	c.JSON(http.StatusCreated, dto.NewUserResponse(*result))
 }
 
 // GetUserById godoc
 // @Summary Get a user by ID
-// @Description Get a user's information by their ID
+// @Description Get a user's information by their ID; users may only get themselves, admins anyone
 // @Tags users
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "User ID"
-// @Success 200 {object} domain.Users
-// @Failure 404 {object} map[string]interface{} "User not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Success 200 {object} dto.UserResponse
+// @Header 200 {string} ETag "Version of the user, send it back in If-Match to update or delete them"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Another user, and the caller is not an admin"
+// @Failure 404 {object} domain.ProblemDetails "User not found"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
 // @Router /users/{id} [get]
 func (uc *UsersController) GetUserById(c *gin.Context) {
 	id := c.Param("id")
 	user, err := uc.svc.GetUserById(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	if user == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, dto.NewUserResponse(*user))
 }
 
 // GetUsersByRole godoc
 // @Summary Get users by role
-// @Description Get all users with a specific role
+// @Description Get all users with a specific role; admin only
 // @Tags users
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param role path string true "User role"
-// @Success 200 {array} domain.Users
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Success 200 {array} dto.UserResponse
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Caller is not an admin"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
 // @Router /users/role/{role} [get]
 func (uc *UsersController) GetUsersByRole(c *gin.Context) {
 	role := c.Param("role")
 	users, err := uc.svc.GetUsersByRole(c.Request.Context(), role)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, users)
+	c.JSON(http.StatusOK, dto.NewUserResponses(users))
 }
 
 // UpdateUser godoc
 // @Summary Update a user
-// @Description Update a user's information
+// @Description Update a user's information; users may only update themselves, admins anyone. Users changing their own password must send current_password, and a new password revokes every session of the user.
 // @Tags users
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "User ID"
-// @Param user body domain.Users true "Updated User information"
-// @Success 200 {object} domain.Users
-// @Failure 400 {object} map[string]interface{} "Invalid request body"
-// @Failure 404 {object} map[string]interface{} "User not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param If-Match header string true "ETag of the user being updated, or * for any version"
+// @Param user body dto.UpdateUserRequest true "Updated User information"
+// @Success 200 {object} dto.UserResponse
+// @Header 200 {string} ETag "New version of the user"
+// @Failure 400 {object} domain.ProblemDetails "Invalid request body, or a missing or wrong current password"
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Another user or a role changed, and the caller is not an admin"
+// @Failure 404 {object} domain.ProblemDetails "User not found"
+// @Failure 409 {object} domain.ProblemDetails "Username already taken"
+// @Failure 412 {object} domain.ProblemDetails "User was modified since it was read"
+// @Failure 428 {object} domain.ProblemDetails "If-Match header is missing"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
 // @Router /users/{id} [put]
 func (uc *UsersController) UpdateUser(c *gin.Context) {
 	id := c.Param("id")
 
-	// Check if user exists
-	_, err := uc.svc.GetUserById(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
-	var updatedUser domain.Users
-	if err := c.ShouldBindJSON(&updatedUser); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.UpdateUserRequest
+	if !bindJSON(c, &req) {
 		return
 	}
+	updatedUser := req.ToDomain()
+	updatedUser.Version = version
 
-	result, err := uc.svc.UpdateUser(c.Request.Context(), id, &updatedUser)
+	result, err := uc.svc.UpdateUser(c.Request.Context(), id, updatedUser, req.CurrentPassword)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	setETag(c, result.Version)
+	c.JSON(http.StatusOK, dto.NewUserResponse(*result))
 }
 
 // DeleteUser godoc
 // @Summary Delete a user
-// @Description Delete a user by their ID
+// @Description Move a user to the trash by their ID; the username stays taken until an admin purges the user. Users may only delete themselves, admins anyone.
 // @Tags users
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "User ID"
-// @Success 200 {object} map[string]interface{} "Success message"
-// @Failure 404 {object} map[string]interface{} "User not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Param If-Match header string true "ETag of the user being deleted, or * for any version"
+// @Param cascade query bool false "Also delete the user's bookings"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 401 {object} domain.ProblemDetails "Missing or invalid access token"
+// @Failure 403 {object} domain.ProblemDetails "Another user, and the caller is not an admin"
+// @Failure 404 {object} domain.ProblemDetails "User not found"
+// @Failure 409 {object} domain.ProblemDetails "User still has bookings"
+// @Failure 412 {object} domain.ProblemDetails "User was modified since it was read"
+// @Failure 428 {object} domain.ProblemDetails "If-Match header is missing"
+// @Failure 500 {object} domain.ProblemDetails "Internal server error"
 // @Router /users/{id} [delete]
 func (uc *UsersController) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
 
-	err := uc.svc.DeleteUser(c.Request.Context(), id)
+	opts, ok := deleteOptions(c)
+	if !ok {
+		return
+	}
+
+	err := uc.svc.DeleteUser(c.Request.Context(), id, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "User deleted successfully"})
 }
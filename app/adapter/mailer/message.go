@@ -0,0 +1,71 @@
+// Package mailer holds the adapters of port.Mailer: SMTPMailer delivers mail through an SMTP server and
+// FileMailer writes it to an outbox directory for development and tests.
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// ErrInvalidHeader is returned for mail whose sender, recipient or subject could inject headers
+var ErrInvalidHeader = errors.New("mail header contains a line break")
+
+// envelope checks the sender and recipient of msg and returns their bare addresses
+func envelope(from string, msg domain.Mail) (string, string, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid sender %q: %w", from, err)
+	}
+	recipient, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+	return sender.Address, recipient.Address, nil
+}
+
+// render formats msg from from as an RFC 5322 message with a quoted-printable UTF-8 body
+func render(from string, msg domain.Mail, date time.Time) ([]byte, error) {
+	for _, header := range []string{from, msg.To, msg.Subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, ErrInvalidHeader
+		}
+	}
+	sender, _, err := envelope(from, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", uuid.New().String(), domainOf(sender))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	if _, err := body.Write([]byte(strings.ReplaceAll(msg.Body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// domainOf returns the domain part of an email address
+func domainOf(address string) string {
+	_, domain, _ := strings.Cut(address, "@")
+	return domain
+}
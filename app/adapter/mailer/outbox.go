@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// FileMailer writes every mail as an .eml file to an outbox directory instead of delivering it,
+// so that links such as password resets can be followed in development and tests
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer creates the outbox directory dir when it does not exist yet
+func NewFileMailer(dir string, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+// Send writes msg to a file named after the time it was sent, so that the outbox lists mail in order.
+// The file is written under a temporary name and renamed, so that a reader never sees half a mail.
+func (m *FileMailer) Send(ctx context.Context, msg domain.Mail) error {
+	now := time.Now().UTC()
+	data, err := render(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Join(m.dir, now.Format("20060102T150405.000000000Z")+"-"+uuid.New().String()+".eml")
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMailer(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "outbox")
+	mailer, err := NewFileMailer(dir, "Liongate <no-reply@liongate.test>")
+	require.NoError(t, err)
+
+	t.Run("writes a readable message", func(t *testing.T) {
+		require.NoError(t, mailer.Send(ctx, domain.Mail{
+			To:      "somchai@example.com",
+			Subject: "รีเซ็ตรหัสผ่าน",
+			Body:    "Open https://liongate.test/reset?token=abc\n",
+		}))
+
+		files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+
+		file, err := os.Open(files[0])
+		require.NoError(t, err)
+		defer file.Close()
+		msg, err := mail.ReadMessage(file)
+		require.NoError(t, err)
+
+		assert.Equal(t, "somchai@example.com", msg.Header.Get("To"))
+		subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+		require.NoError(t, err)
+		assert.Equal(t, "รีเซ็ตรหัสผ่าน", subject)
+		body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+		require.NoError(t, err)
+		assert.Equal(t, "Open https://liongate.test/reset?token=abc\r\n", string(body))
+	})
+
+	t.Run("rejects header injection", func(t *testing.T) {
+		err := mailer.Send(ctx, domain.Mail{To: "somchai@example.com", Subject: "Hi\r\nBcc: eve@example.com"})
+		assert.ErrorIs(t, err, ErrInvalidHeader)
+
+		err = mailer.Send(ctx, domain.Mail{To: "not an address", Subject: "Hi"})
+		assert.Error(t, err)
+	})
+}
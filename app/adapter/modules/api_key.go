@@ -0,0 +1,28 @@
+package modules
+
+import (
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/repository"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/core/services"
+	"go.uber.org/fx"
+)
+
+// ProvideApiKeyRepository extracts port.ApiKeyRepository from RepositoryFactory for Fx DI
+func ProvideApiKeyRepository(factory *repository.RepositoryFactory) (port.ApiKeyRepository, error) {
+	return factory.CreateApiKeyRepository()
+}
+
+// ProvideApiKeyService creates the API key service with the lifetime and rotation grace of keys from the config
+func ProvideApiKeyService(cfg *config.Config, apiKeys port.ApiKeyRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork) port.ApiKeyService {
+	return services.NewApiKeyService(apiKeys, auditLog, unitOfWork, cfg.ApiKeys.TTL, cfg.ApiKeys.RotationGrace)
+}
+
+var ApiKeyModule = fx.Options(
+	fx.Provide(
+		ProvideApiKeyRepository,
+		ProvideApiKeyService,
+		controllers.NewApiKeysController,
+	),
+)
@@ -0,0 +1,25 @@
+package modules
+
+import (
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/repository"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/core/services"
+	"go.uber.org/fx"
+)
+
+// ProvideAuditLogRepository extracts port.AuditLogRepository from RepositoryFactory for Fx DI
+func ProvideAuditLogRepository(factory *repository.RepositoryFactory) (port.AuditLogRepository, error) {
+	return factory.CreateAuditLogRepository()
+}
+
+var AuditLogModule = fx.Options(
+	fx.Provide(
+		ProvideAuditLogRepository,
+		fx.Annotate(
+			services.NewAuditLogService,
+			fx.As(new(port.AuditLogService)),
+		),
+		controllers.NewAuditLogController,
+	),
+)
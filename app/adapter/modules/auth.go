@@ -1,20 +1,97 @@
 package modules
 
 import (
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
 	"github.com/khunmostz/be-liongate-go/app/adapter/controllers"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/repository"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
 	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"github.com/khunmostz/be-liongate-go/app/core/services"
 	"github.com/khunmostz/be-liongate-go/app/utils"
 	"go.uber.org/fx"
 )
 
+// ProvideLoginAttemptRepository extracts port.LoginAttemptRepository from RepositoryFactory for Fx DI
+func ProvideLoginAttemptRepository(factory *repository.RepositoryFactory) (port.LoginAttemptRepository, error) {
+	return factory.CreateLoginAttemptRepository()
+}
+
+// ProvideMfaRepository extracts port.MfaRepository from RepositoryFactory for Fx DI
+func ProvideMfaRepository(factory *repository.RepositoryFactory) (port.MfaRepository, error) {
+	return factory.CreateMfaRepository()
+}
+
+// ProvideSessionRepository extracts port.SessionRepository from RepositoryFactory for Fx DI
+func ProvideSessionRepository(factory *repository.RepositoryFactory) (port.SessionRepository, error) {
+	return factory.CreateSessionRepository()
+}
+
+// ProvidePasswordResetRepository extracts port.PasswordResetRepository from RepositoryFactory for Fx DI
+func ProvidePasswordResetRepository(factory *repository.RepositoryFactory) (port.PasswordResetRepository, error) {
+	return factory.CreatePasswordResetRepository()
+}
+
+// loginPolicy builds the login throttling policy from the config
+func loginPolicy(cfg *config.Config) domain.LoginPolicy {
+	return domain.LoginPolicy{
+		MaxFailures:      cfg.Login.MaxFailures,
+		MaxFailuresPerIP: cfg.Login.MaxFailuresPerIP,
+		Window:           cfg.Login.Window,
+		Lockout:          cfg.Login.Lockout,
+		BaseDelay:        cfg.Login.BaseDelay,
+		MaxDelay:         cfg.Login.MaxDelay,
+	}
+}
+
+// ProvidePasswordHasher creates the hasher of passwords with the algorithm and parameters from the config
+func ProvidePasswordHasher(cfg *config.Config) (*utils.PasswordHasher, error) {
+	return utils.NewPasswordHasher(utils.PasswordParams{
+		Algorithm:         cfg.Passwords.Algorithm,
+		Argon2Memory:      cfg.Passwords.Argon2Memory,
+		Argon2Iterations:  cfg.Passwords.Argon2Iterations,
+		Argon2Parallelism: cfg.Passwords.Argon2Parallelism,
+		BcryptCost:        cfg.Passwords.BcryptCost,
+	})
+}
+
+// ProvideAuthService creates the auth service with the login throttling and MFA settings from the config. It is
+// provided as itself too, since sign-ins with external providers finish like its logins.
+func ProvideAuthService(cfg *config.Config, usersRepository port.UsersRepository, loginAttempts port.LoginAttemptRepository, mfa port.MfaRepository, sessions port.SessionRepository, auditLog port.AuditLogRepository, jwtService *utils.JWTService, hasher *utils.PasswordHasher) *services.AuthService {
+	return services.NewAuthService(usersRepository, loginAttempts, mfa, sessions, auditLog, jwtService, hasher, loginPolicy(cfg), cfg.Mfa.ChallengeTTL, cfg.Mfa.Issuer)
+}
+
+// ProvideMfaService creates the MFA service; codes are throttled with the same policy as passwords
+func ProvideMfaService(cfg *config.Config, usersRepository port.UsersRepository, mfa port.MfaRepository, loginAttempts port.LoginAttemptRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork) port.MfaService {
+	return services.NewMfaService(usersRepository, mfa, loginAttempts, auditLog, unitOfWork, loginPolicy(cfg), cfg.Mfa.Issuer)
+}
+
+// ProvidePasswordResetService creates the password reset service with the lifetime and link of resets from the config
+func ProvidePasswordResetService(cfg *config.Config, usersRepository port.UsersRepository, resets port.PasswordResetRepository, sessions port.SessionRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork, mailer port.Mailer, hasher *utils.PasswordHasher) port.PasswordResetService {
+	return services.NewPasswordResetService(usersRepository, resets, sessions, auditLog, unitOfWork, mailer, hasher, cfg.PasswordReset.TTL, cfg.PasswordReset.URL)
+}
+
 var AuthModule = fx.Options(
 	fx.Provide(
 		utils.NewJWTService,
+		ProvidePasswordHasher,
+		ProvideLoginAttemptRepository,
+		ProvideMfaRepository,
+		ProvideSessionRepository,
+		ProvidePasswordResetRepository,
 		fx.Annotate(
-			services.NewAuthService,
+			ProvideAuthService,
+			fx.As(fx.Self()),
 			fx.As(new(port.AuthService)),
 		),
+		ProvideMfaService,
+		ProvidePasswordResetService,
+		fx.Annotate(
+			services.NewLoginLockoutService,
+			fx.As(new(port.LoginLockoutService)),
+		),
 		controllers.NewAuthController,
+		controllers.NewAuthMiddleware,
+		controllers.NewLoginLockoutsController,
+		controllers.NewMfaController,
 	),
 )
@@ -0,0 +1,29 @@
+package modules
+
+import (
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/repository"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/core/services"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+	"go.uber.org/fx"
+)
+
+// ProvideInvitationRepository extracts port.InvitationRepository from RepositoryFactory for Fx DI
+func ProvideInvitationRepository(factory *repository.RepositoryFactory) (port.InvitationRepository, error) {
+	return factory.CreateInvitationRepository()
+}
+
+// ProvideInvitationService creates the invitation service with the lifetime and link of invitations from the config
+func ProvideInvitationService(cfg *config.Config, invitationRepository port.InvitationRepository, usersRepository port.UsersRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork, jwtService *utils.JWTService, hasher *utils.PasswordHasher) port.InvitationService {
+	return services.NewInvitationService(invitationRepository, usersRepository, auditLog, unitOfWork, jwtService, hasher, cfg.Invitations.TTL, cfg.Invitations.AcceptURL)
+}
+
+var InvitationModule = fx.Options(
+	fx.Provide(
+		ProvideInvitationRepository,
+		ProvideInvitationService,
+		controllers.NewInvitationsController,
+	),
+)
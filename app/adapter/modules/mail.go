@@ -0,0 +1,26 @@
+package modules
+
+import (
+	"fmt"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
+	"github.com/khunmostz/be-liongate-go/app/adapter/mailer"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"go.uber.org/fx"
+)
+
+// ProvideMailer creates the mailer selected by MAIL_DRIVER
+func ProvideMailer(cfg *config.Config) (port.Mailer, error) {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		return mailer.NewSMTPMailer(cfg.Mail.SMTPHost, cfg.Mail.SMTPPort, cfg.Mail.SMTPUsername, cfg.Mail.SMTPPassword, cfg.Mail.From), nil
+	case "file":
+		return mailer.NewFileMailer(cfg.Mail.OutboxDir, cfg.Mail.From)
+	default:
+		return nil, fmt.Errorf("unsupported mail driver: %s", cfg.Mail.Driver)
+	}
+}
+
+var MailModule = fx.Options(
+	fx.Provide(ProvideMailer),
+)
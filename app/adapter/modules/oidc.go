@@ -0,0 +1,43 @@
+package modules
+
+import (
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers"
+	"github.com/khunmostz/be-liongate-go/app/adapter/oidc"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/repository"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/core/services"
+	"go.uber.org/fx"
+)
+
+// ProvideOidcRepository extracts port.OidcRepository from RepositoryFactory for Fx DI
+func ProvideOidcRepository(factory *repository.RepositoryFactory) (port.OidcRepository, error) {
+	return factory.CreateOidcRepository()
+}
+
+// ProvideOidcProviders creates the identity providers listed in the config
+func ProvideOidcProviders(cfg *config.Config) ([]port.OidcProvider, error) {
+	providers := make([]port.OidcProvider, 0, len(cfg.Oidc.Providers))
+	for _, providerConfig := range cfg.Oidc.Providers {
+		provider, err := oidc.NewProvider(providerConfig)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+	return providers, nil
+}
+
+// ProvideOidcService creates the service signing users in with the identity providers
+func ProvideOidcService(cfg *config.Config, usersRepository port.UsersRepository, oidcRepository port.OidcRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork, auth *services.AuthService, providers []port.OidcProvider) port.OidcService {
+	return services.NewOidcService(usersRepository, oidcRepository, auditLog, unitOfWork, auth, providers, cfg.Oidc.LoginTTL)
+}
+
+var OidcModule = fx.Options(
+	fx.Provide(
+		ProvideOidcRepository,
+		ProvideOidcProviders,
+		ProvideOidcService,
+		controllers.NewOidcController,
+	),
+)
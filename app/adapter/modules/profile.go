@@ -0,0 +1,28 @@
+package modules
+
+import (
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/repository"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/core/services"
+	"go.uber.org/fx"
+)
+
+// ProvideVerificationRepository extracts port.VerificationRepository from RepositoryFactory for Fx DI
+func ProvideVerificationRepository(factory *repository.RepositoryFactory) (port.VerificationRepository, error) {
+	return factory.CreateVerificationRepository()
+}
+
+// ProvideProfileService creates the profile service with the lifetime and attempts of verification codes from the config
+func ProvideProfileService(cfg *config.Config, usersRepository port.UsersRepository, verifications port.VerificationRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork, mailer port.Mailer, sms port.SmsSender) port.ProfileService {
+	return services.NewProfileService(usersRepository, verifications, auditLog, unitOfWork, mailer, sms, cfg.Verification.CodeTTL, cfg.Verification.MaxAttempts)
+}
+
+var ProfileModule = fx.Options(
+	fx.Provide(
+		ProvideVerificationRepository,
+		ProvideProfileService,
+		controllers.NewProfileController,
+	),
+)
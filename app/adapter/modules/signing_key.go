@@ -0,0 +1,93 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/repository"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/core/services"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+	"go.uber.org/fx"
+)
+
+// ProvideSigningKeyRepository extracts port.SigningKeyRepository from RepositoryFactory for Fx DI
+func ProvideSigningKeyRepository(factory *repository.RepositoryFactory) (port.SigningKeyRepository, error) {
+	return factory.CreateSigningKeyRepository()
+}
+
+// ProvideSigningKeyService creates the key rotation service. Retired keys stay published until the longest lived
+// token they may have signed, an invitation link included, has expired. Private keys are encrypted with
+// JWT_KEY_ENCRYPTION_KEY, without which the app refuses to start.
+func ProvideSigningKeyService(cfg *config.Config, keys port.SigningKeyRepository, ring *utils.KeyRing, jwtService *utils.JWTService) (port.SigningKeyService, error) {
+	if cfg.SigningKeys.Rotation <= cfg.SigningKeys.PublishAhead {
+		return nil, fmt.Errorf("JWT_KEY_ROTATION (%s) must be longer than JWT_KEY_PUBLISH_AHEAD (%s)", cfg.SigningKeys.Rotation, cfg.SigningKeys.PublishAhead)
+	}
+	if cfg.SigningKeys.EncryptionKey == "" {
+		return nil, errors.New("JWT_KEY_ENCRYPTION_KEY is required, generate one with `openssl rand -base64 32`")
+	}
+	cipher, err := utils.NewKeyCipher(cfg.SigningKeys.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("JWT_KEY_ENCRYPTION_KEY: %w", err)
+	}
+	retention := max(jwtService.TokenLifetime(), cfg.Invitations.TTL, cfg.Mfa.ChallengeTTL)
+	return services.NewSigningKeyService(keys, ring, cipher, cfg.SigningKeys.Algorithm, cfg.SigningKeys.Rotation, cfg.SigningKeys.PublishAhead, retention), nil
+}
+
+// RunSigningKeyRotation loads the signing keys before the app serves, creating the first one on a fresh database,
+// and reloads and rotates them every JWT_KEY_REFRESH_INTERVAL so that keys created by other instances are picked up
+func RunSigningKeyRotation(lc fx.Lifecycle, cfg *config.Config, svc port.SigningKeyService) {
+	interval := cfg.SigningKeys.RefreshInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(startCtx context.Context) error {
+			if err := svc.Rotate(startCtx); err != nil {
+				return fmt.Errorf("loading signing keys: %w", err)
+			}
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						if err := svc.Rotate(ctx); err != nil {
+							log.Printf("signing key rotation failed: %v", err)
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+}
+
+var SigningKeyModule = fx.Options(
+	fx.Provide(
+		utils.NewKeyRing,
+		ProvideSigningKeyRepository,
+		ProvideSigningKeyService,
+		controllers.NewJWKSController,
+	),
+	fx.Invoke(RunSigningKeyRotation),
+)
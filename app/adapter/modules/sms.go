@@ -0,0 +1,26 @@
+package modules
+
+import (
+	"fmt"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
+	"github.com/khunmostz/be-liongate-go/app/adapter/sms"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"go.uber.org/fx"
+)
+
+// ProvideSmsSender creates the text message sender selected by SMS_DRIVER
+func ProvideSmsSender(cfg *config.Config) (port.SmsSender, error) {
+	switch cfg.Sms.Driver {
+	case "webhook":
+		return sms.NewWebhookSender(cfg.Sms.WebhookURL, cfg.Sms.WebhookToken)
+	case "file":
+		return sms.NewFileSender(cfg.Sms.OutboxDir)
+	default:
+		return nil, fmt.Errorf("unsupported sms driver: %s", cfg.Sms.Driver)
+	}
+}
+
+var SmsModule = fx.Options(
+	fx.Provide(ProvideSmsSender),
+)
@@ -0,0 +1,75 @@
+package modules
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/repository"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/core/services"
+	"go.uber.org/fx"
+)
+
+// ProvideTrashRepository extracts port.TrashRepository from RepositoryFactory for Fx DI
+func ProvideTrashRepository(factory *repository.RepositoryFactory) (port.TrashRepository, error) {
+	return factory.CreateTrashRepository()
+}
+
+// ProvideTrashService creates the trash service with the retention period from the config
+func ProvideTrashService(cfg *config.Config, trashRepository port.TrashRepository, usersRepository port.UsersRepository, animalRepository port.AnimalsRepository, stageRepository port.PerformanceStageRepository, showRoundRepository port.ShowRoundsRepository, bookingsRepository port.BookingsRepository, unitOfWork port.UnitOfWork) port.TrashService {
+	return services.NewTrashService(trashRepository, usersRepository, animalRepository, stageRepository, showRoundRepository, bookingsRepository, unitOfWork, cfg.Trash.Retention)
+}
+
+// RunTrashPurge purges expired trash every TRASH_PURGE_INTERVAL while the app runs; a zero interval disables it
+func RunTrashPurge(lc fx.Lifecycle, cfg *config.Config, svc port.TrashService) {
+	interval := cfg.Trash.PurgeInterval
+	if interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						purged, err := svc.PurgeExpired(ctx)
+						if err != nil {
+							log.Printf("trash purge failed after purging %d entities: %v", purged, err)
+						} else if purged > 0 {
+							log.Printf("trash purge removed %d expired entities", purged)
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+}
+
+var TrashModule = fx.Options(
+	fx.Provide(
+		ProvideTrashRepository,
+		ProvideTrashService,
+		controllers.NewTrashController,
+	),
+	fx.Invoke(RunTrashPurge),
+)
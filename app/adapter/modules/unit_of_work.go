@@ -0,0 +1,18 @@
+package modules
+
+import (
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/repository"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"go.uber.org/fx"
+)
+
+// ProvideUnitOfWork extracts port.UnitOfWork from RepositoryFactory for Fx DI
+func ProvideUnitOfWork(factory *repository.RepositoryFactory) (port.UnitOfWork, error) {
+	return factory.CreateUnitOfWork()
+}
+
+var UnitOfWorkModule = fx.Options(
+	fx.Provide(
+		ProvideUnitOfWork,
+	),
+)
@@ -0,0 +1,369 @@
+// Package oidctest runs an OpenID Connect provider in the test process, so that sign-ins can be tested without
+// a real identity provider.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
+)
+
+// RedirectURL is the redirect URL of the client registered at the provider
+const RedirectURL = "http://client.test/auth/oidc/callback"
+
+// Identity is the account of the provider a user signs in with
+type Identity struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// grant is a code issued by the authorization endpoint, waiting to be redeemed
+type grant struct {
+	identity      Identity
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+}
+
+// signingKey is an RSA key of the provider with its key id
+type signingKey struct {
+	kid     string
+	private *rsa.PrivateKey
+}
+
+// Provider is a provider with one registered client. It serves discovery, the authorization endpoint, the token
+// endpoint with PKCE and the JWKS of the key it signs ID tokens with.
+type Provider struct {
+	Server       *httptest.Server
+	ClientID     string
+	ClientSecret string
+
+	mu sync.Mutex
+	// keys are the published keys; the last one signs
+	keys     []signingKey
+	grants   map[string]grant
+	identity Identity
+	// claims edits the claims of ID tokens before they are signed
+	claims func(jwt.MapClaims)
+	// signWithSecret signs ID tokens with the client secret and HS256
+	signWithSecret bool
+	// forge signs ID tokens with a key that is not published, under the kid of the published one
+	forge bool
+}
+
+// NewProvider starts a provider, which is stopped when t ends
+func NewProvider(t testing.TB) *Provider {
+	t.Helper()
+
+	p := &Provider{ClientID: "liongate-client", ClientSecret: "liongate-secret", grants: map[string]grant{}}
+	p.RotateKey(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /authorize", p.authorize)
+	mux.HandleFunc("POST /token", p.token)
+	mux.HandleFunc("GET /jwks", p.jwks)
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Server.Close)
+	return p
+}
+
+// Issuer is the issuer of the provider, from which its endpoints are discovered
+func (p *Provider) Issuer() string {
+	return p.Server.URL
+}
+
+// Config returns the configuration of a provider named name signing in at p
+func (p *Provider) Config(name string) config.OidcProviderConfig {
+	return config.OidcProviderConfig{
+		Name:         name,
+		Issuer:       p.Issuer(),
+		ClientID:     p.ClientID,
+		ClientSecret: p.ClientSecret,
+		RedirectURL:  RedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+		Role:         "user",
+	}
+}
+
+// SignIn follows authorizationURL as a browser would, signing in as identity, and returns the code and state the
+// provider redirects back with
+func (p *Provider) SignIn(t testing.TB, authorizationURL string, identity Identity) (code string, state string) {
+	t.Helper()
+
+	p.mu.Lock()
+	p.identity = identity
+	p.mu.Unlock()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authorizationURL)
+	if err != nil {
+		t.Fatalf("oidctest: authorization request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("oidctest: authorization request answered %s", resp.Status)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("oidctest: bad redirect: %v", err)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+// RotateKey publishes a new key and signs the next ID tokens with it
+func (p *Provider) RotateKey(t testing.TB) {
+	t.Helper()
+
+	key, err := newSigningKey()
+	if err != nil {
+		t.Fatalf("oidctest: %v", err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append(p.keys, key)
+}
+
+// EditClaims makes edit change the claims of the next ID tokens before they are signed
+func (p *Provider) EditClaims(edit func(claims jwt.MapClaims)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.claims = edit
+}
+
+// SignWithSecret makes the provider sign the next ID tokens with HS256 and the client secret, as LINE does
+func (p *Provider) SignWithSecret() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.signWithSecret = true
+}
+
+// Forge makes the provider sign the next ID tokens with a key it does not publish
+func (p *Provider) Forge() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.forge = true
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.Issuer(),
+		"authorization_endpoint":                p.Issuer() + "/authorize",
+		"token_endpoint":                        p.Issuer() + "/token",
+		"jwks_uri":                              p.Issuer() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	switch {
+	case query.Get("response_type") != "code":
+		http.Error(w, "unsupported response_type", http.StatusBadRequest)
+		return
+	case query.Get("client_id") != p.ClientID:
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	case query.Get("redirect_uri") != RedirectURL:
+		http.Error(w, "unregistered redirect_uri", http.StatusBadRequest)
+		return
+	case !slices.Contains(strings.Fields(query.Get("scope")), "openid"):
+		http.Error(w, "scope must include openid", http.StatusBadRequest)
+		return
+	case query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "":
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	code, err := randomHex(16)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	p.mu.Lock()
+	p.grants[code] = grant{
+		identity:      p.identity,
+		redirectURI:   query.Get("redirect_uri"),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+	}
+	p.mu.Unlock()
+
+	redirect, _ := url.Parse(query.Get("redirect_uri"))
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientId, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientId != p.ClientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(p.ClientSecret)) != 1 {
+		tokenError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	p.mu.Lock()
+	code := r.PostForm.Get("code")
+	g, ok := p.grants[code]
+	delete(p.grants, code)
+	p.mu.Unlock()
+
+	if !ok || g.redirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+	challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(challenge[:]) != g.codeChallenge {
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	idToken, err := p.idToken(g)
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	accessToken, err := randomHex(16)
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	keys := make([]map[string]string, 0, len(p.keys))
+	for _, key := range p.keys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"kid": key.kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.private.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.private.E)).Bytes()),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"keys": keys})
+}
+
+// idToken signs the ID token of g
+func (p *Provider) idToken(g grant) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   p.Issuer(),
+		"sub":   g.identity.Subject,
+		"aud":   p.ClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": g.nonce,
+	}
+	if g.identity.Email != "" {
+		claims["email"] = g.identity.Email
+		claims["email_verified"] = g.identity.EmailVerified
+	}
+	if g.identity.Name != "" {
+		claims["name"] = g.identity.Name
+	}
+	if g.identity.PreferredUsername != "" {
+		claims["preferred_username"] = g.identity.PreferredUsername
+	}
+	if p.claims != nil {
+		p.claims(claims)
+	}
+
+	if p.signWithSecret {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(p.ClientSecret))
+	}
+	key := p.keys[len(p.keys)-1]
+	private := key.private
+	if p.forge {
+		forged, err := newSigningKey()
+		if err != nil {
+			return "", err
+		}
+		private = forged.private
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(private)
+}
+
+// newSigningKey creates an RSA key with a random key id
+func newSigningKey() (signingKey, error) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return signingKey{}, err
+	}
+	kid, err := randomHex(8)
+	if err != nil {
+		return signingKey{}, err
+	}
+	return signingKey{kid: kid, private: private}, nil
+}
+
+// randomHex returns n random bytes in hex
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func tokenError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,307 @@
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// httpTimeout bounds each request to a provider, so that a hanging provider does not hold up a sign-in
+const httpTimeout = 10 * time.Second
+
+// clockLeeway tolerates clock skew between us and a provider on the times of ID tokens
+const clockLeeway = time.Minute
+
+// keysRefetchInterval limits how often the keys of a provider are fetched again for ID tokens signed with an
+// unknown key, so that forged key ids cannot make us hammer the provider
+const keysRefetchInterval = time.Minute
+
+// signatureAlgorithms are the asymmetric algorithms accepted on ID tokens
+var signatureAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// metadata is the part of the discovery document of a provider (OpenID Connect Discovery 1.0) we use
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// tokenResponse is the answer of the token endpoint, or its error (RFC 6749 section 5)
+type tokenResponse struct {
+	IdToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// idTokenClaims are the claims of an ID token we check or use
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string       `json:"nonce"`
+	AuthorizedParty   string       `json:"azp"`
+	Email             string       `json:"email"`
+	EmailVerified     flexibleBool `json:"email_verified"`
+	Name              string       `json:"name"`
+	PreferredUsername string       `json:"preferred_username"`
+}
+
+// flexibleBool is a boolean claim that some providers send as the string "true" or "false"
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case bool:
+		*b = flexibleBool(value)
+	case string:
+		*b = flexibleBool(value == "true")
+	default:
+		*b = false
+	}
+	return nil
+}
+
+// Provider signs users in with an OpenID Connect provider through the authorization code flow with PKCE. The
+// endpoints are discovered from the issuer on first use, and the ID token returned with the code is checked
+// against the keys the provider publishes.
+type Provider struct {
+	name         string
+	role         string
+	issuer       string
+	clientId     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	client       *http.Client
+
+	mu        sync.Mutex
+	metadata  *metadata
+	keys      map[string]any
+	fetchedAt time.Time
+}
+
+// NewProvider creates the provider configured by cfg
+func NewProvider(cfg config.OidcProviderConfig) (*Provider, error) {
+	prefix := "OIDC_" + strings.ToUpper(cfg.Name) + "_"
+	if cfg.Issuer == "" {
+		return nil, fmt.Errorf("%sISSUER is required", prefix)
+	}
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("%sCLIENT_ID is required", prefix)
+	}
+	if cfg.RedirectURL == "" {
+		return nil, fmt.Errorf("%sREDIRECT_URL is required", prefix)
+	}
+	if cfg.Role != domain.RoleAdmin && cfg.Role != domain.RoleUser {
+		return nil, fmt.Errorf("%sROLE %q is not a role", prefix, cfg.Role)
+	}
+	scopes := cfg.Scopes
+	if !slices.Contains(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+
+	return &Provider{
+		name:         cfg.Name,
+		role:         cfg.Role,
+		issuer:       cfg.Issuer,
+		clientId:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		redirectURL:  cfg.RedirectURL,
+		scopes:       scopes,
+		client:       &http.Client{Timeout: httpTimeout},
+	}, nil
+}
+
+func (p *Provider) Name() string {
+	return p.name
+}
+
+func (p *Provider) Role() string {
+	return p.role
+}
+
+// AuthorizationURL returns the URL of the authorization endpoint asking for a code for the S256 codeChallenge
+func (p *Provider) AuthorizationURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	endpoint, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("authorization endpoint of %s: %w", p.name, err)
+	}
+
+	query := endpoint.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.clientId)
+	query.Set("redirect_uri", p.redirectURL)
+	query.Set("scope", strings.Join(p.scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	endpoint.RawQuery = query.Encode()
+	return endpoint.String(), nil
+}
+
+// Exchange redeems code with codeVerifier and returns the claims of the ID token, which must carry nonce
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*domain.OidcClaims, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.redirectURL},
+		"client_id":     {p.clientId},
+		"code_verifier": {codeVerifier},
+	}
+	if p.clientSecret != "" {
+		form.Set("client_secret", p.clientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem code with %s: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&token)
+		return nil, fmt.Errorf("%w: %s rejected the code: %s %s", domain.ErrOidcLoginInvalid, p.name, token.Error, token.ErrorDescription)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("failed to redeem code with %s: token endpoint answered %s: %s", p.name, resp.Status, bytes.TrimSpace(detail))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to redeem code with %s: %w", p.name, err)
+	}
+	if token.IdToken == "" {
+		return nil, fmt.Errorf("%w: %s returned no ID token", domain.ErrOidcLoginInvalid, p.name)
+	}
+	return p.verify(ctx, meta, token.IdToken, nonce)
+}
+
+// verify checks the signature, issuer, audience, times and nonce of an ID token (OpenID Connect Core 1.0
+// section 3.1.3.7) and returns its claims
+func (p *Provider) verify(ctx context.Context, meta *metadata, idToken string, nonce string) (*domain.OidcClaims, error) {
+	methods := signatureAlgorithms
+	if p.clientSecret != "" {
+		// Some providers, LINE among them, sign ID tokens of web logins with the client secret
+		methods = append([]string{"HS256"}, signatureAlgorithms...)
+	}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.clientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockLeeway),
+	)
+
+	var claims idTokenClaims
+	var keyErr error
+	_, err := parser.ParseWithClaims(idToken, &claims, func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+			return []byte(p.clientSecret), nil
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.key(ctx, meta, kid)
+		keyErr = err
+		return key, err
+	})
+	if keyErr != nil && !errors.Is(keyErr, errUnknownKey) {
+		return nil, keyErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: ID token of %s: %v", domain.ErrOidcLoginInvalid, p.name, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: ID token of %s has no subject", domain.ErrOidcLoginInvalid, p.name)
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: ID token of %s has another nonce", domain.ErrOidcLoginInvalid, p.name)
+	}
+	if (len(claims.Audience) > 1 || claims.AuthorizedParty != "") && claims.AuthorizedParty != p.clientId {
+		return nil, fmt.Errorf("%w: ID token of %s was issued to another party", domain.ErrOidcLoginInvalid, p.name)
+	}
+
+	return &domain.OidcClaims{
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     bool(claims.EmailVerified),
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+// discover returns the metadata of the provider, fetching it on first use. The issuer the document names must
+// be the configured one, since ID tokens are checked against it.
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+	var meta metadata
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.issuer, "/")+"/.well-known/openid-configuration", &meta); err != nil {
+		return nil, fmt.Errorf("failed to discover %s: %w", p.name, err)
+	}
+	if meta.Issuer != p.issuer {
+		return nil, fmt.Errorf("failed to discover %s: document is for issuer %q", p.name, meta.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JwksURI == "" {
+		return nil, fmt.Errorf("failed to discover %s: document lacks an endpoint", p.name)
+	}
+	p.metadata = &meta
+	return p.metadata, nil
+}
+
+// getJSON decodes the JSON document at url into v
+func (p *Provider) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s answered %s: %s", url, resp.Status, bytes.TrimSpace(detail))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
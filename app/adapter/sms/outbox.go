@@ -0,0 +1,44 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// FileSender writes every text message as a .sms file to an outbox directory instead of delivering it,
+// so that verification codes can be read in development and tests
+type FileSender struct {
+	dir string
+}
+
+// NewFileSender creates the outbox directory dir when it does not exist yet
+func NewFileSender(dir string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileSender{dir: dir}, nil
+}
+
+// SendSms writes msg to a file named after the time it was sent, with the recipient on the first line.
+// Like the mail outbox, the file is renamed into place once complete.
+func (s *FileSender) SendSms(ctx context.Context, msg domain.Sms) error {
+	now := time.Now().UTC()
+	data := fmt.Sprintf("To: %s\n\n%s\n", msg.To, msg.Body)
+
+	name := filepath.Join(s.dir, now.Format("20060102T150405.000000000Z")+"-"+uuid.New().String()+".sms")
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, []byte(data), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
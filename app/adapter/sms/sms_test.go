@@ -0,0 +1,72 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	sender, err := NewFileSender(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, sender.SendSms(context.Background(), domain.Sms{To: "+66812345678", Body: "Your code is 042917."}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.sms"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, "To: +66812345678\n\nYour code is 042917.\n", string(data))
+}
+
+func TestWebhookSender(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("posts the message", func(t *testing.T) {
+		var got webhookPayload
+		var authorization string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		sender, err := NewWebhookSender(server.URL, "secret")
+		require.NoError(t, err)
+
+		require.NoError(t, sender.SendSms(ctx, domain.Sms{To: "+66812345678", Body: "Your code is 042917."}))
+		assert.Equal(t, webhookPayload{To: "+66812345678", Body: "Your code is 042917."}, got)
+		assert.Equal(t, "Bearer secret", authorization)
+	})
+
+	t.Run("gateway error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unknown number", http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		sender, err := NewWebhookSender(server.URL, "")
+		require.NoError(t, err)
+
+		err = sender.SendSms(ctx, domain.Sms{To: "+66812345678", Body: "hello"})
+		assert.ErrorContains(t, err, "400 Bad Request: unknown number")
+	})
+
+	t.Run("url is required", func(t *testing.T) {
+		_, err := NewWebhookSender("", "")
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,67 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// webhookTimeout bounds one delivery, so that a hanging gateway does not hold up the request that sends a code
+const webhookTimeout = 30 * time.Second
+
+// webhookPayload is the JSON body posted to the gateway
+type webhookPayload struct {
+	To   string `json:"to"`
+	Body string `json:"body"`
+}
+
+// WebhookSender hands text messages to an SMS gateway by posting them as JSON to its URL.
+// Any gateway, or a small relay in front of one, that accepts {"to": "+66...", "body": "..."} works.
+type WebhookSender struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewWebhookSender creates a sender posting to url; token, when set, is sent as bearer token
+func NewWebhookSender(url string, token string) (*WebhookSender, error) {
+	if url == "" {
+		return nil, errors.New("SMS_WEBHOOK_URL is required for the webhook SMS driver")
+	}
+	return &WebhookSender{url: url, token: token, client: &http.Client{Timeout: webhookTimeout}}, nil
+}
+
+// SendSms posts msg to the gateway; any answer other than 2xx is an error
+func (s *WebhookSender) SendSms(ctx context.Context, msg domain.Sms) error {
+	body, err := json.Marshal(webhookPayload{To: msg.To, Body: msg.Body})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send sms: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to send sms: gateway answered %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/khunmostz/be-liongate-go/app/adapter/config"
 	localGorm "github.com/khunmostz/be-liongate-go/app/adapter/store/repository/gorm"
+	localMemory "github.com/khunmostz/be-liongate-go/app/adapter/store/repository/memory"
 	localMongo "github.com/khunmostz/be-liongate-go/app/adapter/store/repository/mongo"
 	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -13,17 +14,20 @@ import (
 
 // RepositoryFactory creates repositories based on configuration
 type RepositoryFactory struct {
-	config     *config.Config
-	mongoDB    *mongo.Database
-	postgresql *gorm.DB
+	config  *config.Config
+	mongoDB *mongo.Database
+	gormDB  *gorm.DB
+	memory  *localMemory.Store
 }
 
 // NewRepositoryFactory creates a new repository factory
-func NewRepositoryFactory(cfg *config.Config, mongoDb *mongo.Database, postgresDb *gorm.DB) *RepositoryFactory {
+// gormDb backs both the postgresql and sqlite database types
+func NewRepositoryFactory(cfg *config.Config, mongoDb *mongo.Database, gormDb *gorm.DB, memoryStore *localMemory.Store) *RepositoryFactory {
 	return &RepositoryFactory{
-		config:     cfg,
-		mongoDB:    mongoDb,
-		postgresql: postgresDb,
+		config:  cfg,
+		mongoDB: mongoDb,
+		gormDB:  gormDb,
+		memory:  memoryStore,
 	}
 }
 
@@ -36,11 +40,16 @@ func (f *RepositoryFactory) CreateUserRepository() (port.UsersRepository, error)
 		}
 		collection := f.mongoDB.Collection("users")
 		return localMongo.NewMongoUserRepository(collection), nil
-	case "postgresql":
-		if f.postgresql == nil {
-			return nil, fmt.Errorf("postgresql connection is not initialized")
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
 		}
-		return localGorm.NewGormUsersRepository(f.postgresql), nil
+		return localGorm.NewGormUsersRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
+		}
+		return localMemory.NewMemoryUserRepository(f.memory), nil
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
 	}
@@ -55,11 +64,16 @@ func (f *RepositoryFactory) CreateBookingRepository() (port.BookingsRepository,
 		}
 		collection := f.mongoDB.Collection("bookings")
 		return localMongo.NewMongoBookingRepository(collection), nil
-	case "postgresql":
-		if f.postgresql == nil {
-			return nil, fmt.Errorf("postgresql connection is not initialized")
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormBookingRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
 		}
-		return localGorm.NewGormBookingRepository(f.postgresql), nil
+		return localMemory.NewMemoryBookingRepository(f.memory), nil
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
 	}
@@ -73,11 +87,16 @@ func (f *RepositoryFactory) CreateShowRoundRepository() (port.ShowRoundsReposito
 		}
 		collection := f.mongoDB.Collection("show_rounds")
 		return localMongo.NewMongoShowRoundRepository(collection), nil
-	case "postgresql":
-		if f.postgresql == nil {
-			return nil, fmt.Errorf("postgresql connection is not initialized")
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormShowRoundRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
 		}
-		return localGorm.NewGormShowRoundRepository(f.postgresql), nil
+		return localMemory.NewMemoryShowRoundRepository(f.memory), nil
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
 	}
@@ -91,11 +110,16 @@ func (f *RepositoryFactory) CreateAnimalRepository() (port.AnimalsRepository, er
 		}
 		collection := f.mongoDB.Collection("animals")
 		return localMongo.NewMongoAnimalRepository(collection), nil
-	case "postgresql":
-		if f.postgresql == nil {
-			return nil, fmt.Errorf("postgresql connection is not initialized")
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
 		}
-		return localGorm.NewGormAnimalRepository(f.postgresql), nil
+		return localGorm.NewGormAnimalRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
+		}
+		return localMemory.NewMemoryAnimalRepository(f.memory), nil
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
 	}
@@ -109,11 +133,295 @@ func (f *RepositoryFactory) CreatePerformanceStageRepository() (port.Performance
 		}
 		collection := f.mongoDB.Collection("performance_stages")
 		return localMongo.NewMongoPerformanceStageRepository(collection), nil
-	case "postgresql":
-		if f.postgresql == nil {
-			return nil, fmt.Errorf("postgresql connection is not initialized")
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormPerformanceStageRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
+		}
+		return localMemory.NewMemoryPerformanceStageRepository(f.memory), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
+	}
+}
+
+// CreateTrashRepository returns the repository reaching the entities soft-deleted by the other repositories of this factory
+func (f *RepositoryFactory) CreateTrashRepository() (port.TrashRepository, error) {
+	switch f.config.Database.DbType {
+	case "mongodb":
+		if f.mongoDB == nil {
+			return nil, fmt.Errorf("mongodb connection is not initialized")
+		}
+		return localMongo.NewMongoTrashRepository(f.mongoDB), nil
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormTrashRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
+		}
+		return localMemory.NewMemoryTrashRepository(f.memory), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
+	}
+}
+
+// CreateAuditLogRepository returns the append-only audit log implementation
+func (f *RepositoryFactory) CreateAuditLogRepository() (port.AuditLogRepository, error) {
+	switch f.config.Database.DbType {
+	case "mongodb":
+		if f.mongoDB == nil {
+			return nil, fmt.Errorf("mongodb connection is not initialized")
+		}
+		collection := f.mongoDB.Collection("audit_log_entries")
+		return localMongo.NewMongoAuditLogRepository(collection), nil
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormAuditLogRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
+		}
+		return localMemory.NewMemoryAuditLogRepository(f.memory), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
+	}
+}
+
+// CreateInvitationRepository returns the appropriate invitation repository implementation
+func (f *RepositoryFactory) CreateInvitationRepository() (port.InvitationRepository, error) {
+	switch f.config.Database.DbType {
+	case "mongodb":
+		if f.mongoDB == nil {
+			return nil, fmt.Errorf("mongodb connection is not initialized")
+		}
+		collection := f.mongoDB.Collection("invitations")
+		return localMongo.NewMongoInvitationRepository(collection), nil
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormInvitationRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
+		}
+		return localMemory.NewMemoryInvitationRepository(f.memory), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
+	}
+}
+
+// CreateLoginAttemptRepository returns the failed login counters of the configured database
+func (f *RepositoryFactory) CreateLoginAttemptRepository() (port.LoginAttemptRepository, error) {
+	switch f.config.Database.DbType {
+	case "mongodb":
+		if f.mongoDB == nil {
+			return nil, fmt.Errorf("mongodb connection is not initialized")
+		}
+		collection := f.mongoDB.Collection("login_attempts")
+		return localMongo.NewMongoLoginAttemptRepository(collection), nil
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormLoginAttemptRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
+		}
+		return localMemory.NewMemoryLoginAttemptRepository(f.memory), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
+	}
+}
+
+// CreateMfaRepository returns the store of second factors and the roles requiring them
+func (f *RepositoryFactory) CreateMfaRepository() (port.MfaRepository, error) {
+	switch f.config.Database.DbType {
+	case "mongodb":
+		if f.mongoDB == nil {
+			return nil, fmt.Errorf("mongodb connection is not initialized")
+		}
+		return localMongo.NewMongoMfaRepository(f.mongoDB.Collection("mfa_enrollments"), f.mongoDB.Collection("mfa_requirements")), nil
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormMfaRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
+		}
+		return localMemory.NewMemoryMfaRepository(f.memory), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
+	}
+}
+
+// CreatePasswordResetRepository returns the store of pending password resets
+func (f *RepositoryFactory) CreatePasswordResetRepository() (port.PasswordResetRepository, error) {
+	switch f.config.Database.DbType {
+	case "mongodb":
+		if f.mongoDB == nil {
+			return nil, fmt.Errorf("mongodb connection is not initialized")
+		}
+		return localMongo.NewMongoPasswordResetRepository(f.mongoDB.Collection("password_reset_tokens")), nil
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormPasswordResetRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
+		}
+		return localMemory.NewMemoryPasswordResetRepository(f.memory), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
+	}
+}
+
+// CreateSessionRepository returns the store of session revocations
+func (f *RepositoryFactory) CreateSessionRepository() (port.SessionRepository, error) {
+	switch f.config.Database.DbType {
+	case "mongodb":
+		if f.mongoDB == nil {
+			return nil, fmt.Errorf("mongodb connection is not initialized")
+		}
+		return localMongo.NewMongoSessionRepository(f.mongoDB.Collection("session_revocations"), f.mongoDB.Collection("revoked_tokens")), nil
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormSessionRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
+		}
+		return localMemory.NewMemorySessionRepository(f.memory), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
+	}
+}
+
+// CreateVerificationRepository returns the store of pending contact verifications
+func (f *RepositoryFactory) CreateVerificationRepository() (port.VerificationRepository, error) {
+	switch f.config.Database.DbType {
+	case "mongodb":
+		if f.mongoDB == nil {
+			return nil, fmt.Errorf("mongodb connection is not initialized")
+		}
+		return localMongo.NewMongoVerificationRepository(f.mongoDB.Collection("contact_verifications")), nil
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormVerificationRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
+		}
+		return localMemory.NewMemoryVerificationRepository(f.memory), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
+	}
+}
+
+// CreateSigningKeyRepository returns the store of the keys tokens are signed with
+func (f *RepositoryFactory) CreateSigningKeyRepository() (port.SigningKeyRepository, error) {
+	switch f.config.Database.DbType {
+	case "mongodb":
+		if f.mongoDB == nil {
+			return nil, fmt.Errorf("mongodb connection is not initialized")
+		}
+		return localMongo.NewMongoSigningKeyRepository(f.mongoDB.Collection("signing_keys")), nil
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormSigningKeyRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
+		}
+		return localMemory.NewMemorySigningKeyRepository(f.memory), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
+	}
+}
+
+// CreateApiKeyRepository returns the store of machine clients and their API keys
+func (f *RepositoryFactory) CreateApiKeyRepository() (port.ApiKeyRepository, error) {
+	switch f.config.Database.DbType {
+	case "mongodb":
+		if f.mongoDB == nil {
+			return nil, fmt.Errorf("mongodb connection is not initialized")
+		}
+		return localMongo.NewMongoApiKeyRepository(f.mongoDB.Collection("api_clients"), f.mongoDB.Collection("api_keys")), nil
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormApiKeyRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
+		}
+		return localMemory.NewMemoryApiKeyRepository(f.memory), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
+	}
+}
+
+// CreateUnitOfWork returns the unit of work matching the repositories created by this factory
+func (f *RepositoryFactory) CreateUnitOfWork() (port.UnitOfWork, error) {
+	switch f.config.Database.DbType {
+	case "mongodb":
+		if f.mongoDB == nil {
+			return nil, fmt.Errorf("mongodb connection is not initialized")
+		}
+		return localMongo.NewMongoUnitOfWork(f.mongoDB, f.config.MongoDB.AllowStandalone)
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormUnitOfWork(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
+		}
+		return localMemory.NewMemoryUnitOfWork(f.memory), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
+	}
+}
+
+// CreateOidcRepository returns the store of the identities users sign in with at external providers
+func (f *RepositoryFactory) CreateOidcRepository() (port.OidcRepository, error) {
+	switch f.config.Database.DbType {
+	case "mongodb":
+		if f.mongoDB == nil {
+			return nil, fmt.Errorf("mongodb connection is not initialized")
+		}
+		return localMongo.NewMongoOidcRepository(f.mongoDB.Collection("oidc_logins"), f.mongoDB.Collection("oidc_identities")), nil
+	case "postgresql", "sqlite":
+		if f.gormDB == nil {
+			return nil, fmt.Errorf("%s connection is not initialized", f.config.Database.DbType)
+		}
+		return localGorm.NewGormOidcRepository(f.gormDB), nil
+	case "memory":
+		if f.memory == nil {
+			return nil, fmt.Errorf("memory store is not initialized")
 		}
-		return localGorm.NewGormPerformanceStageRepository(f.postgresql), nil
+		return localMemory.NewMemoryOidcRepository(f.memory), nil
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", f.config.Database.DbType)
 	}
@@ -5,9 +5,21 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"gorm.io/gorm"
 )
 
+// animalSortColumns whitelists the fields GetAnimals can be sorted by
+var animalSortColumns = map[string]string{
+	"animal_id":     "animal_id",
+	"name":          "name",
+	"species":       "species",
+	"type":          "type",
+	"show_duration": "show_duration",
+	"created_at":    "created_at",
+	"updated_at":    "updated_at",
+}
+
 type GormAnimalRepository struct {
 	base *BaseGormRepository
 }
@@ -18,17 +30,29 @@ func NewGormAnimalRepository(db *gorm.DB) *GormAnimalRepository {
 	}
 }
 
-func (r *GormAnimalRepository) GetAnimals(ctx context.Context) ([]domain.Animals, error) {
+func (r *GormAnimalRepository) GetAnimals(ctx context.Context, filter port.AnimalFilter, query port.ListQuery) (*port.Page[domain.Animals], error) {
+	tx := live(ctx, r.base.db).Model(&domain.Animals{})
+	if filter.Species != "" {
+		tx = tx.Where("species = ?", filter.Species)
+	}
+	if filter.Type != "" {
+		tx = tx.Where("type = ?", filter.Type)
+	}
+	tx = whereAudit(tx, filter.Audit)
+
 	var animals []domain.Animals
-	if err := r.base.db.WithContext(ctx).Find(&animals).Error; err != nil {
+	total, query, err := findPage(tx, query, animalSortColumns, "animal_id", &animals)
+	if err != nil {
 		return nil, err
 	}
-	return animals, nil
+	return port.NewPage(animals, total, query), nil
 }
 
 func (r *GormAnimalRepository) CreateAnimal(ctx context.Context, animal *domain.Animals) (*domain.Animals, error) {
 	// Generate UUID for new animal
 	animal.Id = uuid.New().String()
+	animal.Version = 1
+	animal.Audit = port.NewAudit(ctx)
 
 	if err := r.base.Create(ctx, animal); err != nil {
 		return nil, err
@@ -38,8 +62,8 @@ func (r *GormAnimalRepository) CreateAnimal(ctx context.Context, animal *domain.
 
 func (r *GormAnimalRepository) GetAnimalById(ctx context.Context, id string) (*domain.Animals, error) {
 	var animal domain.Animals
-	if err := r.base.db.WithContext(ctx).Where("animal_id = ?", id).First(&animal).Error; err != nil {
-		return nil, err
+	if err := live(ctx, r.base.db).Where("animal_id = ?", id).First(&animal).Error; err != nil {
+		return nil, translateError(err)
 	}
 	return &animal, nil
 }
@@ -50,7 +74,13 @@ func (r *GormAnimalRepository) UpdateAnimal(ctx context.Context, id string, anim
 		return nil, err
 	}
 
-	if err := r.base.db.WithContext(ctx).Model(existingAnimal).Updates(animal).Error; err != nil {
+	if err := domain.CheckVersion(animal.Version, existingAnimal.Version); err != nil {
+		return nil, err
+	}
+
+	animal.Version = existingAnimal.Version + 1
+	animal.Audit = port.UpdateAudit(ctx)
+	if err := updateVersioned(live(ctx, r.base.db), existingAnimal, existingAnimal.Version, animal); err != nil {
 		return nil, err
 	}
 
@@ -58,5 +88,5 @@ func (r *GormAnimalRepository) UpdateAnimal(ctx context.Context, id string, anim
 }
 
 func (r *GormAnimalRepository) DeleteAnimal(ctx context.Context, id string) error {
-	return r.base.db.WithContext(ctx).Where("animal_id = ?", id).Delete(&domain.Animals{}).Error
+	return softDelete(ctx, r.base.db, &domain.Animals{}, "animal_id", id)
 }
@@ -0,0 +1,150 @@
+package gorm
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"gorm.io/gorm"
+)
+
+// GormApiKeyRepository stores machine clients and their API keys in two tables
+type GormApiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewGormApiKeyRepository(db *gorm.DB) *GormApiKeyRepository {
+	return &GormApiKeyRepository{db: db}
+}
+
+func (r *GormApiKeyRepository) CreateApiClient(ctx context.Context, client *domain.ApiClient) (*domain.ApiClient, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	client.Id = uuid.New().String()
+	client.Audit = port.NewAudit(ctx)
+	if err := conn(ctx, r.db).Create(client).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return client, nil
+}
+
+func (r *GormApiKeyRepository) GetApiClientById(ctx context.Context, id string) (*domain.ApiClient, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var client domain.ApiClient
+	if err := conn(ctx, r.db).Where("client_id = ?", id).First(&client).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &client, nil
+}
+
+func (r *GormApiKeyRepository) ListApiClients(ctx context.Context) ([]domain.ApiClient, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	clients := []domain.ApiClient{}
+	if err := conn(ctx, r.db).Order("name").Find(&clients).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return clients, nil
+}
+
+func (r *GormApiKeyRepository) CreateApiKey(ctx context.Context, key *domain.ApiKey) (*domain.ApiKey, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	key.Id = uuid.New().String()
+	key.Audit = port.NewAudit(ctx)
+	if err := conn(ctx, r.db).Create(key).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return key, nil
+}
+
+func (r *GormApiKeyRepository) GetApiKeyById(ctx context.Context, id string) (*domain.ApiKey, error) {
+	return r.getApiKey(ctx, "key_id = ?", id)
+}
+
+func (r *GormApiKeyRepository) GetApiKeyByHash(ctx context.Context, keyHash string) (*domain.ApiKey, error) {
+	return r.getApiKey(ctx, "key_hash = ?", keyHash)
+}
+
+func (r *GormApiKeyRepository) getApiKey(ctx context.Context, query string, value string) (*domain.ApiKey, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var key domain.ApiKey
+	if err := conn(ctx, r.db).Where(query, value).First(&key).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &key, nil
+}
+
+func (r *GormApiKeyRepository) ListApiKeys(ctx context.Context, clientId string) ([]domain.ApiKey, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	keys := []domain.ApiKey{}
+	if err := conn(ctx, r.db).Where("client_id = ?", clientId).Order("created_at, key_id").Find(&keys).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return keys, nil
+}
+
+func (r *GormApiKeyRepository) ExpireApiKey(ctx context.Context, id string, expiresAt time.Time) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.GetApiKeyById(ctx, id); err != nil {
+		return err
+	}
+	change := port.UpdateAudit(ctx)
+	err := conn(ctx, r.db).Model(&domain.ApiKey{}).
+		Where("key_id = ? AND expires_at > ?", id, expiresAt.UTC()).
+		Updates(map[string]any{
+			"expires_at": expiresAt.UTC(),
+			"updated_at": change.UpdatedAt,
+			"updated_by": change.UpdatedBy,
+		}).Error
+	return translateError(err)
+}
+
+func (r *GormApiKeyRepository) RevokeApiKey(ctx context.Context, id string, revokedAt time.Time) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	change := port.UpdateAudit(ctx)
+	result := conn(ctx, r.db).Model(&domain.ApiKey{}).
+		Where("key_id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]any{
+			"revoked_at": revokedAt.UTC(),
+			"updated_at": change.UpdatedAt,
+			"updated_by": change.UpdatedBy,
+		})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormApiKeyRepository) TouchApiKey(ctx context.Context, id string, usedAt time.Time) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result := conn(ctx, r.db).Model(&domain.ApiKey{}).Where("key_id = ?", id).Update("last_used_at", usedAt.UTC())
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
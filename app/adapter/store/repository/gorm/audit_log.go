@@ -0,0 +1,66 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"gorm.io/gorm"
+)
+
+// auditLogSortColumns whitelists the fields ListAuditLog can be sorted by
+var auditLogSortColumns = map[string]string{
+	"entry_id":    "entry_id",
+	"occurred_at": "occurred_at",
+	"actor":       "actor",
+	"action":      "action",
+	"entity_type": "entity_type",
+}
+
+// GormAuditLogRepository stores the audit log in a table that triggers keep append-only
+type GormAuditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewGormAuditLogRepository(db *gorm.DB) *GormAuditLogRepository {
+	return &GormAuditLogRepository{db: db}
+}
+
+func (r *GormAuditLogRepository) Append(ctx context.Context, entry *domain.AuditLogEntry) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	entry.Id = uuid.New().String()
+	return translateError(conn(ctx, r.db).Create(entry).Error)
+}
+
+func (r *GormAuditLogRepository) ListAuditLog(ctx context.Context, filter port.AuditLogFilter, query port.ListQuery) (*port.Page[domain.AuditLogEntry], error) {
+	tx := conn(ctx, r.db).Model(&domain.AuditLogEntry{})
+	if filter.Actor != "" {
+		tx = tx.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		tx = tx.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		tx = tx.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityId != "" {
+		tx = tx.Where("entity_id = ?", filter.EntityId)
+	}
+	if filter.From != nil {
+		tx = tx.Where("occurred_at >= ?", filter.From.UTC())
+	}
+	if filter.To != nil {
+		tx = tx.Where("occurred_at <= ?", filter.To.UTC())
+	}
+
+	var entries []domain.AuditLogEntry
+	total, query, err := findPage(tx, query, auditLogSortColumns, "entry_id", &entries)
+	if err != nil {
+		return nil, err
+	}
+	return port.NewPage(entries, total, query), nil
+}
@@ -6,7 +6,10 @@ import (
 	"reflect"
 
 	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BaseGormRepository provides a base implementation for GORM repositories
@@ -26,7 +29,7 @@ func (r *BaseGormRepository) Create(ctx context.Context, entity any) error {
 	ctx, cancel := common.ContextWithTimeout(ctx)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Create(entity).Error
+	return translateError(conn(ctx, r.db).Create(entity).Error)
 }
 
 // FindByID finds an entity by its ID
@@ -34,12 +37,9 @@ func (r *BaseGormRepository) FindByID(ctx context.Context, id string, result any
 	ctx, cancel := common.ContextWithTimeout(ctx)
 	defer cancel()
 
-	err := r.db.WithContext(ctx).First(result, "id = ?", id).Error
+	err := conn(ctx, r.db).First(result, "id = ?", id).Error
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("entity not found")
-		}
-		return err
+		return translateError(err)
 	}
 	return nil
 }
@@ -55,7 +55,7 @@ func (r *BaseGormRepository) FindAll(ctx context.Context, conditions any, result
 		return errors.New("results parameter must be a pointer to a slice")
 	}
 
-	return r.db.WithContext(ctx).Where(conditions).Find(results).Error
+	return conn(ctx, r.db).Where(conditions).Find(results).Error
 }
 
 // Update updates an entity by its ID
@@ -63,7 +63,7 @@ func (r *BaseGormRepository) Update(ctx context.Context, id string, entity any)
 	ctx, cancel := common.ContextWithTimeout(ctx)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Model(entity).Where("id = ?", id).Updates(entity).Error
+	return conn(ctx, r.db).Model(entity).Where("id = ?", id).Updates(entity).Error
 }
 
 // Delete removes an entity by its ID
@@ -76,3 +76,106 @@ func (r *BaseGormRepository) Delete(ctx context.Context, id string) error {
 	// In the specific repositories, you'll need to provide the correct entity type
 	return errors.New("delete operation must be implemented in specific repositories")
 }
+
+// findPage counts the rows matched by tx and loads one ordered page of them into dest.
+// columns whitelists the sortable fields; tieBreaker keeps the ordering stable between pages.
+func findPage(tx *gorm.DB, query port.ListQuery, columns map[string]string, tieBreaker string, dest any) (int64, port.ListQuery, error) {
+	query, err := query.Normalize()
+	if err != nil {
+		return 0, query, err
+	}
+
+	order, err := query.SortColumns(columns, tieBreaker)
+	if err != nil {
+		return 0, query, err
+	}
+
+	tx = tx.Session(&gorm.Session{})
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return 0, query, err
+	}
+
+	for _, field := range order {
+		tx = tx.Order(clause.OrderByColumn{Column: clause.Column{Name: field.Field}, Desc: field.Desc})
+	}
+
+	if err := tx.Limit(query.Limit).Offset(query.Offset).Find(dest).Error; err != nil {
+		return 0, query, err
+	}
+	return total, query, nil
+}
+
+// updateVersioned writes the non-zero fields of changes over current in a single statement that only matches
+// while the row is still at version. changes must already carry the next version, so a writer that read the
+// same version and lost the race updates no row and gets ErrVersionConflict.
+func updateVersioned(tx *gorm.DB, current any, version int64, changes any) error {
+	result := tx.Model(current).Where("version = ?", version).Updates(changes)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrVersionConflict
+	}
+	return nil
+}
+
+// notDeleted is the condition that hides soft-deleted rows from the regular queries
+const notDeleted = "deleted_at IS NULL"
+
+// live is conn restricted to the rows that have not been soft-deleted.
+// The result is a fresh session, so it can be reused for several statements without their conditions mixing.
+func live(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return conn(ctx, db).Where(notDeleted).Session(&gorm.Session{})
+}
+
+// softDelete marks the live row of model whose idColumn equals id as deleted by the actor of ctx and bumps its
+// version so that concurrent writers holding the old version fail. Deleting a row that does not exist or is
+// already deleted is a no-op, like the hard delete it replaces.
+func softDelete(ctx context.Context, db *gorm.DB, model any, idColumn string, id string) error {
+	change := port.UpdateAudit(ctx)
+	return live(ctx, db).Model(model).
+		Where(clause.Eq{Column: clause.Column{Name: idColumn}, Value: id}).
+		Updates(map[string]any{
+			"deleted_at": change.UpdatedAt,
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": change.UpdatedAt,
+			"updated_by": change.UpdatedBy,
+		}).Error
+}
+
+// whereAudit narrows tx to the rows whose audit stamps pass filter
+func whereAudit(tx *gorm.DB, filter port.AuditFilter) *gorm.DB {
+	if filter.CreatedFrom != nil {
+		tx = tx.Where("created_at >= ?", filter.CreatedFrom.UTC())
+	}
+	if filter.CreatedTo != nil {
+		tx = tx.Where("created_at <= ?", filter.CreatedTo.UTC())
+	}
+	if filter.UpdatedFrom != nil {
+		tx = tx.Where("updated_at >= ?", filter.UpdatedFrom.UTC())
+	}
+	if filter.UpdatedTo != nil {
+		tx = tx.Where("updated_at <= ?", filter.UpdatedTo.UTC())
+	}
+	if filter.CreatedBy != "" {
+		tx = tx.Where("created_by = ?", filter.CreatedBy)
+	}
+	if filter.UpdatedBy != "" {
+		tx = tx.Where("updated_by = ?", filter.UpdatedBy)
+	}
+	return tx
+}
+
+// translateError maps GORM errors onto the domain errors expected by the services
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return domain.ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return domain.ErrAlreadyExists
+	default:
+		return err
+	}
+}
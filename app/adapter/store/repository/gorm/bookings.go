@@ -5,9 +5,22 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"gorm.io/gorm"
 )
 
+// bookingSortColumns whitelists the fields ListBookings can be sorted by
+var bookingSortColumns = map[string]string{
+	"booking_id":  "booking_id",
+	"user_id":     "user_id",
+	"round_id":    "round_id",
+	"seat_number": "seat_number",
+	"price":       "price",
+	"status":      "status",
+	"created_at":  "created_at",
+	"updated_at":  "updated_at",
+}
+
 type GormBookingRepository struct {
 	db *gorm.DB
 }
@@ -19,24 +32,26 @@ func NewGormBookingRepository(db *gorm.DB) *GormBookingRepository {
 func (r *GormBookingRepository) CreateBooking(context context.Context, booking *domain.Bookings) (*domain.Bookings, error) {
 	// Generate UUID for new booking
 	booking.Id = uuid.New().String()
+	booking.Version = 1
+	booking.Audit = port.NewAudit(context)
 
-	if err := r.db.WithContext(context).Create(booking).Error; err != nil {
-		return nil, err
+	if err := conn(context, r.db).Create(booking).Error; err != nil {
+		return nil, translateError(err)
 	}
 	return booking, nil
 }
 
 func (r *GormBookingRepository) GetBookingById(context context.Context, id string) (*domain.Bookings, error) {
 	var booking domain.Bookings
-	if err := r.db.WithContext(context).Where("booking_id = ?", id).First(&booking).Error; err != nil {
-		return nil, err
+	if err := live(context, r.db).Where("booking_id = ?", id).First(&booking).Error; err != nil {
+		return nil, translateError(err)
 	}
 	return &booking, nil
 }
 
 func (r *GormBookingRepository) GetBookingsByUserId(context context.Context, userId string) ([]domain.Bookings, error) {
 	var bookings []domain.Bookings
-	if err := r.db.WithContext(context).Where("user_id = ?", userId).Find(&bookings).Error; err != nil {
+	if err := live(context, r.db).Where("user_id = ?", userId).Find(&bookings).Error; err != nil {
 		return nil, err
 	}
 	return bookings, nil
@@ -44,19 +59,46 @@ func (r *GormBookingRepository) GetBookingsByUserId(context context.Context, use
 
 func (r *GormBookingRepository) GetBookingsByRoundId(context context.Context, roundId string) ([]domain.Bookings, error) {
 	var bookings []domain.Bookings
-	if err := r.db.WithContext(context).Where("round_id = ?", roundId).Find(&bookings).Error; err != nil {
+	if err := live(context, r.db).Where("round_id = ?", roundId).Find(&bookings).Error; err != nil {
 		return nil, err
 	}
 	return bookings, nil
 }
 
+func (r *GormBookingRepository) ListBookings(ctx context.Context, filter port.BookingFilter, query port.ListQuery) (*port.Page[domain.Bookings], error) {
+	tx := live(ctx, r.db).Model(&domain.Bookings{})
+	if filter.UserId != "" {
+		tx = tx.Where("user_id = ?", filter.UserId)
+	}
+	if filter.RoundId != "" {
+		tx = tx.Where("round_id = ?", filter.RoundId)
+	}
+	if filter.Status != "" {
+		tx = tx.Where("status = ?", filter.Status)
+	}
+	tx = whereAudit(tx, filter.Audit)
+
+	var bookings []domain.Bookings
+	total, query, err := findPage(tx, query, bookingSortColumns, "booking_id", &bookings)
+	if err != nil {
+		return nil, err
+	}
+	return port.NewPage(bookings, total, query), nil
+}
+
 func (r *GormBookingRepository) UpdateBooking(ctx context.Context, id string, booking *domain.Bookings) (*domain.Bookings, error) {
 	existingBooking, err := r.GetBookingById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := r.db.WithContext(ctx).Model(existingBooking).Updates(booking).Error; err != nil {
+	if err := domain.CheckVersion(booking.Version, existingBooking.Version); err != nil {
+		return nil, err
+	}
+
+	booking.Version = existingBooking.Version + 1
+	booking.Audit = port.UpdateAudit(ctx)
+	if err := updateVersioned(live(ctx, r.db), existingBooking, existingBooking.Version, booking); err != nil {
 		return nil, err
 	}
 
@@ -64,5 +106,5 @@ func (r *GormBookingRepository) UpdateBooking(ctx context.Context, id string, bo
 }
 
 func (r *GormBookingRepository) DeleteBooking(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Where("booking_id = ?", id).Delete(&domain.Bookings{}).Error
+	return softDelete(ctx, r.db, &domain.Bookings{}, "booking_id", id)
 }
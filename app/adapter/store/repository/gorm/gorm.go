@@ -1,11 +1,12 @@
 package gorm
 
 import (
+	"context"
 	"fmt"
 	"log"
 
+	"github.com/glebarez/sqlite"
 	"github.com/khunmostz/be-liongate-go/app/adapter/config"
-	"github.com/khunmostz/be-liongate-go/app/core/domain"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -13,6 +14,50 @@ import (
 
 // InitPostgresDB initializes and returns a GORM DB instance for PostgreSQL
 func InitPostgresDB(cfg *config.Config) *gorm.DB {
+	db, err := OpenPostgresDB(cfg)
+	if err != nil {
+		log.Fatal("Failed to connect to PostgreSQL:", err)
+	}
+
+	log.Println("Successfully connected to PostgreSQL!")
+
+	if err := prepareSchema(db, cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	return db
+}
+
+// InitSQLiteDB initializes and returns a GORM DB instance for SQLite
+func InitSQLiteDB(cfg *config.Config) *gorm.DB {
+	db, err := OpenSQLiteDB(cfg)
+	if err != nil {
+		log.Fatal("Failed to open SQLite database:", err)
+	}
+
+	log.Printf("Successfully opened SQLite database %s!", cfg.SQLite.Path)
+
+	if err := prepareSchema(db, cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	return db
+}
+
+// OpenDB connects to the configured SQL database without touching its schema
+func OpenDB(cfg *config.Config) (*gorm.DB, error) {
+	switch {
+	case cfg.IsPostgres():
+		return OpenPostgresDB(cfg)
+	case cfg.IsSQLite():
+		return OpenSQLiteDB(cfg)
+	default:
+		return nil, fmt.Errorf("%s is not a SQL database", cfg.Database.DbType)
+	}
+}
+
+// OpenPostgresDB connects to PostgreSQL and configures the connection pool
+func OpenPostgresDB(cfg *config.Config) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
 		cfg.Database.Host,
 		cfg.Database.Username,
@@ -26,43 +71,66 @@ func InitPostgresDB(cfg *config.Config) *gorm.DB {
 	// Configure GORM with improved settings
 	gormConfig := &gorm.Config{
 		SkipDefaultTransaction: true,
+		TranslateError:         true,
 		Logger:                 logger.Default.LogMode(logger.Info),
 	}
 
 	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
 	if err != nil {
-		log.Fatal("Failed to connect to PostgreSQL:", err)
-	}
-
-	log.Println("Successfully connected to PostgreSQL!")
-
-	// Auto migrate the models in the correct order to avoid foreign key issues
-	// First migrate tables without foreign keys, then tables with foreign keys
-	if err := db.AutoMigrate(
-		&domain.Users{},
-		&domain.Animals{},
-		&domain.PerformanceStage{},
-	); err != nil {
-		log.Fatal("Failed to auto migrate base models:", err)
-	}
-
-	// Then migrate tables with foreign keys
-	if err := db.AutoMigrate(
-		&domain.ShowRounds{},
-		&domain.Bookings{},
-	); err != nil {
-		log.Fatal("Failed to auto migrate models with foreign keys:", err)
+		return nil, err
 	}
 
 	// Get the underlying SQL DB to configure connection pool
 	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatal("Failed to get SQL DB:", err)
+		return nil, err
 	}
 
 	// Set connection pool settings
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetMaxOpenConns(100)
 
-	return db
+	return db, nil
+}
+
+// OpenSQLiteDB opens the configured SQLite database file
+func OpenSQLiteDB(cfg *config.Config) (*gorm.DB, error) {
+	return openSQLite(cfg.SQLite.Path, logger.Default.LogMode(logger.Info))
+}
+
+// openSQLite opens the SQLite database at path.
+// The driver is pure Go, so the binary still builds with CGO_ENABLED=0.
+func openSQLite(path string, gormLogger logger.Interface) (*gorm.DB, error) {
+	// Wait for competing writers instead of failing with "database is locked",
+	// and enforce foreign keys the way PostgreSQL does
+	dsn := path + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		SkipDefaultTransaction: true,
+		TranslateError:         true,
+		Logger:                 gormLogger,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer at a time; one connection also keeps ":memory:" databases alive
+	sqlDB.SetMaxOpenConns(1)
+
+	return db, nil
+}
+
+// prepareSchema applies pending migrations when DB_MIGRATE_ON_START is set,
+// otherwise it refuses to continue until the schema has been migrated
+func prepareSchema(db *gorm.DB, cfg *config.Config) error {
+	migrator := NewMigrator(db)
+	if cfg.Database.MigrateOnStart {
+		return migrator.Up(context.Background())
+	}
+	return migrator.EnsureCurrent(context.Background())
 }
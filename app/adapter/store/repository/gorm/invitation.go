@@ -0,0 +1,64 @@
+package gorm
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"gorm.io/gorm"
+)
+
+// GormInvitationRepository stores invitations; accepting one is a conditional update so that it succeeds only once
+type GormInvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewGormInvitationRepository(db *gorm.DB) *GormInvitationRepository {
+	return &GormInvitationRepository{db: db}
+}
+
+func (r *GormInvitationRepository) CreateInvitation(ctx context.Context, invitation *domain.Invitation) (*domain.Invitation, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	invitation.Id = uuid.New().String()
+	invitation.Audit = port.NewAudit(ctx)
+	if err := conn(ctx, r.db).Create(invitation).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return invitation, nil
+}
+
+func (r *GormInvitationRepository) GetInvitationById(ctx context.Context, id string) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	if err := conn(ctx, r.db).Where("invitation_id = ?", id).First(&invitation).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &invitation, nil
+}
+
+func (r *GormInvitationRepository) AcceptInvitation(ctx context.Context, id string, userId string, acceptedAt time.Time) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	change := port.UpdateAudit(ctx)
+	// The accepted_at condition makes the update the arbiter between concurrent accepts
+	result := conn(ctx, r.db).Model(&domain.Invitation{}).
+		Where("invitation_id = ? AND accepted_at IS NULL", id).
+		Updates(map[string]any{
+			"accepted_at": acceptedAt.UTC(),
+			"accepted_by": userId,
+			"updated_at":  change.UpdatedAt,
+			"updated_by":  change.UpdatedBy,
+		})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
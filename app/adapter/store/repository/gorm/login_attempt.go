@@ -0,0 +1,94 @@
+package gorm
+
+import (
+	"context"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormLoginAttemptRepository keeps the failed login counters in a table, counting failures with an upsert so that
+// concurrent logins on several replicas never lose one
+type GormLoginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewGormLoginAttemptRepository(db *gorm.DB) *GormLoginAttemptRepository {
+	return &GormLoginAttemptRepository{db: db}
+}
+
+func (r *GormLoginAttemptRepository) GetLoginAttempts(ctx context.Context, keys []string) ([]domain.LoginAttempt, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	attempts := []domain.LoginAttempt{}
+	if err := conn(ctx, r.db).Where("attempt_key IN ?", keys).Find(&attempts).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return attempts, nil
+}
+
+func (r *GormLoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, at time.Time, resetBefore time.Time) (*domain.LoginAttempt, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	at = at.UTC()
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "attempt_key"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"failures":        gorm.Expr("CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END", resetBefore.UTC()),
+			"last_failure_at": at,
+		}),
+	}).Create(&domain.LoginAttempt{Key: key, Failures: 1, LastFailureAt: at}).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var attempt domain.LoginAttempt
+	if err := conn(ctx, r.db).Where("attempt_key = ?", key).First(&attempt).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &attempt, nil
+}
+
+func (r *GormLoginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result := conn(ctx, r.db).Model(&domain.LoginAttempt{}).Where("attempt_key = ?", key).Update("locked_until", until.UTC())
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormLoginAttemptRepository) ClearLoginAttempts(ctx context.Context, key string) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result := conn(ctx, r.db).Where("attempt_key = ?", key).Delete(&domain.LoginAttempt{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormLoginAttemptRepository) ListLoginLockouts(ctx context.Context, now time.Time) ([]domain.LoginAttempt, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	attempts := []domain.LoginAttempt{}
+	if err := conn(ctx, r.db).Where("locked_until > ?", now.UTC()).Order("attempt_key").Find(&attempts).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return attempts, nil
+}
@@ -0,0 +1,166 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mfaRecoveryCode is a row of the recovery codes of an enrollment. Each code has a row of its own,
+// so that using one is a single conditional delete.
+type mfaRecoveryCode struct {
+	UserId   string `gorm:"primaryKey;column:user_id;type:string"`
+	CodeHash string `gorm:"primaryKey;column:code_hash;type:string"`
+}
+
+func (mfaRecoveryCode) TableName() string { return "mfa_recovery_codes" }
+
+// GormMfaRepository stores enrollments, their recovery codes and the roles requiring MFA in three tables.
+// Accepting a code is a conditional update or delete, so that concurrent logins cannot use the same code twice.
+type GormMfaRepository struct {
+	db *gorm.DB
+}
+
+func NewGormMfaRepository(db *gorm.DB) *GormMfaRepository {
+	return &GormMfaRepository{db: db}
+}
+
+func (r *GormMfaRepository) GetMfaEnrollment(ctx context.Context, userId string) (*domain.MfaEnrollment, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var enrollment domain.MfaEnrollment
+	if err := conn(ctx, r.db).Where("user_id = ?", userId).First(&enrollment).Error; err != nil {
+		return nil, translateError(err)
+	}
+	enrollment.RecoveryCodes = []string{}
+	err := conn(ctx, r.db).Model(&mfaRecoveryCode{}).Where("user_id = ?", userId).Order("code_hash").
+		Pluck("code_hash", &enrollment.RecoveryCodes).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &enrollment, nil
+}
+
+func (r *GormMfaRepository) SaveMfaEnrollment(ctx context.Context, enrollment *domain.MfaEnrollment) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	enrollment.Audit = port.NewAudit(ctx)
+	return translateError(conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"secret", "confirmed_at", "last_step", "updated_at", "updated_by"}),
+		}).Create(enrollment).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", enrollment.UserId).Delete(&mfaRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(enrollment.RecoveryCodes) == 0 {
+			return nil
+		}
+		codes := make([]mfaRecoveryCode, len(enrollment.RecoveryCodes))
+		for i, hash := range enrollment.RecoveryCodes {
+			codes[i] = mfaRecoveryCode{UserId: enrollment.UserId, CodeHash: hash}
+		}
+		return tx.Create(&codes).Error
+	}))
+}
+
+func (r *GormMfaRepository) DeleteMfaEnrollment(ctx context.Context, userId string) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	return translateError(conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&mfaRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("user_id = ?", userId).Delete(&domain.MfaEnrollment{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+		return nil
+	}))
+}
+
+func (r *GormMfaRepository) UseMfaStep(ctx context.Context, userId string, step int64) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	// The last_step condition makes the update the arbiter between concurrent logins with the same code
+	result := conn(ctx, r.db).Model(&domain.MfaEnrollment{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL AND last_step < ?", userId, step).
+		Update("last_step", step)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormMfaRepository) UseRecoveryCode(ctx context.Context, userId string, codeHash string) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result := conn(ctx, r.db).Where("user_id = ? AND code_hash = ?", userId, codeHash).Delete(&mfaRecoveryCode{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormMfaRepository) GetMfaRequirement(ctx context.Context, role string) (*domain.MfaRequirement, error) {
+	var requirement domain.MfaRequirement
+	if err := conn(ctx, r.db).Where("role = ?", role).First(&requirement).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &requirement, nil
+}
+
+func (r *GormMfaRepository) ListMfaRequirements(ctx context.Context) ([]domain.MfaRequirement, error) {
+	requirements := []domain.MfaRequirement{}
+	if err := conn(ctx, r.db).Order("role").Find(&requirements).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return requirements, nil
+}
+
+func (r *GormMfaRepository) SaveMfaRequirement(ctx context.Context, requirement *domain.MfaRequirement) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	requirement.Audit = port.NewAudit(ctx)
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(requirement).Error
+	if err != nil {
+		return translateError(err)
+	}
+	return translateError(conn(ctx, r.db).Where("role = ?", requirement.Role).First(requirement).Error)
+}
+
+func (r *GormMfaRepository) DeleteMfaRequirement(ctx context.Context, role string) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result := conn(ctx, r.db).Where("role = ?", role).Delete(&domain.MfaRequirement{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,707 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// migrations lists every schema change in version order. Released migrations must never be edited;
+// add a new one instead. Each migration works on its own frozen copies of the models so that later
+// changes to the domain structs cannot alter what an old migration does.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create users, animals, performance stages, show rounds and bookings",
+		Up: func(tx *gorm.DB) error {
+			// Owners first so the bookings foreign keys can reference them.
+			// On databases created by the former AutoMigrate this is a no-op.
+			return tx.AutoMigrate(&v1User{}, &v1Animal{}, &v1PerformanceStage{}, &v1ShowRound{}, &v1Booking{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v1Booking{}, &v1ShowRound{}, &v1PerformanceStage{}, &v1Animal{}, &v1User{})
+		},
+	},
+	{
+		Version:     2,
+		Description: "add booking status",
+		Up: func(tx *gorm.DB) error {
+			migrator := tx.Migrator()
+			// Existing rows pick up the column default, so every old booking becomes confirmed
+			if !migrator.HasColumn(&v2Booking{}, "Status") {
+				if err := migrator.AddColumn(&v2Booking{}, "Status"); err != nil {
+					return err
+				}
+			}
+			if !migrator.HasIndex(&v2Booking{}, "Status") {
+				return migrator.CreateIndex(&v2Booking{}, "Status")
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			migrator := tx.Migrator()
+			if err := migrator.DropIndex(&v2Booking{}, "Status"); err != nil {
+				return err
+			}
+			return migrator.DropColumn(&v2Booking{}, "Status")
+		},
+	},
+	{
+		Version:     3,
+		Description: "add version columns for optimistic concurrency",
+		Up: func(tx *gorm.DB) error {
+			// Existing rows pick up the column default and start at version 1, like new rows
+			for _, table := range v3VersionedTables {
+				migrator := tx.Table(table).Migrator()
+				if !migrator.HasColumn(&v3Version{}, "Version") {
+					if err := migrator.AddColumn(&v3Version{}, "Version"); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			// A plain ALTER TABLE, because GORM's SQLite migrator rebuilds the table to drop a column and loses its indexes
+			for _, table := range v3VersionedTables {
+				if err := tx.Exec("ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: table}, clause.Column{Name: "version"}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     4,
+		Description: "add deleted_at columns for soft delete",
+		Up: func(tx *gorm.DB) error {
+			// Existing rows get NULL and stay live
+			for _, table := range v4SoftDeletedTables {
+				migrator := tx.Table(table).Migrator()
+				if !migrator.HasColumn(&v4SoftDelete{}, "DeletedAt") {
+					if err := migrator.AddColumn(&v4SoftDelete{}, "DeletedAt"); err != nil {
+						return err
+					}
+				}
+				if err := tx.Exec("CREATE INDEX IF NOT EXISTS ? ON ? (?)", clause.Column{Name: "idx_" + table + "_deleted_at"}, clause.Table{Name: table}, clause.Column{Name: "deleted_at"}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, table := range v4SoftDeletedTables {
+				if err := tx.Exec("DROP INDEX IF EXISTS ?", clause.Column{Name: "idx_" + table + "_deleted_at"}).Error; err != nil {
+					return err
+				}
+				if err := tx.Exec("ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: table}, clause.Column{Name: "deleted_at"}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     5,
+		Description: "add created and updated audit columns",
+		Up: func(tx *gorm.DB) error {
+			// Who made existing rows is unknown, so they keep an empty actor and are stamped with the time of the migration
+			now := time.Now().UTC().Truncate(time.Millisecond)
+			for _, table := range v5AuditedTables {
+				migrator := tx.Table(table).Migrator()
+				for _, field := range []string{"CreatedAt", "UpdatedAt", "CreatedBy", "UpdatedBy"} {
+					if !migrator.HasColumn(&v5Audit{}, field) {
+						if err := migrator.AddColumn(&v5Audit{}, field); err != nil {
+							return err
+						}
+					}
+				}
+				for _, column := range v5IndexedColumns {
+					if err := tx.Exec("UPDATE ? SET ? = ? WHERE ? IS NULL", clause.Table{Name: table}, clause.Column{Name: column}, now, clause.Column{Name: column}).Error; err != nil {
+						return err
+					}
+					if err := tx.Exec("CREATE INDEX IF NOT EXISTS ? ON ? (?)", clause.Column{Name: "idx_" + table + "_" + column}, clause.Table{Name: table}, clause.Column{Name: column}).Error; err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, table := range v5AuditedTables {
+				for _, column := range v5IndexedColumns {
+					if err := tx.Exec("DROP INDEX IF EXISTS ?", clause.Column{Name: "idx_" + table + "_" + column}).Error; err != nil {
+						return err
+					}
+				}
+				for _, column := range []string{"created_at", "updated_at", "created_by", "updated_by"} {
+					if err := tx.Exec("ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: table}, clause.Column{Name: column}).Error; err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     6,
+		Description: "create the append-only audit log",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&v6AuditLogEntry{}); err != nil {
+				return err
+			}
+			for _, statement := range v6AppendOnlyTriggers(tx.Dialector.Name()) {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, statement := range v6DropTriggers(tx.Dialector.Name()) {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropTable(&v6AuditLogEntry{})
+		},
+	},
+	{
+		Version:     7,
+		Description: "create invitations",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v7Invitation{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v7Invitation{})
+		},
+	},
+	{
+		Version:     8,
+		Description: "create login attempts",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v8LoginAttempt{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v8LoginAttempt{})
+		},
+	},
+	{
+		Version:     9,
+		Description: "create mfa enrollments, recovery codes and requirements",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v9MfaEnrollment{}, &v9MfaRecoveryCode{}, &v9MfaRequirement{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v9MfaRequirement{}, &v9MfaRecoveryCode{}, &v9MfaEnrollment{})
+		},
+	},
+	{
+		Version:     10,
+		Description: "add user email, create password reset tokens and session revocations",
+		Up: func(tx *gorm.DB) error {
+			// Existing users pick up the empty default and have no address to send a reset link to
+			if migrator := tx.Migrator(); !migrator.HasColumn(&v10User{}, "Email") {
+				if err := migrator.AddColumn(&v10User{}, "Email"); err != nil {
+					return err
+				}
+			}
+			return tx.AutoMigrate(&v10PasswordResetToken{}, &v10SessionRevocation{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&v10SessionRevocation{}, &v10PasswordResetToken{}); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&v10User{}, "Email")
+		},
+	},
+	{
+		Version:     11,
+		Description: "add user profiles, unique emails and phones, and contact verifications",
+		Up: func(tx *gorm.DB) error {
+			migrator := tx.Migrator()
+			for _, field := range v11UserFields {
+				if !migrator.HasColumn(&v11User{}, field) {
+					if err := migrator.AddColumn(&v11User{}, field); err != nil {
+						return err
+					}
+				}
+			}
+			// Emails are compared in the normalized form the services store them in from now on; the partial
+			// indexes leave the users without an email or phone, which store an empty string, out
+			if err := checkDuplicateEmails(tx); err != nil {
+				return err
+			}
+			statements := []string{
+				"UPDATE users SET email = LOWER(TRIM(email)) WHERE email <> ''",
+				"CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email) WHERE email <> ''",
+				"CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone ON users (phone) WHERE phone <> ''",
+			}
+			for _, statement := range statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			return tx.AutoMigrate(&v11ContactVerification{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&v11ContactVerification{}); err != nil {
+				return err
+			}
+			for _, statement := range []string{"DROP INDEX IF EXISTS idx_users_phone", "DROP INDEX IF EXISTS idx_users_email"} {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			for _, field := range v11UserFields {
+				if err := tx.Migrator().DropColumn(&v11User{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     12,
+		Description: "create signing keys",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v12SigningKey{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v12SigningKey{})
+		},
+	},
+	{
+		Version:     13,
+		Description: "create revoked tokens",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v13RevokedToken{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v13RevokedToken{})
+		},
+	},
+	{
+		Version:     14,
+		Description: "create api clients and api keys",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v14ApiClient{}, &v14ApiKey{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v14ApiKey{}, &v14ApiClient{})
+		},
+	},
+	{
+		Version:     15,
+		Description: "create oidc logins and identities",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v15OidcLogin{}, &v15OidcIdentity{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v15OidcIdentity{}, &v15OidcLogin{})
+		},
+	},
+	{
+		Version:     16,
+		Description: "make a seat of a show round bookable only once",
+		Up: func(tx *gorm.DB) error {
+			// Only live bookings that still hold their seat take part, like in the seat check of the booking service.
+			// The index settles two bookings racing for the same seat, which the check alone cannot.
+			if err := checkDuplicateSeats(tx); err != nil {
+				return err
+			}
+			return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_bookings_round_seat ON bookings (round_id, seat_number) " +
+				"WHERE deleted_at IS NULL AND status NOT IN ('cancelled', 'refunded')").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Exec("DROP INDEX IF EXISTS idx_bookings_round_seat").Error
+		},
+	},
+}
+
+type v1User struct {
+	Id       string      `gorm:"primaryKey;column:user_id;type:string"`
+	Username string      `gorm:"column:username;unique"`
+	Password string      `gorm:"column:password"`
+	Role     string      `gorm:"column:role;default:user"`
+	Bookings []v1Booking `gorm:"foreignKey:UserId;references:Id"`
+}
+
+func (v1User) TableName() string { return "users" }
+
+type v1Animal struct {
+	Id           string `gorm:"primaryKey;column:animal_id;type:string"`
+	Name         string `gorm:"column:name"`
+	Species      string `gorm:"column:species"`
+	Type         string `gorm:"column:type"`
+	ShowDuration int    `gorm:"column:show_duration"`
+}
+
+func (v1Animal) TableName() string { return "animals" }
+
+type v1PerformanceStage struct {
+	Id           string  `gorm:"primaryKey;column:stage_id;type:string"`
+	RoomNumber   string  `gorm:"column:room_number"`
+	SeatCapacity int     `gorm:"column:seat_capacity"`
+	PricePerSeat float64 `gorm:"column:price_per_seat"`
+}
+
+func (v1PerformanceStage) TableName() string { return "performance_stages" }
+
+type v1ShowRound struct {
+	Id       string      `gorm:"primaryKey;column:round_id;type:string"`
+	AnimalId string      `gorm:"column:animal_id;type:string"`
+	StageId  string      `gorm:"column:stage_id;type:string"`
+	ShowTime string      `gorm:"column:show_time;type:timestamp"`
+	Bookings []v1Booking `gorm:"foreignKey:RoundId;references:Id"`
+}
+
+func (v1ShowRound) TableName() string { return "show_rounds" }
+
+type v1Booking struct {
+	Id         string  `gorm:"primaryKey;column:booking_id;type:string"`
+	UserId     string  `gorm:"column:user_id;type:string"`
+	RoundId    string  `gorm:"column:round_id;type:string"`
+	SeatNumber int     `gorm:"column:seat_number"`
+	Price      float64 `gorm:"column:price"`
+	QrCode     string  `gorm:"column:qr_code"`
+}
+
+func (v1Booking) TableName() string { return "bookings" }
+
+type v2Booking struct {
+	Status string `gorm:"column:status;default:confirmed;index"`
+}
+
+func (v2Booking) TableName() string { return "bookings" }
+
+var v3VersionedTables = []string{"users", "animals", "performance_stages", "show_rounds", "bookings"}
+
+type v3Version struct {
+	Version int64 `gorm:"column:version;not null;default:1"`
+}
+
+var v4SoftDeletedTables = []string{"users", "animals", "performance_stages", "show_rounds", "bookings"}
+
+type v4SoftDelete struct {
+	DeletedAt *time.Time `gorm:"column:deleted_at"`
+}
+
+var v5AuditedTables = []string{"users", "animals", "performance_stages", "show_rounds", "bookings"}
+
+// v5IndexedColumns are the audit columns lists filter and sort on
+var v5IndexedColumns = []string{"created_at", "updated_at"}
+
+type v5Audit struct {
+	CreatedAt *time.Time `gorm:"column:created_at"`
+	UpdatedAt *time.Time `gorm:"column:updated_at"`
+	CreatedBy string     `gorm:"column:created_by;not null;default:''"`
+	UpdatedBy string     `gorm:"column:updated_by;not null;default:''"`
+}
+
+type v6AuditLogEntry struct {
+	Id         string         `gorm:"primaryKey;column:entry_id;type:string"`
+	OccurredAt time.Time      `gorm:"column:occurred_at;index"`
+	Actor      string         `gorm:"column:actor;index"`
+	Action     string         `gorm:"column:action;index"`
+	EntityType string         `gorm:"column:entity_type;index:idx_audit_log_entries_entity"`
+	EntityId   string         `gorm:"column:entity_id;index:idx_audit_log_entries_entity"`
+	Before     map[string]any `gorm:"column:before;serializer:json"`
+	After      map[string]any `gorm:"column:after;serializer:json"`
+	IP         string         `gorm:"column:ip"`
+	RequestId  string         `gorm:"column:request_id"`
+}
+
+func (v6AuditLogEntry) TableName() string { return "audit_log_entries" }
+
+// v6AppendOnlyTriggers makes the database reject every update and delete of audit log entries,
+// so that not even a bug or a manual statement can rewrite the history
+func v6AppendOnlyTriggers(dialect string) []string {
+	if dialect == "postgres" {
+		return []string{
+			`CREATE OR REPLACE FUNCTION audit_log_entries_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_log_entries is append-only';
+END;
+$$ LANGUAGE plpgsql`,
+			`DROP TRIGGER IF EXISTS audit_log_entries_append_only ON audit_log_entries`,
+			`CREATE TRIGGER audit_log_entries_append_only BEFORE UPDATE OR DELETE ON audit_log_entries
+FOR EACH ROW EXECUTE PROCEDURE audit_log_entries_append_only()`,
+			`DROP TRIGGER IF EXISTS audit_log_entries_no_truncate ON audit_log_entries`,
+			`CREATE TRIGGER audit_log_entries_no_truncate BEFORE TRUNCATE ON audit_log_entries
+FOR EACH STATEMENT EXECUTE PROCEDURE audit_log_entries_append_only()`,
+		}
+	}
+	return []string{
+		`CREATE TRIGGER IF NOT EXISTS audit_log_entries_no_update BEFORE UPDATE ON audit_log_entries
+BEGIN SELECT RAISE(ABORT, 'audit_log_entries is append-only'); END`,
+		`CREATE TRIGGER IF NOT EXISTS audit_log_entries_no_delete BEFORE DELETE ON audit_log_entries
+BEGIN SELECT RAISE(ABORT, 'audit_log_entries is append-only'); END`,
+	}
+}
+
+// v6DropTriggers removes the triggers of v6AppendOnlyTriggers
+func v6DropTriggers(dialect string) []string {
+	if dialect == "postgres" {
+		return []string{
+			`DROP TRIGGER IF EXISTS audit_log_entries_no_truncate ON audit_log_entries`,
+			`DROP TRIGGER IF EXISTS audit_log_entries_append_only ON audit_log_entries`,
+			`DROP FUNCTION IF EXISTS audit_log_entries_append_only()`,
+		}
+	}
+	return []string{
+		`DROP TRIGGER IF EXISTS audit_log_entries_no_update`,
+		`DROP TRIGGER IF EXISTS audit_log_entries_no_delete`,
+	}
+}
+
+type v7Invitation struct {
+	Id         string     `gorm:"primaryKey;column:invitation_id;type:string"`
+	Role       string     `gorm:"column:role"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at"`
+	AcceptedAt *time.Time `gorm:"column:accepted_at"`
+	AcceptedBy string     `gorm:"column:accepted_by"`
+	CreatedAt  time.Time  `gorm:"column:created_at;index;autoCreateTime:false"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at;index;autoUpdateTime:false"`
+	CreatedBy  string     `gorm:"column:created_by;not null;default:''"`
+	UpdatedBy  string     `gorm:"column:updated_by;not null;default:''"`
+}
+
+func (v7Invitation) TableName() string { return "invitations" }
+
+type v8LoginAttempt struct {
+	Key           string     `gorm:"primaryKey;column:attempt_key;type:string"`
+	Failures      int        `gorm:"column:failures;not null;default:0"`
+	LastFailureAt time.Time  `gorm:"column:last_failure_at"`
+	LockedUntil   *time.Time `gorm:"column:locked_until;index"`
+}
+
+func (v8LoginAttempt) TableName() string { return "login_attempts" }
+
+type v9MfaEnrollment struct {
+	UserId      string     `gorm:"primaryKey;column:user_id;type:string"`
+	Secret      string     `gorm:"column:secret"`
+	ConfirmedAt *time.Time `gorm:"column:confirmed_at"`
+	LastStep    int64      `gorm:"column:last_step;not null;default:0"`
+	CreatedAt   time.Time  `gorm:"column:created_at;index;autoCreateTime:false"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;index;autoUpdateTime:false"`
+	CreatedBy   string     `gorm:"column:created_by;not null;default:''"`
+	UpdatedBy   string     `gorm:"column:updated_by;not null;default:''"`
+}
+
+func (v9MfaEnrollment) TableName() string { return "mfa_enrollments" }
+
+type v9MfaRecoveryCode struct {
+	UserId   string `gorm:"primaryKey;column:user_id;type:string"`
+	CodeHash string `gorm:"primaryKey;column:code_hash;type:string"`
+}
+
+func (v9MfaRecoveryCode) TableName() string { return "mfa_recovery_codes" }
+
+type v9MfaRequirement struct {
+	Role      string    `gorm:"primaryKey;column:role;type:string"`
+	CreatedAt time.Time `gorm:"column:created_at;index;autoCreateTime:false"`
+	UpdatedAt time.Time `gorm:"column:updated_at;index;autoUpdateTime:false"`
+	CreatedBy string    `gorm:"column:created_by;not null;default:''"`
+	UpdatedBy string    `gorm:"column:updated_by;not null;default:''"`
+}
+
+func (v9MfaRequirement) TableName() string { return "mfa_requirements" }
+
+type v10User struct {
+	Email string `gorm:"column:email;not null;default:''"`
+}
+
+func (v10User) TableName() string { return "users" }
+
+type v10PasswordResetToken struct {
+	TokenHash string    `gorm:"primaryKey;column:token_hash;type:string"`
+	UserId    string    `gorm:"column:user_id;index"`
+	ExpiresAt time.Time `gorm:"column:expires_at"`
+	CreatedAt time.Time `gorm:"column:created_at;index;autoCreateTime:false"`
+	UpdatedAt time.Time `gorm:"column:updated_at;index;autoUpdateTime:false"`
+	CreatedBy string    `gorm:"column:created_by;not null;default:''"`
+	UpdatedBy string    `gorm:"column:updated_by;not null;default:''"`
+}
+
+func (v10PasswordResetToken) TableName() string { return "password_reset_tokens" }
+
+type v10SessionRevocation struct {
+	UserId    string    `gorm:"primaryKey;column:user_id;type:string"`
+	RevokedAt time.Time `gorm:"column:revoked_at"`
+}
+
+func (v10SessionRevocation) TableName() string { return "session_revocations" }
+
+var v11UserFields = []string{"Phone", "DisplayName", "Language", "MarketingConsent", "VerifiedEmail", "VerifiedPhone"}
+
+// checkDuplicateEmails fails with the users that would share an email once emails are trimmed and lower-cased,
+// which the unique index of migration 11 cannot be created over. Which of them keeps the email is left to an admin.
+func checkDuplicateEmails(tx *gorm.DB) error {
+	var duplicates []struct {
+		UserId   string
+		Username string
+		Email    string
+	}
+	err := tx.Raw(`SELECT user_id, username, LOWER(TRIM(email)) AS email FROM users
+		WHERE LOWER(TRIM(email)) IN (
+			SELECT LOWER(TRIM(email)) FROM users WHERE TRIM(email) <> '' GROUP BY LOWER(TRIM(email)) HAVING COUNT(*) > 1
+		)
+		ORDER BY email, user_id`).Scan(&duplicates).Error
+	if err != nil || len(duplicates) == 0 {
+		return err
+	}
+
+	var groups []string
+	for i, duplicate := range duplicates {
+		user := fmt.Sprintf("%s (%s)", duplicate.Username, duplicate.UserId)
+		if i > 0 && duplicates[i-1].Email == duplicate.Email {
+			groups[len(groups)-1] += ", " + user
+			continue
+		}
+		groups = append(groups, fmt.Sprintf("%s: %s", duplicate.Email, user))
+	}
+	return fmt.Errorf("users share emails once they are trimmed and lower-cased; give each of them a distinct email, "+
+		"soft-deleted users included, and migrate again: %s", strings.Join(groups, "; "))
+}
+
+type v11User struct {
+	Phone            string `gorm:"column:phone;not null;default:''"`
+	DisplayName      string `gorm:"column:display_name;not null;default:''"`
+	Language         string `gorm:"column:language;not null;default:''"`
+	MarketingConsent bool   `gorm:"column:marketing_consent;not null;default:false"`
+	VerifiedEmail    string `gorm:"column:verified_email;not null;default:''"`
+	VerifiedPhone    string `gorm:"column:verified_phone;not null;default:''"`
+}
+
+func (v11User) TableName() string { return "users" }
+
+type v11ContactVerification struct {
+	Key       string    `gorm:"primaryKey;column:verification_key;type:string"`
+	UserId    string    `gorm:"column:user_id;index"`
+	Channel   string    `gorm:"column:channel"`
+	Target    string    `gorm:"column:target"`
+	CodeHash  string    `gorm:"column:code_hash"`
+	Attempts  int       `gorm:"column:attempts;not null;default:0"`
+	ExpiresAt time.Time `gorm:"column:expires_at"`
+	CreatedAt time.Time `gorm:"column:created_at;index;autoCreateTime:false"`
+	UpdatedAt time.Time `gorm:"column:updated_at;index;autoUpdateTime:false"`
+	CreatedBy string    `gorm:"column:created_by;not null;default:''"`
+	UpdatedBy string    `gorm:"column:updated_by;not null;default:''"`
+}
+
+func (v11ContactVerification) TableName() string { return "contact_verifications" }
+
+type v12SigningKey struct {
+	Kid         string    `gorm:"primaryKey;column:kid;type:string"`
+	Algorithm   string    `gorm:"column:algorithm"`
+	PrivateKey  string    `gorm:"column:private_key"`
+	ActivatesAt time.Time `gorm:"column:activates_at;index"`
+	RetiresAt   time.Time `gorm:"column:retires_at"`
+	ExpiresAt   time.Time `gorm:"column:expires_at;index"`
+	CreatedAt   time.Time `gorm:"column:created_at;index;autoCreateTime:false"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;index;autoUpdateTime:false"`
+	CreatedBy   string    `gorm:"column:created_by;not null;default:''"`
+	UpdatedBy   string    `gorm:"column:updated_by;not null;default:''"`
+}
+
+func (v12SigningKey) TableName() string { return "signing_keys" }
+
+type v13RevokedToken struct {
+	TokenId   string    `gorm:"primaryKey;column:token_id;type:string"`
+	UserId    string    `gorm:"column:user_id;index"`
+	ExpiresAt time.Time `gorm:"column:expires_at;index"`
+	RevokedAt time.Time `gorm:"column:revoked_at"`
+}
+
+func (v13RevokedToken) TableName() string { return "revoked_tokens" }
+
+type v14ApiClient struct {
+	Id          string    `gorm:"primaryKey;column:client_id;type:string"`
+	Name        string    `gorm:"column:name;uniqueIndex"`
+	Description string    `gorm:"column:description"`
+	CreatedAt   time.Time `gorm:"column:created_at;index;autoCreateTime:false"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;index;autoUpdateTime:false"`
+	CreatedBy   string    `gorm:"column:created_by;not null;default:''"`
+	UpdatedBy   string    `gorm:"column:updated_by;not null;default:''"`
+}
+
+func (v14ApiClient) TableName() string { return "api_clients" }
+
+type v14ApiKey struct {
+	Id         string     `gorm:"primaryKey;column:key_id;type:string"`
+	ClientId   string     `gorm:"column:client_id;index"`
+	Prefix     string     `gorm:"column:prefix"`
+	KeyHash    string     `gorm:"column:key_hash;uniqueIndex"`
+	Scopes     []string   `gorm:"column:scopes;serializer:json"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at;index;autoCreateTime:false"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at;index;autoUpdateTime:false"`
+	CreatedBy  string     `gorm:"column:created_by;not null;default:''"`
+	UpdatedBy  string     `gorm:"column:updated_by;not null;default:''"`
+}
+
+func (v14ApiKey) TableName() string { return "api_keys" }
+
+type v15OidcLogin struct {
+	StateHash    string    `gorm:"primaryKey;column:state_hash;type:string"`
+	Provider     string    `gorm:"column:provider"`
+	CodeVerifier string    `gorm:"column:code_verifier"`
+	Nonce        string    `gorm:"column:nonce"`
+	UserId       string    `gorm:"column:user_id;not null;default:''"`
+	ExpiresAt    time.Time `gorm:"column:expires_at"`
+	CreatedAt    time.Time `gorm:"column:created_at;index;autoCreateTime:false"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;index;autoUpdateTime:false"`
+	CreatedBy    string    `gorm:"column:created_by;not null;default:''"`
+	UpdatedBy    string    `gorm:"column:updated_by;not null;default:''"`
+}
+
+func (v15OidcLogin) TableName() string { return "oidc_logins" }
+
+type v15OidcIdentity struct {
+	Id        string    `gorm:"primaryKey;column:identity_id;type:string"`
+	Provider  string    `gorm:"column:provider;uniqueIndex:idx_oidc_identities_subject"`
+	Subject   string    `gorm:"column:subject;uniqueIndex:idx_oidc_identities_subject"`
+	UserId    string    `gorm:"column:user_id;index"`
+	Email     string    `gorm:"column:email;not null;default:''"`
+	CreatedAt time.Time `gorm:"column:created_at;index;autoCreateTime:false"`
+	UpdatedAt time.Time `gorm:"column:updated_at;index;autoUpdateTime:false"`
+	CreatedBy string    `gorm:"column:created_by;not null;default:''"`
+	UpdatedBy string    `gorm:"column:updated_by;not null;default:''"`
+}
+
+func (v15OidcIdentity) TableName() string { return "oidc_identities" }
+
+// checkDuplicateSeats fails with the bookings that hold the same seat of a show round, which the unique index
+// of migration 16 would otherwise reject with a bare constraint error
+func checkDuplicateSeats(tx *gorm.DB) error {
+	var duplicates []struct {
+		RoundId    string
+		SeatNumber int
+		BookingId  string
+	}
+	err := tx.Raw(`SELECT b.round_id, b.seat_number, b.booking_id FROM bookings b
+		WHERE b.deleted_at IS NULL AND b.status NOT IN ('cancelled', 'refunded') AND EXISTS (
+			SELECT 1 FROM bookings o WHERE o.round_id = b.round_id AND o.seat_number = b.seat_number
+				AND o.booking_id <> b.booking_id AND o.deleted_at IS NULL AND o.status NOT IN ('cancelled', 'refunded')
+		)
+		ORDER BY b.round_id, b.seat_number, b.booking_id`).Scan(&duplicates).Error
+	if err != nil || len(duplicates) == 0 {
+		return err
+	}
+
+	var groups []string
+	for i, duplicate := range duplicates {
+		if i > 0 && duplicates[i-1].RoundId == duplicate.RoundId && duplicates[i-1].SeatNumber == duplicate.SeatNumber {
+			groups[len(groups)-1] += ", " + duplicate.BookingId
+			continue
+		}
+		groups = append(groups, fmt.Sprintf("round %s seat %d: %s", duplicate.RoundId, duplicate.SeatNumber, duplicate.BookingId))
+	}
+	return fmt.Errorf("bookings share a seat of a show round; cancel all but one of them and migrate again: %s", strings.Join(groups, "; "))
+}
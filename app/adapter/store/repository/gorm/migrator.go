@@ -0,0 +1,244 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockKey identifies the PostgreSQL advisory lock held while migrating
+const migrationLockKey int64 = 4_207_151_030
+
+// ErrSchemaBehind is returned when the database has not been migrated to the version this build expects
+var ErrSchemaBehind = errors.New("database schema is behind")
+
+// Migration is one versioned change of the SQL schema. Up and Down run inside a transaction.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(tx *gorm.DB) error
+	Down        func(tx *gorm.DB) error
+}
+
+// MigrationStatus reports whether a migration has been applied; AppliedAt is nil for pending ones
+type MigrationStatus struct {
+	Version     int
+	Description string
+	AppliedAt   *time.Time
+}
+
+// schemaMigration is a row of the table recording the applied migrations
+type schemaMigration struct {
+	Version     int       `gorm:"primaryKey;autoIncrement:false;column:version"`
+	Description string    `gorm:"column:description"`
+	AppliedAt   time.Time `gorm:"column:applied_at"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator applies and rolls back the versioned migrations of the SQL backends
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for the migrations shipped with this build
+func NewMigrator(db *gorm.DB) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// Latest returns the version the schema reaches once every migration is applied
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the highest applied migration, 0 for an empty database
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for v := range applied {
+		version = max(version, v)
+	}
+	return version, nil
+}
+
+// Status lists every known migration together with when it was applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Description: migration.Description}
+		if row, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// EnsureCurrent fails with ErrSchemaBehind while any migration is still pending
+func (m *Migrator) EnsureCurrent(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			return fmt.Errorf("%w: migration %d (%s) is pending, run `migrate up`", ErrSchemaBehind, status.Version, status.Description)
+		}
+	}
+	return nil
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down rolls back the most recently applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	version, err := m.Version(ctx)
+	if err != nil || version == 0 {
+		return err
+	}
+
+	target := 0
+	for _, migration := range m.migrations {
+		if migration.Version < version {
+			target = migration.Version
+		}
+	}
+	return m.To(ctx, target)
+}
+
+// To migrates up or down until exactly the migrations up to version are applied; 0 rolls everything back
+func (m *Migrator) To(ctx context.Context, version int) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return m.withLock(ctx, func(conn *gorm.DB) error {
+		if err := conn.AutoMigrate(&schemaMigration{}); err != nil {
+			return err
+		}
+
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		// Roll back newest first, then apply oldest first
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; ok && migration.Version > version {
+				if err := m.rollback(conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+				if err := m.apply(conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (m *Migrator) apply(conn *gorm.DB, migration Migration) error {
+	return conn.Transaction(func(tx *gorm.DB) error {
+		// Another instance may have applied it since the applied set was read
+		var count int64
+		if err := tx.Model(&schemaMigration{}).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		if err := migration.Up(tx); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+		}
+		return tx.Create(&schemaMigration{
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   time.Now().UTC(),
+		}).Error
+	})
+}
+
+func (m *Migrator) rollback(conn *gorm.DB, migration Migration) error {
+	if migration.Down == nil {
+		return fmt.Errorf("migration %d (%s) cannot be rolled back", migration.Version, migration.Description)
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		if err := migration.Down(tx); err != nil {
+			return fmt.Errorf("rollback of migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+		}
+		return tx.Where("version = ?", migration.Version).Delete(&schemaMigration{}).Error
+	})
+}
+
+// applied loads the migration table; a database that was never migrated has none
+func (m *Migrator) applied(conn *gorm.DB) (map[int]schemaMigration, error) {
+	if !conn.Migrator().HasTable(&schemaMigration{}) {
+		return map[int]schemaMigration{}, nil
+	}
+
+	var rows []schemaMigration
+	if err := conn.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// withLock runs fn while holding the migration lock so that only one instance migrates at a time.
+// PostgreSQL uses a session advisory lock on a pinned connection. SQLite serializes writers itself,
+// and apply re-checks the migration table inside its transaction.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	db := m.db.WithContext(ctx)
+	if db.Dialector.Name() != "postgres" {
+		return fn(db)
+	}
+
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey)
+
+		return fn(conn)
+	})
+}
@@ -0,0 +1,180 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fresh database is behind until migrated", func(t *testing.T) {
+		migrator := NewMigrator(openTestDB(t))
+
+		assert.ErrorIs(t, migrator.EnsureCurrent(ctx), ErrSchemaBehind)
+
+		require.NoError(t, migrator.Up(ctx))
+
+		assert.NoError(t, migrator.EnsureCurrent(ctx))
+		version, err := migrator.Version(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, migrator.Latest(), version)
+
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		for _, status := range statuses {
+			assert.NotNil(t, status.AppliedAt, "migration %d", status.Version)
+		}
+	})
+
+	t.Run("up is idempotent", func(t *testing.T) {
+		migrator := NewMigrator(openTestDB(t))
+
+		require.NoError(t, migrator.Up(ctx))
+		assert.NoError(t, migrator.Up(ctx))
+	})
+
+	t.Run("down rolls back the latest migration", func(t *testing.T) {
+		db := openTestDB(t)
+		migrator := NewMigrator(db)
+		require.NoError(t, migrator.Up(ctx))
+
+		require.NoError(t, migrator.Down(ctx))
+
+		version, err := migrator.Version(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 15, version)
+		assert.False(t, db.Migrator().HasIndex("bookings", "idx_bookings_round_seat"))
+		assert.True(t, db.Migrator().HasTable(&v15OidcIdentity{}))
+		assert.True(t, db.Migrator().HasTable(&v14ApiKey{}))
+		assert.True(t, db.Migrator().HasTable(&v13RevokedToken{}))
+		assert.True(t, db.Migrator().HasTable(&v12SigningKey{}))
+		assert.True(t, db.Migrator().HasTable(&v11ContactVerification{}))
+		assert.True(t, db.Migrator().HasColumn(&v10User{}, "Email"))
+		assert.True(t, db.Table("bookings").Migrator().HasColumn(&v5Audit{}, "CreatedAt"))
+		assert.True(t, db.Migrator().HasIndex(&v2Booking{}, "Status"))
+		assert.ErrorIs(t, migrator.EnsureCurrent(ctx), ErrSchemaBehind)
+	})
+
+	t.Run("to version zero drops everything", func(t *testing.T) {
+		db := openTestDB(t)
+		migrator := NewMigrator(db)
+		require.NoError(t, migrator.Up(ctx))
+
+		require.NoError(t, migrator.To(ctx, 0))
+
+		assert.False(t, db.Migrator().HasTable("users"))
+		assert.False(t, db.Migrator().HasTable("bookings"))
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		migrator := NewMigrator(openTestDB(t))
+
+		assert.Error(t, migrator.To(ctx, 999))
+	})
+
+	t.Run("later columns backfill existing bookings", func(t *testing.T) {
+		db := openTestDB(t)
+		migrator := NewMigrator(db)
+		require.NoError(t, migrator.To(ctx, 1))
+		require.NoError(t, db.Create(&v1User{Id: "u1", Username: "alice"}).Error)
+		require.NoError(t, db.Create(&v1ShowRound{Id: "r1", ShowTime: "2025-01-01T09:00:00Z"}).Error)
+		require.NoError(t, db.Create(&v1Booking{Id: "b1", UserId: "u1", RoundId: "r1", SeatNumber: 1}).Error)
+
+		require.NoError(t, migrator.Up(ctx))
+
+		booking, err := NewGormBookingRepository(db).GetBookingById(ctx, "b1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.BookingStatusConfirmed, booking.Status)
+		assert.Equal(t, int64(1), booking.Version)
+		assert.Nil(t, booking.DeletedAt)
+		assert.False(t, booking.CreatedAt.IsZero())
+		assert.Equal(t, booking.CreatedAt, booking.UpdatedAt)
+		assert.Empty(t, booking.CreatedBy)
+	})
+
+	t.Run("emails that collide once normalized are reported before the unique index", func(t *testing.T) {
+		db := openTestDB(t)
+		migrator := NewMigrator(db)
+		require.NoError(t, migrator.To(ctx, 10))
+		for _, user := range []struct{ id, username, email string }{
+			{"u1", "alice", "Alice@Example.com"},
+			{"u2", "alice2", " alice@example.com"},
+			{"u3", "bob", "bob@example.com"},
+			{"u4", "carol", ""},
+			{"u5", "dave", "  "},
+		} {
+			require.NoError(t, db.Exec("INSERT INTO users (user_id, username, email) VALUES (?, ?, ?)", user.id, user.username, user.email).Error)
+		}
+
+		err := migrator.Up(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "migration 11")
+		assert.Contains(t, err.Error(), "alice@example.com: alice (u1), alice2 (u2)")
+		assert.NotContains(t, err.Error(), "bob")
+		assert.NotContains(t, err.Error(), "dave", "blank emails do not collide")
+		version, err := migrator.Version(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 10, version, "nothing of migration 11 is applied")
+
+		require.NoError(t, db.Exec("UPDATE users SET email = ? WHERE user_id = ?", "alice.two@example.com", "u2").Error)
+		require.NoError(t, migrator.Up(ctx))
+		user, err := NewGormUsersRepository(db).GetUserById(ctx, "u1")
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", user.Email)
+	})
+
+	t.Run("bookings sharing a seat are reported before the unique index", func(t *testing.T) {
+		db := openTestDB(t)
+		migrator := NewMigrator(db)
+		require.NoError(t, migrator.To(ctx, 15))
+		require.NoError(t, db.Create(&v1User{Id: "u1", Username: "alice"}).Error)
+		require.NoError(t, db.Create(&v1ShowRound{Id: "r1", ShowTime: "2025-01-01T09:00:00Z"}).Error)
+		for _, booking := range []struct {
+			id     string
+			seat   int
+			status string
+		}{
+			{"b1", 1, "confirmed"},
+			{"b2", 1, "confirmed"},
+			{"b3", 2, "confirmed"},
+			{"b4", 2, "cancelled"},
+		} {
+			require.NoError(t, db.Exec("INSERT INTO bookings (booking_id, user_id, round_id, seat_number, status) VALUES (?, 'u1', 'r1', ?, ?)",
+				booking.id, booking.seat, booking.status).Error)
+		}
+
+		err := migrator.Up(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "migration 16")
+		assert.Contains(t, err.Error(), "round r1 seat 1: b1, b2")
+		assert.NotContains(t, err.Error(), "b3", "a cancelled booking frees its seat")
+
+		require.NoError(t, db.Exec("UPDATE bookings SET status = 'cancelled' WHERE booking_id = 'b2'").Error)
+		require.NoError(t, migrator.Up(ctx))
+
+		repo := NewGormBookingRepository(db)
+		_, err = repo.CreateBooking(ctx, &domain.Bookings{UserId: "u1", RoundId: "r1", SeatNumber: 1, Status: domain.BookingStatusConfirmed})
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+		_, err = repo.CreateBooking(ctx, &domain.Bookings{UserId: "u1", RoundId: "r1", SeatNumber: 1, Status: domain.BookingStatusCancelled})
+		assert.NoError(t, err)
+		require.NoError(t, repo.DeleteBooking(ctx, "b1"))
+		_, err = repo.CreateBooking(ctx, &domain.Bookings{UserId: "u1", RoundId: "r1", SeatNumber: 1, Status: domain.BookingStatusConfirmed})
+		assert.NoError(t, err, "a deleted booking frees its seat")
+	})
+
+	t.Run("adopts a schema created by AutoMigrate", func(t *testing.T) {
+		db := openTestDB(t)
+		require.NoError(t, db.AutoMigrate(&domain.Users{}, &domain.Animals{}, &domain.PerformanceStage{}))
+		require.NoError(t, db.AutoMigrate(&domain.ShowRounds{}, &domain.Bookings{}))
+
+		migrator := NewMigrator(db)
+		require.NoError(t, migrator.Up(ctx))
+
+		assert.NoError(t, migrator.EnsureCurrent(ctx))
+	})
+}
@@ -0,0 +1,79 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormOidcRepository stores the pending sign-ins and the linked identities of external providers in two tables
+type GormOidcRepository struct {
+	db *gorm.DB
+}
+
+func NewGormOidcRepository(db *gorm.DB) *GormOidcRepository {
+	return &GormOidcRepository{db: db}
+}
+
+func (r *GormOidcRepository) CreateOidcLogin(ctx context.Context, login *domain.OidcLogin) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	login.Audit = port.NewAudit(ctx)
+	return translateError(conn(ctx, r.db).Create(login).Error)
+}
+
+func (r *GormOidcRepository) TakeOidcLogin(ctx context.Context, stateHash string) (*domain.OidcLogin, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	// Like password reset tokens, the delete decides which of two requests with the same state gets the sign-in
+	var logins []domain.OidcLogin
+	result := conn(ctx, r.db).Clauses(clause.Returning{}).Where("state_hash = ?", stateHash).Delete(&logins)
+	if result.Error != nil {
+		return nil, translateError(result.Error)
+	}
+	if len(logins) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return &logins[0], nil
+}
+
+func (r *GormOidcRepository) CreateOidcIdentity(ctx context.Context, identity *domain.OidcIdentity) (*domain.OidcIdentity, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	identity.Id = uuid.New().String()
+	identity.Audit = port.NewAudit(ctx)
+	if err := conn(ctx, r.db).Create(identity).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return identity, nil
+}
+
+func (r *GormOidcRepository) GetOidcIdentity(ctx context.Context, provider string, subject string) (*domain.OidcIdentity, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var identity domain.OidcIdentity
+	if err := conn(ctx, r.db).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &identity, nil
+}
+
+func (r *GormOidcRepository) ListOidcIdentities(ctx context.Context, userId string) ([]domain.OidcIdentity, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	identities := []domain.OidcIdentity{}
+	if err := conn(ctx, r.db).Where("user_id = ?", userId).Order("created_at, identity_id").Find(&identities).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return identities, nil
+}
@@ -0,0 +1,50 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormPasswordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewGormPasswordResetRepository(db *gorm.DB) *GormPasswordResetRepository {
+	return &GormPasswordResetRepository{db: db}
+}
+
+func (r *GormPasswordResetRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	token.Audit = port.NewAudit(ctx)
+	return translateError(conn(ctx, r.db).Create(token).Error)
+}
+
+func (r *GormPasswordResetRepository) TakePasswordResetToken(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	// Deleting with RETURNING makes the delete the arbiter between concurrent resets with the same token
+	var tokens []domain.PasswordResetToken
+	result := conn(ctx, r.db).Clauses(clause.Returning{}).Where("token_hash = ?", tokenHash).Delete(&tokens)
+	if result.Error != nil {
+		return nil, translateError(result.Error)
+	}
+	if len(tokens) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return &tokens[0], nil
+}
+
+func (r *GormPasswordResetRepository) DeletePasswordResetTokens(ctx context.Context, userId string) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	return translateError(conn(ctx, r.db).Where("user_id = ?", userId).Delete(&domain.PasswordResetToken{}).Error)
+}
@@ -5,9 +5,20 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"gorm.io/gorm"
 )
 
+// stageSortColumns whitelists the fields GetStages can be sorted by
+var stageSortColumns = map[string]string{
+	"stage_id":       "stage_id",
+	"room_number":    "room_number",
+	"seat_capacity":  "seat_capacity",
+	"price_per_seat": "price_per_seat",
+	"created_at":     "created_at",
+	"updated_at":     "updated_at",
+}
+
 type GormPerformanceStageRepository struct {
 	base *BaseGormRepository
 }
@@ -18,17 +29,29 @@ func NewGormPerformanceStageRepository(db *gorm.DB) *GormPerformanceStageReposit
 	}
 }
 
-func (r *GormPerformanceStageRepository) GetStages(ctx context.Context) ([]domain.PerformanceStage, error) {
+func (r *GormPerformanceStageRepository) GetStages(ctx context.Context, filter port.StageFilter, query port.ListQuery) (*port.Page[domain.PerformanceStage], error) {
+	tx := live(ctx, r.base.db).Model(&domain.PerformanceStage{})
+	if filter.RoomNumber != "" {
+		tx = tx.Where("room_number = ?", filter.RoomNumber)
+	}
+	if filter.MinSeatCapacity > 0 {
+		tx = tx.Where("seat_capacity >= ?", filter.MinSeatCapacity)
+	}
+	tx = whereAudit(tx, filter.Audit)
+
 	var stages []domain.PerformanceStage
-	if err := r.base.db.WithContext(ctx).Find(&stages).Error; err != nil {
+	total, query, err := findPage(tx, query, stageSortColumns, "stage_id", &stages)
+	if err != nil {
 		return nil, err
 	}
-	return stages, nil
+	return port.NewPage(stages, total, query), nil
 }
 
 func (r *GormPerformanceStageRepository) CreateStage(ctx context.Context, stage *domain.PerformanceStage) (*domain.PerformanceStage, error) {
 	// Generate UUID for new stage
 	stage.Id = uuid.New().String()
+	stage.Version = 1
+	stage.Audit = port.NewAudit(ctx)
 
 	if err := r.base.Create(ctx, stage); err != nil {
 		return nil, err
@@ -38,8 +61,8 @@ func (r *GormPerformanceStageRepository) CreateStage(ctx context.Context, stage
 
 func (r *GormPerformanceStageRepository) GetStageById(ctx context.Context, id string) (*domain.PerformanceStage, error) {
 	var stage domain.PerformanceStage
-	if err := r.base.db.WithContext(ctx).Where("stage_id = ?", id).First(&stage).Error; err != nil {
-		return nil, err
+	if err := live(ctx, r.base.db).Where("stage_id = ?", id).First(&stage).Error; err != nil {
+		return nil, translateError(err)
 	}
 	return &stage, nil
 }
@@ -50,7 +73,13 @@ func (r *GormPerformanceStageRepository) UpdateStage(ctx context.Context, id str
 		return nil, err
 	}
 
-	if err := r.base.db.WithContext(ctx).Model(existingStage).Updates(stage).Error; err != nil {
+	if err := domain.CheckVersion(stage.Version, existingStage.Version); err != nil {
+		return nil, err
+	}
+
+	stage.Version = existingStage.Version + 1
+	stage.Audit = port.UpdateAudit(ctx)
+	if err := updateVersioned(live(ctx, r.base.db), existingStage, existingStage.Version, stage); err != nil {
 		return nil, err
 	}
 
@@ -58,5 +87,5 @@ func (r *GormPerformanceStageRepository) UpdateStage(ctx context.Context, id str
 }
 
 func (r *GormPerformanceStageRepository) DeleteStage(ctx context.Context, id string) error {
-	return r.base.db.WithContext(ctx).Where("stage_id = ?", id).Delete(&domain.PerformanceStage{}).Error
+	return softDelete(ctx, r.base.db, &domain.PerformanceStage{}, "stage_id", id)
 }
@@ -0,0 +1,63 @@
+package gorm
+
+import (
+	"context"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewGormSessionRepository(db *gorm.DB) *GormSessionRepository {
+	return &GormSessionRepository{db: db}
+}
+
+func (r *GormSessionRepository) RevokeSessions(ctx context.Context, userId string, at time.Time) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	revocation := &domain.SessionRevocation{UserId: userId, RevokedAt: at}
+	return translateError(conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_at"}),
+	}).Create(revocation).Error)
+}
+
+func (r *GormSessionRepository) GetSessionRevocation(ctx context.Context, userId string) (*domain.SessionRevocation, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var revocation domain.SessionRevocation
+	if err := conn(ctx, r.db).Where("user_id = ?", userId).First(&revocation).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &revocation, nil
+}
+
+func (r *GormSessionRepository) RevokeToken(ctx context.Context, token *domain.RevokedToken) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	db := conn(ctx, r.db)
+	if err := db.Where("expires_at <= ?", time.Now()).Delete(&domain.RevokedToken{}).Error; err != nil {
+		return translateError(err)
+	}
+	return translateError(db.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error)
+}
+
+func (r *GormSessionRepository) IsTokenRevoked(ctx context.Context, tokenId string) (bool, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var count int64
+	if err := conn(ctx, r.db).Model(&domain.RevokedToken{}).Where("token_id = ?", tokenId).Count(&count).Error; err != nil {
+		return false, translateError(err)
+	}
+	return count > 0, nil
+}
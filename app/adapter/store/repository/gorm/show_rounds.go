@@ -2,12 +2,24 @@ package gorm
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"gorm.io/gorm"
 )
 
+// showRoundSortColumns whitelists the fields GetAllShowRounds can be sorted by
+var showRoundSortColumns = map[string]string{
+	"round_id":   "round_id",
+	"animal_id":  "animal_id",
+	"stage_id":   "stage_id",
+	"show_time":  "show_time",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
 type GormShowRoundRepository struct {
 	base *BaseGormRepository
 }
@@ -21,6 +33,8 @@ func NewGormShowRoundRepository(db *gorm.DB) *GormShowRoundRepository {
 func (r *GormShowRoundRepository) CreateShowRound(ctx context.Context, showRound *domain.ShowRounds) (*domain.ShowRounds, error) {
 	// Generate UUID for new show round
 	showRound.Id = uuid.New().String()
+	showRound.Version = 1
+	showRound.Audit = port.NewAudit(ctx)
 
 	if err := r.base.Create(ctx, showRound); err != nil {
 		return nil, err
@@ -30,15 +44,53 @@ func (r *GormShowRoundRepository) CreateShowRound(ctx context.Context, showRound
 
 func (r *GormShowRoundRepository) GetShowRoundById(ctx context.Context, id string) (*domain.ShowRounds, error) {
 	var showRound domain.ShowRounds
-	if err := r.base.db.WithContext(ctx).Where("round_id = ?", id).First(&showRound).Error; err != nil {
-		return nil, err
+	if err := live(ctx, r.base.db).Where("round_id = ?", id).First(&showRound).Error; err != nil {
+		return nil, translateError(err)
 	}
 	return &showRound, nil
 }
 
-func (r *GormShowRoundRepository) GetAllShowRounds(ctx context.Context) ([]*domain.ShowRounds, error) {
+func (r *GormShowRoundRepository) GetAllShowRounds(ctx context.Context, filter port.ShowRoundFilter, query port.ListQuery) (*port.Page[*domain.ShowRounds], error) {
+	db := live(ctx, r.base.db)
+	tx := db.Model(&domain.ShowRounds{})
+	if filter.AnimalId != "" {
+		tx = tx.Where("animal_id = ?", filter.AnimalId)
+	}
+	if filter.StageId != "" {
+		tx = tx.Where("stage_id = ?", filter.StageId)
+	}
+	if filter.Species != "" {
+		tx = tx.Where("animal_id IN (?)", db.Model(&domain.Animals{}).Select("animal_id").Where("species = ?", filter.Species))
+	}
+	// Bounds are bound as RFC3339 strings: PostgreSQL casts them to its timestamp column,
+	// while SQLite compares them with the stored text in the same layout
+	if filter.From != nil {
+		tx = tx.Where("show_time >= ?", filter.From.UTC().Format(time.RFC3339))
+	}
+	if filter.To != nil {
+		tx = tx.Where("show_time <= ?", filter.To.UTC().Format(time.RFC3339))
+	}
+	tx = whereAudit(tx, filter.Audit)
+
+	var showRounds []*domain.ShowRounds
+	total, query, err := findPage(tx, query, showRoundSortColumns, "round_id", &showRounds)
+	if err != nil {
+		return nil, err
+	}
+	return port.NewPage(showRounds, total, query), nil
+}
+
+func (r *GormShowRoundRepository) GetShowRoundsByAnimalId(ctx context.Context, animalId string) ([]*domain.ShowRounds, error) {
+	var showRounds []*domain.ShowRounds
+	if err := live(ctx, r.base.db).Where("animal_id = ?", animalId).Find(&showRounds).Error; err != nil {
+		return nil, err
+	}
+	return showRounds, nil
+}
+
+func (r *GormShowRoundRepository) GetShowRoundsByStageId(ctx context.Context, stageId string) ([]*domain.ShowRounds, error) {
 	var showRounds []*domain.ShowRounds
-	if err := r.base.db.WithContext(ctx).Find(&showRounds).Error; err != nil {
+	if err := live(ctx, r.base.db).Where("stage_id = ?", stageId).Find(&showRounds).Error; err != nil {
 		return nil, err
 	}
 	return showRounds, nil
@@ -50,7 +102,13 @@ func (r *GormShowRoundRepository) UpdateShowRound(ctx context.Context, id string
 		return nil, err
 	}
 
-	if err := r.base.db.WithContext(ctx).Model(existingShowRound).Updates(showRound).Error; err != nil {
+	if err := domain.CheckVersion(showRound.Version, existingShowRound.Version); err != nil {
+		return nil, err
+	}
+
+	showRound.Version = existingShowRound.Version + 1
+	showRound.Audit = port.UpdateAudit(ctx)
+	if err := updateVersioned(live(ctx, r.base.db), existingShowRound, existingShowRound.Version, showRound); err != nil {
 		return nil, err
 	}
 
@@ -58,5 +116,5 @@ func (r *GormShowRoundRepository) UpdateShowRound(ctx context.Context, id string
 }
 
 func (r *GormShowRoundRepository) DeleteShowRound(ctx context.Context, id string) error {
-	return r.base.db.WithContext(ctx).Where("round_id = ?", id).Delete(&domain.ShowRounds{}).Error
+	return softDelete(ctx, r.base.db, &domain.ShowRounds{}, "round_id", id)
 }
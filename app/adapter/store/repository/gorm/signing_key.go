@@ -0,0 +1,67 @@
+package gorm
+
+import (
+	"context"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"gorm.io/gorm"
+)
+
+type GormSigningKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewGormSigningKeyRepository(db *gorm.DB) *GormSigningKeyRepository {
+	return &GormSigningKeyRepository{db: db}
+}
+
+func (r *GormSigningKeyRepository) CreateSigningKey(ctx context.Context, key *domain.SigningKey) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	key.Audit = port.NewAudit(ctx)
+	return translateError(conn(ctx, r.db).Create(key).Error)
+}
+
+func (r *GormSigningKeyRepository) ListSigningKeys(ctx context.Context) ([]domain.SigningKey, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var keys []domain.SigningKey
+	if err := conn(ctx, r.db).Order("activates_at, kid").Find(&keys).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return keys, nil
+}
+
+func (r *GormSigningKeyRepository) UpdateSigningKeyPrivateKey(ctx context.Context, kid string, privateKey string) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	change := port.UpdateAudit(ctx)
+	result := conn(ctx, r.db).Model(&domain.SigningKey{}).
+		Where("kid = ?", kid).
+		Updates(map[string]any{
+			"private_key": privateKey,
+			"updated_at":  change.UpdatedAt,
+			"updated_by":  change.UpdatedBy,
+		})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormSigningKeyRepository) DeleteExpiredSigningKeys(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result := conn(ctx, r.db).Where("expires_at <= ?", now).Delete(&domain.SigningKey{})
+	return result.RowsAffected, translateError(result.Error)
+}
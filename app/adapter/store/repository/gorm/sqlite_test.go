@@ -0,0 +1,94 @@
+package gorm
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/repository/repositorytest"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSQLiteRepositories(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		db := openTestDB(t)
+		require.NoError(t, NewMigrator(db).Up(context.Background()))
+
+		return repositorytest.Repositories{
+			Users:          NewGormUsersRepository(db),
+			Animals:        NewGormAnimalRepository(db),
+			Stages:         NewGormPerformanceStageRepository(db),
+			ShowRounds:     NewGormShowRoundRepository(db),
+			Bookings:       NewGormBookingRepository(db),
+			UnitOfWork:     NewGormUnitOfWork(db),
+			Trash:          NewGormTrashRepository(db),
+			AuditLog:       NewGormAuditLogRepository(db),
+			Invitations:    NewGormInvitationRepository(db),
+			LoginAttempts:  NewGormLoginAttemptRepository(db),
+			Mfa:            NewGormMfaRepository(db),
+			PasswordResets: NewGormPasswordResetRepository(db),
+			Sessions:       NewGormSessionRepository(db),
+			Verifications:  NewGormVerificationRepository(db),
+			SigningKeys:    NewGormSigningKeyRepository(db),
+			ApiKeys:        NewGormApiKeyRepository(db),
+			Oidc:           NewGormOidcRepository(db),
+		}
+	})
+}
+
+func TestUpdateVersionedLosesRace(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	require.NoError(t, NewMigrator(db).Up(ctx))
+	repo := NewGormAnimalRepository(db)
+
+	animal, err := repo.CreateAnimal(ctx, &domain.Animals{Name: "Leo"})
+	require.NoError(t, err)
+
+	// Both writers read version 1; the first one to write wins
+	first := &domain.Animals{Name: "first", Version: 2}
+	second := &domain.Animals{Name: "second", Version: 2}
+	require.NoError(t, updateVersioned(db, animal, 1, first))
+	assert.ErrorIs(t, updateVersioned(db, animal, 1, second), domain.ErrVersionConflict)
+
+	stored, err := repo.GetAnimalById(ctx, animal.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "first", stored.Name)
+	assert.Equal(t, int64(2), stored.Version)
+}
+
+func TestAuditLogIsAppendOnly(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	require.NoError(t, NewMigrator(db).Up(ctx))
+	repo := NewGormAuditLogRepository(db)
+
+	entry := port.NewAuditLogEntry(ctx, domain.AuditActionLogin, domain.AuditEntityUser, "u1", nil, nil)
+	require.NoError(t, repo.Append(ctx, entry))
+
+	assert.Error(t, db.Model(&domain.AuditLogEntry{}).Where("entry_id = ?", entry.Id).Update("actor", "mallory").Error)
+	assert.Error(t, db.Where("entry_id = ?", entry.Id).Delete(&domain.AuditLogEntry{}).Error)
+
+	page, err := repo.ListAuditLog(ctx, port.AuditLogFilter{}, port.ListQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, domain.AnonymousActor, page.Items[0].Actor)
+}
+
+// openTestDB opens an empty SQLite database in a temp file that is closed when the test ends
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := openSQLite(filepath.Join(t.TempDir(), "liongate.db"), logger.Discard)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		sqlDB, err := db.DB()
+		require.NoError(t, err)
+		sqlDB.Close()
+	})
+	return db
+}
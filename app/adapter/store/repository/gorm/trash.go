@@ -0,0 +1,181 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// trashTable describes where the soft-deleted entities of one kind are stored
+type trashTable struct {
+	model    func() any
+	idColumn string
+}
+
+var trashTables = map[port.TrashKind]trashTable{
+	port.TrashUsers:      {model: func() any { return &domain.Users{} }, idColumn: "user_id"},
+	port.TrashAnimals:    {model: func() any { return &domain.Animals{} }, idColumn: "animal_id"},
+	port.TrashStages:     {model: func() any { return &domain.PerformanceStage{} }, idColumn: "stage_id"},
+	port.TrashShowRounds: {model: func() any { return &domain.ShowRounds{} }, idColumn: "round_id"},
+	port.TrashBookings:   {model: func() any { return &domain.Bookings{} }, idColumn: "booking_id"},
+}
+
+type GormTrashRepository struct {
+	db *gorm.DB
+}
+
+func NewGormTrashRepository(db *gorm.DB) *GormTrashRepository {
+	return &GormTrashRepository{db: db}
+}
+
+func (r *GormTrashRepository) ListTrash(ctx context.Context, kind port.TrashKind, filter port.TrashFilter, query port.ListQuery) (*port.Page[port.TrashItem], error) {
+	table, err := lookupTrashTable(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.trashed(ctx).Model(table.model())
+	if filter.DeletedBefore != nil {
+		tx = tx.Where("deleted_at < ?", filter.DeletedBefore.UTC())
+	}
+
+	switch kind {
+	case port.TrashUsers:
+		return listTrash[domain.Users](tx, kind, table, query)
+	case port.TrashAnimals:
+		return listTrash[domain.Animals](tx, kind, table, query)
+	case port.TrashStages:
+		return listTrash[domain.PerformanceStage](tx, kind, table, query)
+	case port.TrashShowRounds:
+		return listTrash[domain.ShowRounds](tx, kind, table, query)
+	default:
+		return listTrash[domain.Bookings](tx, kind, table, query)
+	}
+}
+
+func (r *GormTrashRepository) GetTrashItem(ctx context.Context, kind port.TrashKind, id string) (*port.TrashItem, error) {
+	table, err := lookupTrashTable(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := table.model()
+	if err := r.trashed(ctx).Where(idEquals(table, id)).First(entity).Error; err != nil {
+		return nil, translateError(err)
+	}
+	item := port.NewTrashItem(kind, entity.(port.Trashable))
+	return &item, nil
+}
+
+func (r *GormTrashRepository) Restore(ctx context.Context, kind port.TrashKind, id string) error {
+	table, err := lookupTrashTable(kind)
+	if err != nil {
+		return err
+	}
+
+	change := port.UpdateAudit(ctx)
+	result := r.trashed(ctx).Model(table.model()).
+		Where(idEquals(table, id)).
+		Updates(map[string]any{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": change.UpdatedAt,
+			"updated_by": change.UpdatedBy,
+		})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormTrashRepository) Purge(ctx context.Context, kind port.TrashKind, id string) error {
+	table, err := lookupTrashTable(kind)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	if err := r.trashed(ctx).Model(table.model()).Where(idEquals(table, id)).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrNotFound
+	}
+
+	// Remove the trashed dependents first so that the foreign keys of bookings never dangle
+	switch kind {
+	case port.TrashUsers:
+		err = r.purgeBookings(ctx, "user_id = ?", id)
+	case port.TrashShowRounds:
+		err = r.purgeBookings(ctx, "round_id = ?", id)
+	case port.TrashAnimals:
+		err = r.purgeShowRounds(ctx, "animal_id = ?", id)
+	case port.TrashStages:
+		err = r.purgeShowRounds(ctx, "stage_id = ?", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.trashed(ctx).Where(idEquals(table, id)).Delete(table.model()).Error
+}
+
+// purgeShowRounds permanently removes the trashed show rounds matching condition together with their trashed bookings
+func (r *GormTrashRepository) purgeShowRounds(ctx context.Context, condition string, args ...any) error {
+	rounds := r.trashed(ctx).Model(&domain.ShowRounds{}).Select("round_id").Where(condition, args...)
+	if err := r.purgeBookings(ctx, "round_id IN (?)", rounds); err != nil {
+		return err
+	}
+	return r.trashed(ctx).Where(condition, args...).Delete(&domain.ShowRounds{}).Error
+}
+
+// purgeBookings permanently removes the trashed bookings matching condition
+func (r *GormTrashRepository) purgeBookings(ctx context.Context, condition string, args ...any) error {
+	return r.trashed(ctx).Where(condition, args...).Delete(&domain.Bookings{}).Error
+}
+
+// trashed is conn restricted to the rows that have been soft-deleted
+func (r *GormTrashRepository) trashed(ctx context.Context) *gorm.DB {
+	return conn(ctx, r.db).Where("deleted_at IS NOT NULL").Session(&gorm.Session{})
+}
+
+// listTrash loads one page of the soft-deleted rows of T matched by tx
+func listTrash[T any, PT interface {
+	*T
+	port.Trashable
+}](tx *gorm.DB, kind port.TrashKind, table trashTable, query port.ListQuery) (*port.Page[port.TrashItem], error) {
+	columns := map[string]string{"id": table.idColumn, "deleted_at": "deleted_at"}
+
+	var rows []T
+	total, query, err := findPage(tx, query, columns, table.idColumn, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]port.TrashItem, len(rows))
+	for i := range rows {
+		items[i] = port.NewTrashItem(kind, PT(&rows[i]))
+	}
+	return port.NewPage(items, total, query), nil
+}
+
+// lookupTrashTable resolves a kind, rejecting the ones that are not soft-deletable
+func lookupTrashTable(kind port.TrashKind) (trashTable, error) {
+	table, ok := trashTables[kind]
+	if !ok {
+		_, err := port.ParseTrashKind(string(kind))
+		return table, err
+	}
+	return table, nil
+}
+
+// idEquals matches the row of table with the given id
+func idEquals(table trashTable, id string) clause.Eq {
+	return clause.Eq{Column: clause.Column{Name: table.idColumn}, Value: id}
+}
@@ -0,0 +1,42 @@
+package gorm
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey is the context key under which GormUnitOfWork stores the running transaction
+type txKey struct{}
+
+// GormUnitOfWork runs a group of repository calls inside one SQL transaction
+type GormUnitOfWork struct {
+	db *gorm.DB
+}
+
+// NewGormUnitOfWork creates a unit of work for the repositories sharing db
+func NewGormUnitOfWork(db *gorm.DB) *GormUnitOfWork {
+	return &GormUnitOfWork{db: db}
+}
+
+// Do runs fn in a transaction that is committed when fn returns nil and rolled back otherwise.
+// A Do nested inside another joins the outer transaction.
+func (u *GormUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction bound to ctx by a unit of work, or db when there is none.
+// Repositories must go through it so their queries join the caller's transaction; with SQLite's
+// single connection a query outside it would otherwise wait for the transaction forever.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"gorm.io/gorm"
 )
 
@@ -19,8 +20,12 @@ func NewGormUsersRepository(db *gorm.DB) *GormUserRepository {
 }
 
 func (r *GormUserRepository) CreateUser(ctx context.Context, user *domain.Users) (*domain.Users, error) {
-	// Generate UUID for new user
-	user.Id = uuid.New().String()
+	// Generate UUID for new user, unless the caller picked one to reference it before it is stored
+	if user.Id == "" {
+		user.Id = uuid.New().String()
+	}
+	user.Version = 1
+	user.Audit = port.NewAudit(ctx)
 
 	if err := r.base.Create(ctx, user); err != nil {
 		return nil, err
@@ -30,15 +35,15 @@ func (r *GormUserRepository) CreateUser(ctx context.Context, user *domain.Users)
 
 func (r *GormUserRepository) GetUserById(ctx context.Context, id string) (*domain.Users, error) {
 	var user domain.Users
-	if err := r.base.db.WithContext(ctx).Preload("Bookings").Where("user_id = ?", id).First(&user).Error; err != nil {
-		return nil, err
+	if err := live(ctx, r.base.db).Preload("Bookings", notDeleted).Where("user_id = ?", id).First(&user).Error; err != nil {
+		return nil, translateError(err)
 	}
 	return &user, nil
 }
 
 func (r *GormUserRepository) GetUsersByRole(ctx context.Context, role string) ([]domain.Users, error) {
 	var users []domain.Users
-	if err := r.base.db.WithContext(ctx).Preload("Bookings").Where("role = ?", role).Find(&users).Error; err != nil {
+	if err := live(ctx, r.base.db).Preload("Bookings", notDeleted).Where("role = ?", role).Find(&users).Error; err != nil {
 		return nil, err
 	}
 	return users, nil
@@ -46,8 +51,8 @@ func (r *GormUserRepository) GetUsersByRole(ctx context.Context, role string) ([
 
 func (r *GormUserRepository) GetUserByUsername(ctx context.Context, username string) (*domain.Users, error) {
 	var user domain.Users
-	if err := r.base.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
-		return nil, err
+	if err := live(ctx, r.base.db).Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, translateError(err)
 	}
 	return &user, nil
 }
@@ -58,7 +63,13 @@ func (r *GormUserRepository) UpdateUser(ctx context.Context, id string, user *do
 		return nil, err
 	}
 
-	if err := r.base.db.WithContext(ctx).Model(existingUser).Updates(user).Error; err != nil {
+	if err := domain.CheckVersion(user.Version, existingUser.Version); err != nil {
+		return nil, err
+	}
+
+	user.Version = existingUser.Version + 1
+	user.Audit = port.UpdateAudit(ctx)
+	if err := updateVersioned(live(ctx, r.base.db), existingUser, existingUser.Version, user); err != nil {
 		return nil, err
 	}
 
@@ -66,5 +77,5 @@ func (r *GormUserRepository) UpdateUser(ctx context.Context, id string, user *do
 }
 
 func (r *GormUserRepository) DeleteUser(ctx context.Context, id string) error {
-	return r.base.db.WithContext(ctx).Where("user_id = ?", id).Delete(&domain.Users{}).Error
+	return softDelete(ctx, r.base.db, &domain.Users{}, "user_id", id)
 }
@@ -0,0 +1,55 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewGormVerificationRepository(db *gorm.DB) *GormVerificationRepository {
+	return &GormVerificationRepository{db: db}
+}
+
+func (r *GormVerificationRepository) SaveVerification(ctx context.Context, verification *domain.ContactVerification) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	verification.Audit = port.NewAudit(ctx)
+	return translateError(conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "verification_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"target", "code_hash", "attempts", "expires_at", "updated_at", "updated_by"}),
+	}).Create(verification).Error)
+}
+
+func (r *GormVerificationRepository) GetVerification(ctx context.Context, key string) (*domain.ContactVerification, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var verification domain.ContactVerification
+	if err := conn(ctx, r.db).Where("verification_key = ?", key).First(&verification).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &verification, nil
+}
+
+func (r *GormVerificationRepository) DeleteVerification(ctx context.Context, key string) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result := conn(ctx, r.db).Where("verification_key = ?", key).Delete(&domain.ContactVerification{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
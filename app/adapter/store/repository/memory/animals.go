@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// animalSortKeys whitelists the fields GetAnimals can be sorted by
+var animalSortKeys = sortKeys[domain.Animals]{
+	"animal_id":     by(func(a domain.Animals) string { return a.Id }),
+	"name":          by(func(a domain.Animals) string { return a.Name }),
+	"species":       by(func(a domain.Animals) string { return a.Species }),
+	"type":          by(func(a domain.Animals) string { return a.Type }),
+	"show_duration": by(func(a domain.Animals) int { return a.ShowDuration }),
+	"created_at":    by(func(a domain.Animals) int64 { return a.CreatedAt.UnixNano() }),
+	"updated_at":    by(func(a domain.Animals) int64 { return a.UpdatedAt.UnixNano() }),
+}
+
+type MemoryAnimalRepository struct {
+	store *Store
+}
+
+func NewMemoryAnimalRepository(store *Store) *MemoryAnimalRepository {
+	return &MemoryAnimalRepository{store: store}
+}
+
+func (r *MemoryAnimalRepository) GetAnimals(ctx context.Context, filter port.AnimalFilter, query port.ListQuery) (*port.Page[domain.Animals], error) {
+	r.store.mu.RLock()
+	var animals []domain.Animals
+	for _, animal := range r.store.animals {
+		if animal.DeletedAt != nil || !filter.Audit.Matches(animal.Audit) {
+			continue
+		}
+		if filter.Species != "" && animal.Species != filter.Species {
+			continue
+		}
+		if filter.Type != "" && animal.Type != filter.Type {
+			continue
+		}
+		animals = append(animals, animal)
+	}
+	r.store.mu.RUnlock()
+
+	return findPage(animals, query, animalSortKeys, "animal_id")
+}
+
+func (r *MemoryAnimalRepository) CreateAnimal(ctx context.Context, animal *domain.Animals) (*domain.Animals, error) {
+	defer r.store.lock(ctx)()
+
+	// Generate UUID for new animal
+	animal.Id = uuid.New().String()
+	animal.Version = 1
+	animal.Audit = port.NewAudit(ctx)
+	r.store.animals[animal.Id] = *animal
+	return animal, nil
+}
+
+func (r *MemoryAnimalRepository) GetAnimalById(ctx context.Context, id string) (*domain.Animals, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	animal, ok := live(r.store.animals, id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &animal, nil
+}
+
+func (r *MemoryAnimalRepository) UpdateAnimal(ctx context.Context, id string, animal *domain.Animals) (*domain.Animals, error) {
+	defer r.store.lock(ctx)()
+
+	existingAnimal, ok := live(r.store.animals, id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if err := domain.CheckVersion(animal.Version, existingAnimal.Version); err != nil {
+		return nil, err
+	}
+
+	// Like GORM's Updates, zero values leave the stored field untouched
+	if animal.Name != "" {
+		existingAnimal.Name = animal.Name
+	}
+	if animal.Species != "" {
+		existingAnimal.Species = animal.Species
+	}
+	if animal.Type != "" {
+		existingAnimal.Type = animal.Type
+	}
+	if animal.ShowDuration != 0 {
+		existingAnimal.ShowDuration = animal.ShowDuration
+	}
+
+	existingAnimal.Version++
+	existingAnimal.Touch(port.UpdateAudit(ctx))
+	r.store.animals[id] = existingAnimal
+	return &existingAnimal, nil
+}
+
+func (r *MemoryAnimalRepository) DeleteAnimal(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	// Soft delete: the animal stays in the store, hidden from every query, until it is purged
+	animal, ok := live(r.store.animals, id)
+	if !ok {
+		return nil
+	}
+	change := port.UpdateAudit(ctx)
+	animal.DeletedAt = &change.UpdatedAt
+	animal.Version++
+	animal.Touch(change)
+	r.store.animals[id] = animal
+	return nil
+}
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type MemoryApiKeyRepository struct {
+	store *Store
+}
+
+func NewMemoryApiKeyRepository(store *Store) *MemoryApiKeyRepository {
+	return &MemoryApiKeyRepository{store: store}
+}
+
+func (r *MemoryApiKeyRepository) CreateApiClient(ctx context.Context, client *domain.ApiClient) (*domain.ApiClient, error) {
+	defer r.store.lock(ctx)()
+
+	for _, existing := range r.store.apiClients {
+		if existing.Name == client.Name {
+			return nil, domain.ErrAlreadyExists
+		}
+	}
+	client.Id = uuid.New().String()
+	client.Audit = port.NewAudit(ctx)
+	r.store.apiClients[client.Id] = *client
+	return client, nil
+}
+
+func (r *MemoryApiKeyRepository) GetApiClientById(ctx context.Context, id string) (*domain.ApiClient, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	client, ok := r.store.apiClients[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &client, nil
+}
+
+func (r *MemoryApiKeyRepository) ListApiClients(ctx context.Context) ([]domain.ApiClient, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	clients := make([]domain.ApiClient, 0, len(r.store.apiClients))
+	for _, client := range r.store.apiClients {
+		clients = append(clients, client)
+	}
+	slices.SortFunc(clients, func(a, b domain.ApiClient) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return clients, nil
+}
+
+func (r *MemoryApiKeyRepository) CreateApiKey(ctx context.Context, key *domain.ApiKey) (*domain.ApiKey, error) {
+	defer r.store.lock(ctx)()
+
+	for _, existing := range r.store.apiKeys {
+		if existing.KeyHash == key.KeyHash {
+			return nil, domain.ErrAlreadyExists
+		}
+	}
+	key.Id = uuid.New().String()
+	key.Audit = port.NewAudit(ctx)
+	stored := *key
+	stored.Scopes = slices.Clone(key.Scopes)
+	r.store.apiKeys[key.Id] = stored
+	return key, nil
+}
+
+func (r *MemoryApiKeyRepository) GetApiKeyById(ctx context.Context, id string) (*domain.ApiKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	key.Scopes = slices.Clone(key.Scopes)
+	return &key, nil
+}
+
+func (r *MemoryApiKeyRepository) GetApiKeyByHash(ctx context.Context, keyHash string) (*domain.ApiKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, key := range r.store.apiKeys {
+		if key.KeyHash == keyHash {
+			key.Scopes = slices.Clone(key.Scopes)
+			return &key, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *MemoryApiKeyRepository) ListApiKeys(ctx context.Context, clientId string) ([]domain.ApiKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	keys := []domain.ApiKey{}
+	for _, key := range r.store.apiKeys {
+		if key.ClientId == clientId {
+			key.Scopes = slices.Clone(key.Scopes)
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b domain.ApiKey) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	return keys, nil
+}
+
+func (r *MemoryApiKeyRepository) ExpireApiKey(ctx context.Context, id string, expiresAt time.Time) error {
+	defer r.store.lock(ctx)()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if expiresAt.Before(key.ExpiresAt) {
+		key.ExpiresAt = expiresAt
+		key.Touch(port.UpdateAudit(ctx))
+		r.store.apiKeys[id] = key
+	}
+	return nil
+}
+
+func (r *MemoryApiKeyRepository) RevokeApiKey(ctx context.Context, id string, revokedAt time.Time) error {
+	defer r.store.lock(ctx)()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok || key.RevokedAt != nil {
+		return domain.ErrNotFound
+	}
+	key.RevokedAt = &revokedAt
+	key.Touch(port.UpdateAudit(ctx))
+	r.store.apiKeys[id] = key
+	return nil
+}
+
+func (r *MemoryApiKeyRepository) TouchApiKey(ctx context.Context, id string, usedAt time.Time) error {
+	defer r.store.lock(ctx)()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	key.LastUsedAt = &usedAt
+	r.store.apiKeys[id] = key
+	return nil
+}
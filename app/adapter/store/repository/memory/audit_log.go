@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"maps"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// auditLogSortKeys whitelists the fields ListAuditLog can be sorted by
+var auditLogSortKeys = sortKeys[domain.AuditLogEntry]{
+	"entry_id":    by(func(e domain.AuditLogEntry) string { return e.Id }),
+	"occurred_at": by(func(e domain.AuditLogEntry) int64 { return e.OccurredAt.UnixNano() }),
+	"actor":       by(func(e domain.AuditLogEntry) string { return e.Actor }),
+	"action":      by(func(e domain.AuditLogEntry) string { return e.Action }),
+	"entity_type": by(func(e domain.AuditLogEntry) string { return e.EntityType }),
+}
+
+// MemoryAuditLogRepository keeps the audit log in the store; entries are never changed once appended
+type MemoryAuditLogRepository struct {
+	store *Store
+}
+
+func NewMemoryAuditLogRepository(store *Store) *MemoryAuditLogRepository {
+	return &MemoryAuditLogRepository{store: store}
+}
+
+func (r *MemoryAuditLogRepository) Append(ctx context.Context, entry *domain.AuditLogEntry) error {
+	entry.Id = uuid.New().String()
+
+	defer r.store.lock(ctx)()
+
+	r.store.auditLog = append(r.store.auditLog, copyAuditLogEntry(*entry))
+	return nil
+}
+
+func (r *MemoryAuditLogRepository) ListAuditLog(ctx context.Context, filter port.AuditLogFilter, query port.ListQuery) (*port.Page[domain.AuditLogEntry], error) {
+	r.store.mu.RLock()
+	var entries []domain.AuditLogEntry
+	for _, entry := range r.store.auditLog {
+		if filter.Matches(entry) {
+			entries = append(entries, copyAuditLogEntry(entry))
+		}
+	}
+	r.store.mu.RUnlock()
+
+	return findPage(entries, query, auditLogSortKeys, "entry_id")
+}
+
+// copyAuditLogEntry copies the diffs of an entry so that neither the caller nor the store can change the other's copy
+func copyAuditLogEntry(entry domain.AuditLogEntry) domain.AuditLogEntry {
+	entry.Before = maps.Clone(entry.Before)
+	entry.After = maps.Clone(entry.After)
+	return entry
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// sortKeys maps each sortable field of a list call to a comparison of two items by that field
+type sortKeys[T any] map[string]func(a, b T) int
+
+// findPage orders the matched items and cuts one page out of them.
+// keys whitelists the sortable fields; tieBreaker keeps the ordering stable between pages.
+func findPage[T any](items []T, query port.ListQuery, keys sortKeys[T], tieBreaker string) (*port.Page[T], error) {
+	query, err := query.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]string, len(keys))
+	for field := range keys {
+		columns[field] = field
+	}
+	order, err := query.SortColumns(columns, tieBreaker)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(items, func(a, b T) int {
+		for _, field := range order {
+			c := keys[field.Field](a, b)
+			if field.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	start := min(query.Offset, len(items))
+	end := min(start+query.Limit, len(items))
+	return port.NewPage(items[start:end], int64(len(items)), query), nil
+}
+
+// by builds a sort key comparing one field of an item
+func by[T any, V cmp.Ordered](field func(T) V) func(a, b T) int {
+	return func(a, b T) int {
+		return cmp.Compare(field(a), field(b))
+	}
+}
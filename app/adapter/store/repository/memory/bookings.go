@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// bookingSortKeys whitelists the fields ListBookings can be sorted by
+var bookingSortKeys = sortKeys[domain.Bookings]{
+	"booking_id":  by(func(b domain.Bookings) string { return b.Id }),
+	"user_id":     by(func(b domain.Bookings) string { return b.UserId }),
+	"round_id":    by(func(b domain.Bookings) string { return b.RoundId }),
+	"seat_number": by(func(b domain.Bookings) int { return b.SeatNumber }),
+	"price":       by(func(b domain.Bookings) float64 { return b.Price }),
+	"status":      by(func(b domain.Bookings) string { return b.Status }),
+	"created_at":  by(func(b domain.Bookings) int64 { return b.CreatedAt.UnixNano() }),
+	"updated_at":  by(func(b domain.Bookings) int64 { return b.UpdatedAt.UnixNano() }),
+}
+
+type MemoryBookingRepository struct {
+	store *Store
+}
+
+func NewMemoryBookingRepository(store *Store) *MemoryBookingRepository {
+	return &MemoryBookingRepository{store: store}
+}
+
+func (r *MemoryBookingRepository) CreateBooking(ctx context.Context, booking *domain.Bookings) (*domain.Bookings, error) {
+	defer r.store.lock(ctx)()
+
+	// Generate UUID for new booking
+	booking.Id = uuid.New().String()
+	booking.Version = 1
+	booking.Audit = port.NewAudit(ctx)
+	if booking.Status == "" {
+		booking.Status = domain.BookingStatusConfirmed
+	}
+	r.store.bookings[booking.Id] = *booking
+	return booking, nil
+}
+
+func (r *MemoryBookingRepository) GetBookingById(ctx context.Context, id string) (*domain.Bookings, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	booking, ok := live(r.store.bookings, id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &booking, nil
+}
+
+func (r *MemoryBookingRepository) GetBookingsByUserId(ctx context.Context, userId string) ([]domain.Bookings, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.bookingsWhere(func(booking domain.Bookings) bool {
+		return booking.UserId == userId
+	}), nil
+}
+
+func (r *MemoryBookingRepository) GetBookingsByRoundId(ctx context.Context, roundId string) ([]domain.Bookings, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.bookingsWhere(func(booking domain.Bookings) bool {
+		return booking.RoundId == roundId
+	}), nil
+}
+
+func (r *MemoryBookingRepository) ListBookings(ctx context.Context, filter port.BookingFilter, query port.ListQuery) (*port.Page[domain.Bookings], error) {
+	r.store.mu.RLock()
+	bookings := r.store.bookingsWhere(func(booking domain.Bookings) bool {
+		return (filter.UserId == "" || booking.UserId == filter.UserId) &&
+			(filter.RoundId == "" || booking.RoundId == filter.RoundId) &&
+			(filter.Status == "" || booking.Status == filter.Status) &&
+			filter.Audit.Matches(booking.Audit)
+	})
+	r.store.mu.RUnlock()
+
+	return findPage(bookings, query, bookingSortKeys, "booking_id")
+}
+
+func (r *MemoryBookingRepository) UpdateBooking(ctx context.Context, id string, booking *domain.Bookings) (*domain.Bookings, error) {
+	defer r.store.lock(ctx)()
+
+	existingBooking, ok := live(r.store.bookings, id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if err := domain.CheckVersion(booking.Version, existingBooking.Version); err != nil {
+		return nil, err
+	}
+
+	// Like GORM's Updates, zero values leave the stored field untouched
+	if booking.UserId != "" {
+		existingBooking.UserId = booking.UserId
+	}
+	if booking.RoundId != "" {
+		existingBooking.RoundId = booking.RoundId
+	}
+	if booking.SeatNumber != 0 {
+		existingBooking.SeatNumber = booking.SeatNumber
+	}
+	if booking.Price != 0 {
+		existingBooking.Price = booking.Price
+	}
+	if booking.QrCode != "" {
+		existingBooking.QrCode = booking.QrCode
+	}
+	if booking.Status != "" {
+		existingBooking.Status = booking.Status
+	}
+
+	existingBooking.Version++
+	existingBooking.Touch(port.UpdateAudit(ctx))
+	r.store.bookings[id] = existingBooking
+	return &existingBooking, nil
+}
+
+func (r *MemoryBookingRepository) DeleteBooking(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	// Soft delete: the booking stays in the store, hidden from every query, until it is purged
+	booking, ok := live(r.store.bookings, id)
+	if !ok {
+		return nil
+	}
+	change := port.UpdateAudit(ctx)
+	booking.DeletedAt = &change.UpdatedAt
+	booking.Version++
+	booking.Touch(change)
+	r.store.bookings[id] = booking
+	return nil
+}
+
+// bookingsWhere returns copies of the live bookings accepted by match; the caller must hold the store lock
+func (s *Store) bookingsWhere(match func(domain.Bookings) bool) []domain.Bookings {
+	var bookings []domain.Bookings
+	for _, booking := range s.bookings {
+		if booking.DeletedAt == nil && match(booking) {
+			bookings = append(bookings, booking)
+		}
+	}
+	return bookings
+}
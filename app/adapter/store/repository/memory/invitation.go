@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type MemoryInvitationRepository struct {
+	store *Store
+}
+
+func NewMemoryInvitationRepository(store *Store) *MemoryInvitationRepository {
+	return &MemoryInvitationRepository{store: store}
+}
+
+func (r *MemoryInvitationRepository) CreateInvitation(ctx context.Context, invitation *domain.Invitation) (*domain.Invitation, error) {
+	defer r.store.lock(ctx)()
+
+	invitation.Id = uuid.New().String()
+	invitation.Audit = port.NewAudit(ctx)
+	r.store.invitations[invitation.Id] = *invitation
+	return invitation, nil
+}
+
+func (r *MemoryInvitationRepository) GetInvitationById(ctx context.Context, id string) (*domain.Invitation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	invitation, ok := r.store.invitations[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &invitation, nil
+}
+
+func (r *MemoryInvitationRepository) AcceptInvitation(ctx context.Context, id string, userId string, acceptedAt time.Time) error {
+	defer r.store.lock(ctx)()
+
+	invitation, ok := r.store.invitations[id]
+	if !ok || invitation.AcceptedAt != nil {
+		return domain.ErrNotFound
+	}
+	invitation.AcceptedAt = &acceptedAt
+	invitation.AcceptedBy = userId
+	invitation.Touch(port.UpdateAudit(ctx))
+	r.store.invitations[id] = invitation
+	return nil
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+type MemoryLoginAttemptRepository struct {
+	store *Store
+}
+
+func NewMemoryLoginAttemptRepository(store *Store) *MemoryLoginAttemptRepository {
+	return &MemoryLoginAttemptRepository{store: store}
+}
+
+func (r *MemoryLoginAttemptRepository) GetLoginAttempts(ctx context.Context, keys []string) ([]domain.LoginAttempt, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	attempts := []domain.LoginAttempt{}
+	for _, key := range keys {
+		if attempt, ok := r.store.logins[key]; ok {
+			attempts = append(attempts, attempt)
+		}
+	}
+	return attempts, nil
+}
+
+func (r *MemoryLoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, at time.Time, resetBefore time.Time) (*domain.LoginAttempt, error) {
+	defer r.store.lock(ctx)()
+
+	attempt, ok := r.store.logins[key]
+	if !ok || attempt.LastFailureAt.Before(resetBefore) {
+		attempt.Key = key
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = at
+	r.store.logins[key] = attempt
+	return &attempt, nil
+}
+
+func (r *MemoryLoginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	defer r.store.lock(ctx)()
+
+	attempt, ok := r.store.logins[key]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	attempt.LockedUntil = &until
+	r.store.logins[key] = attempt
+	return nil
+}
+
+func (r *MemoryLoginAttemptRepository) ClearLoginAttempts(ctx context.Context, key string) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.logins[key]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.store.logins, key)
+	return nil
+}
+
+func (r *MemoryLoginAttemptRepository) ListLoginLockouts(ctx context.Context, now time.Time) ([]domain.LoginAttempt, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	locked := sortedValues(r.store.logins, func(a domain.LoginAttempt) string { return a.Key })
+	locked = slices.DeleteFunc(locked, func(a domain.LoginAttempt) bool {
+		return a.LockedUntil == nil || !a.LockedUntil.After(now)
+	})
+	return locked, nil
+}
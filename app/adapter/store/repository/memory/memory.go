@@ -0,0 +1,22 @@
+package memory
+
+import (
+	"log"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
+)
+
+// InitMemoryStore initializes the in-memory store, seeding it from the configured snapshot if there is one
+func InitMemoryStore(cfg *config.Config) *Store {
+	store := NewStore()
+
+	if path := cfg.Memory.SnapshotPath; path != "" {
+		if err := store.Load(path); err != nil {
+			log.Fatal("Failed to load memory snapshot:", err)
+		}
+		log.Printf("Loaded memory snapshot from %s", path)
+	}
+
+	log.Println("Using in-memory store, data is kept only for the lifetime of the process unless a snapshot path is set")
+	return store
+}
@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/repository/repositorytest"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRepositories(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		store := NewStore()
+		return repositorytest.Repositories{
+			Users:          NewMemoryUserRepository(store),
+			Animals:        NewMemoryAnimalRepository(store),
+			Stages:         NewMemoryPerformanceStageRepository(store),
+			ShowRounds:     NewMemoryShowRoundRepository(store),
+			Bookings:       NewMemoryBookingRepository(store),
+			UnitOfWork:     NewMemoryUnitOfWork(store),
+			Trash:          NewMemoryTrashRepository(store),
+			AuditLog:       NewMemoryAuditLogRepository(store),
+			Invitations:    NewMemoryInvitationRepository(store),
+			LoginAttempts:  NewMemoryLoginAttemptRepository(store),
+			Mfa:            NewMemoryMfaRepository(store),
+			PasswordResets: NewMemoryPasswordResetRepository(store),
+			Sessions:       NewMemorySessionRepository(store),
+			Verifications:  NewMemoryVerificationRepository(store),
+			SigningKeys:    NewMemorySigningKeyRepository(store),
+			ApiKeys:        NewMemoryApiKeyRepository(store),
+			Oidc:           NewMemoryOidcRepository(store),
+		}
+	})
+}
+
+func TestMemoryUserRepository(t *testing.T) {
+	ctx := context.Background()
+	userRepo := NewMemoryUserRepository(NewStore())
+
+	alice, err := userRepo.CreateUser(ctx, &domain.Users{Username: "alice", Password: "hash"})
+	require.NoError(t, err)
+
+	t.Run("rename onto taken username", func(t *testing.T) {
+		bob, err := userRepo.CreateUser(ctx, &domain.Users{Username: "bob"})
+		require.NoError(t, err)
+
+		_, err = userRepo.UpdateUser(ctx, bob.Id, &domain.Users{Username: "alice"})
+
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	})
+
+	t.Run("returned values are copies", func(t *testing.T) {
+		result, err := userRepo.GetUserById(ctx, alice.Id)
+		require.NoError(t, err)
+		result.Role = "admin"
+
+		stored, err := userRepo.GetUserById(ctx, alice.Id)
+
+		require.NoError(t, err)
+		assert.Equal(t, "user", stored.Role)
+	})
+}
+
+func TestMemoryStoreSnapshot(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	store := NewStore()
+	user, err := NewMemoryUserRepository(store).CreateUser(ctx, &domain.Users{Username: "alice"})
+	require.NoError(t, err)
+	stage, err := NewMemoryPerformanceStageRepository(store).CreateStage(ctx, &domain.PerformanceStage{RoomNumber: "A1", SeatCapacity: 40})
+	require.NoError(t, err)
+	require.NoError(t, store.Save(path))
+
+	restored := NewStore()
+	require.NoError(t, restored.Load(path))
+
+	restoredUser, err := NewMemoryUserRepository(restored).GetUserByUsername(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, user.Id, restoredUser.Id)
+
+	restoredStage, err := NewMemoryPerformanceStageRepository(restored).GetStageById(ctx, stage.Id)
+	require.NoError(t, err)
+	assert.Equal(t, 40, restoredStage.SeatCapacity)
+
+	t.Run("missing file starts empty", func(t *testing.T) {
+		empty := NewStore()
+
+		assert.NoError(t, empty.Load(filepath.Join(t.TempDir(), "absent.json")))
+		assert.Empty(t, empty.users)
+	})
+}
+
+func TestMemoryConcurrentCreates(t *testing.T) {
+	ctx := context.Background()
+	userRepo := NewMemoryUserRepository(NewStore())
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Every goroutine races for the same username; exactly one may win
+			_, err := userRepo.CreateUser(ctx, &domain.Users{Username: "contended"})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	}
+	assert.Equal(t, 1, created)
+}
+
+func TestMemoryUnitOfWorkKeepsConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	unitOfWork := NewMemoryUnitOfWork(store)
+	animalRepo := NewMemoryAnimalRepository(store)
+
+	started := make(chan struct{})
+	written := make(chan error)
+	err := unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if _, err := animalRepo.CreateAnimal(ctx, &domain.Animals{Name: "rolled back"}); err != nil {
+			return err
+		}
+		go func() {
+			close(started)
+			// Written while the unit of work runs; it must survive the rollback
+			_, err := animalRepo.CreateAnimal(context.Background(), &domain.Animals{Name: "concurrent"})
+			written <- err
+		}()
+		<-started
+		time.Sleep(20 * time.Millisecond)
+		return assert.AnError
+	})
+	require.ErrorIs(t, err, assert.AnError)
+	require.NoError(t, <-written)
+
+	names := []string{}
+	for _, animal := range store.animals {
+		names = append(names, animal.Name)
+	}
+	assert.Equal(t, []string{"concurrent"}, names)
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// MemoryMfaRepository never modifies the recovery codes of a stored enrollment in place, so that the
+// shallow copies taken by MemoryUnitOfWork stay intact
+type MemoryMfaRepository struct {
+	store *Store
+}
+
+func NewMemoryMfaRepository(store *Store) *MemoryMfaRepository {
+	return &MemoryMfaRepository{store: store}
+}
+
+func (r *MemoryMfaRepository) GetMfaEnrollment(ctx context.Context, userId string) (*domain.MfaEnrollment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	enrollment, ok := r.store.mfa[userId]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	enrollment.RecoveryCodes = slices.Clone(enrollment.RecoveryCodes)
+	return &enrollment, nil
+}
+
+func (r *MemoryMfaRepository) SaveMfaEnrollment(ctx context.Context, enrollment *domain.MfaEnrollment) error {
+	defer r.store.lock(ctx)()
+
+	audit := port.UpdateAudit(ctx)
+	if existing, ok := r.store.mfa[enrollment.UserId]; ok {
+		audit.CreatedAt, audit.CreatedBy = existing.CreatedAt, existing.CreatedBy
+	} else {
+		audit = port.NewAudit(ctx)
+	}
+	enrollment.Audit = audit
+
+	stored := *enrollment
+	stored.RecoveryCodes = slices.Clone(enrollment.RecoveryCodes)
+	r.store.mfa[enrollment.UserId] = stored
+	return nil
+}
+
+func (r *MemoryMfaRepository) DeleteMfaEnrollment(ctx context.Context, userId string) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.mfa[userId]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.store.mfa, userId)
+	return nil
+}
+
+func (r *MemoryMfaRepository) UseMfaStep(ctx context.Context, userId string, step int64) error {
+	defer r.store.lock(ctx)()
+
+	enrollment, ok := r.store.mfa[userId]
+	if !ok || !enrollment.Confirmed() || enrollment.LastStep >= step {
+		return domain.ErrNotFound
+	}
+	enrollment.LastStep = step
+	r.store.mfa[userId] = enrollment
+	return nil
+}
+
+func (r *MemoryMfaRepository) UseRecoveryCode(ctx context.Context, userId string, codeHash string) error {
+	defer r.store.lock(ctx)()
+
+	enrollment, ok := r.store.mfa[userId]
+	if !ok || !slices.Contains(enrollment.RecoveryCodes, codeHash) {
+		return domain.ErrNotFound
+	}
+	enrollment.RecoveryCodes = slices.DeleteFunc(slices.Clone(enrollment.RecoveryCodes), func(hash string) bool {
+		return hash == codeHash
+	})
+	r.store.mfa[userId] = enrollment
+	return nil
+}
+
+func (r *MemoryMfaRepository) GetMfaRequirement(ctx context.Context, role string) (*domain.MfaRequirement, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	requirement, ok := r.store.mfaRoles[role]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &requirement, nil
+}
+
+func (r *MemoryMfaRepository) ListMfaRequirements(ctx context.Context) ([]domain.MfaRequirement, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return sortedValues(r.store.mfaRoles, func(r domain.MfaRequirement) string { return r.Role }), nil
+}
+
+func (r *MemoryMfaRepository) SaveMfaRequirement(ctx context.Context, requirement *domain.MfaRequirement) error {
+	defer r.store.lock(ctx)()
+
+	if existing, ok := r.store.mfaRoles[requirement.Role]; ok {
+		*requirement = existing
+		return nil
+	}
+	requirement.Audit = port.NewAudit(ctx)
+	r.store.mfaRoles[requirement.Role] = *requirement
+	return nil
+}
+
+func (r *MemoryMfaRepository) DeleteMfaRequirement(ctx context.Context, role string) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.mfaRoles[role]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.store.mfaRoles, role)
+	return nil
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type MemoryOidcRepository struct {
+	store *Store
+}
+
+func NewMemoryOidcRepository(store *Store) *MemoryOidcRepository {
+	return &MemoryOidcRepository{store: store}
+}
+
+func (r *MemoryOidcRepository) CreateOidcLogin(ctx context.Context, login *domain.OidcLogin) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.oidcLogins[login.StateHash]; ok {
+		return domain.ErrAlreadyExists
+	}
+	login.Audit = port.NewAudit(ctx)
+	r.store.oidcLogins[login.StateHash] = *login
+	return nil
+}
+
+func (r *MemoryOidcRepository) TakeOidcLogin(ctx context.Context, stateHash string) (*domain.OidcLogin, error) {
+	defer r.store.lock(ctx)()
+
+	login, ok := r.store.oidcLogins[stateHash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	delete(r.store.oidcLogins, stateHash)
+	return &login, nil
+}
+
+func (r *MemoryOidcRepository) CreateOidcIdentity(ctx context.Context, identity *domain.OidcIdentity) (*domain.OidcIdentity, error) {
+	defer r.store.lock(ctx)()
+
+	for _, existing := range r.store.oidcIds {
+		if existing.Provider == identity.Provider && existing.Subject == identity.Subject {
+			return nil, domain.ErrAlreadyExists
+		}
+	}
+	identity.Id = uuid.New().String()
+	identity.Audit = port.NewAudit(ctx)
+	r.store.oidcIds[identity.Id] = *identity
+	return identity, nil
+}
+
+func (r *MemoryOidcRepository) GetOidcIdentity(ctx context.Context, provider string, subject string) (*domain.OidcIdentity, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, identity := range r.store.oidcIds {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *MemoryOidcRepository) ListOidcIdentities(ctx context.Context, userId string) ([]domain.OidcIdentity, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	identities := []domain.OidcIdentity{}
+	for _, identity := range r.store.oidcIds {
+		if identity.UserId == userId {
+			identities = append(identities, identity)
+		}
+	}
+	slices.SortFunc(identities, func(a, b domain.OidcIdentity) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	return identities, nil
+}
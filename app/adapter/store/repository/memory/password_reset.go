@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type MemoryPasswordResetRepository struct {
+	store *Store
+}
+
+func NewMemoryPasswordResetRepository(store *Store) *MemoryPasswordResetRepository {
+	return &MemoryPasswordResetRepository{store: store}
+}
+
+func (r *MemoryPasswordResetRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.resets[token.TokenHash]; ok {
+		return domain.ErrAlreadyExists
+	}
+	token.Audit = port.NewAudit(ctx)
+	r.store.resets[token.TokenHash] = *token
+	return nil
+}
+
+func (r *MemoryPasswordResetRepository) TakePasswordResetToken(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	defer r.store.lock(ctx)()
+
+	token, ok := r.store.resets[tokenHash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	delete(r.store.resets, tokenHash)
+	return &token, nil
+}
+
+func (r *MemoryPasswordResetRepository) DeletePasswordResetTokens(ctx context.Context, userId string) error {
+	defer r.store.lock(ctx)()
+
+	for hash, token := range r.store.resets {
+		if token.UserId == userId {
+			delete(r.store.resets, hash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// stageSortKeys whitelists the fields GetStages can be sorted by
+var stageSortKeys = sortKeys[domain.PerformanceStage]{
+	"stage_id":       by(func(s domain.PerformanceStage) string { return s.Id }),
+	"room_number":    by(func(s domain.PerformanceStage) string { return s.RoomNumber }),
+	"seat_capacity":  by(func(s domain.PerformanceStage) int { return s.SeatCapacity }),
+	"price_per_seat": by(func(s domain.PerformanceStage) float64 { return s.PricePerSeat }),
+	"created_at":     by(func(p domain.PerformanceStage) int64 { return p.CreatedAt.UnixNano() }),
+	"updated_at":     by(func(p domain.PerformanceStage) int64 { return p.UpdatedAt.UnixNano() }),
+}
+
+type MemoryPerformanceStageRepository struct {
+	store *Store
+}
+
+func NewMemoryPerformanceStageRepository(store *Store) *MemoryPerformanceStageRepository {
+	return &MemoryPerformanceStageRepository{store: store}
+}
+
+func (r *MemoryPerformanceStageRepository) GetStages(ctx context.Context, filter port.StageFilter, query port.ListQuery) (*port.Page[domain.PerformanceStage], error) {
+	r.store.mu.RLock()
+	var stages []domain.PerformanceStage
+	for _, stage := range r.store.stages {
+		if stage.DeletedAt != nil || !filter.Audit.Matches(stage.Audit) {
+			continue
+		}
+		if filter.RoomNumber != "" && stage.RoomNumber != filter.RoomNumber {
+			continue
+		}
+		if filter.MinSeatCapacity > 0 && stage.SeatCapacity < filter.MinSeatCapacity {
+			continue
+		}
+		stages = append(stages, stage)
+	}
+	r.store.mu.RUnlock()
+
+	return findPage(stages, query, stageSortKeys, "stage_id")
+}
+
+func (r *MemoryPerformanceStageRepository) CreateStage(ctx context.Context, stage *domain.PerformanceStage) (*domain.PerformanceStage, error) {
+	defer r.store.lock(ctx)()
+
+	// Generate UUID for new stage
+	stage.Id = uuid.New().String()
+	stage.Version = 1
+	stage.Audit = port.NewAudit(ctx)
+	r.store.stages[stage.Id] = *stage
+	return stage, nil
+}
+
+func (r *MemoryPerformanceStageRepository) GetStageById(ctx context.Context, id string) (*domain.PerformanceStage, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	stage, ok := live(r.store.stages, id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &stage, nil
+}
+
+func (r *MemoryPerformanceStageRepository) UpdateStage(ctx context.Context, id string, stage *domain.PerformanceStage) (*domain.PerformanceStage, error) {
+	defer r.store.lock(ctx)()
+
+	existingStage, ok := live(r.store.stages, id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if err := domain.CheckVersion(stage.Version, existingStage.Version); err != nil {
+		return nil, err
+	}
+
+	// Like GORM's Updates, zero values leave the stored field untouched
+	if stage.RoomNumber != "" {
+		existingStage.RoomNumber = stage.RoomNumber
+	}
+	if stage.SeatCapacity != 0 {
+		existingStage.SeatCapacity = stage.SeatCapacity
+	}
+	if stage.PricePerSeat != 0 {
+		existingStage.PricePerSeat = stage.PricePerSeat
+	}
+
+	existingStage.Version++
+	existingStage.Touch(port.UpdateAudit(ctx))
+	r.store.stages[id] = existingStage
+	return &existingStage, nil
+}
+
+func (r *MemoryPerformanceStageRepository) DeleteStage(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	// Soft delete: the stage stays in the store, hidden from every query, until it is purged
+	stage, ok := live(r.store.stages, id)
+	if !ok {
+		return nil
+	}
+	change := port.UpdateAudit(ctx)
+	stage.DeletedAt = &change.UpdatedAt
+	stage.Version++
+	stage.Touch(change)
+	r.store.stages[id] = stage
+	return nil
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+type MemorySessionRepository struct {
+	store *Store
+}
+
+func NewMemorySessionRepository(store *Store) *MemorySessionRepository {
+	return &MemorySessionRepository{store: store}
+}
+
+func (r *MemorySessionRepository) RevokeSessions(ctx context.Context, userId string, at time.Time) error {
+	defer r.store.lock(ctx)()
+
+	r.store.sessions[userId] = domain.SessionRevocation{UserId: userId, RevokedAt: at}
+	return nil
+}
+
+func (r *MemorySessionRepository) GetSessionRevocation(ctx context.Context, userId string) (*domain.SessionRevocation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	revocation, ok := r.store.sessions[userId]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &revocation, nil
+}
+
+func (r *MemorySessionRepository) RevokeToken(ctx context.Context, token *domain.RevokedToken) error {
+	defer r.store.lock(ctx)()
+
+	now := time.Now()
+	for tokenId, revoked := range r.store.revokedTokens {
+		if !now.Before(revoked.ExpiresAt) {
+			delete(r.store.revokedTokens, tokenId)
+		}
+	}
+	r.store.revokedTokens[token.TokenId] = *token
+	return nil
+}
+
+func (r *MemorySessionRepository) IsTokenRevoked(ctx context.Context, tokenId string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.revokedTokens[tokenId]
+	return ok, nil
+}
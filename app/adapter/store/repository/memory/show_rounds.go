@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// showRoundSortKeys whitelists the fields GetAllShowRounds can be sorted by
+var showRoundSortKeys = sortKeys[*domain.ShowRounds]{
+	"round_id":   by(func(r *domain.ShowRounds) string { return r.Id }),
+	"animal_id":  by(func(r *domain.ShowRounds) string { return r.AnimalId }),
+	"stage_id":   by(func(r *domain.ShowRounds) string { return r.StageId }),
+	"show_time":  by(func(r *domain.ShowRounds) int64 { return showTime(r).UnixNano() }),
+	"created_at": by(func(r *domain.ShowRounds) int64 { return r.CreatedAt.UnixNano() }),
+	"updated_at": by(func(r *domain.ShowRounds) int64 { return r.UpdatedAt.UnixNano() }),
+}
+
+type MemoryShowRoundRepository struct {
+	store *Store
+}
+
+func NewMemoryShowRoundRepository(store *Store) *MemoryShowRoundRepository {
+	return &MemoryShowRoundRepository{store: store}
+}
+
+func (r *MemoryShowRoundRepository) CreateShowRound(ctx context.Context, showRound *domain.ShowRounds) (*domain.ShowRounds, error) {
+	defer r.store.lock(ctx)()
+
+	// Generate UUID for new show round
+	showRound.Id = uuid.New().String()
+	showRound.Version = 1
+	showRound.Audit = port.NewAudit(ctx)
+	stored := *showRound
+	stored.Bookings = nil
+	r.store.showRounds[showRound.Id] = stored
+	return showRound, nil
+}
+
+func (r *MemoryShowRoundRepository) GetShowRoundById(ctx context.Context, id string) (*domain.ShowRounds, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	showRound, ok := live(r.store.showRounds, id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &showRound, nil
+}
+
+func (r *MemoryShowRoundRepository) GetAllShowRounds(ctx context.Context, filter port.ShowRoundFilter, query port.ListQuery) (*port.Page[*domain.ShowRounds], error) {
+	r.store.mu.RLock()
+	var showRounds []*domain.ShowRounds
+	for _, showRound := range r.store.showRounds {
+		if showRound.DeletedAt != nil || !filter.Audit.Matches(showRound.Audit) {
+			continue
+		}
+		if filter.AnimalId != "" && showRound.AnimalId != filter.AnimalId {
+			continue
+		}
+		if filter.StageId != "" && showRound.StageId != filter.StageId {
+			continue
+		}
+		if filter.Species != "" {
+			if animal, ok := live(r.store.animals, showRound.AnimalId); !ok || animal.Species != filter.Species {
+				continue
+			}
+		}
+		if filter.From != nil && showTime(&showRound).Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && showTime(&showRound).After(*filter.To) {
+			continue
+		}
+		showRounds = append(showRounds, &showRound)
+	}
+	r.store.mu.RUnlock()
+
+	return findPage(showRounds, query, showRoundSortKeys, "round_id")
+}
+
+func (r *MemoryShowRoundRepository) GetShowRoundsByAnimalId(ctx context.Context, animalId string) ([]*domain.ShowRounds, error) {
+	return r.findShowRounds(func(showRound domain.ShowRounds) bool {
+		return showRound.AnimalId == animalId
+	}), nil
+}
+
+func (r *MemoryShowRoundRepository) GetShowRoundsByStageId(ctx context.Context, stageId string) ([]*domain.ShowRounds, error) {
+	return r.findShowRounds(func(showRound domain.ShowRounds) bool {
+		return showRound.StageId == stageId
+	}), nil
+}
+
+func (r *MemoryShowRoundRepository) UpdateShowRound(ctx context.Context, id string, showRound *domain.ShowRounds) (*domain.ShowRounds, error) {
+	defer r.store.lock(ctx)()
+
+	existingShowRound, ok := live(r.store.showRounds, id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if err := domain.CheckVersion(showRound.Version, existingShowRound.Version); err != nil {
+		return nil, err
+	}
+
+	// Like GORM's Updates, zero values leave the stored field untouched
+	if showRound.AnimalId != "" {
+		existingShowRound.AnimalId = showRound.AnimalId
+	}
+	if showRound.StageId != "" {
+		existingShowRound.StageId = showRound.StageId
+	}
+	if showRound.ShowTime != "" {
+		existingShowRound.ShowTime = showRound.ShowTime
+	}
+
+	existingShowRound.Version++
+	existingShowRound.Touch(port.UpdateAudit(ctx))
+	r.store.showRounds[id] = existingShowRound
+	return &existingShowRound, nil
+}
+
+func (r *MemoryShowRoundRepository) DeleteShowRound(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	// Soft delete: the showRound stays in the store, hidden from every query, until it is purged
+	showRound, ok := live(r.store.showRounds, id)
+	if !ok {
+		return nil
+	}
+	change := port.UpdateAudit(ctx)
+	showRound.DeletedAt = &change.UpdatedAt
+	showRound.Version++
+	showRound.Touch(change)
+	r.store.showRounds[id] = showRound
+	return nil
+}
+
+// findShowRounds returns copies of the live show rounds accepted by match
+func (r *MemoryShowRoundRepository) findShowRounds(match func(domain.ShowRounds) bool) []*domain.ShowRounds {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var showRounds []*domain.ShowRounds
+	for _, showRound := range r.store.showRounds {
+		if showRound.DeletedAt != nil {
+			continue
+		}
+		if match(showRound) {
+			showRounds = append(showRounds, &showRound)
+		}
+	}
+	return showRounds
+}
+
+// showTime parses the RFC3339 show time of a round; unparsable values are treated as the zero time
+func showTime(showRound *domain.ShowRounds) time.Time {
+	t, err := time.Parse(time.RFC3339, showRound.ShowTime)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
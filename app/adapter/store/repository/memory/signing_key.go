@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type MemorySigningKeyRepository struct {
+	store *Store
+}
+
+func NewMemorySigningKeyRepository(store *Store) *MemorySigningKeyRepository {
+	return &MemorySigningKeyRepository{store: store}
+}
+
+func (r *MemorySigningKeyRepository) CreateSigningKey(ctx context.Context, key *domain.SigningKey) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.signingKeys[key.Kid]; ok {
+		return domain.ErrAlreadyExists
+	}
+	key.Audit = port.NewAudit(ctx)
+	r.store.signingKeys[key.Kid] = *key
+	return nil
+}
+
+func (r *MemorySigningKeyRepository) ListSigningKeys(ctx context.Context) ([]domain.SigningKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	keys := make([]domain.SigningKey, 0, len(r.store.signingKeys))
+	for _, key := range r.store.signingKeys {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b domain.SigningKey) int {
+		if c := a.ActivatesAt.Compare(b.ActivatesAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Kid, b.Kid)
+	})
+	return keys, nil
+}
+
+func (r *MemorySigningKeyRepository) UpdateSigningKeyPrivateKey(ctx context.Context, kid string, privateKey string) error {
+	defer r.store.lock(ctx)()
+
+	key, ok := r.store.signingKeys[kid]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	key.PrivateKey = privateKey
+	key.Touch(port.UpdateAudit(ctx))
+	r.store.signingKeys[kid] = key
+	return nil
+}
+
+func (r *MemorySigningKeyRepository) DeleteExpiredSigningKeys(ctx context.Context, now time.Time) (int64, error) {
+	defer r.store.lock(ctx)()
+
+	var deleted int64
+	for kid, key := range r.store.signingKeys {
+		if key.Expired(now) {
+			delete(r.store.signingKeys, kid)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,251 @@
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// Store holds every collection of the in-memory backend.
+// All repositories created from one Store share a single lock so that reads spanning
+// several collections (user bookings, species filters) see a consistent view.
+type Store struct {
+	mu            sync.RWMutex
+	txMu          sync.Mutex
+	users         map[string]domain.Users
+	animals       map[string]domain.Animals
+	stages        map[string]domain.PerformanceStage
+	showRounds    map[string]domain.ShowRounds
+	bookings      map[string]domain.Bookings
+	auditLog      []domain.AuditLogEntry
+	invitations   map[string]domain.Invitation
+	logins        map[string]domain.LoginAttempt
+	mfa           map[string]domain.MfaEnrollment
+	mfaRoles      map[string]domain.MfaRequirement
+	resets        map[string]domain.PasswordResetToken
+	sessions      map[string]domain.SessionRevocation
+	verifications map[string]domain.ContactVerification
+	signingKeys   map[string]domain.SigningKey
+	revokedTokens map[string]domain.RevokedToken
+	apiClients    map[string]domain.ApiClient
+	apiKeys       map[string]domain.ApiKey
+	oidcLogins    map[string]domain.OidcLogin
+	oidcIds       map[string]domain.OidcIdentity
+}
+
+// snapshot is the JSON layout written by Save and read by Load
+type snapshot struct {
+	Users         []domain.Users               `json:"users"`
+	Animals       []domain.Animals             `json:"animals"`
+	Stages        []domain.PerformanceStage    `json:"performance_stages"`
+	ShowRounds    []domain.ShowRounds          `json:"show_rounds"`
+	Bookings      []domain.Bookings            `json:"bookings"`
+	AuditLog      []domain.AuditLogEntry       `json:"audit_log"`
+	Invitations   []domain.Invitation          `json:"invitations"`
+	Logins        []domain.LoginAttempt        `json:"login_attempts"`
+	Mfa           []domain.MfaEnrollment       `json:"mfa_enrollments"`
+	MfaRoles      []domain.MfaRequirement      `json:"mfa_requirements"`
+	Resets        []domain.PasswordResetToken  `json:"password_reset_tokens"`
+	Sessions      []domain.SessionRevocation   `json:"session_revocations"`
+	Verifications []domain.ContactVerification `json:"contact_verifications"`
+	SigningKeys   []domain.SigningKey          `json:"signing_keys"`
+	RevokedTokens []domain.RevokedToken        `json:"revoked_tokens"`
+	ApiClients    []domain.ApiClient           `json:"api_clients"`
+	ApiKeys       []domain.ApiKey              `json:"api_keys"`
+	OidcLogins    []domain.OidcLogin           `json:"oidc_logins"`
+	OidcIds       []domain.OidcIdentity        `json:"oidc_identities"`
+}
+
+// NewStore creates an empty in-memory store
+func NewStore() *Store {
+	return &Store{
+		users:         make(map[string]domain.Users),
+		animals:       make(map[string]domain.Animals),
+		stages:        make(map[string]domain.PerformanceStage),
+		showRounds:    make(map[string]domain.ShowRounds),
+		bookings:      make(map[string]domain.Bookings),
+		invitations:   make(map[string]domain.Invitation),
+		logins:        make(map[string]domain.LoginAttempt),
+		mfa:           make(map[string]domain.MfaEnrollment),
+		mfaRoles:      make(map[string]domain.MfaRequirement),
+		resets:        make(map[string]domain.PasswordResetToken),
+		sessions:      make(map[string]domain.SessionRevocation),
+		verifications: make(map[string]domain.ContactVerification),
+		signingKeys:   make(map[string]domain.SigningKey),
+		revokedTokens: make(map[string]domain.RevokedToken),
+		apiClients:    make(map[string]domain.ApiClient),
+		apiKeys:       make(map[string]domain.ApiKey),
+		oidcLogins:    make(map[string]domain.OidcLogin),
+		oidcIds:       make(map[string]domain.OidcIdentity),
+	}
+}
+
+// Load replaces the contents of the store with the snapshot at path.
+// A missing file leaves the store empty so that the first run can start from scratch.
+func (s *Store) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = make(map[string]domain.Users, len(snap.Users))
+	for _, user := range snap.Users {
+		user.Bookings = nil
+		s.users[user.Id] = user
+	}
+	s.animals = make(map[string]domain.Animals, len(snap.Animals))
+	for _, animal := range snap.Animals {
+		s.animals[animal.Id] = animal
+	}
+	s.stages = make(map[string]domain.PerformanceStage, len(snap.Stages))
+	for _, stage := range snap.Stages {
+		s.stages[stage.Id] = stage
+	}
+	s.showRounds = make(map[string]domain.ShowRounds, len(snap.ShowRounds))
+	for _, showRound := range snap.ShowRounds {
+		showRound.Bookings = nil
+		s.showRounds[showRound.Id] = showRound
+	}
+	s.bookings = make(map[string]domain.Bookings, len(snap.Bookings))
+	for _, booking := range snap.Bookings {
+		s.bookings[booking.Id] = booking
+	}
+	s.auditLog = snap.AuditLog
+	s.invitations = make(map[string]domain.Invitation, len(snap.Invitations))
+	for _, invitation := range snap.Invitations {
+		s.invitations[invitation.Id] = invitation
+	}
+	s.logins = make(map[string]domain.LoginAttempt, len(snap.Logins))
+	for _, attempt := range snap.Logins {
+		s.logins[attempt.Key] = attempt
+	}
+	s.mfa = make(map[string]domain.MfaEnrollment, len(snap.Mfa))
+	for _, enrollment := range snap.Mfa {
+		s.mfa[enrollment.UserId] = enrollment
+	}
+	s.mfaRoles = make(map[string]domain.MfaRequirement, len(snap.MfaRoles))
+	for _, requirement := range snap.MfaRoles {
+		s.mfaRoles[requirement.Role] = requirement
+	}
+	s.resets = make(map[string]domain.PasswordResetToken, len(snap.Resets))
+	for _, reset := range snap.Resets {
+		s.resets[reset.TokenHash] = reset
+	}
+	s.sessions = make(map[string]domain.SessionRevocation, len(snap.Sessions))
+	for _, revocation := range snap.Sessions {
+		s.sessions[revocation.UserId] = revocation
+	}
+	s.verifications = make(map[string]domain.ContactVerification, len(snap.Verifications))
+	for _, verification := range snap.Verifications {
+		s.verifications[verification.Key] = verification
+	}
+	s.signingKeys = make(map[string]domain.SigningKey, len(snap.SigningKeys))
+	for _, key := range snap.SigningKeys {
+		s.signingKeys[key.Kid] = key
+	}
+	s.revokedTokens = make(map[string]domain.RevokedToken, len(snap.RevokedTokens))
+	for _, token := range snap.RevokedTokens {
+		s.revokedTokens[token.TokenId] = token
+	}
+	s.apiClients = make(map[string]domain.ApiClient, len(snap.ApiClients))
+	for _, client := range snap.ApiClients {
+		s.apiClients[client.Id] = client
+	}
+	s.apiKeys = make(map[string]domain.ApiKey, len(snap.ApiKeys))
+	for _, key := range snap.ApiKeys {
+		s.apiKeys[key.Id] = key
+	}
+	s.oidcLogins = make(map[string]domain.OidcLogin, len(snap.OidcLogins))
+	for _, login := range snap.OidcLogins {
+		s.oidcLogins[login.StateHash] = login
+	}
+	s.oidcIds = make(map[string]domain.OidcIdentity, len(snap.OidcIds))
+	for _, identity := range snap.OidcIds {
+		s.oidcIds[identity.Id] = identity
+	}
+	return nil
+}
+
+// Save writes the contents of the store to path as JSON.
+// The file is written next to its destination first and renamed into place so a crash never leaves a truncated snapshot.
+func (s *Store) Save(path string) error {
+	s.mu.RLock()
+	snap := snapshot{
+		Users:         sortedValues(s.users, func(u domain.Users) string { return u.Id }),
+		Animals:       sortedValues(s.animals, func(a domain.Animals) string { return a.Id }),
+		Stages:        sortedValues(s.stages, func(p domain.PerformanceStage) string { return p.Id }),
+		ShowRounds:    sortedValues(s.showRounds, func(r domain.ShowRounds) string { return r.Id }),
+		Bookings:      sortedValues(s.bookings, func(b domain.Bookings) string { return b.Id }),
+		AuditLog:      slices.Clone(s.auditLog),
+		Invitations:   sortedValues(s.invitations, func(i domain.Invitation) string { return i.Id }),
+		Logins:        sortedValues(s.logins, func(a domain.LoginAttempt) string { return a.Key }),
+		Mfa:           sortedValues(s.mfa, func(e domain.MfaEnrollment) string { return e.UserId }),
+		MfaRoles:      sortedValues(s.mfaRoles, func(r domain.MfaRequirement) string { return r.Role }),
+		Resets:        sortedValues(s.resets, func(t domain.PasswordResetToken) string { return t.TokenHash }),
+		Sessions:      sortedValues(s.sessions, func(r domain.SessionRevocation) string { return r.UserId }),
+		Verifications: sortedValues(s.verifications, func(v domain.ContactVerification) string { return v.Key }),
+		SigningKeys:   sortedValues(s.signingKeys, func(k domain.SigningKey) string { return k.Kid }),
+		RevokedTokens: sortedValues(s.revokedTokens, func(t domain.RevokedToken) string { return t.TokenId }),
+		ApiClients:    sortedValues(s.apiClients, func(c domain.ApiClient) string { return c.Id }),
+		ApiKeys:       sortedValues(s.apiKeys, func(k domain.ApiKey) string { return k.Id }),
+		OidcLogins:    sortedValues(s.oidcLogins, func(l domain.OidcLogin) string { return l.StateHash }),
+		OidcIds:       sortedValues(s.oidcIds, func(i domain.OidcIdentity) string { return i.Id }),
+	}
+	s.mu.RUnlock()
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// live looks up an entity that has not been soft-deleted; the caller must hold the store lock
+func live[T port.Trashable](collection map[string]T, id string) (T, bool) {
+	entity, ok := collection[id]
+	return entity, ok && entity.GetDeletedAt() == nil
+}
+
+// sortedValues returns the values of a collection ordered by id, giving snapshots a stable layout
+func sortedValues[T any](collection map[string]T, id func(T) string) []T {
+	values := make([]T, 0, len(collection))
+	for _, value := range collection {
+		values = append(values, value)
+	}
+	slices.SortFunc(values, func(a, b T) int {
+		return strings.Compare(id(a), id(b))
+	})
+	return values
+}
@@ -0,0 +1,216 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// trashSortKeys whitelists the fields ListTrash can be sorted by
+var trashSortKeys = sortKeys[port.TrashItem]{
+	"id":         by(func(i port.TrashItem) string { return i.Id }),
+	"deleted_at": by(func(i port.TrashItem) int64 { return i.DeletedAt.UnixNano() }),
+}
+
+// MemoryTrashRepository reaches the soft-deleted entities, which stay in the collections of the store until purged
+type MemoryTrashRepository struct {
+	store *Store
+}
+
+func NewMemoryTrashRepository(store *Store) *MemoryTrashRepository {
+	return &MemoryTrashRepository{store: store}
+}
+
+func (r *MemoryTrashRepository) ListTrash(ctx context.Context, kind port.TrashKind, filter port.TrashFilter, query port.ListQuery) (*port.Page[port.TrashItem], error) {
+	if _, err := port.ParseTrashKind(string(kind)); err != nil {
+		return nil, err
+	}
+
+	r.store.mu.RLock()
+	var items []port.TrashItem
+	switch kind {
+	case port.TrashUsers:
+		items = trashItems(r.store.users, kind, filter)
+	case port.TrashAnimals:
+		items = trashItems(r.store.animals, kind, filter)
+	case port.TrashStages:
+		items = trashItems(r.store.stages, kind, filter)
+	case port.TrashShowRounds:
+		items = trashItems(r.store.showRounds, kind, filter)
+	default:
+		items = trashItems(r.store.bookings, kind, filter)
+	}
+	r.store.mu.RUnlock()
+
+	return findPage(items, query, trashSortKeys, "id")
+}
+
+func (r *MemoryTrashRepository) GetTrashItem(ctx context.Context, kind port.TrashKind, id string) (*port.TrashItem, error) {
+	if _, err := port.ParseTrashKind(string(kind)); err != nil {
+		return nil, err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var item *port.TrashItem
+	switch kind {
+	case port.TrashUsers:
+		item = trashItem(r.store.users, kind, id)
+	case port.TrashAnimals:
+		item = trashItem(r.store.animals, kind, id)
+	case port.TrashStages:
+		item = trashItem(r.store.stages, kind, id)
+	case port.TrashShowRounds:
+		item = trashItem(r.store.showRounds, kind, id)
+	default:
+		item = trashItem(r.store.bookings, kind, id)
+	}
+	if item == nil {
+		return nil, domain.ErrNotFound
+	}
+	return item, nil
+}
+
+func (r *MemoryTrashRepository) Restore(ctx context.Context, kind port.TrashKind, id string) error {
+	if _, err := port.ParseTrashKind(string(kind)); err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	if !r.store.isTrashed(kind, id) {
+		return domain.ErrNotFound
+	}
+
+	change := port.UpdateAudit(ctx)
+	switch kind {
+	case port.TrashUsers:
+		user := r.store.users[id]
+		user.DeletedAt = nil
+		user.Version++
+		user.Touch(change)
+		r.store.users[id] = user
+	case port.TrashAnimals:
+		animal := r.store.animals[id]
+		animal.DeletedAt = nil
+		animal.Version++
+		animal.Touch(change)
+		r.store.animals[id] = animal
+	case port.TrashStages:
+		stage := r.store.stages[id]
+		stage.DeletedAt = nil
+		stage.Version++
+		stage.Touch(change)
+		r.store.stages[id] = stage
+	case port.TrashShowRounds:
+		showRound := r.store.showRounds[id]
+		showRound.DeletedAt = nil
+		showRound.Version++
+		showRound.Touch(change)
+		r.store.showRounds[id] = showRound
+	default:
+		booking := r.store.bookings[id]
+		booking.DeletedAt = nil
+		booking.Version++
+		booking.Touch(change)
+		r.store.bookings[id] = booking
+	}
+	return nil
+}
+
+func (r *MemoryTrashRepository) Purge(ctx context.Context, kind port.TrashKind, id string) error {
+	if _, err := port.ParseTrashKind(string(kind)); err != nil {
+		return err
+	}
+
+	defer r.store.lock(ctx)()
+
+	if !r.store.isTrashed(kind, id) {
+		return domain.ErrNotFound
+	}
+
+	// Remove the trashed dependents too, mirroring the foreign keys of the SQL backends
+	switch kind {
+	case port.TrashUsers:
+		r.store.purgeBookings(func(b domain.Bookings) bool { return b.UserId == id })
+		delete(r.store.users, id)
+	case port.TrashAnimals:
+		r.store.purgeShowRounds(func(s domain.ShowRounds) bool { return s.AnimalId == id })
+		delete(r.store.animals, id)
+	case port.TrashStages:
+		r.store.purgeShowRounds(func(s domain.ShowRounds) bool { return s.StageId == id })
+		delete(r.store.stages, id)
+	case port.TrashShowRounds:
+		r.store.purgeBookings(func(b domain.Bookings) bool { return b.RoundId == id })
+		delete(r.store.showRounds, id)
+	default:
+		delete(r.store.bookings, id)
+	}
+	return nil
+}
+
+// isTrashed reports whether the entity of kind with the given id has been soft-deleted; the caller must hold the store lock
+func (s *Store) isTrashed(kind port.TrashKind, id string) bool {
+	switch kind {
+	case port.TrashUsers:
+		return trashItem(s.users, kind, id) != nil
+	case port.TrashAnimals:
+		return trashItem(s.animals, kind, id) != nil
+	case port.TrashStages:
+		return trashItem(s.stages, kind, id) != nil
+	case port.TrashShowRounds:
+		return trashItem(s.showRounds, kind, id) != nil
+	default:
+		return trashItem(s.bookings, kind, id) != nil
+	}
+}
+
+// purgeShowRounds removes the trashed show rounds accepted by match together with their trashed bookings; the caller must hold the store lock
+func (s *Store) purgeShowRounds(match func(domain.ShowRounds) bool) {
+	for id, showRound := range s.showRounds {
+		if showRound.DeletedAt != nil && match(showRound) {
+			s.purgeBookings(func(b domain.Bookings) bool { return b.RoundId == id })
+			delete(s.showRounds, id)
+		}
+	}
+}
+
+// purgeBookings removes the trashed bookings accepted by match; the caller must hold the store lock
+func (s *Store) purgeBookings(match func(domain.Bookings) bool) {
+	for id, booking := range s.bookings {
+		if booking.DeletedAt != nil && match(booking) {
+			delete(s.bookings, id)
+		}
+	}
+}
+
+// trashItems wraps the soft-deleted entities of a collection that the filter accepts
+func trashItems[T any, PT interface {
+	*T
+	port.Trashable
+}](collection map[string]T, kind port.TrashKind, filter port.TrashFilter) []port.TrashItem {
+	var items []port.TrashItem
+	for _, entity := range collection {
+		deletedAt := PT(&entity).GetDeletedAt()
+		if deletedAt == nil || (filter.DeletedBefore != nil && !deletedAt.Before(*filter.DeletedBefore)) {
+			continue
+		}
+		items = append(items, port.NewTrashItem(kind, PT(&entity)))
+	}
+	return items
+}
+
+// trashItem wraps one soft-deleted entity of a collection, nil when there is no such entity in the trash
+func trashItem[T any, PT interface {
+	*T
+	port.Trashable
+}](collection map[string]T, kind port.TrashKind, id string) *port.TrashItem {
+	entity, ok := collection[id]
+	if !ok || PT(&entity).GetDeletedAt() == nil {
+		return nil
+	}
+	item := port.NewTrashItem(kind, PT(&entity))
+	return &item
+}
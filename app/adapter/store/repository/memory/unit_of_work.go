@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+)
+
+// txKey marks a context that is already running inside a MemoryUnitOfWork
+type txKey struct{}
+
+// MemoryUnitOfWork makes a group of repository calls all-or-nothing by restoring the collections
+// it saw on entry when fn fails. Units of work run one at a time, and repository writes made
+// outside any unit of work wait for the running one, so a rollback only undoes its own writes.
+type MemoryUnitOfWork struct {
+	store *Store
+}
+
+// NewMemoryUnitOfWork creates a unit of work for the repositories sharing store
+func NewMemoryUnitOfWork(store *Store) *MemoryUnitOfWork {
+	return &MemoryUnitOfWork{store: store}
+}
+
+// Do runs fn and rolls the store back when it returns an error. A Do nested inside another joins the outer one.
+func (u *MemoryUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+
+	u.store.txMu.Lock()
+	defer u.store.txMu.Unlock()
+
+	saved := u.store.clone()
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		u.store.restore(saved)
+		return err
+	}
+	return nil
+}
+
+// lock takes the write lock of the store for a repository call and returns its release.
+// Outside a unit of work the call also holds the transaction lock, so it never lands in the
+// middle of a unit of work whose rollback would discard it.
+func (s *Store) lock(ctx context.Context) (unlock func()) {
+	if ctx.Value(txKey{}) != nil {
+		s.mu.Lock()
+		return s.mu.Unlock
+	}
+
+	s.txMu.Lock()
+	s.mu.Lock()
+	return func() {
+		s.mu.Unlock()
+		s.txMu.Unlock()
+	}
+}
+
+// clone copies the collections of the store. The values are plain structs whose slices are never modified in place,
+// so a shallow copy of each collection suffices.
+func (s *Store) clone() *Store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return &Store{
+		users:         maps.Clone(s.users),
+		animals:       maps.Clone(s.animals),
+		stages:        maps.Clone(s.stages),
+		showRounds:    maps.Clone(s.showRounds),
+		bookings:      maps.Clone(s.bookings),
+		auditLog:      slices.Clone(s.auditLog),
+		invitations:   maps.Clone(s.invitations),
+		logins:        maps.Clone(s.logins),
+		mfa:           maps.Clone(s.mfa),
+		mfaRoles:      maps.Clone(s.mfaRoles),
+		resets:        maps.Clone(s.resets),
+		sessions:      maps.Clone(s.sessions),
+		verifications: maps.Clone(s.verifications),
+		signingKeys:   maps.Clone(s.signingKeys),
+		revokedTokens: maps.Clone(s.revokedTokens),
+		apiClients:    maps.Clone(s.apiClients),
+		apiKeys:       maps.Clone(s.apiKeys),
+		oidcLogins:    maps.Clone(s.oidcLogins),
+		oidcIds:       maps.Clone(s.oidcIds),
+	}
+}
+
+// restore replaces the collections of the store with the ones of saved
+func (s *Store) restore(saved *Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = saved.users
+	s.animals = saved.animals
+	s.stages = saved.stages
+	s.showRounds = saved.showRounds
+	s.bookings = saved.bookings
+	s.auditLog = saved.auditLog
+	s.invitations = saved.invitations
+	s.logins = saved.logins
+	s.mfa = saved.mfa
+	s.mfaRoles = saved.mfaRoles
+	s.resets = saved.resets
+	s.sessions = saved.sessions
+	s.verifications = saved.verifications
+	s.signingKeys = saved.signingKeys
+	s.revokedTokens = saved.revokedTokens
+	s.apiClients = saved.apiClients
+	s.apiKeys = saved.apiKeys
+	s.oidcLogins = saved.oidcLogins
+	s.oidcIds = saved.oidcIds
+}
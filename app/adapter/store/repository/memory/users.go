@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type MemoryUserRepository struct {
+	store *Store
+}
+
+func NewMemoryUserRepository(store *Store) *MemoryUserRepository {
+	return &MemoryUserRepository{store: store}
+}
+
+func (r *MemoryUserRepository) CreateUser(ctx context.Context, user *domain.Users) (*domain.Users, error) {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.users[user.Id]; ok || r.store.userTaken(*user, "") {
+		return nil, domain.ErrAlreadyExists
+	}
+
+	// Generate UUID for new user, unless the caller picked one to reference it before it is stored
+	if user.Id == "" {
+		user.Id = uuid.New().String()
+	}
+	user.Version = 1
+	user.Audit = port.NewAudit(ctx)
+	if user.Role == "" {
+		user.Role = "user"
+	}
+	stored := *user
+	stored.Bookings = nil
+	r.store.users[user.Id] = stored
+	return user, nil
+}
+
+func (r *MemoryUserRepository) GetUserById(ctx context.Context, id string) (*domain.Users, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := live(r.store.users, id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return r.store.withBookings(user), nil
+}
+
+func (r *MemoryUserRepository) GetUsersByRole(ctx context.Context, role string) ([]domain.Users, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var users []domain.Users
+	for _, user := range sortedValues(r.store.users, func(u domain.Users) string { return u.Id }) {
+		if user.Role == role && user.DeletedAt == nil {
+			users = append(users, *r.store.withBookings(user))
+		}
+	}
+	return users, nil
+}
+
+func (r *MemoryUserRepository) GetUserByUsername(ctx context.Context, username string) (*domain.Users, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.DeletedAt != nil {
+			continue
+		}
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *MemoryUserRepository) UpdateUser(ctx context.Context, id string, user *domain.Users) (*domain.Users, error) {
+	defer r.store.lock(ctx)()
+
+	existingUser, ok := live(r.store.users, id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if err := domain.CheckVersion(user.Version, existingUser.Version); err != nil {
+		return nil, err
+	}
+
+	if r.store.userTaken(*user, id) {
+		return nil, domain.ErrAlreadyExists
+	}
+
+	// Like GORM's Updates, zero values leave the stored field untouched
+	if user.Username != "" {
+		existingUser.Username = user.Username
+	}
+	if user.Password != "" {
+		existingUser.Password = user.Password
+	}
+	if user.Role != "" {
+		existingUser.Role = user.Role
+	}
+	if user.Email != "" {
+		existingUser.Email = user.Email
+	}
+	if user.Phone != "" {
+		existingUser.Phone = user.Phone
+	}
+	if user.DisplayName != "" {
+		existingUser.DisplayName = user.DisplayName
+	}
+	if user.Language != "" {
+		existingUser.Language = user.Language
+	}
+	if user.MarketingConsent != nil {
+		consent := *user.MarketingConsent
+		existingUser.MarketingConsent = &consent
+	}
+	if user.VerifiedEmail != "" {
+		existingUser.VerifiedEmail = user.VerifiedEmail
+	}
+	if user.VerifiedPhone != "" {
+		existingUser.VerifiedPhone = user.VerifiedPhone
+	}
+
+	existingUser.Version++
+	existingUser.Touch(port.UpdateAudit(ctx))
+	r.store.users[id] = existingUser
+	return r.store.withBookings(existingUser), nil
+}
+
+func (r *MemoryUserRepository) DeleteUser(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	// Soft delete: the user stays in the store, hidden from every query, until it is purged
+	user, ok := live(r.store.users, id)
+	if !ok {
+		return nil
+	}
+	change := port.UpdateAudit(ctx)
+	user.DeletedAt = &change.UpdatedAt
+	user.Version++
+	user.Touch(change)
+	r.store.users[id] = user
+	return nil
+}
+
+// userTaken reports whether a user other than exceptId already uses the username, email or phone of user, like the
+// unique indexes of the other backends, which also keep them taken by soft-deleted users. Empty fields are not
+// compared; the caller must hold the store lock.
+func (s *Store) userTaken(user domain.Users, exceptId string) bool {
+	for id, other := range s.users {
+		if id == exceptId {
+			continue
+		}
+		if (user.Username != "" && other.Username == user.Username) ||
+			(user.Email != "" && other.Email == user.Email) ||
+			(user.Phone != "" && other.Phone == user.Phone) {
+			return true
+		}
+	}
+	return false
+}
+
+// withBookings returns a copy of user with its bookings attached, mirroring the preload of the other backends
+func (s *Store) withBookings(user domain.Users) *domain.Users {
+	user.Bookings = s.bookingsWhere(func(booking domain.Bookings) bool {
+		return booking.UserId == user.Id
+	})
+	return &user
+}
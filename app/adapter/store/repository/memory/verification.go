@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type MemoryVerificationRepository struct {
+	store *Store
+}
+
+func NewMemoryVerificationRepository(store *Store) *MemoryVerificationRepository {
+	return &MemoryVerificationRepository{store: store}
+}
+
+func (r *MemoryVerificationRepository) SaveVerification(ctx context.Context, verification *domain.ContactVerification) error {
+	defer r.store.lock(ctx)()
+
+	audit := port.NewAudit(ctx)
+	if existing, ok := r.store.verifications[verification.Key]; ok {
+		audit.CreatedAt, audit.CreatedBy = existing.CreatedAt, existing.CreatedBy
+	}
+	verification.Audit = audit
+	r.store.verifications[verification.Key] = *verification
+	return nil
+}
+
+func (r *MemoryVerificationRepository) GetVerification(ctx context.Context, key string) (*domain.ContactVerification, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	verification, ok := r.store.verifications[key]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &verification, nil
+}
+
+func (r *MemoryVerificationRepository) DeleteVerification(ctx context.Context, key string) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.verifications[key]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.store.verifications, key)
+	return nil
+}
@@ -5,10 +5,22 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// animalSortColumns whitelists the fields GetAnimals can be sorted by
+var animalSortColumns = map[string]string{
+	"animal_id":     "_id",
+	"name":          "name",
+	"species":       "species",
+	"type":          "type",
+	"show_duration": "show_duration",
+	"created_at":    "created_at",
+	"updated_at":    "updated_at",
+}
+
 type MongoAnimalRepository struct {
 	base *BaseMongoRepository
 }
@@ -19,17 +31,29 @@ func NewMongoAnimalRepository(collection *mongo.Collection) *MongoAnimalReposito
 	}
 }
 
-func (r *MongoAnimalRepository) GetAnimals(ctx context.Context) ([]domain.Animals, error) {
+func (r *MongoAnimalRepository) GetAnimals(ctx context.Context, filter port.AnimalFilter, query port.ListQuery) (*port.Page[domain.Animals], error) {
+	match := bson.M{}
+	if filter.Species != "" {
+		match["species"] = filter.Species
+	}
+	if filter.Type != "" {
+		match["type"] = filter.Type
+	}
+	matchAudit(match, filter.Audit)
+
 	var animals []domain.Animals
-	if err := r.base.FindAll(ctx, bson.M{}, &animals); err != nil {
+	total, query, err := r.base.FindPage(ctx, match, query, animalSortColumns, "_id", &animals)
+	if err != nil {
 		return nil, err
 	}
-	return animals, nil
+	return port.NewPage(animals, total, query), nil
 }
 
 func (r *MongoAnimalRepository) CreateAnimal(ctx context.Context, animal *domain.Animals) (*domain.Animals, error) {
 	// Generate UUID for new animal
 	animal.Id = uuid.New().String()
+	animal.Version = 1
+	animal.Audit = port.NewAudit(ctx)
 
 	if err := r.base.Create(ctx, animal); err != nil {
 		return nil, err
@@ -61,7 +85,7 @@ func (r *MongoAnimalRepository) UpdateAnimal(ctx context.Context, id string, ani
 	}
 
 	// Update the animal
-	if err := r.base.Update(ctx, id, updateData); err != nil {
+	if err := r.base.UpdateVersioned(ctx, id, animal.Version, updateData); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,152 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoApiKeyRepository stores machine clients and their API keys in two collections
+type MongoApiKeyRepository struct {
+	clients *mongo.Collection
+	keys    *mongo.Collection
+}
+
+func NewMongoApiKeyRepository(clients *mongo.Collection, keys *mongo.Collection) *MongoApiKeyRepository {
+	return &MongoApiKeyRepository{clients: clients, keys: keys}
+}
+
+func (r *MongoApiKeyRepository) CreateApiClient(ctx context.Context, client *domain.ApiClient) (*domain.ApiClient, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	client.Id = uuid.New().String()
+	client.Audit = port.NewAudit(ctx)
+	if _, err := r.clients.InsertOne(ctx, client); err != nil {
+		return nil, translateError(err)
+	}
+	return client, nil
+}
+
+func (r *MongoApiKeyRepository) GetApiClientById(ctx context.Context, id string) (*domain.ApiClient, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var client domain.ApiClient
+	if err := r.clients.FindOne(ctx, bson.M{"_id": id}).Decode(&client); err != nil {
+		return nil, translateError(err)
+	}
+	return &client, nil
+}
+
+func (r *MongoApiKeyRepository) ListApiClients(ctx context.Context) ([]domain.ApiClient, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	cursor, err := r.clients.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	clients := []domain.ApiClient{}
+	if err := cursor.All(ctx, &clients); err != nil {
+		return nil, translateError(err)
+	}
+	return clients, nil
+}
+
+func (r *MongoApiKeyRepository) CreateApiKey(ctx context.Context, key *domain.ApiKey) (*domain.ApiKey, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	key.Id = uuid.New().String()
+	key.Audit = port.NewAudit(ctx)
+	if _, err := r.keys.InsertOne(ctx, key); err != nil {
+		return nil, translateError(err)
+	}
+	return key, nil
+}
+
+func (r *MongoApiKeyRepository) GetApiKeyById(ctx context.Context, id string) (*domain.ApiKey, error) {
+	return r.getApiKey(ctx, bson.M{"_id": id})
+}
+
+func (r *MongoApiKeyRepository) GetApiKeyByHash(ctx context.Context, keyHash string) (*domain.ApiKey, error) {
+	return r.getApiKey(ctx, bson.M{"key_hash": keyHash})
+}
+
+func (r *MongoApiKeyRepository) getApiKey(ctx context.Context, filter bson.M) (*domain.ApiKey, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var key domain.ApiKey
+	if err := r.keys.FindOne(ctx, filter).Decode(&key); err != nil {
+		return nil, translateError(err)
+	}
+	return &key, nil
+}
+
+func (r *MongoApiKeyRepository) ListApiKeys(ctx context.Context, clientId string) ([]domain.ApiKey, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	sort := bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}
+	cursor, err := r.keys.Find(ctx, bson.M{"client_id": clientId}, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	keys := []domain.ApiKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, translateError(err)
+	}
+	return keys, nil
+}
+
+func (r *MongoApiKeyRepository) ExpireApiKey(ctx context.Context, id string, expiresAt time.Time) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.GetApiKeyById(ctx, id); err != nil {
+		return err
+	}
+	set := auditSet(ctx)
+	set["expires_at"] = expiresAt.UTC()
+	_, err := r.keys.UpdateOne(ctx, bson.M{"_id": id, "expires_at": bson.M{"$gt": expiresAt.UTC()}}, bson.M{"$set": set})
+	return translateError(err)
+}
+
+func (r *MongoApiKeyRepository) RevokeApiKey(ctx context.Context, id string, revokedAt time.Time) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	set := auditSet(ctx)
+	set["revoked_at"] = revokedAt.UTC()
+	result, err := r.keys.UpdateOne(ctx, bson.M{"_id": id, "revoked_at": nil}, bson.M{"$set": set})
+	if err != nil {
+		return translateError(err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoApiKeyRepository) TouchApiKey(ctx context.Context, id string, usedAt time.Time) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.keys.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_used_at": usedAt.UTC()}})
+	if err != nil {
+		return translateError(err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
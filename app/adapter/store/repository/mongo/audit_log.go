@@ -0,0 +1,63 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// auditLogSortColumns whitelists the fields ListAuditLog can be sorted by
+var auditLogSortColumns = map[string]string{
+	"entry_id":    "_id",
+	"occurred_at": "occurred_at",
+	"actor":       "actor",
+	"action":      "action",
+	"entity_type": "entity_type",
+}
+
+// MongoAuditLogRepository stores the audit log in a collection it only ever inserts into.
+// Entries are never soft-deleted, so it reads them without the live filter of the entity repositories.
+type MongoAuditLogRepository struct {
+	base *BaseMongoRepository
+}
+
+func NewMongoAuditLogRepository(collection *mongo.Collection) *MongoAuditLogRepository {
+	return &MongoAuditLogRepository{
+		base: NewBaseMongoRepository(collection),
+	}
+}
+
+func (r *MongoAuditLogRepository) Append(ctx context.Context, entry *domain.AuditLogEntry) error {
+	entry.Id = uuid.New().String()
+	return r.base.Create(ctx, entry)
+}
+
+func (r *MongoAuditLogRepository) ListAuditLog(ctx context.Context, filter port.AuditLogFilter, query port.ListQuery) (*port.Page[domain.AuditLogEntry], error) {
+	match := bson.M{}
+	if filter.Actor != "" {
+		match["actor"] = filter.Actor
+	}
+	if filter.Action != "" {
+		match["action"] = filter.Action
+	}
+	if filter.EntityType != "" {
+		match["entity_type"] = filter.EntityType
+	}
+	if filter.EntityId != "" {
+		match["entity_id"] = filter.EntityId
+	}
+	if filter.From != nil || filter.To != nil {
+		match["occurred_at"] = timeRange(filter.From, filter.To)
+	}
+
+	var entries []domain.AuditLogEntry
+	total, query, err := r.base.findPage(ctx, match, query, auditLogSortColumns, "_id", &entries)
+	if err != nil {
+		return nil, err
+	}
+	return port.NewPage(entries, total, query), nil
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"time"
 
 	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // BaseMongoRepository provides a base implementation for MongoDB repositories
@@ -28,7 +32,7 @@ func (r *BaseMongoRepository) Create(ctx context.Context, entity any) error {
 	defer cancel()
 
 	_, err := r.collection.InsertOne(ctx, entity)
-	return err
+	return translateError(err)
 }
 
 // FindByID finds an entity by its ID
@@ -36,12 +40,9 @@ func (r *BaseMongoRepository) FindByID(ctx context.Context, id string, result an
 	ctx, cancel := common.ContextWithTimeout(ctx)
 	defer cancel()
 
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(result)
+	err := r.collection.FindOne(ctx, live(bson.M{"_id": id})).Decode(result)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return errors.New("entity not found")
-		}
-		return err
+		return translateError(err)
 	}
 	return nil
 }
@@ -57,7 +58,7 @@ func (r *BaseMongoRepository) FindAll(ctx context.Context, filter any, results a
 		return errors.New("results parameter must be a pointer to a slice")
 	}
 
-	cursor, err := r.collection.Find(ctx, filter)
+	cursor, err := r.collection.Find(ctx, live(filter))
 	if err != nil {
 		return err
 	}
@@ -66,20 +67,159 @@ func (r *BaseMongoRepository) FindAll(ctx context.Context, filter any, results a
 	return cursor.All(ctx, results)
 }
 
+// FindPage counts the live documents matching the filter and loads one ordered page of them into results.
+// columns whitelists the sortable fields; tieBreaker keeps the ordering stable between pages.
+func (r *BaseMongoRepository) FindPage(ctx context.Context, filter any, query port.ListQuery, columns map[string]string, tieBreaker string, results any) (int64, port.ListQuery, error) {
+	return r.findPage(ctx, live(filter), query, columns, tieBreaker, results)
+}
+
+// findPage is FindPage without hiding the soft-deleted documents
+func (r *BaseMongoRepository) findPage(ctx context.Context, filter any, query port.ListQuery, columns map[string]string, tieBreaker string, results any) (int64, port.ListQuery, error) {
+	query, err := query.Normalize()
+	if err != nil {
+		return 0, query, err
+	}
+
+	order, err := query.SortColumns(columns, tieBreaker)
+	if err != nil {
+		return 0, query, err
+	}
+
+	sort := bson.D{}
+	for _, field := range order {
+		direction := 1
+		if field.Desc {
+			direction = -1
+		}
+		sort = append(sort, bson.E{Key: field.Field, Value: direction})
+	}
+
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, query, err
+	}
+
+	findOptions := options.Find().
+		SetSort(sort).
+		SetSkip(int64(query.Offset)).
+		SetLimit(int64(query.Limit))
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return 0, query, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, results); err != nil {
+		return 0, query, err
+	}
+	return total, query, nil
+}
+
 // Update updates an entity by its ID
 func (r *BaseMongoRepository) Update(ctx context.Context, id string, entity any) error {
 	ctx, cancel := common.ContextWithTimeout(ctx)
 	defer cancel()
 
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": entity})
-	return err
+	_, err := r.collection.UpdateOne(ctx, live(bson.M{"_id": id}), bson.M{"$set": entity})
+	return translateError(err)
 }
 
-// Delete removes an entity by its ID
+// UpdateVersioned sets the given fields, stamps the change with the actor of ctx and increments the version
+// of an entity in one atomic update. When expected is not 0 the update only matches while the stored version
+// equals it, otherwise ErrVersionConflict is returned.
+func (r *BaseMongoRepository) UpdateVersioned(ctx context.Context, id string, expected int64, fields bson.M) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "deleted_at": nil}
+	if expected != 0 {
+		filter["version"] = expected
+	}
+
+	set := auditSet(ctx)
+	for field, value := range fields {
+		set[field] = value
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": set, "$inc": bson.M{"version": 1}})
+	if err != nil {
+		return translateError(err)
+	}
+	if result.MatchedCount == 0 {
+		if expected != 0 {
+			return domain.ErrVersionConflict
+		}
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete soft-deletes an entity by its ID, stamping deleted_at and bumping its version so that concurrent writers
+// holding the old version fail. Deleting an entity that does not exist or is already deleted is a no-op.
 func (r *BaseMongoRepository) Delete(ctx context.Context, id string) error {
 	ctx, cancel := common.ContextWithTimeout(ctx)
 	defer cancel()
 
-	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	set := auditSet(ctx)
+	set["deleted_at"] = set["updated_at"]
+	_, err := r.collection.UpdateOne(ctx, live(bson.M{"_id": id}), bson.M{
+		"$set": set,
+		"$inc": bson.M{"version": 1},
+	})
 	return err
 }
+
+// auditSet is the $set document that stamps a change made by the actor of ctx
+func auditSet(ctx context.Context) bson.M {
+	change := port.UpdateAudit(ctx)
+	return bson.M{"updated_at": change.UpdatedAt, "updated_by": change.UpdatedBy}
+}
+
+// matchAudit adds the conditions of filter on the audit stamps to match
+func matchAudit(match bson.M, filter port.AuditFilter) {
+	if filter.CreatedFrom != nil || filter.CreatedTo != nil {
+		match["created_at"] = timeRange(filter.CreatedFrom, filter.CreatedTo)
+	}
+	if filter.UpdatedFrom != nil || filter.UpdatedTo != nil {
+		match["updated_at"] = timeRange(filter.UpdatedFrom, filter.UpdatedTo)
+	}
+	if filter.CreatedBy != "" {
+		match["created_by"] = filter.CreatedBy
+	}
+	if filter.UpdatedBy != "" {
+		match["updated_by"] = filter.UpdatedBy
+	}
+}
+
+// timeRange is an inclusive range condition on a date field; either bound may be nil
+func timeRange(from, to *time.Time) bson.M {
+	condition := bson.M{}
+	if from != nil {
+		condition["$gte"] = from.UTC()
+	}
+	if to != nil {
+		condition["$lte"] = to.UTC()
+	}
+	return condition
+}
+
+// live restricts filter to documents that have not been soft-deleted; a null query also matches a missing field
+func live(filter any) bson.M {
+	return bson.M{"$and": bson.A{filter, bson.M{"deleted_at": nil}}}
+}
+
+// translateError maps MongoDB driver errors onto the domain errors expected by the services
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return domain.ErrNotFound
+	case mongo.IsDuplicateKeyError(err):
+		return domain.ErrAlreadyExists
+	default:
+		return err
+	}
+}
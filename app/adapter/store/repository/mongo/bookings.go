@@ -5,10 +5,23 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// bookingSortColumns whitelists the fields ListBookings can be sorted by
+var bookingSortColumns = map[string]string{
+	"booking_id":  "_id",
+	"user_id":     "user_id",
+	"round_id":    "round_id",
+	"seat_number": "seat_number",
+	"price":       "price",
+	"status":      "status",
+	"created_at":  "created_at",
+	"updated_at":  "updated_at",
+}
+
 type MongoBookingRepository struct {
 	base *BaseMongoRepository
 }
@@ -22,6 +35,8 @@ func NewMongoBookingRepository(collection *mongo.Collection) *MongoBookingReposi
 func (r *MongoBookingRepository) CreateBooking(ctx context.Context, booking *domain.Bookings) (*domain.Bookings, error) {
 	// Generate UUID for new booking
 	booking.Id = uuid.New().String()
+	booking.Version = 1
+	booking.Audit = port.NewAudit(ctx)
 
 	if err := r.base.Create(ctx, booking); err != nil {
 		return nil, err
@@ -53,6 +68,27 @@ func (r *MongoBookingRepository) GetBookingsByRoundId(ctx context.Context, round
 	return bookings, nil
 }
 
+func (r *MongoBookingRepository) ListBookings(ctx context.Context, filter port.BookingFilter, query port.ListQuery) (*port.Page[domain.Bookings], error) {
+	match := bson.M{}
+	if filter.UserId != "" {
+		match["user_id"] = filter.UserId
+	}
+	if filter.RoundId != "" {
+		match["round_id"] = filter.RoundId
+	}
+	if filter.Status != "" {
+		match["status"] = filter.Status
+	}
+	matchAudit(match, filter.Audit)
+
+	var bookings []domain.Bookings
+	total, query, err := r.base.FindPage(ctx, match, query, bookingSortColumns, "_id", &bookings)
+	if err != nil {
+		return nil, err
+	}
+	return port.NewPage(bookings, total, query), nil
+}
+
 func (r *MongoBookingRepository) UpdateBooking(ctx context.Context, id string, booking *domain.Bookings) (*domain.Bookings, error) {
 	// First check if booking exists
 	_, err := r.GetBookingById(ctx, id)
@@ -60,17 +96,27 @@ func (r *MongoBookingRepository) UpdateBooking(ctx context.Context, id string, b
 		return nil, err
 	}
 
-	// Prepare update data based on the booking model fields
-	updateData := bson.M{
-		"user_id":     booking.UserId,
-		"round_id":    booking.RoundId,
-		"seat_number": booking.SeatNumber,
-		"price":       booking.Price,
-		"qr_code":     booking.QrCode,
+	// Like GORM's Updates, zero values leave the stored field untouched
+	updateData := bson.M{}
+	for field, value := range map[string]string{
+		"user_id":  booking.UserId,
+		"round_id": booking.RoundId,
+		"qr_code":  booking.QrCode,
+		"status":   booking.Status,
+	} {
+		if value != "" {
+			updateData[field] = value
+		}
+	}
+	if booking.SeatNumber != 0 {
+		updateData["seat_number"] = booking.SeatNumber
+	}
+	if booking.Price != 0 {
+		updateData["price"] = booking.Price
 	}
 
 	// Update the booking
-	if err := r.base.Update(ctx, id, updateData); err != nil {
+	if err := r.base.UpdateVersioned(ctx, id, booking.Version, updateData); err != nil {
 		return nil, err
 	}
 
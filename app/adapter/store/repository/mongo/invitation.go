@@ -0,0 +1,62 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoInvitationRepository stores invitations, which are never soft-deleted.
+// Accepting one is a conditional update so that it succeeds only once.
+type MongoInvitationRepository struct {
+	base *BaseMongoRepository
+}
+
+func NewMongoInvitationRepository(collection *mongo.Collection) *MongoInvitationRepository {
+	return &MongoInvitationRepository{
+		base: NewBaseMongoRepository(collection),
+	}
+}
+
+func (r *MongoInvitationRepository) CreateInvitation(ctx context.Context, invitation *domain.Invitation) (*domain.Invitation, error) {
+	invitation.Id = uuid.New().String()
+	invitation.Audit = port.NewAudit(ctx)
+	if err := r.base.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (r *MongoInvitationRepository) GetInvitationById(ctx context.Context, id string) (*domain.Invitation, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var invitation domain.Invitation
+	if err := r.base.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&invitation); err != nil {
+		return nil, translateError(err)
+	}
+	return &invitation, nil
+}
+
+func (r *MongoInvitationRepository) AcceptInvitation(ctx context.Context, id string, userId string, acceptedAt time.Time) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	set := auditSet(ctx)
+	set["accepted_at"] = acceptedAt.UTC()
+	set["accepted_by"] = userId
+	result, err := r.base.collection.UpdateOne(ctx, bson.M{"_id": id, "accepted_at": nil}, bson.M{"$set": set})
+	if err != nil {
+		return translateError(err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoLoginAttemptRepository keeps the failed login counters in a collection keyed by the counter key.
+// Failures are counted with an upserting pipeline update, so concurrent logins on several replicas never lose one.
+type MongoLoginAttemptRepository struct {
+	base *BaseMongoRepository
+}
+
+func NewMongoLoginAttemptRepository(collection *mongo.Collection) *MongoLoginAttemptRepository {
+	return &MongoLoginAttemptRepository{
+		base: NewBaseMongoRepository(collection),
+	}
+}
+
+func (r *MongoLoginAttemptRepository) GetLoginAttempts(ctx context.Context, keys []string) ([]domain.LoginAttempt, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	cursor, err := r.base.collection.Find(ctx, bson.M{"_id": bson.M{"$in": keys}})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer cursor.Close(ctx)
+
+	attempts := []domain.LoginAttempt{}
+	if err := cursor.All(ctx, &attempts); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (r *MongoLoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, at time.Time, resetBefore time.Time) (*domain.LoginAttempt, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	// A missing last_failure_at compares lower than any date, so a new counter starts from one as well
+	update := bson.A{bson.M{"$set": bson.M{
+		"failures": bson.M{"$cond": bson.A{
+			bson.M{"$lt": bson.A{"$last_failure_at", resetBefore.UTC()}},
+			1,
+			bson.M{"$add": bson.A{"$failures", 1}},
+		}},
+		"last_failure_at": at.UTC(),
+	}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var attempt domain.LoginAttempt
+	if err := r.base.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&attempt); err != nil {
+		return nil, translateError(err)
+	}
+	return &attempt, nil
+}
+
+func (r *MongoLoginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.base.collection.UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$set": bson.M{"locked_until": until.UTC()}})
+	if err != nil {
+		return translateError(err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoLoginAttemptRepository) ClearLoginAttempts(ctx context.Context, key string) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.base.collection.DeleteOne(ctx, bson.M{"_id": key})
+	if err != nil {
+		return translateError(err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoLoginAttemptRepository) ListLoginLockouts(ctx context.Context, now time.Time) ([]domain.LoginAttempt, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	cursor, err := r.base.collection.Find(ctx, bson.M{"locked_until": bson.M{"$gt": now.UTC()}}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer cursor.Close(ctx)
+
+	attempts := []domain.LoginAttempt{}
+	if err := cursor.All(ctx, &attempts); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
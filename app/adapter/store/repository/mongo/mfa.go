@@ -0,0 +1,164 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoMfaRepository stores enrollments, with their recovery codes embedded, and the roles requiring MFA
+// in two collections. Accepting a code is a conditional update, so that concurrent logins cannot use the same code twice.
+type MongoMfaRepository struct {
+	enrollments  *mongo.Collection
+	requirements *mongo.Collection
+}
+
+func NewMongoMfaRepository(enrollments *mongo.Collection, requirements *mongo.Collection) *MongoMfaRepository {
+	return &MongoMfaRepository{enrollments: enrollments, requirements: requirements}
+}
+
+func (r *MongoMfaRepository) GetMfaEnrollment(ctx context.Context, userId string) (*domain.MfaEnrollment, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var enrollment domain.MfaEnrollment
+	if err := r.enrollments.FindOne(ctx, bson.M{"_id": userId}).Decode(&enrollment); err != nil {
+		return nil, translateError(err)
+	}
+	if enrollment.RecoveryCodes == nil {
+		enrollment.RecoveryCodes = []string{}
+	}
+	return &enrollment, nil
+}
+
+func (r *MongoMfaRepository) SaveMfaEnrollment(ctx context.Context, enrollment *domain.MfaEnrollment) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	enrollment.Audit = port.NewAudit(ctx)
+	if enrollment.RecoveryCodes == nil {
+		enrollment.RecoveryCodes = []string{}
+	}
+
+	set := auditSet(ctx)
+	set["secret"] = enrollment.Secret
+	set["confirmed_at"] = enrollment.ConfirmedAt
+	set["last_step"] = enrollment.LastStep
+	set["recovery_codes"] = enrollment.RecoveryCodes
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": bson.M{"created_at": enrollment.CreatedAt, "created_by": enrollment.CreatedBy},
+	}
+	if enrollment.ConfirmedAt == nil {
+		delete(set, "confirmed_at")
+		update["$unset"] = bson.M{"confirmed_at": ""}
+	}
+	_, err := r.enrollments.UpdateOne(ctx, bson.M{"_id": enrollment.UserId}, update, options.Update().SetUpsert(true))
+	return translateError(err)
+}
+
+func (r *MongoMfaRepository) DeleteMfaEnrollment(ctx context.Context, userId string) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.enrollments.DeleteOne(ctx, bson.M{"_id": userId})
+	if err != nil {
+		return translateError(err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoMfaRepository) UseMfaStep(ctx context.Context, userId string, step int64) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{"_id": userId, "confirmed_at": bson.M{"$ne": nil}, "last_step": bson.M{"$lt": step}}
+	result, err := r.enrollments.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"last_step": step}})
+	if err != nil {
+		return translateError(err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoMfaRepository) UseRecoveryCode(ctx context.Context, userId string, codeHash string) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{"_id": userId, "recovery_codes": codeHash}
+	result, err := r.enrollments.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{"recovery_codes": codeHash}})
+	if err != nil {
+		return translateError(err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoMfaRepository) GetMfaRequirement(ctx context.Context, role string) (*domain.MfaRequirement, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var requirement domain.MfaRequirement
+	if err := r.requirements.FindOne(ctx, bson.M{"_id": role}).Decode(&requirement); err != nil {
+		return nil, translateError(err)
+	}
+	return &requirement, nil
+}
+
+func (r *MongoMfaRepository) ListMfaRequirements(ctx context.Context) ([]domain.MfaRequirement, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	cursor, err := r.requirements.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer cursor.Close(ctx)
+
+	requirements := []domain.MfaRequirement{}
+	if err := cursor.All(ctx, &requirements); err != nil {
+		return nil, err
+	}
+	return requirements, nil
+}
+
+func (r *MongoMfaRepository) SaveMfaRequirement(ctx context.Context, requirement *domain.MfaRequirement) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	audit := port.NewAudit(ctx)
+	update := bson.M{"$setOnInsert": bson.M{
+		"created_at": audit.CreatedAt,
+		"updated_at": audit.UpdatedAt,
+		"created_by": audit.CreatedBy,
+		"updated_by": audit.UpdatedBy,
+	}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	return translateError(r.requirements.FindOneAndUpdate(ctx, bson.M{"_id": requirement.Role}, update, opts).Decode(requirement))
+}
+
+func (r *MongoMfaRepository) DeleteMfaRequirement(ctx context.Context, role string) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.requirements.DeleteOne(ctx, bson.M{"_id": role})
+	if err != nil {
+		return translateError(err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
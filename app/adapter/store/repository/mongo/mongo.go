@@ -14,8 +14,37 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-// InitMongoDB initializes and returns a MongoDB database instance
+// schemaTimeout bounds applying the index registry, which may build indexes on large collections
+const schemaTimeout = 5 * time.Minute
+
+// InitMongoDB initializes and returns a MongoDB database instance with the registered indexes and validators applied
 func InitMongoDB(cfg *config.Config) *mongo.Database {
+	db, err := OpenMongoDB(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("Successfully connected to MongoDB!")
+
+	ctx, cancel := context.WithTimeout(context.Background(), schemaTimeout)
+	defer cancel()
+
+	report, err := EnsureSchema(ctx, db)
+	if err != nil {
+		log.Fatal("Failed to apply MongoDB schema:", err)
+	}
+	for _, created := range report.Created {
+		log.Printf("Created MongoDB %s", created)
+	}
+	for _, drift := range report.Drift {
+		log.Printf("Warning: MongoDB index drift %s", drift)
+	}
+
+	return db
+}
+
+// OpenMongoDB connects to MongoDB and returns the configured database without touching its schema
+func OpenMongoDB(cfg *config.Config) (*mongo.Database, error) {
 	ctx, cancel := common.ContextWithTimeout(context.Background())
 	defer cancel()
 
@@ -62,14 +91,13 @@ func InitMongoDB(cfg *config.Config) *mongo.Database {
 	// Connect to MongoDB
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatal("Failed to connect to MongoDB:", err)
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
 
 	// Ping the database to verify connection
 	if err = client.Ping(ctx, readpref.Primary()); err != nil {
-		log.Fatal("Failed to ping MongoDB:", err)
+		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
 	}
 
-	log.Println("Successfully connected to MongoDB!")
-	return client.Database(cfg.MongoDB.DbName)
+	return client.Database(cfg.MongoDB.DbName), nil
 }
@@ -0,0 +1,60 @@
+package mongo
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/repository/repositorytest"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TestMongoRepositories runs the repository suite against the MongoDB at MONGO_TEST_URI, which must be a replica set
+// so that units of work get transactions, e.g. mongodb://localhost:27017/?replicaSet=rs0&directConnection=true.
+// Every subtest gets its own database, dropped when it ends.
+func TestMongoRepositories(t *testing.T) {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Disconnect(ctx) })
+	require.NoError(t, client.Ping(ctx, nil))
+
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		db := client.Database("liongate_test_" + strings.ReplaceAll(uuid.NewString(), "-", "")[:12])
+		t.Cleanup(func() { _ = db.Drop(ctx) })
+		_, err := EnsureSchema(ctx, db)
+		require.NoError(t, err)
+
+		unitOfWork, err := NewMongoUnitOfWork(db, false)
+		require.NoError(t, err)
+
+		return repositorytest.Repositories{
+			Users:          NewMongoUserRepository(db.Collection("users")),
+			Animals:        NewMongoAnimalRepository(db.Collection("animals")),
+			Stages:         NewMongoPerformanceStageRepository(db.Collection("performance_stages")),
+			ShowRounds:     NewMongoShowRoundRepository(db.Collection("show_rounds")),
+			Bookings:       NewMongoBookingRepository(db.Collection("bookings")),
+			UnitOfWork:     unitOfWork,
+			Trash:          NewMongoTrashRepository(db),
+			AuditLog:       NewMongoAuditLogRepository(db.Collection("audit_log_entries")),
+			Invitations:    NewMongoInvitationRepository(db.Collection("invitations")),
+			LoginAttempts:  NewMongoLoginAttemptRepository(db.Collection("login_attempts")),
+			Mfa:            NewMongoMfaRepository(db.Collection("mfa_enrollments"), db.Collection("mfa_requirements")),
+			PasswordResets: NewMongoPasswordResetRepository(db.Collection("password_reset_tokens")),
+			Sessions:       NewMongoSessionRepository(db.Collection("session_revocations"), db.Collection("revoked_tokens")),
+			Verifications:  NewMongoVerificationRepository(db.Collection("contact_verifications")),
+			SigningKeys:    NewMongoSigningKeyRepository(db.Collection("signing_keys")),
+			ApiKeys:        NewMongoApiKeyRepository(db.Collection("api_clients"), db.Collection("api_keys")),
+			Oidc:           NewMongoOidcRepository(db.Collection("oidc_logins"), db.Collection("oidc_identities")),
+		}
+	})
+}
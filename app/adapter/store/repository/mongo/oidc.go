@@ -0,0 +1,83 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoOidcRepository stores the pending sign-ins of external providers keyed by the hash of their state, and the
+// linked identities. Taking a sign-in is a single FindOneAndDelete, so that a state completes one sign-in.
+type MongoOidcRepository struct {
+	logins     *mongo.Collection
+	identities *mongo.Collection
+}
+
+func NewMongoOidcRepository(logins *mongo.Collection, identities *mongo.Collection) *MongoOidcRepository {
+	return &MongoOidcRepository{logins: logins, identities: identities}
+}
+
+func (r *MongoOidcRepository) CreateOidcLogin(ctx context.Context, login *domain.OidcLogin) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	login.Audit = port.NewAudit(ctx)
+	_, err := r.logins.InsertOne(ctx, login)
+	return translateError(err)
+}
+
+func (r *MongoOidcRepository) TakeOidcLogin(ctx context.Context, stateHash string) (*domain.OidcLogin, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var login domain.OidcLogin
+	if err := r.logins.FindOneAndDelete(ctx, bson.M{"_id": stateHash}).Decode(&login); err != nil {
+		return nil, translateError(err)
+	}
+	return &login, nil
+}
+
+func (r *MongoOidcRepository) CreateOidcIdentity(ctx context.Context, identity *domain.OidcIdentity) (*domain.OidcIdentity, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	identity.Id = uuid.New().String()
+	identity.Audit = port.NewAudit(ctx)
+	if _, err := r.identities.InsertOne(ctx, identity); err != nil {
+		return nil, translateError(err)
+	}
+	return identity, nil
+}
+
+func (r *MongoOidcRepository) GetOidcIdentity(ctx context.Context, provider string, subject string) (*domain.OidcIdentity, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var identity domain.OidcIdentity
+	if err := r.identities.FindOne(ctx, bson.M{"provider": provider, "subject": subject}).Decode(&identity); err != nil {
+		return nil, translateError(err)
+	}
+	return &identity, nil
+}
+
+func (r *MongoOidcRepository) ListOidcIdentities(ctx context.Context, userId string) ([]domain.OidcIdentity, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	sort := bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}
+	cursor, err := r.identities.Find(ctx, bson.M{"user_id": userId}, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	identities := []domain.OidcIdentity{}
+	if err := cursor.All(ctx, &identities); err != nil {
+		return nil, translateError(err)
+	}
+	return identities, nil
+}
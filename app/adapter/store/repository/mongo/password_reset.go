@@ -0,0 +1,49 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoPasswordResetRepository stores pending password resets keyed by the hash of their token.
+// Taking one is a single FindOneAndDelete, so that concurrent resets cannot both use it.
+type MongoPasswordResetRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoPasswordResetRepository(collection *mongo.Collection) *MongoPasswordResetRepository {
+	return &MongoPasswordResetRepository{collection: collection}
+}
+
+func (r *MongoPasswordResetRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	token.Audit = port.NewAudit(ctx)
+	_, err := r.collection.InsertOne(ctx, token)
+	return translateError(err)
+}
+
+func (r *MongoPasswordResetRepository) TakePasswordResetToken(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var token domain.PasswordResetToken
+	if err := r.collection.FindOneAndDelete(ctx, bson.M{"_id": tokenHash}).Decode(&token); err != nil {
+		return nil, translateError(err)
+	}
+	return &token, nil
+}
+
+func (r *MongoPasswordResetRepository) DeletePasswordResetTokens(ctx context.Context, userId string) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userId})
+	return translateError(err)
+}
@@ -5,10 +5,21 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// stageSortColumns whitelists the fields GetStages can be sorted by
+var stageSortColumns = map[string]string{
+	"stage_id":       "_id",
+	"room_number":    "room_number",
+	"seat_capacity":  "seat_capacity",
+	"price_per_seat": "price_per_seat",
+	"created_at":     "created_at",
+	"updated_at":     "updated_at",
+}
+
 type MongoPerformanceStageRepository struct {
 	base *BaseMongoRepository
 }
@@ -19,17 +30,29 @@ func NewMongoPerformanceStageRepository(collection *mongo.Collection) *MongoPerf
 	}
 }
 
-func (r *MongoPerformanceStageRepository) GetStages(ctx context.Context) ([]domain.PerformanceStage, error) {
+func (r *MongoPerformanceStageRepository) GetStages(ctx context.Context, filter port.StageFilter, query port.ListQuery) (*port.Page[domain.PerformanceStage], error) {
+	match := bson.M{}
+	if filter.RoomNumber != "" {
+		match["room_number"] = filter.RoomNumber
+	}
+	if filter.MinSeatCapacity > 0 {
+		match["seat_capacity"] = bson.M{"$gte": filter.MinSeatCapacity}
+	}
+	matchAudit(match, filter.Audit)
+
 	var stages []domain.PerformanceStage
-	if err := r.base.FindAll(ctx, bson.M{}, &stages); err != nil {
+	total, query, err := r.base.FindPage(ctx, match, query, stageSortColumns, "_id", &stages)
+	if err != nil {
 		return nil, err
 	}
-	return stages, nil
+	return port.NewPage(stages, total, query), nil
 }
 
 func (r *MongoPerformanceStageRepository) CreateStage(ctx context.Context, stage *domain.PerformanceStage) (*domain.PerformanceStage, error) {
 	// Generate UUID for new stage
 	stage.Id = uuid.New().String()
+	stage.Version = 1
+	stage.Audit = port.NewAudit(ctx)
 
 	if err := r.base.Create(ctx, stage); err != nil {
 		return nil, err
@@ -60,7 +83,7 @@ func (r *MongoPerformanceStageRepository) UpdateStage(ctx context.Context, id st
 	}
 
 	// Update the stage
-	if err := r.base.Update(ctx, id, updateData); err != nil {
+	if err := r.base.UpdateVersioned(ctx, id, stage.Version, updateData); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,642 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IndexSpec declares one index of a collection. Names follow MongoDB's default "<field>_<direction>" style
+// so that indexes created by hand before the registry existed are recognised.
+type IndexSpec struct {
+	Name   string
+	Keys   bson.D
+	Unique bool
+	// Partial limits the index to the documents matching the filter, such as the ones that have an optional field
+	Partial bson.M
+}
+
+// CollectionSpec declares the indexes and the JSON-schema validator a collection must have
+type CollectionSpec struct {
+	Name      string
+	Indexes   []IndexSpec
+	Validator bson.M
+	// Defaults are set on documents that lack the field, for fields added after documents were first written.
+	// They are applied with an update pipeline, so a value is an aggregation expression such as "$$NOW".
+	Defaults bson.M
+}
+
+// IndexDrift describes an index whose state in the database differs from the registry
+type IndexDrift struct {
+	Collection string
+	Index      string
+	Problem    string
+}
+
+func (d IndexDrift) String() string {
+	return fmt.Sprintf("%s.%s: %s", d.Collection, d.Index, d.Problem)
+}
+
+// SchemaReport is the outcome of checking or applying the registry
+type SchemaReport struct {
+	// Created lists the collections, indexes and backfilled fields EnsureSchema created,
+	// as "collection", "collection.index" or "collection.field on N document(s)"
+	Created []string
+	// Missing lists the indexes CheckSchema found absent
+	Missing []IndexDrift
+	// Drift lists indexes that exist with a different definition, or that the registry does not know about.
+	// They are reported but never dropped automatically.
+	Drift []IndexDrift
+}
+
+// schemaRegistry is the declarative index and validator registry of every collection used by the repositories
+var schemaRegistry = []CollectionSpec{
+	{
+		Name: "users",
+		Indexes: []IndexSpec{
+			{Name: "username_1", Keys: bson.D{{Key: "username", Value: 1}}, Unique: true},
+			// Users without an email or phone lack the field, so that they do not collide on an empty value
+			{Name: "email_1", Keys: bson.D{{Key: "email", Value: 1}}, Unique: true, Partial: bson.M{"email": bson.M{"$type": "string"}}},
+			{Name: "phone_1", Keys: bson.D{{Key: "phone", Value: 1}}, Unique: true, Partial: bson.M{"phone": bson.M{"$type": "string"}}},
+			{Name: "role_1", Keys: bson.D{{Key: "role", Value: 1}}},
+			{Name: "deleted_at_1", Keys: bson.D{{Key: "deleted_at", Value: 1}}},
+			{Name: "created_at_1", Keys: bson.D{{Key: "created_at", Value: 1}}},
+			{Name: "updated_at_1", Keys: bson.D{{Key: "updated_at", Value: 1}}},
+		},
+		Validator: jsonSchema([]string{"_id", "username", "password"}, bson.M{
+			"_id":               bson.M{"bsonType": "string"},
+			"username":          bson.M{"bsonType": "string", "minLength": 1},
+			"password":          bson.M{"bsonType": "string"},
+			"role":              bson.M{"bsonType": "string"},
+			"email":             bson.M{"bsonType": "string"},
+			"phone":             bson.M{"bsonType": "string", "pattern": `^\+[1-9][0-9]{6,14}$`},
+			"display_name":      bson.M{"bsonType": "string"},
+			"language":          bson.M{"bsonType": "string"},
+			"marketing_consent": bson.M{"bsonType": "bool"},
+			"verified_email":    bson.M{"bsonType": "string"},
+			"verified_phone":    bson.M{"bsonType": "string"},
+			"version":           versionProperty,
+			"deleted_at":        deletedAtProperty,
+			"created_at":        auditTimeProperty,
+			"updated_at":        auditTimeProperty,
+			"created_by":        auditActorProperty,
+			"updated_by":        auditActorProperty,
+		}),
+		Defaults: auditDefaults(bson.M{"version": 1}),
+	},
+	{
+		Name: "animals",
+		Indexes: []IndexSpec{
+			{Name: "species_1", Keys: bson.D{{Key: "species", Value: 1}}},
+			{Name: "type_1", Keys: bson.D{{Key: "type", Value: 1}}},
+			{Name: "deleted_at_1", Keys: bson.D{{Key: "deleted_at", Value: 1}}},
+			{Name: "created_at_1", Keys: bson.D{{Key: "created_at", Value: 1}}},
+			{Name: "updated_at_1", Keys: bson.D{{Key: "updated_at", Value: 1}}},
+		},
+		Validator: jsonSchema([]string{"_id", "name"}, bson.M{
+			"_id":           bson.M{"bsonType": "string"},
+			"name":          bson.M{"bsonType": "string"},
+			"species":       bson.M{"bsonType": "string"},
+			"type":          bson.M{"bsonType": "string"},
+			"show_duration": bson.M{"bsonType": "number", "minimum": 0},
+			"version":       versionProperty,
+			"deleted_at":    deletedAtProperty,
+			"created_at":    auditTimeProperty,
+			"updated_at":    auditTimeProperty,
+			"created_by":    auditActorProperty,
+			"updated_by":    auditActorProperty,
+		}),
+		Defaults: auditDefaults(bson.M{"version": 1}),
+	},
+	{
+		Name: "performance_stages",
+		Indexes: []IndexSpec{
+			{Name: "room_number_1", Keys: bson.D{{Key: "room_number", Value: 1}}},
+			{Name: "deleted_at_1", Keys: bson.D{{Key: "deleted_at", Value: 1}}},
+			{Name: "created_at_1", Keys: bson.D{{Key: "created_at", Value: 1}}},
+			{Name: "updated_at_1", Keys: bson.D{{Key: "updated_at", Value: 1}}},
+		},
+		Validator: jsonSchema([]string{"_id", "room_number", "seat_capacity"}, bson.M{
+			"_id":            bson.M{"bsonType": "string"},
+			"room_number":    bson.M{"bsonType": "string"},
+			"seat_capacity":  bson.M{"bsonType": "number", "minimum": 0},
+			"price_per_seat": bson.M{"bsonType": "number", "minimum": 0},
+			"version":        versionProperty,
+			"deleted_at":     deletedAtProperty,
+			"created_at":     auditTimeProperty,
+			"updated_at":     auditTimeProperty,
+			"created_by":     auditActorProperty,
+			"updated_by":     auditActorProperty,
+		}),
+		Defaults: auditDefaults(bson.M{"version": 1}),
+	},
+	{
+		Name: "show_rounds",
+		Indexes: []IndexSpec{
+			{Name: "animal_id_1", Keys: bson.D{{Key: "animal_id", Value: 1}}},
+			{Name: "stage_id_1", Keys: bson.D{{Key: "stage_id", Value: 1}}},
+			{Name: "show_time_1", Keys: bson.D{{Key: "show_time", Value: 1}}},
+			{Name: "deleted_at_1", Keys: bson.D{{Key: "deleted_at", Value: 1}}},
+			{Name: "created_at_1", Keys: bson.D{{Key: "created_at", Value: 1}}},
+			{Name: "updated_at_1", Keys: bson.D{{Key: "updated_at", Value: 1}}},
+		},
+		Validator: jsonSchema([]string{"_id", "animal_id", "stage_id", "show_time"}, bson.M{
+			"_id":        bson.M{"bsonType": "string"},
+			"animal_id":  bson.M{"bsonType": "string"},
+			"stage_id":   bson.M{"bsonType": "string"},
+			"show_time":  bson.M{"bsonType": "string"},
+			"version":    versionProperty,
+			"deleted_at": deletedAtProperty,
+			"created_at": auditTimeProperty,
+			"updated_at": auditTimeProperty,
+			"created_by": auditActorProperty,
+			"updated_by": auditActorProperty,
+		}),
+		Defaults: auditDefaults(bson.M{"version": 1}),
+	},
+	{
+		Name: "bookings",
+		Indexes: []IndexSpec{
+			{Name: "round_id_1", Keys: bson.D{{Key: "round_id", Value: 1}}},
+			// A seat of a show round is held by one confirmed booking at a time. Partial filters cannot match a missing
+			// field, so deleted_at joins the keys instead: live bookings all lack it and collide, while soft-deleted
+			// ones carry the time they were deleted and step aside.
+			{
+				Name:    "round_id_1_seat_number_1_deleted_at_1",
+				Keys:    bson.D{{Key: "round_id", Value: 1}, {Key: "seat_number", Value: 1}, {Key: "deleted_at", Value: 1}},
+				Unique:  true,
+				Partial: bson.M{"status": domain.BookingStatusConfirmed},
+			},
+			{Name: "user_id_1", Keys: bson.D{{Key: "user_id", Value: 1}}},
+			{Name: "status_1", Keys: bson.D{{Key: "status", Value: 1}}},
+			{Name: "deleted_at_1", Keys: bson.D{{Key: "deleted_at", Value: 1}}},
+			{Name: "created_at_1", Keys: bson.D{{Key: "created_at", Value: 1}}},
+			{Name: "updated_at_1", Keys: bson.D{{Key: "updated_at", Value: 1}}},
+		},
+		Validator: jsonSchema([]string{"_id", "round_id", "seat_number"}, bson.M{
+			"_id":         bson.M{"bsonType": "string"},
+			"user_id":     bson.M{"bsonType": "string"},
+			"round_id":    bson.M{"bsonType": "string"},
+			"seat_number": bson.M{"bsonType": "number", "minimum": 0},
+			"price":       bson.M{"bsonType": "number", "minimum": 0},
+			"qr_code":     bson.M{"bsonType": "string"},
+			"status":      bson.M{"bsonType": "string"},
+			"version":     versionProperty,
+			"deleted_at":  deletedAtProperty,
+			"created_at":  auditTimeProperty,
+			"updated_at":  auditTimeProperty,
+			"created_by":  auditActorProperty,
+			"updated_by":  auditActorProperty,
+		}),
+		// Bookings written before statuses existed are confirmed, and must hold their seat in the unique index
+		Defaults: auditDefaults(bson.M{"version": 1, "status": domain.BookingStatusConfirmed}),
+	},
+	{
+		// Entries are only ever inserted; grant the application role insert and find on this collection only
+		// to make MongoDB reject changes to them as well
+		Name: "audit_log_entries",
+		Indexes: []IndexSpec{
+			{Name: "occurred_at_1", Keys: bson.D{{Key: "occurred_at", Value: 1}}},
+			{Name: "actor_1", Keys: bson.D{{Key: "actor", Value: 1}}},
+			{Name: "action_1", Keys: bson.D{{Key: "action", Value: 1}}},
+			{Name: "entity_type_1_entity_id_1", Keys: bson.D{{Key: "entity_type", Value: 1}, {Key: "entity_id", Value: 1}}},
+		},
+		Validator: jsonSchema([]string{"_id", "occurred_at", "actor", "action", "entity_type"}, bson.M{
+			"_id":         bson.M{"bsonType": "string"},
+			"occurred_at": bson.M{"bsonType": "date"},
+			"actor":       bson.M{"bsonType": "string", "minLength": 1},
+			"action":      bson.M{"bsonType": "string", "minLength": 1},
+			"entity_type": bson.M{"bsonType": "string"},
+			"entity_id":   bson.M{"bsonType": "string"},
+			"before":      bson.M{"bsonType": "object"},
+			"after":       bson.M{"bsonType": "object"},
+			"ip":          bson.M{"bsonType": "string"},
+			"request_id":  bson.M{"bsonType": "string"},
+		}),
+	},
+	{
+		Name: "invitations",
+		Indexes: []IndexSpec{
+			{Name: "expires_at_1", Keys: bson.D{{Key: "expires_at", Value: 1}}},
+			{Name: "created_at_1", Keys: bson.D{{Key: "created_at", Value: 1}}},
+		},
+		Validator: jsonSchema([]string{"_id", "role", "expires_at"}, bson.M{
+			"_id":         bson.M{"bsonType": "string"},
+			"role":        bson.M{"bsonType": "string"},
+			"expires_at":  bson.M{"bsonType": "date"},
+			"accepted_at": bson.M{"bsonType": "date"},
+			"accepted_by": bson.M{"bsonType": "string"},
+			"created_at":  auditTimeProperty,
+			"updated_at":  auditTimeProperty,
+			"created_by":  auditActorProperty,
+			"updated_by":  auditActorProperty,
+		}),
+	},
+	{
+		Name: "login_attempts",
+		Indexes: []IndexSpec{
+			{Name: "locked_until_1", Keys: bson.D{{Key: "locked_until", Value: 1}}},
+		},
+		Validator: jsonSchema([]string{"_id", "failures", "last_failure_at"}, bson.M{
+			"_id":             bson.M{"bsonType": "string"},
+			"failures":        bson.M{"bsonType": "number", "minimum": 1},
+			"last_failure_at": bson.M{"bsonType": "date"},
+			"locked_until":    bson.M{"bsonType": "date"},
+		}),
+	},
+	{
+		Name: "mfa_enrollments",
+		Validator: jsonSchema([]string{"_id", "secret", "last_step", "recovery_codes"}, bson.M{
+			"_id":            bson.M{"bsonType": "string"},
+			"secret":         bson.M{"bsonType": "string", "minLength": 1},
+			"confirmed_at":   bson.M{"bsonType": "date"},
+			"last_step":      bson.M{"bsonType": "number", "minimum": 0},
+			"recovery_codes": bson.M{"bsonType": "array", "items": bson.M{"bsonType": "string"}},
+			"created_at":     auditTimeProperty,
+			"updated_at":     auditTimeProperty,
+			"created_by":     auditActorProperty,
+			"updated_by":     auditActorProperty,
+		}),
+	},
+	{
+		Name: "password_reset_tokens",
+		Indexes: []IndexSpec{
+			{Name: "user_id_1", Keys: bson.D{{Key: "user_id", Value: 1}}},
+		},
+		Validator: jsonSchema([]string{"_id", "user_id", "expires_at"}, bson.M{
+			"_id":        bson.M{"bsonType": "string", "minLength": 1},
+			"user_id":    bson.M{"bsonType": "string", "minLength": 1},
+			"expires_at": bson.M{"bsonType": "date"},
+			"created_at": auditTimeProperty,
+			"updated_at": auditTimeProperty,
+			"created_by": auditActorProperty,
+			"updated_by": auditActorProperty,
+		}),
+	},
+	{
+		Name: "session_revocations",
+		Validator: jsonSchema([]string{"_id", "revoked_at"}, bson.M{
+			"_id":        bson.M{"bsonType": "string"},
+			"revoked_at": bson.M{"bsonType": "date"},
+		}),
+	},
+	{
+		Name: "revoked_tokens",
+		Indexes: []IndexSpec{
+			{Name: "expires_at_1", Keys: bson.D{{Key: "expires_at", Value: 1}}},
+		},
+		Validator: jsonSchema([]string{"_id", "user_id", "expires_at", "revoked_at"}, bson.M{
+			"_id":        bson.M{"bsonType": "string", "minLength": 1},
+			"user_id":    bson.M{"bsonType": "string", "minLength": 1},
+			"expires_at": bson.M{"bsonType": "date"},
+			"revoked_at": bson.M{"bsonType": "date"},
+		}),
+	},
+	{
+		Name: "contact_verifications",
+		Indexes: []IndexSpec{
+			{Name: "user_id_1", Keys: bson.D{{Key: "user_id", Value: 1}}},
+		},
+		Validator: jsonSchema([]string{"_id", "user_id", "channel", "target", "code_hash", "expires_at"}, bson.M{
+			"_id":        bson.M{"bsonType": "string"},
+			"user_id":    bson.M{"bsonType": "string", "minLength": 1},
+			"channel":    bson.M{"bsonType": "string", "enum": bson.A{"email", "phone"}},
+			"target":     bson.M{"bsonType": "string", "minLength": 1},
+			"code_hash":  bson.M{"bsonType": "string", "minLength": 1},
+			"attempts":   bson.M{"bsonType": "number", "minimum": 0},
+			"expires_at": bson.M{"bsonType": "date"},
+			"created_at": auditTimeProperty,
+			"updated_at": auditTimeProperty,
+			"created_by": auditActorProperty,
+			"updated_by": auditActorProperty,
+		}),
+	},
+	{
+		Name: "signing_keys",
+		Indexes: []IndexSpec{
+			{Name: "expires_at_1", Keys: bson.D{{Key: "expires_at", Value: 1}}},
+		},
+		Validator: jsonSchema([]string{"_id", "algorithm", "private_key", "activates_at", "retires_at", "expires_at"}, bson.M{
+			"_id":          bson.M{"bsonType": "string", "minLength": 1},
+			"algorithm":    bson.M{"bsonType": "string", "enum": bson.A{"RS256", "EdDSA"}},
+			"private_key":  bson.M{"bsonType": "string", "minLength": 1},
+			"activates_at": bson.M{"bsonType": "date"},
+			"retires_at":   bson.M{"bsonType": "date"},
+			"expires_at":   bson.M{"bsonType": "date"},
+			"created_at":   auditTimeProperty,
+			"updated_at":   auditTimeProperty,
+			"created_by":   auditActorProperty,
+			"updated_by":   auditActorProperty,
+		}),
+	},
+	{
+		Name: "api_clients",
+		Indexes: []IndexSpec{
+			{Name: "name_1", Keys: bson.D{{Key: "name", Value: 1}}, Unique: true},
+		},
+		Validator: jsonSchema([]string{"_id", "name"}, bson.M{
+			"_id":         bson.M{"bsonType": "string", "minLength": 1},
+			"name":        bson.M{"bsonType": "string", "minLength": 1},
+			"description": bson.M{"bsonType": "string"},
+			"created_at":  auditTimeProperty,
+			"updated_at":  auditTimeProperty,
+			"created_by":  auditActorProperty,
+			"updated_by":  auditActorProperty,
+		}),
+	},
+	{
+		Name: "api_keys",
+		Indexes: []IndexSpec{
+			{Name: "key_hash_1", Keys: bson.D{{Key: "key_hash", Value: 1}}, Unique: true},
+			{Name: "client_id_1_created_at_1", Keys: bson.D{{Key: "client_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		},
+		Validator: jsonSchema([]string{"_id", "client_id", "prefix", "key_hash", "scopes", "expires_at"}, bson.M{
+			"_id":          bson.M{"bsonType": "string", "minLength": 1},
+			"client_id":    bson.M{"bsonType": "string", "minLength": 1},
+			"prefix":       bson.M{"bsonType": "string", "minLength": 1},
+			"key_hash":     bson.M{"bsonType": "string", "minLength": 1},
+			"scopes":       bson.M{"bsonType": "array", "items": bson.M{"bsonType": "string"}},
+			"expires_at":   bson.M{"bsonType": "date"},
+			"last_used_at": bson.M{"bsonType": "date"},
+			"revoked_at":   bson.M{"bsonType": "date"},
+			"created_at":   auditTimeProperty,
+			"updated_at":   auditTimeProperty,
+			"created_by":   auditActorProperty,
+			"updated_by":   auditActorProperty,
+		}),
+	},
+	{
+		Name: "oidc_logins",
+		Validator: jsonSchema([]string{"_id", "provider", "code_verifier", "nonce", "expires_at"}, bson.M{
+			"_id":           bson.M{"bsonType": "string", "minLength": 1},
+			"provider":      bson.M{"bsonType": "string", "minLength": 1},
+			"code_verifier": bson.M{"bsonType": "string", "minLength": 1},
+			"nonce":         bson.M{"bsonType": "string", "minLength": 1},
+			"user_id":       bson.M{"bsonType": "string"},
+			"expires_at":    bson.M{"bsonType": "date"},
+			"created_at":    auditTimeProperty,
+			"updated_at":    auditTimeProperty,
+			"created_by":    auditActorProperty,
+			"updated_by":    auditActorProperty,
+		}),
+	},
+	{
+		Name: "oidc_identities",
+		Indexes: []IndexSpec{
+			{Name: "provider_1_subject_1", Keys: bson.D{{Key: "provider", Value: 1}, {Key: "subject", Value: 1}}, Unique: true},
+			{Name: "user_id_1_created_at_1", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		},
+		Validator: jsonSchema([]string{"_id", "provider", "subject", "user_id"}, bson.M{
+			"_id":        bson.M{"bsonType": "string", "minLength": 1},
+			"provider":   bson.M{"bsonType": "string", "minLength": 1},
+			"subject":    bson.M{"bsonType": "string", "minLength": 1},
+			"user_id":    bson.M{"bsonType": "string", "minLength": 1},
+			"email":      bson.M{"bsonType": "string"},
+			"created_at": auditTimeProperty,
+			"updated_at": auditTimeProperty,
+			"created_by": auditActorProperty,
+			"updated_by": auditActorProperty,
+		}),
+	},
+	{
+		Name: "mfa_requirements",
+		Validator: jsonSchema([]string{"_id"}, bson.M{
+			"_id":        bson.M{"bsonType": "string"},
+			"created_at": auditTimeProperty,
+			"updated_at": auditTimeProperty,
+			"created_by": auditActorProperty,
+			"updated_by": auditActorProperty,
+		}),
+	},
+}
+
+// versionProperty validates the optimistic concurrency version every entity carries
+var versionProperty = bson.M{"bsonType": "number", "minimum": 1}
+
+// deletedAtProperty validates the soft-delete timestamp, which is absent on live documents
+var deletedAtProperty = bson.M{"bsonType": "date"}
+
+// auditTimeProperty and auditActorProperty validate the created and updated stamps
+var (
+	auditTimeProperty  = bson.M{"bsonType": "date"}
+	auditActorProperty = bson.M{"bsonType": "string"}
+)
+
+// auditDefaults adds the audit stamps to the defaults of a collection. Who wrote existing documents is unknown,
+// so they get an empty actor and the time the schema is applied.
+func auditDefaults(defaults bson.M) bson.M {
+	defaults["created_at"] = "$$NOW"
+	defaults["updated_at"] = "$$NOW"
+	defaults["created_by"] = ""
+	defaults["updated_by"] = ""
+	return defaults
+}
+
+// jsonSchema builds a $jsonSchema validator; documents may carry fields beyond the declared ones
+func jsonSchema(required []string, properties bson.M) bson.M {
+	return bson.M{"$jsonSchema": bson.M{
+		"bsonType":   "object",
+		"required":   required,
+		"properties": properties,
+	}}
+}
+
+// existingIndex is the part of a listIndexes entry the registry compares against
+type existingIndex struct {
+	Name    string `bson:"name"`
+	Key     bson.D `bson:"key"`
+	Unique  bool   `bson:"unique"`
+	Partial bson.M `bson:"partialFilterExpression"`
+}
+
+// EnsureSchema idempotently creates the registered collections, validators and missing indexes,
+// and backfills the declared defaults.
+// Conflicting or unknown indexes are left untouched and returned as drift.
+func EnsureSchema(ctx context.Context, db *mongo.Database) (*SchemaReport, error) {
+	report := &SchemaReport{}
+
+	names, err := db.ListCollectionNames(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, spec := range schemaRegistry {
+		if slices.Contains(names, spec.Name) {
+			// Moderate validation leaves updates of already invalid legacy documents possible
+			err = db.RunCommand(ctx, bson.D{
+				{Key: "collMod", Value: spec.Name},
+				{Key: "validator", Value: spec.Validator},
+				{Key: "validationLevel", Value: "moderate"},
+				{Key: "validationAction", Value: "error"},
+			}).Err()
+		} else {
+			err = db.CreateCollection(ctx, spec.Name, options.CreateCollection().
+				SetValidator(spec.Validator).
+				SetValidationLevel("moderate").
+				SetValidationAction("error"))
+			if err == nil {
+				report.Created = append(report.Created, spec.Name)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply validator to %s: %w", spec.Name, err)
+		}
+
+		for field, value := range spec.Defaults {
+			// An update pipeline, so that defaults may use aggregation variables such as $$NOW
+			result, err := db.Collection(spec.Name).UpdateMany(ctx,
+				bson.M{field: bson.M{"$exists": false}},
+				bson.A{bson.M{"$set": bson.M{field: value}}})
+			if err != nil {
+				return nil, fmt.Errorf("failed to backfill %s.%s: %w", spec.Name, field, err)
+			}
+			if result.ModifiedCount > 0 {
+				report.Created = append(report.Created, fmt.Sprintf("%s.%s on %d document(s)", spec.Name, field, result.ModifiedCount))
+			}
+		}
+
+		existing, err := listIndexes(ctx, db.Collection(spec.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		missing, drift := diffIndexes(spec, existing)
+		report.Drift = append(report.Drift, drift...)
+
+		for _, index := range missing {
+			indexOptions := options.Index().SetName(index.Name).SetUnique(index.Unique)
+			if index.Partial != nil {
+				indexOptions.SetPartialFilterExpression(index.Partial)
+			}
+			model := mongo.IndexModel{Keys: index.Keys, Options: indexOptions}
+			if _, err := db.Collection(spec.Name).Indexes().CreateOne(ctx, model); err != nil {
+				if mongo.IsDuplicateKeyError(err) {
+					return nil, fmt.Errorf("cannot create unique index %s.%s, duplicate values must be cleaned up first: %w", spec.Name, index.Name, err)
+				}
+				return nil, fmt.Errorf("failed to create index %s.%s: %w", spec.Name, index.Name, err)
+			}
+			report.Created = append(report.Created, spec.Name+"."+index.Name)
+		}
+	}
+
+	return report, nil
+}
+
+// CheckSchema compares the indexes in the database with the registry without changing anything
+func CheckSchema(ctx context.Context, db *mongo.Database) (*SchemaReport, error) {
+	report := &SchemaReport{}
+
+	for _, spec := range schemaRegistry {
+		existing, err := listIndexes(ctx, db.Collection(spec.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		missing, drift := diffIndexes(spec, existing)
+		for _, index := range missing {
+			report.Missing = append(report.Missing, IndexDrift{Collection: spec.Name, Index: index.Name, Problem: "missing"})
+		}
+		report.Drift = append(report.Drift, drift...)
+	}
+
+	return report, nil
+}
+
+func listIndexes(ctx context.Context, collection *mongo.Collection) ([]existingIndex, error) {
+	cursor, err := collection.Indexes().List(ctx)
+	if err != nil {
+		// Listing the indexes of a collection that does not exist yet fails with NamespaceNotFound
+		var commandErr mongo.CommandError
+		if errors.As(err, &commandErr) && commandErr.Name == "NamespaceNotFound" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var indexes []existingIndex
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+// diffIndexes splits the registry entries of a collection into the ones that still need creating
+// and reports existing indexes that conflict with the registry or are not part of it
+func diffIndexes(spec CollectionSpec, existing []existingIndex) ([]IndexSpec, []IndexDrift) {
+	byName := make(map[string]existingIndex, len(existing))
+	for _, index := range existing {
+		byName[index.Name] = index
+	}
+
+	var missing []IndexSpec
+	var drift []IndexDrift
+	declared := make(map[string]bool, len(spec.Indexes))
+	for _, index := range spec.Indexes {
+		declared[index.Name] = true
+
+		current, ok := byName[index.Name]
+		if !ok {
+			missing = append(missing, index)
+			continue
+		}
+		if !sameKeys(current.Key, index.Keys) {
+			drift = append(drift, IndexDrift{Collection: spec.Name, Index: index.Name, Problem: fmt.Sprintf("keys are %v, expected %v", current.Key, index.Keys)})
+		}
+		if current.Unique != index.Unique {
+			drift = append(drift, IndexDrift{Collection: spec.Name, Index: index.Name, Problem: fmt.Sprintf("unique is %t, expected %t", current.Unique, index.Unique)})
+		}
+		if (current.Partial == nil) != (index.Partial == nil) {
+			drift = append(drift, IndexDrift{Collection: spec.Name, Index: index.Name, Problem: fmt.Sprintf("partial filter is %v, expected %v", current.Partial, index.Partial)})
+		}
+	}
+
+	for _, index := range existing {
+		if index.Name != "_id_" && !declared[index.Name] {
+			drift = append(drift, IndexDrift{Collection: spec.Name, Index: index.Name, Problem: "not declared in the registry"})
+		}
+	}
+
+	return missing, drift
+}
+
+// sameKeys compares index key documents field by field; directions are compared numerically
+// because the server returns them as int32 or double while the registry uses int
+func sameKeys(a, b bson.D) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key {
+			return false
+		}
+		x, xok := toFloat(a[i].Value)
+		y, yok := toFloat(b[i].Value)
+		if xok != yok || (xok && x != y) || (!xok && !reflect.DeepEqual(a[i].Value, b[i].Value)) {
+			return false
+		}
+	}
+	return true
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
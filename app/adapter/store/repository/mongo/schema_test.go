@@ -0,0 +1,127 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSchemaRegistry(t *testing.T) {
+	collections := map[string]bool{}
+	for _, spec := range schemaRegistry {
+		assert.False(t, collections[spec.Name], "collection %s registered twice", spec.Name)
+		collections[spec.Name] = true
+		assert.NotNil(t, spec.Validator, "collection %s has no validator", spec.Name)
+
+		names := map[string]bool{}
+		for _, index := range spec.Indexes {
+			assert.False(t, names[index.Name], "index %s.%s declared twice", spec.Name, index.Name)
+			names[index.Name] = true
+		}
+	}
+
+	users := findSpec(t, "users")
+	assert.Contains(t, users.Indexes, IndexSpec{Name: "username_1", Keys: bson.D{{Key: "username", Value: 1}}, Unique: true})
+	assert.Contains(t, users.Indexes, IndexSpec{Name: "email_1", Keys: bson.D{{Key: "email", Value: 1}}, Unique: true, Partial: bson.M{"email": bson.M{"$type": "string"}}})
+
+	bookings := findSpec(t, "bookings")
+	assert.Contains(t, bookings.Indexes, IndexSpec{Name: "round_id_1", Keys: bson.D{{Key: "round_id", Value: 1}}})
+	assert.Contains(t, bookings.Indexes, IndexSpec{Name: "user_id_1", Keys: bson.D{{Key: "user_id", Value: 1}}})
+	assert.Contains(t, bookings.Indexes, IndexSpec{
+		Name:    "round_id_1_seat_number_1_deleted_at_1",
+		Keys:    bson.D{{Key: "round_id", Value: 1}, {Key: "seat_number", Value: 1}, {Key: "deleted_at", Value: 1}},
+		Unique:  true,
+		Partial: bson.M{"status": "confirmed"},
+	})
+}
+
+func TestDiffIndexes(t *testing.T) {
+	spec := CollectionSpec{
+		Name: "users",
+		Indexes: []IndexSpec{
+			{Name: "username_1", Keys: bson.D{{Key: "username", Value: 1}}, Unique: true},
+			{Name: "role_1", Keys: bson.D{{Key: "role", Value: 1}}},
+		},
+	}
+
+	t.Run("fresh collection", func(t *testing.T) {
+		missing, drift := diffIndexes(spec, nil)
+
+		assert.Len(t, missing, 2)
+		assert.Empty(t, drift)
+	})
+
+	t.Run("in sync", func(t *testing.T) {
+		existing := []existingIndex{
+			{Name: "_id_", Key: bson.D{{Key: "_id", Value: int32(1)}}},
+			{Name: "username_1", Key: bson.D{{Key: "username", Value: int32(1)}}, Unique: true},
+			{Name: "role_1", Key: bson.D{{Key: "role", Value: float64(1)}}},
+		}
+
+		missing, drift := diffIndexes(spec, existing)
+
+		assert.Empty(t, missing)
+		assert.Empty(t, drift)
+	})
+
+	t.Run("drift", func(t *testing.T) {
+		existing := []existingIndex{
+			{Name: "username_1", Key: bson.D{{Key: "username", Value: int32(1)}}},
+			{Name: "role_1", Key: bson.D{{Key: "role", Value: int32(-1)}}},
+			{Name: "legacy_email_1", Key: bson.D{{Key: "email", Value: int32(1)}}},
+		}
+
+		missing, drift := diffIndexes(spec, existing)
+
+		assert.Empty(t, missing)
+		assert.Equal(t, []IndexDrift{
+			{Collection: "users", Index: "username_1", Problem: "unique is false, expected true"},
+			{Collection: "users", Index: "role_1", Problem: "keys are [{role -1}], expected [{role 1}]"},
+			{Collection: "users", Index: "legacy_email_1", Problem: "not declared in the registry"},
+		}, drift)
+	})
+}
+
+func TestDiffPartialIndexes(t *testing.T) {
+	spec := CollectionSpec{
+		Name: "users",
+		Indexes: []IndexSpec{
+			{Name: "email_1", Keys: bson.D{{Key: "email", Value: 1}}, Unique: true, Partial: bson.M{"email": bson.M{"$type": "string"}}},
+		},
+	}
+
+	t.Run("in sync", func(t *testing.T) {
+		existing := []existingIndex{
+			{Name: "email_1", Key: bson.D{{Key: "email", Value: int32(1)}}, Unique: true, Partial: bson.M{"email": bson.M{"$type": "string"}}},
+		}
+
+		missing, drift := diffIndexes(spec, existing)
+
+		assert.Empty(t, missing)
+		assert.Empty(t, drift)
+	})
+
+	t.Run("full index where a partial one is declared", func(t *testing.T) {
+		existing := []existingIndex{
+			{Name: "email_1", Key: bson.D{{Key: "email", Value: int32(1)}}, Unique: true},
+		}
+
+		missing, drift := diffIndexes(spec, existing)
+
+		assert.Empty(t, missing)
+		assert.Equal(t, []IndexDrift{
+			{Collection: "users", Index: "email_1", Problem: "partial filter is map[], expected map[email:map[$type:string]]"},
+		}, drift)
+	})
+}
+
+func findSpec(t *testing.T, name string) CollectionSpec {
+	for _, spec := range schemaRegistry {
+		if spec.Name == name {
+			return spec
+		}
+	}
+	t.Fatalf("collection %s is not registered", name)
+	return CollectionSpec{}
+}
@@ -0,0 +1,64 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoSessionRepository struct {
+	collection    *mongo.Collection
+	revokedTokens *mongo.Collection
+}
+
+func NewMongoSessionRepository(collection *mongo.Collection, revokedTokens *mongo.Collection) *MongoSessionRepository {
+	return &MongoSessionRepository{collection: collection, revokedTokens: revokedTokens}
+}
+
+func (r *MongoSessionRepository) RevokeSessions(ctx context.Context, userId string, at time.Time) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"revoked_at": at}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": userId}, update, options.Update().SetUpsert(true))
+	return translateError(err)
+}
+
+func (r *MongoSessionRepository) GetSessionRevocation(ctx context.Context, userId string) (*domain.SessionRevocation, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var revocation domain.SessionRevocation
+	if err := r.collection.FindOne(ctx, bson.M{"_id": userId}).Decode(&revocation); err != nil {
+		return nil, translateError(err)
+	}
+	return &revocation, nil
+}
+
+func (r *MongoSessionRepository) RevokeToken(ctx context.Context, token *domain.RevokedToken) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.revokedTokens.DeleteMany(ctx, bson.M{"expires_at": bson.M{"$lte": time.Now()}}); err != nil {
+		return translateError(err)
+	}
+	update := bson.M{"$setOnInsert": bson.M{"user_id": token.UserId, "expires_at": token.ExpiresAt, "revoked_at": token.RevokedAt}}
+	_, err := r.revokedTokens.UpdateOne(ctx, bson.M{"_id": token.TokenId}, update, options.Update().SetUpsert(true))
+	return translateError(err)
+}
+
+func (r *MongoSessionRepository) IsTokenRevoked(ctx context.Context, tokenId string) (bool, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	count, err := r.revokedTokens.CountDocuments(ctx, bson.M{"_id": tokenId}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, translateError(err)
+	}
+	return count > 0, nil
+}
@@ -2,13 +2,26 @@ package mongo
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// showRoundSortColumns whitelists the fields GetAllShowRounds can be sorted by
+var showRoundSortColumns = map[string]string{
+	"round_id":   "_id",
+	"animal_id":  "animal_id",
+	"stage_id":   "stage_id",
+	"show_time":  "show_time",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
 type MongoShowRoundRepository struct {
 	base *BaseMongoRepository
 }
@@ -22,6 +35,8 @@ func NewMongoShowRoundRepository(collection *mongo.Collection) *MongoShowRoundRe
 func (r *MongoShowRoundRepository) CreateShowRound(ctx context.Context, showRound *domain.ShowRounds) (*domain.ShowRounds, error) {
 	// Generate UUID for new show round
 	showRound.Id = uuid.New().String()
+	showRound.Version = 1
+	showRound.Audit = port.NewAudit(ctx)
 
 	if err := r.base.Create(ctx, showRound); err != nil {
 		return nil, err
@@ -37,19 +52,76 @@ func (r *MongoShowRoundRepository) GetShowRoundById(ctx context.Context, id stri
 	return &showRound, nil
 }
 
-func (r *MongoShowRoundRepository) GetAllShowRounds(ctx context.Context) ([]*domain.ShowRounds, error) {
+// GetAllShowRounds lists show rounds. Show times are stored as RFC 3339 strings,
+// so the date range is compared on their UTC string form.
+func (r *MongoShowRoundRepository) GetAllShowRounds(ctx context.Context, filter port.ShowRoundFilter, query port.ListQuery) (*port.Page[*domain.ShowRounds], error) {
+	match := bson.M{}
+	if filter.AnimalId != "" {
+		match["animal_id"] = filter.AnimalId
+	}
+	if filter.StageId != "" {
+		match["stage_id"] = filter.StageId
+	}
+	if filter.Species != "" {
+		animalIds, err := r.animalIdsBySpecies(ctx, filter.Species)
+		if err != nil {
+			return nil, err
+		}
+		if filter.AnimalId != "" {
+			match["animal_id"] = bson.M{"$eq": filter.AnimalId, "$in": animalIds}
+		} else {
+			match["animal_id"] = bson.M{"$in": animalIds}
+		}
+	}
+	if filter.From != nil || filter.To != nil {
+		showTime := bson.M{}
+		if filter.From != nil {
+			showTime["$gte"] = filter.From.UTC().Format(time.RFC3339)
+		}
+		if filter.To != nil {
+			showTime["$lte"] = filter.To.UTC().Format(time.RFC3339)
+		}
+		match["show_time"] = showTime
+	}
+	matchAudit(match, filter.Audit)
+
 	var showRounds []*domain.ShowRounds
+	total, query, err := r.base.FindPage(ctx, match, query, showRoundSortColumns, "_id", &showRounds)
+	if err != nil {
+		return nil, err
+	}
+	return port.NewPage(showRounds, total, query), nil
+}
+
+// animalIdsBySpecies looks up the ids of every animal of a species in the animals collection
+func (r *MongoShowRoundRepository) animalIdsBySpecies(ctx context.Context, species string) ([]any, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
 
-	cursor, err := r.base.collection.Find(ctx, bson.M{})
+	animals := r.base.collection.Database().Collection("animals")
+	ids, err := animals.Distinct(ctx, "_id", live(bson.M{"species": species}))
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
+	if ids == nil {
+		ids = []any{}
+	}
+	return ids, nil
+}
 
-	if err := cursor.All(ctx, &showRounds); err != nil {
+func (r *MongoShowRoundRepository) GetShowRoundsByAnimalId(ctx context.Context, animalId string) ([]*domain.ShowRounds, error) {
+	var showRounds []*domain.ShowRounds
+	if err := r.base.FindAll(ctx, bson.M{"animal_id": animalId}, &showRounds); err != nil {
 		return nil, err
 	}
+	return showRounds, nil
+}
 
+func (r *MongoShowRoundRepository) GetShowRoundsByStageId(ctx context.Context, stageId string) ([]*domain.ShowRounds, error) {
+	var showRounds []*domain.ShowRounds
+	if err := r.base.FindAll(ctx, bson.M{"stage_id": stageId}, &showRounds); err != nil {
+		return nil, err
+	}
 	return showRounds, nil
 }
 
@@ -60,15 +132,20 @@ func (r *MongoShowRoundRepository) UpdateShowRound(ctx context.Context, id strin
 		return nil, err
 	}
 
-	// Prepare update data based on the ShowRounds model fields
-	updateData := bson.M{
+	// Like GORM's Updates, zero values leave the stored field untouched
+	updateData := bson.M{}
+	for field, value := range map[string]string{
 		"animal_id": showRound.AnimalId,
 		"stage_id":  showRound.StageId,
 		"show_time": showRound.ShowTime,
+	} {
+		if value != "" {
+			updateData[field] = value
+		}
 	}
 
 	// Update the show round
-	if err := r.base.Update(ctx, id, updateData); err != nil {
+	if err := r.base.UpdateVersioned(ctx, id, showRound.Version, updateData); err != nil {
 		return nil, err
 	}
 
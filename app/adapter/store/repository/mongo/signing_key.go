@@ -0,0 +1,72 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoSigningKeyRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoSigningKeyRepository(collection *mongo.Collection) *MongoSigningKeyRepository {
+	return &MongoSigningKeyRepository{collection: collection}
+}
+
+func (r *MongoSigningKeyRepository) CreateSigningKey(ctx context.Context, key *domain.SigningKey) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	key.Audit = port.NewAudit(ctx)
+	_, err := r.collection.InsertOne(ctx, key)
+	return translateError(err)
+}
+
+func (r *MongoSigningKeyRepository) ListSigningKeys(ctx context.Context) ([]domain.SigningKey, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "activates_at", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	keys := []domain.SigningKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, translateError(err)
+	}
+	return keys, nil
+}
+
+func (r *MongoSigningKeyRepository) UpdateSigningKeyPrivateKey(ctx context.Context, kid string, privateKey string) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	set := auditSet(ctx)
+	set["private_key"] = privateKey
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": kid}, bson.M{"$set": set})
+	if err != nil {
+		return translateError(err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoSigningKeyRepository) DeleteExpiredSigningKeys(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"expires_at": bson.M{"$lte": now}})
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return result.DeletedCount, nil
+}
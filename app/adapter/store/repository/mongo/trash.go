@@ -0,0 +1,184 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// trashSortColumns whitelists the fields ListTrash can be sorted by
+var trashSortColumns = map[string]string{
+	"id":         "_id",
+	"deleted_at": "deleted_at",
+}
+
+// MongoTrashRepository reaches the soft-deleted documents of every collection; each trash kind is named after its collection
+type MongoTrashRepository struct {
+	db *mongo.Database
+}
+
+func NewMongoTrashRepository(db *mongo.Database) *MongoTrashRepository {
+	return &MongoTrashRepository{db: db}
+}
+
+func (r *MongoTrashRepository) ListTrash(ctx context.Context, kind port.TrashKind, filter port.TrashFilter, query port.ListQuery) (*port.Page[port.TrashItem], error) {
+	if _, err := port.ParseTrashKind(string(kind)); err != nil {
+		return nil, err
+	}
+
+	deletedAt := bson.M{"$ne": nil}
+	if filter.DeletedBefore != nil {
+		deletedAt["$lt"] = filter.DeletedBefore.UTC()
+	}
+	match := bson.M{"deleted_at": deletedAt}
+
+	base := NewBaseMongoRepository(r.db.Collection(string(kind)))
+	switch kind {
+	case port.TrashUsers:
+		return listTrash[domain.Users](ctx, base, kind, match, query)
+	case port.TrashAnimals:
+		return listTrash[domain.Animals](ctx, base, kind, match, query)
+	case port.TrashStages:
+		return listTrash[domain.PerformanceStage](ctx, base, kind, match, query)
+	case port.TrashShowRounds:
+		return listTrash[domain.ShowRounds](ctx, base, kind, match, query)
+	default:
+		return listTrash[domain.Bookings](ctx, base, kind, match, query)
+	}
+}
+
+func (r *MongoTrashRepository) GetTrashItem(ctx context.Context, kind port.TrashKind, id string) (*port.TrashItem, error) {
+	if _, err := port.ParseTrashKind(string(kind)); err != nil {
+		return nil, err
+	}
+
+	var entity port.Trashable
+	switch kind {
+	case port.TrashUsers:
+		entity = &domain.Users{}
+	case port.TrashAnimals:
+		entity = &domain.Animals{}
+	case port.TrashStages:
+		entity = &domain.PerformanceStage{}
+	case port.TrashShowRounds:
+		entity = &domain.ShowRounds{}
+	default:
+		entity = &domain.Bookings{}
+	}
+
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	if err := r.db.Collection(string(kind)).FindOne(ctx, trashed(bson.M{"_id": id})).Decode(entity); err != nil {
+		return nil, translateError(err)
+	}
+	item := port.NewTrashItem(kind, entity)
+	return &item, nil
+}
+
+func (r *MongoTrashRepository) Restore(ctx context.Context, kind port.TrashKind, id string) error {
+	if _, err := port.ParseTrashKind(string(kind)); err != nil {
+		return err
+	}
+
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.Collection(string(kind)).UpdateOne(ctx, trashed(bson.M{"_id": id}), bson.M{
+		"$unset": bson.M{"deleted_at": ""},
+		"$set":   auditSet(ctx),
+		"$inc":   bson.M{"version": 1},
+	})
+	if err != nil {
+		return translateError(err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoTrashRepository) Purge(ctx context.Context, kind port.TrashKind, id string) error {
+	if _, err := port.ParseTrashKind(string(kind)); err != nil {
+		return err
+	}
+
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	collection := r.db.Collection(string(kind))
+	count, err := collection.CountDocuments(ctx, trashed(bson.M{"_id": id}))
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrNotFound
+	}
+
+	// Remove the trashed dependents first, mirroring the foreign keys of the SQL backends
+	switch kind {
+	case port.TrashUsers:
+		err = r.purgeBookings(ctx, bson.M{"user_id": id})
+	case port.TrashShowRounds:
+		err = r.purgeBookings(ctx, bson.M{"round_id": id})
+	case port.TrashAnimals:
+		err = r.purgeShowRounds(ctx, bson.M{"animal_id": id})
+	case port.TrashStages:
+		err = r.purgeShowRounds(ctx, bson.M{"stage_id": id})
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = collection.DeleteOne(ctx, trashed(bson.M{"_id": id}))
+	return err
+}
+
+// purgeShowRounds permanently removes the trashed show rounds matching filter together with their trashed bookings
+func (r *MongoTrashRepository) purgeShowRounds(ctx context.Context, filter bson.M) error {
+	showRounds := r.db.Collection(string(port.TrashShowRounds))
+	roundIds, err := showRounds.Distinct(ctx, "_id", trashed(filter))
+	if err != nil {
+		return err
+	}
+	if len(roundIds) > 0 {
+		if err := r.purgeBookings(ctx, bson.M{"round_id": bson.M{"$in": roundIds}}); err != nil {
+			return err
+		}
+	}
+	_, err = showRounds.DeleteMany(ctx, trashed(filter))
+	return err
+}
+
+// purgeBookings permanently removes the trashed bookings matching filter
+func (r *MongoTrashRepository) purgeBookings(ctx context.Context, filter bson.M) error {
+	_, err := r.db.Collection(string(port.TrashBookings)).DeleteMany(ctx, trashed(filter))
+	return err
+}
+
+// trashed restricts filter to documents that have been soft-deleted
+func trashed(filter bson.M) bson.M {
+	return bson.M{"$and": bson.A{filter, bson.M{"deleted_at": bson.M{"$ne": nil}}}}
+}
+
+// listTrash loads one page of the soft-deleted documents of T matched by filter
+func listTrash[T any, PT interface {
+	*T
+	port.Trashable
+}](ctx context.Context, base *BaseMongoRepository, kind port.TrashKind, filter bson.M, query port.ListQuery) (*port.Page[port.TrashItem], error) {
+	var documents []T
+	total, query, err := base.findPage(ctx, filter, query, trashSortColumns, "_id", &documents)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]port.TrashItem, len(documents))
+	for i := range documents {
+		items[i] = port.NewTrashItem(kind, PT(&documents[i]))
+	}
+	return port.NewPage(items, total, query), nil
+}
@@ -0,0 +1,76 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// errNotTransactional is returned for a standalone server whose use without transactions was not allowed
+var errNotTransactional = errors.New("MongoDB is neither a replica set member nor mongos, so units of work cannot run in transactions; " +
+	"run a replica set, or set MONGO_ALLOW_STANDALONE=true to accept changes that are not atomic")
+
+// MongoUnitOfWork runs a group of repository calls inside one multi-document transaction.
+// The repositories join it automatically because the session travels in the context they receive.
+type MongoUnitOfWork struct {
+	client *mongo.Client
+	// transactional is false only on a standalone server whose use was allowed explicitly
+	transactional bool
+}
+
+// NewMongoUnitOfWork creates a unit of work on the client behind db. Transactions need a replica set member or
+// mongos, so a standalone server is refused unless allowStandalone is set; units of work then run their calls one
+// by one, and one failing halfway keeps the changes made before it.
+func NewMongoUnitOfWork(db *mongo.Database, allowStandalone bool) (*MongoUnitOfWork, error) {
+	ctx, cancel := common.ContextWithTimeout(context.Background())
+	defer cancel()
+
+	transactional, err := supportsTransactions(ctx, db.Client())
+	if err != nil {
+		return nil, fmt.Errorf("failed to check whether MongoDB supports transactions: %w", err)
+	}
+	if !transactional {
+		if !allowStandalone {
+			return nil, errNotTransactional
+		}
+		log.Println("Warning: MongoDB is not a replica set, units of work run without transactions and are not atomic")
+	}
+	return &MongoUnitOfWork{client: db.Client(), transactional: transactional}, nil
+}
+
+// Do runs fn in a transaction that is committed when fn returns nil and aborted otherwise.
+// Transient transaction errors are retried by the driver, so fn may run more than once.
+// A Do nested inside another joins the outer transaction.
+func (u *MongoUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if mongo.SessionFromContext(ctx) != nil || !u.transactional {
+		return fn(ctx)
+	}
+
+	session, err := u.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (any, error) {
+		return nil, fn(sessionCtx)
+	})
+	return err
+}
+
+// supportsTransactions reports whether the server is a replica set member or mongos
+func supportsTransactions(ctx context.Context, client *mongo.Client) (bool, error) {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false, err
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid", nil
+}
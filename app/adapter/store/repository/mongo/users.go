@@ -2,15 +2,23 @@ package mongo
 
 import (
 	"context"
-	"errors"
 
 	"github.com/google/uuid"
 	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// liveBookingsStage drops the soft-deleted bookings joined by $lookup; $lte against null holds for a missing or null deleted_at
+var liveBookingsStage = bson.D{{Key: "$addFields", Value: bson.M{
+	"bookings": bson.M{"$filter": bson.M{
+		"input": "$bookings",
+		"cond":  bson.M{"$lte": bson.A{"$$this.deleted_at", nil}},
+	}},
+}}}
+
 type MongoUserRepository struct {
 	base *BaseMongoRepository
 	db   *mongo.Database
@@ -24,8 +32,12 @@ func NewMongoUserRepository(collection *mongo.Collection) *MongoUserRepository {
 }
 
 func (r *MongoUserRepository) CreateUser(ctx context.Context, user *domain.Users) (*domain.Users, error) {
-	// Generate UUID for new user
-	user.Id = uuid.New().String()
+	// Generate UUID for new user, unless the caller picked one to reference it before it is stored
+	if user.Id == "" {
+		user.Id = uuid.New().String()
+	}
+	user.Version = 1
+	user.Audit = port.NewAudit(ctx)
 
 	if err := r.base.Create(ctx, user); err != nil {
 		return nil, err
@@ -38,13 +50,14 @@ func (r *MongoUserRepository) GetUserById(ctx context.Context, id string) (*doma
 	defer cancel()
 
 	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: bson.M{"_id": id}}},
+		{{Key: "$match", Value: live(bson.M{"_id": id})}},
 		{{Key: "$lookup", Value: bson.M{
 			"from":         "bookings",
 			"localField":   "_id",
 			"foreignField": "user_id",
 			"as":           "bookings",
 		}}},
+		liveBookingsStage,
 	}
 
 	cursor, err := r.base.collection.Aggregate(ctx, pipeline)
@@ -59,7 +72,7 @@ func (r *MongoUserRepository) GetUserById(ctx context.Context, id string) (*doma
 	}
 
 	if len(users) == 0 {
-		return nil, errors.New("user not found")
+		return nil, domain.ErrNotFound
 	}
 
 	return &users[0], nil
@@ -70,13 +83,14 @@ func (r *MongoUserRepository) GetUsersByRole(ctx context.Context, role string) (
 	defer cancel()
 
 	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: bson.M{"role": role}}},
+		{{Key: "$match", Value: live(bson.M{"role": role})}},
 		{{Key: "$lookup", Value: bson.M{
 			"from":         "bookings",
 			"localField":   "_id",
 			"foreignField": "user_id",
 			"as":           "bookings",
 		}}},
+		liveBookingsStage,
 	}
 
 	cursor, err := r.base.collection.Aggregate(ctx, pipeline)
@@ -98,8 +112,8 @@ func (r *MongoUserRepository) GetUserByUsername(ctx context.Context, username st
 	defer cancel()
 
 	var user domain.Users
-	if err := r.base.collection.FindOne(ctx, bson.M{"username": username}).Decode(&user); err != nil {
-		return nil, err
+	if err := r.base.collection.FindOne(ctx, live(bson.M{"username": username})).Decode(&user); err != nil {
+		return nil, translateError(err)
 	}
 
 	return &user, nil
@@ -112,15 +126,29 @@ func (r *MongoUserRepository) UpdateUser(ctx context.Context, id string, user *d
 		return nil, err
 	}
 
-	// Prepare update data
-	updateData := bson.M{
-		"username": user.Username,
-		"password": user.Password,
-		"role":     user.Role,
+	// Like GORM's Updates, zero values leave the stored field untouched
+	updateData := bson.M{}
+	for field, value := range map[string]string{
+		"username":       user.Username,
+		"password":       user.Password,
+		"role":           user.Role,
+		"email":          user.Email,
+		"phone":          user.Phone,
+		"display_name":   user.DisplayName,
+		"language":       user.Language,
+		"verified_email": user.VerifiedEmail,
+		"verified_phone": user.VerifiedPhone,
+	} {
+		if value != "" {
+			updateData[field] = value
+		}
+	}
+	if user.MarketingConsent != nil {
+		updateData["marketing_consent"] = *user.MarketingConsent
 	}
 
 	// Update the user
-	if err := r.base.Update(ctx, id, updateData); err != nil {
+	if err := r.base.UpdateVersioned(ctx, id, user.Version, updateData); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,65 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/common"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoVerificationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoVerificationRepository(collection *mongo.Collection) *MongoVerificationRepository {
+	return &MongoVerificationRepository{collection: collection}
+}
+
+func (r *MongoVerificationRepository) SaveVerification(ctx context.Context, verification *domain.ContactVerification) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	verification.Audit = port.NewAudit(ctx)
+	set := auditSet(ctx)
+	set["user_id"] = verification.UserId
+	set["channel"] = verification.Channel
+	set["target"] = verification.Target
+	set["code_hash"] = verification.CodeHash
+	set["attempts"] = verification.Attempts
+	set["expires_at"] = verification.ExpiresAt
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": bson.M{"created_at": verification.CreatedAt, "created_by": verification.CreatedBy},
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": verification.Key}, update, options.Update().SetUpsert(true))
+	return translateError(err)
+}
+
+func (r *MongoVerificationRepository) GetVerification(ctx context.Context, key string) (*domain.ContactVerification, error) {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	var verification domain.ContactVerification
+	if err := r.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&verification); err != nil {
+		return nil, translateError(err)
+	}
+	return &verification, nil
+}
+
+func (r *MongoVerificationRepository) DeleteVerification(ctx context.Context, key string) error {
+	ctx, cancel := common.ContextWithTimeout(ctx)
+	defer cancel()
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": key})
+	if err != nil {
+		return translateError(err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
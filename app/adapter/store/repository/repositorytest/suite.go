@@ -0,0 +1,1488 @@
+// Package repositorytest holds the behavioural test suite shared by every repository backend.
+// Each backend runs it from its own tests so that all of them keep the same semantics.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Repositories is one backend's implementation of every repository port, all sharing one empty database
+type Repositories struct {
+	Users          port.UsersRepository
+	Animals        port.AnimalsRepository
+	Stages         port.PerformanceStageRepository
+	ShowRounds     port.ShowRoundsRepository
+	Bookings       port.BookingsRepository
+	UnitOfWork     port.UnitOfWork
+	Trash          port.TrashRepository
+	AuditLog       port.AuditLogRepository
+	Invitations    port.InvitationRepository
+	LoginAttempts  port.LoginAttemptRepository
+	Mfa            port.MfaRepository
+	PasswordResets port.PasswordResetRepository
+	Sessions       port.SessionRepository
+	Verifications  port.VerificationRepository
+	SigningKeys    port.SigningKeyRepository
+	ApiKeys        port.ApiKeyRepository
+	Oidc           port.OidcRepository
+}
+
+// Run executes the suite; open must return repositories backed by a fresh, empty database on every call
+func Run(t *testing.T, open func(t *testing.T) Repositories) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, open(t)) })
+	t.Run("Animals", func(t *testing.T) { testAnimals(t, open(t)) })
+	t.Run("Stages", func(t *testing.T) { testStages(t, open(t)) })
+	t.Run("ShowRounds", func(t *testing.T) { testShowRounds(t, open(t)) })
+	t.Run("Bookings", func(t *testing.T) { testBookings(t, open(t)) })
+	t.Run("UnitOfWork", func(t *testing.T) { testUnitOfWork(t, open(t)) })
+	t.Run("Versioning", func(t *testing.T) { testVersioning(t, open(t)) })
+	t.Run("Trash", func(t *testing.T) { testTrash(t, open(t)) })
+	t.Run("Audit", func(t *testing.T) { testAudit(t, open(t)) })
+	t.Run("AuditLog", func(t *testing.T) { testAuditLog(t, open(t)) })
+	t.Run("Invitations", func(t *testing.T) { testInvitations(t, open(t)) })
+	t.Run("LoginAttempts", func(t *testing.T) { testLoginAttempts(t, open(t)) })
+	t.Run("Mfa", func(t *testing.T) { testMfa(t, open(t)) })
+	t.Run("PasswordResets", func(t *testing.T) { testPasswordResets(t, open(t)) })
+	t.Run("Sessions", func(t *testing.T) { testSessions(t, open(t)) })
+	t.Run("Profiles", func(t *testing.T) { testProfiles(t, open(t)) })
+	t.Run("Verifications", func(t *testing.T) { testVerifications(t, open(t)) })
+	t.Run("SigningKeys", func(t *testing.T) { testSigningKeys(t, open(t)) })
+	t.Run("ApiKeys", func(t *testing.T) { testApiKeys(t, open(t)) })
+	t.Run("Oidc", func(t *testing.T) { testOidc(t, open(t)) })
+}
+
+func testUsers(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	alice, err := repos.Users.CreateUser(ctx, &domain.Users{Username: "alice", Password: "hash"})
+	require.NoError(t, err)
+	require.NotEmpty(t, alice.Id)
+
+	t.Run("get", func(t *testing.T) {
+		user, err := repos.Users.GetUserById(ctx, alice.Id)
+
+		require.NoError(t, err)
+		assert.Equal(t, "alice", user.Username)
+		assert.Equal(t, "user", user.Role)
+		assert.Empty(t, user.Bookings)
+	})
+
+	t.Run("get by username", func(t *testing.T) {
+		user, err := repos.Users.GetUserByUsername(ctx, "alice")
+
+		require.NoError(t, err)
+		assert.Equal(t, alice.Id, user.Id)
+	})
+
+	t.Run("duplicate username", func(t *testing.T) {
+		_, err := repos.Users.CreateUser(ctx, &domain.Users{Username: "alice", Password: "other"})
+
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	})
+
+	t.Run("keeps the id picked by the caller", func(t *testing.T) {
+		id := uuid.New().String()
+		user, err := repos.Users.CreateUser(ctx, &domain.Users{Id: id, Username: "preset", Password: "hash"})
+		require.NoError(t, err)
+		assert.Equal(t, id, user.Id)
+
+		stored, err := repos.Users.GetUserById(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "preset", stored.Username)
+
+		_, err = repos.Users.CreateUser(ctx, &domain.Users{Id: id, Username: "another", Password: "hash"})
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := repos.Users.GetUserById(ctx, "missing")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		_, err = repos.Users.GetUserByUsername(ctx, "missing")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		_, err = repos.Users.UpdateUser(ctx, "missing", &domain.Users{Role: "admin"})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("update keeps zero fields", func(t *testing.T) {
+		user, err := repos.Users.UpdateUser(ctx, alice.Id, &domain.Users{Role: "admin"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "admin", user.Role)
+		assert.Equal(t, "alice", user.Username)
+		assert.Equal(t, "hash", user.Password)
+
+		user, err = repos.Users.UpdateUser(ctx, alice.Id, &domain.Users{Email: "alice@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", user.Email)
+		assert.Equal(t, "admin", user.Role)
+	})
+
+	t.Run("by role", func(t *testing.T) {
+		_, err := repos.Users.CreateUser(ctx, &domain.Users{Username: "bob", Password: "hash", Role: "user"})
+		require.NoError(t, err)
+
+		admins, err := repos.Users.GetUsersByRole(ctx, "admin")
+
+		require.NoError(t, err)
+		require.Len(t, admins, 1)
+		assert.Equal(t, alice.Id, admins[0].Id)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, repos.Users.DeleteUser(ctx, alice.Id))
+
+		_, err := repos.Users.GetUserById(ctx, alice.Id)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func testAnimals(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	for i := 1; i <= 5; i++ {
+		_, err := repos.Animals.CreateAnimal(ctx, &domain.Animals{
+			Name:         fmt.Sprintf("lion-%d", i),
+			Species:      "lion",
+			Type:         "mammal",
+			ShowDuration: i * 10,
+		})
+		require.NoError(t, err)
+	}
+	eagle, err := repos.Animals.CreateAnimal(ctx, &domain.Animals{Name: "eagle", Species: "eagle", Type: "bird", ShowDuration: 15})
+	require.NoError(t, err)
+
+	t.Run("get", func(t *testing.T) {
+		animal, err := repos.Animals.GetAnimalById(ctx, eagle.Id)
+
+		require.NoError(t, err)
+		assert.Equal(t, "eagle", animal.Name)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := repos.Animals.GetAnimalById(ctx, "missing")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		_, err = repos.Animals.UpdateAnimal(ctx, "missing", &domain.Animals{Name: "ghost"})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("filter sort and cursor", func(t *testing.T) {
+		filter := port.AnimalFilter{Species: "lion"}
+		query := port.ListQuery{Limit: 2, Sort: port.ParseSort("-show_duration")}
+
+		first, err := repos.Animals.GetAnimals(ctx, filter, query)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), first.Total)
+		assert.Equal(t, []string{"lion-5", "lion-4"}, animalNames(first.Items))
+		require.NotEmpty(t, first.NextCursor)
+
+		query.Cursor = first.NextCursor
+		second, err := repos.Animals.GetAnimals(ctx, filter, query)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"lion-3", "lion-2"}, animalNames(second.Items))
+
+		query.Cursor = second.NextCursor
+		last, err := repos.Animals.GetAnimals(ctx, filter, query)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"lion-1"}, animalNames(last.Items))
+		assert.Empty(t, last.NextCursor)
+	})
+
+	t.Run("filter by type", func(t *testing.T) {
+		page, err := repos.Animals.GetAnimals(ctx, port.AnimalFilter{Type: "bird"}, port.ListQuery{})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"eagle"}, animalNames(page.Items))
+	})
+
+	t.Run("unknown sort field", func(t *testing.T) {
+		_, err := repos.Animals.GetAnimals(ctx, port.AnimalFilter{}, port.ListQuery{Sort: port.ParseSort("password")})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+	})
+
+	t.Run("update keeps zero fields", func(t *testing.T) {
+		animal, err := repos.Animals.UpdateAnimal(ctx, eagle.Id, &domain.Animals{Name: "golden eagle"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "golden eagle", animal.Name)
+		assert.Equal(t, "bird", animal.Type)
+		assert.Equal(t, 15, animal.ShowDuration)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, repos.Animals.DeleteAnimal(ctx, eagle.Id))
+
+		_, err := repos.Animals.GetAnimalById(ctx, eagle.Id)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func testStages(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	small, err := repos.Stages.CreateStage(ctx, &domain.PerformanceStage{RoomNumber: "A1", SeatCapacity: 20, PricePerSeat: 100})
+	require.NoError(t, err)
+	large, err := repos.Stages.CreateStage(ctx, &domain.PerformanceStage{RoomNumber: "B1", SeatCapacity: 200, PricePerSeat: 50})
+	require.NoError(t, err)
+
+	t.Run("get", func(t *testing.T) {
+		stage, err := repos.Stages.GetStageById(ctx, small.Id)
+
+		require.NoError(t, err)
+		assert.Equal(t, "A1", stage.RoomNumber)
+		assert.Equal(t, 100.0, stage.PricePerSeat)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := repos.Stages.GetStageById(ctx, "missing")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		_, err = repos.Stages.UpdateStage(ctx, "missing", &domain.PerformanceStage{RoomNumber: "Z9"})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("minimum seat capacity", func(t *testing.T) {
+		page, err := repos.Stages.GetStages(ctx, port.StageFilter{MinSeatCapacity: 50}, port.ListQuery{})
+
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, large.Id, page.Items[0].Id)
+	})
+
+	t.Run("sort by price", func(t *testing.T) {
+		page, err := repos.Stages.GetStages(ctx, port.StageFilter{}, port.ListQuery{Sort: port.ParseSort("price_per_seat")})
+
+		require.NoError(t, err)
+		require.Len(t, page.Items, 2)
+		assert.Equal(t, large.Id, page.Items[0].Id)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, repos.Stages.DeleteStage(ctx, small.Id))
+
+		_, err := repos.Stages.GetStageById(ctx, small.Id)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func testShowRounds(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	lion, err := repos.Animals.CreateAnimal(ctx, &domain.Animals{Name: "Leo", Species: "lion"})
+	require.NoError(t, err)
+	eagle, err := repos.Animals.CreateAnimal(ctx, &domain.Animals{Name: "Sky", Species: "eagle"})
+	require.NoError(t, err)
+	stage, err := repos.Stages.CreateStage(ctx, &domain.PerformanceStage{RoomNumber: "A1", SeatCapacity: 20})
+	require.NoError(t, err)
+	otherStage, err := repos.Stages.CreateStage(ctx, &domain.PerformanceStage{RoomNumber: "B1", SeatCapacity: 20})
+	require.NoError(t, err)
+
+	morning, err := repos.ShowRounds.CreateShowRound(ctx, &domain.ShowRounds{AnimalId: lion.Id, StageId: stage.Id, ShowTime: "2025-01-01T09:00:00Z"})
+	require.NoError(t, err)
+	evening, err := repos.ShowRounds.CreateShowRound(ctx, &domain.ShowRounds{AnimalId: lion.Id, StageId: stage.Id, ShowTime: "2025-01-01T18:00:00Z"})
+	require.NoError(t, err)
+	noon, err := repos.ShowRounds.CreateShowRound(ctx, &domain.ShowRounds{AnimalId: eagle.Id, StageId: otherStage.Id, ShowTime: "2025-01-01T12:00:00Z"})
+	require.NoError(t, err)
+
+	t.Run("get", func(t *testing.T) {
+		showRound, err := repos.ShowRounds.GetShowRoundById(ctx, morning.Id)
+
+		require.NoError(t, err)
+		assert.Equal(t, lion.Id, showRound.AnimalId)
+		assert.Equal(t, stage.Id, showRound.StageId)
+		assert.Equal(t, "2025-01-01T09:00:00Z", showRound.ShowTime)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := repos.ShowRounds.GetShowRoundById(ctx, "missing")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		_, err = repos.ShowRounds.UpdateShowRound(ctx, "missing", &domain.ShowRounds{ShowTime: "2025-01-02T09:00:00Z"})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("by animal and stage", func(t *testing.T) {
+		byAnimal, err := repos.ShowRounds.GetShowRoundsByAnimalId(ctx, lion.Id)
+		require.NoError(t, err)
+		assert.Len(t, byAnimal, 2)
+
+		byStage, err := repos.ShowRounds.GetShowRoundsByStageId(ctx, otherStage.Id)
+		require.NoError(t, err)
+		require.Len(t, byStage, 1)
+		assert.Equal(t, noon.Id, byStage[0].Id)
+	})
+
+	t.Run("species and time range", func(t *testing.T) {
+		from := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		to := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+
+		page, err := repos.ShowRounds.GetAllShowRounds(ctx, port.ShowRoundFilter{Species: "lion", From: &from, To: &to}, port.ListQuery{})
+
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, evening.Id, page.Items[0].Id)
+	})
+
+	t.Run("sort by show time", func(t *testing.T) {
+		page, err := repos.ShowRounds.GetAllShowRounds(ctx, port.ShowRoundFilter{}, port.ListQuery{Sort: port.ParseSort("-show_time")})
+
+		require.NoError(t, err)
+		require.Len(t, page.Items, 3)
+		assert.Equal(t, []string{evening.Id, noon.Id, morning.Id}, []string{page.Items[0].Id, page.Items[1].Id, page.Items[2].Id})
+	})
+
+	t.Run("update keeps zero fields", func(t *testing.T) {
+		showRound, err := repos.ShowRounds.UpdateShowRound(ctx, noon.Id, &domain.ShowRounds{ShowTime: "2025-01-01T13:00:00Z"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "2025-01-01T13:00:00Z", showRound.ShowTime)
+		assert.Equal(t, eagle.Id, showRound.AnimalId)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, repos.ShowRounds.DeleteShowRound(ctx, noon.Id))
+
+		_, err := repos.ShowRounds.GetShowRoundById(ctx, noon.Id)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func testBookings(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	user, err := repos.Users.CreateUser(ctx, &domain.Users{Username: "alice", Password: "hash"})
+	require.NoError(t, err)
+	animal, err := repos.Animals.CreateAnimal(ctx, &domain.Animals{Name: "Leo", Species: "lion"})
+	require.NoError(t, err)
+	stage, err := repos.Stages.CreateStage(ctx, &domain.PerformanceStage{RoomNumber: "A1", SeatCapacity: 20})
+	require.NoError(t, err)
+	round, err := repos.ShowRounds.CreateShowRound(ctx, &domain.ShowRounds{AnimalId: animal.Id, StageId: stage.Id, ShowTime: "2025-01-01T09:00:00Z"})
+	require.NoError(t, err)
+
+	first, err := repos.Bookings.CreateBooking(ctx, &domain.Bookings{UserId: user.Id, RoundId: round.Id, SeatNumber: 1, Price: 100})
+	require.NoError(t, err)
+	second, err := repos.Bookings.CreateBooking(ctx, &domain.Bookings{UserId: user.Id, RoundId: round.Id, SeatNumber: 2, Price: 100, Status: domain.BookingStatusCancelled})
+	require.NoError(t, err)
+
+	t.Run("get with default status", func(t *testing.T) {
+		booking, err := repos.Bookings.GetBookingById(ctx, first.Id)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, booking.SeatNumber)
+		assert.Equal(t, domain.BookingStatusConfirmed, booking.Status)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := repos.Bookings.GetBookingById(ctx, "missing")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		_, err = repos.Bookings.UpdateBooking(ctx, "missing", &domain.Bookings{SeatNumber: 3})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("by user and round", func(t *testing.T) {
+		byUser, err := repos.Bookings.GetBookingsByUserId(ctx, user.Id)
+		require.NoError(t, err)
+		assert.Len(t, byUser, 2)
+
+		byRound, err := repos.Bookings.GetBookingsByRoundId(ctx, round.Id)
+		require.NoError(t, err)
+		assert.Len(t, byRound, 2)
+	})
+
+	t.Run("user preloads bookings", func(t *testing.T) {
+		result, err := repos.Users.GetUserById(ctx, user.Id)
+
+		require.NoError(t, err)
+		assert.Len(t, result.Bookings, 2)
+	})
+
+	t.Run("list by status", func(t *testing.T) {
+		page, err := repos.Bookings.ListBookings(ctx, port.BookingFilter{RoundId: round.Id, Status: domain.BookingStatusCancelled}, port.ListQuery{})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), page.Total)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, second.Id, page.Items[0].Id)
+	})
+
+	t.Run("update status", func(t *testing.T) {
+		booking, err := repos.Bookings.UpdateBooking(ctx, first.Id, &domain.Bookings{Status: domain.BookingStatusCancelled})
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.BookingStatusCancelled, booking.Status)
+		assert.Equal(t, 1, booking.SeatNumber)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, repos.Bookings.DeleteBooking(ctx, first.Id))
+
+		_, err := repos.Bookings.GetBookingById(ctx, first.Id)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func animalNames(animals []domain.Animals) []string {
+	names := make([]string, len(animals))
+	for i, animal := range animals {
+		names[i] = animal.Name
+	}
+	return names
+}
+
+func testUnitOfWork(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	t.Run("commit", func(t *testing.T) {
+		var lion *domain.Animals
+		err := repos.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+			var err error
+			lion, err = repos.Animals.CreateAnimal(ctx, &domain.Animals{Name: "Leo", Species: "lion"})
+			if err != nil {
+				return err
+			}
+			_, err = repos.Stages.CreateStage(ctx, &domain.PerformanceStage{RoomNumber: "A1", SeatCapacity: 10})
+			return err
+		})
+		require.NoError(t, err)
+
+		_, err = repos.Animals.GetAnimalById(ctx, lion.Id)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		stage, err := repos.Stages.CreateStage(ctx, &domain.PerformanceStage{RoomNumber: "B1", SeatCapacity: 10})
+		require.NoError(t, err)
+
+		var tiger *domain.Animals
+		err = repos.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+			var err error
+			tiger, err = repos.Animals.CreateAnimal(ctx, &domain.Animals{Name: "Shere Khan", Species: "tiger"})
+			if err != nil {
+				return err
+			}
+			if err := repos.Stages.DeleteStage(ctx, stage.Id); err != nil {
+				return err
+			}
+
+			// Reads inside the unit of work see its own writes
+			_, err = repos.Stages.GetStageById(ctx, stage.Id)
+			assert.ErrorIs(t, err, domain.ErrNotFound)
+			return errAbort
+		})
+		require.ErrorIs(t, err, errAbort)
+
+		_, err = repos.Animals.GetAnimalById(ctx, tiger.Id)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = repos.Stages.GetStageById(ctx, stage.Id)
+		assert.NoError(t, err)
+	})
+
+	t.Run("nested joins the outer unit", func(t *testing.T) {
+		var bear *domain.Animals
+		err := repos.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+			err := repos.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+				var err error
+				bear, err = repos.Animals.CreateAnimal(ctx, &domain.Animals{Name: "Baloo", Species: "bear"})
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			return errAbort
+		})
+		require.ErrorIs(t, err, errAbort)
+
+		_, err = repos.Animals.GetAnimalById(ctx, bear.Id)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func testVersioning(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	animal, err := repos.Animals.CreateAnimal(ctx, &domain.Animals{Name: "Leo", Species: "lion", Version: 7})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), animal.Version, "new entities start at version 1")
+
+	t.Run("update with the current version", func(t *testing.T) {
+		updated, err := repos.Animals.UpdateAnimal(ctx, animal.Id, &domain.Animals{Name: "Leo II", Version: 1})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), updated.Version)
+		assert.Equal(t, "Leo II", updated.Name)
+	})
+
+	t.Run("update with a stale version", func(t *testing.T) {
+		_, err := repos.Animals.UpdateAnimal(ctx, animal.Id, &domain.Animals{Name: "Lost update", Version: 1})
+		assert.ErrorIs(t, err, domain.ErrVersionConflict)
+
+		stored, err := repos.Animals.GetAnimalById(ctx, animal.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "Leo II", stored.Name)
+		assert.Equal(t, int64(2), stored.Version)
+	})
+
+	t.Run("update without a version still bumps it", func(t *testing.T) {
+		updated, err := repos.Animals.UpdateAnimal(ctx, animal.Id, &domain.Animals{Species: "panthera leo"})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), updated.Version)
+	})
+
+	t.Run("every entity is versioned", func(t *testing.T) {
+		user, err := repos.Users.CreateUser(ctx, &domain.Users{Username: "versioned", Password: "hash"})
+		require.NoError(t, err)
+		stage, err := repos.Stages.CreateStage(ctx, &domain.PerformanceStage{RoomNumber: "V1", SeatCapacity: 5})
+		require.NoError(t, err)
+		showRound, err := repos.ShowRounds.CreateShowRound(ctx, &domain.ShowRounds{AnimalId: animal.Id, StageId: stage.Id, ShowTime: "2025-01-01T10:00:00Z"})
+		require.NoError(t, err)
+		booking, err := repos.Bookings.CreateBooking(ctx, &domain.Bookings{UserId: user.Id, RoundId: showRound.Id, SeatNumber: 1})
+		require.NoError(t, err)
+
+		_, err = repos.Users.UpdateUser(ctx, user.Id, &domain.Users{Role: "admin", Version: 2})
+		assert.ErrorIs(t, err, domain.ErrVersionConflict)
+		_, err = repos.Stages.UpdateStage(ctx, stage.Id, &domain.PerformanceStage{SeatCapacity: 6, Version: 2})
+		assert.ErrorIs(t, err, domain.ErrVersionConflict)
+		_, err = repos.ShowRounds.UpdateShowRound(ctx, showRound.Id, &domain.ShowRounds{StageId: stage.Id, Version: 2})
+		assert.ErrorIs(t, err, domain.ErrVersionConflict)
+		_, err = repos.Bookings.UpdateBooking(ctx, booking.Id, &domain.Bookings{SeatNumber: 2, Version: 2})
+		assert.ErrorIs(t, err, domain.ErrVersionConflict)
+
+		updatedBooking, err := repos.Bookings.UpdateBooking(ctx, booking.Id, &domain.Bookings{SeatNumber: 2, Version: 1})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), updatedBooking.Version)
+	})
+}
+
+func testTrash(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	user, err := repos.Users.CreateUser(ctx, &domain.Users{Username: "carol", Password: "hash"})
+	require.NoError(t, err)
+	animal, err := repos.Animals.CreateAnimal(ctx, &domain.Animals{Name: "Leo", Species: "lion"})
+	require.NoError(t, err)
+	stage, err := repos.Stages.CreateStage(ctx, &domain.PerformanceStage{RoomNumber: "T1", SeatCapacity: 10})
+	require.NoError(t, err)
+	showRound, err := repos.ShowRounds.CreateShowRound(ctx, &domain.ShowRounds{AnimalId: animal.Id, StageId: stage.Id, ShowTime: "2025-01-01T10:00:00Z"})
+	require.NoError(t, err)
+	booking, err := repos.Bookings.CreateBooking(ctx, &domain.Bookings{UserId: user.Id, RoundId: showRound.Id, SeatNumber: 1})
+	require.NoError(t, err)
+
+	t.Run("delete hides the entity", func(t *testing.T) {
+		require.NoError(t, repos.Bookings.DeleteBooking(ctx, booking.Id))
+
+		_, err := repos.Bookings.GetBookingById(ctx, booking.Id)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = repos.Bookings.UpdateBooking(ctx, booking.Id, &domain.Bookings{SeatNumber: 2})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		byRound, err := repos.Bookings.GetBookingsByRoundId(ctx, showRound.Id)
+		require.NoError(t, err)
+		assert.Empty(t, byRound)
+		page, err := repos.Bookings.ListBookings(ctx, port.BookingFilter{}, port.ListQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), page.Total)
+		stored, err := repos.Users.GetUserById(ctx, user.Id)
+		require.NoError(t, err)
+		assert.Empty(t, stored.Bookings)
+
+		assert.NoError(t, repos.Bookings.DeleteBooking(ctx, booking.Id), "deleting again is a no-op")
+	})
+
+	t.Run("list trash", func(t *testing.T) {
+		page, err := repos.Trash.ListTrash(ctx, port.TrashBookings, port.TrashFilter{}, port.ListQuery{})
+
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		item := page.Items[0]
+		assert.Equal(t, port.TrashBookings, item.Kind)
+		assert.Equal(t, booking.Id, item.Id)
+		assert.False(t, item.DeletedAt.IsZero())
+		require.IsType(t, &domain.Bookings{}, item.Entity)
+		assert.Equal(t, int64(2), item.Entity.(*domain.Bookings).Version, "deleting bumps the version")
+
+		before := item.DeletedAt
+		expired, err := repos.Trash.ListTrash(ctx, port.TrashBookings, port.TrashFilter{DeletedBefore: &before}, port.ListQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, expired.Items)
+
+		later := time.Now().Add(time.Minute)
+		expired, err = repos.Trash.ListTrash(ctx, port.TrashBookings, port.TrashFilter{DeletedBefore: &later}, port.ListQuery{})
+		require.NoError(t, err)
+		assert.Len(t, expired.Items, 1)
+
+		live, err := repos.Trash.ListTrash(ctx, port.TrashUsers, port.TrashFilter{}, port.ListQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, live.Items)
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		_, err := repos.Trash.ListTrash(ctx, "lions", port.TrashFilter{}, port.ListQuery{})
+		assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+
+		_, err = repos.Trash.ListTrash(ctx, port.TrashBookings, port.TrashFilter{}, port.ListQuery{Sort: port.ParseSort("price")})
+		assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		require.NoError(t, repos.Trash.Restore(ctx, port.TrashBookings, booking.Id))
+
+		restored, err := repos.Bookings.GetBookingById(ctx, booking.Id)
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+		assert.Equal(t, int64(3), restored.Version)
+
+		assert.ErrorIs(t, repos.Trash.Restore(ctx, port.TrashBookings, booking.Id), domain.ErrNotFound, "live entities are not in the trash")
+		_, err = repos.Trash.GetTrashItem(ctx, port.TrashBookings, booking.Id)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("species filter skips deleted animals", func(t *testing.T) {
+		require.NoError(t, repos.Animals.DeleteAnimal(ctx, animal.Id))
+
+		page, err := repos.ShowRounds.GetAllShowRounds(ctx, port.ShowRoundFilter{Species: "lion"}, port.ListQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), page.Total)
+
+		require.NoError(t, repos.Trash.Restore(ctx, port.TrashAnimals, animal.Id))
+	})
+
+	t.Run("deleted username stays reserved until purged", func(t *testing.T) {
+		other, err := repos.Users.CreateUser(ctx, &domain.Users{Username: "dave", Password: "hash"})
+		require.NoError(t, err)
+		require.NoError(t, repos.Users.DeleteUser(ctx, other.Id))
+
+		_, err = repos.Users.GetUserByUsername(ctx, "dave")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = repos.Users.CreateUser(ctx, &domain.Users{Username: "dave", Password: "hash"})
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+
+		require.NoError(t, repos.Trash.Purge(ctx, port.TrashUsers, other.Id))
+		_, err = repos.Users.CreateUser(ctx, &domain.Users{Username: "dave", Password: "hash"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("purge removes trashed dependents", func(t *testing.T) {
+		require.NoError(t, repos.Bookings.DeleteBooking(ctx, booking.Id))
+		require.NoError(t, repos.ShowRounds.DeleteShowRound(ctx, showRound.Id))
+		require.NoError(t, repos.Animals.DeleteAnimal(ctx, animal.Id))
+
+		require.NoError(t, repos.Trash.Purge(ctx, port.TrashAnimals, animal.Id))
+
+		for _, kind := range []port.TrashKind{port.TrashAnimals, port.TrashShowRounds, port.TrashBookings} {
+			page, err := repos.Trash.ListTrash(ctx, kind, port.TrashFilter{}, port.ListQuery{})
+			require.NoError(t, err)
+			assert.Empty(t, page.Items, kind)
+		}
+		assert.ErrorIs(t, repos.Trash.Purge(ctx, port.TrashAnimals, animal.Id), domain.ErrNotFound)
+		assert.ErrorIs(t, repos.Trash.Restore(ctx, port.TrashAnimals, animal.Id), domain.ErrNotFound)
+
+		_, err := repos.Stages.GetStageById(ctx, stage.Id)
+		assert.NoError(t, err, "live entities are never purged")
+		_, err = repos.Users.GetUserById(ctx, user.Id)
+		assert.NoError(t, err)
+	})
+}
+
+func testAudit(t *testing.T, repos Repositories) {
+	alice := port.WithActor(context.Background(), "alice")
+	bob := port.WithActor(context.Background(), "bob")
+	start := time.Now().Add(-time.Second)
+
+	forged := domain.Audit{CreatedAt: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), CreatedBy: "mallory", UpdatedBy: "mallory"}
+	animal, err := repos.Animals.CreateAnimal(alice, &domain.Animals{Name: "Leo", Species: "lion", Audit: forged})
+	require.NoError(t, err)
+
+	t.Run("create stamps the actor", func(t *testing.T) {
+		stored, err := repos.Animals.GetAnimalById(alice, animal.Id)
+
+		require.NoError(t, err)
+		assert.Equal(t, "alice", stored.CreatedBy)
+		assert.Equal(t, "alice", stored.UpdatedBy)
+		assert.True(t, stored.CreatedAt.After(start), "created at %v", stored.CreatedAt)
+		assert.True(t, stored.CreatedAt.Equal(stored.UpdatedAt))
+		assert.True(t, stored.CreatedAt.Equal(animal.CreatedAt))
+	})
+
+	t.Run("update keeps the creation stamp", func(t *testing.T) {
+		time.Sleep(5 * time.Millisecond)
+		updated, err := repos.Animals.UpdateAnimal(bob, animal.Id, &domain.Animals{Name: "Leo II", Audit: forged})
+
+		require.NoError(t, err)
+		assert.Equal(t, "alice", updated.CreatedBy)
+		assert.Equal(t, "bob", updated.UpdatedBy)
+		assert.True(t, updated.CreatedAt.Equal(animal.CreatedAt))
+		assert.True(t, updated.UpdatedAt.After(updated.CreatedAt))
+	})
+
+	t.Run("anonymous changes", func(t *testing.T) {
+		stage, err := repos.Stages.CreateStage(context.Background(), &domain.PerformanceStage{RoomNumber: "A1", SeatCapacity: 5})
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.AnonymousActor, stage.CreatedBy)
+	})
+
+	t.Run("filter by creation time and actor", func(t *testing.T) {
+		_, err := repos.Animals.CreateAnimal(bob, &domain.Animals{Name: "Tony", Species: "tiger"})
+		require.NoError(t, err)
+		from := animal.CreatedAt
+		before := animal.CreatedAt.Add(-time.Millisecond)
+
+		page, err := repos.Animals.GetAnimals(alice, port.AnimalFilter{Audit: port.AuditFilter{CreatedFrom: &from}}, port.ListQuery{Sort: port.ParseSort("created_at")})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Leo II", "Tony"}, animalNames(page.Items))
+
+		page, err = repos.Animals.GetAnimals(alice, port.AnimalFilter{Audit: port.AuditFilter{CreatedTo: &before}}, port.ListQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, page.Items)
+
+		page, err = repos.Animals.GetAnimals(alice, port.AnimalFilter{Audit: port.AuditFilter{CreatedBy: "bob"}}, port.ListQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Tony"}, animalNames(page.Items))
+
+		page, err = repos.Animals.GetAnimals(alice, port.AnimalFilter{Audit: port.AuditFilter{UpdatedBy: "bob"}}, port.ListQuery{Sort: port.ParseSort("name")})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Leo II", "Tony"}, animalNames(page.Items))
+	})
+
+	t.Run("bookings created today", func(t *testing.T) {
+		user, err := repos.Users.CreateUser(alice, &domain.Users{Username: "carol", Password: "hash"})
+		require.NoError(t, err)
+		stage, err := repos.Stages.CreateStage(alice, &domain.PerformanceStage{RoomNumber: "B1", SeatCapacity: 5})
+		require.NoError(t, err)
+		showRound, err := repos.ShowRounds.CreateShowRound(alice, &domain.ShowRounds{AnimalId: animal.Id, StageId: stage.Id, ShowTime: "2025-01-01T10:00:00Z"})
+		require.NoError(t, err)
+		booking, err := repos.Bookings.CreateBooking(bob, &domain.Bookings{UserId: user.Id, RoundId: showRound.Id, SeatNumber: 1})
+		require.NoError(t, err)
+
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		page, err := repos.Bookings.ListBookings(alice, port.BookingFilter{Audit: port.AuditFilter{CreatedFrom: &today}}, port.ListQuery{})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, booking.Id, page.Items[0].Id)
+		assert.Equal(t, "bob", page.Items[0].CreatedBy)
+
+		yesterday := today.Add(-time.Nanosecond)
+		page, err = repos.Bookings.ListBookings(alice, port.BookingFilter{Audit: port.AuditFilter{CreatedTo: &yesterday}}, port.ListQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, page.Items)
+
+		rounds, err := repos.ShowRounds.GetAllShowRounds(alice, port.ShowRoundFilter{Audit: port.AuditFilter{CreatedBy: "alice"}}, port.ListQuery{})
+		require.NoError(t, err)
+		assert.Len(t, rounds.Items, 1)
+		stages, err := repos.Stages.GetStages(alice, port.StageFilter{Audit: port.AuditFilter{CreatedBy: "alice"}}, port.ListQuery{})
+		require.NoError(t, err)
+		assert.Len(t, stages.Items, 1)
+	})
+
+	t.Run("delete and restore stamp the actor", func(t *testing.T) {
+		require.NoError(t, repos.Animals.DeleteAnimal(bob, animal.Id))
+		item, err := repos.Trash.GetTrashItem(alice, port.TrashAnimals, animal.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "bob", item.Entity.(*domain.Animals).UpdatedBy)
+
+		require.NoError(t, repos.Trash.Restore(alice, port.TrashAnimals, animal.Id))
+		restored, err := repos.Animals.GetAnimalById(alice, animal.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", restored.UpdatedBy)
+		assert.Equal(t, "alice", restored.CreatedBy)
+	})
+}
+
+func testAuditLog(t *testing.T, repos Repositories) {
+	ctx := port.WithRequestMeta(port.WithActor(context.Background(), "alice"), port.RequestMeta{IP: "10.0.0.1", RequestId: "req-1"})
+	errAbort := errors.New("abort")
+
+	login := port.NewAuditLogEntry(ctx, domain.AuditActionLogin, domain.AuditEntityUser, "u1", nil, map[string]any{"username": "alice"})
+	require.NoError(t, repos.AuditLog.Append(ctx, login))
+	require.NotEmpty(t, login.Id)
+	time.Sleep(5 * time.Millisecond)
+	priceChange := port.NewAuditLogEntry(port.WithActor(ctx, "bob"), domain.AuditActionStagePriceChanged, domain.AuditEntityStage, "s1",
+		map[string]any{"price_per_seat": 10.0}, map[string]any{"price_per_seat": 12.5})
+	require.NoError(t, repos.AuditLog.Append(ctx, priceChange))
+
+	t.Run("append keeps the entry", func(t *testing.T) {
+		page, err := repos.AuditLog.ListAuditLog(ctx, port.AuditLogFilter{EntityId: "s1"}, port.ListQuery{})
+
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		stored := page.Items[0]
+		assert.Equal(t, priceChange.Id, stored.Id)
+		assert.Equal(t, "bob", stored.Actor)
+		assert.Equal(t, domain.AuditEntityStage, stored.EntityType)
+		assert.Equal(t, map[string]any{"price_per_seat": 10.0}, stored.Before)
+		assert.Equal(t, map[string]any{"price_per_seat": 12.5}, stored.After)
+		assert.Equal(t, "10.0.0.1", stored.IP)
+		assert.Equal(t, "req-1", stored.RequestId)
+		assert.True(t, stored.OccurredAt.Equal(priceChange.OccurredAt), "occurred at %v", stored.OccurredAt)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		page, err := repos.AuditLog.ListAuditLog(ctx, port.AuditLogFilter{Actor: "alice"}, port.ListQuery{})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, login.Id, page.Items[0].Id)
+
+		page, err = repos.AuditLog.ListAuditLog(ctx, port.AuditLogFilter{Action: domain.AuditActionStagePriceChanged, EntityType: domain.AuditEntityStage}, port.ListQuery{})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, priceChange.Id, page.Items[0].Id)
+
+		page, err = repos.AuditLog.ListAuditLog(ctx, port.AuditLogFilter{Action: domain.AuditActionLoginFailed}, port.ListQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, page.Items)
+	})
+
+	t.Run("time range is inclusive", func(t *testing.T) {
+		from, to := priceChange.OccurredAt, login.OccurredAt
+
+		page, err := repos.AuditLog.ListAuditLog(ctx, port.AuditLogFilter{From: &from}, port.ListQuery{})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, priceChange.Id, page.Items[0].Id)
+
+		page, err = repos.AuditLog.ListAuditLog(ctx, port.AuditLogFilter{To: &to}, port.ListQuery{})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, login.Id, page.Items[0].Id)
+	})
+
+	t.Run("sort and page", func(t *testing.T) {
+		query := port.ListQuery{Sort: port.ParseSort("-occurred_at"), Limit: 1}
+		page, err := repos.AuditLog.ListAuditLog(ctx, port.AuditLogFilter{}, query)
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, priceChange.Id, page.Items[0].Id)
+		assert.Equal(t, int64(2), page.Total)
+		require.NotEmpty(t, page.NextCursor)
+
+		query.Cursor = page.NextCursor
+		page, err = repos.AuditLog.ListAuditLog(ctx, port.AuditLogFilter{}, query)
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, login.Id, page.Items[0].Id)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("unknown sort field", func(t *testing.T) {
+		_, err := repos.AuditLog.ListAuditLog(ctx, port.AuditLogFilter{}, port.ListQuery{Sort: port.ParseSort("ip")})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+	})
+
+	t.Run("rolls back with the unit of work", func(t *testing.T) {
+		err := repos.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+			entry := port.NewAuditLogEntry(ctx, domain.AuditActionRoleChanged, domain.AuditEntityUser, "u2", nil, nil)
+			if err := repos.AuditLog.Append(ctx, entry); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		require.ErrorIs(t, err, errAbort)
+
+		page, err := repos.AuditLog.ListAuditLog(ctx, port.AuditLogFilter{EntityId: "u2"}, port.ListQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, page.Items)
+	})
+}
+
+func testInvitations(t *testing.T, repos Repositories) {
+	ctx := port.WithActor(context.Background(), "admin-1")
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond)
+
+	invitation, err := repos.Invitations.CreateInvitation(ctx, &domain.Invitation{Role: domain.RoleAdmin, ExpiresAt: expiresAt})
+	require.NoError(t, err)
+	require.NotEmpty(t, invitation.Id)
+
+	t.Run("get", func(t *testing.T) {
+		stored, err := repos.Invitations.GetInvitationById(ctx, invitation.Id)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.RoleAdmin, stored.Role)
+		assert.True(t, stored.ExpiresAt.Equal(expiresAt), "expires at %v", stored.ExpiresAt)
+		assert.Nil(t, stored.AcceptedAt)
+		assert.Equal(t, "admin-1", stored.CreatedBy)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := repos.Invitations.GetInvitationById(ctx, "missing")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		err = repos.Invitations.AcceptInvitation(ctx, "missing", "u1", time.Now())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("accept only once", func(t *testing.T) {
+		acceptedAt := time.Now().UTC().Truncate(time.Millisecond)
+		require.NoError(t, repos.Invitations.AcceptInvitation(ctx, invitation.Id, "u1", acceptedAt))
+
+		err := repos.Invitations.AcceptInvitation(ctx, invitation.Id, "u2", acceptedAt)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		stored, err := repos.Invitations.GetInvitationById(ctx, invitation.Id)
+		require.NoError(t, err)
+		require.NotNil(t, stored.AcceptedAt)
+		assert.True(t, stored.AcceptedAt.Equal(acceptedAt), "accepted at %v", stored.AcceptedAt)
+		assert.Equal(t, "u1", stored.AcceptedBy)
+		assert.ErrorIs(t, stored.Usable(time.Now()), domain.ErrInvitationUsed)
+	})
+}
+
+func testLoginAttempts(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	alice := domain.LoginKey(domain.LoginKeyUsername, "alice")
+	ip := domain.LoginKey(domain.LoginKeyIP, "203.0.113.7")
+
+	t.Run("failures count up within the window", func(t *testing.T) {
+		for i := 1; i <= 3; i++ {
+			attempt, err := repos.LoginAttempts.RecordLoginFailure(ctx, alice, now.Add(time.Duration(i)*time.Second), now.Add(-time.Minute))
+			require.NoError(t, err)
+			assert.Equal(t, alice, attempt.Key)
+			assert.Equal(t, i, attempt.Failures)
+			assert.True(t, attempt.LastFailureAt.Equal(now.Add(time.Duration(i)*time.Second)), "last failure at %v", attempt.LastFailureAt)
+		}
+	})
+
+	t.Run("failures before the window are forgotten", func(t *testing.T) {
+		_, err := repos.LoginAttempts.RecordLoginFailure(ctx, ip, now.Add(-time.Hour), now.Add(-2*time.Hour))
+		require.NoError(t, err)
+
+		attempt, err := repos.LoginAttempts.RecordLoginFailure(ctx, ip, now, now.Add(-time.Minute))
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempt.Failures)
+	})
+
+	t.Run("get only returns existing counters", func(t *testing.T) {
+		attempts, err := repos.LoginAttempts.GetLoginAttempts(ctx, []string{alice, domain.LoginKey(domain.LoginKeyUsername, "nobody")})
+
+		require.NoError(t, err)
+		require.Len(t, attempts, 1)
+		assert.Equal(t, 3, attempts[0].Failures)
+		assert.Nil(t, attempts[0].LockedUntil)
+	})
+
+	t.Run("lock and list lockouts", func(t *testing.T) {
+		until := now.Add(15 * time.Minute)
+		require.NoError(t, repos.LoginAttempts.LockLogin(ctx, alice, until))
+		require.NoError(t, repos.LoginAttempts.LockLogin(ctx, ip, now.Add(-time.Second)))
+		assert.ErrorIs(t, repos.LoginAttempts.LockLogin(ctx, "username:nobody", until), domain.ErrNotFound)
+
+		locked, err := repos.LoginAttempts.ListLoginLockouts(ctx, now)
+
+		require.NoError(t, err)
+		require.Len(t, locked, 1)
+		assert.Equal(t, alice, locked[0].Key)
+		require.NotNil(t, locked[0].LockedUntil)
+		assert.True(t, locked[0].LockedUntil.Equal(until), "locked until %v", locked[0].LockedUntil)
+	})
+
+	t.Run("clear lifts the lockout", func(t *testing.T) {
+		require.NoError(t, repos.LoginAttempts.ClearLoginAttempts(ctx, alice))
+		assert.ErrorIs(t, repos.LoginAttempts.ClearLoginAttempts(ctx, alice), domain.ErrNotFound)
+
+		attempts, err := repos.LoginAttempts.GetLoginAttempts(ctx, []string{alice})
+		require.NoError(t, err)
+		assert.Empty(t, attempts)
+
+		attempt, err := repos.LoginAttempts.RecordLoginFailure(ctx, alice, now, now.Add(-time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempt.Failures)
+		assert.Nil(t, attempt.LockedUntil)
+	})
+}
+
+func testMfa(t *testing.T, repos Repositories) {
+	ctx := port.WithActor(context.Background(), "alice")
+
+	t.Run("enrollment lifecycle", func(t *testing.T) {
+		_, err := repos.Mfa.GetMfaEnrollment(ctx, "alice")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		pending := &domain.MfaEnrollment{UserId: "alice", Secret: "SECRET1"}
+		require.NoError(t, repos.Mfa.SaveMfaEnrollment(ctx, pending))
+		assert.ErrorIs(t, repos.Mfa.UseMfaStep(ctx, "alice", 10), domain.ErrNotFound, "a pending enrollment accepts no code")
+
+		confirmedAt := time.Now().UTC().Truncate(time.Millisecond)
+		confirmed := &domain.MfaEnrollment{UserId: "alice", Secret: "SECRET1", ConfirmedAt: &confirmedAt, LastStep: 10, RecoveryCodes: []string{"hash-a", "hash-b"}}
+		require.NoError(t, repos.Mfa.SaveMfaEnrollment(ctx, confirmed))
+
+		stored, err := repos.Mfa.GetMfaEnrollment(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, "SECRET1", stored.Secret)
+		assert.True(t, stored.Confirmed())
+		assert.Equal(t, int64(10), stored.LastStep)
+		assert.ElementsMatch(t, []string{"hash-a", "hash-b"}, stored.RecoveryCodes)
+		assert.Equal(t, "alice", stored.CreatedBy)
+	})
+
+	t.Run("a time step is accepted once", func(t *testing.T) {
+		assert.ErrorIs(t, repos.Mfa.UseMfaStep(ctx, "alice", 10), domain.ErrNotFound)
+		require.NoError(t, repos.Mfa.UseMfaStep(ctx, "alice", 11))
+		assert.ErrorIs(t, repos.Mfa.UseMfaStep(ctx, "alice", 11), domain.ErrNotFound)
+		assert.ErrorIs(t, repos.Mfa.UseMfaStep(ctx, "nobody", 12), domain.ErrNotFound)
+	})
+
+	t.Run("a recovery code is used once", func(t *testing.T) {
+		require.NoError(t, repos.Mfa.UseRecoveryCode(ctx, "alice", "hash-a"))
+		assert.ErrorIs(t, repos.Mfa.UseRecoveryCode(ctx, "alice", "hash-a"), domain.ErrNotFound)
+		assert.ErrorIs(t, repos.Mfa.UseRecoveryCode(ctx, "bob", "hash-b"), domain.ErrNotFound)
+
+		stored, err := repos.Mfa.GetMfaEnrollment(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"hash-b"}, stored.RecoveryCodes)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, repos.Mfa.DeleteMfaEnrollment(ctx, "alice"))
+		assert.ErrorIs(t, repos.Mfa.DeleteMfaEnrollment(ctx, "alice"), domain.ErrNotFound)
+		assert.ErrorIs(t, repos.Mfa.UseRecoveryCode(ctx, "alice", "hash-b"), domain.ErrNotFound)
+	})
+
+	t.Run("requirements", func(t *testing.T) {
+		_, err := repos.Mfa.GetMfaRequirement(ctx, domain.RoleAdmin)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		require.NoError(t, repos.Mfa.SaveMfaRequirement(ctx, &domain.MfaRequirement{Role: domain.RoleUser}))
+		first := &domain.MfaRequirement{Role: domain.RoleAdmin}
+		require.NoError(t, repos.Mfa.SaveMfaRequirement(ctx, first))
+		again := &domain.MfaRequirement{Role: domain.RoleAdmin}
+		require.NoError(t, repos.Mfa.SaveMfaRequirement(port.WithActor(ctx, "bob"), again))
+		assert.Equal(t, "alice", again.CreatedBy, "saving again keeps the original requirement")
+
+		requirements, err := repos.Mfa.ListMfaRequirements(ctx)
+		require.NoError(t, err)
+		require.Len(t, requirements, 2)
+		assert.Equal(t, domain.RoleAdmin, requirements[0].Role)
+		assert.Equal(t, domain.RoleUser, requirements[1].Role)
+
+		require.NoError(t, repos.Mfa.DeleteMfaRequirement(ctx, domain.RoleUser))
+		assert.ErrorIs(t, repos.Mfa.DeleteMfaRequirement(ctx, domain.RoleUser), domain.ErrNotFound)
+		_, err = repos.Mfa.GetMfaRequirement(ctx, domain.RoleAdmin)
+		assert.NoError(t, err)
+	})
+}
+
+func testPasswordResets(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	expiresAt := time.Now().UTC().Add(time.Hour).Truncate(time.Millisecond)
+
+	require.NoError(t, repos.PasswordResets.CreatePasswordResetToken(ctx, &domain.PasswordResetToken{TokenHash: "hash-1", UserId: "alice", ExpiresAt: expiresAt}))
+	require.NoError(t, repos.PasswordResets.CreatePasswordResetToken(ctx, &domain.PasswordResetToken{TokenHash: "hash-2", UserId: "alice", ExpiresAt: expiresAt}))
+	require.NoError(t, repos.PasswordResets.CreatePasswordResetToken(ctx, &domain.PasswordResetToken{TokenHash: "hash-3", UserId: "bob", ExpiresAt: expiresAt}))
+
+	t.Run("a token is taken once", func(t *testing.T) {
+		token, err := repos.PasswordResets.TakePasswordResetToken(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", token.UserId)
+		assert.True(t, expiresAt.Equal(token.ExpiresAt))
+
+		_, err = repos.PasswordResets.TakePasswordResetToken(ctx, "hash-1")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("deleting the tokens of a user leaves the others", func(t *testing.T) {
+		require.NoError(t, repos.PasswordResets.DeletePasswordResetTokens(ctx, "alice"))
+		require.NoError(t, repos.PasswordResets.DeletePasswordResetTokens(ctx, "nobody"))
+
+		_, err := repos.PasswordResets.TakePasswordResetToken(ctx, "hash-2")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = repos.PasswordResets.TakePasswordResetToken(ctx, "hash-3")
+		assert.NoError(t, err)
+	})
+
+	t.Run("a taken token comes back when the unit of work fails", func(t *testing.T) {
+		require.NoError(t, repos.PasswordResets.CreatePasswordResetToken(ctx, &domain.PasswordResetToken{TokenHash: "hash-4", UserId: "carol", ExpiresAt: expiresAt}))
+		failure := errors.New("failure")
+
+		err := repos.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+			if _, err := repos.PasswordResets.TakePasswordResetToken(ctx, "hash-4"); err != nil {
+				return err
+			}
+			return failure
+		})
+		require.ErrorIs(t, err, failure)
+
+		_, err = repos.PasswordResets.TakePasswordResetToken(ctx, "hash-4")
+		assert.NoError(t, err)
+	})
+}
+
+func testSessions(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	_, err := repos.Sessions.GetSessionRevocation(ctx, "alice")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	first := time.Now().UTC().Truncate(time.Millisecond)
+	require.NoError(t, repos.Sessions.RevokeSessions(ctx, "alice", first))
+	later := first.Add(time.Minute)
+	require.NoError(t, repos.Sessions.RevokeSessions(ctx, "alice", later))
+
+	revocation, err := repos.Sessions.GetSessionRevocation(ctx, "alice")
+	require.NoError(t, err)
+	assert.True(t, later.Equal(revocation.RevokedAt), "revoking again moves the revocation forward")
+
+	_, err = repos.Sessions.GetSessionRevocation(ctx, "bob")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	t.Run("token denylist", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		expired := &domain.RevokedToken{TokenId: "jti-expired", UserId: "alice", ExpiresAt: now.Add(-time.Second), RevokedAt: now.Add(-time.Hour)}
+		require.NoError(t, repos.Sessions.RevokeToken(ctx, expired))
+		revoked, err := repos.Sessions.IsTokenRevoked(ctx, "jti-expired")
+		require.NoError(t, err)
+		assert.True(t, revoked)
+
+		token := &domain.RevokedToken{TokenId: "jti-1", UserId: "alice", ExpiresAt: now.Add(time.Hour), RevokedAt: now}
+		require.NoError(t, repos.Sessions.RevokeToken(ctx, token))
+		assert.NoError(t, repos.Sessions.RevokeToken(ctx, token), "revoking again is not an error")
+
+		revoked, err = repos.Sessions.IsTokenRevoked(ctx, "jti-1")
+		require.NoError(t, err)
+		assert.True(t, revoked)
+		revoked, err = repos.Sessions.IsTokenRevoked(ctx, "jti-2")
+		require.NoError(t, err)
+		assert.False(t, revoked)
+		revoked, err = repos.Sessions.IsTokenRevoked(ctx, "jti-expired")
+		require.NoError(t, err)
+		assert.False(t, revoked, "entries of expired tokens are dropped")
+	})
+}
+
+func testProfiles(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	alice, err := repos.Users.CreateUser(ctx, &domain.Users{Username: "alice", Password: "hash", Email: "alice@example.com", Phone: "+66811111111"})
+	require.NoError(t, err)
+	bob, err := repos.Users.CreateUser(ctx, &domain.Users{Username: "bob", Password: "hash"})
+	require.NoError(t, err)
+
+	t.Run("users without email or phone do not collide", func(t *testing.T) {
+		_, err := repos.Users.CreateUser(ctx, &domain.Users{Username: "carol", Password: "hash"})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("duplicate email", func(t *testing.T) {
+		_, err := repos.Users.CreateUser(ctx, &domain.Users{Username: "dave", Password: "hash", Email: "alice@example.com"})
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+
+		_, err = repos.Users.UpdateUser(ctx, bob.Id, &domain.Users{Email: "alice@example.com"})
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	})
+
+	t.Run("duplicate phone", func(t *testing.T) {
+		_, err := repos.Users.CreateUser(ctx, &domain.Users{Username: "erin", Password: "hash", Phone: "+66811111111"})
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+
+		_, err = repos.Users.UpdateUser(ctx, bob.Id, &domain.Users{Phone: "+66811111111"})
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	})
+
+	t.Run("update own email and phone", func(t *testing.T) {
+		user, err := repos.Users.UpdateUser(ctx, alice.Id, &domain.Users{Email: "alice@example.com", Phone: "+66811111111"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", user.Email)
+	})
+
+	t.Run("profile fields", func(t *testing.T) {
+		consent := true
+		user, err := repos.Users.UpdateUser(ctx, bob.Id, &domain.Users{
+			DisplayName:      "Bob B.",
+			Language:         "th",
+			MarketingConsent: &consent,
+			Phone:            "+66822222222",
+			VerifiedPhone:    "+66822222222",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Bob B.", user.DisplayName)
+		assert.Equal(t, "th", user.Language)
+		assert.True(t, user.ConsentsToMarketing())
+		assert.True(t, user.ContactVerified(domain.ContactPhone))
+
+		withdrawn := false
+		user, err = repos.Users.UpdateUser(ctx, bob.Id, &domain.Users{MarketingConsent: &withdrawn})
+		require.NoError(t, err)
+		assert.False(t, user.ConsentsToMarketing())
+		assert.Equal(t, "Bob B.", user.DisplayName)
+
+		user, err = repos.Users.GetUserById(ctx, bob.Id)
+		require.NoError(t, err)
+		assert.False(t, user.ConsentsToMarketing())
+		assert.Equal(t, "+66822222222", user.VerifiedPhone)
+	})
+}
+
+func testVerifications(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	expiresAt := time.Now().UTC().Add(time.Hour).Truncate(time.Millisecond)
+	key := domain.VerificationKey("alice", domain.ContactEmail)
+
+	_, err := repos.Verifications.GetVerification(ctx, key)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	require.NoError(t, repos.Verifications.SaveVerification(ctx, &domain.ContactVerification{
+		Key: key, UserId: "alice", Channel: domain.ContactEmail, Target: "alice@example.com", CodeHash: "hash-1", ExpiresAt: expiresAt,
+	}))
+
+	t.Run("save replaces", func(t *testing.T) {
+		require.NoError(t, repos.Verifications.SaveVerification(ctx, &domain.ContactVerification{
+			Key: key, UserId: "alice", Channel: domain.ContactEmail, Target: "alice@example.org", CodeHash: "hash-2", Attempts: 2, ExpiresAt: expiresAt,
+		}))
+
+		verification, err := repos.Verifications.GetVerification(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", verification.UserId)
+		assert.Equal(t, "alice@example.org", verification.Target)
+		assert.Equal(t, "hash-2", verification.CodeHash)
+		assert.Equal(t, 2, verification.Attempts)
+		assert.True(t, expiresAt.Equal(verification.ExpiresAt))
+	})
+
+	t.Run("delete once", func(t *testing.T) {
+		require.NoError(t, repos.Verifications.DeleteVerification(ctx, key))
+
+		assert.ErrorIs(t, repos.Verifications.DeleteVerification(ctx, key), domain.ErrNotFound)
+		_, err := repos.Verifications.GetVerification(ctx, key)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func testSigningKeys(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	for _, key := range []domain.SigningKey{
+		{Kid: "next", Algorithm: domain.SigningAlgorithmEdDSA, PrivateKey: "pem-next", ActivatesAt: now.Add(time.Hour), RetiresAt: now.Add(2 * time.Hour), ExpiresAt: now.Add(3 * time.Hour)},
+		{Kid: "current", Algorithm: domain.SigningAlgorithmRS256, PrivateKey: "pem-current", ActivatesAt: now.Add(-time.Hour), RetiresAt: now.Add(time.Hour), ExpiresAt: now.Add(2 * time.Hour)},
+		{Kid: "expired", Algorithm: domain.SigningAlgorithmRS256, PrivateKey: "pem-expired", ActivatesAt: now.Add(-3 * time.Hour), RetiresAt: now.Add(-2 * time.Hour), ExpiresAt: now},
+	} {
+		require.NoError(t, repos.SigningKeys.CreateSigningKey(ctx, &key))
+	}
+
+	t.Run("create rejects a duplicate kid", func(t *testing.T) {
+		err := repos.SigningKeys.CreateSigningKey(ctx, &domain.SigningKey{Kid: "current", Algorithm: domain.SigningAlgorithmRS256, PrivateKey: "pem"})
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	})
+
+	t.Run("list orders by activation", func(t *testing.T) {
+		keys, err := repos.SigningKeys.ListSigningKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 3)
+		assert.Equal(t, []string{"expired", "current", "next"}, []string{keys[0].Kid, keys[1].Kid, keys[2].Kid})
+		assert.Equal(t, "pem-current", keys[1].PrivateKey)
+		assert.Equal(t, domain.SigningAlgorithmRS256, keys[1].Algorithm)
+		assert.True(t, now.Add(time.Hour).Equal(keys[1].RetiresAt))
+	})
+
+	t.Run("update private key", func(t *testing.T) {
+		require.NoError(t, repos.SigningKeys.UpdateSigningKeyPrivateKey(ctx, "next", "sealed-next"))
+
+		keys, err := repos.SigningKeys.ListSigningKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 3)
+		assert.Equal(t, "sealed-next", keys[2].PrivateKey)
+		assert.Equal(t, "pem-current", keys[1].PrivateKey, "other keys are left alone")
+
+		assert.ErrorIs(t, repos.SigningKeys.UpdateSigningKeyPrivateKey(ctx, "missing", "sealed"), domain.ErrNotFound)
+	})
+
+	t.Run("delete expired", func(t *testing.T) {
+		deleted, err := repos.SigningKeys.DeleteExpiredSigningKeys(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		keys, err := repos.SigningKeys.ListSigningKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, "current", keys[0].Kid)
+	})
+}
+
+func testApiKeys(t *testing.T, repos Repositories) {
+	ctx := port.WithActor(context.Background(), "admin-1")
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	kiosk, err := repos.ApiKeys.CreateApiClient(ctx, &domain.ApiClient{Name: "kiosk-gate-1", Description: "North gate"})
+	require.NoError(t, err)
+	require.NotEmpty(t, kiosk.Id)
+	partner, err := repos.ApiKeys.CreateApiClient(ctx, &domain.ApiClient{Name: "bangkok-travel"})
+	require.NoError(t, err)
+
+	older, err := repos.ApiKeys.CreateApiKey(ctx, &domain.ApiKey{
+		ClientId: kiosk.Id, Prefix: "lg_aaaaaaaa", KeyHash: "hash-older",
+		Scopes: []string{domain.ScopeBookingsCreate, domain.ScopeRoundsRead}, ExpiresAt: now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, older.Id)
+	newer, err := repos.ApiKeys.CreateApiKey(ctx, &domain.ApiKey{
+		ClientId: kiosk.Id, Prefix: "lg_bbbbbbbb", KeyHash: "hash-newer",
+		Scopes: []string{domain.ScopeRoundsRead}, ExpiresAt: now.Add(2 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	t.Run("client names are unique", func(t *testing.T) {
+		_, err := repos.ApiKeys.CreateApiClient(ctx, &domain.ApiClient{Name: "kiosk-gate-1"})
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	})
+
+	t.Run("list clients by name", func(t *testing.T) {
+		clients, err := repos.ApiKeys.ListApiClients(ctx)
+		require.NoError(t, err)
+		require.Len(t, clients, 2)
+		assert.Equal(t, partner.Id, clients[0].Id)
+		assert.Equal(t, "North gate", clients[1].Description)
+		assert.Equal(t, "admin-1", clients[1].CreatedBy)
+	})
+
+	t.Run("get client", func(t *testing.T) {
+		client, err := repos.ApiKeys.GetApiClientById(ctx, kiosk.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "kiosk-gate-1", client.Name)
+
+		_, err = repos.ApiKeys.GetApiClientById(ctx, "missing")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("key hashes are unique", func(t *testing.T) {
+		_, err := repos.ApiKeys.CreateApiKey(ctx, &domain.ApiKey{ClientId: partner.Id, Prefix: "lg_cccccccc", KeyHash: "hash-older", Scopes: []string{}, ExpiresAt: now})
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	})
+
+	t.Run("get key by hash", func(t *testing.T) {
+		key, err := repos.ApiKeys.GetApiKeyByHash(ctx, "hash-older")
+		require.NoError(t, err)
+		assert.Equal(t, older.Id, key.Id)
+		assert.Equal(t, kiosk.Id, key.ClientId)
+		assert.Equal(t, []string{domain.ScopeBookingsCreate, domain.ScopeRoundsRead}, key.Scopes)
+		assert.True(t, now.Add(time.Hour).Equal(key.ExpiresAt))
+		assert.Nil(t, key.LastUsedAt)
+		assert.Nil(t, key.RevokedAt)
+
+		_, err = repos.ApiKeys.GetApiKeyByHash(ctx, "hash-unknown")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("list keys of a client", func(t *testing.T) {
+		keys, err := repos.ApiKeys.ListApiKeys(ctx, kiosk.Id)
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.ElementsMatch(t, []string{older.Id, newer.Id}, []string{keys[0].Id, keys[1].Id})
+
+		keys, err = repos.ApiKeys.ListApiKeys(ctx, partner.Id)
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	t.Run("touch records the last use", func(t *testing.T) {
+		require.NoError(t, repos.ApiKeys.TouchApiKey(ctx, newer.Id, now))
+
+		key, err := repos.ApiKeys.GetApiKeyById(ctx, newer.Id)
+		require.NoError(t, err)
+		require.NotNil(t, key.LastUsedAt)
+		assert.True(t, now.Equal(*key.LastUsedAt))
+		assert.ErrorIs(t, repos.ApiKeys.TouchApiKey(ctx, "missing", now), domain.ErrNotFound)
+	})
+
+	t.Run("expire only brings the expiry forward", func(t *testing.T) {
+		require.NoError(t, repos.ApiKeys.ExpireApiKey(ctx, newer.Id, now.Add(30*time.Minute)))
+		require.NoError(t, repos.ApiKeys.ExpireApiKey(ctx, newer.Id, now.Add(3*time.Hour)))
+
+		key, err := repos.ApiKeys.GetApiKeyById(ctx, newer.Id)
+		require.NoError(t, err)
+		assert.True(t, now.Add(30*time.Minute).Equal(key.ExpiresAt))
+		assert.ErrorIs(t, repos.ApiKeys.ExpireApiKey(ctx, "missing", now), domain.ErrNotFound)
+	})
+
+	t.Run("revoke once", func(t *testing.T) {
+		bob := port.WithActor(ctx, "admin-2")
+		require.NoError(t, repos.ApiKeys.RevokeApiKey(bob, older.Id, now))
+		assert.ErrorIs(t, repos.ApiKeys.RevokeApiKey(bob, older.Id, now), domain.ErrNotFound)
+		assert.ErrorIs(t, repos.ApiKeys.RevokeApiKey(bob, "missing", now), domain.ErrNotFound)
+
+		key, err := repos.ApiKeys.GetApiKeyById(ctx, older.Id)
+		require.NoError(t, err)
+		require.NotNil(t, key.RevokedAt)
+		assert.True(t, now.Equal(*key.RevokedAt))
+		assert.Equal(t, "admin-2", key.UpdatedBy)
+	})
+}
+
+func testOidc(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	expiresAt := time.Now().UTC().Add(10 * time.Minute).Truncate(time.Millisecond)
+
+	t.Run("a pending sign-in is taken once", func(t *testing.T) {
+		require.NoError(t, repos.Oidc.CreateOidcLogin(ctx, &domain.OidcLogin{
+			StateHash: "state-1", Provider: "google", CodeVerifier: "verifier-1", Nonce: "nonce-1", UserId: "alice", ExpiresAt: expiresAt,
+		}))
+
+		login, err := repos.Oidc.TakeOidcLogin(ctx, "state-1")
+		require.NoError(t, err)
+		assert.Equal(t, "google", login.Provider)
+		assert.Equal(t, "verifier-1", login.CodeVerifier)
+		assert.Equal(t, "nonce-1", login.Nonce)
+		assert.Equal(t, "alice", login.UserId)
+		assert.True(t, expiresAt.Equal(login.ExpiresAt))
+
+		_, err = repos.Oidc.TakeOidcLogin(ctx, "state-1")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	google, err := repos.Oidc.CreateOidcIdentity(ctx, &domain.OidcIdentity{Provider: "google", Subject: "108", UserId: "alice", Email: "alice@example.com"})
+	require.NoError(t, err)
+	require.NotEmpty(t, google.Id)
+	line, err := repos.Oidc.CreateOidcIdentity(ctx, &domain.OidcIdentity{Provider: "line", Subject: "108", UserId: "alice"})
+	require.NoError(t, err)
+	_, err = repos.Oidc.CreateOidcIdentity(ctx, &domain.OidcIdentity{Provider: "google", Subject: "209", UserId: "bob"})
+	require.NoError(t, err)
+
+	t.Run("an identity is linked once", func(t *testing.T) {
+		_, err := repos.Oidc.CreateOidcIdentity(ctx, &domain.OidcIdentity{Provider: "google", Subject: "108", UserId: "bob"})
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	})
+
+	t.Run("get an identity by provider and subject", func(t *testing.T) {
+		identity, err := repos.Oidc.GetOidcIdentity(ctx, "line", "108")
+		require.NoError(t, err)
+		assert.Equal(t, line.Id, identity.Id)
+		assert.Equal(t, "alice", identity.UserId)
+
+		_, err = repos.Oidc.GetOidcIdentity(ctx, "line", "209")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("list the identities of a user", func(t *testing.T) {
+		identities, err := repos.Oidc.ListOidcIdentities(ctx, "alice")
+		require.NoError(t, err)
+		require.Len(t, identities, 2)
+		assert.ElementsMatch(t, []string{google.Id, line.Id}, []string{identities[0].Id, identities[1].Id})
+		for _, identity := range identities {
+			if identity.Id == google.Id {
+				assert.Equal(t, "alice@example.com", identity.Email)
+			}
+		}
+
+		identities, err = repos.Oidc.ListOidcIdentities(ctx, "nobody")
+		require.NoError(t, err)
+		assert.Empty(t, identities)
+	})
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+)
+
+// testApp is the whole app wired as in main on the in-memory backend, served without a listener
+type testApp struct {
+	t      *testing.T
+	router *gin.Engine
+}
+
+func newTestApp(t *testing.T) (*testApp, port.UsersService) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("DB_TYPE", "memory")
+	t.Setenv("MEMORY_SNAPSHOT_PATH", "")
+	t.Setenv("JWT_ACCESS_DURATION", "15m")
+	t.Setenv("JWT_REFRESH_DURATION", "24h")
+	t.Setenv("JWT_SIGNING_ALGORITHM", domain.SigningAlgorithmEdDSA)
+	t.Setenv("JWT_KEY_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	t.Setenv("MAIL_DRIVER", "file")
+	t.Setenv("MAIL_OUTBOX_DIR", t.TempDir())
+	t.Setenv("SMS_DRIVER", "file")
+	t.Setenv("SMS_OUTBOX_DIR", t.TempDir())
+	t.Setenv("PASSWORD_ARGON2_MEMORY", "64")
+	t.Setenv("PASSWORD_ARGON2_ITERATIONS", "1")
+
+	var router *gin.Engine
+	var users port.UsersService
+	app := fx.New(
+		fx.NopLogger,
+		fx.Provide(
+			NewRouter,
+			NewConfig,
+			NewRepositoryImpl,
+		),
+		appModules,
+		fx.Invoke(RegisterRoutes),
+		fx.Populate(&router, &users),
+	)
+	require.NoError(t, app.Err())
+	require.NoError(t, app.Start(context.Background()))
+	t.Cleanup(func() { _ = app.Stop(context.Background()) })
+
+	return &testApp{t: t, router: router}, users
+}
+
+// do sends body as JSON with the access token and If-Match header when they are not empty, and decodes the response into out
+func (a *testApp) do(method, path, token, ifMatch string, body any, out any) *httptest.ResponseRecorder {
+	a.t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		require.NoError(a.t, json.NewEncoder(&payload).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	if out != nil && w.Code < http.StatusBadRequest {
+		require.NoError(a.t, json.Unmarshal(w.Body.Bytes(), out), w.Body.String())
+	}
+	return w
+}
+
+// login returns the access token of username
+func (a *testApp) login(username, password string) string {
+	a.t.Helper()
+	var resp struct {
+		Tokens struct {
+			AccessToken string `json:"access_token"`
+		} `json:"tokens"`
+	}
+	w := a.do(http.MethodPost, "/api/v1/auth/login", "", "", map[string]string{"username": username, "password": password}, &resp)
+	require.Equal(a.t, http.StatusOK, w.Code, w.Body.String())
+	return resp.Tokens.AccessToken
+}
+
+// TestBookingFlow drives the HTTP API of the app on the in-memory backend, from the setup of a show by an admin to
+// users competing for a seat
+func TestBookingFlow(t *testing.T) {
+	app, users := newTestApp(t)
+	_, err := users.BootstrapAdmin(context.Background(), &domain.Users{Username: "root", Password: "Lion-Gate-Root-1"})
+	require.NoError(t, err)
+	admin := app.login("root", "Lion-Gate-Root-1")
+
+	userIds := map[string]string{}
+	for _, username := range []string{"alice", "bob"} {
+		var user struct {
+			Id string `json:"user_id"`
+		}
+		w := app.do(http.MethodPost, "/api/v1/users/register", "", "", map[string]string{"username": username, "password": "Lion-Gate-" + username + "-9"}, &user)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		userIds[username] = user.Id
+	}
+	alice := app.login("alice", "Lion-Gate-alice-9")
+	bob := app.login("bob", "Lion-Gate-bob-9")
+
+	var animal struct {
+		Id string `json:"animal_id"`
+	}
+	w := app.do(http.MethodPost, "/api/v1/animals/", alice, "", map[string]any{"name": "Leo", "species": "lion", "type": "mammal", "show_duration": 30}, nil)
+	require.Equal(t, http.StatusForbidden, w.Code, "only admins set up shows")
+	w = app.do(http.MethodPost, "/api/v1/animals/", admin, "", map[string]any{"name": "Leo", "species": "lion", "type": "mammal", "show_duration": 30}, &animal)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var stage struct {
+		Id string `json:"stage_id"`
+	}
+	w = app.do(http.MethodPost, "/api/v1/stages/", admin, "", map[string]any{"room_number": "A1", "seat_capacity": 10, "price_per_seat": 300}, &stage)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var round struct {
+		Id string `json:"round_id"`
+	}
+	w = app.do(http.MethodPost, "/api/v1/show-rounds/", admin, "", map[string]any{"animal_id": animal.Id, "stage_id": stage.Id, "show_time": "2030-01-01T10:00:00Z"}, &round)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	book := func(token, username string) *httptest.ResponseRecorder {
+		return app.do(http.MethodPost, "/api/v1/bookings", token, "", map[string]any{"user_id": userIds[username], "round_id": round.Id, "seat_number": 1}, nil)
+	}
+	w = book(alice, "alice")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var booking struct {
+		Id     string `json:"booking_id"`
+		Status string `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &booking))
+	assert.Equal(t, domain.BookingStatusConfirmed, booking.Status)
+	etag := w.Header().Get("ETag")
+
+	w = book(bob, "bob")
+	assert.Equal(t, http.StatusConflict, w.Code, "the seat is taken")
+
+	w = app.do(http.MethodGet, "/api/v1/bookings/"+booking.Id, bob, "", nil, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code, "bob cannot see the booking of alice")
+
+	w = app.do(http.MethodPut, "/api/v1/bookings/"+booking.Id, alice, "", map[string]string{"status": domain.BookingStatusCancelled}, nil)
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	w = app.do(http.MethodPut, "/api/v1/bookings/"+booking.Id, alice, etag, map[string]string{"status": domain.BookingStatusCancelled}, &booking)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, domain.BookingStatusCancelled, booking.Status)
+	w = app.do(http.MethodPut, "/api/v1/bookings/"+booking.Id, alice, etag, map[string]any{"seat_number": 2}, nil)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code, "%s is stale", etag)
+
+	w = book(bob, "bob")
+	assert.Equal(t, http.StatusCreated, w.Code, "a cancelled booking frees its seat: %s", w.Body.String())
+}
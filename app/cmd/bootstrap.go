@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
+	"github.com/khunmostz/be-liongate-go/app/adapter/modules"
+	"github.com/khunmostz/be-liongate-go/app/adapter/store/repository"
+	GormStore "github.com/khunmostz/be-liongate-go/app/adapter/store/repository/gorm"
+	MemoryStore "github.com/khunmostz/be-liongate-go/app/adapter/store/repository/memory"
+	MongoStore "github.com/khunmostz/be-liongate-go/app/adapter/store/repository/mongo"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/services"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+const bootstrapUsage = "usage: bootstrap-admin <username>, with the password in BOOTSTRAP_ADMIN_PASSWORD or on stdin"
+
+// runBootstrapAdmin implements the `bootstrap-admin` subcommand, which creates the first admin of an installation.
+// It fails once an admin exists; later admins are invited through the API.
+func runBootstrapAdmin(cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New(bootstrapUsage)
+	}
+
+	password, err := bootstrapPassword()
+	if err != nil {
+		return err
+	}
+
+	var mongoDb *mongo.Database
+	var gormDb *gorm.DB
+	var memoryStore *MemoryStore.Store
+	switch {
+	case cfg.IsMongoDB():
+		mongoDb = MongoStore.InitMongoDB(cfg)
+	case cfg.IsPostgres():
+		gormDb = GormStore.InitPostgresDB(cfg)
+	case cfg.IsSQLite():
+		gormDb = GormStore.InitSQLiteDB(cfg)
+	case cfg.IsMemory():
+		if cfg.Memory.SnapshotPath == "" {
+			return errors.New("DB_TYPE=memory needs MEMORY_SNAPSHOT_PATH, otherwise the admin is lost when the command exits")
+		}
+		memoryStore = MemoryStore.InitMemoryStore(cfg)
+	}
+
+	factory := repository.NewRepositoryFactory(cfg, mongoDb, gormDb, memoryStore)
+	usersRepository, err := factory.CreateUserRepository()
+	if err != nil {
+		return err
+	}
+	bookingsRepository, err := factory.CreateBookingRepository()
+	if err != nil {
+		return err
+	}
+	sessions, err := factory.CreateSessionRepository()
+	if err != nil {
+		return err
+	}
+	auditLog, err := factory.CreateAuditLogRepository()
+	if err != nil {
+		return err
+	}
+	unitOfWork, err := factory.CreateUnitOfWork()
+	if err != nil {
+		return err
+	}
+
+	hasher, err := modules.ProvidePasswordHasher(cfg)
+	if err != nil {
+		return err
+	}
+
+	userService := services.NewUsersService(usersRepository, bookingsRepository, sessions, auditLog, unitOfWork, hasher)
+	admin, err := userService.BootstrapAdmin(context.Background(), &domain.Users{Username: args[0], Password: password})
+	if err != nil {
+		return err
+	}
+
+	if memoryStore != nil {
+		if err := memoryStore.Save(cfg.Memory.SnapshotPath); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Created admin %s (%s)\n", admin.Username, admin.Id)
+	return nil
+}
+
+// bootstrapPassword reads the password from BOOTSTRAP_ADMIN_PASSWORD, or else from the first line of stdin
+// so that it does not end up in the shell history
+func bootstrapPassword() (string, error) {
+	if password := os.Getenv("BOOTSTRAP_ADMIN_PASSWORD"); password != "" {
+		return password, nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New(bootstrapUsage)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
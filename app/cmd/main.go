@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"os"
 
 	"github.com/gin-gonic/gin"
@@ -10,6 +12,7 @@ import (
 	"github.com/khunmostz/be-liongate-go/app/adapter/modules"
 	"github.com/khunmostz/be-liongate-go/app/adapter/store/repository"
 	GormStore "github.com/khunmostz/be-liongate-go/app/adapter/store/repository/gorm"
+	MemoryStore "github.com/khunmostz/be-liongate-go/app/adapter/store/repository/memory"
 	MongoStore "github.com/khunmostz/be-liongate-go/app/adapter/store/repository/mongo"
 	_ "github.com/khunmostz/be-liongate-go/app/docs" // Import swagger docs
 	"go.mongodb.org/mongo-driver/mongo"
@@ -33,50 +36,105 @@ import (
 // @BasePath  /api/v1
 
 // @securityDefinitions.basic  BasicAuth
-func NewRouter() *gin.Engine {
-	return gin.Default()
+
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 Access token from /auth/login, sent as "Bearer <token>"
+
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        X-API-Key
+// @description                 API key of a machine client, honoured on the routes that require one of its scopes
+func NewRouter(cfg *config.Config) (*gin.Engine, error) {
+	router := gin.Default()
+	// Handlers pass the gin context on to the services; with the fallback it also carries the values
+	// of the request context, such as the actor the auth middleware records
+	router.ContextWithFallback = true
+	// The client IP counts failed logins, so X-Forwarded-For is only believed from the configured proxies;
+	// otherwise any client could pick a fresh IP per request
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("SERVER_TRUSTED_PROXIES: %w", err)
+	}
+	return router, nil
 }
 
 func NewConfig() *config.Config {
 	return config.NewConfig()
 }
 
-func NewRepositoryImpl(cfg *config.Config) (*repository.RepositoryFactory, error) {
+func NewRepositoryImpl(lc fx.Lifecycle, cfg *config.Config) (*repository.RepositoryFactory, error) {
 	var mongoDb *mongo.Database
-	var postgresDb *gorm.DB
+	var gormDb *gorm.DB
+	var memoryStore *MemoryStore.Store
 
 	if cfg.IsMongoDB() {
 		mongoDb = MongoStore.InitMongoDB(cfg)
 	} else if cfg.IsPostgres() {
-		postgresDb = GormStore.InitPostgresDB(cfg)
+		gormDb = GormStore.InitPostgresDB(cfg)
+	} else if cfg.IsSQLite() {
+		gormDb = GormStore.InitSQLiteDB(cfg)
+	} else if cfg.IsMemory() {
+		memoryStore = MemoryStore.InitMemoryStore(cfg)
+		if path := cfg.Memory.SnapshotPath; path != "" {
+			lc.Append(fx.Hook{
+				OnStop: func(ctx context.Context) error {
+					return memoryStore.Save(path)
+				},
+			})
+		}
 	}
 
-	return repository.NewRepositoryFactory(cfg, mongoDb, postgresDb), nil
+	return repository.NewRepositoryFactory(cfg, mongoDb, gormDb, memoryStore), nil
 }
 
+// RegisterRoutes mounts the middleware and the routes of every controller on the router
 func RegisterRoutes(
-	lc fx.Lifecycle,
 	router *gin.Engine,
+	authMiddleware *controllers.AuthMiddleware,
 	authController *controllers.AuthController,
 	userController *controllers.UsersController,
 	bookingController *controllers.BookingsController,
 	showRoundController *controllers.ShowRoundsController,
 	animalController *controllers.AnimalsController,
 	performanceStageController *controllers.PerformanceStageController,
+	trashController *controllers.TrashController,
+	auditLogController *controllers.AuditLogController,
+	invitationsController *controllers.InvitationsController,
+	loginLockoutsController *controllers.LoginLockoutsController,
+	mfaController *controllers.MfaController,
+	profileController *controllers.ProfileController,
+	jwksController *controllers.JWKSController,
+	apiKeysController *controllers.ApiKeysController,
+	oidcController *controllers.OidcController,
 	swaggerHandler gin.HandlerFunc,
 ) {
+	router.Use(controllers.RequestMeta(), controllers.ErrorHandler(), authMiddleware.Identify())
+
+	// Swagger documentation endpoint
+	router.GET("/swagger/*any", swaggerHandler)
+
+	authController.RegisterRoutes(router)
+	userController.RegisterRoutes(router)
+	bookingController.RegisterRoutes(router)
+	showRoundController.RegisterRoutes(router)
+	animalController.RegisterRoutes(router)
+	performanceStageController.RegisterRoutes(router)
+	trashController.RegisterRoutes(router)
+	auditLogController.RegisterRoutes(router)
+	invitationsController.RegisterRoutes(router)
+	loginLockoutsController.RegisterRoutes(router)
+	mfaController.RegisterRoutes(router)
+	profileController.RegisterRoutes(router)
+	jwksController.RegisterRoutes(router)
+	apiKeysController.RegisterRoutes(router)
+	oidcController.RegisterRoutes(router)
+}
+
+// Serve listens on SERVER_PORT once the app has started
+func Serve(lc fx.Lifecycle, router *gin.Engine) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			// Swagger documentation endpoint
-			router.GET("/swagger/*any", swaggerHandler)
-
-			authController.RegisterRoutes(router)
-			userController.RegisterRoutes(router)
-			bookingController.RegisterRoutes(router)
-			showRoundController.RegisterRoutes(router)
-			animalController.RegisterRoutes(router)
-			performanceStageController.RegisterRoutes(router)
-
 			PORT := os.Getenv("SERVER_PORT")
 			go router.Run(":" + PORT)
 			return nil
@@ -87,21 +145,49 @@ func RegisterRoutes(
 	})
 }
 
+// appModules provides the services and controllers of the app on top of a config, a router and a repository factory
+var appModules = fx.Options(
+	modules.UnitOfWorkModule,
+	modules.AuditLogModule,
+	modules.MailModule,
+	modules.SmsModule,
+	modules.SigningKeyModule,
+	modules.AuthModule,
+	modules.UserModule,
+	modules.BookingModule,
+	modules.SwaggerModule,
+	modules.ShowRoundModule,
+	modules.AnimalModule,
+	modules.PerformanceStageModule,
+	modules.TrashModule,
+	modules.InvitationModule,
+	modules.ProfileModule,
+	modules.ApiKeyModule,
+	modules.OidcModule,
+)
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(config.NewConfig(), os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "bootstrap-admin" {
+		if err := runBootstrapAdmin(config.NewConfig(), os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	app := fx.New(
 		fx.Provide(
 			NewRouter,
 			NewConfig,
 			NewRepositoryImpl,
 		),
-		modules.AuthModule,
-		modules.UserModule,
-		modules.BookingModule,
-		modules.SwaggerModule,
-		modules.ShowRoundModule,
-		modules.AnimalModule,
-		modules.PerformanceStageModule,
-		fx.Invoke(RegisterRoutes),
+		appModules,
+		fx.Invoke(RegisterRoutes, Serve),
 	)
 
 	app.Run()
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
+	"github.com/khunmostz/be-liongate-go/app/adapter/controllers"
+	MemoryStore "github.com/khunmostz/be-liongate-go/app/adapter/store/repository/memory"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/services"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLoginRouter serves the login route of a router built from cfg, counting failures in attempts
+func newLoginRouter(t *testing.T, cfg *config.Config, attempts *MemoryStore.MemoryLoginAttemptRepository, store *MemoryStore.Store) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router, err := NewRouter(cfg)
+	require.NoError(t, err)
+
+	hasher, err := utils.NewPasswordHasher(utils.PasswordParams{Algorithm: utils.PasswordAlgorithmArgon2id, Argon2Memory: 64, Argon2Iterations: 1, Argon2Parallelism: 1})
+	require.NoError(t, err)
+	policy := domain.LoginPolicy{MaxFailures: 100, MaxFailuresPerIP: 3, Window: time.Hour, Lockout: time.Hour}
+	authService := services.NewAuthService(MemoryStore.NewMemoryUserRepository(store), attempts, MemoryStore.NewMemoryMfaRepository(store),
+		MemoryStore.NewMemorySessionRepository(store), MemoryStore.NewMemoryAuditLogRepository(store), nil, hasher, policy, time.Minute, "Liongate")
+	auth := controllers.NewAuthMiddleware(authService, nil)
+
+	router.Use(controllers.RequestMeta(), controllers.ErrorHandler())
+	controllers.NewAuthController(authService, nil, auth).RegisterRoutes(router)
+	return router
+}
+
+// login fails a login from remoteAddr that claims to be forwarded for forwardedFor
+func login(router *gin.Engine, remoteAddr, forwardedFor string, username string) int {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(fmt.Sprintf(`{"username":%q,"password":"wrong-password"}`, username)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestNewRouterTrustedProxies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("a spoofed X-Forwarded-For does not reset the per-IP counter", func(t *testing.T) {
+		store := MemoryStore.NewStore()
+		attempts := MemoryStore.NewMemoryLoginAttemptRepository(store)
+		router := newLoginRouter(t, &config.Config{}, attempts, store)
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusUnauthorized, login(router, "203.0.113.7:4000", fmt.Sprintf("198.51.100.%d", i), fmt.Sprintf("user%d", i)))
+		}
+		assert.Equal(t, http.StatusTooManyRequests, login(router, "203.0.113.7:4000", "198.51.100.99", "user99"))
+
+		ipAttempts, err := attempts.GetLoginAttempts(ctx, []string{domain.LoginKey(domain.LoginKeyIP, "203.0.113.7")})
+		require.NoError(t, err)
+		require.Len(t, ipAttempts, 1)
+		assert.Equal(t, 3, ipAttempts[0].Failures)
+		forwarded, err := attempts.GetLoginAttempts(ctx, []string{domain.LoginKey(domain.LoginKeyIP, "198.51.100.0")})
+		require.NoError(t, err)
+		assert.Empty(t, forwarded)
+	})
+
+	t.Run("a configured proxy forwards the client IP", func(t *testing.T) {
+		store := MemoryStore.NewStore()
+		attempts := MemoryStore.NewMemoryLoginAttemptRepository(store)
+		router := newLoginRouter(t, &config.Config{Server: config.Server{TrustedProxies: []string{"10.0.0.0/8"}}}, attempts, store)
+
+		assert.Equal(t, http.StatusUnauthorized, login(router, "10.0.0.2:4000", "198.51.100.1", "alice"))
+
+		forwarded, err := attempts.GetLoginAttempts(ctx, []string{domain.LoginKey(domain.LoginKeyIP, "198.51.100.1")})
+		require.NoError(t, err)
+		require.Len(t, forwarded, 1)
+		assert.Equal(t, 1, forwarded[0].Failures)
+	})
+
+	t.Run("an invalid proxy is refused", func(t *testing.T) {
+		_, err := NewRouter(&config.Config{Server: config.Server{TrustedProxies: []string{"not-an-ip"}}})
+		assert.ErrorContains(t, err, "SERVER_TRUSTED_PROXIES")
+	})
+}
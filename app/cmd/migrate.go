@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/adapter/config"
+	GormStore "github.com/khunmostz/be-liongate-go/app/adapter/store/repository/gorm"
+	MongoStore "github.com/khunmostz/be-liongate-go/app/adapter/store/repository/mongo"
+)
+
+const migrateUsage = "usage: migrate up | down | status | to <version>"
+
+// runMigrate implements the `migrate` subcommand; SQL backends run versioned migrations, MongoDB applies its index registry
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	if cfg.IsMongoDB() {
+		return runMongoSchema(cfg, args[0])
+	}
+	if !cfg.IsPostgres() && !cfg.IsSQLite() {
+		fmt.Printf("DB_TYPE=%s has no versioned schema, nothing to migrate\n", cfg.Database.DbType)
+		return nil
+	}
+
+	db, err := GormStore.OpenDB(cfg)
+	if err != nil {
+		return err
+	}
+	migrator := GormStore.NewMigrator(db)
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		if err := migrator.Up(ctx); err != nil {
+			return err
+		}
+	case "down":
+		if err := migrator.Down(ctx); err != nil {
+			return err
+		}
+	case "to":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := migrator.To(ctx, version); err != nil {
+			return err
+		}
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Schema is at version %d of %d\n", version, migrator.Latest())
+	return nil
+}
+
+// runMongoSchema maps the migrate actions onto the MongoDB index registry, which has no versions:
+// up applies the registry and status reports missing and drifted indexes
+func runMongoSchema(cfg *config.Config, action string) error {
+	var apply bool
+	switch action {
+	case "up":
+		apply = true
+	case "status":
+		apply = false
+	case "down", "to":
+		return errors.New("mongodb indexes are declarative, only up and status are supported")
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	db, err := MongoStore.OpenMongoDB(cfg)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var report *MongoStore.SchemaReport
+	if apply {
+		report, err = MongoStore.EnsureSchema(ctx, db)
+	} else {
+		report, err = MongoStore.CheckSchema(ctx, db)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, created := range report.Created {
+		fmt.Printf("created   %s\n", created)
+	}
+	for _, missing := range report.Missing {
+		fmt.Printf("missing   %s\n", missing)
+	}
+	for _, drift := range report.Drift {
+		fmt.Printf("drift     %s\n", drift)
+	}
+	if len(report.Created)+len(report.Missing)+len(report.Drift) == 0 {
+		fmt.Println("MongoDB indexes match the registry")
+	}
+	return nil
+}
+
+func printMigrationStatus(ctx context.Context, migrator *GormStore.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tAPPLIED AT\tDESCRIPTION")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, appliedAt, status.Description)
+	}
+	return w.Flush()
+}
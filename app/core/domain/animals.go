@@ -1,9 +1,49 @@
 package domain
 
+import "time"
+
 type Animals struct {
-	Id           string `json:"animal_id" bson:"_id" gorm:"primaryKey;column:animal_id;type:string"`
-	Name         string `json:"name" bson:"name" gorm:"column:name"`
-	Species      string `json:"species" bson:"species" gorm:"column:species"`
-	Type         string `json:"type" bson:"type" gorm:"column:type"`
-	ShowDuration int    `json:"show_duration" bson:"show_duration" gorm:"column:show_duration"`
+	Id           string     `json:"animal_id" bson:"_id" gorm:"primaryKey;column:animal_id;type:string"`
+	Name         string     `json:"name" bson:"name" gorm:"column:name"`
+	Species      string     `json:"species" bson:"species" gorm:"column:species"`
+	Type         string     `json:"type" bson:"type" gorm:"column:type"`
+	ShowDuration int        `json:"show_duration" bson:"show_duration" gorm:"column:show_duration"`
+	Version      int64      `json:"version" bson:"version" gorm:"column:version;not null;default:1"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty" gorm:"column:deleted_at;index"`
+	Audit        `bson:",inline"`
+}
+
+// GetVersion returns the optimistic concurrency version of the animal
+func (a Animals) GetVersion() int64 {
+	return a.Version
+}
+
+// GetId returns the id of the animal
+func (a Animals) GetId() string {
+	return a.Id
+}
+
+// GetDeletedAt returns when the animal was soft-deleted, nil while it is live
+func (a Animals) GetDeletedAt() *time.Time {
+	return a.DeletedAt
+}
+
+// Validate checks an animal that is about to be created
+func (a Animals) Validate() error {
+	var v Validation
+	v.Required("name", a.Name)
+	v.Required("species", a.Species)
+	a.validateFields(&v)
+	return v.Err()
+}
+
+// ValidateUpdate checks a partial update of an animal, whose empty fields are left unchanged
+func (a Animals) ValidateUpdate() error {
+	var v Validation
+	a.validateFields(&v)
+	return v.Err()
+}
+
+func (a Animals) validateFields(v *Validation) {
+	v.Min("show_duration", float64(a.ShowDuration), 0)
 }
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// AnonymousActor is recorded as the creator or updater of changes made by requests without an authenticated user
+const AnonymousActor = "anonymous"
+
+// Audit records when and by whom an entity was created and last changed.
+// The repositories stamp it from the actor of the request context; values sent by clients are ignored.
+type Audit struct {
+	CreatedAt time.Time `json:"created_at" bson:"created_at" gorm:"column:created_at;index;autoCreateTime:false"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at" gorm:"column:updated_at;index;autoUpdateTime:false"`
+	CreatedBy string    `json:"created_by" bson:"created_by" gorm:"column:created_by;not null;default:''"`
+	UpdatedBy string    `json:"updated_by" bson:"updated_by" gorm:"column:updated_by;not null;default:''"`
+}
+
+// Touch copies the update stamp of change, leaving the creation stamp alone
+func (a *Audit) Touch(change Audit) {
+	a.UpdatedAt = change.UpdatedAt
+	a.UpdatedBy = change.UpdatedBy
+}
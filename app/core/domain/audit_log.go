@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// Actions recorded in the audit log
+const (
+	AuditActionLogin                  = "login"
+	AuditActionLoginFailed            = "login_failed"
+	AuditActionRoleChanged            = "role_changed"
+	AuditActionStagePriceChanged      = "stage_price_changed"
+	AuditActionBookingCancelled       = "booking_cancelled"
+	AuditActionBookingRefunded        = "booking_refunded"
+	AuditActionRoundCancelled         = "round_cancelled"
+	AuditActionInvitationCreated      = "invitation_created"
+	AuditActionInvitationAccepted     = "invitation_accepted"
+	AuditActionAdminBootstrapped      = "admin_bootstrapped"
+	AuditActionLoginLocked            = "login_locked"
+	AuditActionLoginUnlocked          = "login_unlocked"
+	AuditActionMfaEnabled             = "mfa_enabled"
+	AuditActionMfaDisabled            = "mfa_disabled"
+	AuditActionMfaReset               = "mfa_reset"
+	AuditActionMfaCodesRenewed        = "mfa_recovery_codes_renewed"
+	AuditActionMfaRequirement         = "mfa_requirement_changed"
+	AuditActionPasswordResetRequested = "password_reset_requested"
+	AuditActionPasswordReset          = "password_reset"
+	AuditActionPasswordChanged        = "password_changed"
+	AuditActionContactVerified        = "contact_verified"
+	AuditActionMarketingConsent       = "marketing_consent_changed"
+	AuditActionLogout                 = "logout"
+	AuditActionApiClientCreated       = "api_client_created"
+	AuditActionApiKeyIssued           = "api_key_issued"
+	AuditActionApiKeyRotated          = "api_key_rotated"
+	AuditActionApiKeyRevoked          = "api_key_revoked"
+	AuditActionOidcIdentityLinked     = "oidc_identity_linked"
+)
+
+// Kinds of entity an audit log entry can be about
+const (
+	AuditEntityUser       = "user"
+	AuditEntityStage      = "performance_stage"
+	AuditEntityShowRound  = "show_round"
+	AuditEntityBooking    = "booking"
+	AuditEntityInvitation = "invitation"
+	AuditEntityApiClient  = "api_client"
+	AuditEntityApiKey     = "api_key"
+	// AuditEntityLogin entries are about the failed login counter of a username or client IP, keyed by domain.LoginKey
+	AuditEntityLogin = "login"
+	// AuditEntityRole entries are about a setting of a role, keyed by the role
+	AuditEntityRole = "role"
+)
+
+// AuditLogEntry is an immutable record of an administrative or security event. Before and After hold only the
+// fields the event changed, as they were before and after it.
+type AuditLogEntry struct {
+	Id         string         `json:"entry_id" bson:"_id" gorm:"primaryKey;column:entry_id;type:string"`
+	OccurredAt time.Time      `json:"occurred_at" bson:"occurred_at" gorm:"column:occurred_at;index"`
+	Actor      string         `json:"actor" bson:"actor" gorm:"column:actor;index"`
+	Action     string         `json:"action" bson:"action" gorm:"column:action;index"`
+	EntityType string         `json:"entity_type" bson:"entity_type" gorm:"column:entity_type;index:idx_audit_log_entries_entity"`
+	EntityId   string         `json:"entity_id" bson:"entity_id" gorm:"column:entity_id;index:idx_audit_log_entries_entity"`
+	Before     map[string]any `json:"before,omitempty" bson:"before,omitempty" gorm:"column:before;serializer:json"`
+	After      map[string]any `json:"after,omitempty" bson:"after,omitempty" gorm:"column:after;serializer:json"`
+	IP         string         `json:"ip" bson:"ip" gorm:"column:ip"`
+	RequestId  string         `json:"request_id" bson:"request_id" gorm:"column:request_id"`
+}
@@ -4,15 +4,21 @@ import "time"
 
 // LoginRequest represents the login request payload
 type LoginRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username string `json:"username"`
+	Password string `json:"password"`
 }
 
-// RegisterRequest represents the register request payload
+// RegisterRequest represents the register request payload. Public registration always creates a user with
+// RoleUser; other roles are granted through invitations.
 type RegisterRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
-	Role     string `json:"role"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Email    string `json:"email"`
+}
+
+// Validate checks a registration; like Users.Validate it leaves the password policy to the services
+func (r RegisterRequest) Validate() error {
+	return Users{Username: r.Username, Password: r.Password, Email: r.Email, Role: RoleUser}.Validate()
 }
 
 // TokenPair represents access and refresh tokens
@@ -21,25 +27,36 @@ type TokenPair struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
-// AuthResponse represents the authentication response
+// AuthResponse represents the authentication response. A login that still needs a second factor carries
+// an Mfa challenge instead of Tokens; a login that completes an enrollment also carries the new RecoveryCodes.
 type AuthResponse struct {
-	User   *Users     `json:"user"`
-	Tokens *TokenPair `json:"tokens"`
+	User          *Users        `json:"user"`
+	Tokens        *TokenPair    `json:"tokens"`
+	Mfa           *MfaChallenge `json:"mfa,omitempty"`
+	RecoveryCodes []string      `json:"recovery_codes,omitempty"`
 }
 
 // RefreshTokenRequest represents refresh token request
 type RefreshTokenRequest struct {
-	RefreshToken string `json:"refresh_token" binding:"required"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 // JWTClaims represents the JWT claims structure
 type JWTClaims struct {
-	UserID    string `json:"user_id"`
-	Username  string `json:"username"`
-	Role      string `json:"role"`
-	Type      string `json:"type"` // "access" or "refresh"
-	IssuedAt  int64  `json:"iat"`
-	ExpiresAt int64  `json:"exp"`
+	// TokenId is the unique id (jti) a single token is revoked by; tokens issued before it was introduced have none
+	TokenId   string    `json:"jti"`
+	UserID    string    `json:"sub"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	Type      string    `json:"type"` // "access", "refresh" or "mfa"
+	IssuedAt  time.Time `json:"iat"`
+	ExpiresAt int64     `json:"exp"`
+}
+
+// LogoutRequest ends the session of the access token it is made with; a refresh token of the same user, when
+// given, is revoked too
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 // RefreshToken represents stored refresh token
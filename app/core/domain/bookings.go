@@ -1,10 +1,91 @@
 package domain
 
+import "time"
+
+const (
+	BookingStatusConfirmed = "confirmed"
+	BookingStatusCancelled = "cancelled"
+	// BookingStatusRefunded is a cancelled booking whose price was paid back
+	BookingStatusRefunded = "refunded"
+)
+
 type Bookings struct {
-	Id         string  `json:"booking_id" bson:"_id" gorm:"primaryKey;column:booking_id;type:string"`
-	UserId     string  `json:"user_id" bson:"user_id" gorm:"column:user_id;type:string"`
-	RoundId    string  `json:"round_id" bson:"round_id" gorm:"column:round_id;type:string"`
-	SeatNumber int     `json:"seat_number" bson:"seat_number" gorm:"column:seat_number"`
-	Price      float64 `json:"price" bson:"price" gorm:"column:price"`
-	QrCode     string  `json:"qr_code" bson:"qr_code" gorm:"column:qr_code"`
+	Id         string     `json:"booking_id" bson:"_id" gorm:"primaryKey;column:booking_id;type:string"`
+	UserId     string     `json:"user_id" bson:"user_id" gorm:"column:user_id;type:string"`
+	RoundId    string     `json:"round_id" bson:"round_id" gorm:"column:round_id;type:string"`
+	SeatNumber int        `json:"seat_number" bson:"seat_number" gorm:"column:seat_number"`
+	Price      float64    `json:"price" bson:"price" gorm:"column:price"`
+	QrCode     string     `json:"qr_code" bson:"qr_code" gorm:"column:qr_code"`
+	Status     string     `json:"status" bson:"status" gorm:"column:status;default:confirmed;index"`
+	Version    int64      `json:"version" bson:"version" gorm:"column:version;not null;default:1"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty" gorm:"column:deleted_at;index"`
+	Audit      `bson:",inline"`
+}
+
+// IsCancelled reports whether the booking was cancelled, with or without a refund, which frees its seat
+func (b Bookings) IsCancelled() bool {
+	return b.Status == BookingStatusCancelled || b.Status == BookingStatusRefunded
+}
+
+// GetVersion returns the optimistic concurrency version of the booking
+func (b Bookings) GetVersion() int64 {
+	return b.Version
+}
+
+// GetId returns the id of the booking
+func (b Bookings) GetId() string {
+	return b.Id
+}
+
+// GetDeletedAt returns when the booking was soft-deleted, nil while it is live
+func (b Bookings) GetDeletedAt() *time.Time {
+	return b.DeletedAt
+}
+
+// Validate checks a booking that is about to be created
+func (b Bookings) Validate() error {
+	var v Validation
+	v.Required("user_id", b.UserId)
+	v.Required("round_id", b.RoundId)
+	v.Min("seat_number", float64(b.SeatNumber), 1)
+	b.validateFields(&v)
+	return v.Err()
+}
+
+// ValidateUpdate checks a partial update of a booking, whose empty fields are left unchanged
+func (b Bookings) ValidateUpdate() error {
+	var v Validation
+	if b.SeatNumber != 0 {
+		v.Min("seat_number", float64(b.SeatNumber), 1)
+	}
+	b.validateFields(&v)
+	return v.Err()
+}
+
+// Merge returns the booking with the non-empty fields of a partial update applied
+func (b Bookings) Merge(change *Bookings) Bookings {
+	if change.UserId != "" {
+		b.UserId = change.UserId
+	}
+	if change.RoundId != "" {
+		b.RoundId = change.RoundId
+	}
+	if change.SeatNumber != 0 {
+		b.SeatNumber = change.SeatNumber
+	}
+	if change.Price != 0 {
+		b.Price = change.Price
+	}
+	if change.QrCode != "" {
+		b.QrCode = change.QrCode
+	}
+	if change.Status != "" {
+		b.Status = change.Status
+	}
+	return b
+}
+
+func (b Bookings) validateFields(v *Validation) {
+	v.Min("price", b.Price, 0)
+	v.OneOf("status", b.Status, BookingStatusConfirmed, BookingStatusCancelled, BookingStatusRefunded)
 }
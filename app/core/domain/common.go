@@ -1,8 +1,20 @@
 package domain
 
-// ErrorResponse represents a standard error response structure for API endpoints
-type ErrorResponse struct {
-	Error string `json:"error" example:"An error occurred"`
+// ProblemDetails is the RFC 7807 application/problem+json body of every error response.
+// Clients branch on Code, which is stable, rather than on Title or Detail, which are meant for people.
+type ProblemDetails struct {
+	// Type is a URI naming the problem; it is derived from Code
+	Type   string `json:"type" example:"urn:liongate:problem:not_found"`
+	Title  string `json:"title" example:"entity not found"`
+	Status int    `json:"status" example:"404"`
+	Detail string `json:"detail,omitempty" example:"entity not found: animal \"42\""`
+	// Instance is the path of the request that failed
+	Instance string `json:"instance,omitempty" example:"/api/v1/animals/42"`
+	Code     string `json:"code" example:"not_found" enums:"validation_failed,invalid_query,invalid_reference,unauthorized,invalid_credentials,token_expired,forbidden,not_found,already_exists,reference_in_use,version_conflict,if_match_required,internal"`
+	// RequestId is the X-Request-ID of the request, to quote when reporting the problem
+	RequestId string `json:"request_id,omitempty" example:"5f0c6d1e-8f7a-4a3b-9c2d-1e2f3a4b5c6d"`
+	// Errors lists the invalid fields of a validation_failed problem
+	Errors []FieldError `json:"errors,omitempty"`
 }
 
 // SuccessMessage represents a standard success message response
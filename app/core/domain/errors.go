@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrorKind classifies a domain error by what went wrong, independently of the transport that reports it
+type ErrorKind int
+
+const (
+	// KindInternal is the kind of every error that is not a domain error, such as a lost database connection
+	KindInternal ErrorKind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindTooManyRequests
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not_found"
+	case KindConflict:
+		return "conflict"
+	case KindValidation:
+		return "validation"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindForbidden:
+		return "forbidden"
+	case KindTooManyRequests:
+		return "too_many_requests"
+	default:
+		return "internal"
+	}
+}
+
+// Error is a domain error of a given kind. Code identifies the error for API clients and never changes once published.
+// The sentinel errors below are *Error values, so an error that wraps one with fmt.Errorf("%w: ...") matches it
+// with errors.Is and reports its kind and code through KindOf and AsError.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+}
+
+// NewError returns a domain error of kind; use it for sentinels, and wrap a sentinel to add details
+func NewError(kind ErrorKind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// AsError returns the outermost domain error err wraps, or nil when it wraps none
+func AsError(err error) *Error {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr
+	}
+	return nil
+}
+
+// KindOf returns the kind of the domain error err wraps, or KindInternal when it wraps none
+func KindOf(err error) ErrorKind {
+	if domainErr := AsError(err); domainErr != nil {
+		return domainErr.Kind
+	}
+	return KindInternal
+}
+
+// FieldError describes why one field of the input is invalid
+type FieldError struct {
+	// Field is the JSON name of the field, with nested fields separated by dots
+	Field string `json:"field" example:"seat_number"`
+	// Code names the rule the field breaks, such as required or min
+	Code string `json:"code" example:"min"`
+	// Params holds the arguments of the rule, such as the minimum, for clients that word the message themselves
+	Params  map[string]string `json:"params,omitempty"`
+	Message string            `json:"message" example:"seat_number must be at least 1"`
+}
+
+// ValidationError lists the fields of the input that are invalid. It wraps ErrValidation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
+	}
+	return ErrValidation.Message + ": " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// LoginThrottledError is returned by login while the username or client IP must wait after failed attempts.
+// It wraps ErrLoginThrottled.
+type LoginThrottledError struct {
+	// RetryAt is the earliest time another attempt is considered
+	RetryAt time.Time
+}
+
+func (e *LoginThrottledError) Error() string {
+	return ErrLoginThrottled.Message + ": try again after " + e.RetryAt.UTC().Format(time.RFC3339)
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrLoginThrottled
+}
+
+var (
+	// ErrNotFound is returned by repositories when the requested entity does not exist
+	ErrNotFound = NewError(KindNotFound, "not_found", "entity not found")
+	// ErrAlreadyExists is returned by repositories when a unique field such as a username is already taken
+	ErrAlreadyExists = NewError(KindConflict, "already_exists", "entity already exists")
+	// ErrInvalidReference is returned when an entity points at another entity that does not exist
+	ErrInvalidReference = NewError(KindValidation, "invalid_reference", "referenced entity does not exist")
+	// ErrReferenceInUse is returned when deleting an entity that other entities still reference
+	ErrReferenceInUse = NewError(KindConflict, "reference_in_use", "entity is still referenced")
+	// ErrInvalidQuery is returned when list paging, sorting or filter parameters are malformed
+	ErrInvalidQuery = NewError(KindValidation, "invalid_query", "invalid query")
+	// ErrVersionConflict is returned when an update or delete expects a version of the entity that is no longer current
+	ErrVersionConflict = NewError(KindConflict, "version_conflict", "entity was modified by someone else")
+	// ErrValidation is returned when a request carries input that is malformed or breaks a business rule
+	ErrValidation = NewError(KindValidation, "validation_failed", "invalid input")
+	// ErrUnauthorized is returned when the caller could not be authenticated, such as for a missing or malformed token
+	ErrUnauthorized = NewError(KindUnauthorized, "unauthorized", "authentication required")
+	// ErrInvalidCredentials is returned by login when the username or password is wrong
+	ErrInvalidCredentials = NewError(KindUnauthorized, "invalid_credentials", "invalid credentials")
+	// ErrTokenExpired is returned when an access or refresh token has expired and must be renewed
+	ErrTokenExpired = NewError(KindUnauthorized, "token_expired", "token expired")
+	// ErrForbidden is returned when the authenticated caller is not allowed to perform the action
+	ErrForbidden = NewError(KindForbidden, "forbidden", "forbidden")
+	// ErrLoginThrottled is returned by login while a username or client IP is delayed or locked out after failed attempts
+	ErrLoginThrottled = NewError(KindTooManyRequests, "login_throttled", "too many failed login attempts")
+	// ErrInvitationInvalid is returned when an invitation token is forged, expired or already used
+	ErrInvitationInvalid = NewError(KindForbidden, "invitation_invalid", "invitation is invalid")
+	// ErrPasswordResetInvalid is returned when a password reset token is unknown, expired or already used
+	ErrPasswordResetInvalid = NewError(KindForbidden, "password_reset_invalid", "password reset link is invalid")
+	// ErrMfaCodeInvalid is returned when a TOTP or recovery code is wrong, expired or already used
+	ErrMfaCodeInvalid = NewError(KindUnauthorized, "mfa_code_invalid", "invalid verification code")
+	// ErrMfaAlreadyEnabled is returned when a user who already has two-factor authentication starts enrolling again
+	ErrMfaAlreadyEnabled = NewError(KindConflict, "mfa_already_enabled", "two-factor authentication is already enabled")
+	// ErrMfaNotEnabled is returned when an action needs a second factor the user has not set up
+	ErrMfaNotEnabled = NewError(KindConflict, "mfa_not_enabled", "two-factor authentication is not enabled")
+	// ErrMfaRequired is returned when a user tries to turn off two-factor authentication their role requires
+	ErrMfaRequired = NewError(KindForbidden, "mfa_required", "two-factor authentication is required for this role")
+	// ErrVerificationInvalid is returned when a contact verification code is wrong, expired, used up or was sent
+	// to an address the user has changed since
+	ErrVerificationInvalid = NewError(KindForbidden, "verification_invalid", "verification code is invalid")
+	// ErrContactAlreadyVerified is returned when a code is requested for an address that is verified already
+	ErrContactAlreadyVerified = NewError(KindConflict, "contact_already_verified", "address is already verified")
+	// ErrOidcLoginInvalid is returned when a sign-in with an external identity provider cannot be completed, such as
+	// for an unknown or expired state, a rejected authorization code or an ID token that does not check out
+	ErrOidcLoginInvalid = NewError(KindUnauthorized, "oidc_login_invalid", "sign-in with the identity provider failed")
+	// ErrOidcIdentityLinked is returned when an identity at a provider is already linked to another user
+	ErrOidcIdentityLinked = NewError(KindConflict, "oidc_identity_linked", "identity is linked to another user")
+)
+
+var (
+	ErrInvitationExpired = fmt.Errorf("%w: invitation has expired", ErrInvitationInvalid)
+	ErrInvitationUsed    = fmt.Errorf("%w: invitation has already been used", ErrInvitationInvalid)
+	ErrSessionRevoked    = fmt.Errorf("%w: session has been revoked", ErrUnauthorized)
+	ErrTokenRevoked      = fmt.Errorf("%w: token has been revoked", ErrUnauthorized)
+	ErrApiKeyInvalid     = fmt.Errorf("%w: invalid API key", ErrUnauthorized)
+	ErrApiKeyExpired     = fmt.Errorf("%w: API key has expired", ErrApiKeyInvalid)
+	ErrApiKeyRevoked     = fmt.Errorf("%w: API key has been revoked", ErrApiKeyInvalid)
+)
+
+// CheckVersion returns ErrVersionConflict when expected is set and differs from current.
+// An expected version of 0 skips the check, which internal updates that do not come from a client rely on.
+func CheckVersion(expected, current int64) error {
+	if expected != 0 && expected != current {
+		return fmt.Errorf("%w: expected version %d, current version is %d", ErrVersionConflict, expected, current)
+	}
+	return nil
+}
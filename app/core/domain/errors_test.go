@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorKind
+	}{
+		{"sentinel", ErrNotFound, KindNotFound},
+		{"wrapped sentinel", fmt.Errorf("%w: seat 5 is taken", ErrAlreadyExists), KindConflict},
+		{"twice wrapped", fmt.Errorf("booking: %w", CheckVersion(1, 2)), KindConflict},
+		{"validation", fmt.Errorf("%w: cannot sort by %q", ErrInvalidQuery, "x"), KindValidation},
+		{"credentials", ErrInvalidCredentials, KindUnauthorized},
+		{"forbidden", ErrForbidden, KindForbidden},
+		{"throttled login", &LoginThrottledError{}, KindTooManyRequests},
+		{"plain error", errors.New("connection refused"), KindInternal},
+		{"nil", nil, KindInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, KindOf(tt.err))
+		})
+	}
+}
+
+func TestWrappedSentinelMatches(t *testing.T) {
+	err := fmt.Errorf("%w: unknown username", ErrInvalidCredentials)
+
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.NotErrorIs(t, err, ErrUnauthorized)
+	assert.Equal(t, "invalid credentials: unknown username", err.Error())
+}
+
+func TestValidationError(t *testing.T) {
+	err := fmt.Errorf("booking: %w", &ValidationError{Fields: []FieldError{
+		{Field: "round_id", Code: "required", Message: "round_id is required"},
+		{Field: "seat_number", Code: "min", Message: "seat_number must be at least 1"},
+	}})
+
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.Equal(t, KindValidation, KindOf(err))
+	assert.Equal(t, "validation_failed", AsError(err).Code)
+	assert.Equal(t, "booking: invalid input: round_id is required; seat_number must be at least 1", err.Error())
+}
@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// Invitation lets its holder sign up with a role that public registration does not grant. The invitee receives
+// a link carrying a token signed by the server; the invitation can be accepted once, until it expires.
+type Invitation struct {
+	Id         string     `json:"invitation_id" bson:"_id" gorm:"primaryKey;column:invitation_id;type:string"`
+	Role       string     `json:"role" bson:"role" gorm:"column:role"`
+	ExpiresAt  time.Time  `json:"expires_at" bson:"expires_at" gorm:"column:expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty" bson:"accepted_at,omitempty" gorm:"column:accepted_at"`
+	AcceptedBy string     `json:"accepted_by,omitempty" bson:"accepted_by,omitempty" gorm:"column:accepted_by"`
+	Audit      `bson:",inline"`
+}
+
+// Usable returns nil when the invitation can still be accepted at now, and otherwise ErrInvitationInvalid
+// saying why not
+func (i Invitation) Usable(now time.Time) error {
+	if i.AcceptedAt != nil {
+		return ErrInvitationUsed
+	}
+	if !now.Before(i.ExpiresAt) {
+		return ErrInvitationExpired
+	}
+	return nil
+}
+
+// InvitationRequest asks for an invitation to the given role
+type InvitationRequest struct {
+	Role string
+}
+
+func (r InvitationRequest) Validate() error {
+	var v Validation
+	v.Required("role", r.Role)
+	v.OneOf("role", r.Role, RoleAdmin, RoleUser)
+	return v.Err()
+}
+
+// InvitationLink is a created invitation together with the signed token and link to hand to the invitee
+type InvitationLink struct {
+	Invitation *Invitation
+	Token      string
+	URL        string
+}
+
+// AcceptInvitationRequest signs up with the token of an invitation
+type AcceptInvitationRequest struct {
+	Token    string
+	Username string
+	Password string
+}
+
+// Validate checks the request; like Users.Validate it leaves the password policy to the services
+func (r AcceptInvitationRequest) Validate() error {
+	var v Validation
+	v.Required("token", r.Token)
+	v.Required("username", r.Username)
+	v.Required("password", r.Password)
+	return v.Err()
+}
+
+// InvitationClaims are the claims of a signed invitation token
+type InvitationClaims struct {
+	InvitationId string
+	Role         string
+	ExpiresAt    int64
+}
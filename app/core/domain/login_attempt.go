@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Prefixes of the keys failed logins are counted under
+const (
+	LoginKeyUsername = "username"
+	LoginKeyIP       = "ip"
+)
+
+// LoginAttempt counts the recent failed logins for one username or one client IP
+type LoginAttempt struct {
+	// Key is the kind of the counter and its subject, such as "username:alice" or "ip:203.0.113.7"
+	Key           string     `json:"key" bson:"_id" gorm:"primaryKey;column:attempt_key;type:string"`
+	Failures      int        `json:"failures" bson:"failures" gorm:"column:failures;not null;default:0"`
+	LastFailureAt time.Time  `json:"last_failure_at" bson:"last_failure_at" gorm:"column:last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty" bson:"locked_until,omitempty" gorm:"column:locked_until;index"`
+}
+
+// LoginKey is the key failed logins for value are counted under, kind being LoginKeyUsername or LoginKeyIP
+func LoginKey(kind string, value string) string {
+	return kind + ":" + value
+}
+
+// LoginKeyKind returns the kind of a key made by LoginKey
+func LoginKeyKind(key string) string {
+	kind, _, _ := strings.Cut(key, ":")
+	return kind
+}
+
+// LoginPolicy decides when failed logins slow down and lock out further attempts
+type LoginPolicy struct {
+	// MaxFailures locks a username out once it has failed this many times within Window
+	MaxFailures int
+	// MaxFailuresPerIP locks a client IP out once it has failed this many times within Window, across all usernames
+	MaxFailuresPerIP int
+	// Window is how long a failure counts; a failure after a quiet Window starts counting from one again
+	Window time.Duration
+	// Lockout is how long a locked out username or IP has to wait
+	Lockout time.Duration
+	// BaseDelay is the wait after the first failure of a username; it doubles with every further failure up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// Threshold returns the number of failures that locks out the subject of key, or 0 when it is never locked out
+func (p LoginPolicy) Threshold(key string) int {
+	if LoginKeyKind(key) == LoginKeyIP {
+		return p.MaxFailuresPerIP
+	}
+	return p.MaxFailures
+}
+
+// Delay returns how long a username has to wait after failing failures times in a row
+func (p LoginPolicy) Delay(failures int) time.Duration {
+	if failures <= 0 || p.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// BlockedUntil returns the time before which the subject of the attempt may not try again, which is the end of
+// its lockout or, for usernames, of the delay after its last failure. A zero time means it is not blocked.
+func (a LoginAttempt) BlockedUntil(policy LoginPolicy) time.Time {
+	var until time.Time
+	if a.LockedUntil != nil {
+		until = *a.LockedUntil
+	}
+	// Client IPs are only locked out, not delayed, as many users may share one behind a NAT
+	if LoginKeyKind(a.Key) == LoginKeyUsername {
+		if delayed := a.LastFailureAt.Add(policy.Delay(a.Failures)); delayed.After(until) {
+			until = delayed
+		}
+	}
+	return until
+}
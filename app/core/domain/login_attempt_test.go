@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginPolicy(t *testing.T) {
+	policy := LoginPolicy{MaxFailures: 5, MaxFailuresPerIP: 50, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	t.Run("delay doubles up to the maximum", func(t *testing.T) {
+		tests := []struct {
+			failures int
+			want     time.Duration
+		}{
+			{0, 0},
+			{1, time.Second},
+			{2, 2 * time.Second},
+			{4, 8 * time.Second},
+			{5, 10 * time.Second},
+			{64, 10 * time.Second},
+		}
+		for _, tt := range tests {
+			assert.Equal(t, tt.want, policy.Delay(tt.failures), "after %d failures", tt.failures)
+		}
+	})
+
+	t.Run("threshold by kind", func(t *testing.T) {
+		assert.Equal(t, 5, policy.Threshold(LoginKey(LoginKeyUsername, "alice")))
+		assert.Equal(t, 50, policy.Threshold(LoginKey(LoginKeyIP, "203.0.113.7")))
+	})
+
+	t.Run("blocked until", func(t *testing.T) {
+		last := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		locked := last.Add(time.Minute)
+
+		assert.Equal(t, last.Add(4*time.Second), LoginAttempt{Key: "username:alice", Failures: 3, LastFailureAt: last}.BlockedUntil(policy))
+		assert.Equal(t, locked, LoginAttempt{Key: "username:alice", Failures: 3, LastFailureAt: last, LockedUntil: &locked}.BlockedUntil(policy))
+		assert.True(t, LoginAttempt{Key: "ip:203.0.113.7", Failures: 3, LastFailureAt: last}.BlockedUntil(policy).IsZero(), "IPs are not delayed")
+	})
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// MfaEnrollment is the TOTP second factor of a user. It is stored unconfirmed when the user starts enrolling
+// and only takes effect once a first code from the authenticator app confirms it.
+type MfaEnrollment struct {
+	UserId      string     `json:"user_id" bson:"_id" gorm:"primaryKey;column:user_id;type:string"`
+	Secret      string     `json:"secret" bson:"secret" gorm:"column:secret"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty" bson:"confirmed_at,omitempty" gorm:"column:confirmed_at"`
+	// LastStep is the TOTP time step of the last code accepted; only codes of later steps are accepted, so that a code cannot be replayed
+	LastStep int64 `json:"last_step" bson:"last_step" gorm:"column:last_step;not null;default:0"`
+	// RecoveryCodes holds the hashes of the recovery codes that have not been used yet
+	RecoveryCodes []string `json:"recovery_codes" bson:"recovery_codes" gorm:"-"`
+	Audit         `bson:",inline"`
+}
+
+// Confirmed reports whether the enrollment is in effect
+func (e MfaEnrollment) Confirmed() bool {
+	return e.ConfirmedAt != nil
+}
+
+// MfaRequirement makes the users of a role set up two-factor authentication before they can log in
+type MfaRequirement struct {
+	Role  string `json:"role" bson:"_id" gorm:"primaryKey;column:role;type:string"`
+	Audit `bson:",inline"`
+}
+
+// MfaRequirementRequest turns the requirement of two-factor authentication for a role on or off
+type MfaRequirementRequest struct {
+	Role     string
+	Required bool
+}
+
+func (r MfaRequirementRequest) Validate() error {
+	var v Validation
+	v.OneOf("role", r.Role, RoleAdmin, RoleUser)
+	return v.Err()
+}
+
+// MfaSetup is what an authenticator app needs to enroll: the secret, the otpauth URI carrying it,
+// and that URI as a PNG QR code
+type MfaSetup struct {
+	Secret string
+	URI    string
+	QRCode []byte
+}
+
+// MfaChallenge is returned by the first step of a login that needs a second factor instead of the tokens.
+// Token is exchanged together with a code for the tokens; with EnrollmentRequired the user must first enroll with it.
+type MfaChallenge struct {
+	Token              string
+	ExpiresAt          time.Time
+	EnrollmentRequired bool
+}
+
+// MfaLoginRequest completes a login with the token of its MfaChallenge and either a TOTP code or a recovery code
+type MfaLoginRequest struct {
+	Token        string
+	Code         string
+	RecoveryCode string
+}
+
+func (r MfaLoginRequest) Validate() error {
+	var v Validation
+	v.Required("mfa_token", r.Token)
+	if r.RecoveryCode == "" {
+		v.Required("code", r.Code)
+	}
+	return v.Err()
+}
+
+// MfaCodeRequest proves possession of the second factor with either a TOTP code or a recovery code
+type MfaCodeRequest struct {
+	Code         string
+	RecoveryCode string
+}
+
+func (r MfaCodeRequest) Validate() error {
+	var v Validation
+	if r.RecoveryCode == "" {
+		v.Required("code", r.Code)
+	}
+	return v.Err()
+}
+
+// NormalizeRecoveryCode strips the separators and case a user may type a recovery code with
+func NormalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '-' || r == ' ':
+			return -1
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return r
+	}, code)
+}
@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// OidcIdentity links the account of a user at an external OpenID Connect provider, named by the provider and the
+// subject it identifies the user with, to a user. An identity belongs to one user; a user may have several.
+type OidcIdentity struct {
+	Id       string `json:"identity_id" bson:"_id" gorm:"primaryKey;column:identity_id;type:string"`
+	Provider string `json:"provider" bson:"provider" gorm:"column:provider;uniqueIndex:idx_oidc_identities_subject"`
+	Subject  string `json:"subject" bson:"subject" gorm:"column:subject;uniqueIndex:idx_oidc_identities_subject"`
+	UserId   string `json:"user_id" bson:"user_id" gorm:"column:user_id;index"`
+	// Email is the address the provider reported when the identity was linked
+	Email string `json:"email,omitempty" bson:"email,omitempty" gorm:"column:email;not null;default:''"`
+	Audit `bson:",inline"`
+}
+
+// OidcLogin is a sign-in waiting for the browser to return from the provider. It is stored under the hash of the
+// state sent along, and removed when the browser returns, so that each state completes one sign-in.
+type OidcLogin struct {
+	StateHash string `json:"-" bson:"_id" gorm:"primaryKey;column:state_hash;type:string"`
+	Provider  string `json:"provider" bson:"provider" gorm:"column:provider"`
+	// CodeVerifier is the PKCE secret the authorization code is redeemed with; only its challenge leaves the server
+	CodeVerifier string `json:"-" bson:"code_verifier" gorm:"column:code_verifier"`
+	// Nonce is bound into the ID token, so that a token issued for another sign-in is not accepted
+	Nonce string `json:"-" bson:"nonce" gorm:"column:nonce"`
+	// UserId is the signed-in user the identity is linked to, empty for a sign-in
+	UserId    string    `json:"user_id,omitempty" bson:"user_id,omitempty" gorm:"column:user_id;not null;default:''"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at" gorm:"column:expires_at"`
+	Audit     `bson:",inline"`
+}
+
+// Expired reports whether the sign-in can no longer be completed at now
+func (l OidcLogin) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// OidcClaims are the claims of a validated ID token the services rely on
+type OidcClaims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// OidcAuthorization is where to send the browser to sign in at a provider. The client keeps State, and only posts
+// the code back when the provider returns the same state, so that nobody can complete a sign-in they started.
+type OidcAuthorization struct {
+	URL       string
+	State     string
+	ExpiresAt time.Time
+}
+
+// OidcLoginRequest completes a sign-in with the state and authorization code the provider returned
+type OidcLoginRequest struct {
+	State string
+	Code  string
+}
+
+func (r OidcLoginRequest) Validate() error {
+	var v Validation
+	v.Required("state", r.State)
+	v.Required("code", r.Code)
+	return v.Err()
+}
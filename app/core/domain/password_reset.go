@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// PasswordResetToken is a pending password reset. The user receives a link carrying a random token; only its hash
+// is stored, and it is removed when used, so that a token resets a password once, until it expires.
+type PasswordResetToken struct {
+	TokenHash string    `json:"-" bson:"_id" gorm:"primaryKey;column:token_hash;type:string"`
+	UserId    string    `json:"user_id" bson:"user_id" gorm:"column:user_id;index"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at" gorm:"column:expires_at"`
+	Audit     `bson:",inline"`
+}
+
+// Expired reports whether the token can no longer be used at now
+func (t PasswordResetToken) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// ForgotPasswordRequest asks for a password reset link to be mailed to the user
+type ForgotPasswordRequest struct {
+	Username string
+}
+
+func (r ForgotPasswordRequest) Validate() error {
+	var v Validation
+	v.Required("username", r.Username)
+	return v.Err()
+}
+
+// ResetPasswordRequest sets a new password with the token of a reset link
+type ResetPasswordRequest struct {
+	Token    string
+	Password string
+}
+
+// Validate checks the request; like Users.Validate it leaves the password policy to the services
+func (r ResetPasswordRequest) Validate() error {
+	var v Validation
+	v.Required("token", r.Token)
+	v.Required("password", r.Password)
+	return v.Err()
+}
+
+// SessionRevocation records when every session of a user was revoked, such as after a password reset.
+// Tokens issued to the user up to then are rejected.
+type SessionRevocation struct {
+	UserId    string    `json:"user_id" bson:"_id" gorm:"primaryKey;column:user_id;type:string"`
+	RevokedAt time.Time `json:"revoked_at" bson:"revoked_at" gorm:"column:revoked_at"`
+}
+
+// Revokes reports whether a token issued at issuedAt is revoked. Tokens carry their issue time in milliseconds, so
+// that a login right after a password reset is not revoked by it; older tokens carry whole seconds, which only makes
+// them look issued earlier.
+func (r SessionRevocation) Revokes(issuedAt time.Time) bool {
+	return !issuedAt.After(r.RevokedAt)
+}
+
+// RevokedToken is a single token rejected before it expires, such as the access token of a logout. It is kept
+// only until ExpiresAt, after which the token is rejected anyway.
+type RevokedToken struct {
+	TokenId   string    `json:"jti" bson:"_id" gorm:"primaryKey;column:token_id;type:string"`
+	UserId    string    `json:"user_id" bson:"user_id" gorm:"column:user_id;index"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at" gorm:"column:expires_at;index"`
+	RevokedAt time.Time `json:"revoked_at" bson:"revoked_at" gorm:"column:revoked_at"`
+}
@@ -1,8 +1,47 @@
 package domain
 
+import "time"
+
 type PerformanceStage struct {
-	Id           string  `json:"stage_id" bson:"_id" gorm:"primaryKey;column:stage_id;type:string"`
-	RoomNumber   string  `json:"room_number" bson:"room_number" gorm:"column:room_number"`
-	SeatCapacity int     `json:"seat_capacity" bson:"seat_capacity" gorm:"column:seat_capacity"`
-	PricePerSeat float64 `json:"price_per_seat" bson:"price_per_seat" gorm:"column:price_per_seat"`
+	Id           string     `json:"stage_id" bson:"_id" gorm:"primaryKey;column:stage_id;type:string"`
+	RoomNumber   string     `json:"room_number" bson:"room_number" gorm:"column:room_number"`
+	SeatCapacity int        `json:"seat_capacity" bson:"seat_capacity" gorm:"column:seat_capacity"`
+	PricePerSeat float64    `json:"price_per_seat" bson:"price_per_seat" gorm:"column:price_per_seat"`
+	Version      int64      `json:"version" bson:"version" gorm:"column:version;not null;default:1"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty" gorm:"column:deleted_at;index"`
+	Audit        `bson:",inline"`
+}
+
+// GetVersion returns the optimistic concurrency version of the performance stage
+func (p PerformanceStage) GetVersion() int64 {
+	return p.Version
+}
+
+// GetId returns the id of the performance stage
+func (p PerformanceStage) GetId() string {
+	return p.Id
+}
+
+// GetDeletedAt returns when the performance stage was soft-deleted, nil while it is live
+func (p PerformanceStage) GetDeletedAt() *time.Time {
+	return p.DeletedAt
+}
+
+// Validate checks a stage that is about to be created
+func (p PerformanceStage) Validate() error {
+	var v Validation
+	v.Required("room_number", p.RoomNumber)
+	v.Min("seat_capacity", float64(p.SeatCapacity), 1)
+	v.Min("price_per_seat", p.PricePerSeat, 0)
+	return v.Err()
+}
+
+// ValidateUpdate checks a partial update of a stage, whose empty fields are left unchanged
+func (p PerformanceStage) ValidateUpdate() error {
+	var v Validation
+	if p.SeatCapacity != 0 {
+		v.Min("seat_capacity", float64(p.SeatCapacity), 1)
+	}
+	v.Min("price_per_seat", p.PricePerSeat, 0)
+	return v.Err()
 }
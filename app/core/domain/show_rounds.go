@@ -1,9 +1,60 @@
 package domain
 
+import "time"
+
 type ShowRounds struct {
-	Id       string     `json:"round_id" bson:"_id" gorm:"primaryKey;column:round_id;type:string"`
-	AnimalId string     `json:"animal_id" bson:"animal_id" gorm:"column:animal_id;type:string"`
-	StageId  string     `json:"stage_id" bson:"stage_id" gorm:"column:stage_id;type:string"`
-	ShowTime string     `json:"show_time" bson:"show_time" gorm:"column:show_time;type:timestamp"`
-	Bookings []Bookings `json:"bookings" bson:"bookings" gorm:"foreignKey:RoundId;references:Id"`
+	Id        string     `json:"round_id" bson:"_id" gorm:"primaryKey;column:round_id;type:string"`
+	AnimalId  string     `json:"animal_id" bson:"animal_id" gorm:"column:animal_id;type:string"`
+	StageId   string     `json:"stage_id" bson:"stage_id" gorm:"column:stage_id;type:string"`
+	ShowTime  string     `json:"show_time" bson:"show_time" gorm:"column:show_time;type:timestamp"`
+	Bookings  []Bookings `json:"bookings" bson:"bookings" gorm:"foreignKey:RoundId;references:Id"`
+	Version   int64      `json:"version" bson:"version" gorm:"column:version;not null;default:1"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty" gorm:"column:deleted_at;index"`
+	Audit     `bson:",inline"`
+}
+
+// GetVersion returns the optimistic concurrency version of the show round
+func (r ShowRounds) GetVersion() int64 {
+	return r.Version
+}
+
+// GetId returns the id of the show round
+func (r ShowRounds) GetId() string {
+	return r.Id
+}
+
+// GetDeletedAt returns when the show round was soft-deleted, nil while it is live
+func (r ShowRounds) GetDeletedAt() *time.Time {
+	return r.DeletedAt
+}
+
+// Validate checks a show round that is about to be created
+func (r ShowRounds) Validate() error {
+	var v Validation
+	v.Required("animal_id", r.AnimalId)
+	v.Required("stage_id", r.StageId)
+	v.Required("show_time", r.ShowTime)
+	v.Timestamp("show_time", r.ShowTime)
+	return v.Err()
+}
+
+// ValidateUpdate checks a partial update of a show round, whose empty fields are left unchanged
+func (r ShowRounds) ValidateUpdate() error {
+	var v Validation
+	v.Timestamp("show_time", r.ShowTime)
+	return v.Err()
+}
+
+// Merge returns the show round with the non-empty fields of a partial update applied
+func (r ShowRounds) Merge(change *ShowRounds) ShowRounds {
+	if change.AnimalId != "" {
+		r.AnimalId = change.AnimalId
+	}
+	if change.StageId != "" {
+		r.StageId = change.StageId
+	}
+	if change.ShowTime != "" {
+		r.ShowTime = change.ShowTime
+	}
+	return r
 }
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// Algorithms tokens can be signed with. HS256 is only accepted from tokens issued before asymmetric keys.
+const (
+	SigningAlgorithmRS256 = "RS256"
+	SigningAlgorithmEdDSA = "EdDSA"
+)
+
+// SigningKey is a private key tokens are signed with. A key is published as soon as it is created, signs tokens
+// from ActivatesAt until RetiresAt, and stays published until ExpiresAt so that the tokens it signed can still
+// be verified.
+type SigningKey struct {
+	Kid       string `json:"kid" bson:"_id" gorm:"primaryKey;column:kid;type:string"`
+	Algorithm string `json:"alg" bson:"algorithm" gorm:"column:algorithm"`
+	// PrivateKey is the PKCS #8 PEM encoding of the key
+	PrivateKey  string    `json:"-" bson:"private_key" gorm:"column:private_key"`
+	ActivatesAt time.Time `json:"activates_at" bson:"activates_at" gorm:"column:activates_at;index"`
+	RetiresAt   time.Time `json:"retires_at" bson:"retires_at" gorm:"column:retires_at"`
+	ExpiresAt   time.Time `json:"expires_at" bson:"expires_at" gorm:"column:expires_at;index"`
+	Audit       `bson:",inline"`
+}
+
+// Signs reports whether the key is the one to sign tokens with at now
+func (k SigningKey) Signs(now time.Time) bool {
+	return !now.Before(k.ActivatesAt) && now.Before(k.RetiresAt)
+}
+
+// Expired reports whether no token signed with the key can still be valid at now
+func (k SigningKey) Expired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
+
+// JSONWebKey is the public half of a signing key as published in the JWKS document (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty" example:"OKP"`
+	Kid string `json:"kid" example:"3b5d0c6e9f1a4b2c"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"EdDSA"`
+	// N and E are the modulus and exponent of an RSA key
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty" example:"AQAB"`
+	// Crv and X are the curve and public point of an Ed25519 key
+	Crv string `json:"crv,omitempty" example:"Ed25519"`
+	X   string `json:"x,omitempty"`
+}
@@ -0,0 +1,7 @@
+package domain
+
+// Sms is a text message to a single phone number in E.164 form
+type Sms struct {
+	To   string
+	Body string
+}
@@ -1,9 +1,131 @@
 package domain
 
+import (
+	"strings"
+	"time"
+)
+
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
 type Users struct {
 	Id       string     `json:"user_id" bson:"_id" gorm:"primaryKey;column:user_id;type:string"`
 	Username string     `json:"username" bson:"username" gorm:"column:username;unique"`
 	Password string     `json:"password" bson:"password" gorm:"column:password"`
+	Email    string     `json:"email,omitempty" bson:"email,omitempty" gorm:"column:email;not null;default:''"`
+	Phone    string     `json:"phone,omitempty" bson:"phone,omitempty" gorm:"column:phone;not null;default:''"`
 	Role     string     `json:"role" bson:"role" gorm:"column:role;default:user"`
 	Bookings []Bookings `json:"bookings" bson:"bookings" gorm:"foreignKey:UserId;references:Id"`
+	// DisplayName is how the user wants to be addressed; Language is a Language code, empty for no preference
+	DisplayName string `json:"display_name,omitempty" bson:"display_name,omitempty" gorm:"column:display_name;not null;default:''"`
+	Language    string `json:"language,omitempty" bson:"language,omitempty" gorm:"column:language;not null;default:''"`
+	// MarketingConsent is a pointer so that a partial update can withdraw the consent; nil leaves it unchanged
+	MarketingConsent *bool `json:"marketing_consent,omitempty" bson:"marketing_consent,omitempty" gorm:"column:marketing_consent;not null;default:false"`
+	// VerifiedEmail and VerifiedPhone are the addresses the user last proved to own. An address is verified while
+	// it equals the current one, so changing the email or phone makes it unverified without further bookkeeping.
+	VerifiedEmail string     `json:"verified_email,omitempty" bson:"verified_email,omitempty" gorm:"column:verified_email;not null;default:''"`
+	VerifiedPhone string     `json:"verified_phone,omitempty" bson:"verified_phone,omitempty" gorm:"column:verified_phone;not null;default:''"`
+	Version       int64      `json:"version" bson:"version" gorm:"column:version;not null;default:1"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty" gorm:"column:deleted_at;index"`
+	Audit         `bson:",inline"`
+}
+
+// GetVersion returns the optimistic concurrency version of the user
+func (u Users) GetVersion() int64 {
+	return u.Version
+}
+
+// GetId returns the id of the user
+func (u Users) GetId() string {
+	return u.Id
+}
+
+// GetDeletedAt returns when the user was soft-deleted, nil while it is live
+func (u Users) GetDeletedAt() *time.Time {
+	return u.DeletedAt
+}
+
+// Validate checks a user that is about to be created. The strength of the password is checked by the services,
+// which own the password policy.
+func (u Users) Validate() error {
+	var v Validation
+	v.Required("username", u.Username)
+	v.Required("password", u.Password)
+	v.OneOf("role", u.Role, RoleAdmin, RoleUser)
+	u.validateProfile(&v)
+	return v.Err()
+}
+
+// ValidateUpdate checks a partial update of a user, whose empty fields are left unchanged
+func (u Users) ValidateUpdate() error {
+	var v Validation
+	v.OneOf("role", u.Role, RoleAdmin, RoleUser)
+	u.validateProfile(&v)
+	return v.Err()
+}
+
+// MaxDisplayNameLength is the longest display name in characters
+const MaxDisplayNameLength = 100
+
+func (u Users) validateProfile(v *Validation) {
+	v.Email("email", u.Email)
+	v.Phone("phone", u.Phone)
+	v.MaxLength("display_name", u.DisplayName, MaxDisplayNameLength)
+	v.OneOf("language", u.Language, string(LanguageEnglish), string(LanguageThai))
+}
+
+// NormalizeContact brings the email and phone into the form they are stored and compared in, so that
+// "Somchai@Example.com " and "somchai@example.com" count as the same address: emails are trimmed and lower-cased,
+// and the spaces, dashes, dots and parentheses people write phone numbers with are removed.
+func (u *Users) NormalizeContact() {
+	u.Email = NormalizeEmail(u.Email)
+	u.Phone = NormalizePhone(u.Phone)
+}
+
+// NormalizeEmail returns email trimmed and lower-cased
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhone returns phone without the separators people write phone numbers with
+func NormalizePhone(phone string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(phone))
+}
+
+// Contact returns the address of the user on channel, empty when they have none
+func (u Users) Contact(channel string) string {
+	switch channel {
+	case ContactEmail:
+		return u.Email
+	case ContactPhone:
+		return u.Phone
+	default:
+		return ""
+	}
+}
+
+// ContactVerified reports whether the user proved to own their current address on channel
+func (u Users) ContactVerified(channel string) bool {
+	contact := u.Contact(channel)
+	switch channel {
+	case ContactEmail:
+		return contact != "" && contact == u.VerifiedEmail
+	case ContactPhone:
+		return contact != "" && contact == u.VerifiedPhone
+	default:
+		return false
+	}
+}
+
+// ConsentsToMarketing reports whether the user agreed to receive marketing messages
+func (u Users) ConsentsToMarketing() bool {
+	return u.MarketingConsent != nil && *u.MarketingConsent
 }
@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"errors"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Validation rules, reported as the code of a FieldError. The params of each rule are listed next to it.
+const (
+	RuleRequired  = "required"
+	RuleMin       = "min"        // min
+	RuleMax       = "max"        // max
+	RuleMinLength = "min_length" // min
+	RuleMaxLength = "max_length" // max
+	RuleOneOf     = "oneof"      // values
+	RuleFormat    = "format"     // format
+	RuleType      = "type"       // type
+	RuleInvalid   = "invalid"
+	RuleCommon    = "common"
+)
+
+// Language is a language field error messages can be written in
+type Language string
+
+const (
+	LanguageEnglish Language = "en"
+	LanguageThai    Language = "th"
+)
+
+// fieldMessages holds the message templates of every rule by language. {field} and {<param>} are replaced
+// by the name of the field and the params of the error.
+var fieldMessages = map[Language]map[string]string{
+	LanguageEnglish: {
+		RuleRequired:  "{field} is required",
+		RuleMin:       "{field} must be at least {min}",
+		RuleMax:       "{field} must be at most {max}",
+		RuleMinLength: "{field} must be at least {min} characters long",
+		RuleMaxLength: "{field} must be at most {max} characters long",
+		RuleOneOf:     "{field} must be one of {values}",
+		RuleFormat:    "{field} must be an {format}",
+		RuleType:      "{field} must be a {type}",
+		RuleInvalid:   "{field} is invalid",
+		RuleCommon:    "{field} is too common, choose another",
+	},
+	LanguageThai: {
+		RuleRequired:  "กรุณาระบุ {field}",
+		RuleMin:       "{field} ต้องมีค่าอย่างน้อย {min}",
+		RuleMax:       "{field} ต้องมีค่าไม่เกิน {max}",
+		RuleMinLength: "{field} ต้องมีความยาวอย่างน้อย {min} ตัวอักษร",
+		RuleMaxLength: "{field} ต้องมีความยาวไม่เกิน {max} ตัวอักษร",
+		RuleOneOf:     "{field} ต้องเป็นค่าใดค่าหนึ่งต่อไปนี้: {values}",
+		RuleFormat:    "{field} ต้องอยู่ในรูปแบบ {format}",
+		RuleType:      "{field} ต้องเป็นชนิด {type}",
+		RuleInvalid:   "{field} ไม่ถูกต้อง",
+		RuleCommon:    "{field} เป็นค่าที่ใช้กันทั่วไปเกินไป กรุณาเลือกค่าอื่น",
+	},
+}
+
+// NewFieldError returns the error of field breaking rule, with its message in English
+func NewFieldError(field string, rule string, params map[string]string) FieldError {
+	return FieldError{Field: field, Code: rule, Params: params}.Localize(LanguageEnglish)
+}
+
+// Localize returns a copy of the error with its message in lang, falling back to English for unknown languages
+func (f FieldError) Localize(lang Language) FieldError {
+	messages, ok := fieldMessages[lang]
+	if !ok {
+		messages = fieldMessages[LanguageEnglish]
+	}
+	template, ok := messages[f.Code]
+	if !ok {
+		template = messages[RuleInvalid]
+	}
+
+	replacements := []string{"{field}", f.Field}
+	for key, value := range f.Params {
+		replacements = append(replacements, "{"+key+"}", value)
+	}
+	f.Message = strings.NewReplacer(replacements...).Replace(template)
+	return f
+}
+
+// Validation collects the field errors of one payload
+type Validation struct {
+	fields []FieldError
+}
+
+// Add records that field breaks rule
+func (v *Validation) Add(field string, rule string, params map[string]string) {
+	v.fields = append(v.fields, NewFieldError(field, rule, params))
+}
+
+// Required records an error when value is empty
+func (v *Validation) Required(field string, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.Add(field, RuleRequired, nil)
+	}
+}
+
+// Min records an error when value is below min
+func (v *Validation) Min(field string, value float64, min float64) {
+	if value < min {
+		v.Add(field, RuleMin, map[string]string{"min": strconv.FormatFloat(min, 'f', -1, 64)})
+	}
+}
+
+// OneOf records an error when value is set and is none of values
+func (v *Validation) OneOf(field string, value string, values ...string) {
+	for _, allowed := range values {
+		if value == allowed {
+			return
+		}
+	}
+	if value != "" {
+		v.Add(field, RuleOneOf, map[string]string{"values": strings.Join(values, ", ")})
+	}
+}
+
+// Timestamp records an error when value is set and is not an RFC 3339 timestamp
+func (v *Validation) Timestamp(field string, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := time.Parse(time.RFC3339, value); err != nil {
+		v.Add(field, RuleFormat, map[string]string{"format": "RFC 3339 timestamp"})
+	}
+}
+
+// Email records an error when value is set and is not a bare email address such as "somchai@example.com"
+func (v *Validation) Email(field string, value string) {
+	if value == "" {
+		return
+	}
+	if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+		v.Add(field, RuleFormat, map[string]string{"format": "email address"})
+	}
+}
+
+// MaxLength records an error when value is longer than max characters
+func (v *Validation) MaxLength(field string, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		v.Add(field, RuleMaxLength, map[string]string{"max": strconv.Itoa(max)})
+	}
+}
+
+// phonePattern matches an E.164 phone number: a plus sign, the country code and at most 15 digits in all
+var phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// Phone records an error when value is set and is not an E.164 phone number such as "+66812345678"
+func (v *Validation) Phone(field string, value string) {
+	if value != "" && !phonePattern.MatchString(value) {
+		v.Add(field, RuleFormat, map[string]string{"format": "E.164 phone number"})
+	}
+}
+
+// Err returns the collected errors as a *ValidationError, or nil when there are none
+func (v *Validation) Err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
+
+// JoinValidation merges the field errors of several validations into one *ValidationError.
+// It returns the first error that is not a validation error as it is, and nil when every error is nil.
+func JoinValidation(errs ...error) error {
+	var v Validation
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			return err
+		}
+		v.fields = append(v.fields, validationErr.Fields...)
+	}
+	return v.Err()
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fieldCodes returns the field and rule of every field error of err
+func fieldCodes(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+
+	codes := make([]string, len(validationErr.Fields))
+	for i, field := range validationErr.Fields {
+		codes[i] = field.Field + ":" + field.Code
+	}
+	return codes
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"valid animal", Animals{Name: "Leo", Species: "lion", ShowDuration: 30}.Validate(), nil},
+		{"animal without name and negative duration", Animals{Species: "lion", ShowDuration: -5}.Validate(),
+			[]string{"name:required", "show_duration:min"}},
+		{"animal update leaves empty fields alone", Animals{}.ValidateUpdate(), nil},
+		{"animal update with negative duration", Animals{ShowDuration: -1}.ValidateUpdate(), []string{"show_duration:min"}},
+
+		{"valid stage", PerformanceStage{RoomNumber: "A1", SeatCapacity: 50, PricePerSeat: 0}.Validate(), nil},
+		{"stage without capacity and negative price", PerformanceStage{RoomNumber: "A1", PricePerSeat: -1}.Validate(),
+			[]string{"seat_capacity:min", "price_per_seat:min"}},
+		{"stage update keeps capacity", PerformanceStage{}.ValidateUpdate(), nil},
+		{"stage update with negative capacity", PerformanceStage{SeatCapacity: -3}.ValidateUpdate(), []string{"seat_capacity:min"}},
+
+		{"valid show round", ShowRounds{AnimalId: "a", StageId: "s", ShowTime: "2025-01-01T09:00:00Z"}.Validate(), nil},
+		{"show round with malformed time", ShowRounds{AnimalId: "a", StageId: "s", ShowTime: "tomorrow"}.Validate(),
+			[]string{"show_time:format"}},
+		{"show round without references", ShowRounds{ShowTime: "2025-01-01T09:00:00+07:00"}.Validate(),
+			[]string{"animal_id:required", "stage_id:required"}},
+
+		{"valid booking", Bookings{UserId: "u", RoundId: "r", SeatNumber: 1, Status: BookingStatusConfirmed}.Validate(), nil},
+		{"booking without round", Bookings{UserId: "u", SeatNumber: 3}.Validate(), []string{"round_id:required"}},
+		{"booking with unknown status", Bookings{Status: "lost"}.ValidateUpdate(), []string{"status:oneof"}},
+		{"booking with seat zero", Bookings{UserId: "u", RoundId: "r"}.Validate(), []string{"seat_number:min"}},
+
+		{"user with unknown role", Users{Username: "ann", Password: "secret", Role: "root"}.Validate(), []string{"role:oneof"}},
+		{"registration without password", RegisterRequest{Username: "ann"}.Validate(), []string{"password:required"}},
+		{"registration with email", RegisterRequest{Username: "ann", Password: "secret", Email: "ann@example.com"}.Validate(), nil},
+		{"user update with malformed email", Users{Email: "Ann <ann@example.com>"}.ValidateUpdate(), []string{"email:format"}},
+		{"valid profile", Users{Phone: "+66812345678", DisplayName: "แอน", Language: "th"}.ValidateUpdate(), nil},
+		{"profile with local phone, unknown language and long name",
+			Users{Phone: "0812345678", Language: "fr", DisplayName: strings.Repeat("n", MaxDisplayNameLength+1)}.ValidateUpdate(),
+			[]string{"phone:format", "display_name:max_length", "language:oneof"}},
+		{"verification without code", VerifyContactRequest{Channel: ContactEmail}.Validate(), []string{"code:required"}},
+		{"verification on unknown channel", VerifyContactRequest{Channel: "fax", Code: "123456"}.Validate(), []string{"channel:oneof"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, fieldCodes(t, tt.err))
+		})
+	}
+}
+
+func TestNormalizeContact(t *testing.T) {
+	user := Users{Email: "  Ann@Example.COM ", Phone: "+66 (81) 234-5678"}
+
+	user.NormalizeContact()
+
+	assert.Equal(t, "ann@example.com", user.Email)
+	assert.Equal(t, "+66812345678", user.Phone)
+	assert.NoError(t, user.ValidateUpdate())
+}
+
+func TestContactVerified(t *testing.T) {
+	user := Users{Email: "ann@example.com", VerifiedEmail: "ann@example.com", Phone: "+66812345678", VerifiedPhone: "+66899999999"}
+
+	assert.True(t, user.ContactVerified(ContactEmail))
+	assert.False(t, user.ContactVerified(ContactPhone), "a new phone needs verifying again")
+	assert.False(t, Users{}.ContactVerified(ContactEmail), "no address is never verified")
+}
+
+func TestFieldErrorLocalize(t *testing.T) {
+	fieldErr := NewFieldError("seat_capacity", RuleMin, map[string]string{"min": "1"})
+
+	assert.Equal(t, "seat_capacity must be at least 1", fieldErr.Message)
+	assert.Equal(t, "seat_capacity ต้องมีค่าอย่างน้อย 1", fieldErr.Localize(LanguageThai).Message)
+	assert.Equal(t, "seat_capacity must be at least 1", fieldErr.Localize("fr").Message)
+	assert.Equal(t, "status ไม่ถูกต้อง", NewFieldError("status", "unknown_rule", nil).Localize(LanguageThai).Message)
+}
+
+func TestJoinValidation(t *testing.T) {
+	errDatabase := errors.New("database error")
+	name := &ValidationError{Fields: []FieldError{NewFieldError("name", RuleRequired, nil)}}
+	password := &ValidationError{Fields: []FieldError{NewFieldError("password", RuleMinLength, map[string]string{"min": "6"})}}
+
+	assert.NoError(t, JoinValidation(nil, nil))
+	assert.Equal(t, []string{"name:required", "password:min_length"}, fieldCodes(t, JoinValidation(name, nil, password)))
+	assert.Same(t, errDatabase, JoinValidation(name, errDatabase))
+}
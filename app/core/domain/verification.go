@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+// Channels an address of a user can be verified on
+const (
+	ContactEmail = "email"
+	ContactPhone = "phone"
+)
+
+// ContactVerification is a pending proof that a user owns their email or phone. The user is sent a short code;
+// only its hash is stored, and the verification is removed once the code is accepted, after too many wrong
+// codes, or when a new code is sent.
+type ContactVerification struct {
+	Key     string `json:"-" bson:"_id" gorm:"primaryKey;column:verification_key;type:string"`
+	UserId  string `json:"user_id" bson:"user_id" gorm:"column:user_id;index"`
+	Channel string `json:"channel" bson:"channel" gorm:"column:channel"`
+	// Target is the address the code was sent to. The code no longer counts once the user changes it.
+	Target    string    `json:"target" bson:"target" gorm:"column:target"`
+	CodeHash  string    `json:"-" bson:"code_hash" gorm:"column:code_hash"`
+	Attempts  int       `json:"attempts" bson:"attempts" gorm:"column:attempts;not null;default:0"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at" gorm:"column:expires_at"`
+	Audit     `bson:",inline"`
+}
+
+// VerificationKey is the key of the pending verification of a user on channel; a user has at most one per channel
+func VerificationKey(userId string, channel string) string {
+	return userId + ":" + channel
+}
+
+// Expired reports whether the code can no longer be used at now
+func (v ContactVerification) Expired(now time.Time) bool {
+	return !now.Before(v.ExpiresAt)
+}
+
+// ProfileUpdate is a change a user makes to their own profile; fields left empty, and a nil MarketingConsent,
+// keep their value
+type ProfileUpdate struct {
+	DisplayName      string
+	Email            string
+	Phone            string
+	Language         string
+	MarketingConsent *bool
+	Version          int64
+}
+
+// ToUser returns the update as a partial user with its email and phone normalized
+func (p ProfileUpdate) ToUser() *Users {
+	user := &Users{
+		DisplayName:      p.DisplayName,
+		Email:            p.Email,
+		Phone:            p.Phone,
+		Language:         p.Language,
+		MarketingConsent: p.MarketingConsent,
+		Version:          p.Version,
+	}
+	user.NormalizeContact()
+	return user
+}
+
+// VerifyContactRequest proves ownership of an address with the code sent to it
+type VerifyContactRequest struct {
+	Channel string
+	Code    string
+}
+
+func (r VerifyContactRequest) Validate() error {
+	var v Validation
+	v.Required("channel", r.Channel)
+	v.OneOf("channel", r.Channel, ContactEmail, ContactPhone)
+	v.Required("code", r.Code)
+	return v.Err()
+}
@@ -7,7 +7,7 @@ import (
 )
 
 type AnimalsRepository interface {
-	GetAnimals(ctx context.Context) ([]domain.Animals, error)
+	GetAnimals(ctx context.Context, filter AnimalFilter, query ListQuery) (*Page[domain.Animals], error)
 	CreateAnimal(ctx context.Context, animal *domain.Animals) (*domain.Animals, error)
 	GetAnimalById(ctx context.Context, id string) (*domain.Animals, error)
 	UpdateAnimal(ctx context.Context, id string, animal *domain.Animals) (*domain.Animals, error)
@@ -15,9 +15,9 @@ type AnimalsRepository interface {
 }
 
 type AnimalsService interface {
-	GetAnimals(ctx context.Context) ([]domain.Animals, error)
+	GetAnimals(ctx context.Context, filter AnimalFilter, query ListQuery) (*Page[domain.Animals], error)
 	CreateAnimal(ctx context.Context, animal *domain.Animals) (*domain.Animals, error)
 	GetAnimalById(ctx context.Context, id string) (*domain.Animals, error)
 	UpdateAnimal(ctx context.Context, id string, animal *domain.Animals) (*domain.Animals, error)
-	DeleteAnimal(ctx context.Context, id string) error
+	DeleteAnimal(ctx context.Context, id string, opts DeleteOptions) error
 }
@@ -0,0 +1,46 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// ApiKeyRepository stores machine clients and the API keys issued to them
+type ApiKeyRepository interface {
+	// CreateApiClient stores a client; it returns ErrAlreadyExists when the name is taken
+	CreateApiClient(ctx context.Context, client *domain.ApiClient) (*domain.ApiClient, error)
+	GetApiClientById(ctx context.Context, id string) (*domain.ApiClient, error)
+	// ListApiClients returns every client, ordered by name
+	ListApiClients(ctx context.Context) ([]domain.ApiClient, error)
+
+	CreateApiKey(ctx context.Context, key *domain.ApiKey) (*domain.ApiKey, error)
+	GetApiKeyById(ctx context.Context, id string) (*domain.ApiKey, error)
+	GetApiKeyByHash(ctx context.Context, keyHash string) (*domain.ApiKey, error)
+	// ListApiKeys returns the keys of a client, revoked and expired ones included, oldest first
+	ListApiKeys(ctx context.Context, clientId string) ([]domain.ApiKey, error)
+	// ExpireApiKey brings the expiry of a key forward to expiresAt, leaving keys that expire earlier alone.
+	// It returns ErrNotFound when there is no key with the id.
+	ExpireApiKey(ctx context.Context, id string, expiresAt time.Time) error
+	// RevokeApiKey revokes a key at revokedAt; it returns ErrNotFound when there is no key with the id that is not revoked yet
+	RevokeApiKey(ctx context.Context, id string, revokedAt time.Time) error
+	// TouchApiKey records that a key was used at usedAt. It does not stamp the key as updated.
+	TouchApiKey(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// ApiKeyService lets admins register machine clients and issue, rotate and revoke their keys, and authenticates
+// requests made with a key
+type ApiKeyService interface {
+	CreateClient(ctx context.Context, req *domain.ApiClientRequest) (*domain.ApiClient, error)
+	ListClients(ctx context.Context) ([]domain.ApiClient, error)
+	ListKeys(ctx context.Context, clientId string) ([]domain.ApiKey, error)
+	IssueKey(ctx context.Context, clientId string, req *domain.ApiKeyRequest) (*domain.IssuedApiKey, error)
+	// RotateKey issues a successor with the scopes of a key, and lets the old key expire after a grace period
+	// so that its client can switch over
+	RotateKey(ctx context.Context, id string) (*domain.IssuedApiKey, error)
+	RevokeKey(ctx context.Context, id string) error
+	// Authenticate returns the key a request was made with, and records that it was used. It returns
+	// ErrApiKeyInvalid when the key is unknown, expired or revoked.
+	Authenticate(ctx context.Context, key string) (*domain.ApiKey, error)
+}
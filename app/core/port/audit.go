@@ -0,0 +1,65 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// actorKey is the context key of the id of the user making the request
+type actorKey struct{}
+
+// WithActor returns a copy of ctx that records actor as the user making the changes
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the user recorded by WithActor, or domain.AnonymousActor when there is none
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return domain.AnonymousActor
+}
+
+// AuditTime is the current time as audit stamps store it: in UTC and truncated to milliseconds,
+// the precision MongoDB keeps, so that every backend returns the same value
+func AuditTime() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// NewAudit stamps an entity being created by the actor of ctx
+func NewAudit(ctx context.Context) domain.Audit {
+	now, actor := AuditTime(), ActorFromContext(ctx)
+	return domain.Audit{CreatedAt: now, UpdatedAt: now, CreatedBy: actor, UpdatedBy: actor}
+}
+
+// UpdateAudit stamps a change made by the actor of ctx. The creation stamp is left zero,
+// so partial updates that skip zero fields keep the stored one.
+func UpdateAudit(ctx context.Context) domain.Audit {
+	return domain.Audit{UpdatedAt: AuditTime(), UpdatedBy: ActorFromContext(ctx)}
+}
+
+// AuditFilter narrows a list to the entities created or last updated within a time range, or by a user.
+// Both bounds are inclusive.
+type AuditFilter struct {
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	CreatedBy   string
+	UpdatedBy   string
+}
+
+// Matches reports whether an entity with the given audit stamps passes the filter
+func (f AuditFilter) Matches(audit domain.Audit) bool {
+	return inRange(audit.CreatedAt, f.CreatedFrom, f.CreatedTo) &&
+		inRange(audit.UpdatedAt, f.UpdatedFrom, f.UpdatedTo) &&
+		(f.CreatedBy == "" || audit.CreatedBy == f.CreatedBy) &&
+		(f.UpdatedBy == "" || audit.UpdatedBy == f.UpdatedBy)
+}
+
+func inRange(t time.Time, from, to *time.Time) bool {
+	return (from == nil || !t.Before(*from)) && (to == nil || !t.After(*to))
+}
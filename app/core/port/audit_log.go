@@ -0,0 +1,77 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// requestMetaKey is the context key of the RequestMeta of the request being served
+type requestMetaKey struct{}
+
+// RequestMeta identifies the HTTP request a change is made in
+type RequestMeta struct {
+	IP        string
+	RequestId string
+}
+
+// WithRequestMeta returns a copy of ctx that records the request it serves
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFromContext returns the request recorded by WithRequestMeta, or a zero RequestMeta outside of a request
+func RequestMetaFromContext(ctx context.Context) RequestMeta {
+	meta, _ := ctx.Value(requestMetaKey{}).(RequestMeta)
+	return meta
+}
+
+// NewAuditLogEntry records an action of the actor of ctx on an entity, stamped with the current time and the
+// request of ctx. before and after hold the fields the action changed; either may be nil.
+func NewAuditLogEntry(ctx context.Context, action string, entityType string, entityId string, before, after map[string]any) *domain.AuditLogEntry {
+	meta := RequestMetaFromContext(ctx)
+	return &domain.AuditLogEntry{
+		OccurredAt: AuditTime(),
+		Actor:      ActorFromContext(ctx),
+		Action:     action,
+		EntityType: entityType,
+		EntityId:   entityId,
+		Before:     before,
+		After:      after,
+		IP:         meta.IP,
+		RequestId:  meta.RequestId,
+	}
+}
+
+// AuditLogFilter narrows ListAuditLog. Both bounds of the time range are inclusive.
+type AuditLogFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityId   string
+	From       *time.Time
+	To         *time.Time
+}
+
+// Matches reports whether an entry passes the filter
+func (f AuditLogFilter) Matches(entry domain.AuditLogEntry) bool {
+	return (f.Actor == "" || entry.Actor == f.Actor) &&
+		(f.Action == "" || entry.Action == f.Action) &&
+		(f.EntityType == "" || entry.EntityType == f.EntityType) &&
+		(f.EntityId == "" || entry.EntityId == f.EntityId) &&
+		inRange(entry.OccurredAt, f.From, f.To)
+}
+
+// AuditLogRepository stores the audit log. It is append-only: entries can be added and read but never changed
+// or removed. Appending inside a unit of work commits or rolls back the entry together with the change it records.
+// ListAuditLog can be sorted by entry_id, occurred_at, actor, action and entity_type.
+type AuditLogRepository interface {
+	// Append assigns the entry an id and stores it
+	Append(ctx context.Context, entry *domain.AuditLogEntry) error
+	ListAuditLog(ctx context.Context, filter AuditLogFilter, query ListQuery) (*Page[domain.AuditLogEntry], error)
+}
+
+type AuditLogService interface {
+	ListAuditLog(ctx context.Context, filter AuditLogFilter, query ListQuery) (*Page[domain.AuditLogEntry], error)
+}
@@ -0,0 +1,64 @@
+package port
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAuditLogEntry(t *testing.T) {
+	t.Run("stamps the actor and request of the context", func(t *testing.T) {
+		ctx := WithActor(context.Background(), "admin-1")
+		ctx = WithRequestMeta(ctx, RequestMeta{IP: "203.0.113.7", RequestId: "req-1"})
+		start := AuditTime()
+
+		entry := NewAuditLogEntry(ctx, domain.AuditActionRoleChanged, domain.AuditEntityUser, "user-1", map[string]any{"role": "user"}, map[string]any{"role": "admin"})
+
+		assert.Equal(t, "admin-1", entry.Actor)
+		assert.Equal(t, "203.0.113.7", entry.IP)
+		assert.Equal(t, "req-1", entry.RequestId)
+		assert.Equal(t, domain.AuditEntityUser, entry.EntityType)
+		assert.Equal(t, "user-1", entry.EntityId)
+		assert.Equal(t, map[string]any{"role": "admin"}, entry.After)
+		assert.False(t, entry.OccurredAt.Before(start))
+	})
+
+	t.Run("outside of a request", func(t *testing.T) {
+		entry := NewAuditLogEntry(context.Background(), domain.AuditActionLoginFailed, domain.AuditEntityUser, "", nil, nil)
+
+		assert.Equal(t, domain.AnonymousActor, entry.Actor)
+		assert.Empty(t, entry.IP)
+		assert.Empty(t, entry.RequestId)
+	})
+}
+
+func TestAuditLogFilterMatches(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	entry := domain.AuditLogEntry{OccurredAt: at, Actor: "admin-1", Action: domain.AuditActionStagePriceChanged, EntityType: domain.AuditEntityStage, EntityId: "stage-1"}
+	before, after := at.Add(-time.Hour), at.Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		filter AuditLogFilter
+		want   bool
+	}{
+		{"empty filter", AuditLogFilter{}, true},
+		{"actor", AuditLogFilter{Actor: "admin-1"}, true},
+		{"other actor", AuditLogFilter{Actor: "admin-2"}, false},
+		{"entity", AuditLogFilter{EntityType: domain.AuditEntityStage, EntityId: "stage-1"}, true},
+		{"other entity", AuditLogFilter{EntityType: domain.AuditEntityStage, EntityId: "stage-2"}, false},
+		{"action", AuditLogFilter{Action: domain.AuditActionLogin}, false},
+		{"inside range", AuditLogFilter{From: &before, To: &after}, true},
+		{"inclusive bounds", AuditLogFilter{From: &at, To: &at}, true},
+		{"after range", AuditLogFilter{To: &before}, false},
+		{"before range", AuditLogFilter{From: &after}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(entry))
+		})
+	}
+}
@@ -10,4 +10,14 @@ type AuthService interface {
 	Login(ctx context.Context, req *domain.LoginRequest) (*domain.AuthResponse, error)
 	Register(ctx context.Context, req *domain.RegisterRequest) (*domain.AuthResponse, error)
 	RefreshToken(ctx context.Context, req *domain.RefreshTokenRequest) (*domain.TokenPair, error)
+	// Authenticate returns the claims of an access token that is valid and neither revoked itself nor by a
+	// revocation of its session
+	Authenticate(ctx context.Context, accessToken string) (*domain.JWTClaims, error)
+	// Logout revokes the access token of claims, and the refresh token of req when given
+	Logout(ctx context.Context, claims *domain.JWTClaims, req *domain.LogoutRequest) error
+	// VerifyMfa completes a login that returned an MfaChallenge
+	VerifyMfa(ctx context.Context, req *domain.MfaLoginRequest) (*domain.AuthResponse, error)
+	// EnrollMfa starts the enrollment of a user whose role requires a second factor they have not set up,
+	// with the token of the MfaChallenge of their login
+	EnrollMfa(ctx context.Context, mfaToken string) (*domain.MfaSetup, error)
 }
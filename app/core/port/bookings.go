@@ -11,6 +11,7 @@ type BookingsRepository interface {
 	GetBookingById(context context.Context, id string) (*domain.Bookings, error)
 	GetBookingsByUserId(context context.Context, userId string) ([]domain.Bookings, error)
 	GetBookingsByRoundId(context context.Context, roundId string) ([]domain.Bookings, error)
+	ListBookings(context context.Context, filter BookingFilter, query ListQuery) (*Page[domain.Bookings], error)
 	UpdateBooking(context context.Context, id string, booking *domain.Bookings) (*domain.Bookings, error)
 	DeleteBooking(context context.Context, id string) error
 }
@@ -20,6 +21,7 @@ type BookingsService interface {
 	GetBookingById(context context.Context, id string) (*domain.Bookings, error)
 	GetBookingsByUserId(context context.Context, userId string) ([]domain.Bookings, error)
 	GetBookingsByRoundId(context context.Context, roundId string) ([]domain.Bookings, error)
+	ListBookings(context context.Context, filter BookingFilter, query ListQuery) (*Page[domain.Bookings], error)
 	UpdateBooking(context context.Context, id string, booking *domain.Bookings) (*domain.Bookings, error)
-	DeleteBooking(context context.Context, id string) error
+	DeleteBooking(context context.Context, id string, opts DeleteOptions) error
 }
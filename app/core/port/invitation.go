@@ -0,0 +1,21 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+type InvitationRepository interface {
+	CreateInvitation(ctx context.Context, invitation *domain.Invitation) (*domain.Invitation, error)
+	GetInvitationById(ctx context.Context, id string) (*domain.Invitation, error)
+	// AcceptInvitation marks a pending invitation as accepted by userId at acceptedAt. It returns ErrNotFound when
+	// there is no invitation with the id that is still pending, so that two concurrent accepts cannot both succeed.
+	AcceptInvitation(ctx context.Context, id string, userId string, acceptedAt time.Time) error
+}
+
+type InvitationService interface {
+	CreateInvitation(ctx context.Context, req *domain.InvitationRequest) (*domain.InvitationLink, error)
+	AcceptInvitation(ctx context.Context, req *domain.AcceptInvitationRequest) (*domain.AuthResponse, error)
+}
@@ -0,0 +1,30 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// LoginAttemptRepository keeps the failed login counters in the database, so that every replica sees the same counts
+type LoginAttemptRepository interface {
+	// GetLoginAttempts returns the counters of the given keys that exist, in no particular order
+	GetLoginAttempts(ctx context.Context, keys []string) ([]domain.LoginAttempt, error)
+	// RecordLoginFailure atomically counts a failure of key at at and returns the updated counter.
+	// A counter whose last failure is before resetBefore starts again from one.
+	RecordLoginFailure(ctx context.Context, key string, at time.Time, resetBefore time.Time) (*domain.LoginAttempt, error)
+	// LockLogin locks key out until the given time; it returns ErrNotFound when key has no counter
+	LockLogin(ctx context.Context, key string, until time.Time) error
+	// ClearLoginAttempts removes the counter of key, lifting any lockout; it returns ErrNotFound when there is none
+	ClearLoginAttempts(ctx context.Context, key string) error
+	// ListLoginLockouts returns the counters locked out beyond now, ordered by key
+	ListLoginLockouts(ctx context.Context, now time.Time) ([]domain.LoginAttempt, error)
+}
+
+// LoginLockoutService lets admins see and lift the lockouts of usernames and client IPs
+type LoginLockoutService interface {
+	ListLockouts(ctx context.Context) ([]domain.LoginAttempt, error)
+	// Unlock clears the failed logins of the username or IP value, kind being domain.LoginKeyUsername or domain.LoginKeyIP
+	Unlock(ctx context.Context, kind string, value string) error
+}
@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// Mailer delivers mail to users
+type Mailer interface {
+	Send(ctx context.Context, mail domain.Mail) error
+}
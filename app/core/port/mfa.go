@@ -0,0 +1,44 @@
+package port
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// MfaRepository stores the second factors of users and the roles that require one
+type MfaRepository interface {
+	GetMfaEnrollment(ctx context.Context, userId string) (*domain.MfaEnrollment, error)
+	// SaveMfaEnrollment creates or replaces the enrollment of its user, recovery codes included
+	SaveMfaEnrollment(ctx context.Context, enrollment *domain.MfaEnrollment) error
+	// DeleteMfaEnrollment removes the enrollment of a user; it returns ErrNotFound when there is none
+	DeleteMfaEnrollment(ctx context.Context, userId string) error
+	// UseMfaStep records that the code of a TOTP time step was accepted. It returns ErrNotFound when the user has no
+	// confirmed enrollment or a code of that step or a later one was accepted already, so that a code works only once.
+	UseMfaStep(ctx context.Context, userId string, step int64) error
+	// UseRecoveryCode removes the recovery code with the given hash; it returns ErrNotFound when the user has no such code
+	UseRecoveryCode(ctx context.Context, userId string, codeHash string) error
+
+	GetMfaRequirement(ctx context.Context, role string) (*domain.MfaRequirement, error)
+	// ListMfaRequirements returns the roles that require two-factor authentication, ordered by role
+	ListMfaRequirements(ctx context.Context) ([]domain.MfaRequirement, error)
+	// SaveMfaRequirement requires two-factor authentication for the role of requirement; saving it again changes nothing
+	SaveMfaRequirement(ctx context.Context, requirement *domain.MfaRequirement) error
+	// DeleteMfaRequirement stops requiring two-factor authentication for role; it returns ErrNotFound when it was not required
+	DeleteMfaRequirement(ctx context.Context, role string) error
+}
+
+// MfaService lets users manage their own second factor, identified by the actor of ctx, and admins decide
+// which roles require one
+type MfaService interface {
+	Enroll(ctx context.Context) (*domain.MfaSetup, error)
+	// Confirm puts the pending enrollment into effect with a first code and returns the recovery codes
+	Confirm(ctx context.Context, code string) ([]string, error)
+	// RenewRecoveryCodes replaces the recovery codes after checking a code
+	RenewRecoveryCodes(ctx context.Context, req *domain.MfaCodeRequest) ([]string, error)
+	Disable(ctx context.Context, req *domain.MfaCodeRequest) error
+	// Reset removes the second factor of another user, such as one who lost both their device and recovery codes
+	Reset(ctx context.Context, userId string) error
+	ListRequirements(ctx context.Context) ([]domain.MfaRequirement, error)
+	SetRequirement(ctx context.Context, req *domain.MfaRequirementRequest) error
+}
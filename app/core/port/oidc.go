@@ -0,0 +1,48 @@
+package port
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// OidcRepository stores the identities users sign in with at external providers, and the sign-ins waiting for
+// the browser to return from one
+type OidcRepository interface {
+	CreateOidcLogin(ctx context.Context, login *domain.OidcLogin) error
+	// TakeOidcLogin removes and returns the pending sign-in with stateHash. It returns ErrNotFound when there is none,
+	// so that a state completes one sign-in.
+	TakeOidcLogin(ctx context.Context, stateHash string) (*domain.OidcLogin, error)
+	// CreateOidcIdentity links an identity to a user; it returns ErrAlreadyExists when the identity is linked already
+	CreateOidcIdentity(ctx context.Context, identity *domain.OidcIdentity) (*domain.OidcIdentity, error)
+	GetOidcIdentity(ctx context.Context, provider string, subject string) (*domain.OidcIdentity, error)
+	// ListOidcIdentities returns the identities linked to a user, oldest first
+	ListOidcIdentities(ctx context.Context, userId string) ([]domain.OidcIdentity, error)
+}
+
+// OidcProvider is an external OpenID Connect provider users sign in with, using the authorization code flow with PKCE
+type OidcProvider interface {
+	// Name is what the provider is configured and addressed by, such as google
+	Name() string
+	// Role is the role of the users provisioned on their first sign-in with the provider
+	Role() string
+	// AuthorizationURL returns the URL of the provider that the browser is sent to, carrying state, nonce and the
+	// S256 challenge of the code verifier
+	AuthorizationURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error)
+	// Exchange redeems an authorization code with its code verifier, validates the ID token the provider answers
+	// with against its keys, client and nonce, and returns its claims. A rejected code or an ID token that does not
+	// check out is ErrOidcLoginInvalid.
+	Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*domain.OidcClaims, error)
+}
+
+// OidcService signs users in with external providers, provisioning a user on the first sign-in of an identity
+type OidcService interface {
+	// Authorize starts a sign-in with provider. When ctx carries a signed-in user, the identity is linked to them
+	// instead.
+	Authorize(ctx context.Context, provider string) (*domain.OidcAuthorization, error)
+	// Login completes a sign-in with the state and code the provider returned. Like a password login, users with
+	// a second factor get an MfaChallenge instead of the tokens.
+	Login(ctx context.Context, req *domain.OidcLoginRequest) (*domain.AuthResponse, error)
+	// ListIdentities returns the identities linked to the signed-in user
+	ListIdentities(ctx context.Context) ([]domain.OidcIdentity, error)
+}
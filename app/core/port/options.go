@@ -0,0 +1,10 @@
+package port
+
+// DeleteOptions controls how a service handles entities that still reference the one being deleted
+// and which version of the entity it may delete
+type DeleteOptions struct {
+	// Cascade deletes dependent entities instead of rejecting the delete
+	Cascade bool
+	// Version is the version of the entity the caller expects to delete; 0 deletes whatever version is current
+	Version int64
+}
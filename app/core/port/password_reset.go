@@ -0,0 +1,24 @@
+package port
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+type PasswordResetRepository interface {
+	CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error
+	// TakePasswordResetToken removes and returns the token with tokenHash. It returns ErrNotFound when there is none,
+	// so that two concurrent resets cannot both use the same token.
+	TakePasswordResetToken(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error)
+	// DeletePasswordResetTokens removes every pending reset of a user
+	DeletePasswordResetTokens(ctx context.Context, userId string) error
+}
+
+// PasswordResetService lets users who forgot their password choose a new one through a link mailed to them
+type PasswordResetService interface {
+	// ForgotPassword mails a reset link to the user. It answers alike whether the user exists or not.
+	ForgotPassword(ctx context.Context, req *domain.ForgotPasswordRequest) error
+	// ResetPassword sets a new password with the token of a reset link and revokes every session of the user
+	ResetPassword(ctx context.Context, req *domain.ResetPasswordRequest) error
+}
@@ -7,7 +7,7 @@ import (
 )
 
 type PerformanceStageRepository interface {
-	GetStages(ctx context.Context) ([]domain.PerformanceStage, error)
+	GetStages(ctx context.Context, filter StageFilter, query ListQuery) (*Page[domain.PerformanceStage], error)
 	CreateStage(ctx context.Context, stage *domain.PerformanceStage) (*domain.PerformanceStage, error)
 	GetStageById(ctx context.Context, id string) (*domain.PerformanceStage, error)
 	UpdateStage(ctx context.Context, id string, stage *domain.PerformanceStage) (*domain.PerformanceStage, error)
@@ -15,9 +15,9 @@ type PerformanceStageRepository interface {
 }
 
 type PerformanceStageService interface {
-	GetStages(ctx context.Context) ([]domain.PerformanceStage, error)
+	GetStages(ctx context.Context, filter StageFilter, query ListQuery) (*Page[domain.PerformanceStage], error)
 	CreateStage(ctx context.Context, stage *domain.PerformanceStage) (*domain.PerformanceStage, error)
 	GetStageById(ctx context.Context, id string) (*domain.PerformanceStage, error)
 	UpdateStage(ctx context.Context, id string, stage *domain.PerformanceStage) (*domain.PerformanceStage, error)
-	DeleteStage(ctx context.Context, id string) error
+	DeleteStage(ctx context.Context, id string, opts DeleteOptions) error
 }
@@ -0,0 +1,162 @@
+package port
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+const (
+	// DefaultPageLimit is used when a list query does not specify a limit
+	DefaultPageLimit = 20
+	// MaxPageLimit caps the number of items a single page may return
+	MaxPageLimit = 100
+
+	cursorPrefix = "offset:"
+)
+
+// SortField is one key of a list ordering, e.g. "-show_time" parses to {Field: "show_time", Desc: true}
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListQuery is the paging and ordering spec shared by every list call.
+// A cursor, when present, takes precedence over Offset.
+type ListQuery struct {
+	Limit  int
+	Offset int
+	Cursor string
+	Sort   []SortField
+}
+
+// Page is one page of list results together with its paging metadata
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// AnimalFilter narrows GetAnimals
+type AnimalFilter struct {
+	Species string
+	Type    string
+	Audit   AuditFilter
+}
+
+// StageFilter narrows GetStages
+type StageFilter struct {
+	RoomNumber      string
+	MinSeatCapacity int
+	Audit           AuditFilter
+}
+
+// ShowRoundFilter narrows GetAllShowRounds. Species matches the species of the round's animal.
+type ShowRoundFilter struct {
+	AnimalId string
+	StageId  string
+	Species  string
+	From     *time.Time
+	To       *time.Time
+	Audit    AuditFilter
+}
+
+// BookingFilter narrows ListBookings
+type BookingFilter struct {
+	UserId  string
+	RoundId string
+	Status  string
+	Audit   AuditFilter
+}
+
+// ParseSort parses a comma separated sort expression such as "name,-show_duration"
+func ParseSort(expr string) []SortField {
+	var fields []SortField
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if strings.HasPrefix(part, "-") {
+			fields = append(fields, SortField{Field: part[1:], Desc: true})
+			continue
+		}
+		fields = append(fields, SortField{Field: strings.TrimPrefix(part, "+")})
+	}
+	return fields
+}
+
+// Normalize applies the default and maximum limit and resolves the cursor into an offset
+func (q ListQuery) Normalize() (ListQuery, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageLimit
+	}
+	if q.Limit > MaxPageLimit {
+		q.Limit = MaxPageLimit
+	}
+	if q.Offset < 0 {
+		return q, fmt.Errorf("%w: offset must not be negative", domain.ErrInvalidQuery)
+	}
+	if q.Cursor != "" {
+		offset, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return q, err
+		}
+		q.Offset = offset
+	}
+	return q, nil
+}
+
+// SortColumns resolves the requested sort fields to storage columns using the given whitelist.
+// The returned ordering always ends with the tie breaker so that pages are stable.
+func (q ListQuery) SortColumns(columns map[string]string, tieBreaker string) ([]SortField, error) {
+	var resolved []SortField
+	hasTieBreaker := false
+	for _, field := range q.Sort {
+		column, ok := columns[field.Field]
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot sort by %q", domain.ErrInvalidQuery, field.Field)
+		}
+		if column == tieBreaker {
+			hasTieBreaker = true
+		}
+		resolved = append(resolved, SortField{Field: column, Desc: field.Desc})
+	}
+	if !hasTieBreaker {
+		resolved = append(resolved, SortField{Field: tieBreaker})
+	}
+	return resolved, nil
+}
+
+// NewPage builds a page for a normalized query, computing the cursor of the following page
+func NewPage[T any](items []T, total int64, q ListQuery) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	page := &Page[T]{Items: items, Total: total}
+	next := q.Offset + len(items)
+	if len(items) > 0 && int64(next) < total {
+		page.NextCursor = encodeCursor(next)
+	}
+	return page
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidQuery)
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidQuery)
+	}
+	return offset, nil
+}
@@ -0,0 +1,101 @@
+package port
+
+import (
+	"testing"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSort(t *testing.T) {
+	assert.Equal(t, []SortField{
+		{Field: "name"},
+		{Field: "show_duration", Desc: true},
+		{Field: "species"},
+	}, ParseSort("name, -show_duration,,+species"))
+	assert.Nil(t, ParseSort(""))
+}
+
+func TestListQueryNormalize(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		query, err := ListQuery{}.Normalize()
+
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultPageLimit, query.Limit)
+		assert.Equal(t, 0, query.Offset)
+	})
+
+	t.Run("caps limit", func(t *testing.T) {
+		query, err := ListQuery{Limit: 1000}.Normalize()
+
+		assert.NoError(t, err)
+		assert.Equal(t, MaxPageLimit, query.Limit)
+	})
+
+	t.Run("cursor overrides offset", func(t *testing.T) {
+		query, err := ListQuery{Offset: 3, Cursor: encodeCursor(40)}.Normalize()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 40, query.Offset)
+	})
+
+	t.Run("malformed cursor", func(t *testing.T) {
+		_, err := ListQuery{Cursor: "not-a-cursor"}.Normalize()
+
+		assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+	})
+
+	t.Run("negative offset", func(t *testing.T) {
+		_, err := ListQuery{Offset: -1}.Normalize()
+
+		assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+	})
+}
+
+func TestListQuerySortColumns(t *testing.T) {
+	columns := map[string]string{"animal_id": "_id", "name": "name"}
+
+	t.Run("appends tie breaker", func(t *testing.T) {
+		order, err := ListQuery{Sort: []SortField{{Field: "name", Desc: true}}}.SortColumns(columns, "_id")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []SortField{{Field: "name", Desc: true}, {Field: "_id"}}, order)
+	})
+
+	t.Run("keeps explicit tie breaker", func(t *testing.T) {
+		order, err := ListQuery{Sort: []SortField{{Field: "animal_id", Desc: true}}}.SortColumns(columns, "_id")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []SortField{{Field: "_id", Desc: true}}, order)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := ListQuery{Sort: []SortField{{Field: "password"}}}.SortColumns(columns, "_id")
+
+		assert.ErrorIs(t, err, domain.ErrInvalidQuery)
+	})
+}
+
+func TestNewPage(t *testing.T) {
+	t.Run("more pages", func(t *testing.T) {
+		page := NewPage([]int{1, 2}, 5, ListQuery{Limit: 2, Offset: 2})
+
+		assert.Equal(t, int64(5), page.Total)
+		offset, err := decodeCursor(page.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, offset)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		page := NewPage([]int{5}, 5, ListQuery{Limit: 2, Offset: 4})
+
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("empty page", func(t *testing.T) {
+		page := NewPage[int](nil, 0, ListQuery{Limit: 2})
+
+		assert.NotNil(t, page.Items)
+		assert.Empty(t, page.NextCursor)
+	})
+}
@@ -0,0 +1,21 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// SessionRepository records when the sessions of users were revoked, and which single tokens were
+type SessionRepository interface {
+	// RevokeSessions revokes every token issued to a user up to at
+	RevokeSessions(ctx context.Context, userId string, at time.Time) error
+	// GetSessionRevocation returns the latest revocation of the sessions of a user, or ErrNotFound when there was none
+	GetSessionRevocation(ctx context.Context, userId string) (*domain.SessionRevocation, error)
+	// RevokeToken adds a token to the denylist; revoking it again is not an error. Entries whose token has
+	// expired are dropped on the way, keeping the list as short as the tokens live.
+	RevokeToken(ctx context.Context, token *domain.RevokedToken) error
+	// IsTokenRevoked reports whether the token with tokenId is on the denylist
+	IsTokenRevoked(ctx context.Context, tokenId string) (bool, error)
+}
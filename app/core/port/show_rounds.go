@@ -9,7 +9,9 @@ import (
 type ShowRoundsRepository interface {
 	CreateShowRound(ctx context.Context, showRound *domain.ShowRounds) (*domain.ShowRounds, error)
 	GetShowRoundById(ctx context.Context, id string) (*domain.ShowRounds, error)
-	GetAllShowRounds(ctx context.Context) ([]*domain.ShowRounds, error)
+	GetAllShowRounds(ctx context.Context, filter ShowRoundFilter, query ListQuery) (*Page[*domain.ShowRounds], error)
+	GetShowRoundsByAnimalId(ctx context.Context, animalId string) ([]*domain.ShowRounds, error)
+	GetShowRoundsByStageId(ctx context.Context, stageId string) ([]*domain.ShowRounds, error)
 	UpdateShowRound(ctx context.Context, id string, showRound *domain.ShowRounds) (*domain.ShowRounds, error)
 	DeleteShowRound(ctx context.Context, id string) error
 }
@@ -17,7 +19,7 @@ type ShowRoundsRepository interface {
 type ShowRoundsService interface {
 	CreateShowRound(ctx context.Context, showRound *domain.ShowRounds) (*domain.ShowRounds, error)
 	GetShowRoundById(ctx context.Context, id string) (*domain.ShowRounds, error)
-	GetAllShowRounds(ctx context.Context) ([]*domain.ShowRounds, error)
+	GetAllShowRounds(ctx context.Context, filter ShowRoundFilter, query ListQuery) (*Page[*domain.ShowRounds], error)
 	UpdateShowRound(ctx context.Context, id string, showRound *domain.ShowRounds) (*domain.ShowRounds, error)
-	DeleteShowRound(ctx context.Context, id string) error
+	DeleteShowRound(ctx context.Context, id string, opts DeleteOptions) error
 }
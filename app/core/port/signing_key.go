@@ -0,0 +1,28 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// SigningKeyRepository stores the keys tokens are signed with, shared by every instance of the app
+type SigningKeyRepository interface {
+	CreateSigningKey(ctx context.Context, key *domain.SigningKey) error
+	// ListSigningKeys returns every stored key ordered by ActivatesAt, then Kid
+	ListSigningKeys(ctx context.Context) ([]domain.SigningKey, error)
+	// UpdateSigningKeyPrivateKey replaces the stored private key of the key with kid
+	UpdateSigningKeyPrivateKey(ctx context.Context, kid string, privateKey string) error
+	// DeleteExpiredSigningKeys removes the keys that expired at or before now and returns how many there were
+	DeleteExpiredSigningKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+// SigningKeyService rotates the signing keys and publishes their public halves
+type SigningKeyService interface {
+	// Rotate loads the stored keys, creates the next key when the current one is about to retire, and drops
+	// expired ones
+	Rotate(ctx context.Context) error
+	// PublicKeys returns the keys tokens may currently be signed with, including the next one
+	PublicKeys() []domain.JSONWebKey
+}
@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// SmsSender delivers text messages to users
+type SmsSender interface {
+	SendSms(ctx context.Context, sms domain.Sms) error
+}
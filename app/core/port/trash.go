@@ -0,0 +1,82 @@
+package port
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// TrashKind names a collection whose soft-deleted entities can be listed, restored and purged
+type TrashKind string
+
+const (
+	TrashUsers      TrashKind = "users"
+	TrashAnimals    TrashKind = "animals"
+	TrashStages     TrashKind = "performance_stages"
+	TrashShowRounds TrashKind = "show_rounds"
+	TrashBookings   TrashKind = "bookings"
+)
+
+// TrashKinds lists every kind with the dependent ones first, the order in which expired entities are purged
+var TrashKinds = []TrashKind{TrashBookings, TrashShowRounds, TrashUsers, TrashAnimals, TrashStages}
+
+// ParseTrashKind validates a kind taken from a request
+func ParseTrashKind(raw string) (TrashKind, error) {
+	for _, kind := range TrashKinds {
+		if string(kind) == raw {
+			return kind, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown trash kind %q", domain.ErrInvalidQuery, raw)
+}
+
+// TrashFilter narrows ListTrash; DeletedBefore keeps only the entities deleted before that instant
+type TrashFilter struct {
+	DeletedBefore *time.Time
+}
+
+// TrashItem is a soft-deleted entity; Entity holds the *domain struct of its kind as it was when deleted
+type TrashItem struct {
+	Kind      TrashKind `json:"kind" example:"animals"`
+	Id        string    `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+	Entity    any       `json:"entity"`
+}
+
+// Trashable is implemented by every domain entity that can be soft-deleted
+type Trashable interface {
+	GetId() string
+	GetDeletedAt() *time.Time
+}
+
+// NewTrashItem wraps a soft-deleted entity of the given kind
+func NewTrashItem(kind TrashKind, entity Trashable) TrashItem {
+	item := TrashItem{Kind: kind, Id: entity.GetId(), Entity: entity}
+	if deletedAt := entity.GetDeletedAt(); deletedAt != nil {
+		item.DeletedAt = *deletedAt
+	}
+	return item
+}
+
+// TrashRepository gives access to the entities the other repositories soft-deleted and hide from their queries.
+// ListTrash can be sorted by id and deleted_at.
+type TrashRepository interface {
+	ListTrash(ctx context.Context, kind TrashKind, filter TrashFilter, query ListQuery) (*Page[TrashItem], error)
+	GetTrashItem(ctx context.Context, kind TrashKind, id string) (*TrashItem, error)
+	// Restore makes a soft-deleted entity visible again and bumps its version
+	Restore(ctx context.Context, kind TrashKind, id string) error
+	// Purge permanently removes a soft-deleted entity together with the soft-deleted entities that reference it
+	Purge(ctx context.Context, kind TrashKind, id string) error
+}
+
+type TrashService interface {
+	ListTrash(ctx context.Context, kind TrashKind, query ListQuery) (*Page[TrashItem], error)
+	// Restore brings a soft-deleted entity back once the entities it references are live again
+	Restore(ctx context.Context, kind TrashKind, id string) error
+	// Purge permanently removes a soft-deleted entity that no live entity references
+	Purge(ctx context.Context, kind TrashKind, id string) error
+	// PurgeExpired purges every entity deleted longer ago than the retention period and returns how many trash items it purged
+	PurgeExpired(ctx context.Context) (int, error)
+}
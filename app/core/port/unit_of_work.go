@@ -0,0 +1,10 @@
+package port
+
+import "context"
+
+// UnitOfWork groups repository calls so that they either all take effect or none does.
+// Repositories called with the context handed to fn take part in the unit of work;
+// calls made with any other context run on their own.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
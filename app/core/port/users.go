@@ -7,6 +7,7 @@ import (
 )
 
 type UsersRepository interface {
+	// CreateUser stores a new user under the id it carries, or under a generated one when it has none
 	CreateUser(context context.Context, user *domain.Users) (*domain.Users, error)
 	GetUserById(context context.Context, id string) (*domain.Users, error)
 	GetUsersByRole(context context.Context, role string) ([]domain.Users, error)
@@ -17,8 +18,10 @@ type UsersRepository interface {
 
 type UsersService interface {
 	Register(context context.Context, user *domain.Users) (*domain.Users, error)
+	BootstrapAdmin(context context.Context, user *domain.Users) (*domain.Users, error)
 	GetUserById(context context.Context, id string) (*domain.Users, error)
 	GetUsersByRole(context context.Context, role string) ([]domain.Users, error)
-	UpdateUser(context context.Context, id string, user *domain.Users) (*domain.Users, error)
-	DeleteUser(context context.Context, id string) error
+	// UpdateUser changes a user; currentPassword is required when users change their own password
+	UpdateUser(context context.Context, id string, user *domain.Users, currentPassword string) (*domain.Users, error)
+	DeleteUser(context context.Context, id string, opts DeleteOptions) error
 }
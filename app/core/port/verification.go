@@ -0,0 +1,28 @@
+package port
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// VerificationRepository stores the pending contact verifications, at most one per user and channel
+type VerificationRepository interface {
+	// SaveVerification creates or replaces the verification with the key of verification
+	SaveVerification(ctx context.Context, verification *domain.ContactVerification) error
+	GetVerification(ctx context.Context, key string) (*domain.ContactVerification, error)
+	// DeleteVerification removes the verification with key. It returns ErrNotFound when there is none,
+	// so that two concurrent confirmations cannot both use the same code.
+	DeleteVerification(ctx context.Context, key string) error
+}
+
+// ProfileService lets users manage their own profile, identified by the actor of ctx, and prove they own
+// their email and phone
+type ProfileService interface {
+	GetProfile(ctx context.Context) (*domain.Users, error)
+	UpdateProfile(ctx context.Context, update *domain.ProfileUpdate) (*domain.Users, error)
+	// SendVerification sends a code to the current address of the user on channel, replacing the one sent before
+	SendVerification(ctx context.Context, channel string) (*domain.ContactVerification, error)
+	// VerifyContact marks the address as verified when the code sent to it is right
+	VerifyContact(ctx context.Context, req *domain.VerifyContactRequest) (*domain.Users, error)
+}
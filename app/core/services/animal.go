@@ -8,20 +8,32 @@ import (
 )
 
 type AnimalService struct {
-	animalRepository port.AnimalsRepository
+	animalRepository    port.AnimalsRepository
+	showRoundRepository port.ShowRoundsRepository
+	bookingsRepository  port.BookingsRepository
+	auditLog            port.AuditLogRepository
+	unitOfWork          port.UnitOfWork
 }
 
-func NewAnimalService(animalRepository port.AnimalsRepository) *AnimalService {
+func NewAnimalService(animalRepository port.AnimalsRepository, showRoundRepository port.ShowRoundsRepository, bookingsRepository port.BookingsRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork) *AnimalService {
 	return &AnimalService{
-		animalRepository: animalRepository,
+		animalRepository:    animalRepository,
+		showRoundRepository: showRoundRepository,
+		bookingsRepository:  bookingsRepository,
+		auditLog:            auditLog,
+		unitOfWork:          unitOfWork,
 	}
 }
 
-func (s *AnimalService) GetAnimals(ctx context.Context) ([]domain.Animals, error) {
-	return s.animalRepository.GetAnimals(ctx)
+func (s *AnimalService) GetAnimals(ctx context.Context, filter port.AnimalFilter, query port.ListQuery) (*port.Page[domain.Animals], error) {
+	return s.animalRepository.GetAnimals(ctx, filter, query)
 }
 
 func (s *AnimalService) CreateAnimal(ctx context.Context, animal *domain.Animals) (*domain.Animals, error) {
+	if err := animal.Validate(); err != nil {
+		return nil, err
+	}
+
 	return s.animalRepository.CreateAnimal(ctx, animal)
 }
 
@@ -30,9 +42,34 @@ func (s *AnimalService) GetAnimalById(ctx context.Context, id string) (*domain.A
 }
 
 func (s *AnimalService) UpdateAnimal(ctx context.Context, id string, animal *domain.Animals) (*domain.Animals, error) {
+	if err := animal.ValidateUpdate(); err != nil {
+		return nil, err
+	}
+
 	return s.animalRepository.UpdateAnimal(ctx, id, animal)
 }
 
-func (s *AnimalService) DeleteAnimal(ctx context.Context, id string) error {
-	return s.animalRepository.DeleteAnimal(ctx, id)
+// DeleteAnimal deletes an animal, refusing when show rounds still feature it unless cascade is requested
+func (s *AnimalService) DeleteAnimal(ctx context.Context, id string, opts port.DeleteOptions) error {
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := checkDeleteVersion(ctx, id, opts, s.animalRepository.GetAnimalById); err != nil {
+			return err
+		}
+
+		showRounds, err := s.showRoundRepository.GetShowRoundsByAnimalId(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if len(showRounds) > 0 {
+			if !opts.Cascade {
+				return referenceInUse("animal", id, len(showRounds), "show round(s)")
+			}
+			if err := deleteShowRoundsCascade(ctx, s.showRoundRepository, s.bookingsRepository, s.auditLog, showRounds); err != nil {
+				return err
+			}
+		}
+
+		return s.animalRepository.DeleteAnimal(ctx, id)
+	})
 }
@@ -6,21 +6,35 @@ import (
 	"testing"
 
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// stubUnitOfWork runs fn directly with the caller's context so mock expectations on ctx still match
+type stubUnitOfWork struct {
+	// commitErr is returned after fn succeeds, simulating a failed commit
+	commitErr error
+}
+
+func (u stubUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	return u.commitErr
+}
+
 // MockAnimalsRepository is a mock of AnimalsRepository interface
 type MockAnimalsRepository struct {
 	mock.Mock
 }
 
-func (m *MockAnimalsRepository) GetAnimals(ctx context.Context) ([]domain.Animals, error) {
-	args := m.Called(ctx)
+func (m *MockAnimalsRepository) GetAnimals(ctx context.Context, filter port.AnimalFilter, query port.ListQuery) (*port.Page[domain.Animals], error) {
+	args := m.Called(ctx, filter, query)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]domain.Animals), args.Error(1)
+	return args.Get(0).(*port.Page[domain.Animals]), args.Error(1)
 }
 
 func (m *MockAnimalsRepository) CreateAnimal(ctx context.Context, animal *domain.Animals) (*domain.Animals, error) {
@@ -54,18 +68,23 @@ func (m *MockAnimalsRepository) DeleteAnimal(ctx context.Context, id string) err
 
 func TestGetAnimals(t *testing.T) {
 	mockRepo := new(MockAnimalsRepository)
-	animalService := NewAnimalService(mockRepo)
+	animalService := NewAnimalService(mockRepo, new(MockShowRoundsRepository), new(MockBookingsRepository), new(MockAuditLogRepository), stubUnitOfWork{})
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
-		expectedAnimals := []domain.Animals{
-			{Id: "1", Name: "Lion"},
-			{Id: "2", Name: "Tiger"},
+		filter := port.AnimalFilter{Species: "Panthera leo"}
+		query := port.ListQuery{Limit: 2}
+		expectedAnimals := &port.Page[domain.Animals]{
+			Items: []domain.Animals{
+				{Id: "1", Name: "Lion"},
+				{Id: "2", Name: "Tiger"},
+			},
+			Total: 2,
 		}
 
-		mockRepo.On("GetAnimals", ctx).Return(expectedAnimals, nil).Once()
+		mockRepo.On("GetAnimals", ctx, filter, query).Return(expectedAnimals, nil).Once()
 
-		result, err := animalService.GetAnimals(ctx)
+		result, err := animalService.GetAnimals(ctx, filter, query)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedAnimals, result)
@@ -75,9 +94,9 @@ func TestGetAnimals(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		expectedErr := errors.New("database error")
 
-		mockRepo.On("GetAnimals", ctx).Return(nil, expectedErr).Once()
+		mockRepo.On("GetAnimals", ctx, port.AnimalFilter{}, port.ListQuery{}).Return(nil, expectedErr).Once()
 
-		result, err := animalService.GetAnimals(ctx)
+		result, err := animalService.GetAnimals(ctx, port.AnimalFilter{}, port.ListQuery{})
 
 		assert.Error(t, err)
 		assert.Equal(t, expectedErr, err)
@@ -88,13 +107,14 @@ func TestGetAnimals(t *testing.T) {
 
 func TestCreateAnimal(t *testing.T) {
 	mockRepo := new(MockAnimalsRepository)
-	animalService := NewAnimalService(mockRepo)
+	animalService := NewAnimalService(mockRepo, new(MockShowRoundsRepository), new(MockBookingsRepository), new(MockAuditLogRepository), stubUnitOfWork{})
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
 		animal := &domain.Animals{
-			Id:   "1",
-			Name: "Lion",
+			Id:      "1",
+			Name:    "Lion",
+			Species: "lion",
 		}
 
 		mockRepo.On("CreateAnimal", ctx, animal).Return(animal, nil).Once()
@@ -108,8 +128,9 @@ func TestCreateAnimal(t *testing.T) {
 
 	t.Run("error", func(t *testing.T) {
 		animal := &domain.Animals{
-			Id:   "1",
-			Name: "Lion",
+			Id:      "1",
+			Name:    "Lion",
+			Species: "lion",
 		}
 
 		expectedErr := errors.New("database error")
@@ -126,7 +147,7 @@ func TestCreateAnimal(t *testing.T) {
 
 func TestGetAnimalById(t *testing.T) {
 	mockRepo := new(MockAnimalsRepository)
-	animalService := NewAnimalService(mockRepo)
+	animalService := NewAnimalService(mockRepo, new(MockShowRoundsRepository), new(MockBookingsRepository), new(MockAuditLogRepository), stubUnitOfWork{})
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
@@ -162,7 +183,7 @@ func TestGetAnimalById(t *testing.T) {
 
 func TestUpdateAnimal(t *testing.T) {
 	mockRepo := new(MockAnimalsRepository)
-	animalService := NewAnimalService(mockRepo)
+	animalService := NewAnimalService(mockRepo, new(MockShowRoundsRepository), new(MockBookingsRepository), new(MockAuditLogRepository), stubUnitOfWork{})
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
@@ -202,30 +223,85 @@ func TestUpdateAnimal(t *testing.T) {
 
 func TestDeleteAnimal(t *testing.T) {
 	mockRepo := new(MockAnimalsRepository)
-	animalService := NewAnimalService(mockRepo)
+	mockShowRoundRepo := new(MockShowRoundsRepository)
+	mockBookingRepo := new(MockBookingsRepository)
+	mockAuditLog := new(MockAuditLogRepository)
+	animalService := NewAnimalService(mockRepo, mockShowRoundRepo, mockBookingRepo, mockAuditLog, stubUnitOfWork{})
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
 		animalId := "1"
 
+		mockShowRoundRepo.On("GetShowRoundsByAnimalId", ctx, animalId).Return([]*domain.ShowRounds{}, nil).Once()
 		mockRepo.On("DeleteAnimal", ctx, animalId).Return(nil).Once()
 
-		err := animalService.DeleteAnimal(ctx, animalId)
+		err := animalService.DeleteAnimal(ctx, animalId, port.DeleteOptions{})
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
+		mockShowRoundRepo.AssertExpectations(t)
 	})
 
 	t.Run("error", func(t *testing.T) {
 		animalId := "999"
 		expectedErr := errors.New("animal not found")
 
+		mockShowRoundRepo.On("GetShowRoundsByAnimalId", ctx, animalId).Return([]*domain.ShowRounds{}, nil).Once()
 		mockRepo.On("DeleteAnimal", ctx, animalId).Return(expectedErr).Once()
 
-		err := animalService.DeleteAnimal(ctx, animalId)
+		err := animalService.DeleteAnimal(ctx, animalId, port.DeleteOptions{})
 
 		assert.Error(t, err)
 		assert.Equal(t, expectedErr, err)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("referenced by show rounds", func(t *testing.T) {
+		animalId := "3"
+		showRounds := []*domain.ShowRounds{{Id: "round1", AnimalId: animalId}}
+
+		mockShowRoundRepo.On("GetShowRoundsByAnimalId", ctx, animalId).Return(showRounds, nil).Once()
+
+		err := animalService.DeleteAnimal(ctx, animalId, port.DeleteOptions{})
+
+		assert.ErrorIs(t, err, domain.ErrReferenceInUse)
+		mockRepo.AssertNotCalled(t, "DeleteAnimal", ctx, animalId)
+		mockShowRoundRepo.AssertExpectations(t)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		animalId := "4"
+
+		mockRepo.On("GetAnimalById", ctx, animalId).Return(&domain.Animals{Id: animalId, Version: 2}, nil).Once()
+
+		err := animalService.DeleteAnimal(ctx, animalId, port.DeleteOptions{Version: 1})
+
+		assert.ErrorIs(t, err, domain.ErrVersionConflict)
+		mockShowRoundRepo.AssertNotCalled(t, "GetShowRoundsByAnimalId", ctx, animalId)
+		mockRepo.AssertNotCalled(t, "DeleteAnimal", ctx, animalId)
+	})
+
+	t.Run("cascade", func(t *testing.T) {
+		animalId := "2"
+		showRounds := []*domain.ShowRounds{{Id: "round2", AnimalId: animalId, StageId: "stage1", ShowTime: "2025-01-01T09:00:00Z"}}
+		bookings := []domain.Bookings{{Id: "booking1", RoundId: "round2"}}
+
+		mockShowRoundRepo.On("GetShowRoundsByAnimalId", ctx, animalId).Return(showRounds, nil).Once()
+		mockBookingRepo.On("GetBookingsByRoundId", ctx, "round2").Return(bookings, nil).Once()
+		mockBookingRepo.On("DeleteBooking", ctx, "booking1").Return(nil).Once()
+		mockShowRoundRepo.On("DeleteShowRound", ctx, "round2").Return(nil).Once()
+		mockAuditLog.On("Append", ctx, auditEntry(domain.AuditActionRoundCancelled, "round2",
+			map[string]any{"animal_id": animalId, "stage_id": "stage1", "show_time": "2025-01-01T09:00:00Z"},
+			map[string]any{"deleted_bookings": 1},
+		)).Return(nil).Once()
+		mockRepo.On("DeleteAnimal", ctx, animalId).Return(nil).Once()
+
+		err := animalService.DeleteAnimal(ctx, animalId, port.DeleteOptions{Cascade: true})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockShowRoundRepo.AssertExpectations(t)
+		mockBookingRepo.AssertExpectations(t)
+		mockAuditLog.AssertExpectations(t)
+	})
 }
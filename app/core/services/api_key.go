@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+)
+
+// apiKeyMarker starts every API key, so that a key is recognisable in configs and secret scanners
+const apiKeyMarker = "lg_"
+
+// apiKeyPrefixLength is how much of a key is stored in the clear for admins to tell keys apart
+const apiKeyPrefixLength = len(apiKeyMarker) + 8
+
+// apiKeyTouchInterval is how often the last use of a key is written; a busy kiosk would otherwise write on
+// every request
+const apiKeyTouchInterval = time.Minute
+
+type ApiKeyService struct {
+	apiKeys       port.ApiKeyRepository
+	auditLog      port.AuditLogRepository
+	unitOfWork    port.UnitOfWork
+	ttl           time.Duration
+	rotationGrace time.Duration
+}
+
+// NewApiKeyService creates the API key service. Keys expire after ttl unless issued with an expiry of their own,
+// and a rotated key keeps working for rotationGrace.
+func NewApiKeyService(apiKeys port.ApiKeyRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork, ttl, rotationGrace time.Duration) *ApiKeyService {
+	return &ApiKeyService{
+		apiKeys:       apiKeys,
+		auditLog:      auditLog,
+		unitOfWork:    unitOfWork,
+		ttl:           ttl,
+		rotationGrace: rotationGrace,
+	}
+}
+
+// CreateClient registers a machine client
+func (s *ApiKeyService) CreateClient(ctx context.Context, req *domain.ApiClientRequest) (*domain.ApiClient, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	client := &domain.ApiClient{Name: strings.TrimSpace(req.Name), Description: req.Description}
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.apiKeys.CreateApiClient(ctx, client); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionApiClientCreated, domain.AuditEntityApiClient, client.Id, nil,
+			map[string]any{"name": client.Name})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (s *ApiKeyService) ListClients(ctx context.Context) ([]domain.ApiClient, error) {
+	return s.apiKeys.ListApiClients(ctx)
+}
+
+// ListKeys returns the keys of a client; it returns ErrNotFound when there is no such client
+func (s *ApiKeyService) ListKeys(ctx context.Context, clientId string) ([]domain.ApiKey, error) {
+	if _, err := s.apiKeys.GetApiClientById(ctx, clientId); err != nil {
+		return nil, err
+	}
+	return s.apiKeys.ListApiKeys(ctx, clientId)
+}
+
+// IssueKey issues a new key to a client. The key is only returned here; only its hash is stored.
+func (s *ApiKeyService) IssueKey(ctx context.Context, clientId string, req *domain.ApiKeyRequest) (*domain.IssuedApiKey, error) {
+	now := port.AuditTime()
+	if err := req.Validate(now); err != nil {
+		return nil, err
+	}
+	expiresAt := now.Add(s.ttl)
+	if req.ExpiresAt != nil {
+		expiresAt = req.ExpiresAt.UTC()
+	}
+
+	var issued *domain.IssuedApiKey
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.apiKeys.GetApiClientById(ctx, clientId); err != nil {
+			return err
+		}
+		var err error
+		issued, err = s.createKey(ctx, clientId, req.Scopes, expiresAt)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionApiKeyIssued, domain.AuditEntityApiKey, issued.ApiKey.Id, nil,
+			map[string]any{"client_id": clientId, "scopes": issued.ApiKey.Scopes, "expires_at": expiresAt})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issued, nil
+}
+
+// RotateKey issues a successor with the scopes of a key that lasts as long as a new key would, and brings the
+// expiry of the old key forward to the end of the grace period. A revoked or expired key cannot be rotated.
+func (s *ApiKeyService) RotateKey(ctx context.Context, id string) (*domain.IssuedApiKey, error) {
+	now := port.AuditTime()
+
+	var issued *domain.IssuedApiKey
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		old, err := s.apiKeys.GetApiKeyById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if old.RevokedAt != nil || !now.Before(old.ExpiresAt) {
+			return domain.ErrNotFound
+		}
+
+		issued, err = s.createKey(ctx, old.ClientId, old.Scopes, now.Add(s.ttl))
+		if err != nil {
+			return err
+		}
+		expiresAt := now.Add(s.rotationGrace)
+		if old.ExpiresAt.Before(expiresAt) {
+			expiresAt = old.ExpiresAt
+		}
+		if err := s.apiKeys.ExpireApiKey(ctx, old.Id, expiresAt); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionApiKeyRotated, domain.AuditEntityApiKey, old.Id,
+			map[string]any{"expires_at": old.ExpiresAt},
+			map[string]any{"expires_at": expiresAt, "successor_id": issued.ApiKey.Id})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issued, nil
+}
+
+// RevokeKey stops a key from working at once
+func (s *ApiKeyService) RevokeKey(ctx context.Context, id string) error {
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		key, err := s.apiKeys.GetApiKeyById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.apiKeys.RevokeApiKey(ctx, id, port.AuditTime()); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionApiKeyRevoked, domain.AuditEntityApiKey, id, nil,
+			map[string]any{"client_id": key.ClientId})
+	})
+}
+
+// Authenticate looks a key up by its hash and checks that it can still be used
+func (s *ApiKeyService) Authenticate(ctx context.Context, key string) (*domain.ApiKey, error) {
+	if !strings.HasPrefix(key, apiKeyMarker) {
+		return nil, domain.ErrApiKeyInvalid
+	}
+
+	apiKey, err := s.apiKeys.GetApiKeyByHash(ctx, utils.HashOpaqueToken(key))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrApiKeyInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := port.AuditTime()
+	if err := apiKey.Usable(now); err != nil {
+		return nil, err
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.apiKeys.TouchApiKey(ctx, apiKey.Id, now); err != nil {
+			return nil, err
+		}
+		apiKey.LastUsedAt = &now
+	}
+	return apiKey, nil
+}
+
+// createKey generates a key and stores its hash
+func (s *ApiKeyService) createKey(ctx context.Context, clientId string, scopes []string, expiresAt time.Time) (*domain.IssuedApiKey, error) {
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	key := apiKeyMarker + token
+
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+	apiKey := &domain.ApiKey{
+		ClientId:  clientId,
+		Prefix:    key[:apiKeyPrefixLength],
+		KeyHash:   utils.HashOpaqueToken(key),
+		Scopes:    slices.Compact(scopes),
+		ExpiresAt: expiresAt,
+	}
+	if _, err := s.apiKeys.CreateApiKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+	return &domain.IssuedApiKey{ApiKey: apiKey, Key: key}, nil
+}
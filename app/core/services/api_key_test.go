@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockApiKeyRepository is a mock of ApiKeyRepository interface
+type MockApiKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyRepository) CreateApiClient(ctx context.Context, client *domain.ApiClient) (*domain.ApiClient, error) {
+	args := m.Called(ctx, client)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ApiClient), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) GetApiClientById(ctx context.Context, id string) (*domain.ApiClient, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ApiClient), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) ListApiClients(ctx context.Context) ([]domain.ApiClient, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ApiClient), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) CreateApiKey(ctx context.Context, key *domain.ApiKey) (*domain.ApiKey, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) GetApiKeyById(ctx context.Context, id string) (*domain.ApiKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) GetApiKeyByHash(ctx context.Context, keyHash string) (*domain.ApiKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) ListApiKeys(ctx context.Context, clientId string) ([]domain.ApiKey, error) {
+	args := m.Called(ctx, clientId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) ExpireApiKey(ctx context.Context, id string, expiresAt time.Time) error {
+	args := m.Called(ctx, id, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyRepository) RevokeApiKey(ctx context.Context, id string, revokedAt time.Time) error {
+	args := m.Called(ctx, id, revokedAt)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyRepository) TouchApiKey(ctx context.Context, id string, usedAt time.Time) error {
+	args := m.Called(ctx, id, usedAt)
+	return args.Error(0)
+}
+
+func newApiKeyService() (*ApiKeyService, *MockApiKeyRepository, *MockAuditLogRepository) {
+	apiKeys, auditLog := new(MockApiKeyRepository), new(MockAuditLogRepository)
+	return NewApiKeyService(apiKeys, auditLog, stubUnitOfWork{}, 90*24*time.Hour, 24*time.Hour), apiKeys, auditLog
+}
+
+func TestIssueApiKey(t *testing.T) {
+	ctx := port.WithActor(context.Background(), "admin-1")
+
+	t.Run("stores the hash of the key with sorted scopes and the default expiry", func(t *testing.T) {
+		svc, apiKeys, auditLog := newApiKeyService()
+		apiKeys.On("GetApiClientById", ctx, "c-1").Return(&domain.ApiClient{Id: "c-1"}, nil)
+		apiKeys.On("CreateApiKey", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.ApiKey).Id = "k-1"
+		}).Return(&domain.ApiKey{Id: "k-1"}, nil)
+		auditLog.On("Append", ctx, auditAction(domain.AuditActionApiKeyIssued)).Return(nil).Once()
+
+		issued, err := svc.IssueKey(ctx, "c-1", &domain.ApiKeyRequest{
+			Scopes: []string{domain.ScopeRoundsRead, domain.ScopeBookingsCreate, domain.ScopeRoundsRead},
+		})
+
+		require.NoError(t, err)
+		assert.True(t, len(issued.Key) > apiKeyPrefixLength)
+		assert.Equal(t, issued.Key[:apiKeyPrefixLength], issued.ApiKey.Prefix)
+		assert.Equal(t, utils.HashOpaqueToken(issued.Key), issued.ApiKey.KeyHash)
+		assert.Equal(t, []string{domain.ScopeBookingsCreate, domain.ScopeRoundsRead}, issued.ApiKey.Scopes)
+		assert.WithinDuration(t, time.Now().Add(90*24*time.Hour), issued.ApiKey.ExpiresAt, time.Minute)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("rejects unknown scopes and an expiry in the past", func(t *testing.T) {
+		svc, apiKeys, _ := newApiKeyService()
+		past := time.Now().Add(-time.Hour)
+
+		_, err := svc.IssueKey(ctx, "c-1", &domain.ApiKeyRequest{Scopes: []string{"bookings:everything"}, ExpiresAt: &past})
+
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Len(t, validationErr.Fields, 2)
+		apiKeys.AssertNotCalled(t, "CreateApiKey", mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails for an unknown client", func(t *testing.T) {
+		svc, apiKeys, _ := newApiKeyService()
+		apiKeys.On("GetApiClientById", ctx, "missing").Return(nil, domain.ErrNotFound)
+
+		_, err := svc.IssueKey(ctx, "missing", &domain.ApiKeyRequest{Scopes: []string{domain.ScopeRoundsRead}})
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		apiKeys.AssertNotCalled(t, "CreateApiKey", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRotateApiKey(t *testing.T) {
+	ctx := port.WithActor(context.Background(), "admin-1")
+
+	t.Run("issues a successor with the same scopes and ends the old key after the grace period", func(t *testing.T) {
+		svc, apiKeys, auditLog := newApiKeyService()
+		apiKeys.On("GetApiKeyById", ctx, "k-1").Return(&domain.ApiKey{
+			Id: "k-1", ClientId: "c-1", Scopes: []string{domain.ScopeRoundsRead}, ExpiresAt: time.Now().Add(30 * 24 * time.Hour),
+		}, nil)
+		apiKeys.On("CreateApiKey", ctx, mock.MatchedBy(func(key *domain.ApiKey) bool {
+			return key.ClientId == "c-1" && assert.ObjectsAreEqual([]string{domain.ScopeRoundsRead}, key.Scopes)
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.ApiKey).Id = "k-2"
+		}).Return(&domain.ApiKey{Id: "k-2"}, nil)
+		var graceEnd time.Time
+		apiKeys.On("ExpireApiKey", ctx, "k-1", mock.Anything).Run(func(args mock.Arguments) {
+			graceEnd = args.Get(2).(time.Time)
+		}).Return(nil)
+		auditLog.On("Append", ctx, auditAction(domain.AuditActionApiKeyRotated)).Return(nil).Once()
+
+		issued, err := svc.RotateKey(ctx, "k-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "k-2", issued.ApiKey.Id)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), graceEnd, time.Minute)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("keeps an earlier expiry of the old key", func(t *testing.T) {
+		svc, apiKeys, auditLog := newApiKeyService()
+		expiresAt := time.Now().Add(time.Hour).UTC()
+		apiKeys.On("GetApiKeyById", ctx, "k-1").Return(&domain.ApiKey{Id: "k-1", ClientId: "c-1", ExpiresAt: expiresAt}, nil)
+		apiKeys.On("CreateApiKey", ctx, mock.Anything).Return(&domain.ApiKey{}, nil)
+		apiKeys.On("ExpireApiKey", ctx, "k-1", expiresAt).Return(nil).Once()
+		auditLog.On("Append", ctx, auditAction(domain.AuditActionApiKeyRotated)).Return(nil)
+
+		_, err := svc.RotateKey(ctx, "k-1")
+
+		require.NoError(t, err)
+		apiKeys.AssertExpectations(t)
+	})
+
+	t.Run("refuses revoked and expired keys", func(t *testing.T) {
+		svc, apiKeys, _ := newApiKeyService()
+		revokedAt := time.Now().Add(-time.Minute)
+		apiKeys.On("GetApiKeyById", ctx, "revoked").Return(&domain.ApiKey{Id: "revoked", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}, nil)
+		apiKeys.On("GetApiKeyById", ctx, "expired").Return(&domain.ApiKey{Id: "expired", ExpiresAt: time.Now().Add(-time.Hour)}, nil)
+
+		_, err := svc.RotateKey(ctx, "revoked")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = svc.RotateKey(ctx, "expired")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		apiKeys.AssertNotCalled(t, "CreateApiKey", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRevokeApiKey(t *testing.T) {
+	ctx := port.WithActor(context.Background(), "admin-1")
+	svc, apiKeys, auditLog := newApiKeyService()
+	apiKeys.On("GetApiKeyById", ctx, "k-1").Return(&domain.ApiKey{Id: "k-1", ClientId: "c-1"}, nil)
+	apiKeys.On("RevokeApiKey", ctx, "k-1", mock.Anything).Return(nil).Once()
+	auditLog.On("Append", ctx, auditAction(domain.AuditActionApiKeyRevoked)).Return(nil).Once()
+
+	require.NoError(t, svc.RevokeKey(ctx, "k-1"))
+	apiKeys.AssertExpectations(t)
+	auditLog.AssertExpectations(t)
+}
+
+func TestAuthenticateApiKey(t *testing.T) {
+	ctx := context.Background()
+	key := apiKeyMarker + "secret"
+	hash := utils.HashOpaqueToken(key)
+
+	t.Run("records the first use of a key", func(t *testing.T) {
+		svc, apiKeys, _ := newApiKeyService()
+		apiKeys.On("GetApiKeyByHash", ctx, hash).Return(&domain.ApiKey{Id: "k-1", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		apiKeys.On("TouchApiKey", ctx, "k-1", mock.Anything).Return(nil).Once()
+
+		apiKey, err := svc.Authenticate(ctx, key)
+
+		require.NoError(t, err)
+		assert.NotNil(t, apiKey.LastUsedAt)
+		apiKeys.AssertExpectations(t)
+	})
+
+	t.Run("does not record every request", func(t *testing.T) {
+		svc, apiKeys, _ := newApiKeyService()
+		lastUsed := time.Now().Add(-10 * time.Second)
+		apiKeys.On("GetApiKeyByHash", ctx, hash).Return(&domain.ApiKey{Id: "k-1", ExpiresAt: time.Now().Add(time.Hour), LastUsedAt: &lastUsed}, nil)
+
+		_, err := svc.Authenticate(ctx, key)
+
+		require.NoError(t, err)
+		apiKeys.AssertNotCalled(t, "TouchApiKey", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects unknown, expired and revoked keys", func(t *testing.T) {
+		svc, apiKeys, _ := newApiKeyService()
+		revokedAt := time.Now().Add(-time.Minute)
+		apiKeys.On("GetApiKeyByHash", ctx, utils.HashOpaqueToken(apiKeyMarker+"unknown")).Return(nil, domain.ErrNotFound)
+		apiKeys.On("GetApiKeyByHash", ctx, utils.HashOpaqueToken(apiKeyMarker+"expired")).Return(&domain.ApiKey{ExpiresAt: time.Now().Add(-time.Hour)}, nil)
+		apiKeys.On("GetApiKeyByHash", ctx, utils.HashOpaqueToken(apiKeyMarker+"revoked")).Return(&domain.ApiKey{ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}, nil)
+
+		for _, tt := range []struct {
+			key  string
+			want error
+		}{
+			{"no-marker", domain.ErrApiKeyInvalid},
+			{apiKeyMarker + "unknown", domain.ErrApiKeyInvalid},
+			{apiKeyMarker + "expired", domain.ErrApiKeyExpired},
+			{apiKeyMarker + "revoked", domain.ErrApiKeyRevoked},
+		} {
+			_, err := svc.Authenticate(ctx, tt.key)
+			assert.ErrorIs(t, err, tt.want, tt.key)
+			assert.ErrorIs(t, err, domain.ErrUnauthorized, tt.key)
+		}
+		apiKeys.AssertNotCalled(t, "TouchApiKey", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type AuditLogService struct {
+	auditLogRepository port.AuditLogRepository
+}
+
+func NewAuditLogService(auditLogRepository port.AuditLogRepository) *AuditLogService {
+	return &AuditLogService{auditLogRepository: auditLogRepository}
+}
+
+// ListAuditLog lists the audit log entries matching filter, most recent first unless the query sorts otherwise
+func (s *AuditLogService) ListAuditLog(ctx context.Context, filter port.AuditLogFilter, query port.ListQuery) (*port.Page[domain.AuditLogEntry], error) {
+	if len(query.Sort) == 0 {
+		query.Sort = []port.SortField{{Field: "occurred_at", Desc: true}}
+	}
+	return s.auditLogRepository.ListAuditLog(ctx, filter, query)
+}
+
+// recordAudit appends an entry for an action of the actor of ctx to the audit log
+func recordAudit(ctx context.Context, auditLog port.AuditLogRepository, action string, entityType string, entityId string, before, after map[string]any) error {
+	return auditLog.Append(ctx, port.NewAuditLogEntry(ctx, action, entityType, entityId, before, after))
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuditLogRepository is a mock of AuditLogRepository interface
+type MockAuditLogRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditLogRepository) Append(ctx context.Context, entry *domain.AuditLogEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditLogRepository) ListAuditLog(ctx context.Context, filter port.AuditLogFilter, query port.ListQuery) (*port.Page[domain.AuditLogEntry], error) {
+	args := m.Called(ctx, filter, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*port.Page[domain.AuditLogEntry]), args.Error(1)
+}
+
+// auditEntry matches the entry of an action on an entity whose before and after diffs equal the given ones
+func auditEntry(action string, entityId string, before, after map[string]any) any {
+	return mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+		return entry.Action == action && entry.EntityId == entityId &&
+			assert.ObjectsAreEqual(before, entry.Before) && assert.ObjectsAreEqual(after, entry.After)
+	})
+}
+
+func TestListAuditLog(t *testing.T) {
+	mockRepo := new(MockAuditLogRepository)
+	auditLogService := NewAuditLogService(mockRepo)
+	ctx := context.Background()
+	filter := port.AuditLogFilter{Actor: "admin-1", EntityType: domain.AuditEntityStage}
+
+	t.Run("most recent first by default", func(t *testing.T) {
+		expected := &port.Page[domain.AuditLogEntry]{Items: []domain.AuditLogEntry{{Id: "1"}}, Total: 1}
+		mockRepo.On("ListAuditLog", ctx, filter, port.ListQuery{Sort: []port.SortField{{Field: "occurred_at", Desc: true}}}).Return(expected, nil).Once()
+
+		result, err := auditLogService.ListAuditLog(ctx, filter, port.ListQuery{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("explicit sort", func(t *testing.T) {
+		query := port.ListQuery{Sort: []port.SortField{{Field: "action"}}}
+		mockRepo.On("ListAuditLog", ctx, filter, query).Return(&port.Page[domain.AuditLogEntry]{}, nil).Once()
+
+		_, err := auditLogService.ListAuditLog(ctx, filter, query)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -3,6 +3,9 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
 	"github.com/khunmostz/be-liongate-go/app/core/port"
@@ -11,26 +14,216 @@ import (
 
 type AuthService struct {
 	userRepo   port.UsersRepository
+	mfa        port.MfaRepository
+	sessions   port.SessionRepository
+	auditLog   port.AuditLogRepository
+	throttle   loginThrottle
+	codes      mfaCodes
 	jwtService *utils.JWTService
+	hasher     *utils.PasswordHasher
+	mfaTTL     time.Duration
+	// unknownUserHash is checked against the password of logins for unknown usernames, so that they take as long
+	// as logins with a wrong password and response times do not tell which usernames exist
+	unknownUserHash func() string
 }
 
-func NewAuthService(userRepo port.UsersRepository, jwtService *utils.JWTService) *AuthService {
-	return &AuthService{userRepo: userRepo, jwtService: jwtService}
+// NewAuthService creates the auth service; policy decides how failed logins counted in loginAttempts are throttled.
+// Logins that need a second factor wait for it for mfaTTL, and authenticator apps list the accounts under issuer.
+// Tokens issued before the sessions of their user were revoked in sessions are rejected. Passwords are hashed with
+// hasher, and hashes it would make differently are replaced on login.
+func NewAuthService(userRepo port.UsersRepository, loginAttempts port.LoginAttemptRepository, mfa port.MfaRepository, sessions port.SessionRepository, auditLog port.AuditLogRepository, jwtService *utils.JWTService, hasher *utils.PasswordHasher, policy domain.LoginPolicy, mfaTTL time.Duration, issuer string) *AuthService {
+	throttle := loginThrottle{attempts: loginAttempts, auditLog: auditLog, policy: policy}
+	return &AuthService{
+		userRepo:   userRepo,
+		mfa:        mfa,
+		sessions:   sessions,
+		auditLog:   auditLog,
+		throttle:   throttle,
+		codes:      mfaCodes{mfa: mfa, auditLog: auditLog, throttle: throttle, issuer: issuer},
+		jwtService: jwtService,
+		hasher:     hasher,
+		mfaTTL:     mfaTTL,
+		unknownUserHash: sync.OnceValue(func() string {
+			hash, _ := hasher.Hash("unknown user")
+			return hash
+		}),
+	}
 }
 
+// Login checks the credentials and issues a token pair. Every attempt is recorded in the audit log:
+// a successful one as done by the user, a failed one as done by an anonymous caller.
+// Failures are counted per username and per client IP; while either is delayed or locked out, attempts are refused
+// with a LoginThrottledError before the password is checked. An unknown username and a wrong password both return
+// ErrInvalidCredentials, so that the response does not tell whether a username exists.
+// Users with a second factor, or whose role requires one, get an MfaChallenge instead of the tokens and continue
+// with VerifyMfa.
 func (s *AuthService) Login(ctx context.Context, req *domain.LoginRequest) (*domain.AuthResponse, error) {
+	keys := loginKeys(ctx, req.Username)
+	if err := s.throttle.check(ctx, keys); err != nil {
+		var throttled *domain.LoginThrottledError
+		if !errors.As(err, &throttled) {
+			return nil, err
+		}
+		return nil, s.recordLoginFailed(ctx, req.Username, "", "throttled", err)
+	}
+
 	user, err := s.userRepo.GetUserByUsername(ctx, req.Username)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	if err != nil || user == nil {
+		s.hasher.Verify(s.unknownUserHash(), req.Password)
+		return nil, s.loginFailed(ctx, keys, req.Username, "", "unknown username", domain.ErrInvalidCredentials)
+	}
+
+	if !s.hasher.Verify(user.Password, req.Password) {
+		return nil, s.loginFailed(ctx, keys, req.Username, user.Id, "invalid password", domain.ErrInvalidCredentials)
+	}
+	if s.hasher.NeedsRehash(user.Password) {
+		if err := s.rehashPassword(ctx, user, req.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	// The failures of the username are only forgotten once the second factor is proven too,
+	// or else logging in again between wrong codes would keep them from ever adding up
+	challenge, err := s.mfaChallenge(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &domain.AuthResponse{Mfa: challenge}, nil
+	}
+
+	return s.completeLogin(ctx, keys[0], user, map[string]any{"username": user.Username})
+}
+
+// VerifyMfa completes a login with the token of its MfaChallenge and a TOTP or recovery code. When the login had
+// to enroll first, the code confirms the enrollment and the response carries the new recovery codes.
+func (s *AuthService) VerifyMfa(ctx context.Context, req *domain.MfaLoginRequest) (*domain.AuthResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	user, err := s.mfaUser(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := s.mfa.GetMfaEnrollment(ctx, user.Id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrMfaNotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	method := mfaMethodTOTP
+	if enrollment.Confirmed() {
+		method, err = s.codes.verify(ctx, user, enrollment, domain.MfaCodeRequest{Code: req.Code, RecoveryCode: req.RecoveryCode})
+	} else {
+		recoveryCodes, err = s.codes.confirm(ctx, user, enrollment, req.Code)
+	}
+	if errors.Is(err, domain.ErrMfaCodeInvalid) {
+		return nil, s.recordLoginFailed(ctx, user.Username, user.Id, "invalid mfa code", err)
+	}
+	var throttled *domain.LoginThrottledError
+	if errors.As(err, &throttled) {
+		return nil, s.recordLoginFailed(ctx, user.Username, user.Id, "throttled", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.completeLogin(ctx, domain.LoginKey(domain.LoginKeyUsername, user.Username), user,
+		map[string]any{"username": user.Username, "mfa": method})
+	if err != nil {
+		return nil, err
+	}
+	response.RecoveryCodes = recoveryCodes
+	return response, nil
+}
+
+// EnrollMfa starts the enrollment of a user whose login was held back because their role requires a second factor
+func (s *AuthService) EnrollMfa(ctx context.Context, mfaToken string) (*domain.MfaSetup, error) {
+	user, err := s.mfaUser(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.codes.start(ctx, user)
+}
+
+// mfaChallenge returns the challenge a login of user must pass before it gets the tokens,
+// or nil when the user has no second factor and their role does not require one
+func (s *AuthService) mfaChallenge(ctx context.Context, user *domain.Users) (*domain.MfaChallenge, error) {
+	enrollment, err := s.mfa.GetMfaEnrollment(ctx, user.Id)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	confirmed := enrollment != nil && enrollment.Confirmed()
+	if !confirmed {
+		_, err := s.mfa.GetMfaRequirement(ctx, user.Role)
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	expiresAt := port.AuditTime().Add(s.mfaTTL)
+	token, err := s.jwtService.GenerateMfaToken(user, expiresAt)
 	if err != nil {
 		return nil, err
 	}
+	return &domain.MfaChallenge{Token: token, ExpiresAt: expiresAt, EnrollmentRequired: !confirmed}, nil
+}
 
-	if user == nil {
-		return nil, errors.New("user not found")
+// mfaUser returns the user a login waiting for a second factor is for
+func (s *AuthService) mfaUser(ctx context.Context, mfaToken string) (*domain.Users, error) {
+	claims, err := s.jwtService.VerifyMfaToken(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSession(ctx, claims); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetUserById(ctx, claims.UserID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrUnauthorized
 	}
+	return user, err
+}
 
-	valid := utils.IsPasswordValid(user.Password, req.Password)
-	if !valid {
-		return nil, errors.New("invalid password")
+// completeLogin forgets the failed logins under usernameKey, records the login of user and issues their tokens
+func (s *AuthService) completeLogin(ctx context.Context, usernameKey string, user *domain.Users, after map[string]any) (*domain.AuthResponse, error) {
+	if err := s.throttle.reset(ctx, usernameKey); err != nil {
+		return nil, err
+	}
+	return s.issueLogin(ctx, user, after)
+}
+
+// rehashPassword replaces the stored hash of user with one made with the current parameters of the hasher, now
+// that the password is known. The user is the actor, as when they change their password themselves. A password the
+// hasher cannot hash, such as one longer than bcrypt takes that was hashed with argon2id, keeps its hash rather
+// than failing the login.
+func (s *AuthService) rehashPassword(ctx context.Context, user *domain.Users, password string) error {
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return nil
+	}
+	if _, err := s.userRepo.UpdateUser(port.WithActor(ctx, user.Id), user.Id, &domain.Users{Password: hash}); err != nil {
+		return err
+	}
+	user.Password = hash
+	return nil
+}
+
+// issueLogin records the login of user and issues their tokens, once they proved who they are
+func (s *AuthService) issueLogin(ctx context.Context, user *domain.Users, after map[string]any) (*domain.AuthResponse, error) {
+	if err := recordAudit(port.WithActor(ctx, user.Id), s.auditLog, domain.AuditActionLogin, domain.AuditEntityUser, user.Id, nil, after); err != nil {
+		return nil, err
 	}
 
 	access_token, err := s.jwtService.GenerateAccessToken(user)
@@ -52,8 +245,32 @@ func (s *AuthService) Login(ctx context.Context, req *domain.LoginRequest) (*dom
 	}, nil
 }
 
+// loginFailed counts a login that failed for reason against the throttle and returns loginErr,
+// or the error that kept the failure from being recorded
+func (s *AuthService) loginFailed(ctx context.Context, keys []string, username string, userId string, reason string, loginErr error) error {
+	if err := s.throttle.recordFailure(ctx, keys); err != nil {
+		return err
+	}
+	return s.recordLoginFailed(ctx, username, userId, reason, loginErr)
+}
+
+// recordLoginFailed records a failed login attempt for username and returns loginErr,
+// or the error of the audit log when the attempt could not be recorded
+func (s *AuthService) recordLoginFailed(ctx context.Context, username string, userId string, reason string, loginErr error) error {
+	after := map[string]any{"username": username, "reason": reason}
+	if err := recordAudit(ctx, s.auditLog, domain.AuditActionLoginFailed, domain.AuditEntityUser, userId, nil, after); err != nil {
+		return err
+	}
+	return loginErr
+}
+
 func (s *AuthService) Register(ctx context.Context, req *domain.RegisterRequest) (*domain.AuthResponse, error) {
-	hashedPassword, err := utils.HashPassword(req.Password)
+	req.Email = domain.NormalizeEmail(req.Email)
+	if err := domain.JoinValidation(req.Validate(), checkPasswordStrength(s.hasher, "password", req.Password)); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.hasher.Hash(req.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +278,8 @@ func (s *AuthService) Register(ctx context.Context, req *domain.RegisterRequest)
 	user := &domain.Users{
 		Username: req.Username,
 		Password: hashedPassword,
-		Role:     req.Role,
+		Email:    req.Email,
+		Role:     domain.RoleUser,
 	}
 
 	if _, err := s.userRepo.CreateUser(ctx, user); err != nil {
@@ -92,6 +310,9 @@ func (s *AuthService) RefreshToken(ctx context.Context, req *domain.RefreshToken
 	if err != nil {
 		return nil, err
 	}
+	if err := s.checkSession(ctx, claims); err != nil {
+		return nil, err
+	}
 
 	user, err := s.userRepo.GetUserById(ctx, claims.UserID)
 	if err != nil {
@@ -108,3 +329,73 @@ func (s *AuthService) RefreshToken(ctx context.Context, req *domain.RefreshToken
 		RefreshToken: req.RefreshToken,
 	}, nil
 }
+
+func (s *AuthService) Authenticate(ctx context.Context, accessToken string) (*domain.JWTClaims, error) {
+	claims, err := s.jwtService.VerifyAccessToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSession(ctx, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Logout revokes the access token of claims, and the refresh token of req when it belongs to the same user.
+// Both stay on the denylist until they would have expired anyway.
+func (s *AuthService) Logout(ctx context.Context, claims *domain.JWTClaims, req *domain.LogoutRequest) error {
+	revoke := []*domain.JWTClaims{claims}
+	if req.RefreshToken != "" {
+		refresh, err := s.jwtService.VerifyRefreshToken(req.RefreshToken)
+		if err != nil {
+			return err
+		}
+		if refresh.UserID != claims.UserID {
+			return fmt.Errorf("%w: refresh token of another user", utils.ErrInvalidToken)
+		}
+		revoke = append(revoke, refresh)
+	}
+
+	now := port.AuditTime()
+	for _, token := range revoke {
+		// Tokens issued before they carried an id can only be revoked with the whole session
+		if token.TokenId == "" {
+			continue
+		}
+		err := s.sessions.RevokeToken(ctx, &domain.RevokedToken{
+			TokenId:   token.TokenId,
+			UserId:    token.UserID,
+			ExpiresAt: time.Unix(token.ExpiresAt, 0).UTC(),
+			RevokedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return recordAudit(ctx, s.auditLog, domain.AuditActionLogout, domain.AuditEntityUser, claims.UserID, nil,
+		map[string]any{"refresh_token_revoked": len(revoke) > 1})
+}
+
+// checkSession returns ErrSessionRevoked when the sessions of the user of claims were revoked after it was issued,
+// and ErrTokenRevoked when the token itself was
+func (s *AuthService) checkSession(ctx context.Context, claims *domain.JWTClaims) error {
+	revocation, err := s.sessions.GetSessionRevocation(ctx, claims.UserID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	if err == nil && revocation.Revokes(claims.IssuedAt) {
+		return domain.ErrSessionRevoked
+	}
+
+	if claims.TokenId == "" {
+		return nil
+	}
+	revoked, err := s.sessions.IsTokenRevoked(ctx, claims.TokenId)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return domain.ErrTokenRevoked
+	}
+	return nil
+}
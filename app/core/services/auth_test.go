@@ -2,37 +2,49 @@ package services
 
 import (
 	"context"
-	"os"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"github.com/khunmostz/be-liongate-go/app/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
-func TestLogin(t *testing.T) {
-	// Set up environment variables for JWT service
-	os.Setenv("JWT_SECRET", "test-secret-key")
-	os.Setenv("JWT_ACCESS_DURATION", "15m")
-	os.Setenv("JWT_REFRESH_DURATION", "168h")
-	defer func() {
-		os.Unsetenv("JWT_SECRET")
-		os.Unsetenv("JWT_ACCESS_DURATION")
-		os.Unsetenv("JWT_REFRESH_DURATION")
-	}()
+// testHasher makes argon2id hashes with the smallest parameters, which keeps the tests fast
+var testHasher = func() *utils.PasswordHasher {
+	hasher, err := utils.NewPasswordHasher(utils.PasswordParams{
+		Algorithm:         utils.PasswordAlgorithmArgon2id,
+		Argon2Memory:      64,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return hasher
+}()
 
+func TestLogin(t *testing.T) {
 	mockRepo := new(MockUsersRepository)
-	mockJWT, err := utils.NewJWTService()
-	assert.NoError(t, err)
-	authService := NewAuthService(mockRepo, mockJWT)
+	mockAuditLog := new(MockAuditLogRepository)
+	mockJWT := newTestJWTService(t)
+	authService := NewAuthService(mockRepo, quietLoginAttempts(), noMfa(), noRevocations(), mockAuditLog, mockJWT, testHasher, testLoginPolicy, testMfaTTL, "Liongate")
 
 	ctx := context.Background()
 
 	// Hash the password for testing
-	hashedPassword, _ := utils.HashPassword("password")
+	hashedPassword, _ := testHasher.Hash("password")
 
 	t.Run("success", func(t *testing.T) {
 		mockRepo.On("GetUserByUsername", ctx, "testuser").Return(&domain.Users{Id: "1", Username: "testuser", Password: hashedPassword}, nil).Once()
+		mockAuditLog.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionLogin && entry.Actor == "1" && entry.EntityId == "1"
+		})).Return(nil).Once()
 
 		result, err := authService.Login(ctx, &domain.LoginRequest{
 			Username: "testuser",
@@ -42,26 +54,162 @@ func TestLogin(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 		mockRepo.AssertExpectations(t)
+		mockAuditLog.AssertExpectations(t)
+	})
+
+	t.Run("outdated hash is replaced", func(t *testing.T) {
+		bcryptHash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+		require.NoError(t, err)
+		mockRepo.On("GetUserByUsername", ctx, "testuser").Return(&domain.Users{Id: "1", Username: "testuser", Password: string(bcryptHash)}, nil).Once()
+		mockRepo.On("UpdateUser", mock.Anything, "1", mock.MatchedBy(func(user *domain.Users) bool {
+			return strings.HasPrefix(user.Password, "$argon2id$") && testHasher.Verify(user.Password, "password") && !testHasher.NeedsRehash(user.Password)
+		})).Return(&domain.Users{Id: "1"}, nil).Once()
+		mockAuditLog.On("Append", mock.Anything, auditAction(domain.AuditActionLogin)).Return(nil).Once()
+
+		result, err := authService.Login(ctx, &domain.LoginRequest{Username: "testuser", Password: "password"})
+
+		require.NoError(t, err)
+		assert.NotNil(t, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("password stored unhashed is replaced", func(t *testing.T) {
+		mockRepo.On("GetUserByUsername", ctx, "testuser").Return(&domain.Users{Id: "1", Username: "testuser", Password: "password"}, nil).Once()
+		mockRepo.On("UpdateUser", mock.Anything, "1", mock.MatchedBy(func(user *domain.Users) bool {
+			return strings.HasPrefix(user.Password, "$argon2id$") && testHasher.Verify(user.Password, "password")
+		})).Return(&domain.Users{Id: "1"}, nil).Once()
+		mockAuditLog.On("Append", mock.Anything, auditAction(domain.AuditActionLogin)).Return(nil).Once()
+
+		result, err := authService.Login(ctx, &domain.LoginRequest{Username: "testuser", Password: "password"})
+
+		require.NoError(t, err)
+		assert.NotNil(t, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("password too long for bcrypt keeps its argon2id hash", func(t *testing.T) {
+		bcryptHasher, err := utils.NewPasswordHasher(utils.PasswordParams{Algorithm: utils.PasswordAlgorithmBcrypt, BcryptCost: bcrypt.MinCost})
+		require.NoError(t, err)
+		users := new(MockUsersRepository)
+		svc := NewAuthService(users, quietLoginAttempts(), noMfa(), noRevocations(), mockAuditLog, mockJWT, bcryptHasher, testLoginPolicy, testMfaTTL, "Liongate")
+		long := strings.Repeat("lion gate ", 10)
+		longHash, err := testHasher.Hash(long)
+		require.NoError(t, err)
+		users.On("GetUserByUsername", ctx, "testuser").Return(&domain.Users{Id: "1", Username: "testuser", Password: longHash}, nil).Once()
+		mockAuditLog.On("Append", mock.Anything, auditAction(domain.AuditActionLogin)).Return(nil).Once()
+
+		result, err := svc.Login(ctx, &domain.LoginRequest{Username: "testuser", Password: long})
+
+		require.NoError(t, err)
+		assert.NotNil(t, result)
+		users.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+		users.AssertExpectations(t)
 	})
 
 	t.Run("invalid password", func(t *testing.T) {
 		mockRepo.On("GetUserByUsername", ctx, "testuser").Return(&domain.Users{Id: "1", Username: "testuser", Password: hashedPassword}, nil).Once()
+		mockAuditLog.On("Append", ctx, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionLoginFailed && entry.Actor == domain.AnonymousActor && entry.EntityId == "1" &&
+				entry.After["reason"] == "invalid password"
+		})).Return(nil).Once()
 
 		result, err := authService.Login(ctx, &domain.LoginRequest{Username: "testuser", Password: "wrongpassword"})
 
-		assert.Error(t, err)
+		assert.Equal(t, domain.ErrInvalidCredentials, err)
 		assert.Nil(t, result)
-		assert.Equal(t, "invalid password", err.Error())
 		mockRepo.AssertExpectations(t)
+		mockAuditLog.AssertExpectations(t)
 	})
 
 	t.Run("user not found", func(t *testing.T) {
-		mockRepo.On("GetUserByUsername", ctx, "testuser").Return(nil, nil).Once()
+		mockRepo.On("GetUserByUsername", ctx, "testuser").Return(nil, domain.ErrNotFound).Once()
+		mockAuditLog.On("Append", ctx, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionLoginFailed && entry.EntityId == "" && entry.After["username"] == "testuser"
+		})).Return(nil).Once()
 
 		result, err := authService.Login(ctx, &domain.LoginRequest{Username: "testuser", Password: "password"})
 
-		assert.Error(t, err)
+		assert.Equal(t, domain.ErrInvalidCredentials, err, "an unknown username fails like a wrong password")
 		assert.Nil(t, result)
 		mockRepo.AssertExpectations(t)
+		mockAuditLog.AssertExpectations(t)
+	})
+
+	t.Run("failed attempt cannot be recorded", func(t *testing.T) {
+		auditErr := errors.New("audit log unavailable")
+		mockRepo.On("GetUserByUsername", ctx, "testuser").Return(&domain.Users{Id: "1", Username: "testuser", Password: hashedPassword}, nil).Once()
+		mockAuditLog.On("Append", ctx, mock.Anything).Return(auditErr).Once()
+
+		result, err := authService.Login(ctx, &domain.LoginRequest{Username: "testuser", Password: "wrongpassword"})
+
+		assert.ErrorIs(t, err, auditErr)
+		assert.Nil(t, result)
+		mockAuditLog.AssertExpectations(t)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	jwtService := newTestJWTService(t)
+	ctx := port.WithActor(context.Background(), "1")
+	alice := &domain.Users{Id: "1", Username: "alice", Role: domain.RoleUser}
+	tokens, err := jwtService.GenerateTokenPair(alice)
+	require.NoError(t, err)
+	access, err := jwtService.VerifyAccessToken(tokens.AccessToken)
+	require.NoError(t, err)
+	refresh, err := jwtService.VerifyRefreshToken(tokens.RefreshToken)
+	require.NoError(t, err)
+
+	newService := func() (*AuthService, *MockSessionRepository, *MockAuditLogRepository) {
+		sessions := new(MockSessionRepository)
+		auditLog := new(MockAuditLogRepository)
+		return NewAuthService(new(MockUsersRepository), quietLoginAttempts(), noMfa(), sessions, auditLog, jwtService, testHasher, testLoginPolicy, testMfaTTL, "Liongate"), sessions, auditLog
+	}
+	revoked := func(claims *domain.JWTClaims) any {
+		return mock.MatchedBy(func(token *domain.RevokedToken) bool {
+			return token.TokenId == claims.TokenId && token.UserId == "1" && token.ExpiresAt.Unix() == claims.ExpiresAt
+		})
+	}
+
+	t.Run("revokes the access token", func(t *testing.T) {
+		svc, sessions, auditLog := newService()
+		sessions.On("RevokeToken", ctx, revoked(access)).Return(nil).Once()
+		auditLog.On("Append", ctx, auditAction(domain.AuditActionLogout)).Return(nil).Once()
+
+		require.NoError(t, svc.Logout(ctx, access, &domain.LogoutRequest{}))
+		sessions.AssertExpectations(t)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("revokes the refresh token along", func(t *testing.T) {
+		svc, sessions, auditLog := newService()
+		sessions.On("RevokeToken", ctx, revoked(access)).Return(nil).Once()
+		sessions.On("RevokeToken", ctx, revoked(refresh)).Return(nil).Once()
+		auditLog.On("Append", ctx, auditAction(domain.AuditActionLogout)).Return(nil).Once()
+
+		require.NoError(t, svc.Logout(ctx, access, &domain.LogoutRequest{RefreshToken: tokens.RefreshToken}))
+		sessions.AssertExpectations(t)
+	})
+
+	t.Run("refuses the refresh token of another user", func(t *testing.T) {
+		svc, sessions, _ := newService()
+		bobTokens, err := jwtService.GenerateTokenPair(&domain.Users{Id: "2", Username: "bob", Role: domain.RoleUser})
+		require.NoError(t, err)
+
+		err = svc.Logout(ctx, access, &domain.LogoutRequest{RefreshToken: bobTokens.RefreshToken})
+		assert.ErrorIs(t, err, domain.ErrUnauthorized)
+		sessions.AssertNotCalled(t, "RevokeToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("revoked tokens are rejected", func(t *testing.T) {
+		sessions := new(MockSessionRepository)
+		sessions.On("GetSessionRevocation", mock.Anything, "1").Return(nil, domain.ErrNotFound)
+		sessions.On("IsTokenRevoked", mock.Anything, access.TokenId).Return(true, nil)
+		sessions.On("IsTokenRevoked", mock.Anything, refresh.TokenId).Return(true, nil)
+		svc := NewAuthService(new(MockUsersRepository), quietLoginAttempts(), noMfa(), sessions, new(MockAuditLogRepository), jwtService, testHasher, testLoginPolicy, testMfaTTL, "Liongate")
+
+		_, err := svc.Authenticate(ctx, tokens.AccessToken)
+		assert.ErrorIs(t, err, domain.ErrTokenRevoked)
+		_, err = svc.RefreshToken(ctx, &domain.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+		assert.ErrorIs(t, err, domain.ErrTokenRevoked)
 	})
 }
@@ -10,68 +10,340 @@ import (
 )
 
 type BookingService struct {
-	bookingsRepository port.BookingsRepository
+	bookingsRepository  port.BookingsRepository
+	showRoundRepository port.ShowRoundsRepository
+	stageRepository     port.PerformanceStageRepository
+	usersRepository     port.UsersRepository
+	auditLog            port.AuditLogRepository
+	unitOfWork          port.UnitOfWork
 }
 
-func NewBookingsService(bookingsRepository port.BookingsRepository) *BookingService {
+func NewBookingsService(bookingsRepository port.BookingsRepository, showRoundRepository port.ShowRoundsRepository, stageRepository port.PerformanceStageRepository, usersRepository port.UsersRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork) *BookingService {
 	return &BookingService{
-		bookingsRepository: bookingsRepository,
+		bookingsRepository:  bookingsRepository,
+		showRoundRepository: showRoundRepository,
+		stageRepository:     stageRepository,
+		usersRepository:     usersRepository,
+		auditLog:            auditLog,
+		unitOfWork:          unitOfWork,
 	}
 }
 
+// bookingActor is who acts on bookings. Staff, that is admins and API clients whose key was checked for the scope
+// of the route, act on every booking; other users only on their own.
+type bookingActor struct {
+	id    string
+	staff bool
+}
+
+// actor returns who acts on bookings in ctx
+func (s *BookingService) actor(ctx context.Context) (bookingActor, error) {
+	actor := port.ActorFromContext(ctx)
+	if domain.IsApiClientActor(actor) {
+		return bookingActor{id: actor, staff: true}, nil
+	}
+	if actor == domain.AnonymousActor {
+		return bookingActor{}, fmt.Errorf("%w: only signed in users can use bookings", domain.ErrForbidden)
+	}
+
+	user, err := s.usersRepository.GetUserById(ctx, actor)
+	if errors.Is(err, domain.ErrNotFound) {
+		return bookingActor{}, fmt.Errorf("%w: only signed in users can use bookings", domain.ErrForbidden)
+	}
+	if err != nil {
+		return bookingActor{}, err
+	}
+	return bookingActor{id: actor, staff: user.Role == domain.RoleAdmin}, nil
+}
+
+// owns returns ErrForbidden unless the actor may act on the bookings of userId
+func (a bookingActor) owns(userId string) error {
+	if a.staff || userId == a.id {
+		return nil
+	}
+	return fmt.Errorf("%w: the booking belongs to another user", domain.ErrForbidden)
+}
+
+// checkChange returns ErrForbidden when a user who is not staff sets a field of an update that only staff may set:
+// users may move their bookings and cancel them, but not hand them over, reprice or refund them
+func (a bookingActor) checkChange(booking *domain.Bookings) error {
+	if a.staff {
+		return nil
+	}
+	if booking.UserId != "" && booking.UserId != a.id {
+		return fmt.Errorf("%w: only an admin can hand a booking to another user", domain.ErrForbidden)
+	}
+	if booking.Price != 0 {
+		return fmt.Errorf("%w: only an admin can change the price of a booking", domain.ErrForbidden)
+	}
+	if booking.Status != "" && booking.Status != domain.BookingStatusCancelled {
+		return fmt.Errorf("%w: only an admin can set a booking %s", domain.ErrForbidden, booking.Status)
+	}
+	return nil
+}
+
+// validateReferences checks that the show round and, when set, the user of a booking exist
+func (s *BookingService) validateReferences(ctx context.Context, booking *domain.Bookings) error {
+	_, err := s.showRoundRepository.GetShowRoundById(ctx, booking.RoundId)
+	if err := ensureReference(err, "show round", booking.RoundId); err != nil {
+		return err
+	}
+
+	if booking.UserId == "" {
+		return nil
+	}
+	_, err = s.usersRepository.GetUserById(ctx, booking.UserId)
+	return ensureReference(err, "user", booking.UserId)
+}
+
+// seatPrice returns the price per seat of the stage a show round is performed on
+func (s *BookingService) seatPrice(ctx context.Context, roundId string) (float64, error) {
+	round, err := s.showRoundRepository.GetShowRoundById(ctx, roundId)
+	if err != nil {
+		return 0, ensureReference(err, "show round", roundId)
+	}
+	stage, err := s.stageRepository.GetStageById(ctx, round.StageId)
+	if err != nil {
+		return 0, ensureReference(err, "stage", round.StageId)
+	}
+	return stage.PricePerSeat, nil
+}
+
 // checkSeatAvailability checks if the seat number is already taken for a specific round
 func (s *BookingService) checkSeatAvailability(ctx context.Context, roundId string, seatNumber int, excludeBookingId string) error {
 	// Get all bookings for this round
-	bookings, err := s.GetBookingsByRoundId(ctx, roundId)
+	bookings, err := s.bookingsRepository.GetBookingsByRoundId(ctx, roundId)
 	if err != nil {
 		return err
 	}
 
 	// Check if the seat number is already taken
 	for _, booking := range bookings {
-		// Skip the current booking if we're updating
-		if booking.Id == excludeBookingId {
+		// Skip the current booking if we're updating, and cancelled or refunded bookings which free their seat
+		if booking.Id == excludeBookingId || booking.IsCancelled() {
 			continue
 		}
 
 		if booking.SeatNumber == seatNumber {
-			return errors.New(fmt.Sprintf("seat number %d is already taken for this round", seatNumber))
+			return seatTaken(seatNumber)
 		}
 	}
 
 	return nil
 }
 
+// seatTaken is the error of booking a seat that another live booking of the round holds
+func seatTaken(seatNumber int) error {
+	return fmt.Errorf("%w: seat number %d is already taken for this round", domain.ErrAlreadyExists, seatNumber)
+}
+
+// translateSeatConflict reports a write rejected by the unique seat index, which catches a concurrent booking
+// of the seat that checkSeatAvailability could not see yet, as the seat being taken
+func translateSeatConflict(err error, seatNumber int) error {
+	if errors.Is(err, domain.ErrAlreadyExists) {
+		return seatTaken(seatNumber)
+	}
+	return err
+}
+
+// CreateBooking books a seat; the seat check and the insert run in one unit of work. Users who are not staff book
+// for themselves at the price per seat of the round's stage, whatever user and price the booking names.
 func (s *BookingService) CreateBooking(ctx context.Context, booking *domain.Bookings) (*domain.Bookings, error) {
-	// Check if the seat is available
-	if err := s.checkSeatAvailability(ctx, booking.RoundId, booking.SeatNumber, ""); err != nil {
+	actor, err := s.actor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.staff {
+		booking.UserId, booking.Price = actor.id, 0
+	}
+	if err := booking.Validate(); err != nil {
 		return nil, err
 	}
 
-	return s.bookingsRepository.CreateBooking(ctx, booking)
+	var created *domain.Bookings
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := s.validateReferences(ctx, booking); err != nil {
+			return err
+		}
+		if !actor.staff {
+			price, err := s.seatPrice(ctx, booking.RoundId)
+			if err != nil {
+				return err
+			}
+			booking.Price = price
+		}
+
+		// Check if the seat is available
+		if err := s.checkSeatAvailability(ctx, booking.RoundId, booking.SeatNumber, ""); err != nil {
+			return err
+		}
+
+		if booking.Status == "" {
+			booking.Status = domain.BookingStatusConfirmed
+		}
+
+		var err error
+		created, err = s.bookingsRepository.CreateBooking(ctx, booking)
+		return translateSeatConflict(err, booking.SeatNumber)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
+// GetBookingById returns a booking to its user or staff
 func (s *BookingService) GetBookingById(ctx context.Context, id string) (*domain.Bookings, error) {
-	return s.bookingsRepository.GetBookingById(ctx, id)
+	actor, err := s.actor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	booking, err := s.bookingsRepository.GetBookingById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := actor.owns(booking.UserId); err != nil {
+		return nil, err
+	}
+	return booking, nil
 }
 
+// GetBookingsByUserId returns the bookings of a user to the user themselves or staff
 func (s *BookingService) GetBookingsByUserId(ctx context.Context, userId string) ([]domain.Bookings, error) {
+	actor, err := s.actor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := actor.owns(userId); err != nil {
+		return nil, err
+	}
 	return s.bookingsRepository.GetBookingsByUserId(ctx, userId)
 }
 
+// GetBookingsByRoundId returns the bookings of a show round; users who are not staff only get their own
 func (s *BookingService) GetBookingsByRoundId(ctx context.Context, roundId string) ([]domain.Bookings, error) {
-	return s.bookingsRepository.GetBookingsByRoundId(ctx, roundId)
+	actor, err := s.actor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := s.bookingsRepository.GetBookingsByRoundId(ctx, roundId)
+	if err != nil || actor.staff {
+		return bookings, err
+	}
+
+	own := []domain.Bookings{}
+	for _, booking := range bookings {
+		if booking.UserId == actor.id {
+			own = append(own, booking)
+		}
+	}
+	return own, nil
 }
 
+// ListBookings lists bookings; users who are not staff only list their own
+func (s *BookingService) ListBookings(ctx context.Context, filter port.BookingFilter, query port.ListQuery) (*port.Page[domain.Bookings], error) {
+	actor, err := s.actor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.staff {
+		if err := actor.owns(filter.UserId); filter.UserId != "" && err != nil {
+			return nil, err
+		}
+		filter.UserId = actor.id
+	}
+	return s.bookingsRepository.ListBookings(ctx, filter, query)
+}
+
+// UpdateBooking changes a booking of the user or, for staff, any booking; the seat check, the update and the audit
+// log entry of a cancellation or refund run in one unit of work
 func (s *BookingService) UpdateBooking(ctx context.Context, id string, booking *domain.Bookings) (*domain.Bookings, error) {
-	// Check if the seat is available (excluding the current booking)
-	if err := s.checkSeatAvailability(ctx, booking.RoundId, booking.SeatNumber, id); err != nil {
+	actor, err := s.actor(ctx)
+	if err != nil {
 		return nil, err
 	}
+	if err := actor.checkChange(booking); err != nil {
+		return nil, err
+	}
+	if err := booking.ValidateUpdate(); err != nil {
+		return nil, err
+	}
+
+	var updated *domain.Bookings
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		existing, err := s.bookingsRepository.GetBookingById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := actor.owns(existing.UserId); err != nil {
+			return err
+		}
+
+		// The update only names the fields that change, so check the booking it results in
+		merged := existing.Merge(booking)
+		if err := s.validateReferences(ctx, &merged); err != nil {
+			return err
+		}
+
+		// Check if the seat is available (excluding the current booking); a cancelled booking holds no seat
+		if !merged.IsCancelled() {
+			if err := s.checkSeatAvailability(ctx, merged.RoundId, merged.SeatNumber, id); err != nil {
+				return err
+			}
+		}
+
+		updated, err = s.bookingsRepository.UpdateBooking(ctx, id, booking)
+		if err != nil {
+			return translateSeatConflict(err, merged.SeatNumber)
+		}
+		return s.recordStatusChange(ctx, existing, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
 
-	return s.bookingsRepository.UpdateBooking(ctx, id, booking)
+// recordStatusChange records in the audit log a booking being cancelled or refunded
+func (s *BookingService) recordStatusChange(ctx context.Context, existing *domain.Bookings, updated *domain.Bookings) error {
+	if updated.Status == existing.Status {
+		return nil
+	}
+
+	var action string
+	switch updated.Status {
+	case domain.BookingStatusCancelled:
+		action = domain.AuditActionBookingCancelled
+	case domain.BookingStatusRefunded:
+		action = domain.AuditActionBookingRefunded
+	default:
+		return nil
+	}
+
+	before := map[string]any{"status": existing.Status}
+	after := map[string]any{"status": updated.Status, "price": updated.Price}
+	return recordAudit(ctx, s.auditLog, action, domain.AuditEntityBooking, updated.Id, before, after)
 }
 
-func (s *BookingService) DeleteBooking(ctx context.Context, id string) error {
-	return s.bookingsRepository.DeleteBooking(ctx, id)
+// DeleteBooking deletes a booking of the user or, for staff, any booking, provided it is still at the version in opts
+func (s *BookingService) DeleteBooking(ctx context.Context, id string, opts port.DeleteOptions) error {
+	actor, err := s.actor(ctx)
+	if err != nil {
+		return err
+	}
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if !actor.staff {
+			existing, err := s.bookingsRepository.GetBookingById(ctx, id)
+			if err != nil {
+				return err
+			}
+			if err := actor.owns(existing.UserId); err != nil {
+				return err
+			}
+		}
+		if err := checkDeleteVersion(ctx, id, opts, s.bookingsRepository.GetBookingById); err != nil {
+			return err
+		}
+		return s.bookingsRepository.DeleteBooking(ctx, id)
+	})
 }
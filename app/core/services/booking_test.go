@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -47,6 +48,14 @@ func (m *MockBookingsRepository) GetBookingsByRoundId(ctx context.Context, round
 	return args.Get(0).([]domain.Bookings), args.Error(1)
 }
 
+func (m *MockBookingsRepository) ListBookings(ctx context.Context, filter port.BookingFilter, query port.ListQuery) (*port.Page[domain.Bookings], error) {
+	args := m.Called(ctx, filter, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*port.Page[domain.Bookings]), args.Error(1)
+}
+
 func (m *MockBookingsRepository) UpdateBooking(ctx context.Context, id string, booking *domain.Bookings) (*domain.Bookings, error) {
 	args := m.Called(ctx, id, booking)
 	if args.Get(0) == nil {
@@ -60,10 +69,21 @@ func (m *MockBookingsRepository) DeleteBooking(ctx context.Context, id string) e
 	return args.Error(0)
 }
 
+// kioskContext acts as an API client, which like an admin may act on every booking
+func kioskContext() context.Context {
+	return port.WithActor(context.Background(), domain.ApiClientActor("kiosk"))
+}
+
 func TestCreateBooking(t *testing.T) {
 	mockRepo := new(MockBookingsRepository)
-	bookingService := NewBookingsService(mockRepo)
-	ctx := context.Background()
+	mockShowRoundRepo := new(MockShowRoundsRepository)
+	mockUserRepo := new(MockUsersRepository)
+	bookingService := NewBookingsService(mockRepo, mockShowRoundRepo, new(MockPerformanceStageRepository), mockUserRepo, new(MockAuditLogRepository), stubUnitOfWork{})
+	ctx := kioskContext()
+
+	mockShowRoundRepo.On("GetShowRoundById", ctx, "round1").Return(&domain.ShowRounds{Id: "round1"}, nil)
+	mockShowRoundRepo.On("GetShowRoundById", ctx, "missing").Return(nil, domain.ErrNotFound)
+	mockUserRepo.On("GetUserById", ctx, mock.Anything).Return(&domain.Users{}, nil)
 
 	t.Run("success", func(t *testing.T) {
 		booking := &domain.Bookings{
@@ -107,11 +127,27 @@ func TestCreateBooking(t *testing.T) {
 		result, err := bookingService.CreateBooking(ctx, booking)
 
 		assert.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
 		assert.Contains(t, err.Error(), "seat number 5 is already taken")
 		assert.Nil(t, result)
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("seat taken by a concurrent booking", func(t *testing.T) {
+		booking := &domain.Bookings{UserId: "user2", RoundId: "round1", SeatNumber: 6}
+
+		// The seat check saw the seat free, but the unique seat index rejects the insert
+		mockRepo.On("GetBookingsByRoundId", ctx, "round1").Return([]domain.Bookings{}, nil).Once()
+		mockRepo.On("CreateBooking", ctx, booking).Return(nil, domain.ErrAlreadyExists).Once()
+
+		result, err := bookingService.CreateBooking(ctx, booking)
+
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+		assert.Contains(t, err.Error(), "seat number 6 is already taken")
+		assert.Nil(t, result)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("error getting bookings", func(t *testing.T) {
 		booking := &domain.Bookings{
 			Id:         "2",
@@ -152,12 +188,94 @@ func TestCreateBooking(t *testing.T) {
 		assert.Nil(t, result)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("seat of cancelled booking is free", func(t *testing.T) {
+		booking := &domain.Bookings{
+			UserId:     "user2",
+			RoundId:    "round1",
+			SeatNumber: 7,
+		}
+
+		existingBookings := []domain.Bookings{
+			{Id: "1", RoundId: "round1", SeatNumber: 7, Status: domain.BookingStatusCancelled},
+		}
+		mockRepo.On("GetBookingsByRoundId", ctx, "round1").Return(existingBookings, nil).Once()
+		mockRepo.On("CreateBooking", ctx, booking).Return(booking, nil).Once()
+
+		result, err := bookingService.CreateBooking(ctx, booking)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.BookingStatusConfirmed, result.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown show round", func(t *testing.T) {
+		booking := &domain.Bookings{
+			UserId:     "user1",
+			RoundId:    "missing",
+			SeatNumber: 5,
+		}
+
+		result, err := bookingService.CreateBooking(ctx, booking)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidReference)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "CreateBooking", ctx, booking)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		userRepo := new(MockUsersRepository)
+		service := NewBookingsService(mockRepo, mockShowRoundRepo, new(MockPerformanceStageRepository), userRepo, new(MockAuditLogRepository), stubUnitOfWork{})
+		booking := &domain.Bookings{
+			UserId:     "ghost",
+			RoundId:    "round1",
+			SeatNumber: 5,
+		}
+
+		userRepo.On("GetUserById", ctx, "ghost").Return(nil, domain.ErrNotFound).Once()
+
+		result, err := service.CreateBooking(ctx, booking)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidReference)
+		assert.Nil(t, result)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("commit fails", func(t *testing.T) {
+		commitErr := errors.New("commit failed")
+		service := NewBookingsService(mockRepo, mockShowRoundRepo, new(MockPerformanceStageRepository), mockUserRepo, new(MockAuditLogRepository), stubUnitOfWork{commitErr: commitErr})
+		booking := &domain.Bookings{
+			UserId:     "user1",
+			RoundId:    "round1",
+			SeatNumber: 7,
+		}
+
+		mockRepo.On("GetBookingsByRoundId", ctx, "round1").Return([]domain.Bookings{}, nil).Once()
+		mockRepo.On("CreateBooking", ctx, booking).Return(booking, nil).Once()
+
+		result, err := service.CreateBooking(ctx, booking)
+
+		assert.ErrorIs(t, err, commitErr)
+		assert.Nil(t, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("missing round", func(t *testing.T) {
+		booking := &domain.Bookings{UserId: "user1", SeatNumber: 8}
+
+		result, err := bookingService.CreateBooking(ctx, booking)
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+		assert.Contains(t, err.Error(), "round_id is required")
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "CreateBooking", ctx, booking)
+	})
 }
 
 func TestGetBookingById(t *testing.T) {
 	mockRepo := new(MockBookingsRepository)
-	bookingService := NewBookingsService(mockRepo)
-	ctx := context.Background()
+	bookingService := NewBookingsService(mockRepo, new(MockShowRoundsRepository), new(MockPerformanceStageRepository), new(MockUsersRepository), new(MockAuditLogRepository), stubUnitOfWork{})
+	ctx := kioskContext()
 
 	t.Run("success", func(t *testing.T) {
 		bookingId := "1"
@@ -192,8 +310,8 @@ func TestGetBookingById(t *testing.T) {
 
 func TestGetBookingsByUserId(t *testing.T) {
 	mockRepo := new(MockBookingsRepository)
-	bookingService := NewBookingsService(mockRepo)
-	ctx := context.Background()
+	bookingService := NewBookingsService(mockRepo, new(MockShowRoundsRepository), new(MockPerformanceStageRepository), new(MockUsersRepository), new(MockAuditLogRepository), stubUnitOfWork{})
+	ctx := kioskContext()
 
 	t.Run("success", func(t *testing.T) {
 		userId := "user1"
@@ -228,8 +346,8 @@ func TestGetBookingsByUserId(t *testing.T) {
 
 func TestGetBookingsByRoundId(t *testing.T) {
 	mockRepo := new(MockBookingsRepository)
-	bookingService := NewBookingsService(mockRepo)
-	ctx := context.Background()
+	bookingService := NewBookingsService(mockRepo, new(MockShowRoundsRepository), new(MockPerformanceStageRepository), new(MockUsersRepository), new(MockAuditLogRepository), stubUnitOfWork{})
+	ctx := kioskContext()
 
 	t.Run("success", func(t *testing.T) {
 		roundId := "round1"
@@ -262,10 +380,54 @@ func TestGetBookingsByRoundId(t *testing.T) {
 	})
 }
 
+func TestListBookings(t *testing.T) {
+	mockRepo := new(MockBookingsRepository)
+	bookingService := NewBookingsService(mockRepo, new(MockShowRoundsRepository), new(MockPerformanceStageRepository), new(MockUsersRepository), new(MockAuditLogRepository), stubUnitOfWork{})
+	ctx := kioskContext()
+
+	t.Run("success", func(t *testing.T) {
+		filter := port.BookingFilter{UserId: "user1", Status: domain.BookingStatusConfirmed}
+		query := port.ListQuery{Limit: 1}
+		expectedPage := &port.Page[domain.Bookings]{
+			Items:      []domain.Bookings{{Id: "1", UserId: "user1", Status: domain.BookingStatusConfirmed}},
+			Total:      3,
+			NextCursor: "b2Zmc2V0OjE",
+		}
+
+		mockRepo.On("ListBookings", ctx, filter, query).Return(expectedPage, nil).Once()
+
+		result, err := bookingService.ListBookings(ctx, filter, query)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedPage, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		expectedErr := errors.New("database error")
+
+		mockRepo.On("ListBookings", ctx, port.BookingFilter{}, port.ListQuery{}).Return(nil, expectedErr).Once()
+
+		result, err := bookingService.ListBookings(ctx, port.BookingFilter{}, port.ListQuery{})
+
+		assert.Equal(t, expectedErr, err)
+		assert.Nil(t, result)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 func TestUpdateBooking(t *testing.T) {
 	mockRepo := new(MockBookingsRepository)
-	bookingService := NewBookingsService(mockRepo)
-	ctx := context.Background()
+	mockShowRoundRepo := new(MockShowRoundsRepository)
+	mockUserRepo := new(MockUsersRepository)
+	mockAuditLog := new(MockAuditLogRepository)
+	bookingService := NewBookingsService(mockRepo, mockShowRoundRepo, new(MockPerformanceStageRepository), mockUserRepo, mockAuditLog, stubUnitOfWork{})
+	ctx := kioskContext()
+
+	mockShowRoundRepo.On("GetShowRoundById", ctx, "round1").Return(&domain.ShowRounds{Id: "round1"}, nil)
+	mockShowRoundRepo.On("GetShowRoundById", ctx, "missing").Return(nil, domain.ErrNotFound)
+	mockUserRepo.On("GetUserById", ctx, mock.Anything).Return(&domain.Users{}, nil)
+	mockRepo.On("GetBookingById", ctx, "1").Return(&domain.Bookings{Id: "1", RoundId: "round1", SeatNumber: 5}, nil)
 
 	t.Run("success", func(t *testing.T) {
 		bookingId := "1"
@@ -311,6 +473,7 @@ func TestUpdateBooking(t *testing.T) {
 		result, err := bookingService.UpdateBooking(ctx, bookingId, booking)
 
 		assert.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
 		assert.Contains(t, err.Error(), "seat number 5 is already taken")
 		assert.Nil(t, result)
 		mockRepo.AssertExpectations(t)
@@ -386,19 +549,60 @@ func TestUpdateBooking(t *testing.T) {
 		assert.Nil(t, result)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("cancellation and refund are recorded", func(t *testing.T) {
+		tests := []struct {
+			from   string
+			to     string
+			action string
+		}{
+			{domain.BookingStatusConfirmed, domain.BookingStatusCancelled, domain.AuditActionBookingCancelled},
+			{domain.BookingStatusConfirmed, domain.BookingStatusRefunded, domain.AuditActionBookingRefunded},
+			{domain.BookingStatusCancelled, domain.BookingStatusRefunded, domain.AuditActionBookingRefunded},
+		}
+		for _, tt := range tests {
+			booking := &domain.Bookings{Id: "2", RoundId: "round1", SeatNumber: 7, Price: 150, Status: tt.to}
+			mockRepo.On("GetBookingById", ctx, "2").Return(&domain.Bookings{Id: "2", RoundId: "round1", SeatNumber: 7, Price: 150, Status: tt.from}, nil).Once()
+			mockRepo.On("UpdateBooking", ctx, "2", booking).Return(booking, nil).Once()
+			mockAuditLog.On("Append", ctx, auditEntry(tt.action, "2",
+				map[string]any{"status": tt.from},
+				map[string]any{"status": tt.to, "price": 150.0},
+			)).Return(nil).Once()
+
+			_, err := bookingService.UpdateBooking(ctx, "2", booking)
+
+			assert.NoError(t, err, "%s to %s", tt.from, tt.to)
+		}
+		mockRepo.AssertExpectations(t)
+		mockAuditLog.AssertExpectations(t)
+	})
+
+	t.Run("cancellation fails when it cannot be recorded", func(t *testing.T) {
+		auditErr := errors.New("audit log unavailable")
+		booking := &domain.Bookings{Id: "1", RoundId: "round1", SeatNumber: 5, Status: domain.BookingStatusCancelled}
+		mockRepo.On("GetBookingsByRoundId", ctx, "round1").Return([]domain.Bookings{}, nil).Once()
+		mockRepo.On("UpdateBooking", ctx, "1", booking).Return(booking, nil).Once()
+		mockAuditLog.On("Append", ctx, mock.Anything).Return(auditErr).Once()
+
+		result, err := bookingService.UpdateBooking(ctx, "1", booking)
+
+		assert.ErrorIs(t, err, auditErr)
+		assert.Nil(t, result)
+		mockAuditLog.AssertExpectations(t)
+	})
 }
 
 func TestDeleteBooking(t *testing.T) {
 	mockRepo := new(MockBookingsRepository)
-	bookingService := NewBookingsService(mockRepo)
-	ctx := context.Background()
+	bookingService := NewBookingsService(mockRepo, new(MockShowRoundsRepository), new(MockPerformanceStageRepository), new(MockUsersRepository), new(MockAuditLogRepository), stubUnitOfWork{})
+	ctx := kioskContext()
 
 	t.Run("success", func(t *testing.T) {
 		bookingId := "1"
 
 		mockRepo.On("DeleteBooking", ctx, bookingId).Return(nil).Once()
 
-		err := bookingService.DeleteBooking(ctx, bookingId)
+		err := bookingService.DeleteBooking(ctx, bookingId, port.DeleteOptions{})
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
@@ -410,10 +614,146 @@ func TestDeleteBooking(t *testing.T) {
 
 		mockRepo.On("DeleteBooking", ctx, bookingId).Return(expectedErr).Once()
 
-		err := bookingService.DeleteBooking(ctx, bookingId)
+		err := bookingService.DeleteBooking(ctx, bookingId, port.DeleteOptions{})
 
 		assert.Error(t, err)
 		assert.Equal(t, expectedErr, err)
 		mockRepo.AssertExpectations(t)
 	})
+	t.Run("current version", func(t *testing.T) {
+		bookingId := "2"
+
+		mockRepo.On("GetBookingById", ctx, bookingId).Return(&domain.Bookings{Id: bookingId, Version: 3}, nil).Once()
+		mockRepo.On("DeleteBooking", ctx, bookingId).Return(nil).Once()
+
+		err := bookingService.DeleteBooking(ctx, bookingId, port.DeleteOptions{Version: 3})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		bookingId := "3"
+
+		mockRepo.On("GetBookingById", ctx, bookingId).Return(&domain.Bookings{Id: bookingId, Version: 4}, nil).Once()
+
+		err := bookingService.DeleteBooking(ctx, bookingId, port.DeleteOptions{Version: 3})
+
+		assert.ErrorIs(t, err, domain.ErrVersionConflict)
+		mockRepo.AssertNotCalled(t, "DeleteBooking", ctx, bookingId)
+	})
+}
+
+func TestBookingOwnership(t *testing.T) {
+	newService := func() (*BookingService, *MockBookingsRepository) {
+		bookings := new(MockBookingsRepository)
+		showRounds := new(MockShowRoundsRepository)
+		users := new(MockUsersRepository)
+		users.On("GetUserById", mock.Anything, "alice").Return(&domain.Users{Id: "alice", Role: domain.RoleUser}, nil).Maybe()
+		users.On("GetUserById", mock.Anything, "root").Return(&domain.Users{Id: "root", Role: domain.RoleAdmin}, nil).Maybe()
+		stages := new(MockPerformanceStageRepository)
+		showRounds.On("GetShowRoundById", mock.Anything, "round1").Return(&domain.ShowRounds{Id: "round1", StageId: "stage1"}, nil).Maybe()
+		stages.On("GetStageById", mock.Anything, "stage1").Return(&domain.PerformanceStage{Id: "stage1", PricePerSeat: 250}, nil).Maybe()
+		auditLog := new(MockAuditLogRepository)
+		auditLog.On("Append", mock.Anything, mock.Anything).Return(nil).Maybe()
+		return NewBookingsService(bookings, showRounds, stages, users, auditLog, stubUnitOfWork{}), bookings
+	}
+	alice := port.WithActor(context.Background(), "alice")
+	root := port.WithActor(context.Background(), "root")
+	bobsBooking := &domain.Bookings{Id: "b2", UserId: "bob", RoundId: "round1", SeatNumber: 2, Status: domain.BookingStatusConfirmed}
+	alicesBooking := &domain.Bookings{Id: "b1", UserId: "alice", RoundId: "round1", SeatNumber: 1, Status: domain.BookingStatusConfirmed}
+
+	t.Run("users book for themselves at the price of the stage", func(t *testing.T) {
+		svc, bookings := newService()
+		bookings.On("GetBookingsByRoundId", mock.Anything, "round1").Return([]domain.Bookings{}, nil).Once()
+		bookings.On("CreateBooking", mock.Anything, mock.MatchedBy(func(booking *domain.Bookings) bool {
+			return booking.UserId == "alice" && booking.Price == 250
+		})).Return(&domain.Bookings{Id: "b3"}, nil).Once()
+
+		_, err := svc.CreateBooking(alice, &domain.Bookings{UserId: "bob", RoundId: "round1", SeatNumber: 3, Price: 1})
+
+		assert.NoError(t, err)
+		bookings.AssertExpectations(t)
+	})
+
+	t.Run("users only read their own bookings", func(t *testing.T) {
+		svc, bookings := newService()
+		bookings.On("GetBookingById", mock.Anything, "b1").Return(alicesBooking, nil)
+		bookings.On("GetBookingById", mock.Anything, "b2").Return(bobsBooking, nil)
+		bookings.On("GetBookingsByRoundId", mock.Anything, "round1").Return([]domain.Bookings{*alicesBooking, *bobsBooking}, nil)
+
+		_, err := svc.GetBookingById(alice, "b1")
+		assert.NoError(t, err)
+		_, err = svc.GetBookingById(alice, "b2")
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		_, err = svc.GetBookingById(root, "b2")
+		assert.NoError(t, err, "admins read every booking")
+
+		_, err = svc.GetBookingsByUserId(alice, "bob")
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+
+		own, err := svc.GetBookingsByRoundId(alice, "round1")
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Bookings{*alicesBooking}, own)
+		all, err := svc.GetBookingsByRoundId(root, "round1")
+		assert.NoError(t, err)
+		assert.Len(t, all, 2)
+	})
+
+	t.Run("users only list their own bookings", func(t *testing.T) {
+		svc, bookings := newService()
+		bookings.On("ListBookings", mock.Anything, port.BookingFilter{UserId: "alice", Status: domain.BookingStatusConfirmed}, port.ListQuery{}).
+			Return(&port.Page[domain.Bookings]{}, nil).Once()
+
+		_, err := svc.ListBookings(alice, port.BookingFilter{Status: domain.BookingStatusConfirmed}, port.ListQuery{})
+		assert.NoError(t, err)
+		_, err = svc.ListBookings(alice, port.BookingFilter{UserId: "bob"}, port.ListQuery{})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		bookings.AssertExpectations(t)
+	})
+
+	t.Run("users only change and delete their own bookings", func(t *testing.T) {
+		svc, bookings := newService()
+		bookings.On("GetBookingById", mock.Anything, "b2").Return(bobsBooking, nil)
+
+		_, err := svc.UpdateBooking(alice, "b2", &domain.Bookings{Status: domain.BookingStatusCancelled})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.ErrorIs(t, svc.DeleteBooking(alice, "b2", port.DeleteOptions{}), domain.ErrForbidden)
+		bookings.AssertNotCalled(t, "UpdateBooking", mock.Anything, mock.Anything, mock.Anything)
+		bookings.AssertNotCalled(t, "DeleteBooking", mock.Anything, mock.Anything)
+	})
+
+	t.Run("users cancel their own booking with a status-only update", func(t *testing.T) {
+		svc, bookings := newService()
+		change := &domain.Bookings{Status: domain.BookingStatusCancelled, Version: 1}
+		bookings.On("GetBookingById", mock.Anything, "b1").Return(alicesBooking, nil).Once()
+		bookings.On("UpdateBooking", mock.Anything, "b1", change).Return(&domain.Bookings{Id: "b1", UserId: "alice", RoundId: "round1", SeatNumber: 1, Status: domain.BookingStatusCancelled}, nil).Once()
+
+		updated, err := svc.UpdateBooking(alice, "b1", change)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.BookingStatusCancelled, updated.Status)
+		bookings.AssertExpectations(t)
+	})
+
+	t.Run("users cannot reprice, refund or hand over a booking", func(t *testing.T) {
+		svc, bookings := newService()
+		for _, change := range []*domain.Bookings{
+			{Price: 1},
+			{Status: domain.BookingStatusRefunded},
+			{Status: domain.BookingStatusConfirmed},
+			{UserId: "bob"},
+		} {
+			_, err := svc.UpdateBooking(alice, "b1", change)
+			assert.ErrorIs(t, err, domain.ErrForbidden, "%+v", change)
+		}
+		bookings.AssertNotCalled(t, "UpdateBooking", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("anonymous callers are refused", func(t *testing.T) {
+		svc, _ := newService()
+
+		_, err := svc.GetBookingById(context.Background(), "b1")
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
 }
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// ensureReference converts a not-found lookup of a referenced entity into ErrInvalidReference
+func ensureReference(err error, entity string, id string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("%w: %s %q", domain.ErrInvalidReference, entity, id)
+	}
+	return err
+}
+
+// referenceInUse builds the error returned when a delete would orphan dependent entities
+func referenceInUse(entity string, id string, count int, dependents string) error {
+	return fmt.Errorf("%w: %s %q has %d %s, delete with cascade to remove them", domain.ErrReferenceInUse, entity, id, count, dependents)
+}
+
+// checkDeleteVersion loads the entity being deleted and compares its version with the one the caller expects.
+// Nothing is loaded when no version is expected.
+func checkDeleteVersion[T interface{ GetVersion() int64 }](ctx context.Context, id string, opts port.DeleteOptions, get func(ctx context.Context, id string) (T, error)) error {
+	if opts.Version == 0 {
+		return nil
+	}
+	entity, err := get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return domain.CheckVersion(opts.Version, entity.GetVersion())
+}
+
+// deleteShowRoundsCascade cancels the given show rounds: it removes them together with their bookings and records
+// each cancellation in the audit log. Callers run it inside a unit of work so that a failure part way leaves
+// nothing half deleted.
+func deleteShowRoundsCascade(ctx context.Context, showRoundRepository port.ShowRoundsRepository, bookingsRepository port.BookingsRepository, auditLog port.AuditLogRepository, showRounds []*domain.ShowRounds) error {
+	for _, showRound := range showRounds {
+		bookings, err := bookingsRepository.GetBookingsByRoundId(ctx, showRound.Id)
+		if err != nil {
+			return err
+		}
+		if err := deleteBookings(ctx, bookingsRepository, bookings); err != nil {
+			return err
+		}
+		if err := showRoundRepository.DeleteShowRound(ctx, showRound.Id); err != nil {
+			return err
+		}
+		if err := recordRoundCancelled(ctx, auditLog, showRound, len(bookings)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordRoundCancelled records in the audit log that a show round was deleted together with deletedBookings bookings.
+// Show rounds have no status, so deleting one is how a round is cancelled.
+func recordRoundCancelled(ctx context.Context, auditLog port.AuditLogRepository, showRound *domain.ShowRounds, deletedBookings int) error {
+	before := map[string]any{
+		"animal_id": showRound.AnimalId,
+		"stage_id":  showRound.StageId,
+		"show_time": showRound.ShowTime,
+	}
+	after := map[string]any{"deleted_bookings": deletedBookings}
+	return recordAudit(ctx, auditLog, domain.AuditActionRoundCancelled, domain.AuditEntityShowRound, showRound.Id, before, after)
+}
+
+// deleteBookings removes the given bookings one by one
+func deleteBookings(ctx context.Context, bookingsRepository port.BookingsRepository, bookings []domain.Bookings) error {
+	for _, booking := range bookings {
+		if err := bookingsRepository.DeleteBooking(ctx, booking.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+)
+
+type InvitationService struct {
+	invitations port.InvitationRepository
+	users       port.UsersRepository
+	auditLog    port.AuditLogRepository
+	unitOfWork  port.UnitOfWork
+	jwtService  *utils.JWTService
+	hasher      *utils.PasswordHasher
+	ttl         time.Duration
+	acceptURL   string
+}
+
+// NewInvitationService creates the invitation service; invitations expire after ttl and their links point at acceptURL
+func NewInvitationService(invitations port.InvitationRepository, users port.UsersRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork, jwtService *utils.JWTService, hasher *utils.PasswordHasher, ttl time.Duration, acceptURL string) *InvitationService {
+	return &InvitationService{
+		invitations: invitations,
+		users:       users,
+		auditLog:    auditLog,
+		unitOfWork:  unitOfWork,
+		jwtService:  jwtService,
+		hasher:      hasher,
+		ttl:         ttl,
+		acceptURL:   acceptURL,
+	}
+}
+
+// CreateInvitation stores an invitation to the requested role and signs the link handed to the invitee
+func (s *InvitationService) CreateInvitation(ctx context.Context, req *domain.InvitationRequest) (*domain.InvitationLink, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	invitation := &domain.Invitation{Role: req.Role, ExpiresAt: port.AuditTime().Add(s.ttl)}
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.invitations.CreateInvitation(ctx, invitation); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionInvitationCreated, domain.AuditEntityInvitation, invitation.Id, nil, map[string]any{"role": invitation.Role})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.jwtService.GenerateInvitationToken(invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.InvitationLink{
+		Invitation: invitation,
+		Token:      token,
+		URL:        s.acceptURL + "?token=" + url.QueryEscape(token),
+	}, nil
+}
+
+// AcceptInvitation signs up a user with the role of the invitation and logs them in.
+// The invitation is marked as accepted in the same unit of work, so that only one of concurrent accepts succeeds.
+func (s *InvitationService) AcceptInvitation(ctx context.Context, req *domain.AcceptInvitationRequest) (*domain.AuthResponse, error) {
+	if err := domain.JoinValidation(req.Validate(), checkPasswordStrength(s.hasher, "password", req.Password)); err != nil {
+		return nil, err
+	}
+
+	claims, err := s.jwtService.VerifyInvitationToken(req.Token)
+	if errors.Is(err, domain.ErrTokenExpired) {
+		return nil, domain.ErrInvitationExpired
+	}
+	if err != nil {
+		// Why a token failed to verify is of no use to its holder and only helps forging one
+		return nil, domain.ErrInvitationInvalid
+	}
+
+	hashedPassword, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &domain.Users{Username: req.Username, Password: hashedPassword}
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		invitation, err := s.invitations.GetInvitationById(ctx, claims.InvitationId)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrInvitationInvalid
+		}
+		if err != nil {
+			return err
+		}
+		if invitation.Role != claims.Role {
+			return domain.ErrInvitationInvalid
+		}
+		now := port.AuditTime()
+		if err := invitation.Usable(now); err != nil {
+			return err
+		}
+
+		// The invitation is claimed before the user is created, so that of concurrent accepts only the one winning
+		// the claim creates a user, even where the unit of work cannot roll the claim back
+		user.Id = uuid.New().String()
+		user.Role = invitation.Role
+		ctx = port.WithActor(ctx, user.Id)
+		err = s.invitations.AcceptInvitation(ctx, invitation.Id, user.Id, now)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrInvitationUsed
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := s.users.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionInvitationAccepted, domain.AuditEntityInvitation, invitation.Id, nil,
+			map[string]any{"user_id": user.Id, "username": user.Username, "role": user.Role})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.jwtService.GenerateTokenPair(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AuthResponse{User: user, Tokens: tokens}, nil
+}
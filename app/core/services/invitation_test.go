@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockInvitationRepository is a mock of InvitationRepository interface
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepository) CreateInvitation(ctx context.Context, invitation *domain.Invitation) (*domain.Invitation, error) {
+	args := m.Called(ctx, invitation)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) GetInvitationById(ctx context.Context, id string) (*domain.Invitation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) AcceptInvitation(ctx context.Context, id string, userId string, acceptedAt time.Time) error {
+	args := m.Called(ctx, id, userId, acceptedAt)
+	return args.Error(0)
+}
+
+func newTestJWTService(t *testing.T) *utils.JWTService {
+	t.Setenv("JWT_ACCESS_DURATION", "15m")
+	t.Setenv("JWT_REFRESH_DURATION", "168h")
+
+	jwtService, err := utils.NewJWTService(newTestKeyRing(t, domain.SigningAlgorithmEdDSA))
+	require.NoError(t, err)
+	return jwtService
+}
+
+func TestCreateInvitation(t *testing.T) {
+	jwtService := newTestJWTService(t)
+	mockRepo := new(MockInvitationRepository)
+	mockAuditLog := new(MockAuditLogRepository)
+	invitationService := NewInvitationService(mockRepo, new(MockUsersRepository), mockAuditLog, stubUnitOfWork{}, jwtService, testHasher, time.Hour, "https://liongate.test/accept")
+	ctx := port.WithActor(context.Background(), "admin-1")
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.On("CreateInvitation", ctx, mock.MatchedBy(func(invitation *domain.Invitation) bool {
+			return invitation.Role == domain.RoleAdmin && time.Until(invitation.ExpiresAt) > 59*time.Minute
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.Invitation).Id = "inv-1"
+		}).Return(&domain.Invitation{Id: "inv-1"}, nil).Once()
+		mockAuditLog.On("Append", ctx, auditEntry(domain.AuditActionInvitationCreated, "inv-1", nil, map[string]any{"role": domain.RoleAdmin})).Return(nil).Once()
+
+		link, err := invitationService.CreateInvitation(ctx, &domain.InvitationRequest{Role: domain.RoleAdmin})
+
+		require.NoError(t, err)
+		assert.Equal(t, "inv-1", link.Invitation.Id)
+		assert.True(t, strings.HasPrefix(link.URL, "https://liongate.test/accept?token="), link.URL)
+		parsed, err := url.Parse(link.URL)
+		require.NoError(t, err)
+		assert.Equal(t, link.Token, parsed.Query().Get("token"))
+
+		claims, err := jwtService.VerifyInvitationToken(link.Token)
+		require.NoError(t, err)
+		assert.Equal(t, "inv-1", claims.InvitationId)
+		assert.Equal(t, domain.RoleAdmin, claims.Role)
+		mockRepo.AssertExpectations(t)
+		mockAuditLog.AssertExpectations(t)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		link, err := invitationService.CreateInvitation(ctx, &domain.InvitationRequest{Role: "root"})
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+		assert.Nil(t, link)
+	})
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	jwtService := newTestJWTService(t)
+	ctx := context.Background()
+	invitation := &domain.Invitation{Id: "inv-1", Role: domain.RoleAdmin, ExpiresAt: time.Now().Add(time.Hour)}
+	token, err := jwtService.GenerateInvitationToken(invitation)
+	require.NoError(t, err)
+
+	setup := func() (*InvitationService, *MockInvitationRepository, *MockUsersRepository, *MockAuditLogRepository) {
+		mockRepo := new(MockInvitationRepository)
+		mockUsers := new(MockUsersRepository)
+		mockAuditLog := new(MockAuditLogRepository)
+		return NewInvitationService(mockRepo, mockUsers, mockAuditLog, stubUnitOfWork{}, jwtService, testHasher, time.Hour, ""), mockRepo, mockUsers, mockAuditLog
+	}
+
+	t.Run("success", func(t *testing.T) {
+		invitationService, mockRepo, mockUsers, mockAuditLog := setup()
+		mockRepo.On("GetInvitationById", ctx, "inv-1").Return(invitation, nil).Once()
+		var claimedBy string
+		mockRepo.On("AcceptInvitation", mock.Anything, "inv-1", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			claimedBy = args.String(2)
+		}).Return(nil).Once()
+		mockUsers.On("CreateUser", mock.Anything, registeredUser("staff", "lion-gate-42", domain.RoleAdmin)).Run(func(args mock.Arguments) {
+			require.NotEmpty(t, claimedBy, "the invitation is claimed before the user is created")
+			assert.Equal(t, claimedBy, args.Get(1).(*domain.Users).Id)
+		}).Return(&domain.Users{Id: "u1"}, nil).Once()
+		mockAuditLog.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionInvitationAccepted && entry.EntityId == "inv-1" && entry.Actor == claimedBy
+		})).Return(nil).Once()
+
+		result, err := invitationService.AcceptInvitation(ctx, &domain.AcceptInvitationRequest{Token: token, Username: "staff", Password: "lion-gate-42"})
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.RoleAdmin, result.User.Role)
+		assert.NotEmpty(t, result.Tokens.AccessToken)
+		mockRepo.AssertExpectations(t)
+		mockUsers.AssertExpectations(t)
+		mockAuditLog.AssertExpectations(t)
+	})
+
+	t.Run("already used", func(t *testing.T) {
+		invitationService, mockRepo, _, _ := setup()
+		acceptedAt := time.Now()
+		mockRepo.On("GetInvitationById", ctx, "inv-1").Return(&domain.Invitation{Id: "inv-1", Role: domain.RoleAdmin, ExpiresAt: invitation.ExpiresAt, AcceptedAt: &acceptedAt}, nil).Once()
+
+		result, err := invitationService.AcceptInvitation(ctx, &domain.AcceptInvitationRequest{Token: token, Username: "staff", Password: "lion-gate-42"})
+
+		assert.ErrorIs(t, err, domain.ErrInvitationUsed)
+		assert.Nil(t, result)
+	})
+
+	t.Run("lost a concurrent accept", func(t *testing.T) {
+		invitationService, mockRepo, mockUsers, _ := setup()
+		mockRepo.On("GetInvitationById", ctx, "inv-1").Return(invitation, nil).Once()
+		mockRepo.On("AcceptInvitation", mock.Anything, "inv-1", mock.Anything, mock.Anything).Return(domain.ErrNotFound).Once()
+
+		result, err := invitationService.AcceptInvitation(ctx, &domain.AcceptInvitationRequest{Token: token, Username: "staff", Password: "lion-gate-42"})
+
+		assert.ErrorIs(t, err, domain.ErrInvitationUsed)
+		assert.Nil(t, result)
+		mockUsers.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		invitationService, mockRepo, _, _ := setup()
+		expired, err := jwtService.GenerateInvitationToken(&domain.Invitation{Id: "inv-2", Role: domain.RoleUser, ExpiresAt: time.Now().Add(-time.Minute)})
+		require.NoError(t, err)
+
+		result, err := invitationService.AcceptInvitation(ctx, &domain.AcceptInvitationRequest{Token: expired, Username: "staff", Password: "lion-gate-42"})
+
+		assert.ErrorIs(t, err, domain.ErrInvitationExpired)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "GetInvitationById", mock.Anything, mock.Anything)
+	})
+
+	t.Run("forged token", func(t *testing.T) {
+		invitationService, _, _, _ := setup()
+
+		result, err := invitationService.AcceptInvitation(ctx, &domain.AcceptInvitationRequest{Token: token + "x", Username: "staff", Password: "lion-gate-42"})
+
+		assert.ErrorIs(t, err, domain.ErrInvitationInvalid)
+		assert.Nil(t, result)
+	})
+
+	t.Run("access token is not an invitation", func(t *testing.T) {
+		invitationService, _, _, _ := setup()
+		accessToken, err := jwtService.GenerateAccessToken(&domain.Users{Id: "u1", Role: domain.RoleUser})
+		require.NoError(t, err)
+
+		result, err := invitationService.AcceptInvitation(ctx, &domain.AcceptInvitationRequest{Token: accessToken, Username: "staff", Password: "lion-gate-42"})
+
+		assert.ErrorIs(t, err, domain.ErrInvitationInvalid)
+		assert.Nil(t, result)
+	})
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+// loginThrottle applies a LoginPolicy to the failed login counters of the usernames and client IPs that log in
+type loginThrottle struct {
+	attempts port.LoginAttemptRepository
+	auditLog port.AuditLogRepository
+	policy   domain.LoginPolicy
+}
+
+// loginKeys returns the keys the failures of a login are counted under; requests without a known client IP
+// are only counted by username
+func loginKeys(ctx context.Context, username string) []string {
+	keys := []string{domain.LoginKey(domain.LoginKeyUsername, username)}
+	if ip := port.RequestMetaFromContext(ctx).IP; ip != "" {
+		keys = append(keys, domain.LoginKey(domain.LoginKeyIP, ip))
+	}
+	return keys
+}
+
+// check returns a LoginThrottledError while any of keys is delayed or locked out
+func (t loginThrottle) check(ctx context.Context, keys []string) error {
+	attempts, err := t.attempts.GetLoginAttempts(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	now := port.AuditTime()
+	throttled := &domain.LoginThrottledError{}
+	for _, attempt := range attempts {
+		if until := attempt.BlockedUntil(t.policy); until.After(now) && until.After(throttled.RetryAt) {
+			throttled.RetryAt = until
+		}
+	}
+	if throttled.RetryAt.IsZero() {
+		return nil
+	}
+	return throttled
+}
+
+// recordFailure counts a failed login under keys and locks out every key that reaches its threshold
+func (t loginThrottle) recordFailure(ctx context.Context, keys []string) error {
+	now := port.AuditTime()
+	for _, key := range keys {
+		attempt, err := t.attempts.RecordLoginFailure(ctx, key, now, now.Add(-t.policy.Window))
+		if err != nil {
+			return err
+		}
+
+		threshold := t.policy.Threshold(key)
+		if threshold <= 0 || attempt.Failures < threshold {
+			continue
+		}
+		until := now.Add(t.policy.Lockout)
+		if err := t.attempts.LockLogin(ctx, key, until); err != nil {
+			return err
+		}
+		after := map[string]any{"failures": attempt.Failures, "locked_until": until.Format(time.RFC3339)}
+		if err := recordAudit(ctx, t.auditLog, domain.AuditActionLoginLocked, domain.AuditEntityLogin, key, nil, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reset forgets the failures of key after a successful login
+func (t loginThrottle) reset(ctx context.Context, key string) error {
+	if err := t.attempts.ClearLoginAttempts(ctx, key); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+type LoginLockoutService struct {
+	attempts   port.LoginAttemptRepository
+	auditLog   port.AuditLogRepository
+	unitOfWork port.UnitOfWork
+}
+
+func NewLoginLockoutService(attempts port.LoginAttemptRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork) *LoginLockoutService {
+	return &LoginLockoutService{attempts: attempts, auditLog: auditLog, unitOfWork: unitOfWork}
+}
+
+// ListLockouts lists the usernames and client IPs that are locked out right now
+func (s *LoginLockoutService) ListLockouts(ctx context.Context) ([]domain.LoginAttempt, error) {
+	return s.attempts.ListLoginLockouts(ctx, port.AuditTime())
+}
+
+// Unlock clears the failed logins of a username or client IP, so that it may log in again at once
+func (s *LoginLockoutService) Unlock(ctx context.Context, kind string, value string) error {
+	var v domain.Validation
+	v.OneOf("kind", kind, domain.LoginKeyUsername, domain.LoginKeyIP)
+	v.Required("value", value)
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	key := domain.LoginKey(kind, value)
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		attempts, err := s.attempts.GetLoginAttempts(ctx, []string{key})
+		if err != nil {
+			return err
+		}
+		if len(attempts) == 0 {
+			return domain.ErrNotFound
+		}
+
+		if err := s.attempts.ClearLoginAttempts(ctx, key); err != nil {
+			return err
+		}
+		before := map[string]any{"failures": attempts[0].Failures}
+		if attempts[0].LockedUntil != nil {
+			before["locked_until"] = attempts[0].LockedUntil.Format(time.RFC3339)
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionLoginUnlocked, domain.AuditEntityLogin, key, before, nil)
+	})
+}
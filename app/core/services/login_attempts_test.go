@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockLoginAttemptRepository is a mock of LoginAttemptRepository interface
+type MockLoginAttemptRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginAttemptRepository) GetLoginAttempts(ctx context.Context, keys []string) ([]domain.LoginAttempt, error) {
+	args := m.Called(ctx, keys)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.LoginAttempt), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, at time.Time, resetBefore time.Time) (*domain.LoginAttempt, error) {
+	args := m.Called(ctx, key, at, resetBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoginAttempt), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	args := m.Called(ctx, key, until)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptRepository) ClearLoginAttempts(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptRepository) ListLoginLockouts(ctx context.Context, now time.Time) ([]domain.LoginAttempt, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.LoginAttempt), args.Error(1)
+}
+
+// testLoginPolicy locks a username out after 3 failures and an IP after 10
+var testLoginPolicy = domain.LoginPolicy{
+	MaxFailures:      3,
+	MaxFailuresPerIP: 10,
+	Window:           15 * time.Minute,
+	Lockout:          15 * time.Minute,
+	BaseDelay:        time.Second,
+	MaxDelay:         30 * time.Second,
+}
+
+// quietLoginAttempts returns login counters that never throttle, for tests about other parts of login
+func quietLoginAttempts() *MockLoginAttemptRepository {
+	attempts := new(MockLoginAttemptRepository)
+	attempts.On("GetLoginAttempts", mock.Anything, mock.Anything).Return([]domain.LoginAttempt{}, nil)
+	attempts.On("RecordLoginFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&domain.LoginAttempt{Failures: 1}, nil)
+	attempts.On("ClearLoginAttempts", mock.Anything, mock.Anything).Return(domain.ErrNotFound)
+	return attempts
+}
+
+func TestLoginThrottle(t *testing.T) {
+	jwtService := newTestJWTService(t)
+
+	hashedPassword, err := testHasher.Hash("password")
+	require.NoError(t, err)
+	ctx := port.WithRequestMeta(context.Background(), port.RequestMeta{IP: "203.0.113.7"})
+	keys := []string{"username:alice", "ip:203.0.113.7"}
+
+	setup := func() (*AuthService, *MockUsersRepository, *MockLoginAttemptRepository, *MockAuditLogRepository) {
+		users := new(MockUsersRepository)
+		attempts := new(MockLoginAttemptRepository)
+		auditLog := new(MockAuditLogRepository)
+		return NewAuthService(users, attempts, noMfa(), noRevocations(), auditLog, jwtService, testHasher, testLoginPolicy, testMfaTTL, "Liongate"), users, attempts, auditLog
+	}
+
+	t.Run("locked out username is refused before the password is checked", func(t *testing.T) {
+		authService, users, attempts, auditLog := setup()
+		lockedUntil := time.Now().Add(10 * time.Minute)
+		attempts.On("GetLoginAttempts", ctx, keys).Return([]domain.LoginAttempt{
+			{Key: "username:alice", Failures: 3, LastFailureAt: time.Now(), LockedUntil: &lockedUntil},
+		}, nil).Once()
+		auditLog.On("Append", ctx, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionLoginFailed && entry.After["reason"] == "throttled"
+		})).Return(nil).Once()
+
+		result, err := authService.Login(ctx, &domain.LoginRequest{Username: "alice", Password: "password"})
+
+		var throttled *domain.LoginThrottledError
+		require.ErrorAs(t, err, &throttled)
+		assert.ErrorIs(t, err, domain.ErrLoginThrottled)
+		assert.WithinDuration(t, lockedUntil, throttled.RetryAt, time.Millisecond)
+		assert.Nil(t, result)
+		users.AssertNotCalled(t, "GetUserByUsername", mock.Anything, mock.Anything)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("delay grows with every failure", func(t *testing.T) {
+		authService, _, attempts, auditLog := setup()
+		lastFailure := time.Now()
+		attempts.On("GetLoginAttempts", ctx, keys).Return([]domain.LoginAttempt{
+			{Key: "username:alice", Failures: 2, LastFailureAt: lastFailure},
+		}, nil).Once()
+		auditLog.On("Append", ctx, mock.Anything).Return(nil).Once()
+
+		_, err := authService.Login(ctx, &domain.LoginRequest{Username: "alice", Password: "password"})
+
+		var throttled *domain.LoginThrottledError
+		require.ErrorAs(t, err, &throttled)
+		assert.WithinDuration(t, lastFailure.Add(2*time.Second), throttled.RetryAt, time.Millisecond)
+	})
+
+	t.Run("failure is counted per username and per IP", func(t *testing.T) {
+		authService, users, attempts, auditLog := setup()
+		attempts.On("GetLoginAttempts", ctx, keys).Return([]domain.LoginAttempt{}, nil).Once()
+		users.On("GetUserByUsername", ctx, "alice").Return(&domain.Users{Id: "1", Username: "alice", Password: hashedPassword}, nil).Once()
+		attempts.On("RecordLoginFailure", ctx, "username:alice", mock.Anything, mock.Anything).Return(&domain.LoginAttempt{Key: "username:alice", Failures: 1}, nil).Once()
+		attempts.On("RecordLoginFailure", ctx, "ip:203.0.113.7", mock.Anything, mock.Anything).Return(&domain.LoginAttempt{Key: "ip:203.0.113.7", Failures: 1}, nil).Once()
+		auditLog.On("Append", ctx, auditEntry(domain.AuditActionLoginFailed, "1", nil, map[string]any{"username": "alice", "reason": "invalid password"})).Return(nil).Once()
+
+		result, err := authService.Login(ctx, &domain.LoginRequest{Username: "alice", Password: "wrong"})
+
+		assert.Equal(t, domain.ErrInvalidCredentials, err)
+		assert.Nil(t, result)
+		attempts.AssertExpectations(t)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("reaching the threshold locks out", func(t *testing.T) {
+		authService, users, attempts, auditLog := setup()
+		attempts.On("GetLoginAttempts", ctx, keys).Return([]domain.LoginAttempt{}, nil).Once()
+		users.On("GetUserByUsername", ctx, "alice").Return(nil, domain.ErrNotFound).Once()
+		attempts.On("RecordLoginFailure", ctx, "username:alice", mock.Anything, mock.Anything).Return(&domain.LoginAttempt{Key: "username:alice", Failures: 3}, nil).Once()
+		attempts.On("RecordLoginFailure", ctx, "ip:203.0.113.7", mock.Anything, mock.Anything).Return(&domain.LoginAttempt{Key: "ip:203.0.113.7", Failures: 3}, nil).Once()
+		attempts.On("LockLogin", ctx, "username:alice", mock.MatchedBy(func(until time.Time) bool {
+			return time.Until(until) > 14*time.Minute
+		})).Return(nil).Once()
+		auditLog.On("Append", ctx, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionLoginLocked && entry.EntityType == domain.AuditEntityLogin && entry.EntityId == "username:alice"
+		})).Return(nil).Once()
+		auditLog.On("Append", ctx, auditEntry(domain.AuditActionLoginFailed, "", nil, map[string]any{"username": "alice", "reason": "unknown username"})).Return(nil).Once()
+
+		result, err := authService.Login(ctx, &domain.LoginRequest{Username: "alice", Password: "password"})
+
+		assert.Equal(t, domain.ErrInvalidCredentials, err)
+		assert.Nil(t, result)
+		attempts.AssertExpectations(t)
+		attempts.AssertNotCalled(t, "LockLogin", ctx, "ip:203.0.113.7", mock.Anything)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("success clears the username but not the IP", func(t *testing.T) {
+		authService, users, attempts, auditLog := setup()
+		attempts.On("GetLoginAttempts", ctx, keys).Return([]domain.LoginAttempt{
+			{Key: "ip:203.0.113.7", Failures: 4, LastFailureAt: time.Now()},
+		}, nil).Once()
+		users.On("GetUserByUsername", ctx, "alice").Return(&domain.Users{Id: "1", Username: "alice", Password: hashedPassword}, nil).Once()
+		attempts.On("ClearLoginAttempts", ctx, "username:alice").Return(nil).Once()
+		auditLog.On("Append", mock.Anything, mock.Anything).Return(nil).Once()
+
+		result, err := authService.Login(ctx, &domain.LoginRequest{Username: "alice", Password: "password"})
+
+		require.NoError(t, err)
+		assert.NotNil(t, result.Tokens)
+		attempts.AssertExpectations(t)
+		attempts.AssertNotCalled(t, "ClearLoginAttempts", ctx, "ip:203.0.113.7")
+	})
+}
+
+func TestUnlockLogin(t *testing.T) {
+	ctx := port.WithActor(context.Background(), "admin-1")
+
+	t.Run("success", func(t *testing.T) {
+		attempts := new(MockLoginAttemptRepository)
+		auditLog := new(MockAuditLogRepository)
+		lockoutService := NewLoginLockoutService(attempts, auditLog, stubUnitOfWork{})
+		lockedUntil := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		attempts.On("GetLoginAttempts", ctx, []string{"username:alice"}).Return([]domain.LoginAttempt{
+			{Key: "username:alice", Failures: 5, LockedUntil: &lockedUntil},
+		}, nil).Once()
+		attempts.On("ClearLoginAttempts", ctx, "username:alice").Return(nil).Once()
+		auditLog.On("Append", ctx, auditEntry(domain.AuditActionLoginUnlocked, "username:alice",
+			map[string]any{"failures": 5, "locked_until": "2025-01-01T12:00:00Z"}, nil,
+		)).Return(nil).Once()
+
+		err := lockoutService.Unlock(ctx, domain.LoginKeyUsername, "alice")
+
+		assert.NoError(t, err)
+		attempts.AssertExpectations(t)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("not locked", func(t *testing.T) {
+		attempts := new(MockLoginAttemptRepository)
+		lockoutService := NewLoginLockoutService(attempts, new(MockAuditLogRepository), stubUnitOfWork{})
+		attempts.On("GetLoginAttempts", ctx, []string{"ip:203.0.113.7"}).Return([]domain.LoginAttempt{}, nil).Once()
+
+		err := lockoutService.Unlock(ctx, domain.LoginKeyIP, "203.0.113.7")
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		attempts.AssertNotCalled(t, "ClearLoginAttempts", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		lockoutService := NewLoginLockoutService(new(MockLoginAttemptRepository), new(MockAuditLogRepository), stubUnitOfWork{})
+
+		err := lockoutService.Unlock(ctx, "email", "alice@example.com")
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
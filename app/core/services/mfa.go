@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+)
+
+// recoveryCodeCount is how many recovery codes an enrollment gets when it is confirmed or its codes are renewed
+const recoveryCodeCount = 10
+
+// Ways a second factor can be proven, as recorded in the audit log
+const (
+	mfaMethodTOTP         = "totp"
+	mfaMethodRecoveryCode = "recovery_code"
+)
+
+// mfaCodes enrolls users in TOTP and checks their codes. Wrong codes count against the same throttle as failed
+// logins, so that the six digits of a code cannot be guessed by trying them all.
+type mfaCodes struct {
+	mfa      port.MfaRepository
+	auditLog port.AuditLogRepository
+	throttle loginThrottle
+	issuer   string
+}
+
+// start stores a new pending enrollment for user, replacing any earlier pending one, and returns its setup
+func (c mfaCodes) start(ctx context.Context, user *domain.Users) (*domain.MfaSetup, error) {
+	enrollment, err := c.mfa.GetMfaEnrollment(ctx, user.Id)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if enrollment != nil && enrollment.Confirmed() {
+		return nil, domain.ErrMfaAlreadyEnabled
+	}
+
+	setup, err := utils.GenerateTOTP(c.issuer, user.Username)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.mfa.SaveMfaEnrollment(ctx, &domain.MfaEnrollment{UserId: user.Id, Secret: setup.Secret}); err != nil {
+		return nil, err
+	}
+	return setup, nil
+}
+
+// confirm puts the pending enrollment of user into effect with a first code from the authenticator app
+// and returns the recovery codes, which are only ever shown this once
+func (c mfaCodes) confirm(ctx context.Context, user *domain.Users, enrollment *domain.MfaEnrollment, code string) ([]string, error) {
+	keys := loginKeys(ctx, user.Username)
+	if err := c.throttle.check(ctx, keys); err != nil {
+		return nil, err
+	}
+
+	step, ok := utils.MatchTOTP(enrollment.Secret, code, port.AuditTime())
+	if !ok {
+		if err := c.throttle.recordFailure(ctx, keys); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrMfaCodeInvalid
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	confirmedAt := port.AuditTime()
+	enrollment.ConfirmedAt = &confirmedAt
+	enrollment.LastStep = step
+	enrollment.RecoveryCodes = hashes
+	if err := c.mfa.SaveMfaEnrollment(ctx, enrollment); err != nil {
+		return nil, err
+	}
+	if err := recordAudit(ctx, c.auditLog, domain.AuditActionMfaEnabled, domain.AuditEntityUser, user.Id, nil, map[string]any{"method": mfaMethodTOTP}); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// verify checks a TOTP code or, when one is given, a recovery code against the confirmed enrollment of user and
+// uses it up. It returns the method that was used, or ErrMfaCodeInvalid after counting the wrong code.
+func (c mfaCodes) verify(ctx context.Context, user *domain.Users, enrollment *domain.MfaEnrollment, req domain.MfaCodeRequest) (string, error) {
+	keys := loginKeys(ctx, user.Username)
+	if err := c.throttle.check(ctx, keys); err != nil {
+		return "", err
+	}
+
+	method, err := c.use(ctx, enrollment, req)
+	if errors.Is(err, domain.ErrMfaCodeInvalid) {
+		if err := c.throttle.recordFailure(ctx, keys); err != nil {
+			return "", err
+		}
+		return "", domain.ErrMfaCodeInvalid
+	}
+	if err != nil {
+		return "", err
+	}
+	return method, nil
+}
+
+// use marks the code of req as used, so that it cannot be used again
+func (c mfaCodes) use(ctx context.Context, enrollment *domain.MfaEnrollment, req domain.MfaCodeRequest) (string, error) {
+	if req.RecoveryCode != "" {
+		err := c.mfa.UseRecoveryCode(ctx, enrollment.UserId, utils.HashRecoveryCode(req.RecoveryCode))
+		if errors.Is(err, domain.ErrNotFound) {
+			return "", domain.ErrMfaCodeInvalid
+		}
+		return mfaMethodRecoveryCode, err
+	}
+
+	step, ok := utils.MatchTOTP(enrollment.Secret, req.Code, port.AuditTime())
+	if !ok {
+		return "", domain.ErrMfaCodeInvalid
+	}
+	err := c.mfa.UseMfaStep(ctx, enrollment.UserId, step)
+	if errors.Is(err, domain.ErrNotFound) {
+		return "", fmt.Errorf("%w: code was already used", domain.ErrMfaCodeInvalid)
+	}
+	return mfaMethodTOTP, err
+}
+
+// newRecoveryCodes generates a set of recovery codes and returns them with the hashes they are stored as
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashRecoveryCode(code)
+	}
+	return codes, hashes, nil
+}
+
+type MfaService struct {
+	users      port.UsersRepository
+	mfa        port.MfaRepository
+	auditLog   port.AuditLogRepository
+	unitOfWork port.UnitOfWork
+	codes      mfaCodes
+}
+
+// NewMfaService creates the MFA service; wrong codes are throttled like failed logins under policy,
+// and authenticator apps list the accounts under issuer
+func NewMfaService(users port.UsersRepository, mfa port.MfaRepository, loginAttempts port.LoginAttemptRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork, policy domain.LoginPolicy, issuer string) *MfaService {
+	return &MfaService{
+		users:      users,
+		mfa:        mfa,
+		auditLog:   auditLog,
+		unitOfWork: unitOfWork,
+		codes: mfaCodes{
+			mfa:      mfa,
+			auditLog: auditLog,
+			throttle: loginThrottle{attempts: loginAttempts, auditLog: auditLog, policy: policy},
+			issuer:   issuer,
+		},
+	}
+}
+
+// Enroll starts setting up TOTP for the calling user; it takes effect once confirmed with a code
+func (s *MfaService) Enroll(ctx context.Context) (*domain.MfaSetup, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.codes.start(ctx, user)
+}
+
+// Confirm puts the pending enrollment of the calling user into effect and returns their recovery codes
+func (s *MfaService) Confirm(ctx context.Context, code string) ([]string, error) {
+	var v domain.Validation
+	v.Required("code", code)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := s.mfa.GetMfaEnrollment(ctx, user.Id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrMfaNotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if enrollment.Confirmed() {
+		return nil, domain.ErrMfaAlreadyEnabled
+	}
+	return s.codes.confirm(ctx, user, enrollment, code)
+}
+
+// RenewRecoveryCodes replaces the recovery codes of the calling user, such as after they used some of them
+func (s *MfaService) RenewRecoveryCodes(ctx context.Context, req *domain.MfaCodeRequest) ([]string, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	user, enrollment, err := s.currentEnrollment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.codes.verify(ctx, user, enrollment, *req); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		// Read again, so that the time step the code just used up is kept
+		enrollment, err := s.mfa.GetMfaEnrollment(ctx, user.Id)
+		if err != nil {
+			return err
+		}
+		enrollment.RecoveryCodes = hashes
+		if err := s.mfa.SaveMfaEnrollment(ctx, enrollment); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionMfaCodesRenewed, domain.AuditEntityUser, user.Id, nil, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns off two-factor authentication for the calling user, unless their role requires it
+func (s *MfaService) Disable(ctx context.Context, req *domain.MfaCodeRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	user, enrollment, err := s.currentEnrollment(ctx)
+	if err != nil {
+		return err
+	}
+	required, err := s.required(ctx, user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return domain.ErrMfaRequired
+	}
+	method, err := s.codes.verify(ctx, user, enrollment, *req)
+	if err != nil {
+		return err
+	}
+
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := s.mfa.DeleteMfaEnrollment(ctx, user.Id); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionMfaDisabled, domain.AuditEntityUser, user.Id, nil, map[string]any{"method": method})
+	})
+}
+
+// Reset removes the second factor of a user, who must enroll again if their role requires it
+func (s *MfaService) Reset(ctx context.Context, userId string) error {
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := s.mfa.DeleteMfaEnrollment(ctx, userId); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionMfaReset, domain.AuditEntityUser, userId, nil, nil)
+	})
+}
+
+// ListRequirements lists the roles whose users must log in with a second factor
+func (s *MfaService) ListRequirements(ctx context.Context) ([]domain.MfaRequirement, error) {
+	return s.mfa.ListMfaRequirements(ctx)
+}
+
+// SetRequirement requires a second factor for a role, or stops requiring it. Users of the role who have none
+// are made to enroll at their next login; sessions that are already open are left alone.
+func (s *MfaService) SetRequirement(ctx context.Context, req *domain.MfaRequirementRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		required, err := s.required(ctx, req.Role)
+		if err != nil {
+			return err
+		}
+		if required == req.Required {
+			return nil
+		}
+
+		if req.Required {
+			err = s.mfa.SaveMfaRequirement(ctx, &domain.MfaRequirement{Role: req.Role})
+		} else {
+			err = s.mfa.DeleteMfaRequirement(ctx, req.Role)
+		}
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionMfaRequirement, domain.AuditEntityRole, req.Role,
+			map[string]any{"mfa_required": required}, map[string]any{"mfa_required": req.Required})
+	})
+}
+
+// required reports whether role requires a second factor
+func (s *MfaService) required(ctx context.Context, role string) (bool, error) {
+	_, err := s.mfa.GetMfaRequirement(ctx, role)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// currentUser returns the user making the request
+func (s *MfaService) currentUser(ctx context.Context) (*domain.Users, error) {
+	actor := port.ActorFromContext(ctx)
+	if actor == domain.AnonymousActor {
+		return nil, domain.ErrUnauthorized
+	}
+	user, err := s.users.GetUserById(ctx, actor)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrUnauthorized
+	}
+	return user, err
+}
+
+// currentEnrollment returns the user making the request with their confirmed enrollment
+func (s *MfaService) currentEnrollment(ctx context.Context) (*domain.Users, *domain.MfaEnrollment, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	enrollment, err := s.mfa.GetMfaEnrollment(ctx, user.Id)
+	if errors.Is(err, domain.ErrNotFound) || (err == nil && !enrollment.Confirmed()) {
+		return nil, nil, domain.ErrMfaNotEnabled
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, enrollment, nil
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockMfaRepository is a mock of MfaRepository interface
+type MockMfaRepository struct {
+	mock.Mock
+}
+
+func (m *MockMfaRepository) GetMfaEnrollment(ctx context.Context, userId string) (*domain.MfaEnrollment, error) {
+	args := m.Called(ctx, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MfaEnrollment), args.Error(1)
+}
+
+func (m *MockMfaRepository) SaveMfaEnrollment(ctx context.Context, enrollment *domain.MfaEnrollment) error {
+	args := m.Called(ctx, enrollment)
+	return args.Error(0)
+}
+
+func (m *MockMfaRepository) DeleteMfaEnrollment(ctx context.Context, userId string) error {
+	args := m.Called(ctx, userId)
+	return args.Error(0)
+}
+
+func (m *MockMfaRepository) UseMfaStep(ctx context.Context, userId string, step int64) error {
+	args := m.Called(ctx, userId, step)
+	return args.Error(0)
+}
+
+func (m *MockMfaRepository) UseRecoveryCode(ctx context.Context, userId string, codeHash string) error {
+	args := m.Called(ctx, userId, codeHash)
+	return args.Error(0)
+}
+
+func (m *MockMfaRepository) GetMfaRequirement(ctx context.Context, role string) (*domain.MfaRequirement, error) {
+	args := m.Called(ctx, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MfaRequirement), args.Error(1)
+}
+
+func (m *MockMfaRepository) ListMfaRequirements(ctx context.Context) ([]domain.MfaRequirement, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.MfaRequirement), args.Error(1)
+}
+
+func (m *MockMfaRepository) SaveMfaRequirement(ctx context.Context, requirement *domain.MfaRequirement) error {
+	args := m.Called(ctx, requirement)
+	return args.Error(0)
+}
+
+func (m *MockMfaRepository) DeleteMfaRequirement(ctx context.Context, role string) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+
+// testMfaTTL is how long the logins of the tests wait for a second factor
+const testMfaTTL = 5 * time.Minute
+
+// testRecoveryCode is the only recovery code left in the enrollments of confirmedEnrollment
+const testRecoveryCode = "abcde-fghjk"
+
+// noMfa returns an MFA store where no user has a second factor and no role requires one
+func noMfa() *MockMfaRepository {
+	mfa := new(MockMfaRepository)
+	mfa.On("GetMfaEnrollment", mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound)
+	mfa.On("GetMfaRequirement", mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound)
+	return mfa
+}
+
+// confirmedEnrollment returns an enrollment of userId that is in effect with a fresh secret
+func confirmedEnrollment(t *testing.T, userId string) *domain.MfaEnrollment {
+	setup, err := utils.GenerateTOTP("Liongate", userId)
+	require.NoError(t, err)
+	confirmedAt := time.Now()
+	return &domain.MfaEnrollment{
+		UserId:        userId,
+		Secret:        setup.Secret,
+		ConfirmedAt:   &confirmedAt,
+		RecoveryCodes: []string{utils.HashRecoveryCode(testRecoveryCode)},
+	}
+}
+
+// currentCode returns the code an authenticator app shows for secret right now
+func currentCode(t *testing.T, secret string) string {
+	code, err := utils.TOTPCode(secret, time.Now())
+	require.NoError(t, err)
+	return code
+}
+
+// wrongCode returns a code that differs from the current one for secret
+func wrongCode(t *testing.T, secret string) string {
+	code, err := strconv.Atoi(currentCode(t, secret))
+	require.NoError(t, err)
+	return fmt.Sprintf("%06d", (code+500000)%1000000)
+}
+
+// auditAction matches audit log entries of action
+func auditAction(action string) any {
+	return mock.MatchedBy(func(entry *domain.AuditLogEntry) bool { return entry.Action == action })
+}
+
+func TestMfaLogin(t *testing.T) {
+	jwtService := newTestJWTService(t)
+	hashedPassword, err := testHasher.Hash("password")
+	require.NoError(t, err)
+	alice := &domain.Users{Id: "1", Username: "alice", Password: hashedPassword, Role: domain.RoleAdmin}
+	usernameKey := domain.LoginKey(domain.LoginKeyUsername, "alice")
+	ctx := context.Background()
+
+	setup := func() (*AuthService, *MockMfaRepository, *MockLoginAttemptRepository, *MockAuditLogRepository) {
+		users := new(MockUsersRepository)
+		users.On("GetUserByUsername", ctx, "alice").Return(alice, nil).Maybe()
+		users.On("GetUserById", ctx, "1").Return(alice, nil).Maybe()
+		mfa := new(MockMfaRepository)
+		attempts := quietLoginAttempts()
+		auditLog := new(MockAuditLogRepository)
+		return NewAuthService(users, attempts, mfa, noRevocations(), auditLog, jwtService, testHasher, testLoginPolicy, testMfaTTL, "Liongate"), mfa, attempts, auditLog
+	}
+	mfaToken := func(t *testing.T) string {
+		token, err := jwtService.GenerateMfaToken(alice, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("a second factor holds back the tokens", func(t *testing.T) {
+		authService, mfa, attempts, auditLog := setup()
+		mfa.On("GetMfaEnrollment", ctx, "1").Return(confirmedEnrollment(t, "1"), nil)
+
+		response, err := authService.Login(ctx, &domain.LoginRequest{Username: "alice", Password: "password"})
+
+		require.NoError(t, err)
+		assert.Nil(t, response.Tokens)
+		assert.Nil(t, response.User)
+		require.NotNil(t, response.Mfa)
+		assert.False(t, response.Mfa.EnrollmentRequired)
+		claims, err := jwtService.VerifyMfaToken(response.Mfa.Token)
+		require.NoError(t, err)
+		assert.Equal(t, "1", claims.UserID)
+		_, err = jwtService.VerifyAccessToken(response.Mfa.Token)
+		assert.Error(t, err, "an mfa token is not an access token")
+		attempts.AssertNotCalled(t, "ClearLoginAttempts", mock.Anything, mock.Anything)
+		auditLog.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+	})
+
+	t.Run("a valid code completes the login", func(t *testing.T) {
+		authService, mfa, attempts, auditLog := setup()
+		enrollment := confirmedEnrollment(t, "1")
+		mfa.On("GetMfaEnrollment", ctx, "1").Return(enrollment, nil)
+		mfa.On("UseMfaStep", ctx, "1", mock.AnythingOfType("int64")).Return(nil).Once()
+		auditLog.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionLogin && entry.Actor == "1" && entry.After["mfa"] == mfaMethodTOTP
+		})).Return(nil).Once()
+
+		response, err := authService.VerifyMfa(ctx, &domain.MfaLoginRequest{Token: mfaToken(t), Code: currentCode(t, enrollment.Secret)})
+
+		require.NoError(t, err)
+		require.NotNil(t, response.Tokens)
+		assert.Equal(t, alice, response.User)
+		assert.Empty(t, response.RecoveryCodes)
+		attempts.AssertCalled(t, "ClearLoginAttempts", ctx, usernameKey)
+		mfa.AssertExpectations(t)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("a used code is refused", func(t *testing.T) {
+		authService, mfa, attempts, auditLog := setup()
+		enrollment := confirmedEnrollment(t, "1")
+		mfa.On("GetMfaEnrollment", ctx, "1").Return(enrollment, nil)
+		mfa.On("UseMfaStep", ctx, "1", mock.Anything).Return(domain.ErrNotFound).Once()
+		auditLog.On("Append", ctx, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionLoginFailed && entry.After["reason"] == "invalid mfa code"
+		})).Return(nil).Once()
+
+		response, err := authService.VerifyMfa(ctx, &domain.MfaLoginRequest{Token: mfaToken(t), Code: currentCode(t, enrollment.Secret)})
+
+		assert.ErrorIs(t, err, domain.ErrMfaCodeInvalid)
+		assert.Nil(t, response)
+		attempts.AssertCalled(t, "RecordLoginFailure", ctx, usernameKey, mock.Anything, mock.Anything)
+		attempts.AssertNotCalled(t, "ClearLoginAttempts", mock.Anything, mock.Anything)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("a wrong code counts as a failed login", func(t *testing.T) {
+		authService, mfa, attempts, auditLog := setup()
+		enrollment := confirmedEnrollment(t, "1")
+		mfa.On("GetMfaEnrollment", ctx, "1").Return(enrollment, nil)
+		auditLog.On("Append", ctx, auditAction(domain.AuditActionLoginFailed)).Return(nil).Once()
+
+		_, err := authService.VerifyMfa(ctx, &domain.MfaLoginRequest{Token: mfaToken(t), Code: wrongCode(t, enrollment.Secret)})
+
+		assert.Equal(t, domain.ErrMfaCodeInvalid, err)
+		attempts.AssertCalled(t, "RecordLoginFailure", ctx, usernameKey, mock.Anything, mock.Anything)
+		mfa.AssertNotCalled(t, "UseMfaStep", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("a recovery code stands in for a code", func(t *testing.T) {
+		authService, mfa, _, auditLog := setup()
+		mfa.On("GetMfaEnrollment", ctx, "1").Return(confirmedEnrollment(t, "1"), nil)
+		mfa.On("UseRecoveryCode", ctx, "1", utils.HashRecoveryCode(testRecoveryCode)).Return(nil).Once()
+		auditLog.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionLogin && entry.After["mfa"] == mfaMethodRecoveryCode
+		})).Return(nil).Once()
+
+		response, err := authService.VerifyMfa(ctx, &domain.MfaLoginRequest{Token: mfaToken(t), RecoveryCode: "ABCDE FGHJK"})
+
+		require.NoError(t, err)
+		assert.NotNil(t, response.Tokens)
+		mfa.AssertExpectations(t)
+	})
+
+	t.Run("a role that requires MFA makes the user enroll", func(t *testing.T) {
+		authService, mfa, _, auditLog := setup()
+		mfa.On("GetMfaEnrollment", ctx, "1").Return(nil, domain.ErrNotFound).Twice()
+		mfa.On("GetMfaRequirement", ctx, domain.RoleAdmin).Return(&domain.MfaRequirement{Role: domain.RoleAdmin}, nil).Once()
+
+		response, err := authService.Login(ctx, &domain.LoginRequest{Username: "alice", Password: "password"})
+		require.NoError(t, err)
+		require.NotNil(t, response.Mfa)
+		assert.True(t, response.Mfa.EnrollmentRequired)
+		assert.Nil(t, response.Tokens)
+
+		var pending *domain.MfaEnrollment
+		mfa.On("SaveMfaEnrollment", ctx, mock.MatchedBy(func(enrollment *domain.MfaEnrollment) bool {
+			return !enrollment.Confirmed() && enrollment.Secret != ""
+		})).Run(func(args mock.Arguments) {
+			pending = args.Get(1).(*domain.MfaEnrollment)
+		}).Return(nil).Once()
+
+		setup, err := authService.EnrollMfa(ctx, response.Mfa.Token)
+		require.NoError(t, err)
+		assert.Contains(t, setup.URI, "otpauth://totp/Liongate:alice")
+		assert.NotEmpty(t, setup.QRCode)
+		require.NotNil(t, pending)
+		assert.Equal(t, setup.Secret, pending.Secret)
+
+		mfa.On("GetMfaEnrollment", ctx, "1").Return(pending, nil).Once()
+		mfa.On("SaveMfaEnrollment", ctx, mock.MatchedBy(func(enrollment *domain.MfaEnrollment) bool {
+			return enrollment.Confirmed() && len(enrollment.RecoveryCodes) == recoveryCodeCount
+		})).Return(nil).Once()
+		auditLog.On("Append", ctx, auditAction(domain.AuditActionMfaEnabled)).Return(nil).Once()
+		auditLog.On("Append", mock.Anything, auditAction(domain.AuditActionLogin)).Return(nil).Once()
+
+		response, err = authService.VerifyMfa(ctx, &domain.MfaLoginRequest{Token: response.Mfa.Token, Code: currentCode(t, setup.Secret)})
+
+		require.NoError(t, err)
+		assert.NotNil(t, response.Tokens)
+		require.Len(t, response.RecoveryCodes, recoveryCodeCount)
+		assert.Contains(t, pending.RecoveryCodes, utils.HashRecoveryCode(response.RecoveryCodes[0]))
+		mfa.AssertExpectations(t)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("an access token cannot continue a login", func(t *testing.T) {
+		authService, _, _, _ := setup()
+		accessToken, err := jwtService.GenerateAccessToken(alice)
+		require.NoError(t, err)
+
+		_, err = authService.VerifyMfa(ctx, &domain.MfaLoginRequest{Token: accessToken, Code: "123456"})
+
+		assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	})
+}
+
+func TestMfaService(t *testing.T) {
+	alice := &domain.Users{Id: "1", Username: "alice", Role: domain.RoleAdmin}
+	ctx := port.WithActor(context.Background(), "1")
+
+	setup := func() (*MfaService, *MockMfaRepository, *MockAuditLogRepository) {
+		users := new(MockUsersRepository)
+		users.On("GetUserById", ctx, "1").Return(alice, nil).Maybe()
+		mfa := new(MockMfaRepository)
+		auditLog := new(MockAuditLogRepository)
+		return NewMfaService(users, mfa, quietLoginAttempts(), auditLog, stubUnitOfWork{}, testLoginPolicy, "Liongate"), mfa, auditLog
+	}
+
+	t.Run("an anonymous caller cannot enroll", func(t *testing.T) {
+		mfaService, _, _ := setup()
+
+		_, err := mfaService.Enroll(context.Background())
+
+		assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	})
+
+	t.Run("enrolling again needs the second factor off first", func(t *testing.T) {
+		mfaService, mfa, _ := setup()
+		mfa.On("GetMfaEnrollment", ctx, "1").Return(confirmedEnrollment(t, "1"), nil)
+
+		_, err := mfaService.Enroll(ctx)
+
+		assert.ErrorIs(t, err, domain.ErrMfaAlreadyEnabled)
+		mfa.AssertNotCalled(t, "SaveMfaEnrollment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("a required second factor cannot be turned off", func(t *testing.T) {
+		mfaService, mfa, _ := setup()
+		enrollment := confirmedEnrollment(t, "1")
+		mfa.On("GetMfaEnrollment", ctx, "1").Return(enrollment, nil)
+		mfa.On("GetMfaRequirement", ctx, domain.RoleAdmin).Return(&domain.MfaRequirement{Role: domain.RoleAdmin}, nil)
+
+		err := mfaService.Disable(ctx, &domain.MfaCodeRequest{Code: currentCode(t, enrollment.Secret)})
+
+		assert.ErrorIs(t, err, domain.ErrMfaRequired)
+		mfa.AssertNotCalled(t, "DeleteMfaEnrollment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("disable with a code", func(t *testing.T) {
+		mfaService, mfa, auditLog := setup()
+		enrollment := confirmedEnrollment(t, "1")
+		mfa.On("GetMfaEnrollment", ctx, "1").Return(enrollment, nil)
+		mfa.On("GetMfaRequirement", ctx, domain.RoleAdmin).Return(nil, domain.ErrNotFound)
+		mfa.On("UseMfaStep", ctx, "1", mock.Anything).Return(nil).Once()
+		mfa.On("DeleteMfaEnrollment", ctx, "1").Return(nil).Once()
+		auditLog.On("Append", ctx, auditAction(domain.AuditActionMfaDisabled)).Return(nil).Once()
+
+		err := mfaService.Disable(ctx, &domain.MfaCodeRequest{Code: currentCode(t, enrollment.Secret)})
+
+		require.NoError(t, err)
+		mfa.AssertExpectations(t)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("renew recovery codes", func(t *testing.T) {
+		mfaService, mfa, auditLog := setup()
+		mfa.On("GetMfaEnrollment", ctx, "1").Return(confirmedEnrollment(t, "1"), nil)
+		mfa.On("UseRecoveryCode", ctx, "1", utils.HashRecoveryCode(testRecoveryCode)).Return(nil).Once()
+		var saved *domain.MfaEnrollment
+		mfa.On("SaveMfaEnrollment", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*domain.MfaEnrollment)
+		}).Return(nil).Once()
+		auditLog.On("Append", ctx, auditAction(domain.AuditActionMfaCodesRenewed)).Return(nil).Once()
+
+		codes, err := mfaService.RenewRecoveryCodes(ctx, &domain.MfaCodeRequest{RecoveryCode: testRecoveryCode})
+
+		require.NoError(t, err)
+		require.Len(t, codes, recoveryCodeCount)
+		require.NotNil(t, saved)
+		for _, code := range codes {
+			assert.Contains(t, saved.RecoveryCodes, utils.HashRecoveryCode(code))
+		}
+		assert.True(t, saved.Confirmed())
+	})
+
+	t.Run("requiring MFA for a role is only recorded when it changes", func(t *testing.T) {
+		mfaService, mfa, auditLog := setup()
+		mfa.On("GetMfaRequirement", ctx, domain.RoleAdmin).Return(nil, domain.ErrNotFound).Once()
+		mfa.On("SaveMfaRequirement", ctx, &domain.MfaRequirement{Role: domain.RoleAdmin}).Return(nil).Once()
+		auditLog.On("Append", ctx, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionMfaRequirement && entry.EntityType == domain.AuditEntityRole &&
+				entry.EntityId == domain.RoleAdmin && entry.After["mfa_required"] == true
+		})).Return(nil).Once()
+
+		require.NoError(t, mfaService.SetRequirement(ctx, &domain.MfaRequirementRequest{Role: domain.RoleAdmin, Required: true}))
+
+		mfa.On("GetMfaRequirement", ctx, domain.RoleAdmin).Return(&domain.MfaRequirement{Role: domain.RoleAdmin}, nil).Once()
+		require.NoError(t, mfaService.SetRequirement(ctx, &domain.MfaRequirementRequest{Role: domain.RoleAdmin, Required: true}))
+
+		mfa.AssertExpectations(t)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		mfaService, _, _ := setup()
+
+		err := mfaService.SetRequirement(ctx, &domain.MfaRequirementRequest{Role: "superuser", Required: true})
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
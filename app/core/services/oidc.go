@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+)
+
+// maxOidcUsernameLength bounds the usernames derived from the claims of a provider
+const maxOidcUsernameLength = 32
+
+// oidcUsernameAttempts is how many usernames with a random suffix are tried when the derived one is taken
+const oidcUsernameAttempts = 5
+
+type OidcService struct {
+	users      port.UsersRepository
+	oidc       port.OidcRepository
+	auditLog   port.AuditLogRepository
+	unitOfWork port.UnitOfWork
+	auth       *AuthService
+	providers  map[string]port.OidcProvider
+	loginTTL   time.Duration
+}
+
+// NewOidcService creates the service signing users in with providers. A sign-in must return from the provider
+// within loginTTL; it then goes on like a password login of auth, with its second factor and tokens.
+func NewOidcService(users port.UsersRepository, oidc port.OidcRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork, auth *AuthService, providers []port.OidcProvider, loginTTL time.Duration) *OidcService {
+	byName := make(map[string]port.OidcProvider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+	return &OidcService{
+		users:      users,
+		oidc:       oidc,
+		auditLog:   auditLog,
+		unitOfWork: unitOfWork,
+		auth:       auth,
+		providers:  byName,
+		loginTTL:   loginTTL,
+	}
+}
+
+// Authorize stores a pending sign-in under the hash of a random state, with the PKCE verifier and nonce it must be
+// completed with, and returns the URL of the provider to send the browser to
+func (s *OidcService) Authorize(ctx context.Context, name string) (*domain.OidcAuthorization, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown identity provider %q", domain.ErrNotFound, name)
+	}
+
+	var userId string
+	if port.ActorFromContext(ctx) != domain.AnonymousActor {
+		user, err := s.currentUser(ctx)
+		if err != nil {
+			return nil, err
+		}
+		userId = user.Id
+	}
+
+	secrets := make([]string, 3)
+	for i := range secrets {
+		secret, err := utils.GenerateOpaqueToken()
+		if err != nil {
+			return nil, err
+		}
+		secrets[i] = secret
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+
+	url, err := provider.AuthorizationURL(ctx, state, nonce, utils.CodeChallengeS256(verifier))
+	if err != nil {
+		return nil, err
+	}
+	login := &domain.OidcLogin{
+		StateHash:    utils.HashOpaqueToken(state),
+		Provider:     name,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		UserId:       userId,
+		ExpiresAt:    port.AuditTime().Add(s.loginTTL),
+	}
+	if err := s.oidc.CreateOidcLogin(ctx, login); err != nil {
+		return nil, err
+	}
+	return &domain.OidcAuthorization{URL: url, State: state, ExpiresAt: login.ExpiresAt}, nil
+}
+
+// Login completes a sign-in. The pending sign-in is taken before the code is redeemed, so that a state is used
+// once even when the provider rejects the code. An identity seen for the first time is linked to the user who
+// started the sign-in, or to a new user with the role of the provider.
+func (s *OidcService) Login(ctx context.Context, req *domain.OidcLoginRequest) (*domain.AuthResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	login, err := s.oidc.TakeOidcLogin(ctx, utils.HashOpaqueToken(req.State))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown state", domain.ErrOidcLoginInvalid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if login.Expired(port.AuditTime()) {
+		return nil, fmt.Errorf("%w: sign-in has expired", domain.ErrOidcLoginInvalid)
+	}
+	provider, ok := s.providers[login.Provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: identity provider %q is no longer configured", domain.ErrOidcLoginInvalid, login.Provider)
+	}
+
+	claims, err := provider.Exchange(ctx, req.Code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *domain.Users
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.identityUser(ctx, provider, login, claims)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.auth.mfaChallenge(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &domain.AuthResponse{Mfa: challenge}, nil
+	}
+	return s.auth.issueLogin(ctx, user, map[string]any{"username": user.Username, "provider": provider.Name()})
+}
+
+// ListIdentities returns the identities linked to the user making the request
+func (s *OidcService) ListIdentities(ctx context.Context) ([]domain.OidcIdentity, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.oidc.ListOidcIdentities(ctx, user.Id)
+}
+
+// identityUser returns the user the identity of claims is linked to, linking it first when it is new
+func (s *OidcService) identityUser(ctx context.Context, provider port.OidcProvider, login *domain.OidcLogin, claims *domain.OidcClaims) (*domain.Users, error) {
+	identity, err := s.oidc.GetOidcIdentity(ctx, provider.Name(), claims.Subject)
+	if err == nil {
+		if login.UserId != "" && login.UserId != identity.UserId {
+			return nil, domain.ErrOidcIdentityLinked
+		}
+		user, err := s.users.GetUserById(ctx, identity.UserId)
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("%w: the user of the identity has been deleted", domain.ErrOidcLoginInvalid)
+		}
+		return user, err
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	var user *domain.Users
+	if login.UserId != "" {
+		user, err = s.users.GetUserById(ctx, login.UserId)
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrUnauthorized
+		}
+	} else {
+		user, err = s.provision(ctx, provider, claims)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	identity = &domain.OidcIdentity{Provider: provider.Name(), Subject: claims.Subject, UserId: user.Id, Email: claims.Email}
+	if _, err := s.oidc.CreateOidcIdentity(ctx, identity); err != nil {
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			return nil, domain.ErrOidcIdentityLinked
+		}
+		return nil, err
+	}
+	return user, recordAudit(port.WithActor(ctx, user.Id), s.auditLog, domain.AuditActionOidcIdentityLinked, domain.AuditEntityUser, user.Id, nil,
+		map[string]any{"provider": provider.Name(), "subject": claims.Subject, "provisioned": login.UserId == ""})
+}
+
+// provision creates the user of an identity on its first sign-in. The user gets a random password they do not
+// know, and the email of the provider only when the provider verified it.
+func (s *OidcService) provision(ctx context.Context, provider port.OidcProvider, claims *domain.OidcClaims) (*domain.Users, error) {
+	password, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := s.auth.hasher.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+	username, err := s.availableUsername(ctx, provider, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &domain.Users{
+		Username:    username,
+		Password:    hash,
+		Role:        provider.Role(),
+		DisplayName: truncateRunes(strings.TrimSpace(claims.Name), domain.MaxDisplayNameLength),
+	}
+	if claims.EmailVerified {
+		user.Email = domain.NormalizeEmail(claims.Email)
+		user.VerifiedEmail = user.Email
+	}
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.users.CreateUser(ctx, user); err != nil {
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			return nil, fmt.Errorf("%w: another account uses the email of the identity; sign in to it and link the identity", err)
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// availableUsername derives a username from the claims, adding a random suffix while it is taken
+func (s *OidcService) availableUsername(ctx context.Context, provider port.OidcProvider, claims *domain.OidcClaims) (string, error) {
+	base := provider.Name()
+	for _, candidate := range []string{claims.PreferredUsername, claims.Email, claims.Name} {
+		candidate, _, _ = strings.Cut(candidate, "@")
+		if candidate = sanitizeUsername(candidate); candidate != "" {
+			base = candidate
+			break
+		}
+	}
+
+	username := base
+	for range oidcUsernameAttempts {
+		_, err := s.users.GetUserByUsername(ctx, username)
+		if errors.Is(err, domain.ErrNotFound) {
+			return username, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		suffix, err := utils.GenerateNumericCode(4)
+		if err != nil {
+			return "", err
+		}
+		username = base + "-" + suffix
+	}
+	return "", fmt.Errorf("%w: no free username for %q", domain.ErrAlreadyExists, base)
+}
+
+// sanitizeUsername lower-cases name and keeps the letters, digits, dots, dashes and underscores of it
+func sanitizeUsername(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		case r == ' ':
+			return '.'
+		default:
+			return -1
+		}
+	}, strings.ToLower(strings.TrimSpace(name)))
+	return strings.Trim(truncateRunes(name, maxOidcUsernameLength), ".-_")
+}
+
+// truncateRunes returns the first n characters of s
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// currentUser returns the user making the request
+func (s *OidcService) currentUser(ctx context.Context) (*domain.Users, error) {
+	actor := port.ActorFromContext(ctx)
+	if actor == domain.AnonymousActor {
+		return nil, domain.ErrUnauthorized
+	}
+	user, err := s.users.GetUserById(ctx, actor)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrUnauthorized
+	}
+	return user, err
+}
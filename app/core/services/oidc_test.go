@@ -0,0 +1,354 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockOidcRepository is a mock of OidcRepository interface
+type MockOidcRepository struct {
+	mock.Mock
+}
+
+func (m *MockOidcRepository) CreateOidcLogin(ctx context.Context, login *domain.OidcLogin) error {
+	args := m.Called(ctx, login)
+	return args.Error(0)
+}
+
+func (m *MockOidcRepository) TakeOidcLogin(ctx context.Context, stateHash string) (*domain.OidcLogin, error) {
+	args := m.Called(ctx, stateHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OidcLogin), args.Error(1)
+}
+
+func (m *MockOidcRepository) CreateOidcIdentity(ctx context.Context, identity *domain.OidcIdentity) (*domain.OidcIdentity, error) {
+	args := m.Called(ctx, identity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OidcIdentity), args.Error(1)
+}
+
+func (m *MockOidcRepository) GetOidcIdentity(ctx context.Context, provider string, subject string) (*domain.OidcIdentity, error) {
+	args := m.Called(ctx, provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OidcIdentity), args.Error(1)
+}
+
+func (m *MockOidcRepository) ListOidcIdentities(ctx context.Context, userId string) ([]domain.OidcIdentity, error) {
+	args := m.Called(ctx, userId)
+	return args.Get(0).([]domain.OidcIdentity), args.Error(1)
+}
+
+// MockOidcProvider is a mock of OidcProvider interface named google, provisioning users with the user role
+type MockOidcProvider struct {
+	mock.Mock
+}
+
+func (m *MockOidcProvider) Name() string {
+	return "google"
+}
+
+func (m *MockOidcProvider) Role() string {
+	return domain.RoleUser
+}
+
+func (m *MockOidcProvider) AuthorizationURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	args := m.Called(ctx, state, nonce, codeChallenge)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockOidcProvider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*domain.OidcClaims, error) {
+	args := m.Called(ctx, code, codeVerifier, nonce)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OidcClaims), args.Error(1)
+}
+
+type oidcFixture struct {
+	svc      *OidcService
+	users    *MockUsersRepository
+	oidc     *MockOidcRepository
+	provider *MockOidcProvider
+	auditLog *MockAuditLogRepository
+}
+
+func newOidcService(t *testing.T, mfa *MockMfaRepository) oidcFixture {
+	f := oidcFixture{
+		users:    new(MockUsersRepository),
+		oidc:     new(MockOidcRepository),
+		provider: new(MockOidcProvider),
+		auditLog: new(MockAuditLogRepository),
+	}
+	auth := NewAuthService(f.users, quietLoginAttempts(), mfa, noRevocations(), f.auditLog, newTestJWTService(t), testHasher, testLoginPolicy, testMfaTTL, "Liongate")
+	f.svc = NewOidcService(f.users, f.oidc, f.auditLog, stubUnitOfWork{}, auth, []port.OidcProvider{f.provider}, 10*time.Minute)
+	return f
+}
+
+// pendingLogin is a sign-in with google started by userId, or by nobody when empty, that the browser returns
+// from with state
+func pendingLogin(f oidcFixture, state string, userId string, expiresAt time.Time) {
+	f.oidc.On("TakeOidcLogin", mock.Anything, utils.HashOpaqueToken(state)).Return(&domain.OidcLogin{
+		StateHash:    utils.HashOpaqueToken(state),
+		Provider:     "google",
+		CodeVerifier: "verifier",
+		Nonce:        "nonce",
+		UserId:       userId,
+		ExpiresAt:    expiresAt,
+	}, nil).Once()
+}
+
+var annClaims = &domain.OidcClaims{Subject: "110248495921238986420", Email: "Ann@Example.com", EmailVerified: true, Name: "Ann Smith"}
+
+func TestOidcAuthorize(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores the hashed state with the verifier of the challenge", func(t *testing.T) {
+		f := newOidcService(t, noMfa())
+		var nonce, challenge string
+		f.provider.On("AuthorizationURL", ctx, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			nonce, challenge = args.String(2), args.String(3)
+		}).Return("https://accounts.google.com/o/oauth2/v2/auth?state=x", nil)
+		var stored *domain.OidcLogin
+		f.oidc.On("CreateOidcLogin", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*domain.OidcLogin)
+		}).Return(nil)
+
+		authorization, err := f.svc.Authorize(ctx, "google")
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://accounts.google.com/o/oauth2/v2/auth?state=x", authorization.URL)
+		require.NotNil(t, stored)
+		assert.Equal(t, utils.HashOpaqueToken(authorization.State), stored.StateHash)
+		assert.Equal(t, "google", stored.Provider)
+		assert.Equal(t, nonce, stored.Nonce)
+		assert.Equal(t, challenge, utils.CodeChallengeS256(stored.CodeVerifier))
+		assert.Empty(t, stored.UserId)
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), stored.ExpiresAt, time.Minute)
+		assert.Equal(t, stored.ExpiresAt, authorization.ExpiresAt)
+	})
+
+	t.Run("links to the signed-in user", func(t *testing.T) {
+		f := newOidcService(t, noMfa())
+		ctx := port.WithActor(ctx, "1")
+		f.users.On("GetUserById", ctx, "1").Return(&domain.Users{Id: "1", Username: "ann"}, nil)
+		f.provider.On("AuthorizationURL", ctx, mock.Anything, mock.Anything, mock.Anything).Return("https://accounts.google.com/o/oauth2/v2/auth", nil)
+		f.oidc.On("CreateOidcLogin", ctx, mock.MatchedBy(func(login *domain.OidcLogin) bool { return login.UserId == "1" })).Return(nil).Once()
+
+		_, err := f.svc.Authorize(ctx, "google")
+
+		require.NoError(t, err)
+		f.oidc.AssertExpectations(t)
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		f := newOidcService(t, noMfa())
+
+		_, err := f.svc.Authorize(ctx, "facebook")
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		f.oidc.AssertNotCalled(t, "CreateOidcLogin", mock.Anything, mock.Anything)
+	})
+}
+
+func TestOidcLogin(t *testing.T) {
+	ctx := context.Background()
+	req := &domain.OidcLoginRequest{State: "state", Code: "code"}
+	later := time.Now().Add(time.Minute)
+
+	t.Run("provisions a user on the first sign-in", func(t *testing.T) {
+		f := newOidcService(t, noMfa())
+		pendingLogin(f, "state", "", later)
+		f.provider.On("Exchange", ctx, "code", "verifier", "nonce").Return(annClaims, nil)
+		f.oidc.On("GetOidcIdentity", mock.Anything, "google", annClaims.Subject).Return(nil, domain.ErrNotFound)
+		f.users.On("GetUserByUsername", mock.Anything, "ann").Return(&domain.Users{Id: "9", Username: "ann"}, nil).Once()
+		f.users.On("GetUserByUsername", mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound).Once()
+		var created *domain.Users
+		f.users.On("CreateUser", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			created = args.Get(1).(*domain.Users)
+			created.Id = "1"
+		}).Return(&domain.Users{Id: "1"}, nil)
+		f.oidc.On("CreateOidcIdentity", mock.Anything, mock.MatchedBy(func(identity *domain.OidcIdentity) bool {
+			return identity.Provider == "google" && identity.Subject == annClaims.Subject && identity.UserId == "1"
+		})).Return(&domain.OidcIdentity{Id: "id-1"}, nil).Once()
+		f.auditLog.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionOidcIdentityLinked && entry.Actor == "1" && entry.After["provisioned"] == true
+		})).Return(nil).Once()
+		f.auditLog.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionLogin && entry.Actor == "1" && entry.After["provider"] == "google"
+		})).Return(nil).Once()
+
+		result, err := f.svc.Login(ctx, req)
+
+		require.NoError(t, err)
+		require.NotNil(t, result.Tokens)
+		require.NotNil(t, created)
+		assert.Regexp(t, `^ann-\d{4}$`, created.Username)
+		assert.Equal(t, domain.RoleUser, created.Role)
+		assert.Equal(t, "Ann Smith", created.DisplayName)
+		assert.Equal(t, "ann@example.com", created.Email)
+		assert.Equal(t, "ann@example.com", created.VerifiedEmail)
+		assert.NotEmpty(t, created.Password)
+		f.oidc.AssertExpectations(t)
+		f.auditLog.AssertExpectations(t)
+	})
+
+	t.Run("does not take an unverified email", func(t *testing.T) {
+		f := newOidcService(t, noMfa())
+		pendingLogin(f, "state", "", later)
+		f.provider.On("Exchange", ctx, "code", "verifier", "nonce").Return(&domain.OidcClaims{Subject: "42", Email: "bob@example.com"}, nil)
+		f.oidc.On("GetOidcIdentity", mock.Anything, "google", "42").Return(nil, domain.ErrNotFound)
+		f.users.On("GetUserByUsername", mock.Anything, "bob").Return(nil, domain.ErrNotFound)
+		var created *domain.Users
+		f.users.On("CreateUser", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			created = args.Get(1).(*domain.Users)
+			created.Id = "2"
+		}).Return(&domain.Users{Id: "2"}, nil)
+		f.oidc.On("CreateOidcIdentity", mock.Anything, mock.Anything).Return(&domain.OidcIdentity{Id: "id-2"}, nil)
+		f.auditLog.On("Append", mock.Anything, mock.Anything).Return(nil)
+
+		_, err := f.svc.Login(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "bob", created.Username)
+		assert.Empty(t, created.Email)
+		assert.Empty(t, created.VerifiedEmail)
+	})
+
+	t.Run("signs in the user of a linked identity", func(t *testing.T) {
+		f := newOidcService(t, noMfa())
+		pendingLogin(f, "state", "", later)
+		f.provider.On("Exchange", ctx, "code", "verifier", "nonce").Return(annClaims, nil)
+		f.oidc.On("GetOidcIdentity", mock.Anything, "google", annClaims.Subject).Return(&domain.OidcIdentity{UserId: "1"}, nil)
+		f.users.On("GetUserById", mock.Anything, "1").Return(&domain.Users{Id: "1", Username: "ann", Role: domain.RoleUser}, nil)
+		f.auditLog.On("Append", mock.Anything, auditAction(domain.AuditActionLogin)).Return(nil).Once()
+
+		result, err := f.svc.Login(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "1", result.User.Id)
+		assert.NotNil(t, result.Tokens)
+		f.users.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+		f.oidc.AssertNotCalled(t, "CreateOidcIdentity", mock.Anything, mock.Anything)
+		f.auditLog.AssertExpectations(t)
+	})
+
+	t.Run("links the identity to the user who started the sign-in", func(t *testing.T) {
+		f := newOidcService(t, noMfa())
+		pendingLogin(f, "state", "1", later)
+		f.provider.On("Exchange", ctx, "code", "verifier", "nonce").Return(annClaims, nil)
+		f.oidc.On("GetOidcIdentity", mock.Anything, "google", annClaims.Subject).Return(nil, domain.ErrNotFound)
+		f.users.On("GetUserById", mock.Anything, "1").Return(&domain.Users{Id: "1", Username: "ann"}, nil)
+		f.oidc.On("CreateOidcIdentity", mock.Anything, mock.MatchedBy(func(identity *domain.OidcIdentity) bool {
+			return identity.UserId == "1"
+		})).Return(&domain.OidcIdentity{Id: "id-1"}, nil).Once()
+		f.auditLog.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionOidcIdentityLinked && entry.After["provisioned"] == false
+		})).Return(nil).Once()
+		f.auditLog.On("Append", mock.Anything, auditAction(domain.AuditActionLogin)).Return(nil).Once()
+
+		_, err := f.svc.Login(ctx, req)
+
+		require.NoError(t, err)
+		f.users.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+		f.oidc.AssertExpectations(t)
+		f.auditLog.AssertExpectations(t)
+	})
+
+	t.Run("identity linked to another user", func(t *testing.T) {
+		f := newOidcService(t, noMfa())
+		pendingLogin(f, "state", "2", later)
+		f.provider.On("Exchange", ctx, "code", "verifier", "nonce").Return(annClaims, nil)
+		f.oidc.On("GetOidcIdentity", mock.Anything, "google", annClaims.Subject).Return(&domain.OidcIdentity{UserId: "1"}, nil)
+
+		_, err := f.svc.Login(ctx, req)
+
+		assert.ErrorIs(t, err, domain.ErrOidcIdentityLinked)
+		f.auditLog.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+	})
+
+	t.Run("second factor", func(t *testing.T) {
+		mfa := new(MockMfaRepository)
+		mfa.On("GetMfaEnrollment", mock.Anything, "1").Return(confirmedEnrollment(t, "1"), nil)
+		f := newOidcService(t, mfa)
+		pendingLogin(f, "state", "", later)
+		f.provider.On("Exchange", ctx, "code", "verifier", "nonce").Return(annClaims, nil)
+		f.oidc.On("GetOidcIdentity", mock.Anything, "google", annClaims.Subject).Return(&domain.OidcIdentity{UserId: "1"}, nil)
+		f.users.On("GetUserById", mock.Anything, "1").Return(&domain.Users{Id: "1", Username: "ann", Role: domain.RoleUser}, nil)
+
+		result, err := f.svc.Login(ctx, req)
+
+		require.NoError(t, err)
+		assert.Nil(t, result.Tokens)
+		require.NotNil(t, result.Mfa)
+		assert.NotEmpty(t, result.Mfa.Token)
+		f.auditLog.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		f := newOidcService(t, noMfa())
+		f.oidc.On("TakeOidcLogin", mock.Anything, utils.HashOpaqueToken("state")).Return(nil, domain.ErrNotFound)
+
+		_, err := f.svc.Login(ctx, req)
+
+		assert.ErrorIs(t, err, domain.ErrOidcLoginInvalid)
+		f.provider.AssertNotCalled(t, "Exchange", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("expired sign-in", func(t *testing.T) {
+		f := newOidcService(t, noMfa())
+		pendingLogin(f, "state", "", time.Now().Add(-time.Second))
+
+		_, err := f.svc.Login(ctx, req)
+
+		assert.ErrorIs(t, err, domain.ErrOidcLoginInvalid)
+		f.provider.AssertNotCalled(t, "Exchange", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("provider rejects the code", func(t *testing.T) {
+		f := newOidcService(t, noMfa())
+		pendingLogin(f, "state", "", later)
+		f.provider.On("Exchange", ctx, "code", "verifier", "nonce").Return(nil, domain.ErrOidcLoginInvalid)
+
+		_, err := f.svc.Login(ctx, req)
+
+		assert.ErrorIs(t, err, domain.ErrOidcLoginInvalid)
+		f.oidc.AssertNotCalled(t, "GetOidcIdentity", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires state and code", func(t *testing.T) {
+		f := newOidcService(t, noMfa())
+
+		_, err := f.svc.Login(ctx, &domain.OidcLoginRequest{State: "state"})
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestOidcListIdentities(t *testing.T) {
+	f := newOidcService(t, noMfa())
+	ctx := port.WithActor(context.Background(), "1")
+	f.users.On("GetUserById", ctx, "1").Return(&domain.Users{Id: "1"}, nil)
+	f.oidc.On("ListOidcIdentities", ctx, "1").Return([]domain.OidcIdentity{{Provider: "google", UserId: "1"}}, nil)
+
+	identities, err := f.svc.ListIdentities(ctx)
+
+	require.NoError(t, err)
+	assert.Len(t, identities, 1)
+
+	_, err = f.svc.ListIdentities(context.Background())
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+}
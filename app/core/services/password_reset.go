@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+)
+
+type PasswordResetService struct {
+	users      port.UsersRepository
+	resets     port.PasswordResetRepository
+	sessions   port.SessionRepository
+	auditLog   port.AuditLogRepository
+	unitOfWork port.UnitOfWork
+	mailer     port.Mailer
+	hasher     *utils.PasswordHasher
+	ttl        time.Duration
+	resetURL   string
+}
+
+// NewPasswordResetService creates the password reset service; reset links expire after ttl and point at resetURL
+func NewPasswordResetService(users port.UsersRepository, resets port.PasswordResetRepository, sessions port.SessionRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork, mailer port.Mailer, hasher *utils.PasswordHasher, ttl time.Duration, resetURL string) *PasswordResetService {
+	return &PasswordResetService{
+		users:      users,
+		resets:     resets,
+		sessions:   sessions,
+		auditLog:   auditLog,
+		unitOfWork: unitOfWork,
+		mailer:     mailer,
+		hasher:     hasher,
+		ttl:        ttl,
+		resetURL:   resetURL,
+	}
+}
+
+// ForgotPassword mails a reset link to the user, replacing the links they were sent before.
+// Unknown usernames and users without an email address are ignored without an error, so that the response
+// does not tell whether a username exists.
+func (s *PasswordResetService) ForgotPassword(ctx context.Context, req *domain.ForgotPasswordRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	user, err := s.users.GetUserByUsername(ctx, req.Username)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return nil
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	reset := &domain.PasswordResetToken{
+		TokenHash: utils.HashOpaqueToken(token),
+		UserId:    user.Id,
+		ExpiresAt: port.AuditTime().Add(s.ttl),
+	}
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := s.resets.DeletePasswordResetTokens(ctx, user.Id); err != nil {
+			return err
+		}
+		if err := s.resets.CreatePasswordResetToken(ctx, reset); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionPasswordResetRequested, domain.AuditEntityUser, user.Id, nil,
+			map[string]any{"expires_at": reset.ExpiresAt})
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, domain.Mail{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\n"+
+			"Someone asked to reset the password of your account. To choose a new password, open this link before %s:\n\n"+
+			"%s\n\n"+
+			"If it was not you, ignore this mail and your password stays as it is.\n",
+			user.Username, reset.ExpiresAt.Format(time.RFC1123), s.resetURL+"?token="+url.QueryEscape(token)),
+	})
+}
+
+// ResetPassword sets the new password of the user of a reset token and revokes all their sessions in one unit
+// of work. Taking the token removes it, so that it can be used once.
+func (s *PasswordResetService) ResetPassword(ctx context.Context, req *domain.ResetPasswordRequest) error {
+	if err := domain.JoinValidation(req.Validate(), checkPasswordStrength(s.hasher, "password", req.Password)); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return err
+	}
+
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		reset, err := s.resets.TakePasswordResetToken(ctx, utils.HashOpaqueToken(req.Token))
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrPasswordResetInvalid
+		}
+		if err != nil {
+			return err
+		}
+		now := port.AuditTime()
+		if reset.Expired(now) {
+			return domain.ErrPasswordResetInvalid
+		}
+
+		ctx = port.WithActor(ctx, reset.UserId)
+		_, err = s.users.UpdateUser(ctx, reset.UserId, &domain.Users{Password: hashedPassword})
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrPasswordResetInvalid
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.resets.DeletePasswordResetTokens(ctx, reset.UserId); err != nil {
+			return err
+		}
+		if err := s.sessions.RevokeSessions(ctx, reset.UserId, now); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionPasswordReset, domain.AuditEntityUser, reset.UserId, nil,
+			map[string]any{"sessions_revoked_at": now})
+	})
+}
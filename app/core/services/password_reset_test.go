@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPasswordResetRepository is a mock of PasswordResetRepository interface
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) TakePasswordResetToken(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) DeletePasswordResetTokens(ctx context.Context, userId string) error {
+	args := m.Called(ctx, userId)
+	return args.Error(0)
+}
+
+// MockSessionRepository is a mock of SessionRepository interface
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) RevokeSessions(ctx context.Context, userId string, at time.Time) error {
+	args := m.Called(ctx, userId, at)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) GetSessionRevocation(ctx context.Context, userId string) (*domain.SessionRevocation, error) {
+	args := m.Called(ctx, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SessionRevocation), args.Error(1)
+}
+
+func (m *MockSessionRepository) RevokeToken(ctx context.Context, token *domain.RevokedToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) IsTokenRevoked(ctx context.Context, tokenId string) (bool, error) {
+	args := m.Called(ctx, tokenId)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockMailer is a mock of Mailer interface
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(ctx context.Context, mail domain.Mail) error {
+	args := m.Called(ctx, mail)
+	return args.Error(0)
+}
+
+// noRevocations returns a session repository in which no user ever had their sessions or a token revoked
+func noRevocations() *MockSessionRepository {
+	sessions := new(MockSessionRepository)
+	sessions.On("GetSessionRevocation", mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound)
+	sessions.On("IsTokenRevoked", mock.Anything, mock.Anything).Return(false, nil)
+	return sessions
+}
+
+// mailedToken returns the token of the reset link in mail
+func mailedToken(t *testing.T, mail domain.Mail) string {
+	t.Helper()
+	_, link, ok := strings.Cut(mail.Body, "https://liongate.test/reset?")
+	require.True(t, ok, "mail carries the reset link")
+	query, err := url.ParseQuery(strings.Fields(link)[0])
+	require.NoError(t, err)
+	return query.Get("token")
+}
+
+func TestForgotPassword(t *testing.T) {
+	ctx := context.Background()
+	newService := func() (*PasswordResetService, *MockUsersRepository, *MockPasswordResetRepository, *MockMailer, *MockAuditLogRepository) {
+		users, resets, mailer, auditLog := new(MockUsersRepository), new(MockPasswordResetRepository), new(MockMailer), new(MockAuditLogRepository)
+		return NewPasswordResetService(users, resets, noRevocations(), auditLog, stubUnitOfWork{}, mailer, testHasher, time.Hour, "https://liongate.test/reset"), users, resets, mailer, auditLog
+	}
+
+	t.Run("mails a link whose token is stored hashed", func(t *testing.T) {
+		svc, users, resets, mailer, auditLog := newService()
+		users.On("GetUserByUsername", ctx, "alice").Return(&domain.Users{Id: "1", Username: "alice", Email: "alice@example.com"}, nil)
+		resets.On("DeletePasswordResetTokens", mock.Anything, "1").Return(nil).Once()
+		var stored *domain.PasswordResetToken
+		resets.On("CreatePasswordResetToken", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*domain.PasswordResetToken)
+		}).Return(nil)
+		auditLog.On("Append", mock.Anything, auditAction(domain.AuditActionPasswordResetRequested)).Return(nil)
+		var sent domain.Mail
+		mailer.On("Send", ctx, mock.Anything).Run(func(args mock.Arguments) { sent = args.Get(1).(domain.Mail) }).Return(nil)
+
+		require.NoError(t, svc.ForgotPassword(ctx, &domain.ForgotPasswordRequest{Username: "alice"}))
+
+		assert.Equal(t, "alice@example.com", sent.To)
+		token := mailedToken(t, sent)
+		require.NotNil(t, stored)
+		assert.Equal(t, utils.HashOpaqueToken(token), stored.TokenHash)
+		assert.Equal(t, "1", stored.UserId)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
+		resets.AssertExpectations(t)
+	})
+
+	t.Run("answers alike for unknown users and users without email", func(t *testing.T) {
+		svc, users, _, mailer, _ := newService()
+		users.On("GetUserByUsername", ctx, "ghost").Return(nil, domain.ErrNotFound)
+		users.On("GetUserByUsername", ctx, "bob").Return(&domain.Users{Id: "2", Username: "bob"}, nil)
+
+		assert.NoError(t, svc.ForgotPassword(ctx, &domain.ForgotPasswordRequest{Username: "ghost"}))
+		assert.NoError(t, svc.ForgotPassword(ctx, &domain.ForgotPasswordRequest{Username: "bob"}))
+		mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires a username", func(t *testing.T) {
+		svc, _, _, _, _ := newService()
+		err := svc.ForgotPassword(ctx, &domain.ForgotPasswordRequest{})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	newService := func() (*PasswordResetService, *MockUsersRepository, *MockPasswordResetRepository, *MockSessionRepository, *MockAuditLogRepository) {
+		users, resets, sessions, auditLog := new(MockUsersRepository), new(MockPasswordResetRepository), new(MockSessionRepository), new(MockAuditLogRepository)
+		return NewPasswordResetService(users, resets, sessions, auditLog, stubUnitOfWork{}, new(MockMailer), testHasher, time.Hour, "https://liongate.test/reset"), users, resets, sessions, auditLog
+	}
+	hash := utils.HashOpaqueToken("token")
+
+	t.Run("sets the password and revokes every session", func(t *testing.T) {
+		svc, users, resets, sessions, auditLog := newService()
+		resets.On("TakePasswordResetToken", mock.Anything, hash).
+			Return(&domain.PasswordResetToken{TokenHash: hash, UserId: "1", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		var update *domain.Users
+		users.On("UpdateUser", mock.Anything, "1", mock.Anything).Run(func(args mock.Arguments) {
+			update = args.Get(2).(*domain.Users)
+		}).Return(&domain.Users{Id: "1"}, nil)
+		resets.On("DeletePasswordResetTokens", mock.Anything, "1").Return(nil)
+		sessions.On("RevokeSessions", mock.Anything, "1", mock.AnythingOfType("time.Time")).Return(nil)
+		auditLog.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionPasswordReset && entry.Actor == "1" && entry.EntityId == "1"
+		})).Return(nil)
+
+		require.NoError(t, svc.ResetPassword(ctx, &domain.ResetPasswordRequest{Token: "token", Password: "new-secret"}))
+
+		require.NotNil(t, update)
+		assert.True(t, testHasher.Verify(update.Password, "new-secret"))
+		assert.Zero(t, update.Version, "a reset does not depend on the version a client saw")
+		sessions.AssertExpectations(t)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("rejects unknown, used and expired tokens", func(t *testing.T) {
+		svc, users, resets, _, _ := newService()
+		resets.On("TakePasswordResetToken", mock.Anything, utils.HashOpaqueToken("used")).Return(nil, domain.ErrNotFound)
+		resets.On("TakePasswordResetToken", mock.Anything, hash).
+			Return(&domain.PasswordResetToken{TokenHash: hash, UserId: "1", ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+
+		assert.ErrorIs(t, svc.ResetPassword(ctx, &domain.ResetPasswordRequest{Token: "used", Password: "new-secret"}), domain.ErrPasswordResetInvalid)
+		assert.ErrorIs(t, svc.ResetPassword(ctx, &domain.ResetPasswordRequest{Token: "token", Password: "new-secret"}), domain.ErrPasswordResetInvalid)
+		users.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("checks the password before the token", func(t *testing.T) {
+		svc, _, resets, _, _ := newService()
+		err := svc.ResetPassword(ctx, &domain.ResetPasswordRequest{Token: "token", Password: "short"})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+		resets.AssertNotCalled(t, "TakePasswordResetToken", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRevokedSessions(t *testing.T) {
+	jwtService := newTestJWTService(t)
+	ctx := context.Background()
+	user := &domain.Users{Id: "1", Username: "alice", Role: domain.RoleUser}
+	tokens, err := jwtService.GenerateTokenPair(user)
+	require.NoError(t, err)
+
+	newService := func(revokedAt time.Time) *AuthService {
+		sessions := new(MockSessionRepository)
+		sessions.On("GetSessionRevocation", mock.Anything, "1").Return(&domain.SessionRevocation{UserId: "1", RevokedAt: revokedAt}, nil)
+		sessions.On("IsTokenRevoked", mock.Anything, mock.Anything).Return(false, nil)
+		users := new(MockUsersRepository)
+		users.On("GetUserById", mock.Anything, "1").Return(user, nil)
+		return NewAuthService(users, quietLoginAttempts(), noMfa(), sessions, new(MockAuditLogRepository), jwtService, testHasher, testLoginPolicy, testMfaTTL, "Liongate")
+	}
+
+	t.Run("rejects tokens issued before the revocation", func(t *testing.T) {
+		svc := newService(time.Now().Add(time.Second))
+
+		_, err := svc.Authenticate(ctx, tokens.AccessToken)
+		assert.ErrorIs(t, err, domain.ErrSessionRevoked)
+		_, err = svc.RefreshToken(ctx, &domain.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+		assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	})
+
+	t.Run("accepts tokens issued within the second after the revocation", func(t *testing.T) {
+		revokedAt := time.Now()
+		time.Sleep(2 * time.Millisecond)
+		tokens, err := jwtService.GenerateTokenPair(user)
+		require.NoError(t, err)
+		svc := newService(revokedAt)
+
+		_, err = svc.Authenticate(ctx, tokens.AccessToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("accepts tokens issued after the revocation", func(t *testing.T) {
+		svc := newService(time.Now().Add(-time.Hour))
+
+		claims, err := svc.Authenticate(ctx, tokens.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "1", claims.UserID)
+		_, err = svc.RefreshToken(ctx, &domain.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+		assert.NoError(t, err)
+	})
+}
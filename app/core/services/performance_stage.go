@@ -8,20 +8,32 @@ import (
 )
 
 type PerformanceStageService struct {
-	stageRepository port.PerformanceStageRepository
+	stageRepository     port.PerformanceStageRepository
+	showRoundRepository port.ShowRoundsRepository
+	bookingsRepository  port.BookingsRepository
+	auditLog            port.AuditLogRepository
+	unitOfWork          port.UnitOfWork
 }
 
-func NewPerformanceStageService(stageRepository port.PerformanceStageRepository) *PerformanceStageService {
+func NewPerformanceStageService(stageRepository port.PerformanceStageRepository, showRoundRepository port.ShowRoundsRepository, bookingsRepository port.BookingsRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork) *PerformanceStageService {
 	return &PerformanceStageService{
-		stageRepository: stageRepository,
+		stageRepository:     stageRepository,
+		showRoundRepository: showRoundRepository,
+		bookingsRepository:  bookingsRepository,
+		auditLog:            auditLog,
+		unitOfWork:          unitOfWork,
 	}
 }
 
-func (s *PerformanceStageService) GetStages(ctx context.Context) ([]domain.PerformanceStage, error) {
-	return s.stageRepository.GetStages(ctx)
+func (s *PerformanceStageService) GetStages(ctx context.Context, filter port.StageFilter, query port.ListQuery) (*port.Page[domain.PerformanceStage], error) {
+	return s.stageRepository.GetStages(ctx, filter, query)
 }
 
 func (s *PerformanceStageService) CreateStage(ctx context.Context, stage *domain.PerformanceStage) (*domain.PerformanceStage, error) {
+	if err := stage.Validate(); err != nil {
+		return nil, err
+	}
+
 	return s.stageRepository.CreateStage(ctx, stage)
 }
 
@@ -29,10 +41,55 @@ func (s *PerformanceStageService) GetStageById(ctx context.Context, id string) (
 	return s.stageRepository.GetStageById(ctx, id)
 }
 
+// UpdateStage changes a stage; a change of its seat price is recorded in the audit log in the same unit of work
 func (s *PerformanceStageService) UpdateStage(ctx context.Context, id string, stage *domain.PerformanceStage) (*domain.PerformanceStage, error) {
-	return s.stageRepository.UpdateStage(ctx, id, stage)
+	if err := stage.ValidateUpdate(); err != nil {
+		return nil, err
+	}
+
+	var updated *domain.PerformanceStage
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		existing, err := s.stageRepository.GetStageById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		updated, err = s.stageRepository.UpdateStage(ctx, id, stage)
+		if err != nil || updated.PricePerSeat == existing.PricePerSeat {
+			return err
+		}
+
+		before := map[string]any{"price_per_seat": existing.PricePerSeat}
+		after := map[string]any{"price_per_seat": updated.PricePerSeat}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionStagePriceChanged, domain.AuditEntityStage, id, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
 }
 
-func (s *PerformanceStageService) DeleteStage(ctx context.Context, id string) error {
-	return s.stageRepository.DeleteStage(ctx, id)
+// DeleteStage deletes a stage, refusing when show rounds are still scheduled on it unless cascade is requested
+func (s *PerformanceStageService) DeleteStage(ctx context.Context, id string, opts port.DeleteOptions) error {
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := checkDeleteVersion(ctx, id, opts, s.stageRepository.GetStageById); err != nil {
+			return err
+		}
+
+		showRounds, err := s.showRoundRepository.GetShowRoundsByStageId(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if len(showRounds) > 0 {
+			if !opts.Cascade {
+				return referenceInUse("stage", id, len(showRounds), "show round(s)")
+			}
+			if err := deleteShowRoundsCascade(ctx, s.showRoundRepository, s.bookingsRepository, s.auditLog, showRounds); err != nil {
+				return err
+			}
+		}
+
+		return s.stageRepository.DeleteStage(ctx, id)
+	})
 }
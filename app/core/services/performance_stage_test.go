@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -15,12 +16,12 @@ type MockPerformanceStageRepository struct {
 	mock.Mock
 }
 
-func (m *MockPerformanceStageRepository) GetStages(ctx context.Context) ([]domain.PerformanceStage, error) {
-	args := m.Called(ctx)
+func (m *MockPerformanceStageRepository) GetStages(ctx context.Context, filter port.StageFilter, query port.ListQuery) (*port.Page[domain.PerformanceStage], error) {
+	args := m.Called(ctx, filter, query)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]domain.PerformanceStage), args.Error(1)
+	return args.Get(0).(*port.Page[domain.PerformanceStage]), args.Error(1)
 }
 
 func (m *MockPerformanceStageRepository) CreateStage(ctx context.Context, stage *domain.PerformanceStage) (*domain.PerformanceStage, error) {
@@ -54,18 +55,23 @@ func (m *MockPerformanceStageRepository) DeleteStage(ctx context.Context, id str
 
 func TestGetStages(t *testing.T) {
 	mockRepo := new(MockPerformanceStageRepository)
-	stageService := NewPerformanceStageService(mockRepo)
+	stageService := NewPerformanceStageService(mockRepo, new(MockShowRoundsRepository), new(MockBookingsRepository), new(MockAuditLogRepository), stubUnitOfWork{})
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
-		expectedStages := []domain.PerformanceStage{
-			{Id: "1", RoomNumber: "A101", SeatCapacity: 100, PricePerSeat: 50.0},
-			{Id: "2", RoomNumber: "B202", SeatCapacity: 200, PricePerSeat: 75.0},
+		filter := port.StageFilter{MinSeatCapacity: 100}
+		query := port.ListQuery{Sort: []port.SortField{{Field: "price_per_seat", Desc: true}}}
+		expectedStages := &port.Page[domain.PerformanceStage]{
+			Items: []domain.PerformanceStage{
+				{Id: "1", RoomNumber: "A101", SeatCapacity: 100, PricePerSeat: 50.0},
+				{Id: "2", RoomNumber: "B202", SeatCapacity: 200, PricePerSeat: 75.0},
+			},
+			Total: 2,
 		}
 
-		mockRepo.On("GetStages", ctx).Return(expectedStages, nil).Once()
+		mockRepo.On("GetStages", ctx, filter, query).Return(expectedStages, nil).Once()
 
-		result, err := stageService.GetStages(ctx)
+		result, err := stageService.GetStages(ctx, filter, query)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedStages, result)
@@ -75,9 +81,9 @@ func TestGetStages(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		expectedErr := errors.New("database error")
 
-		mockRepo.On("GetStages", ctx).Return(nil, expectedErr).Once()
+		mockRepo.On("GetStages", ctx, port.StageFilter{}, port.ListQuery{}).Return(nil, expectedErr).Once()
 
-		result, err := stageService.GetStages(ctx)
+		result, err := stageService.GetStages(ctx, port.StageFilter{}, port.ListQuery{})
 
 		assert.Error(t, err)
 		assert.Equal(t, expectedErr, err)
@@ -88,7 +94,7 @@ func TestGetStages(t *testing.T) {
 
 func TestCreateStage(t *testing.T) {
 	mockRepo := new(MockPerformanceStageRepository)
-	stageService := NewPerformanceStageService(mockRepo)
+	stageService := NewPerformanceStageService(mockRepo, new(MockShowRoundsRepository), new(MockBookingsRepository), new(MockAuditLogRepository), stubUnitOfWork{})
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
@@ -126,11 +132,21 @@ func TestCreateStage(t *testing.T) {
 		assert.Nil(t, result)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("zero capacity and negative price", func(t *testing.T) {
+		stage := &domain.PerformanceStage{RoomNumber: "A102", PricePerSeat: -10}
+
+		result, err := stageService.CreateStage(ctx, stage)
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "CreateStage", ctx, stage)
+	})
 }
 
 func TestGetStageById(t *testing.T) {
 	mockRepo := new(MockPerformanceStageRepository)
-	stageService := NewPerformanceStageService(mockRepo)
+	stageService := NewPerformanceStageService(mockRepo, new(MockShowRoundsRepository), new(MockBookingsRepository), new(MockAuditLogRepository), stubUnitOfWork{})
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
@@ -168,7 +184,8 @@ func TestGetStageById(t *testing.T) {
 
 func TestUpdateStage(t *testing.T) {
 	mockRepo := new(MockPerformanceStageRepository)
-	stageService := NewPerformanceStageService(mockRepo)
+	mockAuditLog := new(MockAuditLogRepository)
+	stageService := NewPerformanceStageService(mockRepo, new(MockShowRoundsRepository), new(MockBookingsRepository), mockAuditLog, stubUnitOfWork{})
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
@@ -180,13 +197,34 @@ func TestUpdateStage(t *testing.T) {
 			PricePerSeat: 60.0,
 		}
 
+		mockRepo.On("GetStageById", ctx, stageId).Return(&domain.PerformanceStage{Id: stageId, RoomNumber: "A101", PricePerSeat: 60.0}, nil).Once()
+		mockRepo.On("UpdateStage", ctx, stageId, stage).Return(stage, nil).Once()
+
+		result, err := stageService.UpdateStage(ctx, stageId, stage)
+
+		assert.NoError(t, err)
+		assert.Equal(t, stage, result)
+		mockRepo.AssertExpectations(t)
+		mockAuditLog.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+	})
+
+	t.Run("price change is recorded", func(t *testing.T) {
+		stageId := "2"
+		stage := &domain.PerformanceStage{Id: stageId, PricePerSeat: 75.0}
+
+		mockRepo.On("GetStageById", ctx, stageId).Return(&domain.PerformanceStage{Id: stageId, PricePerSeat: 60.0}, nil).Once()
 		mockRepo.On("UpdateStage", ctx, stageId, stage).Return(stage, nil).Once()
+		mockAuditLog.On("Append", ctx, auditEntry(domain.AuditActionStagePriceChanged, stageId,
+			map[string]any{"price_per_seat": 60.0},
+			map[string]any{"price_per_seat": 75.0},
+		)).Return(nil).Once()
 
 		result, err := stageService.UpdateStage(ctx, stageId, stage)
 
 		assert.NoError(t, err)
 		assert.Equal(t, stage, result)
 		mockRepo.AssertExpectations(t)
+		mockAuditLog.AssertExpectations(t)
 	})
 
 	t.Run("error", func(t *testing.T) {
@@ -199,6 +237,7 @@ func TestUpdateStage(t *testing.T) {
 		}
 		expectedErr := errors.New("stage not found")
 
+		mockRepo.On("GetStageById", ctx, stageId).Return(&domain.PerformanceStage{Id: stageId}, nil).Once()
 		mockRepo.On("UpdateStage", ctx, stageId, stage).Return(nil, expectedErr).Once()
 
 		result, err := stageService.UpdateStage(ctx, stageId, stage)
@@ -212,30 +251,70 @@ func TestUpdateStage(t *testing.T) {
 
 func TestDeleteStage(t *testing.T) {
 	mockRepo := new(MockPerformanceStageRepository)
-	stageService := NewPerformanceStageService(mockRepo)
+	mockShowRoundRepo := new(MockShowRoundsRepository)
+	mockBookingRepo := new(MockBookingsRepository)
+	mockAuditLog := new(MockAuditLogRepository)
+	stageService := NewPerformanceStageService(mockRepo, mockShowRoundRepo, mockBookingRepo, mockAuditLog, stubUnitOfWork{})
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
 		stageId := "1"
 
+		mockShowRoundRepo.On("GetShowRoundsByStageId", ctx, stageId).Return([]*domain.ShowRounds{}, nil).Once()
 		mockRepo.On("DeleteStage", ctx, stageId).Return(nil).Once()
 
-		err := stageService.DeleteStage(ctx, stageId)
+		err := stageService.DeleteStage(ctx, stageId, port.DeleteOptions{})
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
+		mockShowRoundRepo.AssertExpectations(t)
 	})
 
 	t.Run("error", func(t *testing.T) {
 		stageId := "999"
 		expectedErr := errors.New("stage not found")
 
+		mockShowRoundRepo.On("GetShowRoundsByStageId", ctx, stageId).Return([]*domain.ShowRounds{}, nil).Once()
 		mockRepo.On("DeleteStage", ctx, stageId).Return(expectedErr).Once()
 
-		err := stageService.DeleteStage(ctx, stageId)
+		err := stageService.DeleteStage(ctx, stageId, port.DeleteOptions{})
 
 		assert.Error(t, err)
 		assert.Equal(t, expectedErr, err)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("referenced by show rounds", func(t *testing.T) {
+		stageId := "3"
+		showRounds := []*domain.ShowRounds{{Id: "round1", StageId: stageId}}
+
+		mockShowRoundRepo.On("GetShowRoundsByStageId", ctx, stageId).Return(showRounds, nil).Once()
+
+		err := stageService.DeleteStage(ctx, stageId, port.DeleteOptions{})
+
+		assert.ErrorIs(t, err, domain.ErrReferenceInUse)
+		mockRepo.AssertNotCalled(t, "DeleteStage", ctx, stageId)
+	})
+
+	t.Run("cascade", func(t *testing.T) {
+		stageId := "2"
+		showRounds := []*domain.ShowRounds{{Id: "round2", StageId: stageId}}
+
+		mockShowRoundRepo.On("GetShowRoundsByStageId", ctx, stageId).Return(showRounds, nil).Once()
+		mockBookingRepo.On("GetBookingsByRoundId", ctx, "round2").Return([]domain.Bookings{}, nil).Once()
+		mockShowRoundRepo.On("DeleteShowRound", ctx, "round2").Return(nil).Once()
+		mockAuditLog.On("Append", ctx, auditEntry(domain.AuditActionRoundCancelled, "round2",
+			map[string]any{"animal_id": "", "stage_id": stageId, "show_time": ""},
+			map[string]any{"deleted_bookings": 0},
+		)).Return(nil).Once()
+		mockRepo.On("DeleteStage", ctx, stageId).Return(nil).Once()
+
+		err := stageService.DeleteStage(ctx, stageId, port.DeleteOptions{Cascade: true})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockShowRoundRepo.AssertExpectations(t)
+		mockBookingRepo.AssertExpectations(t)
+		mockAuditLog.AssertExpectations(t)
+	})
 }
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+)
+
+// verificationCodeDigits is the length of the codes sent to verify an address
+const verificationCodeDigits = 6
+
+// verificationMessage is the text of a verification code in one language; %[1]s is the name of the user,
+// %[2]s the code and %[3]s when it expires
+type verificationMessage struct {
+	subject string
+	mail    string
+	sms     string
+}
+
+var verificationMessages = map[domain.Language]verificationMessage{
+	domain.LanguageEnglish: {
+		subject: "Verify your email address",
+		mail: "Hello %[1]s,\n\n" +
+			"Your verification code is %[2]s. Enter it before %[3]s to confirm this email address.\n\n" +
+			"If you did not ask for it, ignore this mail.\n",
+		sms: "Your Liongate verification code is %[2]s. It expires at %[3]s.",
+	},
+	domain.LanguageThai: {
+		subject: "ยืนยันอีเมลของคุณ",
+		mail: "สวัสดี %[1]s\n\n" +
+			"รหัสยืนยันของคุณคือ %[2]s กรุณากรอกรหัสก่อน %[3]s เพื่อยืนยันอีเมลนี้\n\n" +
+			"หากคุณไม่ได้ขอรหัสนี้ กรุณาเพิกเฉยต่ออีเมลฉบับนี้\n",
+		sms: "รหัสยืนยัน Liongate ของคุณคือ %[2]s หมดอายุเวลา %[3]s",
+	},
+}
+
+type ProfileService struct {
+	users         port.UsersRepository
+	verifications port.VerificationRepository
+	auditLog      port.AuditLogRepository
+	unitOfWork    port.UnitOfWork
+	mailer        port.Mailer
+	sms           port.SmsSender
+	codeTTL       time.Duration
+	maxAttempts   int
+}
+
+// NewProfileService creates the profile service. Verification codes expire after codeTTL and are discarded
+// after maxAttempts wrong guesses.
+func NewProfileService(users port.UsersRepository, verifications port.VerificationRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork, mailer port.Mailer, sms port.SmsSender, codeTTL time.Duration, maxAttempts int) *ProfileService {
+	return &ProfileService{
+		users:         users,
+		verifications: verifications,
+		auditLog:      auditLog,
+		unitOfWork:    unitOfWork,
+		mailer:        mailer,
+		sms:           sms,
+		codeTTL:       codeTTL,
+		maxAttempts:   maxAttempts,
+	}
+}
+
+// GetProfile returns the user making the request
+func (s *ProfileService) GetProfile(ctx context.Context) (*domain.Users, error) {
+	return s.currentUser(ctx)
+}
+
+// UpdateProfile changes the profile of the user making the request. A new email or phone starts out unverified,
+// and a change of the marketing consent is recorded in the audit log in the same unit of work.
+func (s *ProfileService) UpdateProfile(ctx context.Context, update *domain.ProfileUpdate) (*domain.Users, error) {
+	change := update.ToUser()
+	if err := change.ValidateUpdate(); err != nil {
+		return nil, err
+	}
+
+	var updated *domain.Users
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		existing, err := s.currentUser(ctx)
+		if err != nil {
+			return err
+		}
+
+		updated, err = s.users.UpdateUser(ctx, existing.Id, change)
+		if err != nil || updated.ConsentsToMarketing() == existing.ConsentsToMarketing() {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionMarketingConsent, domain.AuditEntityUser, existing.Id,
+			map[string]any{"marketing_consent": existing.ConsentsToMarketing()},
+			map[string]any{"marketing_consent": updated.ConsentsToMarketing()})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// SendVerification sends a new code to the current email or phone of the user making the request.
+// The code sent before stops working.
+func (s *ProfileService) SendVerification(ctx context.Context, channel string) (*domain.ContactVerification, error) {
+	var v domain.Validation
+	v.OneOf("channel", channel, domain.ContactEmail, domain.ContactPhone)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	target := user.Contact(channel)
+	if target == "" {
+		v.Add(channel, domain.RuleRequired, nil)
+		return nil, v.Err()
+	}
+	if user.ContactVerified(channel) {
+		return nil, domain.ErrContactAlreadyVerified
+	}
+
+	code, err := utils.GenerateNumericCode(verificationCodeDigits)
+	if err != nil {
+		return nil, err
+	}
+	key := domain.VerificationKey(user.Id, channel)
+	verification := &domain.ContactVerification{
+		Key:       key,
+		UserId:    user.Id,
+		Channel:   channel,
+		Target:    target,
+		CodeHash:  hashVerificationCode(key, code),
+		ExpiresAt: port.AuditTime().Add(s.codeTTL),
+	}
+	if err := s.verifications.SaveVerification(ctx, verification); err != nil {
+		return nil, err
+	}
+
+	if err := s.deliver(ctx, user, verification, code); err != nil {
+		return nil, err
+	}
+	return verification, nil
+}
+
+// VerifyContact marks the email or phone of the user making the request as verified when the code sent to it
+// is right. A wrong code counts as an attempt; the code is discarded once the attempts run out.
+func (s *ProfileService) VerifyContact(ctx context.Context, req *domain.VerifyContactRequest) (*domain.Users, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := domain.VerificationKey(user.Id, req.Channel)
+	verification, err := s.verifications.GetVerification(ctx, key)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrVerificationInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A code sent to an address the user has changed since proves nothing about the current one
+	if verification.Expired(port.AuditTime()) || verification.Target != user.Contact(req.Channel) {
+		if err := s.verifications.DeleteVerification(ctx, key); err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		return nil, domain.ErrVerificationInvalid
+	}
+
+	// The failed attempt is stored outside of any unit of work, which would roll it back with the error
+	if subtle.ConstantTimeCompare([]byte(hashVerificationCode(key, req.Code)), []byte(verification.CodeHash)) != 1 {
+		verification.Attempts++
+		if verification.Attempts >= s.maxAttempts {
+			err = s.verifications.DeleteVerification(ctx, key)
+		} else {
+			err = s.verifications.SaveVerification(ctx, verification)
+		}
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		return nil, domain.ErrVerificationInvalid
+	}
+
+	var updated *domain.Users
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		err := s.verifications.DeleteVerification(ctx, key)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrVerificationInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		change := &domain.Users{}
+		if req.Channel == domain.ContactEmail {
+			change.VerifiedEmail = verification.Target
+		} else {
+			change.VerifiedPhone = verification.Target
+		}
+		updated, err = s.users.UpdateUser(ctx, user.Id, change)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionContactVerified, domain.AuditEntityUser, user.Id, nil,
+			map[string]any{"channel": req.Channel})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// deliver sends code to the address of verification, in the language the user prefers
+func (s *ProfileService) deliver(ctx context.Context, user *domain.Users, verification *domain.ContactVerification, code string) error {
+	message, ok := verificationMessages[domain.Language(user.Language)]
+	if !ok {
+		message = verificationMessages[domain.LanguageEnglish]
+	}
+	name := user.DisplayName
+	if name == "" {
+		name = user.Username
+	}
+	expires := verification.ExpiresAt.Format(time.RFC1123)
+
+	if verification.Channel == domain.ContactPhone {
+		return s.sms.SendSms(ctx, domain.Sms{
+			To:   verification.Target,
+			Body: fmt.Sprintf(message.sms, name, code, expires),
+		})
+	}
+	return s.mailer.Send(ctx, domain.Mail{
+		To:      verification.Target,
+		Subject: message.subject,
+		Body:    fmt.Sprintf(message.mail, name, code, expires),
+	})
+}
+
+// currentUser returns the user making the request
+func (s *ProfileService) currentUser(ctx context.Context) (*domain.Users, error) {
+	actor := port.ActorFromContext(ctx)
+	if actor == domain.AnonymousActor {
+		return nil, domain.ErrUnauthorized
+	}
+	user, err := s.users.GetUserById(ctx, actor)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrUnauthorized
+	}
+	return user, err
+}
+
+// hashVerificationCode returns the hash under which a code is stored. A six-digit code has too little entropy to
+// withstand brute force on its own; salting it with the key at least keeps one table of hashes from serving every
+// user, and codes expire within minutes.
+func hashVerificationCode(key string, code string) string {
+	return utils.HashOpaqueToken(key + ":" + code)
+}
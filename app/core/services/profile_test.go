@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockVerificationRepository is a mock of VerificationRepository interface
+type MockVerificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockVerificationRepository) SaveVerification(ctx context.Context, verification *domain.ContactVerification) error {
+	args := m.Called(ctx, verification)
+	return args.Error(0)
+}
+
+func (m *MockVerificationRepository) GetVerification(ctx context.Context, key string) (*domain.ContactVerification, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ContactVerification), args.Error(1)
+}
+
+func (m *MockVerificationRepository) DeleteVerification(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+// MockSmsSender is a mock of SmsSender interface
+type MockSmsSender struct {
+	mock.Mock
+}
+
+func (m *MockSmsSender) SendSms(ctx context.Context, sms domain.Sms) error {
+	args := m.Called(ctx, sms)
+	return args.Error(0)
+}
+
+type profileMocks struct {
+	users         *MockUsersRepository
+	verifications *MockVerificationRepository
+	auditLog      *MockAuditLogRepository
+	mailer        *MockMailer
+	sms           *MockSmsSender
+}
+
+func newProfileService() (*ProfileService, profileMocks) {
+	m := profileMocks{
+		users:         new(MockUsersRepository),
+		verifications: new(MockVerificationRepository),
+		auditLog:      new(MockAuditLogRepository),
+		mailer:        new(MockMailer),
+		sms:           new(MockSmsSender),
+	}
+	return NewProfileService(m.users, m.verifications, m.auditLog, stubUnitOfWork{}, m.mailer, m.sms, 15*time.Minute, 3), m
+}
+
+var sixDigits = regexp.MustCompile(`\b[0-9]{6}\b`)
+
+func TestUpdateProfile(t *testing.T) {
+	ctx := port.WithActor(context.Background(), "1")
+	consent, withdrawn := true, false
+
+	t.Run("normalizes the contact details and records a change of consent", func(t *testing.T) {
+		svc, m := newProfileService()
+		m.users.On("GetUserById", mock.Anything, "1").Return(&domain.Users{Id: "1", Username: "alice"}, nil)
+		m.users.On("UpdateUser", mock.Anything, "1", &domain.Users{
+			Email: "alice@example.com", Phone: "+66812345678", Language: "th", MarketingConsent: &consent, Version: 4,
+		}).Return(&domain.Users{Id: "1", Email: "alice@example.com", MarketingConsent: &consent, Version: 5}, nil)
+		m.auditLog.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditLogEntry) bool {
+			return entry.Action == domain.AuditActionMarketingConsent && entry.After["marketing_consent"] == true
+		})).Return(nil).Once()
+
+		user, err := svc.UpdateProfile(ctx, &domain.ProfileUpdate{
+			Email: " Alice@Example.com", Phone: "+66 81-234 5678", Language: "th", MarketingConsent: &consent, Version: 4,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), user.Version)
+		m.auditLog.AssertExpectations(t)
+	})
+
+	t.Run("leaves the audit log alone when the consent stays", func(t *testing.T) {
+		svc, m := newProfileService()
+		m.users.On("GetUserById", mock.Anything, "1").Return(&domain.Users{Id: "1", MarketingConsent: &withdrawn}, nil)
+		m.users.On("UpdateUser", mock.Anything, "1", mock.Anything).Return(&domain.Users{Id: "1", DisplayName: "Alice"}, nil)
+
+		_, err := svc.UpdateProfile(ctx, &domain.ProfileUpdate{DisplayName: "Alice"})
+
+		require.NoError(t, err)
+		m.auditLog.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects malformed fields", func(t *testing.T) {
+		svc, m := newProfileService()
+
+		_, err := svc.UpdateProfile(ctx, &domain.ProfileUpdate{Phone: "0812345678", Language: "fr", Email: "not-an-email"})
+
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Len(t, validationErr.Fields, 3)
+		m.users.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires a caller", func(t *testing.T) {
+		svc, _ := newProfileService()
+
+		_, err := svc.UpdateProfile(context.Background(), &domain.ProfileUpdate{DisplayName: "Alice"})
+
+		assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	})
+}
+
+func TestSendVerification(t *testing.T) {
+	ctx := port.WithActor(context.Background(), "1")
+
+	t.Run("texts a code to the phone and stores its hash", func(t *testing.T) {
+		svc, m := newProfileService()
+		m.users.On("GetUserById", mock.Anything, "1").Return(&domain.Users{Id: "1", Username: "alice", Phone: "+66812345678"}, nil)
+		var stored *domain.ContactVerification
+		m.verifications.On("SaveVerification", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*domain.ContactVerification)
+		}).Return(nil)
+		var sent domain.Sms
+		m.sms.On("SendSms", mock.Anything, mock.Anything).Run(func(args mock.Arguments) { sent = args.Get(1).(domain.Sms) }).Return(nil)
+
+		verification, err := svc.SendVerification(ctx, domain.ContactPhone)
+
+		require.NoError(t, err)
+		assert.Equal(t, "+66812345678", sent.To)
+		code := sixDigits.FindString(sent.Body)
+		require.NotEmpty(t, code, "message carries the code")
+		require.NotNil(t, stored)
+		assert.Equal(t, domain.VerificationKey("1", domain.ContactPhone), stored.Key)
+		assert.Equal(t, "+66812345678", stored.Target)
+		assert.Equal(t, hashVerificationCode(stored.Key, code), stored.CodeHash)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), verification.ExpiresAt, time.Minute)
+		m.mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+
+	t.Run("mails the code in the preferred language", func(t *testing.T) {
+		svc, m := newProfileService()
+		m.users.On("GetUserById", mock.Anything, "1").Return(&domain.Users{Id: "1", Username: "alice", Email: "alice@example.com", Language: "th"}, nil)
+		m.verifications.On("SaveVerification", mock.Anything, mock.Anything).Return(nil)
+		var sent domain.Mail
+		m.mailer.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) { sent = args.Get(1).(domain.Mail) }).Return(nil)
+
+		_, err := svc.SendVerification(ctx, domain.ContactEmail)
+
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", sent.To)
+		assert.Equal(t, verificationMessages[domain.LanguageThai].subject, sent.Subject)
+	})
+
+	t.Run("refuses a verified address", func(t *testing.T) {
+		svc, m := newProfileService()
+		m.users.On("GetUserById", mock.Anything, "1").Return(&domain.Users{Id: "1", Email: "alice@example.com", VerifiedEmail: "alice@example.com"}, nil)
+
+		_, err := svc.SendVerification(ctx, domain.ContactEmail)
+
+		assert.ErrorIs(t, err, domain.ErrContactAlreadyVerified)
+	})
+
+	t.Run("needs an address and a known channel", func(t *testing.T) {
+		svc, m := newProfileService()
+		m.users.On("GetUserById", mock.Anything, "1").Return(&domain.Users{Id: "1"}, nil)
+
+		_, err := svc.SendVerification(ctx, domain.ContactPhone)
+		assert.ErrorIs(t, err, domain.ErrValidation)
+
+		_, err = svc.SendVerification(ctx, "fax")
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestVerifyContact(t *testing.T) {
+	ctx := port.WithActor(context.Background(), "1")
+	key := domain.VerificationKey("1", domain.ContactPhone)
+	pending := func(attempts int) *domain.ContactVerification {
+		return &domain.ContactVerification{
+			Key: key, UserId: "1", Channel: domain.ContactPhone, Target: "+66812345678",
+			CodeHash: hashVerificationCode(key, "042917"), Attempts: attempts, ExpiresAt: time.Now().Add(time.Minute),
+		}
+	}
+	alice := &domain.Users{Id: "1", Phone: "+66812345678"}
+
+	t.Run("marks the phone verified and uses up the code", func(t *testing.T) {
+		svc, m := newProfileService()
+		m.users.On("GetUserById", mock.Anything, "1").Return(alice, nil)
+		m.verifications.On("GetVerification", mock.Anything, key).Return(pending(0), nil)
+		m.verifications.On("DeleteVerification", mock.Anything, key).Return(nil).Once()
+		m.users.On("UpdateUser", mock.Anything, "1", &domain.Users{VerifiedPhone: "+66812345678"}).
+			Return(&domain.Users{Id: "1", Phone: "+66812345678", VerifiedPhone: "+66812345678"}, nil)
+		m.auditLog.On("Append", mock.Anything, auditAction(domain.AuditActionContactVerified)).Return(nil)
+
+		user, err := svc.VerifyContact(ctx, &domain.VerifyContactRequest{Channel: domain.ContactPhone, Code: "042917"})
+
+		require.NoError(t, err)
+		assert.True(t, user.ContactVerified(domain.ContactPhone))
+		m.verifications.AssertExpectations(t)
+	})
+
+	t.Run("counts a wrong code", func(t *testing.T) {
+		svc, m := newProfileService()
+		m.users.On("GetUserById", mock.Anything, "1").Return(alice, nil)
+		m.verifications.On("GetVerification", mock.Anything, key).Return(pending(0), nil)
+		m.verifications.On("SaveVerification", mock.Anything, mock.MatchedBy(func(v *domain.ContactVerification) bool {
+			return v.Attempts == 1
+		})).Return(nil).Once()
+
+		_, err := svc.VerifyContact(ctx, &domain.VerifyContactRequest{Channel: domain.ContactPhone, Code: "000000"})
+
+		assert.ErrorIs(t, err, domain.ErrVerificationInvalid)
+		m.verifications.AssertExpectations(t)
+		m.users.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("discards the code after the last attempt", func(t *testing.T) {
+		svc, m := newProfileService()
+		m.users.On("GetUserById", mock.Anything, "1").Return(alice, nil)
+		m.verifications.On("GetVerification", mock.Anything, key).Return(pending(2), nil)
+		m.verifications.On("DeleteVerification", mock.Anything, key).Return(nil).Once()
+
+		_, err := svc.VerifyContact(ctx, &domain.VerifyContactRequest{Channel: domain.ContactPhone, Code: "000000"})
+
+		assert.ErrorIs(t, err, domain.ErrVerificationInvalid)
+		m.verifications.AssertExpectations(t)
+	})
+
+	t.Run("rejects a code sent to a previous phone", func(t *testing.T) {
+		svc, m := newProfileService()
+		m.users.On("GetUserById", mock.Anything, "1").Return(&domain.Users{Id: "1", Phone: "+66899999999"}, nil)
+		m.verifications.On("GetVerification", mock.Anything, key).Return(pending(0), nil)
+		m.verifications.On("DeleteVerification", mock.Anything, key).Return(nil).Once()
+
+		_, err := svc.VerifyContact(ctx, &domain.VerifyContactRequest{Channel: domain.ContactPhone, Code: "042917"})
+
+		assert.ErrorIs(t, err, domain.ErrVerificationInvalid)
+		m.users.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects when nothing was sent", func(t *testing.T) {
+		svc, m := newProfileService()
+		m.users.On("GetUserById", mock.Anything, "1").Return(alice, nil)
+		m.verifications.On("GetVerification", mock.Anything, key).Return(nil, domain.ErrNotFound)
+
+		_, err := svc.VerifyContact(ctx, &domain.VerifyContactRequest{Channel: domain.ContactPhone, Code: "042917"})
+
+		assert.ErrorIs(t, err, domain.ErrVerificationInvalid)
+	})
+}
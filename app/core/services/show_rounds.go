@@ -9,15 +9,44 @@ import (
 
 type ShowRoundService struct {
 	showRoundRepository port.ShowRoundsRepository
+	animalRepository    port.AnimalsRepository
+	stageRepository     port.PerformanceStageRepository
+	bookingsRepository  port.BookingsRepository
+	auditLog            port.AuditLogRepository
+	unitOfWork          port.UnitOfWork
 }
 
-func NewShowRoundService(showRoundRepository port.ShowRoundsRepository) *ShowRoundService {
+func NewShowRoundService(showRoundRepository port.ShowRoundsRepository, animalRepository port.AnimalsRepository, stageRepository port.PerformanceStageRepository, bookingsRepository port.BookingsRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork) *ShowRoundService {
 	return &ShowRoundService{
 		showRoundRepository: showRoundRepository,
+		animalRepository:    animalRepository,
+		stageRepository:     stageRepository,
+		bookingsRepository:  bookingsRepository,
+		auditLog:            auditLog,
+		unitOfWork:          unitOfWork,
 	}
 }
 
+// validateReferences checks that the animal and stage of a show round exist
+func (s *ShowRoundService) validateReferences(ctx context.Context, showRound *domain.ShowRounds) error {
+	_, err := s.animalRepository.GetAnimalById(ctx, showRound.AnimalId)
+	if err := ensureReference(err, "animal", showRound.AnimalId); err != nil {
+		return err
+	}
+
+	_, err = s.stageRepository.GetStageById(ctx, showRound.StageId)
+	return ensureReference(err, "stage", showRound.StageId)
+}
+
 func (s *ShowRoundService) CreateShowRound(ctx context.Context, showRound *domain.ShowRounds) (*domain.ShowRounds, error) {
+	if err := showRound.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateReferences(ctx, showRound); err != nil {
+		return nil, err
+	}
+
 	return s.showRoundRepository.CreateShowRound(ctx, showRound)
 }
 
@@ -25,14 +54,66 @@ func (s *ShowRoundService) GetShowRoundById(ctx context.Context, id string) (*do
 	return s.showRoundRepository.GetShowRoundById(ctx, id)
 }
 
-func (s *ShowRoundService) GetAllShowRounds(ctx context.Context) ([]*domain.ShowRounds, error) {
-	return s.showRoundRepository.GetAllShowRounds(ctx)
+func (s *ShowRoundService) GetAllShowRounds(ctx context.Context, filter port.ShowRoundFilter, query port.ListQuery) (*port.Page[*domain.ShowRounds], error) {
+	return s.showRoundRepository.GetAllShowRounds(ctx, filter, query)
 }
 
 func (s *ShowRoundService) UpdateShowRound(ctx context.Context, id string, showRound *domain.ShowRounds) (*domain.ShowRounds, error) {
-	return s.showRoundRepository.UpdateShowRound(ctx, id, showRound)
+	if err := showRound.ValidateUpdate(); err != nil {
+		return nil, err
+	}
+
+	var updated *domain.ShowRounds
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		existing, err := s.showRoundRepository.GetShowRoundById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// The update only names the fields that change, so check the show round it results in
+		merged := existing.Merge(showRound)
+		if err := s.validateReferences(ctx, &merged); err != nil {
+			return err
+		}
+
+		updated, err = s.showRoundRepository.UpdateShowRound(ctx, id, showRound)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
 }
 
-func (s *ShowRoundService) DeleteShowRound(ctx context.Context, id string) error {
-	return s.showRoundRepository.DeleteShowRound(ctx, id)
+// DeleteShowRound cancels a show round, refusing when it still has bookings unless cascade is requested.
+// The cancellation is recorded in the audit log.
+func (s *ShowRoundService) DeleteShowRound(ctx context.Context, id string, opts port.DeleteOptions) error {
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		showRound, err := s.showRoundRepository.GetShowRoundById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := domain.CheckVersion(opts.Version, showRound.Version); err != nil {
+			return err
+		}
+
+		bookings, err := s.bookingsRepository.GetBookingsByRoundId(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if len(bookings) > 0 {
+			if !opts.Cascade {
+				return referenceInUse("show round", id, len(bookings), "booking(s)")
+			}
+			if err := deleteBookings(ctx, s.bookingsRepository, bookings); err != nil {
+				return err
+			}
+		}
+
+		if err := s.showRoundRepository.DeleteShowRound(ctx, id); err != nil {
+			return err
+		}
+		return recordRoundCancelled(ctx, s.auditLog, showRound, len(bookings))
+	})
 }
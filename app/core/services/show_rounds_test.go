@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -31,8 +33,24 @@ func (m *MockShowRoundsRepository) GetShowRoundById(ctx context.Context, id stri
 	return args.Get(0).(*domain.ShowRounds), args.Error(1)
 }
 
-func (m *MockShowRoundsRepository) GetAllShowRounds(ctx context.Context) ([]*domain.ShowRounds, error) {
-	args := m.Called(ctx)
+func (m *MockShowRoundsRepository) GetAllShowRounds(ctx context.Context, filter port.ShowRoundFilter, query port.ListQuery) (*port.Page[*domain.ShowRounds], error) {
+	args := m.Called(ctx, filter, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*port.Page[*domain.ShowRounds]), args.Error(1)
+}
+
+func (m *MockShowRoundsRepository) GetShowRoundsByAnimalId(ctx context.Context, animalId string) ([]*domain.ShowRounds, error) {
+	args := m.Called(ctx, animalId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ShowRounds), args.Error(1)
+}
+
+func (m *MockShowRoundsRepository) GetShowRoundsByStageId(ctx context.Context, stageId string) ([]*domain.ShowRounds, error) {
+	args := m.Called(ctx, stageId)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -54,9 +72,16 @@ func (m *MockShowRoundsRepository) DeleteShowRound(ctx context.Context, id strin
 
 func TestCreateShowRound(t *testing.T) {
 	mockRepo := new(MockShowRoundsRepository)
-	showRoundService := NewShowRoundService(mockRepo)
+	mockAnimalRepo := new(MockAnimalsRepository)
+	mockStageRepo := new(MockPerformanceStageRepository)
+	showRoundService := NewShowRoundService(mockRepo, mockAnimalRepo, mockStageRepo, new(MockBookingsRepository), new(MockAuditLogRepository), stubUnitOfWork{})
 	ctx := context.Background()
 
+	mockAnimalRepo.On("GetAnimalById", ctx, "animal1").Return(&domain.Animals{Id: "animal1"}, nil)
+	mockAnimalRepo.On("GetAnimalById", ctx, "missing").Return(nil, domain.ErrNotFound)
+	mockStageRepo.On("GetStageById", ctx, "stage1").Return(&domain.PerformanceStage{Id: "stage1"}, nil)
+	mockStageRepo.On("GetStageById", ctx, "missing").Return(nil, domain.ErrNotFound)
+
 	t.Run("success", func(t *testing.T) {
 		showRound := &domain.ShowRounds{
 			Id:       "1",
@@ -92,22 +117,58 @@ func TestCreateShowRound(t *testing.T) {
 		assert.Nil(t, result)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("unknown animal", func(t *testing.T) {
+		showRound := &domain.ShowRounds{AnimalId: "missing", StageId: "stage1", ShowTime: "2025-01-01T09:00:00Z"}
+
+		result, err := showRoundService.CreateShowRound(ctx, showRound)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidReference)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "CreateShowRound", ctx, showRound)
+	})
+
+	t.Run("unknown stage", func(t *testing.T) {
+		showRound := &domain.ShowRounds{AnimalId: "animal1", StageId: "missing", ShowTime: "2025-01-01T09:00:00Z"}
+
+		result, err := showRoundService.CreateShowRound(ctx, showRound)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidReference)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "CreateShowRound", ctx, showRound)
+	})
+
+	t.Run("error looking up animal", func(t *testing.T) {
+		expectedErr := errors.New("database error")
+		mockAnimalRepo.On("GetAnimalById", ctx, "broken").Return(nil, expectedErr).Once()
+
+		result, err := showRoundService.CreateShowRound(ctx, &domain.ShowRounds{AnimalId: "broken", StageId: "stage1", ShowTime: "2025-01-01T09:00:00Z"})
+
+		assert.Equal(t, expectedErr, err)
+		assert.Nil(t, result)
+	})
 }
 
 func TestGetAllShowRounds(t *testing.T) {
 	mockRepo := new(MockShowRoundsRepository)
-	showRoundService := NewShowRoundService(mockRepo)
+	showRoundService := NewShowRoundService(mockRepo, new(MockAnimalsRepository), new(MockPerformanceStageRepository), new(MockBookingsRepository), new(MockAuditLogRepository), stubUnitOfWork{})
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
-		showRounds := []*domain.ShowRounds{
-			{
-				Id: "1",
+		from := time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)
+		filter := port.ShowRoundFilter{Species: "Panthera leo", From: &from}
+		query := port.ListQuery{Limit: 10}
+		showRounds := &port.Page[*domain.ShowRounds]{
+			Items: []*domain.ShowRounds{
+				{
+					Id: "1",
+				},
 			},
+			Total: 1,
 		}
-		mockRepo.On("GetAllShowRounds", ctx).Return(showRounds, nil).Once()
+		mockRepo.On("GetAllShowRounds", ctx, filter, query).Return(showRounds, nil).Once()
 
-		result, err := showRoundService.GetAllShowRounds(ctx)
+		result, err := showRoundService.GetAllShowRounds(ctx, filter, query)
 
 		assert.NoError(t, err)
 		assert.Equal(t, showRounds, result)
@@ -115,9 +176,9 @@ func TestGetAllShowRounds(t *testing.T) {
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		mockRepo.On("GetAllShowRounds", ctx).Return(nil, errors.New("database error")).Once()
+		mockRepo.On("GetAllShowRounds", ctx, port.ShowRoundFilter{}, port.ListQuery{}).Return(nil, errors.New("database error")).Once()
 
-		result, err := showRoundService.GetAllShowRounds(ctx)
+		result, err := showRoundService.GetAllShowRounds(ctx, port.ShowRoundFilter{}, port.ListQuery{})
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -127,7 +188,7 @@ func TestGetAllShowRounds(t *testing.T) {
 
 func TestGetShowRoundById(t *testing.T) {
 	mockRepo := new(MockShowRoundsRepository)
-	showRoundService := NewShowRoundService(mockRepo)
+	showRoundService := NewShowRoundService(mockRepo, new(MockAnimalsRepository), new(MockPerformanceStageRepository), new(MockBookingsRepository), new(MockAuditLogRepository), stubUnitOfWork{})
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
@@ -165,9 +226,18 @@ func TestGetShowRoundById(t *testing.T) {
 
 func TestUpdateShowRound(t *testing.T) {
 	mockRepo := new(MockShowRoundsRepository)
-	showRoundService := NewShowRoundService(mockRepo)
+	mockAnimalRepo := new(MockAnimalsRepository)
+	mockStageRepo := new(MockPerformanceStageRepository)
+	showRoundService := NewShowRoundService(mockRepo, mockAnimalRepo, mockStageRepo, new(MockBookingsRepository), new(MockAuditLogRepository), stubUnitOfWork{})
 	ctx := context.Background()
 
+	mockAnimalRepo.On("GetAnimalById", ctx, "animal1").Return(&domain.Animals{Id: "animal1"}, nil)
+	mockStageRepo.On("GetStageById", ctx, "stage2").Return(&domain.PerformanceStage{Id: "stage2"}, nil)
+	mockStageRepo.On("GetStageById", ctx, "missing").Return(nil, domain.ErrNotFound)
+	stored := func(id string) *domain.ShowRounds {
+		return &domain.ShowRounds{Id: id, AnimalId: "animal1", StageId: "stage2", Version: 1}
+	}
+
 	t.Run("success", func(t *testing.T) {
 		roundId := "1"
 		showRound := &domain.ShowRounds{
@@ -177,6 +247,7 @@ func TestUpdateShowRound(t *testing.T) {
 			ShowTime: "2023-06-15T16:00:00Z",
 		}
 
+		mockRepo.On("GetShowRoundById", ctx, roundId).Return(stored(roundId), nil).Once()
 		mockRepo.On("UpdateShowRound", ctx, roundId, showRound).Return(showRound, nil).Once()
 
 		result, err := showRoundService.UpdateShowRound(ctx, roundId, showRound)
@@ -196,6 +267,7 @@ func TestUpdateShowRound(t *testing.T) {
 		}
 		expectedErr := errors.New("show round not found")
 
+		mockRepo.On("GetShowRoundById", ctx, roundId).Return(stored(roundId), nil).Once()
 		mockRepo.On("UpdateShowRound", ctx, roundId, showRound).Return(nil, expectedErr).Once()
 
 		result, err := showRoundService.UpdateShowRound(ctx, roundId, showRound)
@@ -205,34 +277,146 @@ func TestUpdateShowRound(t *testing.T) {
 		assert.Nil(t, result)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("unknown stage", func(t *testing.T) {
+		roundId := "1"
+		showRound := &domain.ShowRounds{Id: roundId, AnimalId: "animal1", StageId: "missing"}
+		mockRepo.On("GetShowRoundById", ctx, roundId).Return(stored(roundId), nil).Once()
+
+		result, err := showRoundService.UpdateShowRound(ctx, roundId, showRound)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidReference)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "UpdateShowRound", ctx, roundId, showRound)
+	})
+
+	t.Run("partial update checks the stored references", func(t *testing.T) {
+		roundId := "1"
+		showRound := &domain.ShowRounds{ShowTime: "2023-06-15T18:00:00Z", Version: 1}
+		updated := &domain.ShowRounds{Id: roundId, AnimalId: "animal1", StageId: "stage2", ShowTime: showRound.ShowTime, Version: 2}
+
+		mockRepo.On("GetShowRoundById", ctx, roundId).Return(stored(roundId), nil).Once()
+		mockRepo.On("UpdateShowRound", ctx, roundId, showRound).Return(updated, nil).Once()
+
+		result, err := showRoundService.UpdateShowRound(ctx, roundId, showRound)
+
+		assert.NoError(t, err)
+		assert.Equal(t, updated, result)
+		mockAnimalRepo.AssertCalled(t, "GetAnimalById", ctx, "animal1")
+		mockStageRepo.AssertCalled(t, "GetStageById", ctx, "stage2")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		showRound := &domain.ShowRounds{ShowTime: "2023-06-15T18:00:00Z"}
+		mockRepo.On("GetShowRoundById", ctx, "gone").Return(nil, domain.ErrNotFound).Once()
+
+		result, err := showRoundService.UpdateShowRound(ctx, "gone", showRound)
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "UpdateShowRound", ctx, "gone", showRound)
+	})
 }
 
 func TestDeleteShowRound(t *testing.T) {
 	mockRepo := new(MockShowRoundsRepository)
-	showRoundService := NewShowRoundService(mockRepo)
+	mockBookingRepo := new(MockBookingsRepository)
+	mockAuditLog := new(MockAuditLogRepository)
+	showRoundService := NewShowRoundService(mockRepo, new(MockAnimalsRepository), new(MockPerformanceStageRepository), mockBookingRepo, mockAuditLog, stubUnitOfWork{})
 	ctx := context.Background()
 
+	showRound := func(id string) *domain.ShowRounds {
+		return &domain.ShowRounds{Id: id, AnimalId: "animal1", StageId: "stage1", ShowTime: "2025-01-01T09:00:00Z", Version: 1}
+	}
+	cancelled := func(id string, deletedBookings int) any {
+		return auditEntry(domain.AuditActionRoundCancelled, id,
+			map[string]any{"animal_id": "animal1", "stage_id": "stage1", "show_time": "2025-01-01T09:00:00Z"},
+			map[string]any{"deleted_bookings": deletedBookings},
+		)
+	}
+
 	t.Run("success", func(t *testing.T) {
 		roundId := "1"
 
+		mockRepo.On("GetShowRoundById", ctx, roundId).Return(showRound(roundId), nil).Once()
+		mockBookingRepo.On("GetBookingsByRoundId", ctx, roundId).Return([]domain.Bookings{}, nil).Once()
 		mockRepo.On("DeleteShowRound", ctx, roundId).Return(nil).Once()
+		mockAuditLog.On("Append", ctx, cancelled(roundId, 0)).Return(nil).Once()
 
-		err := showRoundService.DeleteShowRound(ctx, roundId)
+		err := showRoundService.DeleteShowRound(ctx, roundId, port.DeleteOptions{})
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
+		mockBookingRepo.AssertExpectations(t)
+		mockAuditLog.AssertExpectations(t)
 	})
 
 	t.Run("error", func(t *testing.T) {
 		roundId := "999"
-		expectedErr := errors.New("show round not found")
+		expectedErr := errors.New("database error")
 
+		mockRepo.On("GetShowRoundById", ctx, roundId).Return(showRound(roundId), nil).Once()
+		mockBookingRepo.On("GetBookingsByRoundId", ctx, roundId).Return([]domain.Bookings{}, nil).Once()
 		mockRepo.On("DeleteShowRound", ctx, roundId).Return(expectedErr).Once()
 
-		err := showRoundService.DeleteShowRound(ctx, roundId)
+		err := showRoundService.DeleteShowRound(ctx, roundId, port.DeleteOptions{})
 
 		assert.Error(t, err)
 		assert.Equal(t, expectedErr, err)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("not found", func(t *testing.T) {
+		roundId := "missing"
+
+		mockRepo.On("GetShowRoundById", ctx, roundId).Return(nil, domain.ErrNotFound).Once()
+
+		err := showRoundService.DeleteShowRound(ctx, roundId, port.DeleteOptions{})
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		mockRepo.AssertNotCalled(t, "DeleteShowRound", ctx, roundId)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		roundId := "4"
+
+		mockRepo.On("GetShowRoundById", ctx, roundId).Return(showRound(roundId), nil).Once()
+
+		err := showRoundService.DeleteShowRound(ctx, roundId, port.DeleteOptions{Version: 2})
+
+		assert.ErrorIs(t, err, domain.ErrVersionConflict)
+		mockRepo.AssertNotCalled(t, "DeleteShowRound", ctx, roundId)
+	})
+
+	t.Run("has bookings", func(t *testing.T) {
+		roundId := "3"
+		bookings := []domain.Bookings{{Id: "booking1", RoundId: roundId}}
+
+		mockRepo.On("GetShowRoundById", ctx, roundId).Return(showRound(roundId), nil).Once()
+		mockBookingRepo.On("GetBookingsByRoundId", ctx, roundId).Return(bookings, nil).Once()
+
+		err := showRoundService.DeleteShowRound(ctx, roundId, port.DeleteOptions{})
+
+		assert.ErrorIs(t, err, domain.ErrReferenceInUse)
+		mockRepo.AssertNotCalled(t, "DeleteShowRound", ctx, roundId)
+	})
+
+	t.Run("cascade", func(t *testing.T) {
+		roundId := "2"
+		bookings := []domain.Bookings{{Id: "booking2", RoundId: roundId}}
+
+		mockRepo.On("GetShowRoundById", ctx, roundId).Return(showRound(roundId), nil).Once()
+		mockBookingRepo.On("GetBookingsByRoundId", ctx, roundId).Return(bookings, nil).Once()
+		mockBookingRepo.On("DeleteBooking", ctx, "booking2").Return(nil).Once()
+		mockRepo.On("DeleteShowRound", ctx, roundId).Return(nil).Once()
+		mockAuditLog.On("Append", ctx, cancelled(roundId, 1)).Return(nil).Once()
+
+		err := showRoundService.DeleteShowRound(ctx, roundId, port.DeleteOptions{Cascade: true})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockBookingRepo.AssertExpectations(t)
+		mockAuditLog.AssertExpectations(t)
+	})
 }
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+)
+
+type SigningKeyService struct {
+	keys         port.SigningKeyRepository
+	ring         *utils.KeyRing
+	cipher       *utils.KeyCipher
+	algorithm    string
+	rotation     time.Duration
+	publishAhead time.Duration
+	retention    time.Duration
+}
+
+// NewSigningKeyService creates the service rotating the keys of ring. Each key signs for rotation, is created
+// publishAhead before it starts signing, and stays published for retention after it retires, which must cover
+// the longest lived token. Private keys are stored encrypted with cipher.
+func NewSigningKeyService(keys port.SigningKeyRepository, ring *utils.KeyRing, cipher *utils.KeyCipher, algorithm string, rotation, publishAhead, retention time.Duration) *SigningKeyService {
+	return &SigningKeyService{
+		keys:         keys,
+		ring:         ring,
+		cipher:       cipher,
+		algorithm:    algorithm,
+		rotation:     rotation,
+		publishAhead: publishAhead,
+		retention:    retention,
+	}
+}
+
+// Rotate loads the stored keys into the ring. The successor of the newest key is created once that key retires
+// within publishAhead and starts signing when it retires; only when no key is left to sign does a new one start
+// at once. Instances rotating at the same time may both create a successor, which does no harm: both are
+// published and the ring of every instance signs with the same one.
+func (s *SigningKeyService) Rotate(ctx context.Context) error {
+	now := port.AuditTime()
+	if _, err := s.keys.DeleteExpiredSigningKeys(ctx, now); err != nil {
+		return err
+	}
+	keys, err := s.keys.ListSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for i, key := range keys {
+		if keys[i], err = s.open(ctx, key); err != nil {
+			return fmt.Errorf("signing key %s: %w", key.Kid, err)
+		}
+	}
+
+	if len(keys) == 0 || !keys[len(keys)-1].RetiresAt.After(now.Add(s.publishAhead)) {
+		activatesAt := now
+		if len(keys) > 0 && keys[len(keys)-1].RetiresAt.After(now) {
+			activatesAt = keys[len(keys)-1].RetiresAt
+		}
+		key, err := utils.GenerateSigningKey(s.algorithm)
+		if err != nil {
+			return err
+		}
+		key.ActivatesAt = activatesAt
+		key.RetiresAt = activatesAt.Add(s.rotation)
+		key.ExpiresAt = key.RetiresAt.Add(s.retention)
+		sealed, err := s.cipher.Seal(*key)
+		if err != nil {
+			return err
+		}
+		if err := s.keys.CreateSigningKey(ctx, &sealed); err != nil {
+			return err
+		}
+		keys = append(keys, *key)
+	}
+
+	return s.ring.Replace(keys)
+}
+
+// open decrypts the private key of a stored key. Keys stored in plain PEM before private keys were encrypted are
+// encrypted in place.
+func (s *SigningKeyService) open(ctx context.Context, key domain.SigningKey) (domain.SigningKey, error) {
+	if utils.IsSealed(key) {
+		return s.cipher.Open(key)
+	}
+	sealed, err := s.cipher.Seal(key)
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+	if err := s.keys.UpdateSigningKeyPrivateKey(ctx, key.Kid, sealed.PrivateKey); err != nil {
+		return domain.SigningKey{}, err
+	}
+	return key, nil
+}
+
+// PublicKeys returns the keys tokens may currently be signed with, including the next one
+func (s *SigningKeyService) PublicKeys() []domain.JSONWebKey {
+	return s.ring.PublicKeys(time.Now())
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSigningKeyRepository is a mock of SigningKeyRepository interface
+type MockSigningKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockSigningKeyRepository) CreateSigningKey(ctx context.Context, key *domain.SigningKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockSigningKeyRepository) ListSigningKeys(ctx context.Context) ([]domain.SigningKey, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.SigningKey), args.Error(1)
+}
+
+func (m *MockSigningKeyRepository) UpdateSigningKeyPrivateKey(ctx context.Context, kid string, privateKey string) error {
+	args := m.Called(ctx, kid, privateKey)
+	return args.Error(0)
+}
+
+func (m *MockSigningKeyRepository) DeleteExpiredSigningKeys(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+const (
+	testKeyRotation     = 30 * 24 * time.Hour
+	testKeyPublishAhead = time.Hour
+	testKeyRetention    = 7 * 24 * time.Hour
+)
+
+// testKeyCipher encrypts the private keys the tests store
+var testKeyCipher = newTestKeyCipher()
+
+func newTestKeyCipher() *utils.KeyCipher {
+	cipher, err := utils.NewKeyCipher(base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	if err != nil {
+		panic(err)
+	}
+	return cipher
+}
+
+// sealed returns key as it is stored, with its private key encrypted
+func sealed(t *testing.T, key *domain.SigningKey) domain.SigningKey {
+	stored, err := testKeyCipher.Seal(*key)
+	require.NoError(t, err)
+	return stored
+}
+
+// newTestKeyRing returns a key ring holding a single key of algorithm that is active now
+func newTestKeyRing(t *testing.T, algorithm string) *utils.KeyRing {
+	key := newTestSigningKey(t, algorithm, time.Now().Add(-time.Hour))
+	ring := utils.NewKeyRing()
+	require.NoError(t, ring.Replace([]domain.SigningKey{*key}))
+	return ring
+}
+
+// newTestSigningKey returns a key of algorithm that starts signing at activatesAt
+func newTestSigningKey(t *testing.T, algorithm string, activatesAt time.Time) *domain.SigningKey {
+	key, err := utils.GenerateSigningKey(algorithm)
+	require.NoError(t, err)
+	key.ActivatesAt = activatesAt
+	key.RetiresAt = activatesAt.Add(testKeyRotation)
+	key.ExpiresAt = key.RetiresAt.Add(testKeyRetention)
+	return key
+}
+
+func newSigningKeyService(algorithm string) (*SigningKeyService, *MockSigningKeyRepository, *utils.KeyRing) {
+	keys := new(MockSigningKeyRepository)
+	ring := utils.NewKeyRing()
+	keys.On("DeleteExpiredSigningKeys", mock.Anything, mock.Anything).Return(int64(0), nil).Maybe()
+	return NewSigningKeyService(keys, ring, testKeyCipher, algorithm, testKeyRotation, testKeyPublishAhead, testKeyRetention), keys, ring
+}
+
+func TestRotateSigningKeys(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates an active key on a fresh store", func(t *testing.T) {
+		svc, keys, _ := newSigningKeyService(domain.SigningAlgorithmEdDSA)
+		keys.On("ListSigningKeys", ctx).Return([]domain.SigningKey{}, nil).Once()
+		var created *domain.SigningKey
+		keys.On("CreateSigningKey", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			created = args.Get(1).(*domain.SigningKey)
+		}).Return(nil).Once()
+
+		require.NoError(t, svc.Rotate(ctx))
+
+		require.NotNil(t, created)
+		assert.True(t, utils.IsSealed(*created), "the private key is stored encrypted")
+		assert.NotContains(t, created.PrivateKey, "PRIVATE KEY")
+		assert.Equal(t, domain.SigningAlgorithmEdDSA, created.Algorithm)
+		assert.True(t, created.Signs(time.Now()))
+		assert.Equal(t, testKeyRotation, created.RetiresAt.Sub(created.ActivatesAt))
+		assert.Equal(t, testKeyRetention, created.ExpiresAt.Sub(created.RetiresAt))
+		jwks := svc.PublicKeys()
+		require.Len(t, jwks, 1)
+		assert.Equal(t, created.Kid, jwks[0].Kid)
+		assert.Equal(t, "OKP", jwks[0].Kty)
+		assert.Equal(t, "Ed25519", jwks[0].Crv)
+		assert.NotEmpty(t, jwks[0].X)
+		keys.AssertExpectations(t)
+	})
+
+	t.Run("keeps the current key until it nears retirement", func(t *testing.T) {
+		svc, keys, _ := newSigningKeyService(domain.SigningAlgorithmEdDSA)
+		current := newTestSigningKey(t, domain.SigningAlgorithmEdDSA, time.Now().Add(-time.Hour))
+		keys.On("ListSigningKeys", ctx).Return([]domain.SigningKey{sealed(t, current)}, nil).Once()
+
+		require.NoError(t, svc.Rotate(ctx))
+
+		keys.AssertNotCalled(t, "CreateSigningKey", mock.Anything, mock.Anything)
+		assert.Len(t, svc.PublicKeys(), 1)
+	})
+
+	t.Run("publishes the successor before the current key retires", func(t *testing.T) {
+		svc, keys, _ := newSigningKeyService(domain.SigningAlgorithmRS256)
+		current := newTestSigningKey(t, domain.SigningAlgorithmRS256, time.Now().Add(-testKeyRotation+testKeyPublishAhead/2))
+		keys.On("ListSigningKeys", ctx).Return([]domain.SigningKey{sealed(t, current)}, nil).Once()
+		var created *domain.SigningKey
+		keys.On("CreateSigningKey", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			created = args.Get(1).(*domain.SigningKey)
+		}).Return(nil).Once()
+
+		require.NoError(t, svc.Rotate(ctx))
+
+		require.NotNil(t, created)
+		assert.True(t, created.ActivatesAt.Equal(current.RetiresAt))
+		assert.False(t, created.Signs(time.Now()))
+		jwks := svc.PublicKeys()
+		require.Len(t, jwks, 2)
+		assert.Equal(t, []string{current.Kid, created.Kid}, []string{jwks[0].Kid, jwks[1].Kid})
+		assert.Equal(t, "RSA", jwks[1].Kty)
+		assert.Equal(t, "AQAB", jwks[1].E)
+	})
+
+	t.Run("activates a new key at once when every key has retired", func(t *testing.T) {
+		svc, keys, _ := newSigningKeyService(domain.SigningAlgorithmEdDSA)
+		retired := newTestSigningKey(t, domain.SigningAlgorithmEdDSA, time.Now().Add(-testKeyRotation-time.Hour))
+		keys.On("ListSigningKeys", ctx).Return([]domain.SigningKey{sealed(t, retired)}, nil).Once()
+		var created *domain.SigningKey
+		keys.On("CreateSigningKey", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			created = args.Get(1).(*domain.SigningKey)
+		}).Return(nil).Once()
+
+		require.NoError(t, svc.Rotate(ctx))
+
+		require.NotNil(t, created)
+		assert.True(t, created.Signs(time.Now()))
+	})
+
+	t.Run("drops expired keys first", func(t *testing.T) {
+		keys := new(MockSigningKeyRepository)
+		svc := NewSigningKeyService(keys, utils.NewKeyRing(), testKeyCipher, domain.SigningAlgorithmEdDSA, testKeyRotation, testKeyPublishAhead, testKeyRetention)
+		current := newTestSigningKey(t, domain.SigningAlgorithmEdDSA, time.Now().Add(-time.Hour))
+		keys.On("DeleteExpiredSigningKeys", ctx, mock.MatchedBy(func(now time.Time) bool {
+			return time.Since(now) < time.Minute
+		})).Return(int64(2), nil).Once()
+		keys.On("ListSigningKeys", ctx).Return([]domain.SigningKey{sealed(t, current)}, nil).Once()
+
+		require.NoError(t, svc.Rotate(ctx))
+		keys.AssertExpectations(t)
+	})
+
+	t.Run("keeps the loaded keys when one cannot be decoded", func(t *testing.T) {
+		svc, keys, _ := newSigningKeyService(domain.SigningAlgorithmEdDSA)
+		current := newTestSigningKey(t, domain.SigningAlgorithmEdDSA, time.Now().Add(-time.Hour))
+		keys.On("ListSigningKeys", ctx).Return([]domain.SigningKey{sealed(t, current)}, nil).Once()
+		require.NoError(t, svc.Rotate(ctx))
+
+		broken := *current
+		broken.Kid, broken.PrivateKey = "broken", "not a key"
+		keys.On("ListSigningKeys", ctx).Return([]domain.SigningKey{sealed(t, current), sealed(t, &broken)}, nil).Once()
+
+		assert.Error(t, svc.Rotate(ctx))
+		require.Len(t, svc.PublicKeys(), 1)
+		assert.Equal(t, current.Kid, svc.PublicKeys()[0].Kid)
+	})
+
+	t.Run("encrypts keys stored in plain PEM", func(t *testing.T) {
+		svc, keys, _ := newSigningKeyService(domain.SigningAlgorithmEdDSA)
+		current := newTestSigningKey(t, domain.SigningAlgorithmEdDSA, time.Now().Add(-time.Hour))
+		keys.On("ListSigningKeys", ctx).Return([]domain.SigningKey{*current}, nil).Once()
+		keys.On("UpdateSigningKeyPrivateKey", ctx, current.Kid, mock.MatchedBy(func(privateKey string) bool {
+			opened, err := testKeyCipher.Open(domain.SigningKey{Kid: current.Kid, PrivateKey: privateKey})
+			return err == nil && opened.PrivateKey == current.PrivateKey
+		})).Return(nil).Once()
+
+		require.NoError(t, svc.Rotate(ctx))
+
+		keys.AssertExpectations(t)
+		require.Len(t, svc.PublicKeys(), 1)
+		assert.Equal(t, current.Kid, svc.PublicKeys()[0].Kid)
+	})
+
+	t.Run("refuses keys encrypted with another key-encryption key", func(t *testing.T) {
+		other, err := utils.NewKeyCipher(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+		require.NoError(t, err)
+		svc, keys, _ := newSigningKeyService(domain.SigningAlgorithmEdDSA)
+		current := newTestSigningKey(t, domain.SigningAlgorithmEdDSA, time.Now().Add(-time.Hour))
+		stored, err := other.Seal(*current)
+		require.NoError(t, err)
+		keys.On("ListSigningKeys", ctx).Return([]domain.SigningKey{stored}, nil).Once()
+
+		assert.ErrorContains(t, svc.Rotate(ctx), "cannot be decrypted")
+		keys.AssertNotCalled(t, "CreateSigningKey", mock.Anything, mock.Anything)
+		assert.Empty(t, svc.PublicKeys())
+	})
+}
+
+func TestSignedTokens(t *testing.T) {
+	t.Setenv("JWT_ACCESS_DURATION", "15m")
+	t.Setenv("JWT_REFRESH_DURATION", "168h")
+	user := &domain.Users{Id: "u1", Username: "alice", Role: domain.RoleUser}
+
+	for _, algorithm := range []string{domain.SigningAlgorithmRS256, domain.SigningAlgorithmEdDSA} {
+		t.Run(algorithm+" tokens name their key and verify", func(t *testing.T) {
+			ring := newTestKeyRing(t, algorithm)
+			jwtService, err := utils.NewJWTService(ring)
+			require.NoError(t, err)
+
+			token, err := jwtService.GenerateAccessToken(user)
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, algorithm, parsed.Header["alg"])
+			assert.Equal(t, ring.PublicKeys(time.Now())[0].Kid, parsed.Header["kid"])
+
+			claims, err := jwtService.VerifyAccessToken(token)
+			require.NoError(t, err)
+			assert.Equal(t, "u1", claims.UserID)
+		})
+	}
+
+	t.Run("tokens of a retired key verify until it expires", func(t *testing.T) {
+		old := newTestSigningKey(t, domain.SigningAlgorithmEdDSA, time.Now().Add(-time.Hour))
+		ring := utils.NewKeyRing()
+		require.NoError(t, ring.Replace([]domain.SigningKey{*old}))
+		jwtService, err := utils.NewJWTService(ring)
+		require.NoError(t, err)
+		token, err := jwtService.GenerateRefreshToken(user)
+		require.NoError(t, err)
+
+		old.RetiresAt = time.Now().Add(-time.Minute)
+		successor := newTestSigningKey(t, domain.SigningAlgorithmEdDSA, old.RetiresAt)
+		require.NoError(t, ring.Replace([]domain.SigningKey{*old, *successor}))
+		_, err = jwtService.VerifyRefreshToken(token)
+		assert.NoError(t, err)
+
+		require.NoError(t, ring.Replace([]domain.SigningKey{*successor}))
+		_, err = jwtService.VerifyRefreshToken(token)
+		assert.ErrorIs(t, err, utils.ErrInvalidToken)
+	})
+
+	t.Run("nothing is signed before the keys are loaded", func(t *testing.T) {
+		jwtService, err := utils.NewJWTService(utils.NewKeyRing())
+		require.NoError(t, err)
+
+		_, err = jwtService.GenerateAccessToken(user)
+		assert.ErrorIs(t, err, utils.ErrNoSigningKey)
+	})
+
+	legacyToken := func(t *testing.T, kid string, secret []byte) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id": "u1",
+			"type":    utils.AccessTokenType,
+			"iat":     time.Now().Unix(),
+			"exp":     time.Now().Add(time.Minute).Unix(),
+		})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(secret)
+		require.NoError(t, err)
+		return signed
+	}
+
+	t.Run("HS256 tokens verify only with the legacy secret", func(t *testing.T) {
+		token := legacyToken(t, "", []byte("legacy-secret"))
+
+		jwtService, err := utils.NewJWTService(newTestKeyRing(t, domain.SigningAlgorithmRS256))
+		require.NoError(t, err)
+		_, err = jwtService.VerifyAccessToken(token)
+		assert.ErrorIs(t, err, utils.ErrInvalidToken)
+
+		t.Setenv("JWT_SECRET", "legacy-secret")
+		jwtService, err = utils.NewJWTService(newTestKeyRing(t, domain.SigningAlgorithmRS256))
+		require.NoError(t, err)
+		claims, err := jwtService.VerifyAccessToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, "u1", claims.UserID)
+	})
+
+	t.Run("a public key cannot serve as an HMAC secret", func(t *testing.T) {
+		ring := newTestKeyRing(t, domain.SigningAlgorithmEdDSA)
+		jwtService, err := utils.NewJWTService(ring)
+		require.NoError(t, err)
+		jwk := ring.PublicKeys(time.Now())[0]
+
+		_, err = jwtService.VerifyAccessToken(legacyToken(t, jwk.Kid, []byte(jwk.X)))
+		assert.ErrorIs(t, err, utils.ErrInvalidToken)
+	})
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+)
+
+type TrashService struct {
+	trashRepository     port.TrashRepository
+	usersRepository     port.UsersRepository
+	animalRepository    port.AnimalsRepository
+	stageRepository     port.PerformanceStageRepository
+	showRoundRepository port.ShowRoundsRepository
+	bookingsRepository  port.BookingsRepository
+	unitOfWork          port.UnitOfWork
+	retention           time.Duration
+}
+
+// NewTrashService creates the service managing soft-deleted entities; retention is how long they are kept before PurgeExpired removes them
+func NewTrashService(trashRepository port.TrashRepository, usersRepository port.UsersRepository, animalRepository port.AnimalsRepository, stageRepository port.PerformanceStageRepository, showRoundRepository port.ShowRoundsRepository, bookingsRepository port.BookingsRepository, unitOfWork port.UnitOfWork, retention time.Duration) *TrashService {
+	return &TrashService{
+		trashRepository:     trashRepository,
+		usersRepository:     usersRepository,
+		animalRepository:    animalRepository,
+		stageRepository:     stageRepository,
+		showRoundRepository: showRoundRepository,
+		bookingsRepository:  bookingsRepository,
+		unitOfWork:          unitOfWork,
+		retention:           retention,
+	}
+}
+
+// ListTrash lists the soft-deleted entities of a kind, most recently deleted first unless the query sorts otherwise
+func (s *TrashService) ListTrash(ctx context.Context, kind port.TrashKind, query port.ListQuery) (*port.Page[port.TrashItem], error) {
+	if len(query.Sort) == 0 {
+		query.Sort = []port.SortField{{Field: "deleted_at", Desc: true}}
+	}
+	return s.trashRepository.ListTrash(ctx, kind, port.TrashFilter{}, query)
+}
+
+// Restore brings back a soft-deleted entity. Entities are restored one at a time, so the owners of a show round
+// or booking have to be restored before it; a booking whose seat was taken in the meantime cannot come back.
+func (s *TrashService) Restore(ctx context.Context, kind port.TrashKind, id string) error {
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		item, err := s.trashRepository.GetTrashItem(ctx, kind, id)
+		if err != nil {
+			return err
+		}
+
+		switch entity := item.Entity.(type) {
+		case *domain.ShowRounds:
+			_, err := s.animalRepository.GetAnimalById(ctx, entity.AnimalId)
+			if err := ensureReference(err, "animal", entity.AnimalId); err != nil {
+				return err
+			}
+			_, err = s.stageRepository.GetStageById(ctx, entity.StageId)
+			if err := ensureReference(err, "stage", entity.StageId); err != nil {
+				return err
+			}
+		case *domain.Bookings:
+			if err := s.checkBookingRestorable(ctx, entity); err != nil {
+				return err
+			}
+		}
+
+		return s.trashRepository.Restore(ctx, kind, id)
+	})
+}
+
+// checkBookingRestorable checks that the show round and user of a booking are live and that its seat is still free
+func (s *TrashService) checkBookingRestorable(ctx context.Context, booking *domain.Bookings) error {
+	_, err := s.showRoundRepository.GetShowRoundById(ctx, booking.RoundId)
+	if err := ensureReference(err, "show round", booking.RoundId); err != nil {
+		return err
+	}
+	if booking.UserId != "" {
+		_, err := s.usersRepository.GetUserById(ctx, booking.UserId)
+		if err := ensureReference(err, "user", booking.UserId); err != nil {
+			return err
+		}
+	}
+
+	if booking.IsCancelled() {
+		return nil
+	}
+	bookings, err := s.bookingsRepository.GetBookingsByRoundId(ctx, booking.RoundId)
+	if err != nil {
+		return err
+	}
+	for _, other := range bookings {
+		if other.SeatNumber == booking.SeatNumber && !other.IsCancelled() {
+			return fmt.Errorf("%w: seat %d of show round %q has been booked again", domain.ErrAlreadyExists, booking.SeatNumber, booking.RoundId)
+		}
+	}
+	return nil
+}
+
+// Purge permanently removes a soft-deleted entity, together with its soft-deleted dependents
+func (s *TrashService) Purge(ctx context.Context, kind port.TrashKind, id string) error {
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.trashRepository.GetTrashItem(ctx, kind, id); err != nil {
+			return err
+		}
+		if err := s.checkNoLiveDependents(ctx, kind, id); err != nil {
+			return err
+		}
+		return s.trashRepository.Purge(ctx, kind, id)
+	})
+}
+
+// checkNoLiveDependents rejects purging an entity that live entities still reference
+func (s *TrashService) checkNoLiveDependents(ctx context.Context, kind port.TrashKind, id string) error {
+	var count int
+	var dependents string
+	switch kind {
+	case port.TrashUsers, port.TrashShowRounds:
+		get := s.bookingsRepository.GetBookingsByUserId
+		if kind == port.TrashShowRounds {
+			get = s.bookingsRepository.GetBookingsByRoundId
+		}
+		bookings, err := get(ctx, id)
+		if err != nil {
+			return err
+		}
+		count, dependents = len(bookings), "booking(s)"
+	case port.TrashAnimals, port.TrashStages:
+		get := s.showRoundRepository.GetShowRoundsByAnimalId
+		if kind == port.TrashStages {
+			get = s.showRoundRepository.GetShowRoundsByStageId
+		}
+		showRounds, err := get(ctx, id)
+		if err != nil {
+			return err
+		}
+		count, dependents = len(showRounds), "show round(s)"
+	}
+
+	if count > 0 {
+		return fmt.Errorf("%w: %s %q is still referenced by %d live %s", domain.ErrReferenceInUse, kind, id, count, dependents)
+	}
+	return nil
+}
+
+// PurgeExpired purges everything deleted longer ago than the retention period, dependents first.
+// Each entity is purged in its own unit of work; entities that live ones still reference are skipped.
+func (s *TrashService) PurgeExpired(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.retention)
+	filter := port.TrashFilter{DeletedBefore: &cutoff}
+
+	purged := 0
+	for _, kind := range port.TrashKinds {
+		skipped := 0
+		for {
+			query := port.ListQuery{Limit: port.MaxPageLimit, Offset: skipped, Sort: []port.SortField{{Field: "id"}}}
+			page, err := s.trashRepository.ListTrash(ctx, kind, filter, query)
+			if err != nil {
+				return purged, err
+			}
+			if len(page.Items) == 0 {
+				break
+			}
+
+			for _, item := range page.Items {
+				err := s.Purge(ctx, kind, item.Id)
+				switch {
+				case err == nil:
+					purged++
+				case errors.Is(err, domain.ErrReferenceInUse):
+					skipped++
+				case errors.Is(err, domain.ErrNotFound):
+					// Already removed together with the entity it belonged to
+				default:
+					return purged, err
+				}
+			}
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTrashRepository is a mock of TrashRepository interface
+type MockTrashRepository struct {
+	mock.Mock
+}
+
+func (m *MockTrashRepository) ListTrash(ctx context.Context, kind port.TrashKind, filter port.TrashFilter, query port.ListQuery) (*port.Page[port.TrashItem], error) {
+	args := m.Called(ctx, kind, filter, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*port.Page[port.TrashItem]), args.Error(1)
+}
+
+func (m *MockTrashRepository) GetTrashItem(ctx context.Context, kind port.TrashKind, id string) (*port.TrashItem, error) {
+	args := m.Called(ctx, kind, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*port.TrashItem), args.Error(1)
+}
+
+func (m *MockTrashRepository) Restore(ctx context.Context, kind port.TrashKind, id string) error {
+	args := m.Called(ctx, kind, id)
+	return args.Error(0)
+}
+
+func (m *MockTrashRepository) Purge(ctx context.Context, kind port.TrashKind, id string) error {
+	args := m.Called(ctx, kind, id)
+	return args.Error(0)
+}
+
+// trashMocks bundles the repositories a TrashService talks to
+type trashMocks struct {
+	trash      *MockTrashRepository
+	users      *MockUsersRepository
+	animals    *MockAnimalsRepository
+	stages     *MockPerformanceStageRepository
+	showRounds *MockShowRoundsRepository
+	bookings   *MockBookingsRepository
+}
+
+func newTrashService(retention time.Duration) (*TrashService, trashMocks) {
+	m := trashMocks{
+		trash:      new(MockTrashRepository),
+		users:      new(MockUsersRepository),
+		animals:    new(MockAnimalsRepository),
+		stages:     new(MockPerformanceStageRepository),
+		showRounds: new(MockShowRoundsRepository),
+		bookings:   new(MockBookingsRepository),
+	}
+	return NewTrashService(m.trash, m.users, m.animals, m.stages, m.showRounds, m.bookings, stubUnitOfWork{}, retention), m
+}
+
+func TestListTrash(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("newest first by default", func(t *testing.T) {
+		service, m := newTrashService(time.Hour)
+		query := port.ListQuery{Sort: []port.SortField{{Field: "deleted_at", Desc: true}}}
+		m.trash.On("ListTrash", ctx, port.TrashAnimals, port.TrashFilter{}, query).Return(&port.Page[port.TrashItem]{}, nil).Once()
+
+		_, err := service.ListTrash(ctx, port.TrashAnimals, port.ListQuery{})
+
+		assert.NoError(t, err)
+		m.trash.AssertExpectations(t)
+	})
+
+	t.Run("keeps the requested order", func(t *testing.T) {
+		service, m := newTrashService(time.Hour)
+		query := port.ListQuery{Sort: port.ParseSort("id")}
+		m.trash.On("ListTrash", ctx, port.TrashAnimals, port.TrashFilter{}, query).Return(&port.Page[port.TrashItem]{}, nil).Once()
+
+		_, err := service.ListTrash(ctx, port.TrashAnimals, query)
+
+		assert.NoError(t, err)
+		m.trash.AssertExpectations(t)
+	})
+}
+
+func TestRestoreTrash(t *testing.T) {
+	ctx := context.Background()
+	deletedAt := time.Now()
+	showRound := &domain.ShowRounds{Id: "r1", AnimalId: "a1", StageId: "s1", DeletedAt: &deletedAt}
+	booking := &domain.Bookings{Id: "b1", UserId: "u1", RoundId: "r1", SeatNumber: 4, Status: domain.BookingStatusConfirmed, DeletedAt: &deletedAt}
+
+	t.Run("show round", func(t *testing.T) {
+		service, m := newTrashService(time.Hour)
+		item := port.NewTrashItem(port.TrashShowRounds, showRound)
+		m.trash.On("GetTrashItem", ctx, port.TrashShowRounds, "r1").Return(&item, nil).Once()
+		m.animals.On("GetAnimalById", ctx, "a1").Return(&domain.Animals{Id: "a1"}, nil).Once()
+		m.stages.On("GetStageById", ctx, "s1").Return(&domain.PerformanceStage{Id: "s1"}, nil).Once()
+		m.trash.On("Restore", ctx, port.TrashShowRounds, "r1").Return(nil).Once()
+
+		err := service.Restore(ctx, port.TrashShowRounds, "r1")
+
+		assert.NoError(t, err)
+		m.trash.AssertExpectations(t)
+	})
+
+	t.Run("show round of a deleted animal", func(t *testing.T) {
+		service, m := newTrashService(time.Hour)
+		item := port.NewTrashItem(port.TrashShowRounds, showRound)
+		m.trash.On("GetTrashItem", ctx, port.TrashShowRounds, "r1").Return(&item, nil).Once()
+		m.animals.On("GetAnimalById", ctx, "a1").Return(nil, domain.ErrNotFound).Once()
+
+		err := service.Restore(ctx, port.TrashShowRounds, "r1")
+
+		assert.ErrorIs(t, err, domain.ErrInvalidReference)
+		m.trash.AssertNotCalled(t, "Restore", ctx, port.TrashShowRounds, "r1")
+	})
+
+	t.Run("booking whose seat was taken again", func(t *testing.T) {
+		service, m := newTrashService(time.Hour)
+		item := port.NewTrashItem(port.TrashBookings, booking)
+		m.trash.On("GetTrashItem", ctx, port.TrashBookings, "b1").Return(&item, nil).Once()
+		m.showRounds.On("GetShowRoundById", ctx, "r1").Return(&domain.ShowRounds{Id: "r1"}, nil).Once()
+		m.users.On("GetUserById", ctx, "u1").Return(&domain.Users{Id: "u1"}, nil).Once()
+		m.bookings.On("GetBookingsByRoundId", ctx, "r1").Return([]domain.Bookings{
+			{Id: "b2", RoundId: "r1", SeatNumber: 4, Status: domain.BookingStatusConfirmed},
+		}, nil).Once()
+
+		err := service.Restore(ctx, port.TrashBookings, "b1")
+
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+		m.trash.AssertNotCalled(t, "Restore", ctx, port.TrashBookings, "b1")
+	})
+
+	t.Run("not in the trash", func(t *testing.T) {
+		service, m := newTrashService(time.Hour)
+		m.trash.On("GetTrashItem", ctx, port.TrashUsers, "u1").Return(nil, domain.ErrNotFound).Once()
+
+		err := service.Restore(ctx, port.TrashUsers, "u1")
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestPurgeTrash(t *testing.T) {
+	ctx := context.Background()
+	deletedAt := time.Now()
+	item := port.NewTrashItem(port.TrashAnimals, &domain.Animals{Id: "a1", DeletedAt: &deletedAt})
+
+	t.Run("success", func(t *testing.T) {
+		service, m := newTrashService(time.Hour)
+		m.trash.On("GetTrashItem", ctx, port.TrashAnimals, "a1").Return(&item, nil).Once()
+		m.showRounds.On("GetShowRoundsByAnimalId", ctx, "a1").Return([]*domain.ShowRounds{}, nil).Once()
+		m.trash.On("Purge", ctx, port.TrashAnimals, "a1").Return(nil).Once()
+
+		err := service.Purge(ctx, port.TrashAnimals, "a1")
+
+		assert.NoError(t, err)
+		m.trash.AssertExpectations(t)
+	})
+
+	t.Run("still referenced by live show rounds", func(t *testing.T) {
+		service, m := newTrashService(time.Hour)
+		m.trash.On("GetTrashItem", ctx, port.TrashAnimals, "a1").Return(&item, nil).Once()
+		m.showRounds.On("GetShowRoundsByAnimalId", ctx, "a1").Return([]*domain.ShowRounds{{Id: "r1"}}, nil).Once()
+
+		err := service.Purge(ctx, port.TrashAnimals, "a1")
+
+		assert.ErrorIs(t, err, domain.ErrReferenceInUse)
+		m.trash.AssertNotCalled(t, "Purge", ctx, port.TrashAnimals, "a1")
+	})
+}
+
+func TestPurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	retention := 24 * time.Hour
+	service, m := newTrashService(retention)
+	deletedAt := time.Now().Add(-48 * time.Hour)
+	expiredBooking := port.NewTrashItem(port.TrashBookings, &domain.Bookings{Id: "b1", DeletedAt: &deletedAt})
+	usedAnimal := port.NewTrashItem(port.TrashAnimals, &domain.Animals{Id: "a1", DeletedAt: &deletedAt})
+
+	withinRetention := mock.MatchedBy(func(filter port.TrashFilter) bool {
+		return filter.DeletedBefore != nil && time.Since(*filter.DeletedBefore) >= retention
+	})
+	page := func(offset int) port.ListQuery {
+		return port.ListQuery{Limit: port.MaxPageLimit, Offset: offset, Sort: []port.SortField{{Field: "id"}}}
+	}
+	empty := &port.Page[port.TrashItem]{}
+
+	m.trash.On("ListTrash", ctx, port.TrashBookings, withinRetention, page(0)).Return(&port.Page[port.TrashItem]{Items: []port.TrashItem{expiredBooking}, Total: 1}, nil).Once()
+	m.trash.On("ListTrash", ctx, port.TrashBookings, withinRetention, page(0)).Return(empty, nil).Once()
+	m.trash.On("GetTrashItem", ctx, port.TrashBookings, "b1").Return(&expiredBooking, nil).Once()
+	m.trash.On("Purge", ctx, port.TrashBookings, "b1").Return(nil).Once()
+
+	m.trash.On("ListTrash", ctx, port.TrashAnimals, withinRetention, page(0)).Return(&port.Page[port.TrashItem]{Items: []port.TrashItem{usedAnimal}, Total: 1}, nil).Once()
+	m.trash.On("ListTrash", ctx, port.TrashAnimals, withinRetention, page(1)).Return(empty, nil).Once()
+	m.trash.On("GetTrashItem", ctx, port.TrashAnimals, "a1").Return(&usedAnimal, nil).Once()
+	m.showRounds.On("GetShowRoundsByAnimalId", ctx, "a1").Return([]*domain.ShowRounds{{Id: "r1"}}, nil).Once()
+
+	for _, kind := range []port.TrashKind{port.TrashShowRounds, port.TrashUsers, port.TrashStages} {
+		m.trash.On("ListTrash", ctx, kind, withinRetention, page(0)).Return(empty, nil).Once()
+	}
+
+	purged, err := service.PurgeExpired(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	m.trash.AssertExpectations(t)
+	m.trash.AssertNotCalled(t, "Purge", ctx, port.TrashAnimals, "a1")
+}
@@ -2,37 +2,229 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
 	"github.com/khunmostz/be-liongate-go/app/core/port"
+	"github.com/khunmostz/be-liongate-go/app/utils"
 )
 
 type UserService struct {
-	usersRepository port.UsersRepository
+	usersRepository    port.UsersRepository
+	bookingsRepository port.BookingsRepository
+	sessions           port.SessionRepository
+	auditLog           port.AuditLogRepository
+	unitOfWork         port.UnitOfWork
+	hasher             *utils.PasswordHasher
 }
 
-func NewUsersService(usersRepository port.UsersRepository) *UserService {
+func NewUsersService(usersRepository port.UsersRepository, bookingsRepository port.BookingsRepository, sessions port.SessionRepository, auditLog port.AuditLogRepository, unitOfWork port.UnitOfWork, hasher *utils.PasswordHasher) *UserService {
 	return &UserService{
-		usersRepository: usersRepository,
+		usersRepository:    usersRepository,
+		bookingsRepository: bookingsRepository,
+		sessions:           sessions,
+		auditLog:           auditLog,
+		unitOfWork:         unitOfWork,
+		hasher:             hasher,
 	}
 }
 
+// Register signs up a user. Public registration always creates a user with RoleUser, whatever role was asked for;
+// admins are created by BootstrapAdmin or through invitations.
 func (s *UserService) Register(ctx context.Context, user *domain.Users) (*domain.Users, error) {
-	return s.usersRepository.CreateUser(ctx, user)
+	registered := &domain.Users{Username: user.Username, Password: user.Password, Email: user.Email, Role: domain.RoleUser}
+	registered.NormalizeContact()
+	if err := domain.JoinValidation(registered.Validate(), checkPasswordStrength(s.hasher, "password", registered.Password)); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.hasher.Hash(registered.Password)
+	if err != nil {
+		return nil, err
+	}
+	registered.Password = hashedPassword
+
+	return s.usersRepository.CreateUser(ctx, registered)
+}
+
+// BootstrapAdmin creates the first admin of an installation. It refuses once any admin exists, so that it cannot
+// be used to take over a running installation; further admins are invited by an existing one.
+func (s *UserService) BootstrapAdmin(ctx context.Context, user *domain.Users) (*domain.Users, error) {
+	admin := &domain.Users{Username: user.Username, Password: user.Password, Role: domain.RoleAdmin}
+	if err := domain.JoinValidation(admin.Validate(), checkPasswordStrength(s.hasher, "password", admin.Password)); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.hasher.Hash(admin.Password)
+	if err != nil {
+		return nil, err
+	}
+	admin.Password = hashedPassword
+
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		admins, err := s.usersRepository.GetUsersByRole(ctx, domain.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		if len(admins) > 0 {
+			return fmt.Errorf("%w: an admin already exists, invite further admins instead", domain.ErrAlreadyExists)
+		}
+
+		if _, err := s.usersRepository.CreateUser(ctx, admin); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionAdminBootstrapped, domain.AuditEntityUser, admin.Id, nil, map[string]any{"username": admin.Username})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return admin, nil
 }
 
+// GetUserById returns a user to themselves or to an admin
 func (s *UserService) GetUserById(ctx context.Context, id string) (*domain.Users, error) {
+	if err := s.requireSelfOrAdmin(ctx, id); err != nil {
+		return nil, err
+	}
 	return s.usersRepository.GetUserById(ctx, id)
 }
 
+// GetUsersByRole lists the users with a role; only admins may list users
 func (s *UserService) GetUsersByRole(ctx context.Context, role string) ([]domain.Users, error) {
+	if err := s.requireAdmin(ctx, "list users"); err != nil {
+		return nil, err
+	}
 	return s.usersRepository.GetUsersByRole(ctx, role)
 }
 
-func (s *UserService) UpdateUser(ctx context.Context, id string, user *domain.Users) (*domain.Users, error) {
-	return s.usersRepository.UpdateUser(ctx, id, user)
+// UpdateUser changes a user on behalf of the user themselves or an admin. Only an admin may change a role, and
+// users changing their own password must give the current one. A new password revokes every session of the user.
+// Role and password changes are recorded in the audit log in the same unit of work.
+func (s *UserService) UpdateUser(ctx context.Context, id string, user *domain.Users, currentPassword string) (*domain.Users, error) {
+	if err := s.requireSelfOrAdmin(ctx, id); err != nil {
+		return nil, err
+	}
+
+	user.NormalizeContact()
+	if err := domain.JoinValidation(user.ValidateUpdate(), checkPasswordStrength(s.hasher, "password", user.Password)); err != nil {
+		return nil, err
+	}
+
+	changesPassword := user.Password != ""
+	if changesPassword {
+		hashedPassword, err := s.hasher.Hash(user.Password)
+		if err != nil {
+			return nil, err
+		}
+		user.Password = hashedPassword
+	}
+
+	var updated *domain.Users
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		existing, err := s.usersRepository.GetUserById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if user.Role != "" && user.Role != existing.Role {
+			if err := s.requireAdmin(ctx, "change roles"); err != nil {
+				return err
+			}
+		}
+		if changesPassword && port.ActorFromContext(ctx) == id {
+			if err := s.checkCurrentPassword(existing, currentPassword); err != nil {
+				return err
+			}
+		}
+
+		updated, err = s.usersRepository.UpdateUser(ctx, id, user)
+		if err != nil {
+			return err
+		}
+
+		if changesPassword {
+			now := port.AuditTime()
+			if err := s.sessions.RevokeSessions(ctx, id, now); err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, s.auditLog, domain.AuditActionPasswordChanged, domain.AuditEntityUser, id, nil,
+				map[string]any{"sessions_revoked_at": now}); err != nil {
+				return err
+			}
+		}
+		if updated.Role == existing.Role {
+			return nil
+		}
+		before := map[string]any{"role": existing.Role}
+		after := map[string]any{"role": updated.Role}
+		return recordAudit(ctx, s.auditLog, domain.AuditActionRoleChanged, domain.AuditEntityUser, id, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// checkCurrentPassword returns a validation error unless currentPassword is the password of user
+func (s *UserService) checkCurrentPassword(user *domain.Users, currentPassword string) error {
+	var v domain.Validation
+	if currentPassword == "" {
+		v.Add("current_password", domain.RuleRequired, nil)
+	} else if !s.hasher.Verify(user.Password, currentPassword) {
+		v.Add("current_password", domain.RuleInvalid, nil)
+	}
+	return v.Err()
+}
+
+// requireSelfOrAdmin returns ErrForbidden unless the actor of ctx is the user id or an admin
+func (s *UserService) requireSelfOrAdmin(ctx context.Context, id string) error {
+	if actor := port.ActorFromContext(ctx); actor != domain.AnonymousActor && actor == id {
+		return nil
+	}
+	return s.requireAdmin(ctx, "act on other users")
+}
+
+// requireAdmin returns ErrForbidden unless the actor of ctx is an admin; action completes "only an admin can"
+func (s *UserService) requireAdmin(ctx context.Context, action string) error {
+	actor := port.ActorFromContext(ctx)
+	if actor == domain.AnonymousActor {
+		return fmt.Errorf("%w: only an admin can %s", domain.ErrForbidden, action)
+	}
+
+	caller, err := s.usersRepository.GetUserById(ctx, actor)
+	if errors.Is(err, domain.ErrNotFound) || (err == nil && caller.Role != domain.RoleAdmin) {
+		return fmt.Errorf("%w: only an admin can %s", domain.ErrForbidden, action)
+	}
+	return err
 }
 
-func (s *UserService) DeleteUser(ctx context.Context, id string) error {
-	return s.usersRepository.DeleteUser(ctx, id)
+// DeleteUser deletes a user on behalf of the user themselves or an admin, refusing when the user still holds
+// bookings unless cascade is requested
+func (s *UserService) DeleteUser(ctx context.Context, id string, opts port.DeleteOptions) error {
+	if err := s.requireSelfOrAdmin(ctx, id); err != nil {
+		return err
+	}
+
+	return s.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := checkDeleteVersion(ctx, id, opts, s.usersRepository.GetUserById); err != nil {
+			return err
+		}
+
+		bookings, err := s.bookingsRepository.GetBookingsByUserId(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if len(bookings) > 0 {
+			if !opts.Cascade {
+				return referenceInUse("user", id, len(bookings), "booking(s)")
+			}
+			if err := deleteBookings(ctx, s.bookingsRepository, bookings); err != nil {
+				return err
+			}
+		}
+
+		return s.usersRepository.DeleteUser(ctx, id)
+	})
 }
@@ -6,8 +6,10 @@ import (
 	"testing"
 
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/core/port"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockUsersRepository is a mock of UsersRepository interface
@@ -60,25 +62,46 @@ func (m *MockUsersRepository) DeleteUser(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
+// registeredUser matches the user a service stores for username, with role and a hash of password
+func registeredUser(username, password, role string) any {
+	return mock.MatchedBy(func(user *domain.Users) bool {
+		return user.Username == username && user.Role == role && testHasher.Verify(user.Password, password)
+	})
+}
+
 func TestRegister(t *testing.T) {
 	mockRepo := new(MockUsersRepository)
-	userService := NewUsersService(mockRepo)
+	userService := NewUsersService(mockRepo, new(MockBookingsRepository), new(MockSessionRepository), new(MockAuditLogRepository), stubUnitOfWork{}, testHasher)
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
 		user := &domain.Users{
 			Id:       "1",
 			Username: "testuser",
-			Password: "password",
+			Password: "lion-gate-42",
 			Role:     "user",
 		}
+		stored := &domain.Users{Id: "1", Username: "testuser", Role: "user"}
 
-		mockRepo.On("CreateUser", ctx, user).Return(user, nil).Once()
+		mockRepo.On("CreateUser", ctx, registeredUser("testuser", "lion-gate-42", domain.RoleUser)).Return(stored, nil).Once()
 
 		result, err := userService.Register(ctx, user)
 
 		assert.NoError(t, err)
-		assert.Equal(t, user, result)
+		assert.Equal(t, stored, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("role is always user", func(t *testing.T) {
+		user := &domain.Users{Username: "sneaky", Password: "lion-gate-42", Role: domain.RoleAdmin}
+		stored := &domain.Users{Id: "2", Username: "sneaky", Role: domain.RoleUser}
+
+		mockRepo.On("CreateUser", ctx, registeredUser("sneaky", "lion-gate-42", domain.RoleUser)).Return(stored, nil).Once()
+
+		result, err := userService.Register(ctx, user)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RoleUser, result.Role)
 		mockRepo.AssertExpectations(t)
 	})
 
@@ -86,12 +109,12 @@ func TestRegister(t *testing.T) {
 		user := &domain.Users{
 			Id:       "1",
 			Username: "testuser",
-			Password: "password",
+			Password: "lion-gate-42",
 			Role:     "user",
 		}
 
 		expectedErr := errors.New("database error")
-		mockRepo.On("CreateUser", ctx, user).Return(nil, expectedErr).Once()
+		mockRepo.On("CreateUser", ctx, registeredUser("testuser", "lion-gate-42", domain.RoleUser)).Return(nil, expectedErr).Once()
 
 		result, err := userService.Register(ctx, user)
 
@@ -105,63 +128,140 @@ func TestRegister(t *testing.T) {
 		user := &domain.Users{
 			Id:       "1",
 			Username: "testuser",
-			Password: "password",
+			Password: "lion-gate-42",
 			Role:     "user",
 		}
 
-		expectedErr := errors.New("username already exists")
-		mockRepo.On("CreateUser", ctx, user).Return(nil, expectedErr).Once()
+		mockRepo.On("CreateUser", ctx, registeredUser("testuser", "lion-gate-42", domain.RoleUser)).Return(nil, domain.ErrAlreadyExists).Once()
 
 		result, err := userService.Register(ctx, user)
 
-		assert.Error(t, err)
-		assert.Equal(t, expectedErr, err)
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+		assert.Nil(t, result)
+	})
+
+	t.Run("weak password", func(t *testing.T) {
+		user := &domain.Users{Username: "weak", Password: "12345", Role: "root"}
+
+		result, err := userService.Register(ctx, user)
+
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []domain.FieldError{
+			domain.NewFieldError("password", domain.RuleMinLength, map[string]string{"min": "6"}),
+		}, validationErr.Fields)
 		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "CreateUser", ctx, registeredUser("weak", "12345", domain.RoleUser))
 	})
 }
 
-func TestGetUserById(t *testing.T) {
-	mockRepo := new(MockUsersRepository)
-	userService := NewUsersService(mockRepo)
+func TestBootstrapAdmin(t *testing.T) {
 	ctx := context.Background()
 
-	t.Run("success", func(t *testing.T) {
-		userId := "1"
-		expectedUser := &domain.Users{
-			Id:       userId,
-			Username: "testuser",
-			Password: "password",
-			Role:     "user",
-		}
+	t.Run("creates the first admin", func(t *testing.T) {
+		mockRepo := new(MockUsersRepository)
+		mockAuditLog := new(MockAuditLogRepository)
+		userService := NewUsersService(mockRepo, new(MockBookingsRepository), new(MockSessionRepository), mockAuditLog, stubUnitOfWork{}, testHasher)
+
+		mockRepo.On("GetUsersByRole", ctx, domain.RoleAdmin).Return([]domain.Users{}, nil).Once()
+		mockRepo.On("CreateUser", ctx, registeredUser("root", "lion-gate-42", domain.RoleAdmin)).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.Users).Id = "1"
+		}).Return(&domain.Users{Id: "1"}, nil).Once()
+		mockAuditLog.On("Append", ctx, auditEntry(domain.AuditActionAdminBootstrapped, "1", nil, map[string]any{"username": "root"})).Return(nil).Once()
+
+		result, err := userService.BootstrapAdmin(ctx, &domain.Users{Username: "root", Password: "lion-gate-42"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "1", result.Id)
+		assert.Equal(t, domain.RoleAdmin, result.Role)
+		mockRepo.AssertExpectations(t)
+		mockAuditLog.AssertExpectations(t)
+	})
 
-		mockRepo.On("GetUserById", ctx, userId).Return(expectedUser, nil).Once()
+	t.Run("refuses once an admin exists", func(t *testing.T) {
+		mockRepo := new(MockUsersRepository)
+		userService := NewUsersService(mockRepo, new(MockBookingsRepository), new(MockSessionRepository), new(MockAuditLogRepository), stubUnitOfWork{}, testHasher)
 
-		result, err := userService.GetUserById(ctx, userId)
+		mockRepo.On("GetUsersByRole", ctx, domain.RoleAdmin).Return([]domain.Users{{Id: "1", Role: domain.RoleAdmin}}, nil).Once()
+
+		result, err := userService.BootstrapAdmin(ctx, &domain.Users{Username: "root", Password: "lion-gate-42"})
+
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	})
+}
+
+// actingAs returns ctx acting as the user id with role, who GetUserById finds in users when they check the caller
+func actingAs(ctx context.Context, users *MockUsersRepository, id string, role string) context.Context {
+	ctx = port.WithActor(ctx, id)
+	users.On("GetUserById", ctx, id).Return(&domain.Users{Id: id, Username: id, Role: role}, nil).Maybe()
+	return ctx
+}
+
+func TestGetUserById(t *testing.T) {
+	ctx := context.Background()
+	newService := func() (*UserService, *MockUsersRepository) {
+		mockRepo := new(MockUsersRepository)
+		return NewUsersService(mockRepo, new(MockBookingsRepository), new(MockSessionRepository), new(MockAuditLogRepository), stubUnitOfWork{}, testHasher), mockRepo
+	}
+
+	t.Run("self", func(t *testing.T) {
+		userService, mockRepo := newService()
+		userCtx := port.WithActor(ctx, "1")
+		expectedUser := &domain.Users{Id: "1", Username: "testuser", Role: "user"}
+		mockRepo.On("GetUserById", userCtx, "1").Return(expectedUser, nil).Once()
+
+		result, err := userService.GetUserById(userCtx, "1")
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedUser, result)
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("admin gets anyone", func(t *testing.T) {
+		userService, mockRepo := newService()
+		adminCtx := actingAs(ctx, mockRepo, "admin-1", domain.RoleAdmin)
+		expectedUser := &domain.Users{Id: "1", Username: "testuser", Role: "user"}
+		mockRepo.On("GetUserById", adminCtx, "1").Return(expectedUser, nil).Once()
+
+		result, err := userService.GetUserById(adminCtx, "1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedUser, result)
+	})
+
+	t.Run("another user is forbidden", func(t *testing.T) {
+		userService, mockRepo := newService()
+		otherCtx := actingAs(ctx, mockRepo, "2", domain.RoleUser)
+
+		result, err := userService.GetUserById(otherCtx, "1")
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "GetUserById", otherCtx, "1")
+
+		_, err = userService.GetUserById(ctx, "1")
+		assert.ErrorIs(t, err, domain.ErrForbidden, "anonymous callers are nobody")
+	})
+
 	t.Run("not found", func(t *testing.T) {
-		userId := "999"
+		userService, mockRepo := newService()
+		userCtx := port.WithActor(ctx, "999")
 		expectedErr := errors.New("user not found")
+		mockRepo.On("GetUserById", userCtx, "999").Return(nil, expectedErr).Once()
 
-		mockRepo.On("GetUserById", ctx, userId).Return(nil, expectedErr).Once()
+		result, err := userService.GetUserById(userCtx, "999")
 
-		result, err := userService.GetUserById(ctx, userId)
-
-		assert.Error(t, err)
 		assert.Equal(t, expectedErr, err)
 		assert.Nil(t, result)
-		mockRepo.AssertExpectations(t)
 	})
 }
 
 func TestGetUsersByRole(t *testing.T) {
 	mockRepo := new(MockUsersRepository)
-	userService := NewUsersService(mockRepo)
-	ctx := context.Background()
+	userService := NewUsersService(mockRepo, new(MockBookingsRepository), new(MockSessionRepository), new(MockAuditLogRepository), stubUnitOfWork{}, testHasher)
+	adminCtx := actingAs(context.Background(), mockRepo, "admin-1", domain.RoleAdmin)
 
 	t.Run("success", func(t *testing.T) {
 		role := "admin"
@@ -170,9 +270,9 @@ func TestGetUsersByRole(t *testing.T) {
 			{Id: "2", Username: "admin2", Role: "admin"},
 		}
 
-		mockRepo.On("GetUsersByRole", ctx, role).Return(expectedUsers, nil).Once()
+		mockRepo.On("GetUsersByRole", adminCtx, role).Return(expectedUsers, nil).Once()
 
-		result, err := userService.GetUsersByRole(ctx, role)
+		result, err := userService.GetUsersByRole(adminCtx, role)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedUsers, result)
@@ -183,53 +283,160 @@ func TestGetUsersByRole(t *testing.T) {
 		role := "unknown"
 		expectedErr := errors.New("database error")
 
-		mockRepo.On("GetUsersByRole", ctx, role).Return(nil, expectedErr).Once()
+		mockRepo.On("GetUsersByRole", adminCtx, role).Return(nil, expectedErr).Once()
 
-		result, err := userService.GetUsersByRole(ctx, role)
+		result, err := userService.GetUsersByRole(adminCtx, role)
 
 		assert.Error(t, err)
 		assert.Equal(t, expectedErr, err)
 		assert.Nil(t, result)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("only admins list users", func(t *testing.T) {
+		userCtx := actingAs(context.Background(), mockRepo, "1", domain.RoleUser)
+
+		result, err := userService.GetUsersByRole(userCtx, domain.RoleAdmin)
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "GetUsersByRole", userCtx, domain.RoleAdmin)
+	})
 }
 
 func TestUpdateUser(t *testing.T) {
-	mockRepo := new(MockUsersRepository)
-	userService := NewUsersService(mockRepo)
 	ctx := context.Background()
+	newService := func() (*UserService, *MockUsersRepository, *MockSessionRepository, *MockAuditLogRepository) {
+		mockRepo := new(MockUsersRepository)
+		sessions := new(MockSessionRepository)
+		mockAuditLog := new(MockAuditLogRepository)
+		return NewUsersService(mockRepo, new(MockBookingsRepository), sessions, mockAuditLog, stubUnitOfWork{}, testHasher), mockRepo, sessions, mockAuditLog
+	}
+	currentHash, err := testHasher.Hash("lion-gate-42")
+	require.NoError(t, err)
 
 	t.Run("success", func(t *testing.T) {
-		userId := "1"
-		user := &domain.Users{
-			Id:       userId,
-			Username: "updated",
-			Password: "newpassword",
-			Role:     "admin",
+		userService, mockRepo, _, mockAuditLog := newService()
+		userCtx := port.WithActor(ctx, "1")
+		user := &domain.Users{Id: "1", Username: "updated"}
+
+		mockRepo.On("GetUserById", userCtx, "1").Return(&domain.Users{Id: "1", Username: "old", Role: "user"}, nil).Once()
+		mockRepo.On("UpdateUser", userCtx, "1", user).Return(&domain.Users{Id: "1", Username: "updated", Role: "user"}, nil).Once()
+
+		result, err := userService.UpdateUser(userCtx, "1", user, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "updated", result.Username)
+		mockRepo.AssertExpectations(t)
+		mockAuditLog.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+	})
+
+	t.Run("own password with the current one revokes sessions", func(t *testing.T) {
+		userService, mockRepo, sessions, mockAuditLog := newService()
+		userCtx := port.WithActor(ctx, "1")
+		user := &domain.Users{Password: "new-lion-gate-7"}
+
+		mockRepo.On("GetUserById", userCtx, "1").Return(&domain.Users{Id: "1", Username: "ann", Password: currentHash, Role: "user"}, nil).Once()
+		mockRepo.On("UpdateUser", userCtx, "1", registeredUser("", "new-lion-gate-7", "")).Return(&domain.Users{Id: "1", Role: "user"}, nil).Once()
+		sessions.On("RevokeSessions", userCtx, "1", mock.AnythingOfType("time.Time")).Return(nil).Once()
+		mockAuditLog.On("Append", userCtx, auditAction(domain.AuditActionPasswordChanged)).Return(nil).Once()
+
+		_, err := userService.UpdateUser(userCtx, "1", user, "lion-gate-42")
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		sessions.AssertExpectations(t)
+		mockAuditLog.AssertExpectations(t)
+	})
+
+	t.Run("own password needs the current one", func(t *testing.T) {
+		for current, rule := range map[string]string{"": domain.RuleRequired, "wrong-horse-9": domain.RuleInvalid} {
+			userService, mockRepo, sessions, _ := newService()
+			userCtx := port.WithActor(ctx, "1")
+			mockRepo.On("GetUserById", userCtx, "1").Return(&domain.Users{Id: "1", Username: "ann", Password: currentHash, Role: "user"}, nil).Once()
+
+			result, err := userService.UpdateUser(userCtx, "1", &domain.Users{Password: "new-lion-gate-7"}, current)
+
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, []domain.FieldError{domain.NewFieldError("current_password", rule, nil)}, validationErr.Fields)
+			assert.Nil(t, result)
+			mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+			sessions.AssertNotCalled(t, "RevokeSessions", mock.Anything, mock.Anything, mock.Anything)
 		}
+	})
 
-		mockRepo.On("UpdateUser", ctx, userId, user).Return(user, nil).Once()
+	t.Run("admin sets the password of another user", func(t *testing.T) {
+		userService, mockRepo, sessions, mockAuditLog := newService()
+		adminCtx := actingAs(ctx, mockRepo, "admin-1", domain.RoleAdmin)
 
-		result, err := userService.UpdateUser(ctx, userId, user)
+		mockRepo.On("GetUserById", adminCtx, "1").Return(&domain.Users{Id: "1", Username: "ann", Password: currentHash, Role: "user"}, nil).Once()
+		mockRepo.On("UpdateUser", adminCtx, "1", registeredUser("", "new-lion-gate-7", "")).Return(&domain.Users{Id: "1", Role: "user"}, nil).Once()
+		sessions.On("RevokeSessions", adminCtx, "1", mock.AnythingOfType("time.Time")).Return(nil).Once()
+		mockAuditLog.On("Append", adminCtx, auditAction(domain.AuditActionPasswordChanged)).Return(nil).Once()
+
+		_, err := userService.UpdateUser(adminCtx, "1", &domain.Users{Password: "new-lion-gate-7"}, "")
+
+		require.NoError(t, err)
+		sessions.AssertExpectations(t)
+	})
+
+	t.Run("another user is forbidden", func(t *testing.T) {
+		userService, mockRepo, _, _ := newService()
+		otherCtx := actingAs(ctx, mockRepo, "2", domain.RoleUser)
+
+		for _, caller := range []context.Context{otherCtx, ctx} {
+			result, err := userService.UpdateUser(caller, "1", &domain.Users{Password: "new-lion-gate-7"}, "")
+
+			assert.ErrorIs(t, err, domain.ErrForbidden)
+			assert.Nil(t, result)
+		}
+		mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("role change is recorded", func(t *testing.T) {
+		userService, mockRepo, _, mockAuditLog := newService()
+		userId := "2"
+		user := &domain.Users{Id: userId, Username: "staff", Role: "admin"}
+		adminCtx := actingAs(ctx, mockRepo, "admin-1", domain.RoleAdmin)
+
+		mockRepo.On("GetUserById", adminCtx, userId).Return(&domain.Users{Id: userId, Username: "staff", Role: "user"}, nil).Once()
+		mockRepo.On("UpdateUser", adminCtx, userId, user).Return(user, nil).Once()
+		mockAuditLog.On("Append", adminCtx, auditEntry(domain.AuditActionRoleChanged, userId,
+			map[string]any{"role": "user"}, map[string]any{"role": "admin"},
+		)).Return(nil).Once()
+
+		result, err := userService.UpdateUser(adminCtx, userId, user, "")
 
 		assert.NoError(t, err)
 		assert.Equal(t, user, result)
 		mockRepo.AssertExpectations(t)
+		mockAuditLog.AssertExpectations(t)
+	})
+
+	t.Run("role change needs an admin", func(t *testing.T) {
+		userService, mockRepo, _, _ := newService()
+		userId := "3"
+		user := &domain.Users{Id: userId, Role: "admin"}
+		userCtx := port.WithActor(ctx, userId)
+
+		mockRepo.On("GetUserById", userCtx, userId).Return(&domain.Users{Id: userId, Username: "self", Role: "user"}, nil).Twice()
+
+		result, err := userService.UpdateUser(userCtx, userId, user, "")
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, result)
+		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("error", func(t *testing.T) {
-		userId := "999"
-		user := &domain.Users{
-			Id:       userId,
-			Username: "updated",
-			Password: "newpassword",
-			Role:     "admin",
-		}
+		userService, mockRepo, _, _ := newService()
+		userCtx := port.WithActor(ctx, "999")
 		expectedErr := errors.New("user not found")
 
-		mockRepo.On("UpdateUser", ctx, userId, user).Return(nil, expectedErr).Once()
+		mockRepo.On("GetUserById", userCtx, "999").Return(nil, expectedErr).Once()
 
-		result, err := userService.UpdateUser(ctx, userId, user)
+		result, err := userService.UpdateUser(userCtx, "999", &domain.Users{Username: "updated"}, "")
 
 		assert.Error(t, err)
 		assert.Equal(t, expectedErr, err)
@@ -240,30 +447,71 @@ func TestUpdateUser(t *testing.T) {
 
 func TestDeleteUser(t *testing.T) {
 	mockRepo := new(MockUsersRepository)
-	userService := NewUsersService(mockRepo)
-	ctx := context.Background()
+	mockBookingRepo := new(MockBookingsRepository)
+	userService := NewUsersService(mockRepo, mockBookingRepo, new(MockSessionRepository), new(MockAuditLogRepository), stubUnitOfWork{}, testHasher)
+	ctx := actingAs(context.Background(), mockRepo, "admin-1", domain.RoleAdmin)
 
 	t.Run("success", func(t *testing.T) {
 		userId := "1"
 
+		mockBookingRepo.On("GetBookingsByUserId", ctx, userId).Return([]domain.Bookings{}, nil).Once()
 		mockRepo.On("DeleteUser", ctx, userId).Return(nil).Once()
 
-		err := userService.DeleteUser(ctx, userId)
+		err := userService.DeleteUser(ctx, userId, port.DeleteOptions{})
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
+		mockBookingRepo.AssertExpectations(t)
 	})
 
 	t.Run("error", func(t *testing.T) {
 		userId := "999"
 		expectedErr := errors.New("user not found")
 
+		mockBookingRepo.On("GetBookingsByUserId", ctx, userId).Return([]domain.Bookings{}, nil).Once()
 		mockRepo.On("DeleteUser", ctx, userId).Return(expectedErr).Once()
 
-		err := userService.DeleteUser(ctx, userId)
+		err := userService.DeleteUser(ctx, userId, port.DeleteOptions{})
 
 		assert.Error(t, err)
 		assert.Equal(t, expectedErr, err)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("has bookings", func(t *testing.T) {
+		userId := "3"
+		bookings := []domain.Bookings{{Id: "booking1", UserId: userId}}
+
+		mockBookingRepo.On("GetBookingsByUserId", ctx, userId).Return(bookings, nil).Once()
+
+		err := userService.DeleteUser(ctx, userId, port.DeleteOptions{})
+
+		assert.ErrorIs(t, err, domain.ErrReferenceInUse)
+		mockRepo.AssertNotCalled(t, "DeleteUser", ctx, userId)
+	})
+
+	t.Run("cascade", func(t *testing.T) {
+		userId := "2"
+		bookings := []domain.Bookings{{Id: "booking2", UserId: userId}}
+
+		mockBookingRepo.On("GetBookingsByUserId", ctx, userId).Return(bookings, nil).Once()
+		mockBookingRepo.On("DeleteBooking", ctx, "booking2").Return(nil).Once()
+		mockRepo.On("DeleteUser", ctx, userId).Return(nil).Once()
+
+		err := userService.DeleteUser(ctx, userId, port.DeleteOptions{Cascade: true})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockBookingRepo.AssertExpectations(t)
+	})
+
+	t.Run("another user is forbidden", func(t *testing.T) {
+		otherCtx := actingAs(context.Background(), mockRepo, "4", domain.RoleUser)
+
+		err := userService.DeleteUser(otherCtx, "1", port.DeleteOptions{})
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "DeleteUser", otherCtx, "1")
+		mockBookingRepo.AssertNotCalled(t, "GetBookingsByUserId", otherCtx, "1")
+	})
 }
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/khunmostz/be-liongate-go/app/utils"
+)
+
+// checkPasswordStrength reports a password that breaks the strength rules of hasher as a validation error of field.
+// An empty password is left to the required rule of the payload, or means the password is not changed.
+func checkPasswordStrength(hasher *utils.PasswordHasher, field string, password string) error {
+	if password == "" {
+		return nil
+	}
+
+	var fieldErr domain.FieldError
+	switch err := hasher.CheckStrength(password); {
+	case err == nil:
+		return nil
+	case errors.Is(err, utils.ErrPasswordTooShort):
+		fieldErr = domain.NewFieldError(field, domain.RuleMinLength, map[string]string{"min": strconv.Itoa(utils.MinPasswordLength)})
+	case errors.Is(err, utils.ErrPasswordTooLong):
+		fieldErr = domain.NewFieldError(field, domain.RuleMaxLength, map[string]string{"max": strconv.Itoa(hasher.MaxLength())})
+	case errors.Is(err, utils.ErrPasswordTooCommon):
+		fieldErr = domain.NewFieldError(field, domain.RuleCommon, nil)
+	default:
+		fieldErr = domain.NewFieldError(field, domain.RuleInvalid, nil)
+	}
+	return &domain.ValidationError{Fields: []domain.FieldError{fieldErr}}
+}
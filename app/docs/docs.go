@@ -24,40 +24,58 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/animals": {
+        "/admin/api-clients": {
             "get": {
-                "description": "Get a list of all animals",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get the machine clients that may be issued API keys, ordered by name",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "animals"
+                    "admin"
                 ],
-                "summary": "Get all animals",
+                "summary": "List API clients",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Animals"
+                                "$ref": "#/definitions/dto.ApiClientResponse"
                             }
                         }
                     },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a new animal with the provided information",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register a machine client, such as a box-office kiosk or a partner, to issue API keys to",
                 "consumes": [
                     "application/json"
                 ],
@@ -65,17 +83,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "animals"
+                    "admin"
                 ],
-                "summary": "Create a new animal",
+                "summary": "Register an API client",
                 "parameters": [
                     {
-                        "description": "Animal information",
-                        "name": "animal",
+                        "description": "Name and description of the client",
+                        "name": "client",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Animals"
+                            "$ref": "#/definitions/dto.ApiClientRequest"
                         }
                     }
                 ],
@@ -83,43 +101,61 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.Animals"
+                            "$ref": "#/definitions/dto.ApiClientResponse"
                         }
                     },
                     "400": {
                         "description": "Invalid request body",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Name already taken",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/animals/{id}": {
+        "/admin/api-clients/{id}/keys": {
             "get": {
-                "description": "Get an animal's information by its ID",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get every key issued to a client, expired and revoked ones included, oldest first. The keys themselves cannot be read back.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "animals"
+                    "admin"
                 ],
-                "summary": "Get an animal by ID",
+                "summary": "List the API keys of a client",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Animal ID",
+                        "description": "Client ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -129,27 +165,45 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Animals"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.ApiKeyResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "404": {
-                        "description": "Animal not found",
+                        "description": "Client not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Update an animal's information",
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issue a key with the given scopes to a client. The key expires at expires_at, or after API_KEY_TTL, and is only returned in this response.",
                 "consumes": [
                     "application/json"
                 ],
@@ -157,73 +211,86 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "animals"
+                    "admin"
                 ],
-                "summary": "Update an animal",
+                "summary": "Issue an API key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Animal ID",
+                        "description": "Client ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated Animal information",
-                        "name": "animal",
+                        "description": "Scopes and optional expiry of the key",
+                        "name": "key",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Animals"
+                            "$ref": "#/definitions/dto.ApiKeyRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.Animals"
+                            "$ref": "#/definitions/dto.IssuedApiKeyResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid request body, unknown scope or expiry in the past",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "404": {
-                        "description": "Animal not found",
+                        "description": "Client not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/api-keys/{id}": {
             "delete": {
-                "description": "Delete an animal by its ID",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Stop a key from working at once",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "animals"
+                    "admin"
                 ],
-                "summary": "Delete an animal",
+                "summary": "Revoke an API key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Animal ID",
+                        "description": "Key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -231,140 +298,256 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success message",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "404": {
-                        "description": "Animal not found",
+                        "description": "Key not found or already revoked",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/animals/{id}/perform-show/{roundId}": {
+        "/admin/api-keys/{id}/rotate": {
             "post": {
-                "description": "Record an animal performing a specific show round",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Issue a successor with the scopes of a key, which is only returned in this response. The old key keeps working for API_KEY_ROTATION_GRACE so that the client can switch over.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "animals"
+                    "admin"
                 ],
-                "summary": "Animal performs a show round",
+                "summary": "Rotate an API key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Animal ID",
+                        "description": "Key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Show Round ID",
-                        "name": "roundId",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.ShowRounds"
+                            "$ref": "#/definitions/dto.IssuedApiKeyResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "401": {
+                        "description": "Missing or invalid access token",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "404": {
-                        "description": "Animal or show round not found",
+                        "description": "Key not found, expired or revoked",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/auth/login": {
-            "post": {
-                "description": "Authenticate user with username and password",
-                "consumes": [
-                    "application/json"
+        "/admin/audit-log": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get a page of the audit log of administrative and security events, most recent first, optionally filtered by actor, action, entity and time range",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "admin"
                 ],
-                "summary": "User login",
+                "summary": "List audit log entries",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Only entries of this user ID, or anonymous",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "login",
+                            "login_failed",
+                            "role_changed",
+                            "stage_price_changed",
+                            "booking_cancelled",
+                            "booking_refunded",
+                            "round_cancelled",
+                            "invitation_created",
+                            "invitation_accepted",
+                            "admin_bootstrapped",
+                            "login_locked",
+                            "login_unlocked",
+                            "mfa_enabled",
+                            "mfa_disabled",
+                            "mfa_reset",
+                            "mfa_recovery_codes_renewed",
+                            "mfa_requirement_changed",
+                            "password_reset_requested",
+                            "password_reset",
+                            "password_changed",
+                            "contact_verified",
+                            "marketing_consent_changed",
+                            "logout",
+                            "api_client_created",
+                            "api_key_issued",
+                            "api_key_rotated",
+                            "api_key_revoked",
+                            "oidc_identity_linked"
+                        ],
+                        "type": "string",
+                        "description": "Only entries of this action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "user",
+                            "performance_stage",
+                            "show_round",
+                            "booking",
+                            "invitation",
+                            "login",
+                            "role",
+                            "api_client",
+                            "api_key"
+                        ],
+                        "type": "string",
+                        "description": "Only entries about this kind of entity",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries about the entity with this ID",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries that occurred at or after this RFC 3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries that occurred at or before this RFC 3339 time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields, prefix with - for descending (entry_id, occurred_at, actor, action, entity_type)",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful login",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.AuthResponse"
+                            "$ref": "#/definitions/port.Page-dto_AuditLogEntryResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid query parameters",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/auth/refresh-token": {
+        "/admin/invitations": {
             "post": {
-                "description": "Generate new access token using refresh token",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a single-use invitation to sign up with the given role. The signed link expires after INVITATION_TTL and is only returned in this response.",
                 "consumes": [
                     "application/json"
                 ],
@@ -372,185 +555,229 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "admin"
                 ],
-                "summary": "Refresh access token",
+                "summary": "Invite a user",
                 "parameters": [
                     {
-                        "description": "Refresh token",
-                        "name": "request",
+                        "description": "Role of the invited user",
+                        "name": "invitation",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.RefreshTokenRequest"
+                            "$ref": "#/definitions/dto.InvitationRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "New token pair",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.TokenPair"
+                            "$ref": "#/definitions/dto.InvitationResponse"
                         }
                     },
                     "400": {
                         "description": "Invalid request body",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/auth/register": {
-            "post": {
-                "description": "Register a new user account",
-                "consumes": [
-                    "application/json"
+        "/admin/login-lockouts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get the usernames and client IPs that are locked out after too many failed logins",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
-                ],
-                "summary": "User registration",
-                "parameters": [
-                    {
-                        "description": "Registration details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.RegisterRequest"
-                        }
-                    }
+                    "admin"
                 ],
+                "summary": "List login lockouts",
                 "responses": {
                     "200": {
-                        "description": "Successful registration",
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.LoginLockoutResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
                         "schema": {
-                            "$ref": "#/definitions/domain.AuthResponse"
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body",
+                    "403": {
+                        "description": "Caller is not an admin",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/bookings": {
-            "post": {
-                "description": "Create a new booking with the provided information",
-                "consumes": [
-                    "application/json"
+        "/admin/login-lockouts/{kind}/{value}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Clear the failed logins of a username or client IP, lifting its delay and lockout at once",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "admin"
                 ],
-                "summary": "Create a new booking",
+                "summary": "Unlock a username or client IP",
                 "parameters": [
                     {
-                        "description": "Booking information",
-                        "name": "booking",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.Bookings"
-                        }
+                        "enum": [
+                            "username",
+                            "ip"
+                        ],
+                        "type": "string",
+                        "description": "What to unlock",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The username or client IP",
+                        "name": "value",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Bookings"
+                            "$ref": "#/definitions/dto.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Unknown kind",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "No failed logins are recorded for the username or IP",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/bookings/round/{roundId}": {
+        "/admin/mfa/requirements": {
             "get": {
-                "description": "Get all bookings for a specific show round",
-                "consumes": [
-                    "application/json"
-                ],
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the roles whose users must log in with a second factor",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
-                ],
-                "summary": "Get bookings by round ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Round ID",
-                        "name": "roundId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "List roles requiring MFA",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Bookings"
+                                "$ref": "#/definitions/dto.MfaRequirementResponse"
                             }
                         }
                     },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/bookings/user/{userId}": {
-            "get": {
-                "description": "Get all bookings for a specific user",
+        "/admin/mfa/requirements/{role}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Require a second factor from every user of a role, or stop requiring it. Users of the role without one must enroll at their next login.",
                 "consumes": [
                     "application/json"
                 ],
@@ -558,159 +785,230 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "admin"
                 ],
-                "summary": "Get bookings by user ID",
+                "summary": "Require MFA for a role",
                 "parameters": [
                     {
+                        "enum": [
+                            "admin",
+                            "user"
+                        ],
                         "type": "string",
-                        "description": "User ID",
-                        "name": "userId",
+                        "description": "Role",
+                        "name": "role",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Whether the role requires MFA",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MfaRequirementRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Bookings"
-                            }
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or unknown role",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/bookings/{id}": {
-            "get": {
-                "description": "Get a booking's information by its ID",
-                "consumes": [
-                    "application/json"
+        "/admin/trash/purge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Permanently remove every entity deleted longer ago than the retention period (TRASH_RETENTION)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
-                ],
-                "summary": "Get a booking by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Booking ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "Purge expired entities",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Number of purged entities",
                         "schema": {
-                            "$ref": "#/definitions/domain.Bookings"
+                            "$ref": "#/definitions/dto.PurgeResponse"
                         }
                     },
-                    "404": {
-                        "description": "Booking not found",
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update a booking's information",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/admin/trash/{kind}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get a page of the soft-deleted entities of one kind, most recently deleted first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "admin"
                 ],
-                "summary": "Update a booking",
+                "summary": "List deleted entities",
                 "parameters": [
                     {
+                        "enum": [
+                            "users",
+                            "animals",
+                            "performance_stages",
+                            "show_rounds",
+                            "bookings"
+                        ],
                         "type": "string",
-                        "description": "Booking ID",
-                        "name": "id",
+                        "description": "Entity kind",
+                        "name": "kind",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated Booking information",
-                        "name": "booking",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.Bookings"
-                        }
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields, prefix with - for descending (id, deleted_at)",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Bookings"
+                            "$ref": "#/definitions/port.Page-dto_TrashItemResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Unknown kind or invalid query parameters",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
-                    "404": {
-                        "description": "Booking not found",
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/trash/{kind}/{id}": {
             "delete": {
-                "description": "Delete a booking by its ID",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Permanently remove a soft-deleted entity together with the deleted entities that reference it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "admin"
                 ],
-                "summary": "Delete a booking",
+                "summary": "Permanently delete an entity",
                 "parameters": [
                     {
+                        "enum": [
+                            "users",
+                            "animals",
+                            "performance_stages",
+                            "show_rounds",
+                            "bookings"
+                        ],
                         "type": "string",
-                        "description": "Booking ID",
+                        "description": "Entity kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -718,125 +1016,83 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success message",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/dto.MessageResponse"
                         }
                     },
-                    "404": {
-                        "description": "Booking not found",
+                    "400": {
+                        "description": "Unknown kind",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    }
-                }
-            }
-        },
-        "/show-rounds": {
-            "get": {
-                "description": "Get a list of all show rounds",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "show-rounds"
-                ],
-                "summary": "Get all show rounds",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "401": {
+                        "description": "Missing or invalid access token",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.ShowRounds"
-                            }
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    }
-                }
-            },
-            "post": {
-                "description": "Create a new show round with the provided information",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "show-rounds"
-                ],
-                "summary": "Create a new show round",
-                "parameters": [
-                    {
-                        "description": "Show Round information",
-                        "name": "showRound",
-                        "in": "body",
-                        "required": true,
+                    "403": {
+                        "description": "Caller is not an admin",
                         "schema": {
-                            "$ref": "#/definitions/domain.ShowRounds"
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
+                    },
+                    "404": {
+                        "description": "Entity is not in the trash",
                         "schema": {
-                            "$ref": "#/definitions/domain.ShowRounds"
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body",
+                    "409": {
+                        "description": "Live entities still reference it",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/show-rounds/{id}": {
-            "get": {
-                "description": "Get a show round's information by its ID",
-                "consumes": [
-                    "application/json"
+        "/admin/trash/{kind}/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Bring a soft-deleted entity back. The entities it references must be live, so restore a show round's animal and stage, or a booking's show round and user, first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "show-rounds"
+                    "admin"
                 ],
-                "summary": "Get a show round by ID",
+                "summary": "Restore a deleted entity",
                 "parameters": [
                     {
+                        "enum": [
+                            "users",
+                            "animals",
+                            "performance_stages",
+                            "show_rounds",
+                            "bookings"
+                        ],
                         "type": "string",
-                        "description": "Show Round ID",
+                        "description": "Entity kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -846,131 +1102,123 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.ShowRounds"
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown kind",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "404": {
-                        "description": "Show round not found",
+                        "description": "Entity is not in the trash",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "The seat of the booking has been booked again",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "422": {
+                        "description": "A referenced entity is deleted",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update a show round's information",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/admin/users/{id}/mfa": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Remove the second factor of a user who lost their device and recovery codes; they enroll again afterwards",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "show-rounds"
+                    "admin"
                 ],
-                "summary": "Update a show round",
+                "summary": "Reset the second factor of a user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Show Round ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Updated Show Round information",
-                        "name": "showRound",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.ShowRounds"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.ShowRounds"
+                            "$ref": "#/definitions/dto.MessageResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body",
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "404": {
-                        "description": "Show round not found",
+                        "description": "The user has no second factor",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Get a show round's information by its ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "show-rounds"
-                ],
-                "summary": "Get a show round by ID",
-                "parameters": [
+            }
+        },
+        "/animals": {
+            "get": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Show Round ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/domain.ShowRounds"
-                        }
-                    },
-                    "404": {
-                        "description": "Show round not found",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                        "BearerAuth": []
                     },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                    {
+                        "ApiKeyAuth": []
                     }
-                }
-            },
-            "delete": {
-                "description": "Delete a show round by its ID",
+                ],
+                "description": "Get a page of animals, optionally filtered by species and type",
                 "consumes": [
                     "application/json"
                 ],
@@ -978,77 +1226,117 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "show-rounds"
+                    "animals"
                 ],
-                "summary": "Delete a show round",
+                "summary": "Get all animals",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Show Round ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter by species",
+                        "name": "species",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created at or after this RFC 3339 time",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created at or before this RFC 3339 time",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated at or after this RFC 3339 time",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated at or before this RFC 3339 time",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created by this user ID, or anonymous",
+                        "name": "created_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated by this user ID, or anonymous",
+                        "name": "updated_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields, prefix with - for descending (animal_id, name, species, type, show_duration, created_at, updated_at)",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success message",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/port.Page-dto_AnimalResponse"
                         }
                     },
-                    "404": {
-                        "description": "Show round not found",
+                    "400": {
+                        "description": "Invalid query parameters",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    }
-                }
-            }
-        },
-        "/stages": {
-            "get": {
-                "description": "Get a list of all performance stages",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "stages"
-                ],
-                "summary": "Get all performance stages",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.PerformanceStage"
-                            }
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a new performance stage with the provided information",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new animal with the provided information",
                 "consumes": [
                     "application/json"
                 ],
@@ -1056,17 +1344,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stages"
+                    "animals"
                 ],
-                "summary": "Create a new performance stage",
+                "summary": "Create a new animal",
                 "parameters": [
                     {
-                        "description": "Performance Stage information",
-                        "name": "stage",
+                        "description": "Animal information",
+                        "name": "animal",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.PerformanceStage"
+                            "$ref": "#/definitions/dto.AnimalRequest"
                         }
                     }
                 ],
@@ -1074,29 +1362,53 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.PerformanceStage"
+                            "$ref": "#/definitions/dto.AnimalResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the animal"
+                            }
                         }
                     },
                     "400": {
                         "description": "Invalid request body",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/stages/{id}": {
+        "/animals/{id}": {
             "get": {
-                "description": "Get a performance stage's information by its ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get an animal's information by its ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1104,13 +1416,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stages"
+                    "animals"
                 ],
-                "summary": "Get a performance stage by ID",
+                "summary": "Get an animal by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Performance Stage ID",
+                        "description": "Animal ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1120,20 +1432,42 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.PerformanceStage"
+                            "$ref": "#/definitions/dto.AnimalResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the animal, send it back in If-Match to update or delete it"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Animal not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update a performance stage's information",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update an animal's information",
                 "consumes": [
                     "application/json"
                 ],
@@ -1141,24 +1475,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stages"
+                    "animals"
                 ],
-                "summary": "Update a performance stage",
+                "summary": "Update an animal",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Performance Stage ID",
+                        "description": "Animal ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated Performance Stage information",
-                        "name": "stage",
+                        "type": "string",
+                        "description": "ETag of the animal being updated, or * for any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated Animal information",
+                        "name": "animal",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.PerformanceStage"
+                            "$ref": "#/definitions/dto.AnimalRequest"
                         }
                     }
                 ],
@@ -1166,27 +1507,66 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.PerformanceStage"
+                            "$ref": "#/definitions/dto.AnimalResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the animal"
+                            }
                         }
                     },
                     "400": {
                         "description": "Invalid request body",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Animal not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Animal was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is missing",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Delete a performance stage by its ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move an animal to the trash by its ID; an admin can restore it until it is purged",
                 "consumes": [
                     "application/json"
                 ],
@@ -1194,87 +1574,91 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stages"
+                    "animals"
                 ],
-                "summary": "Delete a performance stage",
+                "summary": "Delete an animal",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Performance Stage ID",
+                        "description": "Animal ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the animal being deleted, or * for any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also delete the animal's show rounds and their bookings",
+                        "name": "cascade",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success message",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/dto.MessageResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Missing or invalid access token",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
-                    }
-                }
-            }
-        },
-        "/users/register": {
-            "post": {
-                "description": "Register a new user with the provided information",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "users"
-                ],
-                "summary": "Register a new user",
-                "parameters": [
-                    {
-                        "description": "User information",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
                         "schema": {
-                            "$ref": "#/definitions/domain.Users"
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
+                    },
+                    "404": {
+                        "description": "Animal not found",
                         "schema": {
-                            "$ref": "#/definitions/domain.Users"
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body",
+                    "409": {
+                        "description": "Animal still has show rounds",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Animal was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is missing",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/users/role/{role}": {
-            "get": {
-                "description": "Get all users with a specific role",
+        "/animals/{id}/perform-show/{roundId}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record an animal performing a specific show round",
                 "consumes": [
                     "application/json"
                 ],
@@ -1282,14 +1666,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "animals"
                 ],
-                "summary": "Get users by role",
+                "summary": "Animal performs a show round",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User role",
-                        "name": "role",
+                        "description": "Animal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Show Round ID",
+                        "name": "roundId",
                         "in": "path",
                         "required": true
                     }
@@ -1298,25 +1689,45 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Users"
-                            }
+                            "$ref": "#/definitions/dto.PerformanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Animal or show round not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/users/{id}": {
-            "get": {
-                "description": "Get a user's information by their ID",
+        "/auth/forgot-password": {
+            "post": {
+                "description": "Mail a link to reset the password to the email address of the user. It answers alike whether the username exists and has an address or not.\nThe link expires after a while and replaces the links sent before.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1324,43 +1735,167 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "Authentication"
                 ],
-                "summary": "Get a user by ID",
+                "summary": "Request a password reset link",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Username",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/invitations/accept": {
+            "post": {
+                "description": "Sign up with the token of an invitation link and log in. The user gets the role of the invitation, and the invitation cannot be used again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Accept an invitation",
+                "parameters": [
+                    {
+                        "description": "Invitation token and the new user's credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AcceptInvitationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successful registration",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Invitation is invalid, expired or already used",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Username already taken",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Authenticate user with username and password. An unknown username and a wrong password fail alike.\nAfter failed attempts the username is delayed, and after too many the username or client IP is locked out for a while.\nWhen the user has two-factor authentication, or their role requires it, the response carries only an mfa challenge to complete at /auth/login/mfa.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "User login",
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Successful login, or the mfa challenge of a login that needs a second factor",
                         "schema": {
-                            "$ref": "#/definitions/domain.Users"
+                            "$ref": "#/definitions/dto.AuthResponse"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before the next attempt"
+                            }
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update a user's information",
+            }
+        },
+        "/auth/login/mfa": {
+            "post": {
+                "description": "Exchange the mfa_token of a login challenge and a code from the authenticator app, or an unused recovery code, for tokens.\nWhen the challenge required enrollment, the code confirms it and the response also carries the recovery codes, which are not shown again.\nInvalid codes count as failed logins and are throttled alike.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1368,24 +1903,81 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "Authentication"
                 ],
-                "summary": "Update a user",
+                "summary": "Complete a login with a second factor",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Challenge token and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MfaLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful login",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired challenge, or invalid code",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds to wait before the next attempt"
+                            }
+                        }
                     },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login/mfa/enroll": {
+            "post": {
+                "description": "Start the enrollment a login challenge with enrollment_required asks for. Add the secret to an authenticator app, then complete the login at /auth/login/mfa with its first code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Enroll during login",
+                "parameters": [
                     {
-                        "description": "Updated User information",
-                        "name": "user",
+                        "description": "Challenge token",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Users"
+                            "$ref": "#/definitions/dto.MfaEnrollLoginRequest"
                         }
                     }
                 ],
@@ -1393,34 +1985,44 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Users"
+                            "$ref": "#/definitions/dto.MfaSetupResponse"
                         }
                     },
                     "400": {
                         "description": "Invalid request body",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "401": {
+                        "description": "Invalid or expired challenge",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is already enabled",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a user by their ID",
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke the access token of the request, and the refresh token in the body when given, before they expire. Other sessions of the user stay signed in.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1428,132 +2030,3806 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "Authentication"
                 ],
-                "summary": "Delete a user",
+                "summary": "Log out",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Refresh token to revoke as well",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LogoutRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success message",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/dto.MessageResponse"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token, or a refresh token that is invalid or of another user",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Enable two-factor authentication with the first code of the authenticator app and get the recovery codes, which are not shown again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Confirm a second factor",
+                "parameters": [
+                    {
+                        "description": "Code from the authenticator app",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MfaConfirmRequest"
                         }
                     }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token, or invalid code",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Nothing to confirm, or already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Turn off two-factor authentication for the caller after checking a code or a recovery code. Not allowed when the role of the caller requires it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Disable the second factor",
+                "parameters": [
+                    {
+                        "description": "Code or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MfaCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token, or invalid code",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "The role of the caller requires two-factor authentication",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new TOTP secret for the caller. It takes effect once confirmed with a first code; enrolling again before that replaces it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Start enrolling a second factor",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MfaSetupResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace all recovery codes of the caller after checking a code or a recovery code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Renew recovery codes",
+                "parameters": [
+                    {
+                        "description": "Code or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MfaCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token, or invalid code",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/callback": {
+            "post": {
+                "description": "Exchange the state and code the provider returned for tokens, like a password login. The first sign-in with an identity creates a user, unless the sign-in was started to link it.\nWhen the user has two-factor authentication, or their role requires it, the response carries only an mfa challenge to complete at /auth/login/mfa.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Complete a sign-in with an identity provider",
+                "parameters": [
+                    {
+                        "description": "State and code from the provider",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.OidcCallbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful login, or the mfa challenge of a login that needs a second factor",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unknown or expired state, or the provider rejected the code",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Identity is linked to another user, or its email belongs to another user",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/{provider}/authorize": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the URL of the provider to send the browser to. The provider redirects back to the client with a state and a code, which the client posts to /auth/oidc/callback when the state is the one returned here.\nWith an access token, the identity is linked to the caller instead of signing in as it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Start a sign-in with an identity provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the identity provider, such as google",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OidcAuthorizationResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown identity provider",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh-token": {
+            "post": {
+                "description": "Generate new access token using refresh token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New token pair",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TokenPairResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Register a new user account; public registration always gets the user role, other roles are granted through invitations",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "User registration",
+                "parameters": [
+                    {
+                        "description": "Registration details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful registration",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Username already taken",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password": {
+            "post": {
+                "description": "Set a new password with the token of a reset link. The token can be used once, and every session of the user is revoked, so they must log in again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Reset the password",
+                "parameters": [
+                    {
+                        "description": "Token of the reset link and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or password too weak",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Unknown, expired or already used token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of bookings, optionally filtered by user, show round and status; users only see their own",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "List bookings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by user",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by show round",
+                        "name": "round_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status (confirmed, cancelled, refunded)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created at or after this RFC 3339 time",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created at or before this RFC 3339 time",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated at or after this RFC 3339 time",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated at or before this RFC 3339 time",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created by this user ID, or anonymous",
+                        "name": "created_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated by this user ID, or anonymous",
+                        "name": "updated_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields, prefix with - for descending (booking_id, user_id, round_id, seat_number, price, status, created_at, updated_at)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/port.Page-dto_BookingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Booking of another user, a field only admins may set, or an API key without the scope",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new booking; users book for themselves at the stage's price per seat, only admins and API clients set user_id and price",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Create a new booking",
+                "parameters": [
+                    {
+                        "description": "Booking information",
+                        "name": "booking",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookingResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the booking"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Booking of another user, a field only admins may set, or an API key without the scope",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "422": {
+                        "description": "Show round or user does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/round/{roundId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of bookings for a specific show round; users only see their own",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Get bookings by round ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Round ID",
+                        "name": "roundId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status (confirmed, cancelled, refunded)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created at or after this RFC 3339 time",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created at or before this RFC 3339 time",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated at or after this RFC 3339 time",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated at or before this RFC 3339 time",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created by this user ID, or anonymous",
+                        "name": "created_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated by this user ID, or anonymous",
+                        "name": "updated_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields, prefix with - for descending (booking_id, user_id, round_id, seat_number, price, status, created_at, updated_at)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/port.Page-dto_BookingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/user/{userId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of bookings for a specific user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Get bookings by user ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status (confirmed, cancelled, refunded)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created at or after this RFC 3339 time",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created at or before this RFC 3339 time",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated at or after this RFC 3339 time",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated at or before this RFC 3339 time",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created by this user ID, or anonymous",
+                        "name": "created_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated by this user ID, or anonymous",
+                        "name": "updated_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields, prefix with - for descending (booking_id, user_id, round_id, seat_number, price, status, created_at, updated_at)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/port.Page-dto_BookingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Booking of another user, a field only admins may set, or an API key without the scope",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a booking's information by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Get a booking by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookingResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the booking, send it back in If-Match to update or delete it"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Booking of another user, a field only admins may set, or an API key without the scope",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update a booking's information; users may only change the seat, round or QR code of their own bookings and cancel them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Update a booking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the booking being updated, or * for any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated Booking information",
+                        "name": "booking",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookingResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the booking"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Booking of another user, a field only admins may set, or an API key without the scope",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Booking was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "422": {
+                        "description": "Show round or user does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is missing",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Move a booking to the trash by its ID; an admin can restore it until it is purged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Delete a booking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the booking being deleted, or * for any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Booking of another user, a field only admins may set, or an API key without the scope",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Booking was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is missing",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the profile of the caller, with their contact details and whether they are verified",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Get own profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProfileResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the user"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the display name, email, phone, preferred language or marketing consent of the caller. A new email or phone starts out unverified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Update own profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the profile being updated, or * for any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProfileResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the user"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Email or phone already used by another user",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Profile was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is missing",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/identities": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the identities at external providers the caller can sign in with, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "List linked identities",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.OidcIdentityResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/verifications/{channel}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send a code to the current email or phone of the caller, replacing the code sent before",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Send a verification code",
+                "parameters": [
+                    {
+                        "enum": [
+                            "email",
+                            "phone"
+                        ],
+                        "type": "string",
+                        "description": "Address to verify",
+                        "name": "channel",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerificationSentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown channel, or no address to verify",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Address is already verified",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/verifications/{channel}/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Confirm the current email or phone of the caller with the code sent to it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Verify an email or phone",
+                "parameters": [
+                    {
+                        "enum": [
+                            "email",
+                            "phone"
+                        ],
+                        "type": "string",
+                        "description": "Address to verify",
+                        "name": "channel",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Code that was sent",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerifyContactRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProfileResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the user"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Code is wrong, expired or used up, or the address changed since it was sent",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/show-rounds": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of show rounds, optionally filtered by date range, animal, species or stage",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "show-rounds"
+                ],
+                "summary": "Get all show rounds",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only rounds starting at or after this RFC 3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rounds starting at or before this RFC 3339 time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by animal",
+                        "name": "animal_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by the species of the round's animal",
+                        "name": "species",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by stage",
+                        "name": "stage_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created at or after this RFC 3339 time",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created at or before this RFC 3339 time",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated at or after this RFC 3339 time",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated at or before this RFC 3339 time",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created by this user ID, or anonymous",
+                        "name": "created_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated by this user ID, or anonymous",
+                        "name": "updated_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields, prefix with - for descending (round_id, animal_id, stage_id, show_time, created_at, updated_at)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/port.Page-dto_ShowRoundResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new show round with the provided information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "show-rounds"
+                ],
+                "summary": "Create a new show round",
+                "parameters": [
+                    {
+                        "description": "Show Round information",
+                        "name": "showRound",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ShowRoundRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ShowRoundResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the show round"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin, or an API key without the rounds:write scope",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "422": {
+                        "description": "Animal or stage does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/show-rounds/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a show round's information by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "show-rounds"
+                ],
+                "summary": "Get a show round by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Show Round ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ShowRoundResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the show round, send it back in If-Match to update or delete it"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Show round not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update a show round's information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "show-rounds"
+                ],
+                "summary": "Update a show round",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Show Round ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the show round being updated, or * for any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated Show Round information",
+                        "name": "showRound",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ShowRoundRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ShowRoundResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the show round"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin, or an API key without the rounds:write scope",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Show round not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Show round was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "422": {
+                        "description": "Animal or stage does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is missing",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a show round's information by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "show-rounds"
+                ],
+                "summary": "Get a show round by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Show Round ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ShowRoundResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the show round, send it back in If-Match to update or delete it"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Show round not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Move a show round to the trash by its ID; an admin can restore it until it is purged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "show-rounds"
+                ],
+                "summary": "Delete a show round",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Show Round ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the show round being deleted, or * for any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also delete the show round's bookings",
+                        "name": "cascade",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin, or an API key without the rounds:write scope",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Show round not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Show round still has bookings",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Show round was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is missing",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/stages": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of performance stages, optionally filtered by room number and capacity",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stages"
+                ],
+                "summary": "Get all performance stages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by room number",
+                        "name": "room_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only stages with at least this many seats",
+                        "name": "min_seat_capacity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created at or after this RFC 3339 time",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created at or before this RFC 3339 time",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated at or after this RFC 3339 time",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated at or before this RFC 3339 time",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities created by this user ID, or anonymous",
+                        "name": "created_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entities last updated by this user ID, or anonymous",
+                        "name": "updated_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields, prefix with - for descending (stage_id, room_number, seat_capacity, price_per_seat, created_at, updated_at)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/port.Page-dto_StageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new performance stage with the provided information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stages"
+                ],
+                "summary": "Create a new performance stage",
+                "parameters": [
+                    {
+                        "description": "Performance Stage information",
+                        "name": "stage",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.StageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StageResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the performance stage"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/stages/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a performance stage's information by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stages"
+                ],
+                "summary": "Get a performance stage by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Performance Stage ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StageResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the performance stage, send it back in If-Match to update or delete it"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token or API key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Performance stage not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a performance stage's information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stages"
+                ],
+                "summary": "Update a performance stage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Performance Stage ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the performance stage being updated, or * for any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated Performance Stage information",
+                        "name": "stage",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.StageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StageResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the performance stage"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Performance stage not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Performance stage was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is missing",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move a performance stage to the trash by its ID; an admin can restore it until it is purged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stages"
+                ],
+                "summary": "Delete a performance stage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Performance Stage ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the performance stage being deleted, or * for any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also delete the stage's show rounds and their bookings",
+                        "name": "cascade",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Performance stage not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Stage still has show rounds",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Performance stage was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is missing",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/register": {
+            "post": {
+                "description": "Register a new user with the provided information; the user always gets the user role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User information",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the user"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Username already taken",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/role/{role}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all users with a specific role; admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get users by role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User role",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.UserResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a user's information by their ID; users may only get themselves, admins anyone",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the user, send it back in If-Match to update or delete them"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Another user, and the caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a user's information; users may only update themselves, admins anyone. Users changing their own password must send current_password, and a new password revokes every session of the user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the user being updated, or * for any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated User information",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the user"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, or a missing or wrong current password",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Another user or a role changed, and the caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Username already taken",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "User was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is missing",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move a user to the trash by their ID; the username stays taken until an admin purges the user. Users may only delete themselves, admins anyone.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the user being deleted, or * for any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also delete the user's bookings",
+                        "name": "cascade",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Another user, and the caller is not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "User still has bookings",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "User was modified since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is missing",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code names the rule the field breaks, such as required or min",
+                    "type": "string",
+                    "example": "min"
+                },
+                "field": {
+                    "description": "Field is the JSON name of the field, with nested fields separated by dots",
+                    "type": "string",
+                    "example": "seat_number"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "seat_number must be at least 1"
+                },
+                "params": {
+                    "description": "Params holds the arguments of the rule, such as the minimum, for clients that word the message themselves",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.ProblemDetails": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "enum": [
+                        "validation_failed",
+                        "invalid_query",
+                        "invalid_reference",
+                        "unauthorized",
+                        "invalid_credentials",
+                        "token_expired",
+                        "forbidden",
+                        "not_found",
+                        "already_exists",
+                        "reference_in_use",
+                        "version_conflict",
+                        "if_match_required",
+                        "internal"
+                    ],
+                    "example": "not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "entity not found: animal \"42\""
+                },
+                "errors": {
+                    "description": "Errors lists the invalid fields of a validation_failed problem",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldError"
+                    }
+                },
+                "instance": {
+                    "description": "Instance is the path of the request that failed",
+                    "type": "string",
+                    "example": "/api/v1/animals/42"
+                },
+                "request_id": {
+                    "description": "RequestId is the X-Request-ID of the request, to quote when reporting the problem",
+                    "type": "string",
+                    "example": "5f0c6d1e-8f7a-4a3b-9c2d-1e2f3a4b5c6d"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "entity not found"
+                },
+                "type": {
+                    "description": "Type is a URI naming the problem; it is derived from Code",
+                    "type": "string",
+                    "example": "urn:liongate:problem:not_found"
+                }
+            }
+        },
+        "dto.AcceptInvitationRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "s3cret-pass"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "somchai"
+                }
+            }
+        },
+        "dto.AnimalRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Leo"
+                },
+                "show_duration": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "species": {
+                    "type": "string",
+                    "example": "lion"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "mammal"
+                }
+            }
+        },
+        "dto.AnimalResponse": {
+            "type": "object",
+            "properties": {
+                "animal_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "anonymous"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Leo"
+                },
+                "show_duration": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "species": {
+                    "type": "string",
+                    "example": "lion"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "mammal"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string",
+                    "example": "anonymous"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dto.ApiClientRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Box-office kiosk at the north gate"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "kiosk-north-gate"
+                }
+            }
+        },
+        "dto.ApiClientResponse": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Box-office kiosk at the north gate"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "kiosk-north-gate"
+                }
+            }
+        },
+        "dto.ApiKeyRequest": {
+            "type": "object",
+            "required": [
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "bookings:create",
+                        "rounds:read"
+                    ]
+                }
+            }
+        },
+        "dto.ApiKeyResponse": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "key_id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "lg_Xk3v9Qa1"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "bookings:create",
+                        "rounds:read"
+                    ]
+                }
+            }
+        },
+        "dto.AuditLogEntryResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "role_changed"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "before": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "entity_type": {
+                    "type": "string",
+                    "example": "user"
+                },
+                "entry_id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "mfa": {
+                    "$ref": "#/definitions/dto.MfaChallengeResponse"
+                },
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tokens": {
+                    "$ref": "#/definitions/dto.TokenPairResponse"
+                },
+                "user": {
+                    "$ref": "#/definitions/dto.UserResponse"
+                }
+            }
+        },
+        "dto.BookingRequest": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "number",
+                    "example": 250
+                },
+                "qr_code": {
+                    "type": "string"
+                },
+                "round_id": {
+                    "type": "string"
+                },
+                "seat_number": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "confirmed",
+                        "cancelled",
+                        "refunded"
+                    ],
+                    "example": "confirmed"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BookingResponse": {
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "anonymous"
+                },
+                "price": {
+                    "type": "number",
+                    "example": 250
+                },
+                "qr_code": {
+                    "type": "string"
+                },
+                "round_id": {
+                    "type": "string"
+                },
+                "seat_number": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "confirmed",
+                        "cancelled",
+                        "refunded"
+                    ],
+                    "example": "confirmed"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string",
+                    "example": "anonymous"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dto.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "username"
+            ],
+            "properties": {
+                "username": {
+                    "type": "string",
+                    "example": "somchai"
+                }
+            }
+        },
+        "dto.InvitationRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "user"
+                    ],
+                    "example": "admin"
+                }
+            }
+        },
+        "dto.InvitationResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "invitation_id": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "http://localhost:3000/invitations/accept?token=eyJhbGciOi..."
+                }
+            }
+        },
+        "dto.IssuedApiKeyResponse": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "lg_Xk3v9Qa1..."
+                },
+                "key_id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "lg_Xk3v9Qa1"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "bookings:create",
+                        "rounds:read"
+                    ]
+                }
+            }
+        },
+        "dto.LoginLockoutResponse": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "username",
+                        "ip"
+                    ],
+                    "example": "username"
+                },
+                "last_failure_at": {
+                    "type": "string"
+                },
+                "locked_until": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "alice"
+                }
+            }
+        },
+        "dto.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "s3cret-pass"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "somchai"
+                }
+            }
+        },
+        "dto.LogoutRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Animal deleted successfully"
+                }
+            }
+        },
+        "dto.MfaChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "enrollment_required": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MfaCodeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "recovery_code": {
+                    "type": "string",
+                    "example": "k7m2p-x9qrt"
+                }
+            }
+        },
+        "dto.MfaConfirmRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "dto.MfaEnrollLoginRequest": {
+            "type": "object",
+            "required": [
+                "mfa_token"
+            ],
+            "properties": {
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MfaLoginRequest": {
+            "type": "object",
+            "required": [
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "mfa_token": {
+                    "type": "string"
+                },
+                "recovery_code": {
+                    "type": "string",
+                    "example": "k7m2p-x9qrt"
+                }
+            }
+        },
+        "dto.MfaRequirementRequest": {
+            "type": "object",
+            "required": [
+                "required"
+            ],
+            "properties": {
+                "required": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.MfaRequirementResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "admin"
+                }
+            }
+        },
+        "dto.MfaSetupResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/Liongate:somchai?issuer=Liongate\u0026secret=JBSWY3DPEHPK3PXP"
+                },
+                "qr_code": {
+                    "type": "string",
+                    "example": "data:image/png;base64,iVBORw0KGgo="
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "dto.OidcAuthorizationResponse": {
+            "type": "object",
+            "properties": {
+                "authorization_url": {
+                    "type": "string",
+                    "example": "https://accounts.google.com/o/oauth2/v2/auth?client_id=..."
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "Vg3x8Q6Jt5l2..."
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.Animals": {
+        },
+        "dto.OidcCallbackRequest": {
             "type": "object",
+            "required": [
+                "code",
+                "state"
+            ],
             "properties": {
-                "animal_id": {
-                    "type": "string"
+                "code": {
+                    "type": "string",
+                    "example": "4/0AfJohXk..."
                 },
-                "name": {
+                "state": {
+                    "type": "string",
+                    "example": "Vg3x8Q6Jt5l2..."
+                }
+            }
+        },
+        "dto.OidcIdentityResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
-                "show_duration": {
-                    "type": "integer"
+                "email": {
+                    "type": "string",
+                    "example": "ann@example.com"
                 },
-                "species": {
+                "identity_id": {
                     "type": "string"
                 },
-                "type": {
-                    "type": "string"
+                "provider": {
+                    "type": "string",
+                    "example": "google"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "110248495921238986420"
                 }
             }
         },
-        "domain.AuthResponse": {
+        "dto.PerformanceResponse": {
             "type": "object",
             "properties": {
-                "tokens": {
-                    "$ref": "#/definitions/domain.TokenPair"
+                "animal": {
+                    "$ref": "#/definitions/dto.AnimalResponse"
                 },
-                "user": {
-                    "$ref": "#/definitions/domain.Users"
+                "show_round": {
+                    "$ref": "#/definitions/dto.ShowRoundResponse"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "performing"
                 }
             }
         },
-        "domain.Bookings": {
+        "dto.ProfileResponse": {
             "type": "object",
             "properties": {
-                "booking_id": {
+                "created_at": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "number"
+                "created_by": {
+                    "type": "string",
+                    "example": "anonymous"
                 },
-                "qr_code": {
-                    "type": "string"
+                "display_name": {
+                    "type": "string",
+                    "example": "Somchai J."
                 },
-                "round_id": {
+                "email": {
+                    "type": "string",
+                    "example": "somchai@example.com"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "language": {
+                    "type": "string",
+                    "example": "th"
+                },
+                "marketing_consent": {
+                    "type": "boolean"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+66812345678"
+                },
+                "phone_verified": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "user"
+                },
+                "updated_at": {
                     "type": "string"
                 },
-                "seat_number": {
-                    "type": "integer"
+                "updated_by": {
+                    "type": "string",
+                    "example": "anonymous"
                 },
                 "user_id": {
                     "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "somchai"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "domain.LoginRequest": {
+        "dto.PurgeResponse": {
             "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
             "properties": {
-                "password": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
+                "purged": {
+                    "type": "integer",
+                    "example": 3
                 }
             }
         },
-        "domain.PerformanceStage": {
+        "dto.RecoveryCodesResponse": {
             "type": "object",
             "properties": {
-                "price_per_seat": {
-                    "type": "number"
-                },
-                "room_number": {
-                    "type": "string"
-                },
-                "seat_capacity": {
-                    "type": "integer"
-                },
-                "stage_id": {
-                    "type": "string"
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "domain.RefreshTokenRequest": {
+        "dto.RefreshTokenRequest": {
             "type": "object",
             "required": [
                 "refresh_token"
@@ -1564,25 +5840,59 @@ const docTemplate = `{
                 }
             }
         },
-        "domain.RegisterRequest": {
+        "dto.RegisterRequest": {
             "type": "object",
             "required": [
                 "password",
                 "username"
             ],
             "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "somchai@example.com"
+                },
                 "password": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "s3cret-pass"
                 },
-                "role": {
+                "username": {
+                    "type": "string",
+                    "example": "somchai"
+                }
+            }
+        },
+        "dto.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "n3w-s3cret-pass"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ShowRoundRequest": {
+            "type": "object",
+            "properties": {
+                "animal_id": {
                     "type": "string"
                 },
-                "username": {
+                "show_time": {
+                    "type": "string",
+                    "example": "2025-01-01T10:00:00Z"
+                },
+                "stage_id": {
                     "type": "string"
                 }
             }
         },
-        "domain.ShowRounds": {
+        "dto.ShowRoundResponse": {
             "type": "object",
             "properties": {
                 "animal_id": {
@@ -1591,21 +5901,95 @@ const docTemplate = `{
                 "bookings": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.Bookings"
+                        "$ref": "#/definitions/dto.BookingResponse"
                     }
                 },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "anonymous"
+                },
                 "round_id": {
                     "type": "string"
                 },
                 "show_time": {
+                    "type": "string",
+                    "example": "2025-01-01T10:00:00Z"
+                },
+                "stage_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string",
+                    "example": "anonymous"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dto.StageRequest": {
+            "type": "object",
+            "properties": {
+                "price_per_seat": {
+                    "type": "number",
+                    "example": 250
+                },
+                "room_number": {
+                    "type": "string",
+                    "example": "A101"
+                },
+                "seat_capacity": {
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "dto.StageResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
+                "created_by": {
+                    "type": "string",
+                    "example": "anonymous"
+                },
+                "price_per_seat": {
+                    "type": "number",
+                    "example": 250
+                },
+                "room_number": {
+                    "type": "string",
+                    "example": "A101"
+                },
+                "seat_capacity": {
+                    "type": "integer",
+                    "example": 120
+                },
                 "stage_id": {
                     "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string",
+                    "example": "anonymous"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "domain.TokenPair": {
+        "dto.TokenPairResponse": {
             "type": "object",
             "properties": {
                 "access_token": {
@@ -1616,33 +6000,291 @@ const docTemplate = `{
                 }
             }
         },
-        "domain.Users": {
+        "dto.TrashItemResponse": {
+            "type": "object",
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "entity": {},
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/port.TrashKind"
+                        }
+                    ],
+                    "example": "animals"
+                }
+            }
+        },
+        "dto.UpdateProfileRequest": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "type": "string",
+                    "example": "Somchai J."
+                },
+                "email": {
+                    "type": "string",
+                    "example": "somchai@example.com"
+                },
+                "language": {
+                    "type": "string",
+                    "enum": [
+                        "en",
+                        "th"
+                    ],
+                    "example": "th"
+                },
+                "marketing_consent": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+66812345678"
+                }
+            }
+        },
+        "dto.UpdateUserRequest": {
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "type": "string",
+                    "example": "old-s3cret"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "somchai@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "s3cret-pass"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "user"
+                    ],
+                    "example": "user"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "somchai"
+                }
+            }
+        },
+        "dto.UserResponse": {
             "type": "object",
             "properties": {
                 "bookings": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.Bookings"
+                        "$ref": "#/definitions/dto.BookingResponse"
                     }
                 },
-                "password": {
+                "created_at": {
                     "type": "string"
                 },
+                "created_by": {
+                    "type": "string",
+                    "example": "anonymous"
+                },
+                "display_name": {
+                    "type": "string",
+                    "example": "Somchai J."
+                },
                 "role": {
+                    "type": "string",
+                    "example": "user"
+                },
+                "updated_at": {
                     "type": "string"
                 },
+                "updated_by": {
+                    "type": "string",
+                    "example": "anonymous"
+                },
                 "user_id": {
                     "type": "string"
                 },
                 "username": {
+                    "type": "string",
+                    "example": "somchai"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dto.VerificationSentResponse": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string",
+                    "example": "phone"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "string",
+                    "example": "+66812345678"
+                }
+            }
+        },
+        "dto.VerifyContactRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "042917"
+                }
+            }
+        },
+        "port.Page-dto_AnimalResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AnimalResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "port.Page-dto_AuditLogEntryResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AuditLogEntryResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "port.Page-dto_BookingResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BookingResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "port.Page-dto_ShowRoundResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ShowRoundResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "port.Page-dto_StageResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.StageResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "port.Page-dto_TrashItemResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TrashItemResponse"
+                    }
+                },
+                "next_cursor": {
                     "type": "string"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
+        },
+        "port.TrashKind": {
+            "type": "string",
+            "enum": [
+                "users",
+                "animals",
+                "performance_stages",
+                "show_rounds",
+                "bookings"
+            ],
+            "x-enum-varnames": [
+                "TrashUsers",
+                "TrashAnimals",
+                "TrashStages",
+                "TrashShowRounds",
+                "TrashBookings"
+            ]
         }
     },
     "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key of a machine client, honoured on the routes that require one of its scopes",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BasicAuth": {
             "type": "basic"
+        },
+        "BearerAuth": {
+            "description": "Access token from /auth/login, sent as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -7,33 +7,81 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/khunmostz/be-liongate-go/app/core/domain"
 )
 
 const (
 	AccessTokenType  = "access"
 	RefreshTokenType = "refresh"
+	// InvitationTokenType marks the tokens of invitation links, which cannot be used as access or refresh tokens
+	InvitationTokenType = "invitation"
+	// MfaTokenType marks the tokens of logins waiting for a second factor, which only the MFA login steps accept
+	MfaTokenType = "mfa"
 )
 
+// Defaults of the issuer, audience and clock-skew leeway when JWT_ISSUER, JWT_AUDIENCE or JWT_LEEWAY is unset
+const (
+	defaultIssuer   = "liongate"
+	defaultAudience = "liongate-api"
+	defaultLeeway   = 30 * time.Second
+)
+
+// Tokens carry their times in milliseconds, so that a session revocation tells the tokens issued right after it
+// from those issued before it
+func init() {
+	jwt.TimePrecision = time.Millisecond
+}
+
 var (
-	ErrInvalidToken  = errors.New("invalid token")
-	ErrExpiredToken  = errors.New("token expired")
-	ErrInvalidClaims = errors.New("invalid claims")
+	ErrInvalidToken  = fmt.Errorf("%w: invalid token", domain.ErrUnauthorized)
+	ErrExpiredToken  = domain.ErrTokenExpired
+	ErrInvalidClaims = fmt.Errorf("%w: invalid claims", domain.ErrUnauthorized)
 )
 
+// tokenClaims are the claims of every token the service issues. The registered claims identify the token (jti) and
+// what it is about (sub: the user, or the invitation), who issued it for whom (iss, aud) and when it is valid
+// (iat, nbf, exp); type keeps one kind of token from being used as another.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Type     string `json:"type"`
+	Username string `json:"username,omitempty"`
+	Role     string `json:"role,omitempty"`
+	// LegacyUserId and LegacyInvitationId carry the subject of the HS256 tokens issued before registered claims
+	LegacyUserId       string `json:"user_id,omitempty"`
+	LegacyInvitationId string `json:"invitation_id,omitempty"`
+}
+
+// subject returns what the token is about, falling back to the claims legacy tokens carried it in
+func (c *tokenClaims) subject() string {
+	switch {
+	case c.Subject != "":
+		return c.Subject
+	case c.LegacyUserId != "":
+		return c.LegacyUserId
+	default:
+		return c.LegacyInvitationId
+	}
+}
+
+// JWTService signs tokens with the active key of its key ring and names the key in the kid header, so that
+// verifiers can pick the matching public key from the JWKS document.
 type JWTService struct {
-	secretKey            []byte
+	keys *KeyRing
+	// legacySecret verifies the HS256 tokens issued before tokens were signed with keys; it signs nothing
+	legacySecret         []byte
+	issuer               string
+	audience             string
 	accessTokenDuration  time.Duration
 	refreshTokenDuration time.Duration
+	parser               *jwt.Parser
+	legacyParser         *jwt.Parser
 }
 
-// NewJWTService creates a new JWT service
-func NewJWTService() (*JWTService, error) {
-	secretKey := os.Getenv("JWT_SECRET")
-	if secretKey == "" {
-		return nil, errors.New("JWT_SECRET environment variable not set")
-	}
-
+// NewJWTService creates a new JWT service signing with the keys of keys. Tokens name JWT_ISSUER as issuer and
+// JWT_AUDIENCE as audience, and are checked for both; their times are allowed JWT_LEEWAY of clock skew.
+// JWT_SECRET is optional; when set, HS256 tokens without a kid signed with it are still accepted until they expire.
+func NewJWTService(keys *KeyRing) (*JWTService, error) {
 	accessDuration, err := time.ParseDuration(os.Getenv("JWT_ACCESS_DURATION")) // 15 minutes
 	if err != nil {
 		return nil, fmt.Errorf("invalid JWT_ACCESS_DURATION: %w", err)
@@ -44,21 +92,56 @@ func NewJWTService() (*JWTService, error) {
 		return nil, fmt.Errorf("invalid JWT_REFRESH_DURATION: %w", err)
 	}
 
+	leeway := defaultLeeway
+	if value := os.Getenv("JWT_LEEWAY"); value != "" {
+		if leeway, err = time.ParseDuration(value); err != nil || leeway < 0 {
+			return nil, fmt.Errorf("invalid JWT_LEEWAY: %q", value)
+		}
+	}
+
+	issuer, audience := os.Getenv("JWT_ISSUER"), os.Getenv("JWT_AUDIENCE")
+	if issuer == "" {
+		issuer = defaultIssuer
+	}
+	if audience == "" {
+		audience = defaultAudience
+	}
+
 	return &JWTService{
-		secretKey:            []byte(secretKey),
+		keys:                 keys,
+		legacySecret:         []byte(os.Getenv("JWT_SECRET")),
+		issuer:               issuer,
+		audience:             audience,
 		accessTokenDuration:  accessDuration,
 		refreshTokenDuration: refreshDuration,
+		parser: jwt.NewParser(
+			jwt.WithIssuer(issuer),
+			jwt.WithAudience(audience),
+			jwt.WithLeeway(leeway),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+		),
+		legacyParser: jwt.NewParser(
+			jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+			jwt.WithLeeway(leeway),
+			jwt.WithExpirationRequired(),
+		),
 	}, nil
 }
 
+// TokenLifetime is the longest an access or refresh token stays valid
+func (j *JWTService) TokenLifetime() time.Duration {
+	return max(j.accessTokenDuration, j.refreshTokenDuration)
+}
+
 // GenerateAccessToken creates a new access token
 func (j *JWTService) GenerateAccessToken(user *domain.Users) (string, error) {
-	return j.generateToken(user, AccessTokenType, j.accessTokenDuration)
+	return j.generateToken(user, AccessTokenType, time.Now().Add(j.accessTokenDuration))
 }
 
 // GenerateRefreshToken creates a new refresh token
 func (j *JWTService) GenerateRefreshToken(user *domain.Users) (string, error) {
-	return j.generateToken(user, RefreshTokenType, j.refreshTokenDuration)
+	return j.generateToken(user, RefreshTokenType, time.Now().Add(j.refreshTokenDuration))
 }
 
 // GenerateTokenPair creates both access and refresh tokens
@@ -79,115 +162,170 @@ func (j *JWTService) GenerateTokenPair(user *domain.Users) (*domain.TokenPair, e
 	}, nil
 }
 
-// generateToken creates a JWT token with specified type and duration
-func (j *JWTService) generateToken(user *domain.Users, tokenType string, duration time.Duration) (string, error) {
+// generateToken creates a token of tokenType about user that expires at expiresAt
+func (j *JWTService) generateToken(user *domain.Users, tokenType string, expiresAt time.Time) (string, error) {
+	claims := j.newClaims(tokenType, user.Id, expiresAt)
+	claims.Username = user.Username
+	if tokenType != MfaTokenType {
+		claims.Role = user.Role
+	}
+	return j.sign(claims)
+}
+
+// newClaims returns the claims of a new token of tokenType about subject, with a unique id
+func (j *JWTService) newClaims(tokenType string, subject string, expiresAt time.Time) *tokenClaims {
 	now := time.Now()
-	expiresAt := now.Add(duration)
-
-	claims := domain.JWTClaims{
-		UserID:    user.Id,
-		Username:  user.Username,
-		Role:      user.Role,
-		Type:      tokenType,
-		IssuedAt:  now.Unix(),
-		ExpiresAt: expiresAt.Unix(),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id":  claims.UserID,
-		"username": claims.Username,
-		"role":     claims.Role,
-		"type":     claims.Type,
-		"iat":      claims.IssuedAt,
-		"exp":      claims.ExpiresAt,
-	})
-
-	return token.SignedString(j.secretKey)
-}
-
-// VerifyToken verifies and parses a JWT token
-func (j *JWTService) VerifyToken(tokenString string) (*domain.JWTClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return j.secretKey, nil
-	})
+	return &tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    j.issuer,
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{j.audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Type: tokenType,
+	}
+}
 
+// sign signs claims with the active key of the ring
+func (j *JWTService) sign(claims *tokenClaims) (string, error) {
+	key, err := j.keys.signingKey(time.Now())
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse token: %w", err)
+		return "", err
 	}
 
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.private)
+}
+
+// verify checks the signature, issuer, audience and validity period of a token of tokenType and returns its
+// claims. Tokens must have a subject, and all but legacy ones an id.
+func (j *JWTService) verify(tokenString string, tokenType string) (*tokenClaims, error) {
+	claims := &tokenClaims{}
+	token, err := j.parserFor(tokenString).ParseWithClaims(tokenString, claims, j.verificationKey)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, ErrExpiredToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
 	if !token.Valid {
 		return nil, ErrInvalidToken
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, ErrInvalidClaims
+	if claims.Type != tokenType {
+		return nil, fmt.Errorf("%w: %s token expected", ErrInvalidToken, tokenType)
 	}
-
-	// Check expiration
-	if exp, ok := claims["exp"].(float64); ok {
-		if time.Now().Unix() > int64(exp) {
-			return nil, ErrExpiredToken
-		}
+	if claims.subject() == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidClaims)
 	}
-
-	// Extract claims
-	jwtClaims := &domain.JWTClaims{}
-
-	if userID, ok := claims["user_id"].(string); ok {
-		jwtClaims.UserID = userID
+	if claims.ID == "" && token.Header["kid"] != nil {
+		return nil, fmt.Errorf("%w: no token id", ErrInvalidClaims)
 	}
+	return claims, nil
+}
 
-	if username, ok := claims["username"].(string); ok {
-		jwtClaims.Username = username
+// parserFor picks the parser of a token: the HS256 tokens of before signing keys carry neither a kid nor the
+// registered claims, and are only checked for their signature and expiry
+func (j *JWTService) parserFor(tokenString string) *jwt.Parser {
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &tokenClaims{})
+	if err == nil && token.Header["kid"] == nil && len(j.legacySecret) > 0 {
+		return j.legacyParser
 	}
+	return j.parser
+}
 
-	if role, ok := claims["role"].(string); ok {
-		jwtClaims.Role = role
+// verificationKey picks the key named by the kid header of token. The algorithm must be the one of the key, so
+// that a token cannot make an RSA public key serve as an HMAC secret.
+func (j *JWTService) verificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok && len(j.legacySecret) > 0 {
+			return j.legacySecret, nil
+		}
+		return nil, errors.New("token names no signing key")
 	}
 
-	if tokenType, ok := claims["type"].(string); ok {
-		jwtClaims.Type = tokenType
+	key, ok := j.keys.verificationKey(kid, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
 	}
-
-	if iat, ok := claims["iat"].(float64); ok {
-		jwtClaims.IssuedAt = int64(iat)
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 	}
+	return key.private.Public(), nil
+}
 
-	if exp, ok := claims["exp"].(float64); ok {
-		jwtClaims.ExpiresAt = int64(exp)
+// userClaims converts the claims of a token about a user
+func userClaims(claims *tokenClaims) *domain.JWTClaims {
+	jwtClaims := &domain.JWTClaims{
+		TokenId:  claims.ID,
+		UserID:   claims.subject(),
+		Username: claims.Username,
+		Role:     claims.Role,
+		Type:     claims.Type,
 	}
-
-	return jwtClaims, nil
+	if claims.IssuedAt != nil {
+		jwtClaims.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		jwtClaims.ExpiresAt = claims.ExpiresAt.Unix()
+	}
+	return jwtClaims
 }
 
 // VerifyAccessToken specifically verifies an access token
 func (j *JWTService) VerifyAccessToken(tokenString string) (*domain.JWTClaims, error) {
-	claims, err := j.VerifyToken(tokenString)
+	claims, err := j.verify(tokenString, AccessTokenType)
 	if err != nil {
 		return nil, err
 	}
-
-	if claims.Type != AccessTokenType {
-		return nil, errors.New("not an access token")
-	}
-
-	return claims, nil
+	return userClaims(claims), nil
 }
 
 // VerifyRefreshToken specifically verifies a refresh token
 func (j *JWTService) VerifyRefreshToken(tokenString string) (*domain.JWTClaims, error) {
-	claims, err := j.VerifyToken(tokenString)
+	claims, err := j.verify(tokenString, RefreshTokenType)
 	if err != nil {
 		return nil, err
 	}
+	return userClaims(claims), nil
+}
+
+// GenerateInvitationToken signs the token of an invitation link; it expires together with the invitation
+func (j *JWTService) GenerateInvitationToken(invitation *domain.Invitation) (string, error) {
+	claims := j.newClaims(InvitationTokenType, invitation.Id, invitation.ExpiresAt)
+	claims.Role = invitation.Role
+	return j.sign(claims)
+}
 
-	if claims.Type != RefreshTokenType {
-		return nil, errors.New("not a refresh token")
+// VerifyInvitationToken verifies the token of an invitation link and returns its claims
+func (j *JWTService) VerifyInvitationToken(tokenString string) (*domain.InvitationClaims, error) {
+	claims, err := j.verify(tokenString, InvitationTokenType)
+	if err != nil {
+		return nil, err
 	}
 
-	return claims, nil
+	return &domain.InvitationClaims{
+		InvitationId: claims.subject(),
+		Role:         claims.Role,
+		ExpiresAt:    claims.ExpiresAt.Unix(),
+	}, nil
+}
+
+// GenerateMfaToken signs the token a login that still needs a second factor is continued with
+func (j *JWTService) GenerateMfaToken(user *domain.Users, expiresAt time.Time) (string, error) {
+	return j.generateToken(user, MfaTokenType, expiresAt)
+}
+
+// VerifyMfaToken verifies the token of a login waiting for a second factor and returns its claims
+func (j *JWTService) VerifyMfaToken(tokenString string) (*domain.JWTClaims, error) {
+	claims, err := j.verify(tokenString, MfaTokenType)
+	if err != nil {
+		return nil, err
+	}
+	return userClaims(claims), nil
 }
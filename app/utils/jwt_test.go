@@ -0,0 +1,311 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRing returns a ring holding an active key and an expired one, along with both keys decoded
+func newTestRing(t *testing.T) (*KeyRing, ringKey, ringKey) {
+	now := time.Now()
+	active, err := GenerateSigningKey(domain.SigningAlgorithmEdDSA)
+	require.NoError(t, err)
+	active.ActivatesAt, active.RetiresAt, active.ExpiresAt = now.Add(-time.Hour), now.Add(time.Hour), now.Add(2*time.Hour)
+	expired, err := GenerateSigningKey(domain.SigningAlgorithmRS256)
+	require.NoError(t, err)
+	expired.ActivatesAt, expired.RetiresAt, expired.ExpiresAt = now.Add(-3*time.Hour), now.Add(-2*time.Hour), now.Add(-time.Minute)
+
+	ring := NewKeyRing()
+	require.NoError(t, ring.Replace([]domain.SigningKey{*active, *expired}))
+	activeKey, err := decodeSigningKey(*active)
+	require.NoError(t, err)
+	expiredKey, err := decodeSigningKey(*expired)
+	require.NoError(t, err)
+	return ring, activeKey, expiredKey
+}
+
+func TestVerifyToken(t *testing.T) {
+	t.Setenv("JWT_ACCESS_DURATION", "15m")
+	t.Setenv("JWT_REFRESH_DURATION", "168h")
+	t.Setenv("JWT_LEEWAY", "30s")
+	ring, active, expiredKey := newTestRing(t)
+	jwtService, err := NewJWTService(ring)
+	require.NoError(t, err)
+
+	stranger, err := GenerateSigningKey(domain.SigningAlgorithmEdDSA)
+	require.NoError(t, err)
+	strangerKey, err := decodeSigningKey(*stranger)
+	require.NoError(t, err)
+
+	now := time.Now()
+	validClaims := func() *tokenClaims {
+		claims := jwtService.newClaims(AccessTokenType, "u1", now.Add(time.Minute))
+		claims.Username, claims.Role = "alice", domain.RoleUser
+		return claims
+	}
+	// signed signs claims with key under kid, or with the HMAC secret when key is nil
+	signed := func(t *testing.T, method jwt.SigningMethod, kid string, key crypto.Signer, secret []byte, claims *tokenClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		var signingKey any = secret
+		if key != nil {
+			signingKey = key
+		}
+		s, err := token.SignedString(signingKey)
+		require.NoError(t, err)
+		return s
+	}
+	withActive := func(t *testing.T, mutate func(*tokenClaims)) string {
+		claims := validClaims()
+		mutate(claims)
+		return signed(t, active.method, active.Kid, active.private, nil, claims)
+	}
+	at := func(d time.Duration) *jwt.NumericDate { return jwt.NewNumericDate(now.Add(d)) }
+
+	tests := []struct {
+		name  string
+		token func(t *testing.T) string
+		err   error
+	}{
+		{
+			name:  "valid",
+			token: func(t *testing.T) string { return withActive(t, func(*tokenClaims) {}) },
+		},
+		{
+			name:  "malformed",
+			token: func(t *testing.T) string { return "not.a.token" },
+			err:   ErrInvalidToken,
+		},
+		{
+			name: "unknown kid",
+			token: func(t *testing.T) string {
+				return signed(t, strangerKey.method, strangerKey.Kid, strangerKey.private, nil, validClaims())
+			},
+			err: ErrInvalidToken,
+		},
+		{
+			name: "signed by another key under a known kid",
+			token: func(t *testing.T) string {
+				return signed(t, strangerKey.method, active.Kid, strangerKey.private, nil, validClaims())
+			},
+			err: ErrInvalidToken,
+		},
+		{
+			name: "key has expired",
+			token: func(t *testing.T) string {
+				return signed(t, expiredKey.method, expiredKey.Kid, expiredKey.private, nil, validClaims())
+			},
+			err: ErrInvalidToken,
+		},
+		{
+			name: "unsigned",
+			token: func(t *testing.T) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims())
+				token.Header["kid"] = active.Kid
+				s, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+				require.NoError(t, err)
+				return s
+			},
+			err: ErrInvalidToken,
+		},
+		{
+			name: "HMAC with the public key as secret",
+			token: func(t *testing.T) string {
+				public := active.private.Public().(ed25519.PublicKey)
+				return signed(t, jwt.SigningMethodHS256, active.Kid, nil, public, validClaims())
+			},
+			err: ErrInvalidToken,
+		},
+		{
+			name: "HS256 without a legacy secret",
+			token: func(t *testing.T) string {
+				return signed(t, jwt.SigningMethodHS256, "", nil, []byte("secret"), validClaims())
+			},
+			err: ErrInvalidToken,
+		},
+		{
+			name: "expired",
+			token: func(t *testing.T) string {
+				return withActive(t, func(c *tokenClaims) { c.ExpiresAt = at(-time.Minute) })
+			},
+			err: ErrExpiredToken,
+		},
+		{
+			name: "expired within the leeway",
+			token: func(t *testing.T) string {
+				return withActive(t, func(c *tokenClaims) { c.ExpiresAt = at(-10 * time.Second) })
+			},
+		},
+		{
+			name:  "without expiry",
+			token: func(t *testing.T) string { return withActive(t, func(c *tokenClaims) { c.ExpiresAt = nil }) },
+			err:   ErrInvalidToken,
+		},
+		{
+			name: "not valid yet",
+			token: func(t *testing.T) string {
+				return withActive(t, func(c *tokenClaims) { c.NotBefore = at(time.Minute) })
+			},
+			err: ErrInvalidToken,
+		},
+		{
+			name: "not valid yet within the leeway",
+			token: func(t *testing.T) string {
+				return withActive(t, func(c *tokenClaims) { c.NotBefore = at(10 * time.Second) })
+			},
+		},
+		{
+			name:  "issued in the future",
+			token: func(t *testing.T) string { return withActive(t, func(c *tokenClaims) { c.IssuedAt = at(time.Minute) }) },
+			err:   ErrInvalidToken,
+		},
+		{
+			name:  "other issuer",
+			token: func(t *testing.T) string { return withActive(t, func(c *tokenClaims) { c.Issuer = "someone-else" }) },
+			err:   ErrInvalidToken,
+		},
+		{
+			name:  "without issuer",
+			token: func(t *testing.T) string { return withActive(t, func(c *tokenClaims) { c.Issuer = "" }) },
+			err:   ErrInvalidToken,
+		},
+		{
+			name: "other audience",
+			token: func(t *testing.T) string {
+				return withActive(t, func(c *tokenClaims) { c.Audience = jwt.ClaimStrings{"kiosk"} })
+			},
+			err: ErrInvalidToken,
+		},
+		{
+			name:  "without audience",
+			token: func(t *testing.T) string { return withActive(t, func(c *tokenClaims) { c.Audience = nil }) },
+			err:   ErrInvalidToken,
+		},
+		{
+			name:  "without token id",
+			token: func(t *testing.T) string { return withActive(t, func(c *tokenClaims) { c.ID = "" }) },
+			err:   ErrInvalidClaims,
+		},
+		{
+			name:  "without subject",
+			token: func(t *testing.T) string { return withActive(t, func(c *tokenClaims) { c.Subject = "" }) },
+			err:   ErrInvalidClaims,
+		},
+		{
+			name:  "other token type",
+			token: func(t *testing.T) string { return withActive(t, func(c *tokenClaims) { c.Type = RefreshTokenType }) },
+			err:   ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := jwtService.VerifyAccessToken(tt.token(t))
+
+			if tt.err == nil {
+				require.NoError(t, err)
+				assert.Equal(t, "u1", claims.UserID)
+				assert.NotEmpty(t, claims.TokenId)
+				return
+			}
+			assert.ErrorIs(t, err, tt.err)
+			assert.Nil(t, claims)
+		})
+	}
+}
+
+func TestVerifyLegacyToken(t *testing.T) {
+	t.Setenv("JWT_ACCESS_DURATION", "15m")
+	t.Setenv("JWT_REFRESH_DURATION", "168h")
+	t.Setenv("JWT_SECRET", "legacy-secret")
+	ring, _, _ := newTestRing(t)
+	jwtService, err := NewJWTService(ring)
+	require.NoError(t, err)
+
+	legacy := func(t *testing.T, secret string, claims jwt.MapClaims) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		require.NoError(t, err)
+		return s
+	}
+	valid := jwt.MapClaims{"user_id": "u1", "type": AccessTokenType, "iat": time.Now().Unix(), "exp": time.Now().Add(time.Minute).Unix()}
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{name: "valid", token: legacy(t, "legacy-secret", valid)},
+		{name: "other secret", token: legacy(t, "other-secret", valid), err: ErrInvalidToken},
+		{
+			name:  "expired",
+			token: legacy(t, "legacy-secret", jwt.MapClaims{"user_id": "u1", "type": AccessTokenType, "exp": time.Now().Add(-time.Hour).Unix()}),
+			err:   ErrExpiredToken,
+		},
+		{
+			name:  "without expiry",
+			token: legacy(t, "legacy-secret", jwt.MapClaims{"user_id": "u1", "type": AccessTokenType}),
+			err:   ErrInvalidToken,
+		},
+		{
+			name:  "without user",
+			token: legacy(t, "legacy-secret", jwt.MapClaims{"type": AccessTokenType, "exp": time.Now().Add(time.Minute).Unix()}),
+			err:   ErrInvalidClaims,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := jwtService.VerifyAccessToken(tt.token)
+
+			if tt.err == nil {
+				require.NoError(t, err)
+				assert.Equal(t, "u1", claims.UserID)
+				assert.Empty(t, claims.TokenId)
+				return
+			}
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestIssuedClaims(t *testing.T) {
+	t.Setenv("JWT_ACCESS_DURATION", "15m")
+	t.Setenv("JWT_REFRESH_DURATION", "168h")
+	t.Setenv("JWT_ISSUER", "https://liongate.test")
+	t.Setenv("JWT_AUDIENCE", "gate-scanner")
+	ring, _, _ := newTestRing(t)
+	jwtService, err := NewJWTService(ring)
+	require.NoError(t, err)
+	user := &domain.Users{Id: "u1", Username: "alice", Role: domain.RoleAdmin}
+
+	first, err := jwtService.GenerateAccessToken(user)
+	require.NoError(t, err)
+	second, err := jwtService.GenerateAccessToken(user)
+	require.NoError(t, err)
+
+	claims := &tokenClaims{}
+	_, _, err = jwt.NewParser().ParseUnverified(first, claims)
+	require.NoError(t, err)
+	assert.Equal(t, "https://liongate.test", claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings{"gate-scanner"}, claims.Audience)
+	assert.Equal(t, "u1", claims.Subject)
+	assert.Equal(t, claims.IssuedAt, claims.NotBefore)
+	assert.NotEmpty(t, claims.ID)
+
+	firstClaims, err := jwtService.VerifyAccessToken(first)
+	require.NoError(t, err)
+	secondClaims, err := jwtService.VerifyAccessToken(second)
+	require.NoError(t, err)
+	assert.NotEqual(t, firstClaims.TokenId, secondClaims.TokenId, "every token has its own id")
+	assert.Equal(t, domain.RoleAdmin, firstClaims.Role)
+	assert.WithinDuration(t, time.Now(), firstClaims.IssuedAt, time.Second)
+}
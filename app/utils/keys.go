@@ -0,0 +1,259 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+)
+
+// rsaKeyBits is the size of the RSA keys GenerateSigningKey creates
+const rsaKeyBits = 2048
+
+// kidBytes is the entropy of the key ids GenerateSigningKey assigns
+const kidBytes = 8
+
+// ErrNoSigningKey is returned while no key is active, which only happens before the keys were first loaded
+var ErrNoSigningKey = errors.New("no active signing key")
+
+// GenerateSigningKey creates a new private key for algorithm with a random key id, ready to be stored
+func GenerateSigningKey(algorithm string) (*domain.SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch algorithm {
+	case domain.SigningAlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case domain.SigningAlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	kid := make([]byte, kidBytes)
+	if _, err := rand.Read(kid); err != nil {
+		return nil, err
+	}
+
+	return &domain.SigningKey{
+		Kid:        hex.EncodeToString(kid),
+		Algorithm:  algorithm,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	}, nil
+}
+
+// keyEncryptionKeyBytes is the size of the AES-256 key-encryption key of a KeyCipher
+const keyEncryptionKeyBytes = 32
+
+// sealedKeyPrefix marks a private key a KeyCipher encrypted, as opposed to the plain PEM stored before keys were
+// encrypted
+const sealedKeyPrefix = "aes256gcm:"
+
+// KeyCipher encrypts the private keys of signing keys with a key-encryption key that is kept out of the database,
+// so that reading the stored keys is not enough to sign tokens
+type KeyCipher struct {
+	aead cipher.AEAD
+}
+
+// NewKeyCipher creates the cipher from a base64 encoded 32 byte key-encryption key
+func NewKeyCipher(encodedKey string) (*KeyCipher, error) {
+	kek, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("key-encryption key is not base64: %w", err)
+	}
+	if len(kek) != keyEncryptionKeyBytes {
+		return nil, fmt.Errorf("key-encryption key must be %d bytes, got %d", keyEncryptionKeyBytes, len(kek))
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyCipher{aead: aead}, nil
+}
+
+// IsSealed reports whether the private key of key is encrypted
+func IsSealed(key domain.SigningKey) bool {
+	return strings.HasPrefix(key.PrivateKey, sealedKeyPrefix)
+}
+
+// Seal returns key with its private key encrypted. The key id is authenticated along, so a sealed private key
+// cannot be moved to another key.
+func (c *KeyCipher) Seal(key domain.SigningKey) (domain.SigningKey, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return domain.SigningKey{}, err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(key.PrivateKey), []byte(key.Kid))
+	key.PrivateKey = sealedKeyPrefix + base64.StdEncoding.EncodeToString(sealed)
+	return key, nil
+}
+
+// Open returns key with the private key Seal encrypted decrypted again
+func (c *KeyCipher) Open(key domain.SigningKey) (domain.SigningKey, error) {
+	if !IsSealed(key) {
+		return domain.SigningKey{}, errors.New("private key is not encrypted")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(key.PrivateKey, sealedKeyPrefix))
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return domain.SigningKey{}, errors.New("encrypted private key is malformed")
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, ciphertext, []byte(key.Kid))
+	if err != nil {
+		return domain.SigningKey{}, errors.New("private key cannot be decrypted with this key-encryption key")
+	}
+	key.PrivateKey = string(plain)
+	return key, nil
+}
+
+// ringKey is a stored key with its private key decoded
+type ringKey struct {
+	domain.SigningKey
+	private crypto.Signer
+	method  jwt.SigningMethod
+}
+
+// KeyRing holds the keys a JWTService signs and verifies tokens with. The keys are swapped as a whole whenever
+// they are rotated, while requests keep using it.
+type KeyRing struct {
+	mu   sync.RWMutex
+	keys []ringKey
+}
+
+// NewKeyRing creates an empty key ring; tokens cannot be signed until Replace loads the keys
+func NewKeyRing() *KeyRing {
+	return &KeyRing{}
+}
+
+// Replace swaps the keys of the ring for keys. The ring is left alone when one of them cannot be decoded.
+func (r *KeyRing) Replace(keys []domain.SigningKey) error {
+	decoded := make([]ringKey, 0, len(keys))
+	for _, key := range keys {
+		rk, err := decodeSigningKey(key)
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", key.Kid, err)
+		}
+		decoded = append(decoded, rk)
+	}
+	slices.SortFunc(decoded, func(a, b ringKey) int {
+		if c := a.ActivatesAt.Compare(b.ActivatesAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Kid, b.Kid)
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = decoded
+	return nil
+}
+
+// signingKey returns the key that started signing last among the active ones at now. Instances that created
+// successors at the same time agree on the one with the greatest key id.
+func (r *KeyRing) signingKey(now time.Time) (ringKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if r.keys[i].Signs(now) {
+			return r.keys[i], nil
+		}
+	}
+	return ringKey{}, ErrNoSigningKey
+}
+
+// verificationKey returns the key with kid, unless it is unknown or expired at now
+func (r *KeyRing) verificationKey(kid string, now time.Time) (ringKey, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.Kid == kid {
+			return key, !key.Expired(now)
+		}
+	}
+	return ringKey{}, false
+}
+
+// PublicKeys returns the public halves of the keys that have not expired at now, including the ones that have
+// not started signing yet
+func (r *KeyRing) PublicKeys(now time.Time) []domain.JSONWebKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jwks := make([]domain.JSONWebKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		if !key.Expired(now) {
+			jwks = append(jwks, publicJWK(key))
+		}
+	}
+	return jwks
+}
+
+// decodeSigningKey parses the PEM of a stored key and checks that it matches its algorithm
+func decodeSigningKey(key domain.SigningKey) (ringKey, error) {
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return ringKey{}, errors.New("private key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return ringKey{}, err
+	}
+
+	rk := ringKey{SigningKey: key}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		rk.private, rk.method = private, jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		rk.private, rk.method = private, jwt.SigningMethodEdDSA
+	default:
+		return ringKey{}, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+	if rk.method.Alg() != key.Algorithm {
+		return ringKey{}, fmt.Errorf("private key does not match algorithm %q", key.Algorithm)
+	}
+	return rk, nil
+}
+
+// publicJWK encodes the public half of a key as a JSON Web Key
+func publicJWK(key ringKey) domain.JSONWebKey {
+	jwk := domain.JSONWebKey{Kid: key.Kid, Use: "sig", Alg: key.Algorithm}
+	switch public := key.private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
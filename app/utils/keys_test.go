@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyCipher(t *testing.T) {
+	cipher, err := NewKeyCipher(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	require.NoError(t, err)
+	key, err := GenerateSigningKey(domain.SigningAlgorithmEdDSA)
+	require.NoError(t, err)
+
+	sealed, err := cipher.Seal(*key)
+	require.NoError(t, err)
+	assert.True(t, IsSealed(sealed))
+	assert.False(t, IsSealed(*key))
+	assert.NotContains(t, sealed.PrivateKey, "PRIVATE KEY")
+
+	opened, err := cipher.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, key.PrivateKey, opened.PrivateKey)
+
+	t.Run("bound to the key id", func(t *testing.T) {
+		moved := sealed
+		moved.Kid = "another"
+		_, err := cipher.Open(moved)
+		assert.Error(t, err)
+	})
+
+	t.Run("another key-encryption key cannot open it", func(t *testing.T) {
+		other, err := NewKeyCipher(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{8}, 32)))
+		require.NoError(t, err)
+		_, err = other.Open(sealed)
+		assert.ErrorContains(t, err, "cannot be decrypted")
+	})
+
+	t.Run("plain PEM is not opened", func(t *testing.T) {
+		_, err := cipher.Open(*key)
+		assert.ErrorContains(t, err, "not encrypted")
+	})
+}
+
+func TestNewKeyCipher(t *testing.T) {
+	for name, encoded := range map[string]string{
+		"not base64": "not base64!",
+		"too short":  base64.StdEncoding.EncodeToString(make([]byte, 16)),
+		"empty":      "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewKeyCipher(encoded)
+			assert.Error(t, err)
+		})
+	}
+}
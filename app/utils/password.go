@@ -1,87 +1,247 @@
 package utils
 
 import (
+	"bufio"
+	"crypto/rand"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Algorithms a PasswordHasher makes new hashes with
 const (
-	// DefaultCost is the default cost for bcrypt hashing
-	DefaultCost = bcrypt.DefaultCost
-	// MinCost is the minimum cost for bcrypt hashing
-	MinCost = bcrypt.MinCost
-	// MaxCost is the maximum cost for bcrypt hashing
-	MaxCost = bcrypt.MaxCost
+	PasswordAlgorithmArgon2id = "argon2id"
+	PasswordAlgorithmBcrypt   = "bcrypt"
+)
+
+const (
+	// MinPasswordLength is the minimum length of a password in bytes
+	MinPasswordLength = 6
+	// MaxPasswordLength is the maximum length of a password in bytes, which keeps hashing cheap whatever is sent
+	MaxPasswordLength = 128
+	// MaxBcryptPasswordLength is the maximum length of a password hashed with bcrypt, which ignores everything after it
+	MaxBcryptPasswordLength = 72
+)
+
+const (
+	// argon2SaltLength and argon2KeyLength are the sizes of the salt and the derived key of argon2id hashes in bytes
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+	// maxArgon2Memory bounds the memory of argon2id hashes, configured or stored, to 1 GiB
+	maxArgon2Memory = 1 << 20
 )
 
 var (
-	ErrInvalidPassword = errors.New("invalid password")
-	ErrPasswordTooLong = errors.New("password is too long")
+	ErrPasswordTooShort  = errors.New("password must be at least 6 characters long")
+	ErrPasswordTooLong   = errors.New("password is too long")
+	ErrPasswordTooCommon = errors.New("password is too common")
 )
 
-// HashPassword hashes a password using bcrypt
-func HashPassword(password string) (string, error) {
-	// Check if password is too long (bcrypt has a 72-byte limit)
-	if len(password) > 72 {
-		return "", ErrPasswordTooLong
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonPasswords is the set of common_passwords.txt in lower case
+var commonPasswords = sync.OnceValue(func() map[string]struct{} {
+	passwords := map[string]struct{}{}
+	scanner := bufio.NewScanner(strings.NewReader(commonPasswordList))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			passwords[strings.ToLower(line)] = struct{}{}
+		}
 	}
+	return passwords
+})
+
+// PasswordParams are the algorithm and parameters a PasswordHasher makes new hashes with. The argon2id memory is
+// in KiB (RFC 9106).
+type PasswordParams struct {
+	Algorithm         string
+	Argon2Memory      int
+	Argon2Iterations  int
+	Argon2Parallelism int
+	BcryptCost        int
+}
 
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), DefaultCost)
-	if err != nil {
-		return "", err
+// DefaultPasswordParams follow the OWASP recommendation for argon2id: 19 MiB of memory, two passes and one lane
+var DefaultPasswordParams = PasswordParams{
+	Algorithm:         PasswordAlgorithmArgon2id,
+	Argon2Memory:      19 * 1024,
+	Argon2Iterations:  2,
+	Argon2Parallelism: 1,
+	BcryptCost:        12,
+}
+
+// argon2Params are the parameters encoded in an argon2id hash
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// PasswordHasher hashes passwords with the configured algorithm and parameters, and verifies hashes made with
+// any of them. Hashes carry their parameters: argon2id ones in the PHC string format,
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<key>, and bcrypt ones in its own $2b$<cost>$ format.
+type PasswordHasher struct {
+	params PasswordParams
+}
+
+// NewPasswordHasher creates a hasher making new hashes with params. Parameters out of range are an error rather
+// than replaced by defaults, so that a typo does not silently weaken the hashes.
+func NewPasswordHasher(params PasswordParams) (*PasswordHasher, error) {
+	switch params.Algorithm {
+	case PasswordAlgorithmArgon2id:
+		if params.Argon2Iterations < 1 {
+			return nil, fmt.Errorf("argon2id iterations must be at least 1, got %d", params.Argon2Iterations)
+		}
+		if params.Argon2Parallelism < 1 || params.Argon2Parallelism > 255 {
+			return nil, fmt.Errorf("argon2id parallelism must be between 1 and 255, got %d", params.Argon2Parallelism)
+		}
+		if params.Argon2Memory < 8*params.Argon2Parallelism || params.Argon2Memory > maxArgon2Memory {
+			return nil, fmt.Errorf("argon2id memory must be between %d and %d KiB, got %d", 8*params.Argon2Parallelism, maxArgon2Memory, params.Argon2Memory)
+		}
+	case PasswordAlgorithmBcrypt:
+		if params.BcryptCost < bcrypt.MinCost || params.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, params.BcryptCost)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported password hashing algorithm %q", params.Algorithm)
+	}
+	return &PasswordHasher{params: params}, nil
+}
+
+// MaxLength is the longest password in bytes the hasher accepts
+func (h *PasswordHasher) MaxLength() int {
+	if h.params.Algorithm == PasswordAlgorithmBcrypt {
+		return MaxBcryptPasswordLength
 	}
+	return MaxPasswordLength
+}
 
-	return string(hashedBytes), nil
+// CheckStrength checks that a password about to be set is long enough, not too long for the hasher, and not on
+// the bundled list of common passwords
+func (h *PasswordHasher) CheckStrength(password string) error {
+	if len(password) < MinPasswordLength {
+		return ErrPasswordTooShort
+	}
+	if len(password) > h.MaxLength() {
+		return ErrPasswordTooLong
+	}
+	if _, ok := commonPasswords()[strings.ToLower(password)]; ok {
+		return ErrPasswordTooCommon
+	}
+	return nil
 }
 
-// HashPasswordWithCost hashes a password using bcrypt with custom cost
-func HashPasswordWithCost(password string, cost int) (string, error) {
-	// Check if password is too long (bcrypt has a 72-byte limit)
-	if len(password) > 72 {
+// Hash hashes a password with a random salt
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	if len(password) > h.MaxLength() {
 		return "", ErrPasswordTooLong
 	}
 
-	// Validate cost parameter
-	if cost < MinCost || cost > MaxCost {
-		cost = DefaultCost
+	if h.params.Algorithm == PasswordAlgorithmBcrypt {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.params.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hashed), nil
 	}
 
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), cost)
-	if err != nil {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
 		return "", err
 	}
-
-	return string(hashedBytes), nil
+	params := h.argon2Params()
+	key := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, params.memory, params.iterations, params.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
 }
 
-// VerifyPassword compares a hashed password with a plain text password
-func VerifyPassword(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+// Verify reports whether password matches hash, whichever algorithm and parameters the hash was made with.
+// Users registered before passwords were hashed have theirs stored as is; it is compared as such, and NeedsRehash
+// has it replaced by a hash on their next login.
+func (h *PasswordHasher) Verify(hash string, password string) bool {
+	if isBcryptHash(hash) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+	if !isArgon2Hash(hash) {
+		return hash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(password)) == 1
+	}
+
+	params, salt, key, err := decodeArgon2(hash)
+	if err != nil || len(password) > MaxPasswordLength {
+		return false
+	}
+	derived := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(derived, key) == 1
 }
 
-// CheckPasswordStrength checks if password meets minimum requirements
-func CheckPasswordStrength(password string) error {
-	if len(password) < 6 {
-		return errors.New("password must be at least 6 characters long")
+// NeedsRehash reports whether hash was made with another algorithm or other parameters than the hasher makes
+// new hashes with, so that it should be replaced once the password is known
+func (h *PasswordHasher) NeedsRehash(hash string) bool {
+	if isBcryptHash(hash) {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || h.params.Algorithm != PasswordAlgorithmBcrypt || cost != h.params.BcryptCost
 	}
 
-	if len(password) > 72 {
-		return ErrPasswordTooLong
+	params, _, key, err := decodeArgon2(hash)
+	if err != nil {
+		return true
+	}
+	return h.params.Algorithm != PasswordAlgorithmArgon2id || params != h.argon2Params() || len(key) != argon2KeyLength
+}
+
+func (h *PasswordHasher) argon2Params() argon2Params {
+	return argon2Params{
+		memory:      uint32(h.params.Argon2Memory),
+		iterations:  uint32(h.params.Argon2Iterations),
+		parallelism: uint8(h.params.Argon2Parallelism),
 	}
+}
 
-	// You can add more password strength validations here:
-	// - Must contain uppercase letter
-	// - Must contain lowercase letter
-	// - Must contain number
-	// - Must contain special character
-	// etc.
+// isBcryptHash reports whether hash is in one of the formats of bcrypt
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
 
-	return nil
+// isArgon2Hash reports whether hash is in the PHC string format of an argon2 variant, though possibly a malformed one
+func isArgon2Hash(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2")
 }
 
-// IsPasswordValid validates password and returns true if valid
-func IsPasswordValid(hashedPassword, password string) bool {
-	err := VerifyPassword(hashedPassword, password)
-	return err == nil
+// decodeArgon2 parses an argon2id hash in the PHC string format
+func decodeArgon2(hash string) (argon2Params, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != PasswordAlgorithmArgon2id {
+		return argon2Params{}, nil, nil, errors.New("not an argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Params{}, nil, nil, fmt.Errorf("unsupported argon2 version %q", parts[2])
+	}
+	var params argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return argon2Params{}, nil, nil, fmt.Errorf("invalid argon2 parameters %q", parts[3])
+	}
+	if params.iterations < 1 || params.parallelism < 1 || params.memory < 8*uint32(params.parallelism) || params.memory > maxArgon2Memory {
+		return argon2Params{}, nil, nil, fmt.Errorf("argon2 parameters %q out of range", parts[3])
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return argon2Params{}, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return argon2Params{}, nil, nil, errors.New("invalid argon2 key")
+	}
+	return params, salt, key, nil
 }
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// cheapArgon2 are argon2id parameters small enough for tests
+var cheapArgon2 = PasswordParams{Algorithm: PasswordAlgorithmArgon2id, Argon2Memory: 64, Argon2Iterations: 1, Argon2Parallelism: 1}
+
+func newTestHasher(t *testing.T, params PasswordParams) *PasswordHasher {
+	t.Helper()
+	hasher, err := NewPasswordHasher(params)
+	require.NoError(t, err)
+	return hasher
+}
+
+func TestPasswordHasherArgon2id(t *testing.T) {
+	hasher := newTestHasher(t, cheapArgon2)
+
+	hash, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$"), hash)
+	assert.True(t, hasher.Verify(hash, "correct horse"))
+	assert.False(t, hasher.Verify(hash, "correct horse!"))
+	assert.False(t, hasher.NeedsRehash(hash))
+
+	again, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, again, "every hash has its own salt")
+}
+
+func TestPasswordHasherVerify(t *testing.T) {
+	hasher := newTestHasher(t, cheapArgon2)
+
+	t.Run("bcrypt hashes still verify", func(t *testing.T) {
+		hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+		require.NoError(t, err)
+
+		assert.True(t, hasher.Verify(string(hash), "correct horse"))
+		assert.False(t, hasher.Verify(string(hash), "wrong horse"))
+	})
+
+	t.Run("hashes with other parameters verify", func(t *testing.T) {
+		hash, err := newTestHasher(t, PasswordParams{Algorithm: PasswordAlgorithmArgon2id, Argon2Memory: 128, Argon2Iterations: 2, Argon2Parallelism: 2}).Hash("correct horse")
+		require.NoError(t, err)
+
+		assert.True(t, hasher.Verify(hash, "correct horse"))
+	})
+
+	t.Run("passwords stored unhashed verify", func(t *testing.T) {
+		assert.True(t, hasher.Verify("correct horse", "correct horse"))
+		assert.False(t, hasher.Verify("correct horse", "correct horse!"))
+		assert.False(t, hasher.Verify("", ""))
+		assert.True(t, hasher.NeedsRehash("correct horse"))
+	})
+
+	for _, hash := range []string{
+		"",
+		"$argon2i$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=16$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=4194304,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$",
+	} {
+		assert.False(t, hasher.Verify(hash, "correct horse"), "malformed hash %q", hash)
+		assert.False(t, hasher.Verify(hash, hash), "malformed hash %q is not compared as a password", hash)
+	}
+}
+
+func TestPasswordHasherNeedsRehash(t *testing.T) {
+	argon2Hash, err := newTestHasher(t, cheapArgon2).Hash("correct horse")
+	require.NoError(t, err)
+	bcryptHash, err := newTestHasher(t, PasswordParams{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: bcrypt.MinCost}).Hash("correct horse")
+	require.NoError(t, err)
+
+	stronger := cheapArgon2
+	stronger.Argon2Iterations = 2
+	tests := []struct {
+		name   string
+		params PasswordParams
+		hash   string
+		want   bool
+	}{
+		{"current argon2id", cheapArgon2, argon2Hash, false},
+		{"argon2id with other parameters", stronger, argon2Hash, true},
+		{"bcrypt after switching to argon2id", cheapArgon2, bcryptHash, true},
+		{"current bcrypt", PasswordParams{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: bcrypt.MinCost}, bcryptHash, false},
+		{"bcrypt with another cost", PasswordParams{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: bcrypt.MinCost + 1}, bcryptHash, true},
+		{"argon2id after switching to bcrypt", PasswordParams{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: bcrypt.MinCost}, argon2Hash, true},
+		{"malformed", cheapArgon2, "$argon2id$broken", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, newTestHasher(t, tt.params).NeedsRehash(tt.hash))
+		})
+	}
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	_, err := NewPasswordHasher(DefaultPasswordParams)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		params PasswordParams
+		want   string
+	}{
+		{"unknown algorithm", PasswordParams{Algorithm: "scrypt"}, "unsupported password hashing algorithm"},
+		{"bcrypt cost too low", PasswordParams{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: bcrypt.MinCost - 1}, "bcrypt cost"},
+		{"bcrypt cost too high", PasswordParams{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: bcrypt.MaxCost + 1}, "bcrypt cost"},
+		{"no iterations", PasswordParams{Algorithm: PasswordAlgorithmArgon2id, Argon2Memory: 64, Argon2Parallelism: 1}, "iterations"},
+		{"no parallelism", PasswordParams{Algorithm: PasswordAlgorithmArgon2id, Argon2Memory: 64, Argon2Iterations: 1}, "parallelism"},
+		{"too little memory", PasswordParams{Algorithm: PasswordAlgorithmArgon2id, Argon2Memory: 8, Argon2Iterations: 1, Argon2Parallelism: 2}, "memory"},
+		{"too much memory", PasswordParams{Algorithm: PasswordAlgorithmArgon2id, Argon2Memory: 2 << 20, Argon2Iterations: 1, Argon2Parallelism: 1}, "memory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPasswordHasher(tt.params)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestPasswordHasherCheckStrength(t *testing.T) {
+	argon2Hasher := newTestHasher(t, cheapArgon2)
+	bcryptHasher := newTestHasher(t, PasswordParams{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: bcrypt.MinCost})
+
+	assert.NoError(t, argon2Hasher.CheckStrength("lion-gate-42"))
+	assert.ErrorIs(t, argon2Hasher.CheckStrength("abc"), ErrPasswordTooShort)
+	assert.ErrorIs(t, argon2Hasher.CheckStrength("Password123"), ErrPasswordTooCommon, "the list is matched regardless of case")
+	assert.ErrorIs(t, argon2Hasher.CheckStrength("qwerty"), ErrPasswordTooCommon)
+
+	long := strings.Repeat("x", MaxBcryptPasswordLength+1)
+	assert.NoError(t, argon2Hasher.CheckStrength(long))
+	assert.ErrorIs(t, bcryptHasher.CheckStrength(long), ErrPasswordTooLong, "bcrypt would ignore the rest")
+	assert.ErrorIs(t, argon2Hasher.CheckStrength(strings.Repeat("x", MaxPasswordLength+1)), ErrPasswordTooLong)
+
+	_, err := bcryptHasher.Hash(long)
+	assert.ErrorIs(t, err, ErrPasswordTooLong)
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"strings"
+)
+
+// opaqueTokenBytes is the entropy of the tokens returned by GenerateOpaqueToken
+const opaqueTokenBytes = 32
+
+// GenerateOpaqueToken returns a random URL-safe token, such as the one of a password reset link
+func GenerateOpaqueToken() (string, error) {
+	buf := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashOpaqueToken returns the hash under which a token from GenerateOpaqueToken is stored. The token carries
+// enough entropy that a fast hash cannot be brute-forced.
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CodeChallengeS256 returns the PKCE challenge of a code verifier with the S256 method (RFC 7636): the unpadded
+// base64url encoding of its SHA-256 hash
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// GenerateNumericCode returns a random code of the given number of decimal digits, such as "042917",
+// short enough to be typed from a text message
+func GenerateNumericCode(digits int) (string, error) {
+	var code strings.Builder
+	for range digits {
+		digit, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code.WriteByte(byte('0' + digit.Int64()))
+	}
+	return code.String(), nil
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/khunmostz/be-liongate-go/app/core/domain"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// totpPeriod is the length of a TOTP time step in seconds, the one authenticator apps assume
+const totpPeriod = 30
+
+// totpOptions are the parameters authenticator apps use by default: six digits of HMAC-SHA1 every 30 seconds
+var totpOptions = totp.ValidateOpts{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+
+// recoveryCodeAlphabet leaves out the letters and digits that are easily confused when typed from paper
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// GenerateTOTP creates a new TOTP secret for account and returns it with its otpauth URI and a QR code of the URI
+func GenerateTOTP(issuer string, account string) (*domain.MfaSetup, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: account,
+		Period:      totpPeriod,
+		Digits:      totpOptions.Digits,
+		Algorithm:   totpOptions.Algorithm,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := key.Image(256, 256)
+	if err != nil {
+		return nil, err
+	}
+	var qrCode bytes.Buffer
+	if err := png.Encode(&qrCode, image); err != nil {
+		return nil, err
+	}
+
+	return &domain.MfaSetup{Secret: key.Secret(), URI: key.URL(), QRCode: qrCode.Bytes()}, nil
+}
+
+// TOTPCode returns the code an authenticator app shows for secret at t
+func TOTPCode(secret string, t time.Time) (string, error) {
+	return totp.GenerateCodeCustom(secret, t, totpOptions)
+}
+
+// MatchTOTP checks code against secret at t, allowing a clock drift of one time step either way,
+// and returns the time step the code belongs to
+func MatchTOTP(secret string, code string, t time.Time) (int64, bool) {
+	code = strings.ReplaceAll(code, " ", "")
+	step := t.Unix() / totpPeriod
+	for _, candidate := range []int64{step, step - 1, step + 1} {
+		expected, err := TOTPCode(secret, time.Unix(candidate*totpPeriod, 0))
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return candidate, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCodes returns n random single-use recovery codes such as "k7m2p-x9qrt"
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	// Bytes from limit up are skipped, so that every letter of the alphabet is equally likely
+	limit := 256 - 256%len(recoveryCodeAlphabet)
+	codes := make([]string, n)
+	random := make([]byte, 1)
+	for i := range codes {
+		var code strings.Builder
+		for code.Len() < 11 {
+			if code.Len() == 5 {
+				code.WriteByte('-')
+				continue
+			}
+			if _, err := rand.Read(random); err != nil {
+				return nil, err
+			}
+			if int(random[0]) < limit {
+				code.WriteByte(recoveryCodeAlphabet[int(random[0])%len(recoveryCodeAlphabet)])
+			}
+		}
+		codes[i] = code.String()
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the hash a recovery code is stored as. The codes are random enough that a fast hash
+// suffices, unlike passwords.
+func HashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(domain.NormalizeRecoveryCode(code)))
+	return hex.EncodeToString(sum[:])
+}